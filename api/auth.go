@@ -1,11 +1,42 @@
 package api
 
+import (
+	"context"
+	"fmt"
+)
+
 // Auth is used to perform credential backend related operations.
 type Auth struct {
 	c *Client
 }
 
+// AuthMethod is implemented by the typed login helpers in the api/auth
+// subpackages. Login authenticates against the auth method's mount and
+// returns the resulting secret without modifying the client.
+type AuthMethod interface {
+	Login(ctx context.Context, client *Client) (*Secret, error)
+}
+
 // Auth is used to return the client for credential-backend API calls.
 func (c *Client) Auth() *Auth {
 	return &Auth{c: c}
 }
+
+// Login authenticates using the given auth method and, on success, sets the
+// returned token on the client.
+func (a *Auth) Login(ctx context.Context, authMethod AuthMethod) (*Secret, error) {
+	if authMethod == nil {
+		return nil, fmt.Errorf("no auth method provided for login")
+	}
+
+	secret, err := authMethod.Login(ctx, a.c)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("login response did not return a client token")
+	}
+
+	a.c.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
@@ -0,0 +1,156 @@
+// Package approle provides a typed login helper for the AppRole auth method.
+package approle
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultMountPath = "approle"
+
+// AppRoleAuth logs in with a role ID and secret ID.
+type AppRoleAuth struct {
+	mountPath      string
+	roleID         string
+	secretID       string
+	secretIDFile   string
+	secretIDEnv    string
+	unwrapSecretID bool
+}
+
+var _ api.AuthMethod = (*AppRoleAuth)(nil)
+
+// SecretID specifies where the secret ID is read from. Exactly one of the
+// fields must be set. Reading from a file or environment variable happens at
+// login time, so that updated secret IDs are picked up.
+type SecretID struct {
+	// FromFile is the path to a file containing the secret ID
+	FromFile string
+	// FromEnv is the name of an environment variable containing the secret ID
+	FromEnv string
+	// FromString is the secret ID itself
+	FromString string
+}
+
+// LoginOption configures an AppRoleAuth.
+type LoginOption func(a *AppRoleAuth) error
+
+// NewAppRoleAuth returns an AppRole login helper for the given role ID and
+// secret ID. By default the auth method is expected to be mounted at
+// "approle".
+func NewAppRoleAuth(roleID string, secretID *SecretID, opts ...LoginOption) (*AppRoleAuth, error) {
+	if roleID == "" {
+		return nil, fmt.Errorf("no role ID provided for login")
+	}
+	if secretID == nil {
+		return nil, fmt.Errorf("no secret ID provided for login")
+	}
+
+	set := 0
+	for _, v := range []string{secretID.FromFile, secretID.FromEnv, secretID.FromString} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one secret ID source must be set")
+	}
+
+	a := &AppRoleAuth{
+		mountPath:    defaultMountPath,
+		roleID:       roleID,
+		secretID:     secretID.FromString,
+		secretIDFile: secretID.FromFile,
+		secretIDEnv:  secretID.FromEnv,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, errwrap.Wrapf("error with login option: {{err}}", err)
+		}
+	}
+
+	return a, nil
+}
+
+// WithMountPath sets the path the AppRole auth method is mounted at.
+func WithMountPath(mountPath string) LoginOption {
+	return func(a *AppRoleAuth) error {
+		a.mountPath = strings.Trim(mountPath, "/")
+		return nil
+	}
+}
+
+// WithWrappingToken indicates that the secret ID is a response-wrapping token
+// that must be unwrapped to obtain the actual secret ID.
+func WithWrappingToken() LoginOption {
+	return func(a *AppRoleAuth) error {
+		a.unwrapSecretID = true
+		return nil
+	}
+}
+
+// Login logs in with AppRole, returning the resulting secret.
+func (a *AppRoleAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	secretID, err := a.readSecretID()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.unwrapSecretID {
+		unwrapClient, err := client.Clone()
+		if err != nil {
+			return nil, errwrap.Wrapf("unable to create client to unwrap secret ID: {{err}}", err)
+		}
+		unwrapClient.SetToken(secretID)
+
+		unwrapped, err := unwrapClient.Logical().Unwrap("")
+		if err != nil {
+			return nil, errwrap.Wrapf("unable to unwrap secret ID: {{err}}", err)
+		}
+		if unwrapped == nil || unwrapped.Data == nil {
+			return nil, fmt.Errorf("unwrapped response did not contain a secret ID")
+		}
+		unwrappedID, ok := unwrapped.Data["secret_id"].(string)
+		if !ok || unwrappedID == "" {
+			return nil, fmt.Errorf("unwrapped response did not contain a secret ID")
+		}
+		secretID = unwrappedID
+	}
+
+	path := fmt.Sprintf("auth/%s/login", a.mountPath)
+	secret, err := client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"role_id":   a.roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to log in with app role auth: {{err}}", err)
+	}
+
+	return secret, nil
+}
+
+func (a *AppRoleAuth) readSecretID() (string, error) {
+	switch {
+	case a.secretIDFile != "":
+		b, err := ioutil.ReadFile(a.secretIDFile)
+		if err != nil {
+			return "", errwrap.Wrapf("unable to read secret ID from file: {{err}}", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	case a.secretIDEnv != "":
+		v := os.Getenv(a.secretIDEnv)
+		if v == "" {
+			return "", fmt.Errorf("secret ID was specified with an environment variable %q with an empty value", a.secretIDEnv)
+		}
+		return v, nil
+	default:
+		return a.secretID, nil
+	}
+}
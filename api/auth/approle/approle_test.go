@@ -0,0 +1,61 @@
+package approle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func TestAppRoleAuth_Login(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"auth": {"client_token": "s.approle"}}`))
+	}))
+	defer ts.Close()
+
+	config := api.DefaultConfig()
+	config.Address = ts.URL
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ClearToken()
+
+	auth, err := NewAppRoleAuth("role-id", &SecretID{FromString: "secret-id"}, WithMountPath("/my-approle/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := client.Auth().Login(context.Background(), auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Auth.ClientToken != "s.approle" || client.Token() != "s.approle" {
+		t.Fatalf("bad token: %#v", secret.Auth)
+	}
+	if gotPath != "/v1/auth/my-approle/login" {
+		t.Fatalf("bad path: %q", gotPath)
+	}
+	if gotBody["role_id"] != "role-id" || gotBody["secret_id"] != "secret-id" {
+		t.Fatalf("bad body: %#v", gotBody)
+	}
+}
+
+func TestNewAppRoleAuth_secretIDSources(t *testing.T) {
+	if _, err := NewAppRoleAuth("role-id", &SecretID{}); err == nil {
+		t.Fatal("expected error with no secret ID source")
+	}
+	if _, err := NewAppRoleAuth("role-id", &SecretID{FromString: "a", FromEnv: "B"}); err == nil {
+		t.Fatal("expected error with multiple secret ID sources")
+	}
+	if _, err := NewAppRoleAuth("", &SecretID{FromString: "a"}); err == nil {
+		t.Fatal("expected error with no role ID")
+	}
+}
@@ -0,0 +1,266 @@
+// Package aws provides a typed login helper for the AWS auth method,
+// supporting both the IAM and EC2 login types.
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultMountPath = "aws"
+	defaultRegion    = "us-east-1"
+
+	iamServerIDHeader = "X-Vault-AWS-IAM-Server-ID"
+)
+
+type authType string
+
+const (
+	iamType authType = "iam"
+	ec2Type authType = "ec2"
+)
+
+// AWSAuth logs in with AWS, either by signing an sts:GetCallerIdentity
+// request with IAM credentials or by presenting the EC2 instance identity
+// document.
+type AWSAuth struct {
+	authType               authType
+	mountPath              string
+	roleName               string
+	region                 string
+	iamServerIDHeaderValue string
+	creds                  *credentials.Credentials
+	nonce                  string
+}
+
+var _ api.AuthMethod = (*AWSAuth)(nil)
+
+// LoginOption configures an AWSAuth.
+type LoginOption func(a *AWSAuth) error
+
+// NewAWSAuth returns an AWS login helper. By default it uses the IAM login
+// type with credentials from the standard AWS credential chain, and the auth
+// method is expected to be mounted at "aws".
+func NewAWSAuth(opts ...LoginOption) (*AWSAuth, error) {
+	a := &AWSAuth{
+		authType:  iamType,
+		mountPath: defaultMountPath,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, errwrap.Wrapf("error with login option: {{err}}", err)
+		}
+	}
+
+	return a, nil
+}
+
+// WithMountPath sets the path the AWS auth method is mounted at.
+func WithMountPath(mountPath string) LoginOption {
+	return func(a *AWSAuth) error {
+		a.mountPath = strings.Trim(mountPath, "/")
+		return nil
+	}
+}
+
+// WithRole sets the name of the role to log in against. If unset, Vault
+// infers the role name from the IAM principal or AMI ID.
+func WithRole(roleName string) LoginOption {
+	return func(a *AWSAuth) error {
+		a.roleName = roleName
+		return nil
+	}
+}
+
+// WithIAMAuth selects the IAM login type. This is the default.
+func WithIAMAuth() LoginOption {
+	return func(a *AWSAuth) error {
+		a.authType = iamType
+		return nil
+	}
+}
+
+// WithEC2Auth selects the EC2 login type, which must be run on an EC2
+// instance.
+func WithEC2Auth() LoginOption {
+	return func(a *AWSAuth) error {
+		a.authType = ec2Type
+		return nil
+	}
+}
+
+// WithRegion sets the region used to sign the STS request for the IAM login
+// type. If unset, AWS_REGION and AWS_DEFAULT_REGION are consulted before
+// falling back to us-east-1.
+func WithRegion(region string) LoginOption {
+	return func(a *AWSAuth) error {
+		a.region = region
+		return nil
+	}
+}
+
+// WithIAMServerIDHeader sets the value of the X-Vault-AWS-IAM-Server-ID
+// header included in the signed request, which must match the value
+// configured on the auth method if it requires one.
+func WithIAMServerIDHeader(headerValue string) LoginOption {
+	return func(a *AWSAuth) error {
+		a.iamServerIDHeaderValue = headerValue
+		return nil
+	}
+}
+
+// WithCredentials uses the given credentials for the IAM login type instead
+// of the standard AWS credential chain.
+func WithCredentials(creds *credentials.Credentials) LoginOption {
+	return func(a *AWSAuth) error {
+		a.creds = creds
+		return nil
+	}
+}
+
+// WithNonce sets the client nonce used with the EC2 login type. The nonce
+// must be the same on every login from the instance after the first one.
+func WithNonce(nonce string) LoginOption {
+	return func(a *AWSAuth) error {
+		a.nonce = nonce
+		return nil
+	}
+}
+
+// Login logs in with AWS, returning the resulting secret.
+func (a *AWSAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	var loginData map[string]interface{}
+	var err error
+	switch a.authType {
+	case ec2Type:
+		loginData, err = a.ec2LoginData(ctx)
+	default:
+		loginData, err = a.iamLoginData()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.roleName != "" {
+		loginData["role"] = a.roleName
+	}
+
+	path := fmt.Sprintf("auth/%s/login", a.mountPath)
+	secret, err := client.Logical().WriteWithContext(ctx, path, loginData)
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to log in with AWS auth: {{err}}", err)
+	}
+
+	return secret, nil
+}
+
+func (a *AWSAuth) ec2LoginData(ctx context.Context) (map[string]interface{}, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, errwrap.Wrapf("error creating session to probe EC2 metadata: {{err}}", err)
+	}
+	metadataSvc := ec2metadata.New(sess)
+
+	// The metadata client has no context aware calls, so bind the context
+	// to each of its requests before they are sent
+	metadataSvc.Handlers.Validate.PushFront(func(r *request.Request) {
+		r.SetContext(ctx)
+	})
+
+	if !metadataSvc.Available() {
+		return nil, fmt.Errorf("metadata service not available")
+	}
+
+	pkcs7, err := metadataSvc.GetDynamicData("instance-identity/pkcs7")
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to get PKCS 7 data from metadata service: {{err}}", err)
+	}
+
+	loginData := map[string]interface{}{
+		"pkcs7": strings.Replace(strings.TrimSpace(pkcs7), "\n", "", -1),
+	}
+	if a.nonce != "" {
+		loginData["nonce"] = a.nonce
+	}
+	return loginData, nil
+}
+
+func (a *AWSAuth) iamLoginData() (map[string]interface{}, error) {
+	region := a.region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+
+	stsSession, err := session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Credentials:      a.creds,
+			Region:           &region,
+			EndpointResolver: endpoints.ResolverFunc(stsSigningResolver),
+		},
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("error creating STS session: {{err}}", err)
+	}
+
+	svc := sts.New(stsSession)
+	stsRequest, _ := svc.GetCallerIdentityRequest(nil)
+
+	// Inject the required auth header value, if supplied, and then sign the
+	// request including that header
+	if a.iamServerIDHeaderValue != "" {
+		stsRequest.HTTPRequest.Header.Add(iamServerIDHeader, a.iamServerIDHeaderValue)
+	}
+	if err := stsRequest.Sign(); err != nil {
+		return nil, errwrap.Wrapf("error signing STS request: {{err}}", err)
+	}
+
+	headersJSON, err := json.Marshal(stsRequest.HTTPRequest.Header)
+	if err != nil {
+		return nil, err
+	}
+	requestBody, err := ioutil.ReadAll(stsRequest.HTTPRequest.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"iam_http_request_method": stsRequest.HTTPRequest.Method,
+		"iam_request_url":         base64.StdEncoding.EncodeToString([]byte(stsRequest.HTTPRequest.URL.String())),
+		"iam_request_headers":     base64.StdEncoding.EncodeToString(headersJSON),
+		"iam_request_body":        base64.StdEncoding.EncodeToString(requestBody),
+	}, nil
+}
+
+// STS used to only have global endpoints, and for backwards compatibility it
+// still signs for us-east-1 even when another region is requested. This
+// resolver forces it to sign for the configured region.
+func stsSigningResolver(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	defaultEndpoint, err := endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
+	if err != nil {
+		return defaultEndpoint, err
+	}
+	defaultEndpoint.SigningRegion = region
+	return defaultEndpoint, nil
+}
@@ -0,0 +1,14 @@
+module github.com/hashicorp/vault/api/auth/aws
+
+go 1.12
+
+replace (
+	github.com/hashicorp/vault/api => ../../
+	github.com/hashicorp/vault/sdk => ../../../sdk
+)
+
+require (
+	github.com/aws/aws-sdk-go v1.19.11
+	github.com/hashicorp/errwrap v1.0.0
+	github.com/hashicorp/vault/api v1.0.2
+)
@@ -0,0 +1,77 @@
+// Package cert provides a typed login helper for the TLS certificate auth
+// method.
+package cert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultMountPath = "cert"
+
+// CertAuth logs in with the TLS client certificate the client is configured
+// with, e.g. via api.Config.ConfigureTLS or the VAULT_CLIENT_CERT and
+// VAULT_CLIENT_KEY environment variables.
+type CertAuth struct {
+	mountPath string
+	name      string
+}
+
+var _ api.AuthMethod = (*CertAuth)(nil)
+
+// LoginOption configures a CertAuth.
+type LoginOption func(a *CertAuth) error
+
+// NewCertAuth returns a certificate login helper. By default the auth method
+// is expected to be mounted at "cert" and Vault matches the presented
+// certificate against all configured certificate roles.
+func NewCertAuth(opts ...LoginOption) (*CertAuth, error) {
+	a := &CertAuth{
+		mountPath: defaultMountPath,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, errwrap.Wrapf("error with login option: {{err}}", err)
+		}
+	}
+
+	return a, nil
+}
+
+// WithMountPath sets the path the certificate auth method is mounted at.
+func WithMountPath(mountPath string) LoginOption {
+	return func(a *CertAuth) error {
+		a.mountPath = strings.Trim(mountPath, "/")
+		return nil
+	}
+}
+
+// WithName restricts the login to the named certificate role.
+func WithName(name string) LoginOption {
+	return func(a *CertAuth) error {
+		a.name = name
+		return nil
+	}
+}
+
+// Login logs in with the client's TLS certificate, returning the resulting
+// secret.
+func (a *CertAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	data := map[string]interface{}{}
+	if a.name != "" {
+		data["name"] = a.name
+	}
+
+	path := fmt.Sprintf("auth/%s/login", a.mountPath)
+	secret, err := client.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to log in with cert auth: {{err}}", err)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,129 @@
+// Package kubernetes provides a typed login helper for the Kubernetes auth
+// method.
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultMountPath           = "kubernetes"
+	defaultServiceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// KubernetesAuth logs in with a Kubernetes service account token.
+type KubernetesAuth struct {
+	roleName                string
+	mountPath               string
+	serviceAccountToken     string
+	serviceAccountTokenPath string
+	serviceAccountTokenEnv  string
+}
+
+var _ api.AuthMethod = (*KubernetesAuth)(nil)
+
+// LoginOption configures a KubernetesAuth.
+type LoginOption func(a *KubernetesAuth) error
+
+// NewKubernetesAuth returns a Kubernetes login helper for the given role. By
+// default the service account token is read from the standard in-pod path at
+// login time and the auth method is expected to be mounted at "kubernetes".
+func NewKubernetesAuth(roleName string, opts ...LoginOption) (*KubernetesAuth, error) {
+	if roleName == "" {
+		return nil, fmt.Errorf("no role name provided for login")
+	}
+
+	a := &KubernetesAuth{
+		roleName:                roleName,
+		mountPath:               defaultMountPath,
+		serviceAccountTokenPath: defaultServiceAccountToken,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, errwrap.Wrapf("error with login option: {{err}}", err)
+		}
+	}
+
+	return a, nil
+}
+
+// WithMountPath sets the path the Kubernetes auth method is mounted at.
+func WithMountPath(mountPath string) LoginOption {
+	return func(a *KubernetesAuth) error {
+		a.mountPath = strings.Trim(mountPath, "/")
+		return nil
+	}
+}
+
+// WithServiceAccountToken uses the given service account token rather than
+// reading it from a file.
+func WithServiceAccountToken(jwt string) LoginOption {
+	return func(a *KubernetesAuth) error {
+		a.serviceAccountToken = jwt
+		return nil
+	}
+}
+
+// WithServiceAccountTokenPath reads the service account token from the given
+// path rather than the default.
+func WithServiceAccountTokenPath(path string) LoginOption {
+	return func(a *KubernetesAuth) error {
+		a.serviceAccountTokenPath = path
+		return nil
+	}
+}
+
+// WithServiceAccountTokenEnv reads the service account token from the given
+// environment variable.
+func WithServiceAccountTokenEnv(envVar string) LoginOption {
+	return func(a *KubernetesAuth) error {
+		a.serviceAccountTokenEnv = envVar
+		return nil
+	}
+}
+
+// Login logs in with Kubernetes, returning the resulting secret.
+func (a *KubernetesAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	jwt, err := a.readServiceAccountToken()
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("auth/%s/login", a.mountPath)
+	secret, err := client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"role": a.roleName,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to log in with Kubernetes auth: {{err}}", err)
+	}
+
+	return secret, nil
+}
+
+func (a *KubernetesAuth) readServiceAccountToken() (string, error) {
+	switch {
+	case a.serviceAccountToken != "":
+		return a.serviceAccountToken, nil
+	case a.serviceAccountTokenEnv != "":
+		v := os.Getenv(a.serviceAccountTokenEnv)
+		if v == "" {
+			return "", fmt.Errorf("service account token was specified with an environment variable %q with an empty value", a.serviceAccountTokenEnv)
+		}
+		return v, nil
+	default:
+		b, err := ioutil.ReadFile(a.serviceAccountTokenPath)
+		if err != nil {
+			return "", errwrap.Wrapf("unable to read service account token: {{err}}", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+}
@@ -0,0 +1,57 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func TestKubernetesAuth_Login(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"auth": {"client_token": "s.kubernetes"}}`))
+	}))
+	defer ts.Close()
+
+	config := api.DefaultConfig()
+	config.Address = ts.URL
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenFile, err := ioutil.TempFile("", "vault-k8s-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tokenFile.Name())
+	tokenFile.WriteString("jwt-from-file\n")
+	tokenFile.Close()
+
+	auth, err := NewKubernetesAuth("my-role", WithServiceAccountTokenPath(tokenFile.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Auth().Login(context.Background(), auth); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/v1/auth/kubernetes/login" {
+		t.Fatalf("bad path: %q", gotPath)
+	}
+	if gotBody["role"] != "my-role" || gotBody["jwt"] != "jwt-from-file" {
+		t.Fatalf("bad body: %#v", gotBody)
+	}
+	if client.Token() != "s.kubernetes" {
+		t.Fatalf("bad token: %q", client.Token())
+	}
+}
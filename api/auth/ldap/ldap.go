@@ -0,0 +1,123 @@
+// Package ldap provides a typed login helper for the LDAP auth method.
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultMountPath = "ldap"
+
+// LDAPAuth logs in with a username and password.
+type LDAPAuth struct {
+	mountPath    string
+	username     string
+	password     string
+	passwordFile string
+	passwordEnv  string
+}
+
+var _ api.AuthMethod = (*LDAPAuth)(nil)
+
+// Password specifies where the password is read from. Exactly one of the
+// fields must be set.
+type Password struct {
+	// FromFile is the path to a file containing the password
+	FromFile string
+	// FromEnv is the name of an environment variable containing the password
+	FromEnv string
+	// FromString is the password itself
+	FromString string
+}
+
+// LoginOption configures an LDAPAuth.
+type LoginOption func(a *LDAPAuth) error
+
+// NewLDAPAuth returns an LDAP login helper for the given username and
+// password. By default the auth method is expected to be mounted at
+// "ldap".
+func NewLDAPAuth(username string, password *Password, opts ...LoginOption) (*LDAPAuth, error) {
+	if username == "" {
+		return nil, fmt.Errorf("no username provided for login")
+	}
+	if password == nil {
+		return nil, fmt.Errorf("no password provided for login")
+	}
+
+	set := 0
+	for _, v := range []string{password.FromFile, password.FromEnv, password.FromString} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one password source must be set")
+	}
+
+	a := &LDAPAuth{
+		mountPath:    defaultMountPath,
+		username:     username,
+		password:     password.FromString,
+		passwordFile: password.FromFile,
+		passwordEnv:  password.FromEnv,
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, errwrap.Wrapf("error with login option: {{err}}", err)
+		}
+	}
+
+	return a, nil
+}
+
+// WithMountPath sets the path the LDAP auth method is mounted at.
+func WithMountPath(mountPath string) LoginOption {
+	return func(a *LDAPAuth) error {
+		a.mountPath = strings.Trim(mountPath, "/")
+		return nil
+	}
+}
+
+// Login logs in with LDAP, returning the resulting secret.
+func (a *LDAPAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	password, err := a.readPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("auth/%s/login/%s", a.mountPath, a.username)
+	secret, err := client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"password": password,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to log in with LDAP auth: {{err}}", err)
+	}
+
+	return secret, nil
+}
+
+func (a *LDAPAuth) readPassword() (string, error) {
+	switch {
+	case a.passwordFile != "":
+		b, err := ioutil.ReadFile(a.passwordFile)
+		if err != nil {
+			return "", errwrap.Wrapf("unable to read password from file: {{err}}", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	case a.passwordEnv != "":
+		v := os.Getenv(a.passwordEnv)
+		if v == "" {
+			return "", fmt.Errorf("password was specified with an environment variable %q with an empty value", a.passwordEnv)
+		}
+		return v, nil
+	default:
+		return a.password, nil
+	}
+}
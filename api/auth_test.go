@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type testAuthMethod struct {
+	secret *Secret
+}
+
+func (m *testAuthMethod) Login(ctx context.Context, client *Client) (*Secret, error) {
+	return m.secret, nil
+}
+
+func TestAuth_Login(t *testing.T) {
+	config, ln := testHTTPServer(t, http.NotFoundHandler())
+	defer ln.Close()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ClearToken()
+
+	method := &testAuthMethod{
+		secret: &Secret{Auth: &SecretAuth{ClientToken: "s.token"}},
+	}
+	if _, err := client.Auth().Login(context.Background(), method); err != nil {
+		t.Fatal(err)
+	}
+	if client.Token() != "s.token" {
+		t.Fatalf("expected client token to be set, got %q", client.Token())
+	}
+
+	client.ClearToken()
+	method.secret = &Secret{}
+	if _, err := client.Auth().Login(context.Background(), method); err == nil {
+		t.Fatal("expected error when no client token is returned")
+	}
+	if client.Token() != "" {
+		t.Fatalf("expected client token to be unset, got %q", client.Token())
+	}
+}
@@ -98,6 +98,14 @@ type Config struct {
 	// Note: It is not thread-safe to set this and make concurrent requests
 	// with the same client. Cloning a client will not clone this value.
 	OutputCurlString bool
+
+	// OutputPolicy causes the actual request to return an error of type
+	// *OutputPolicyError. Type asserting the error message will allow
+	// fetching the HCL policy needed to perform the operation.
+	//
+	// Note: It is not thread-safe to set this and make concurrent requests
+	// with the same client. Cloning a client will not clone this value.
+	OutputPolicy bool
 }
 
 // TLSConfig contains the parameters needed to configure TLS on the HTTP client
@@ -503,6 +511,24 @@ func (c *Client) SetOutputCurlString(curl bool) {
 	c.config.OutputCurlString = curl
 }
 
+func (c *Client) OutputPolicy() bool {
+	c.modifyLock.RLock()
+	c.config.modifyLock.RLock()
+	defer c.config.modifyLock.RUnlock()
+	c.modifyLock.RUnlock()
+
+	return c.config.OutputPolicy
+}
+
+func (c *Client) SetOutputPolicy(isSet bool) {
+	c.modifyLock.RLock()
+	c.config.modifyLock.Lock()
+	defer c.config.modifyLock.Unlock()
+	c.modifyLock.RUnlock()
+
+	c.config.OutputPolicy = isSet
+}
+
 // CurrentWrappingLookupFunc sets a lookup function that returns desired wrap TTLs
 // for a given operation and path
 func (c *Client) CurrentWrappingLookupFunc() WrappingLookupFunc {
@@ -521,6 +547,40 @@ func (c *Client) SetWrappingLookupFunc(lookupFunc WrappingLookupFunc) {
 	c.wrappingLookupFunc = lookupFunc
 }
 
+// WithWrappingTTL returns a copy of the client, carrying over its token and
+// headers, that requests every response be wrapped with the given TTL. The
+// original client is left unchanged.
+func (c *Client) WithWrappingTTL(ttl string) (*Client, error) {
+	if ttl == "" {
+		return nil, fmt.Errorf("wrapping TTL must not be empty")
+	}
+	if _, err := parseutil.ParseDurationSecond(ttl); err != nil {
+		return nil, errwrap.Wrapf("invalid wrapping TTL: {{err}}", err)
+	}
+
+	client, err := c.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	c.modifyLock.RLock()
+	client.token = c.token
+	client.mfaCreds = c.mfaCreds
+	client.policyOverride = c.policyOverride
+	if c.headers != nil {
+		client.headers = make(http.Header, len(c.headers))
+		for k, v := range c.headers {
+			client.headers[k] = append([]string(nil), v...)
+		}
+	}
+	c.modifyLock.RUnlock()
+
+	client.SetWrappingLookupFunc(func(operation, path string) string {
+		return ttl
+	})
+	return client, nil
+}
+
 // SetMFACreds sets the MFA credentials supplied either via the environment
 // variable or via the command line.
 func (c *Client) SetMFACreds(creds []string) {
@@ -731,6 +791,7 @@ func (c *Client) RawRequestWithContext(ctx context.Context, r *Request) (*Respon
 	httpClient := c.config.HttpClient
 	timeout := c.config.Timeout
 	outputCurlString := c.config.OutputCurlString
+	outputPolicy := c.config.OutputPolicy
 	c.config.modifyLock.RUnlock()
 
 	c.modifyLock.RUnlock()
@@ -762,6 +823,15 @@ START:
 		return nil, LastOutputStringError
 	}
 
+	if outputPolicy {
+		LastOutputPolicyError = &OutputPolicyError{
+			method: req.Method,
+			path:   req.URL.Path,
+			params: req.URL.Query(),
+		}
+		return nil, LastOutputPolicyError
+	}
+
 	if timeout != 0 {
 		ctx, _ = context.WithTimeout(ctx, timeout)
 	}
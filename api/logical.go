@@ -129,12 +129,16 @@ func (c *Logical) List(path string) (*Secret, error) {
 }
 
 func (c *Logical) Write(path string, data map[string]interface{}) (*Secret, error) {
+	return c.WriteWithContext(context.Background(), path, data)
+}
+
+func (c *Logical) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*Secret, error) {
 	r := c.c.NewRequest("PUT", "/v1/"+path)
 	if err := r.SetJSONBody(data); err != nil {
 		return nil, err
 	}
 
-	ctx, cancelFunc := context.WithCancel(context.Background())
+	ctx, cancelFunc := context.WithCancel(ctx)
 	defer cancelFunc()
 	resp, err := c.c.RawRequestWithContext(ctx, r)
 	if resp != nil {
@@ -189,6 +193,16 @@ func (c *Logical) Delete(path string) (*Secret, error) {
 	return ParseSecret(resp.Body)
 }
 
+// UnwrapWithValidation unwraps a response-wrapping token after verifying
+// that it was created by a request to one of the expected paths. See
+// Sys.ValidateWrappingToken for how paths are matched.
+func (c *Logical) UnwrapWithValidation(wrappingToken string, expectedPaths ...string) (*Secret, error) {
+	if _, err := c.c.Sys().ValidateWrappingToken(wrappingToken, expectedPaths...); err != nil {
+		return nil, err
+	}
+	return c.Unwrap(wrappingToken)
+}
+
 func (c *Logical) Unwrap(wrappingToken string) (*Secret, error) {
 	var data map[string]interface{}
 	if wrappingToken != "" {
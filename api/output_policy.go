@@ -0,0 +1,155 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	ErrOutputPolicyRequest = "output a policy, please"
+)
+
+var (
+	LastOutputPolicyError *OutputPolicyError
+)
+
+// sudoPaths are the paths requiring the sudo capability, relative to the
+// API root. Paths ending with a * match any path with that prefix. They mirror
+// the root paths of the system backend, under sys/, and of the token store,
+// under auth/token/, and must be updated along with them.
+var sudoPaths = []string{
+	"auth/token/accessors*",
+	"auth/token/revoke-orphan/*",
+	"sys/audit",
+	"sys/audit/*",
+	"sys/auth/*",
+	"sys/config/auditing/*",
+	"sys/config/cors",
+	"sys/config/cubbyhole",
+	"sys/config/rate-limits",
+	"sys/config/rate-limits/*",
+	"sys/config/state/*",
+	"sys/config/ui/headers/*",
+	"sys/features/*",
+	"sys/host-info",
+	"sys/internal/cache/flush",
+	"sys/internal/cache/flush/*",
+	"sys/keyring/backup",
+	"sys/leases/lookup/*",
+	"sys/leases/revoke-force/*",
+	"sys/leases/revoke-prefix/*",
+	"sys/managed-keys/*",
+	"sys/plugins/catalog/*",
+	"sys/raw",
+	"sys/raw/*",
+	"sys/remount",
+	"sys/replication/dr/primary/secondary-token",
+	"sys/replication/dr/reindex",
+	"sys/replication/performance/primary/secondary-token",
+	"sys/replication/performance/reindex",
+	"sys/replication/primary/secondary-token",
+	"sys/replication/reindex",
+	"sys/revoke-force/*",
+	"sys/revoke-prefix/*",
+	"sys/rotate",
+	"sys/wrapping/accessors/*",
+	"sys/wrapping/tokens*",
+}
+
+// SudoPaths returns the paths requiring the sudo capability, relative to the
+// API root. Paths ending with a * match any path with that prefix.
+func SudoPaths() []string {
+	paths := make([]string, len(sudoPaths))
+	copy(paths, sudoPaths)
+	return paths
+}
+
+// OutputPolicyError is returned instead of performing a request when the
+// client is configured to output policies. It describes the policy needed to
+// perform the request.
+type OutputPolicyError struct {
+	method          string
+	path            string
+	params          url.Values
+	parsingError    error
+	parsedHCLString string
+}
+
+func (d *OutputPolicyError) Error() string {
+	if d.parsedHCLString == "" {
+		d.parseRequest()
+		if d.parsingError != nil {
+			return d.parsingError.Error()
+		}
+	}
+
+	return ErrOutputPolicyRequest
+}
+
+func (d *OutputPolicyError) parseRequest() {
+	// Strip the address and the API version from the path
+	path, err := url.PathUnescape(d.path)
+	if err != nil {
+		d.parsingError = err
+		return
+	}
+	if i := strings.Index(path, "/v1/"); i != -1 {
+		path = path[i+len("/v1/"):]
+	}
+	path = strings.TrimPrefix(path, "/")
+
+	// Lists are sent as GET requests with a list parameter
+	method := d.method
+	if method == http.MethodGet && d.params.Get("list") == "true" {
+		method = "LIST"
+	}
+
+	var capabilities []string
+	switch method {
+	case http.MethodGet, http.MethodHead, "":
+		capabilities = []string{"read"}
+	case http.MethodPost, http.MethodPut:
+		capabilities = []string{"create", "update"}
+	case http.MethodDelete:
+		capabilities = []string{"delete"}
+	case "LIST":
+		capabilities = []string{"list"}
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+	default:
+		d.parsingError = fmt.Errorf("unsupported request method %q", method)
+		return
+	}
+
+	if isSudoPath(path) {
+		capabilities = append(capabilities, "sudo")
+	}
+
+	d.parsedHCLString = fmt.Sprintf("path %q {\n  capabilities = [\"%s\"]\n}", path, strings.Join(capabilities, `", "`))
+}
+
+// HCLString returns the policy needed to perform the request, in HCL
+func (d *OutputPolicyError) HCLString() string {
+	if d.parsedHCLString == "" {
+		d.parseRequest()
+	}
+	return d.parsedHCLString
+}
+
+func isSudoPath(path string) bool {
+	for _, sudoPath := range sudoPaths {
+		if strings.HasSuffix(sudoPath, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(sudoPath, "*")) {
+				return true
+			}
+			continue
+		}
+		if path == sudoPath {
+			return true
+		}
+	}
+	return false
+}
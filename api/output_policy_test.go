@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestOutputPolicy(t *testing.T) {
+	client, err := NewClient(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAddress("https://127.0.0.1:8200/prefix")
+	client.SetOutputPolicy(true)
+
+	cases := map[string]struct {
+		do       func() error
+		expected string
+	}{
+		"read": {
+			func() error {
+				_, err := client.Logical().Read("secret/foo bar")
+				return err
+			},
+			"path \"secret/foo bar\" {\n  capabilities = [\"read\"]\n}",
+		},
+		"write": {
+			func() error {
+				_, err := client.Logical().Write("secret/foo", map[string]interface{}{"a": "b"})
+				return err
+			},
+			"path \"secret/foo\" {\n  capabilities = [\"create\", \"update\"]\n}",
+		},
+		"list": {
+			func() error {
+				_, err := client.Logical().List("secret/")
+				return err
+			},
+			"path \"secret/\" {\n  capabilities = [\"list\"]\n}",
+		},
+		"delete": {
+			func() error {
+				_, err := client.Logical().Delete("secret/foo")
+				return err
+			},
+			"path \"secret/foo\" {\n  capabilities = [\"delete\"]\n}",
+		},
+		"sudo": {
+			func() error {
+				return client.Sys().EnableAuditWithOptions("file", &EnableAuditOptions{Type: "file"})
+			},
+			"path \"sys/audit/file\" {\n  capabilities = [\"create\", \"update\", \"sudo\"]\n}",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.do()
+			policyErr, ok := err.(*OutputPolicyError)
+			if !ok {
+				t.Fatalf("expected an OutputPolicyError, got %v", err)
+			}
+			if policyErr.Error() != ErrOutputPolicyRequest {
+				t.Fatalf("bad: %s", policyErr.Error())
+			}
+			if policyErr.HCLString() != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, policyErr.HCLString())
+			}
+		})
+	}
+}
+
+func TestIsSudoPath(t *testing.T) {
+	cases := map[string]bool{
+		"sys/auth":                  false,
+		"sys/auth/userpass":         true,
+		"sys/audit":                 true,
+		"sys/rotate":                true,
+		"sys/rotate/foo":            false,
+		"auth/token/accessors/":     true,
+		"auth/token/revoke-orphan/": true,
+		"secret/foo":                false,
+	}
+	for path, expected := range cases {
+		if isSudoPath(path) != expected {
+			t.Fatalf("%s: expected %t", path, expected)
+		}
+	}
+}
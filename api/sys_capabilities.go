@@ -23,6 +23,21 @@ func (c *Sys) Capabilities(token, path string) ([]string, error) {
 		reqPath = fmt.Sprintf("%s-self", reqPath)
 	}
 
+	return c.capabilities(reqPath, body, path)
+}
+
+// CapabilitiesAccessor returns the capabilities on the given path of the token
+// associated with the given accessor
+func (c *Sys) CapabilitiesAccessor(accessor, path string) ([]string, error) {
+	body := map[string]string{
+		"accessor": accessor,
+		"path":     path,
+	}
+
+	return c.capabilities("/v1/sys/capabilities-accessor", body, path)
+}
+
+func (c *Sys) capabilities(reqPath string, body map[string]string, path string) ([]string, error) {
 	r := c.c.NewRequest("POST", reqPath)
 	if err := r.SetJSONBody(body); err != nil {
 		return nil, err
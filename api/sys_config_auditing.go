@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ListAuditedRequestHeaders returns the request headers that are included in
+// audit entries, keyed by lowercased header name.
+func (c *Sys) ListAuditedRequestHeaders() (map[string]*AuditedRequestHeader, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/config/auditing/request-headers")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result struct {
+		Headers map[string]*AuditedRequestHeader `mapstructure:"headers"`
+	}
+	err = mapstructure.Decode(secret.Data, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Headers == nil {
+		result.Headers = map[string]*AuditedRequestHeader{}
+	}
+
+	return result.Headers, nil
+}
+
+// SetAuditedRequestHeader includes the given request header in audit entries.
+// If hmac is true, the header's value is HMAC'd in the same way as other
+// sensitive request data.
+func (c *Sys) SetAuditedRequestHeader(header string, hmac bool) error {
+	r := c.c.NewRequest("PUT", fmt.Sprintf("/v1/sys/config/auditing/request-headers/%s", header))
+	if err := r.SetJSONBody(map[string]interface{}{
+		"hmac": hmac,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DeleteAuditedRequestHeader stops including the given request header in
+// audit entries.
+func (c *Sys) DeleteAuditedRequestHeader(header string) error {
+	r := c.c.NewRequest("DELETE", fmt.Sprintf("/v1/sys/config/auditing/request-headers/%s", header))
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err == nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
+type AuditedRequestHeader struct {
+	HMAC bool `json:"hmac" mapstructure:"hmac"`
+}
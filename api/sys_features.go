@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ListFeatures returns the feature flags registered in this build of Vault,
+// keyed by name.
+func (c *Sys) ListFeatures() (map[string]*FeatureFlag, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/features")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result struct {
+		Features map[string]*FeatureFlag `mapstructure:"features"`
+	}
+	err = mapstructure.Decode(secret.Data, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Features == nil {
+		result.Features = map[string]*FeatureFlag{}
+	}
+
+	return result.Features, nil
+}
+
+// SetFeatureEnabled turns the given feature on or off for the cluster.
+func (c *Sys) SetFeatureEnabled(name string, enabled bool) error {
+	r := c.c.NewRequest("PUT", fmt.Sprintf("/v1/sys/features/%s", name))
+	if err := r.SetJSONBody(map[string]interface{}{
+		"enabled": enabled,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// ResetFeature removes any override of the given feature, so that its default
+// applies again.
+func (c *Sys) ResetFeature(name string) error {
+	r := c.c.NewRequest("DELETE", fmt.Sprintf("/v1/sys/features/%s", name))
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err == nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
+type FeatureFlag struct {
+	Name        string `json:"name" mapstructure:"name"`
+	Description string `json:"description" mapstructure:"description"`
+	Default     bool   `json:"default" mapstructure:"default"`
+	Enabled     bool   `json:"enabled" mapstructure:"enabled"`
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+func (c *Sys) HAStatus() (*HAStatusResponse, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/ha-status")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result HAStatusResponse
+	err = resp.DecodeJSON(&result)
+	return &result, err
+}
+
+type HAStatusResponse struct {
+	HAEnabled            bool           `json:"ha_enabled"`
+	ClusterName          string         `json:"cluster_name"`
+	ClusterID            string         `json:"cluster_id"`
+	IsSelf               bool           `json:"is_self"`
+	LeaderAddress        string         `json:"leader_address"`
+	LeaderClusterAddress string         `json:"leader_cluster_address"`
+	LastWAL              uint64         `json:"last_wal"`
+	Nodes                []HAStatusNode `json:"nodes"`
+}
+
+type HAStatusNode struct {
+	ClusterAddress string     `json:"cluster_address"`
+	APIAddress     string     `json:"api_address"`
+	ActiveNode     bool       `json:"active_node"`
+	PerfStandby    bool       `json:"performance_standby"`
+	LastEcho       *time.Time `json:"last_echo"`
+}
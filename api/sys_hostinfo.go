@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// HostInfo returns information about the host of the active node. It requires
+// sudo capability on sys/host-info.
+func (c *Sys) HostInfo() (*HostInfoResponse, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/host-info")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result HostInfoResponse
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339),
+		WeaklyTypedInput: true,
+		Result:           &result,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(secret.Data); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+type HostInfoResponse struct {
+	Timestamp time.Time            `json:"timestamp" mapstructure:"timestamp"`
+	Host      *HostInfoHost        `json:"host" mapstructure:"host"`
+	CPU       *HostInfoCPU         `json:"cpu" mapstructure:"cpu"`
+	Memory    *HostInfoMemory      `json:"memory" mapstructure:"memory"`
+	Disk      []*HostInfoDiskUsage `json:"disk" mapstructure:"disk"`
+}
+
+type HostInfoHost struct {
+	Hostname string `json:"hostname" mapstructure:"hostname"`
+	OS       string `json:"os" mapstructure:"os"`
+	Arch     string `json:"arch" mapstructure:"arch"`
+	Uptime   uint64 `json:"uptime" mapstructure:"uptime"`
+	BootTime uint64 `json:"boot_time" mapstructure:"boot_time"`
+}
+
+type HostInfoCPU struct {
+	Count  int     `json:"count" mapstructure:"count"`
+	Load1  float64 `json:"load1" mapstructure:"load1"`
+	Load5  float64 `json:"load5" mapstructure:"load5"`
+	Load15 float64 `json:"load15" mapstructure:"load15"`
+}
+
+type HostInfoMemory struct {
+	Total       uint64  `json:"total" mapstructure:"total"`
+	Available   uint64  `json:"available" mapstructure:"available"`
+	Free        uint64  `json:"free" mapstructure:"free"`
+	Used        uint64  `json:"used" mapstructure:"used"`
+	UsedPercent float64 `json:"used_percent" mapstructure:"used_percent"`
+}
+
+type HostInfoDiskUsage struct {
+	Path        string  `json:"path" mapstructure:"path"`
+	Total       uint64  `json:"total" mapstructure:"total"`
+	Free        uint64  `json:"free" mapstructure:"free"`
+	Used        uint64  `json:"used" mapstructure:"used"`
+	UsedPercent float64 `json:"used_percent" mapstructure:"used_percent"`
+}
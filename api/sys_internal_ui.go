@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// UIMountsOutput is the response of the sys/internal/ui/mounts endpoint.
+type UIMountsOutput struct {
+	Secret map[string]*MountOutput `json:"secret"`
+	Auth   map[string]*MountOutput `json:"auth"`
+}
+
+// ListUIMounts lists the mounts visible to the client. When the client has no
+// token, only mounts tuned with listing_visibility set to "unauth" are
+// returned, and only their type, description and options are populated. This
+// allows login interfaces to present the available auth methods before a
+// token has been obtained.
+func (c *Sys) ListUIMounts() (*UIMountsOutput, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/internal/ui/mounts")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result UIMountsOutput
+	if err := mapstructure.Decode(secret.Data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// KeyringBackup returns a backup of the barrier keyring and the root of trust
+// protecting it. The backup is only usable together with the unseal keys or
+// seal of the Vault it was taken from.
+func (c *Sys) KeyringBackup() (*KeyringBackupOutput, error) {
+	r := c.c.NewRequest("GET", "/v1/sys/keyring/backup")
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result KeyringBackupOutput
+	if err := mapstructure.WeakDecode(secret.Data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// KeyringRestore restores a backup returned by KeyringBackup into an
+// uninitialized Vault, which remains sealed afterwards.
+func (c *Sys) KeyringRestore(backup string) error {
+	r := c.c.NewRequest("PUT", "/v1/sys/keyring/restore")
+	if err := r.SetJSONBody(map[string]interface{}{
+		"backup": backup,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err == nil {
+		defer resp.Body.Close()
+	}
+	return err
+}
+
+type KeyringBackupOutput struct {
+	Term   int    `json:"term" mapstructure:"term"`
+	Backup string `json:"backup" mapstructure:"backup"`
+}
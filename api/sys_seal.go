@@ -65,18 +65,20 @@ func sealStatusRequest(c *Sys, r *Request) (*SealStatusResponse, error) {
 }
 
 type SealStatusResponse struct {
-	Type         string `json:"type"`
-	Initialized  bool   `json:"initialized"`
-	Sealed       bool   `json:"sealed"`
-	T            int    `json:"t"`
-	N            int    `json:"n"`
-	Progress     int    `json:"progress"`
-	Nonce        string `json:"nonce"`
-	Version      string `json:"version"`
-	Migration    bool   `json:"migration"`
-	ClusterName  string `json:"cluster_name,omitempty"`
-	ClusterID    string `json:"cluster_id,omitempty"`
-	RecoverySeal bool   `json:"recovery_seal"`
+	Type          string `json:"type"`
+	Initialized   bool   `json:"initialized"`
+	Sealed        bool   `json:"sealed"`
+	T             int    `json:"t"`
+	N             int    `json:"n"`
+	Progress      int    `json:"progress"`
+	Nonce         string `json:"nonce"`
+	Version       string `json:"version"`
+	Migration     bool   `json:"migration"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+	ClusterID     string `json:"cluster_id,omitempty"`
+	LeaderAddress string `json:"leader_address,omitempty"`
+	LastWAL       uint64 `json:"last_wal,omitempty"`
+	RecoverySeal  bool   `json:"recovery_seal"`
 }
 
 type UnsealOpts struct {
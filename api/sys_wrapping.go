@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
+)
+
+// WrapLookupResponse holds the properties of a response-wrapping token.
+type WrapLookupResponse struct {
+	CreationTTL  time.Duration
+	CreationTime time.Time
+	CreationPath string
+}
+
+// WrapLookup looks up the properties of a response-wrapping token without
+// unwrapping it.
+func (c *Sys) WrapLookup(wrappingToken string) (*WrapLookupResponse, error) {
+	r := c.c.NewRequest("POST", "/v1/sys/wrapping/lookup")
+	if err := r.SetJSONBody(map[string]interface{}{
+		"token": wrappingToken,
+	}); err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result WrapLookupResponse
+	if ttlRaw, ok := secret.Data["creation_ttl"]; ok {
+		if result.CreationTTL, err = parseutil.ParseDurationSecond(ttlRaw); err != nil {
+			return nil, errwrap.Wrapf("error parsing creation_ttl: {{err}}", err)
+		}
+	}
+	if timeRaw, ok := secret.Data["creation_time"].(string); ok && timeRaw != "" {
+		if result.CreationTime, err = time.Parse(time.RFC3339Nano, timeRaw); err != nil {
+			return nil, errwrap.Wrapf("error parsing creation_time: {{err}}", err)
+		}
+	}
+	result.CreationPath, _ = secret.Data["creation_path"].(string)
+
+	return &result, nil
+}
+
+// ValidateWrappingToken looks up a response-wrapping token and verifies that
+// it was created by a request to one of the expected paths. A path ending in
+// "*" matches any creation path with that prefix. Checking the creation path
+// before unwrapping detects a token that was intercepted and replaced with
+// one wrapping attacker-controlled data.
+func (c *Sys) ValidateWrappingToken(wrappingToken string, expectedPaths ...string) (*WrapLookupResponse, error) {
+	if len(expectedPaths) == 0 {
+		return nil, errors.New("no expected creation paths provided")
+	}
+
+	lookup, err := c.WrapLookup(wrappingToken)
+	if err != nil {
+		return nil, errwrap.Wrapf("error looking up wrapping token: {{err}}", err)
+	}
+
+	if !wrappingPathMatches(lookup.CreationPath, expectedPaths) {
+		return nil, fmt.Errorf("wrapping token creation path %q does not match any expected path", lookup.CreationPath)
+	}
+
+	return lookup, nil
+}
+
+func wrappingPathMatches(creationPath string, expectedPaths []string) bool {
+	creationPath = strings.Trim(creationPath, "/")
+	for _, expected := range expectedPaths {
+		expected = strings.TrimPrefix(expected, "/")
+		if strings.HasSuffix(expected, "*") {
+			if strings.HasPrefix(creationPath, strings.TrimSuffix(expected, "*")) {
+				return true
+			}
+			continue
+		}
+		if creationPath == strings.TrimSuffix(expected, "/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSys_ValidateWrappingToken(t *testing.T) {
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/sys/wrapping/lookup" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {
+			"creation_ttl": 300,
+			"creation_time": "2019-06-01T12:00:00.123456789Z",
+			"creation_path": "auth/approle/role/web/secret-id"
+		}}`))
+	}
+	config, ln := testHTTPServer(t, http.HandlerFunc(handler))
+	defer ln.Close()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookup, err := client.Sys().ValidateWrappingToken("s.wrapped", "auth/approle/role/web/secret-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookup.CreationTTL != 5*time.Minute {
+		t.Fatalf("bad creation TTL: %v", lookup.CreationTTL)
+	}
+	if lookup.CreationTime.IsZero() {
+		t.Fatal("expected creation time to be parsed")
+	}
+
+	if _, err := client.Sys().ValidateWrappingToken("s.wrapped", "auth/approle/role/*"); err != nil {
+		t.Fatalf("expected glob to match: %v", err)
+	}
+	if _, err := client.Sys().ValidateWrappingToken("s.wrapped", "secret/data/foo", "auth/approle/role/db/*"); err == nil {
+		t.Fatal("expected error for unexpected creation path")
+	}
+	if _, err := client.Logical().UnwrapWithValidation("s.wrapped", "sys/wrapping/wrap"); err == nil {
+		t.Fatal("expected unwrap to be refused for unexpected creation path")
+	}
+}
+
+func TestClient_WithWrappingTTL(t *testing.T) {
+	config, ln := testHTTPServer(t, http.NotFoundHandler())
+	defer ln.Close()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("s.token")
+
+	wrapping, err := client.WithWrappingTTL("2m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrapping.Token() != "s.token" {
+		t.Fatalf("expected token to be carried over, got %q", wrapping.Token())
+	}
+	if ttl := wrapping.NewRequest("GET", "/v1/secret/foo").WrapTTL; ttl != "2m" {
+		t.Fatalf("bad wrap TTL: %q", ttl)
+	}
+	if ttl := client.NewRequest("GET", "/v1/secret/foo").WrapTTL; ttl != "" {
+		t.Fatalf("original client should not wrap, got %q", ttl)
+	}
+
+	if _, err := client.WithWrappingTTL("soon"); err == nil {
+		t.Fatal("expected error for invalid TTL")
+	}
+}
@@ -3,6 +3,7 @@ package audit
 import (
 	"context"
 
+	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -57,6 +58,9 @@ type BackendConfig struct {
 
 	// Config is the opaque user configuration provided when mounting
 	Config map[string]string
+
+	// Logger is used for errors that can't be returned to a request
+	Logger log.Logger
 }
 
 // Factory is the factory function to create an audit backend.
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/copystructure"
@@ -140,6 +141,7 @@ func (f *AuditFormatter) FormatRequest(ctx context.Context, w io.Writer, config
 
 		Request: AuditRequest{
 			ID:                  req.ID,
+			TraceID:             tracing.TraceID(ctx),
 			ClientToken:         req.ClientToken,
 			ClientTokenAccessor: req.ClientTokenAccessor,
 			Operation:           req.Operation,
@@ -309,6 +311,18 @@ func (f *AuditFormatter) FormatResponse(ctx context.Context, w io.Writer, config
 			EntityID:                  resp.Auth.EntityID,
 			TokenType:                 resp.Auth.TokenType.String(),
 		}
+		if resp.Auth.Alias != nil {
+			// The source IP isn't persisted along with the alias, it is added
+			// to the audited metadata only
+			aliasMetadata := make(map[string]string, len(resp.Auth.Alias.Metadata)+1)
+			for k, v := range resp.Auth.Alias.Metadata {
+				aliasMetadata[k] = v
+			}
+			if req.Connection != nil && req.Connection.RemoteAddr != "" {
+				aliasMetadata[logical.AliasMetadataKeySourceIP] = req.Connection.RemoteAddr
+			}
+			respAuth.AliasMetadata = aliasMetadata
+		}
 	}
 
 	var respSecret *AuditSecret
@@ -353,6 +367,7 @@ func (f *AuditFormatter) FormatResponse(ctx context.Context, w io.Writer, config
 
 		Request: AuditRequest{
 			ID:                  req.ID,
+			TraceID:             tracing.TraceID(ctx),
 			ClientToken:         req.ClientToken,
 			ClientTokenAccessor: req.ClientTokenAccessor,
 			Operation:           req.Operation,
@@ -411,6 +426,7 @@ type AuditResponseEntry struct {
 
 type AuditRequest struct {
 	ID                  string                 `json:"id"`
+	TraceID             string                 `json:"trace_id,omitempty"`
 	ReplicationCluster  string                 `json:"replication_cluster,omitempty"`
 	Operation           logical.Operation      `json:"operation"`
 	ClientToken         string                 `json:"client_token"`
@@ -443,6 +459,7 @@ type AuditAuth struct {
 	IdentityPolicies          []string            `json:"identity_policies,omitempty"`
 	ExternalNamespacePolicies map[string][]string `json:"external_namespace_policies,omitempty"`
 	Metadata                  map[string]string   `json:"metadata"`
+	AliasMetadata             map[string]string   `json:"alias_metadata,omitempty"`
 	NumUses                   int                 `json:"num_uses,omitempty"`
 	RemainingUses             int                 `json:"remaining_uses,omitempty"`
 	EntityID                  string              `json:"entity_id"`
@@ -4,8 +4,10 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
 
+	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -70,3 +72,56 @@ func TestFormatResponseErrors(t *testing.T) {
 		t.Fatal("expected error due to nil writer")
 	}
 }
+
+type captureFormatWriter struct {
+	noopFormatWriter
+	response *AuditResponseEntry
+}
+
+func (c *captureFormatWriter) WriteResponse(_ io.Writer, entry *AuditResponseEntry) error {
+	c.response = entry
+	return nil
+}
+
+func TestFormatResponse_AliasMetadata(t *testing.T) {
+	writer := &captureFormatWriter{}
+	formatter := AuditFormatter{
+		AuditFormatWriter: writer,
+	}
+
+	alias := &logical.Alias{
+		Name: "foo",
+		Metadata: map[string]string{
+			logical.AliasMetadataKeyMountAccessor: "auth_userpass_1234",
+		},
+	}
+	in := &LogInput{
+		Request: &logical.Request{
+			Connection: &logical.Connection{
+				RemoteAddr: "127.0.0.1",
+			},
+		},
+		Response: &logical.Response{
+			Auth: &logical.Auth{
+				Alias: alias,
+			},
+		},
+	}
+	if err := formatter.FormatResponse(namespace.RootContext(nil), ioutil.Discard, FormatterConfig{}, in); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		logical.AliasMetadataKeyMountAccessor: "auth_userpass_1234",
+		logical.AliasMetadataKeySourceIP:      "127.0.0.1",
+	}
+	metadata := writer.response.Response.Auth.AliasMetadata
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Fatalf("bad: %#v", metadata)
+	}
+
+	// The source IP is only added to the audited metadata
+	if _, ok := alias.Metadata[logical.AliasMetadataKeySourceIP]; ok {
+		t.Fatalf("source IP set on the alias: %#v", alias.Metadata)
+	}
+}
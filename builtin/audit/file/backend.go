@@ -3,17 +3,19 @@ package file
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/sdk/helper/salt"
-	"github.com/hashicorp/vault/sdk/logical"
 )
 
 func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, error) {
@@ -82,11 +84,22 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 		}
 	}
 
+	rotate, err := parseRotateConfig(conf.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := conf.Logger
+	if logger == nil {
+		logger = log.NewNullLogger()
+	}
+
 	b := &Backend{
-		path:       path,
-		mode:       mode,
-		saltConfig: conf.SaltConfig,
-		saltView:   conf.SaltView,
+		path:   path,
+		mode:   mode,
+		rotate: rotate,
+		logger: logger,
+		salt:   salt.NewLazySalt(conf.SaltView, conf.SaltConfig),
 		formatConfig: audit.FormatterConfig{
 			Raw:          logRaw,
 			HMACAccessor: hmacAccessor,
@@ -109,6 +122,9 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 	switch path {
 	case "stdout", "discard":
 		// no need to test opening file if outputting to stdout or discarding
+		if rotate.enabled() {
+			return nil, fmt.Errorf("log rotation is only supported when writing to a file")
+		}
 	default:
 		// Ensure that the file can be successfully opened for writing;
 		// otherwise it will be too late to catch later without problems
@@ -123,9 +139,8 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 
 // Backend is the audit backend for the file-based audit store.
 //
-// NOTE: This audit backend is currently very simple: it appends to a file.
-// It doesn't do anything more at the moment to assist with rotation
-// or reset the write cursor, this should be done in the future.
+// It appends to a file, which it can optionally rotate once it reaches a
+// given size or age.
 type Backend struct {
 	path string
 
@@ -135,33 +150,23 @@ type Backend struct {
 	fileLock sync.RWMutex
 	f        *os.File
 	mode     os.FileMode
+	size     int64
+	info     os.FileInfo
+	openedAt time.Time
+
+	rotate     rotateConfig
+	rotateLock sync.Mutex
+	rotateWg   sync.WaitGroup
 
-	saltMutex  sync.RWMutex
-	salt       *salt.Salt
-	saltConfig *salt.Config
-	saltView   logical.Storage
+	logger log.Logger
+
+	salt *salt.LazySalt
 }
 
 var _ audit.Backend = (*Backend)(nil)
 
 func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
-	b.saltMutex.RLock()
-	if b.salt != nil {
-		defer b.saltMutex.RUnlock()
-		return b.salt, nil
-	}
-	b.saltMutex.RUnlock()
-	b.saltMutex.Lock()
-	defer b.saltMutex.Unlock()
-	if b.salt != nil {
-		return b.salt, nil
-	}
-	salt, err := salt.NewSalt(ctx, b.saltView, b.saltConfig)
-	if err != nil {
-		return nil, err
-	}
-	b.salt = salt
-	return salt, nil
+	return b.salt.Get(ctx)
 }
 
 func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
@@ -183,11 +188,11 @@ func (b *Backend) LogRequest(ctx context.Context, in *audit.LogInput) error {
 		return b.formatter.FormatRequest(ctx, ioutil.Discard, b.formatConfig, in)
 	}
 
-	if err := b.open(); err != nil {
+	if err := b.openForWrite(); err != nil {
 		return err
 	}
 
-	if err := b.formatter.FormatRequest(ctx, b.f, b.formatConfig, in); err == nil {
+	if err := b.formatter.FormatRequest(ctx, b.writer(), b.formatConfig, in); err == nil {
 		return nil
 	}
 
@@ -199,7 +204,7 @@ func (b *Backend) LogRequest(ctx context.Context, in *audit.LogInput) error {
 		return err
 	}
 
-	return b.formatter.FormatRequest(ctx, b.f, b.formatConfig, in)
+	return b.formatter.FormatRequest(ctx, b.writer(), b.formatConfig, in)
 }
 
 func (b *Backend) LogResponse(ctx context.Context, in *audit.LogInput) error {
@@ -214,11 +219,11 @@ func (b *Backend) LogResponse(ctx context.Context, in *audit.LogInput) error {
 		return b.formatter.FormatResponse(ctx, ioutil.Discard, b.formatConfig, in)
 	}
 
-	if err := b.open(); err != nil {
+	if err := b.openForWrite(); err != nil {
 		return err
 	}
 
-	if err := b.formatter.FormatResponse(ctx, b.f, b.formatConfig, in); err == nil {
+	if err := b.formatter.FormatResponse(ctx, b.writer(), b.formatConfig, in); err == nil {
 		return nil
 	}
 
@@ -230,7 +235,7 @@ func (b *Backend) LogResponse(ctx context.Context, in *audit.LogInput) error {
 		return err
 	}
 
-	return b.formatter.FormatResponse(ctx, b.f, b.formatConfig, in)
+	return b.formatter.FormatResponse(ctx, b.writer(), b.formatConfig, in)
 }
 
 // The file lock must be held before calling this
@@ -248,6 +253,19 @@ func (b *Backend) open() error {
 		return err
 	}
 
+	info, err := b.f.Stat()
+	if err != nil {
+		return err
+	}
+	b.size = info.Size()
+
+	// Reopening the same file, e.g. on reload, keeps its age, so that
+	// reloads don't postpone its rotation
+	if b.info == nil || !os.SameFile(b.info, info) {
+		b.openedAt = b.createdAt()
+	}
+	b.info = info
+
 	// Change the file mode in case the log file already existed. We special
 	// case /dev/null since we can't chmod it and bypass if the mode is zero
 	switch b.path {
@@ -264,6 +282,24 @@ func (b *Backend) open() error {
 	return nil
 }
 
+// openForWrite opens the file if needed and rotates it if it is due. The
+// file lock must be held before calling this.
+func (b *Backend) openForWrite() error {
+	if err := b.open(); err != nil {
+		return err
+	}
+	if b.shouldRotate() {
+		return b.rotateFile()
+	}
+	return nil
+}
+
+// writer returns a writer for the open file that tracks its size. The file
+// lock must be held before calling this.
+func (b *Backend) writer() io.Writer {
+	return &countingWriter{w: b.f, n: &b.size}
+}
+
 func (b *Backend) Reload(_ context.Context) error {
 	switch b.path {
 	case "stdout", "discard":
@@ -289,7 +325,5 @@ func (b *Backend) Reload(_ context.Context) error {
 }
 
 func (b *Backend) Invalidate(_ context.Context) {
-	b.saltMutex.Lock()
-	defer b.saltMutex.Unlock()
-	b.salt = nil
+	b.salt.Reset()
 }
@@ -1,14 +1,19 @@
 package file
 
 import (
+	"compress/gzip"
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -90,3 +95,217 @@ func TestAuditFile_fileModeExisting(t *testing.T) {
 		t.Fatalf("File mode does not match.")
 	}
 }
+
+func TestAuditFile_rotate(t *testing.T) {
+	path, err := ioutil.TempDir("", "vault-test_audit_file-rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	file := filepath.Join(path, "audit.log")
+
+	backend, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config: map[string]string{
+			"path":             file,
+			"rotate_bytes":     "1",
+			"rotate_max_files": "2",
+			"rotate_compress":  "true",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := backend.(*Backend)
+
+	for i := 0; i < 5; i++ {
+		err := b.LogRequest(namespace.RootContext(nil), &audit.LogInput{
+			Request: &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "secret/" + strconv.Itoa(i),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.rotateWg.Wait()
+
+	// Every entry after the first rotates the file, and only the two most
+	// recent rotated files are kept
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rotated []string
+	for _, entry := range entries {
+		if entry.Name() != "audit.log" {
+			rotated = append(rotated, entry.Name())
+		}
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", rotated)
+	}
+
+	for i, name := range rotated {
+		if !strings.HasSuffix(name, ".gz") {
+			t.Fatalf("expected %q to be compressed", name)
+		}
+		f, err := os.Open(filepath.Join(path, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(contents), fmt.Sprintf(`"path":"secret/%d"`, i+2)) {
+			t.Fatalf("unexpected contents of %q: %s", name, contents)
+		}
+	}
+
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), `"path":"secret/4"`) {
+		t.Fatalf("unexpected contents of current file: %s", contents)
+	}
+}
+
+func TestAuditFile_rotateExistingFile(t *testing.T) {
+	path, err := ioutil.TempDir("", "vault-test_audit_file-rotate_existing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	// A file created by a previous run more than rotate_duration ago, and
+	// written to until the restart
+	file := filepath.Join(path, "audit.log")
+	if err := ioutil.WriteFile(file, []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	if err := ioutil.WriteFile(createdStampPath(file), []byte(created+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := testRotatingBackend(t, file, "1h")
+	testLogRequest(t, b, "secret/foo")
+	b.rotateWg.Wait()
+
+	if rotated := testRotatedFiles(t, path); len(rotated) != 1 {
+		t.Fatalf("expected the existing file to be rotated, got %v", rotated)
+	}
+
+	// The new file is stamped with its own creation time, so a restart
+	// doesn't rotate it again
+	b = testRotatingBackend(t, file, "1h")
+	testLogRequest(t, b, "secret/bar")
+	b.rotateWg.Wait()
+
+	if rotated := testRotatedFiles(t, path); len(rotated) != 1 {
+		t.Fatalf("expected the new file not to be rotated, got %v", rotated)
+	}
+}
+
+func TestAuditFile_rotateAfterReload(t *testing.T) {
+	path, err := ioutil.TempDir("", "vault-test_audit_file-rotate_reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	file := filepath.Join(path, "audit.log")
+	b := testRotatingBackend(t, file, "1h")
+	testLogRequest(t, b, "secret/foo")
+
+	// The file is due once the duration has passed, even though it was
+	// written to right before the reload
+	b.fileLock.Lock()
+	b.openedAt = b.openedAt.Add(-2 * time.Hour)
+	b.fileLock.Unlock()
+
+	if err := b.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	testLogRequest(t, b, "secret/bar")
+	b.rotateWg.Wait()
+
+	if rotated := testRotatedFiles(t, path); len(rotated) != 1 {
+		t.Fatalf("expected the file to be rotated after the reload, got %v", rotated)
+	}
+}
+
+func testRotatingBackend(t *testing.T, file, duration string) *Backend {
+	t.Helper()
+	backend, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config: map[string]string{
+			"path":            file,
+			"rotate_duration": duration,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend.(*Backend)
+}
+
+func testLogRequest(t *testing.T, b *Backend, path string) {
+	t.Helper()
+	err := b.LogRequest(namespace.RootContext(nil), &audit.LogInput{
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      path,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testRotatedFiles returns the names of the rotated files in the directory
+func testRotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rotated []string
+	for _, entry := range entries {
+		if rotatedFileSuffixRe.MatchString(strings.TrimPrefix(entry.Name(), "audit.log")) {
+			rotated = append(rotated, entry.Name())
+		}
+	}
+	return rotated
+}
+
+func TestAuditFile_rotateConfig(t *testing.T) {
+	for _, config := range []map[string]string{
+		{"path": "stdout", "rotate_bytes": "1024"},
+		{"path": "discard", "rotate_duration": "24h"},
+		{"path": "audit.log", "rotate_bytes": "-1"},
+		{"path": "audit.log", "rotate_duration": "soon"},
+		{"path": "audit.log", "rotate_max_files": "many"},
+		{"path": "audit.log", "rotate_compress": "yes please"},
+	} {
+		_, err := Factory(context.Background(), &audit.BackendConfig{
+			SaltConfig: &salt.Config{},
+			SaltView:   &logical.InmemStorage{},
+			Config:     config,
+		})
+		if err == nil {
+			t.Fatalf("expected an error for %v", config)
+		}
+	}
+}
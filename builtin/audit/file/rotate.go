@@ -0,0 +1,257 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
+)
+
+// rotatedFileTimeFormat is the format of the timestamp appended to the names
+// of rotated files. It sorts lexically in chronological order.
+const rotatedFileTimeFormat = "20060102T150405.000000000Z"
+
+var rotatedFileSuffixRe = regexp.MustCompile(`^\.\d{8}T\d{6}\.\d{9}Z(\.gz)?$`)
+
+// rotateConfig holds the log rotation settings of a file audit backend
+type rotateConfig struct {
+	// bytes is the size after which the file is rotated, or zero
+	bytes int64
+
+	// duration is the time after which the file is rotated, or zero
+	duration time.Duration
+
+	// maxFiles is the number of rotated files to keep, or zero to keep all
+	maxFiles int
+
+	// compress is whether to gzip rotated files
+	compress bool
+}
+
+func (c rotateConfig) enabled() bool {
+	return c.bytes > 0 || c.duration > 0
+}
+
+func parseRotateConfig(config map[string]string) (rotateConfig, error) {
+	var rc rotateConfig
+
+	if raw, ok := config["rotate_bytes"]; ok {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			return rc, fmt.Errorf("rotate_bytes must be a non-negative number of bytes")
+		}
+		rc.bytes = value
+	}
+
+	if raw, ok := config["rotate_duration"]; ok {
+		value, err := parseutil.ParseDurationSecond(raw)
+		if err != nil {
+			return rc, errwrap.Wrapf("error parsing rotate_duration: {{err}}", err)
+		}
+		if value < 0 {
+			return rc, fmt.Errorf("rotate_duration must not be negative")
+		}
+		rc.duration = value
+	}
+
+	if raw, ok := config["rotate_max_files"]; ok {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return rc, fmt.Errorf("rotate_max_files must be a non-negative number")
+		}
+		rc.maxFiles = value
+	}
+
+	if raw, ok := config["rotate_compress"]; ok {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return rc, errwrap.Wrapf("error parsing rotate_compress: {{err}}", err)
+		}
+		rc.compress = value
+	}
+
+	return rc, nil
+}
+
+// countingWriter counts the bytes written to the current file
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// createdStampPath returns the path of the file recording when the audit log
+// at path was created. The modification time of the audit log moves with
+// every entry, so it can't date the file across restarts.
+func createdStampPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".created")
+}
+
+// createdAt returns when the newly opened file was created, as recorded by
+// its stamp. Empty files and files without a valid stamp, e.g. ones written
+// before time-based rotation was enabled, are dated now and stamped. The
+// file lock must be held before calling this.
+func (b *Backend) createdAt() time.Time {
+	now := time.Now()
+	if b.rotate.duration == 0 {
+		return now
+	}
+
+	stamp := createdStampPath(b.path)
+	if b.size > 0 {
+		if raw, err := ioutil.ReadFile(stamp); err == nil {
+			if created, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(raw))); err == nil {
+				return created
+			}
+		}
+	}
+
+	mode := b.mode
+	if mode == 0 {
+		mode = 0600
+	}
+	if err := ioutil.WriteFile(stamp, []byte(now.UTC().Format(time.RFC3339Nano)+"\n"), mode); err != nil {
+		// Without the stamp, the next restart dates the file anew
+		b.logger.Warn("failed to record the creation time of the audit log", "file", stamp, "error", err)
+	}
+	return now
+}
+
+// The file lock must be held before calling this
+func (b *Backend) shouldRotate() bool {
+	if !b.rotate.enabled() || b.f == nil || b.size == 0 {
+		return false
+	}
+	if b.rotate.bytes > 0 && b.size >= b.rotate.bytes {
+		return true
+	}
+	if b.rotate.duration > 0 && time.Since(b.openedAt) >= b.rotate.duration {
+		return true
+	}
+	return false
+}
+
+// rotateFile moves the current file aside and opens a new one. Compressing
+// the rotated file and removing old ones happens in the background, so
+// entries aren't held up by it. The file lock must be held before calling
+// this.
+func (b *Backend) rotateFile() error {
+	if err := b.f.Close(); err != nil {
+		return err
+	}
+	b.f = nil
+
+	rotated := b.path + "." + time.Now().UTC().Format(rotatedFileTimeFormat)
+	if err := os.Rename(b.path, rotated); err != nil {
+		return errwrap.Wrapf("failed to rotate audit log: {{err}}", err)
+	}
+
+	b.rotateWg.Add(1)
+	go func() {
+		defer b.rotateWg.Done()
+
+		b.rotateLock.Lock()
+		defer b.rotateLock.Unlock()
+
+		if b.rotate.compress {
+			// Failures leave the uncompressed file in place, which is
+			// still picked up by retention
+			if err := compressFile(rotated, b.mode); err != nil {
+				b.logger.Error("failed to compress rotated audit log", "file", rotated, "error", err)
+			}
+		}
+		if b.rotate.maxFiles > 0 {
+			if err := removeRotatedFiles(b.path, b.rotate.maxFiles); err != nil {
+				b.logger.Error("failed to remove old rotated audit logs", "error", err)
+			}
+		}
+	}()
+
+	return b.open()
+}
+
+// compressFile gzips the given file, replacing it
+func compressFile(path string, mode os.FileMode) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if mode == 0 {
+		mode = 0600
+	}
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// removeRotatedFiles removes all but the newest maxFiles rotated files of
+// the audit log at path
+func removeRotatedFiles(path string, maxFiles int) error {
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	base := filepath.Base(path)
+	var rotated []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+		if rotatedFileSuffixRe.MatchString(strings.TrimPrefix(name, base)) {
+			rotated = append(rotated, name)
+		}
+	}
+	if len(rotated) <= maxFiles {
+		return nil
+	}
+
+	// Names only differ in their timestamp and compression suffix, so they
+	// sort chronologically
+	sort.Strings(rotated)
+	for _, name := range rotated[:len(rotated)-maxFiles] {
+		if err := os.Remove(filepath.Join(filepath.Dir(path), name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,12 +5,10 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"sync"
 
 	gsyslog "github.com/hashicorp/go-syslog"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/sdk/helper/salt"
-	"github.com/hashicorp/vault/sdk/logical"
 )
 
 func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, error) {
@@ -70,9 +68,8 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 	}
 
 	b := &Backend{
-		logger:     logger,
-		saltConfig: conf.SaltConfig,
-		saltView:   conf.SaltView,
+		logger: logger,
+		salt:   salt.NewLazySalt(conf.SaltView, conf.SaltConfig),
 		formatConfig: audit.FormatterConfig{
 			Raw:          logRaw,
 			HMACAccessor: hmacAccessor,
@@ -102,10 +99,7 @@ type Backend struct {
 	formatter    audit.AuditFormatter
 	formatConfig audit.FormatterConfig
 
-	saltMutex  sync.RWMutex
-	salt       *salt.Salt
-	saltConfig *salt.Config
-	saltView   logical.Storage
+	salt *salt.LazySalt
 }
 
 var _ audit.Backend = (*Backend)(nil)
@@ -145,27 +139,9 @@ func (b *Backend) Reload(_ context.Context) error {
 }
 
 func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
-	b.saltMutex.RLock()
-	if b.salt != nil {
-		defer b.saltMutex.RUnlock()
-		return b.salt, nil
-	}
-	b.saltMutex.RUnlock()
-	b.saltMutex.Lock()
-	defer b.saltMutex.Unlock()
-	if b.salt != nil {
-		return b.salt, nil
-	}
-	salt, err := salt.NewSalt(ctx, b.saltView, b.saltConfig)
-	if err != nil {
-		return nil, err
-	}
-	b.salt = salt
-	return salt, nil
+	return b.salt.Get(ctx)
 }
 
 func (b *Backend) Invalidate(_ context.Context) {
-	b.saltMutex.Lock()
-	defer b.saltMutex.Unlock()
-	b.salt = nil
+	b.salt.Reset()
 }
@@ -986,6 +986,25 @@ func TestBackend_PathBlacklistRoleTag(t *testing.T) {
 		t.Fatalf("failed to read the blacklisted role tag:%s. Err: %s\n", tag, resp.Data["error"])
 	}
 
+	// tokens issued using the blacklisted role tag should not be renewable
+	_, err = b.pathLoginRenew(context.Background(), &logical.Request{
+		Operation: logical.RenewOperation,
+		Storage:   storage,
+		Auth: &logical.Auth{
+			Metadata: map[string]string{
+				"auth_type":   ec2AuthType,
+				"instance_id": "i-abcd123",
+				"region":      "us-east-1",
+			},
+			InternalData: map[string]interface{}{
+				"role_tag": tag,
+			},
+		},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "role tag is blacklisted") {
+		t.Fatalf("expected renewal to fail for the blacklisted role tag, got: %v", err)
+	}
+
 	// delete the blacklisted entry
 	_, err = b.HandleRequest(context.Background(), &logical.Request{
 		Operation: logical.DeleteOperation,
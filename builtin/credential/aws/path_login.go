@@ -831,10 +831,21 @@ func (b *backend) pathLoginUpdateEc2(ctx context.Context, req *logical.Request,
 			},
 			Alias: &logical.Alias{
 				Name: identityAlias,
+				Metadata: map[string]string{
+					logical.AliasMetadataKeyUpstreamID: identityDocParsed.InstanceID,
+				},
 			},
 		},
 	}
 
+	// Cache the role tag so that renewals can be refused once it is
+	// blacklisted
+	if roleTagResp != nil {
+		resp.Auth.InternalData = map[string]interface{}{
+			"role_tag": roleTagResp.Value,
+		}
+	}
+
 	// Return the nonce only if reauthentication is allowed and if the nonce
 	// was not supplied by the user.
 	if !disallowReauthentication && !clientNonceSupplied {
@@ -914,6 +925,7 @@ func (b *backend) handleRoleTagLogin(ctx context.Context, s logical.Storage, rol
 	}
 
 	return &roleTagLoginResponse{
+		Value:                    rTagValue,
 		Policies:                 rTag.Policies,
 		MaxTTL:                   rTag.MaxTTL,
 		DisallowReauthentication: rTag.DisallowReauthentication,
@@ -1064,6 +1076,19 @@ func (b *backend) pathLoginRenewEc2(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	// If the login was made using the role tag, ensure that it was not
+	// blacklisted since. Tokens issued before the role tag was cached are
+	// not checked.
+	if rTagValue, ok := req.Auth.InternalData["role_tag"].(string); ok && rTagValue != "" {
+		blacklistEntry, err := b.lockedBlacklistRoleTagEntry(ctx, req.Storage, rTagValue)
+		if err != nil {
+			return nil, err
+		}
+		if blacklistEntry != nil {
+			return nil, fmt.Errorf("role tag is blacklisted")
+		}
+	}
+
 	// Cross check that the instance is still in 'running' state
 	_, err := b.validateInstance(ctx, req.Storage, instanceID, region, accountID)
 	if err != nil {
@@ -1344,6 +1369,9 @@ func (b *backend) pathLoginUpdateIam(ctx context.Context, req *logical.Request,
 			},
 			Alias: &logical.Alias{
 				Name: identityAlias,
+				Metadata: map[string]string{
+					logical.AliasMetadataKeyUpstreamID: callerUniqueId,
+				},
 			},
 		},
 	}
@@ -1581,6 +1609,7 @@ type identityDocument struct {
 // roleTagLoginResponse represents the return values required after the process
 // of verifying a role tag login
 type roleTagLoginResponse struct {
+	Value                    string        `json:"value"`
 	Policies                 []string      `json:"policies"`
 	MaxTTL                   time.Duration `json:"max_ttl"`
 	DisallowReauthentication bool          `json:"disallow_reauthentication"`
@@ -759,6 +759,128 @@ func TestBackend_RegisteredNonCA_CRL(t *testing.T) {
 	}
 }
 
+func TestBackend_TrustedNonCAs(t *testing.T) {
+	config := logical.TestBackendConfig()
+	storage := &logical.InmemStorage{}
+	config.StorageView = storage
+
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorityKeyID := []byte{1, 2, 3, 4}
+	generateKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	generateCert := func(key *ecdsa.PrivateKey, serial int64, commonName string, notAfter time.Time) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber:   big.NewInt(serial),
+			Subject:        pkix.Name{CommonName: commonName},
+			AuthorityKeyId: authorityKeyID,
+			ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			KeyUsage:       x509.KeyUsageDigitalSignature,
+			NotBefore:      time.Now().Add(-2 * time.Hour),
+			NotAfter:       notAfter,
+		}
+		certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(certBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	register := func(name string, data map[string]interface{}, certs ...*x509.Certificate) {
+		var certsPEM string
+		for _, cert := range certs {
+			certsPEM += string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+		}
+		data["certificate"] = certsPEM
+		data["policies"] = "abc"
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "certs/" + name,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+	}
+
+	validUntil := time.Now().Add(time.Hour)
+	key1, key2, expiredKey := generateKey(), generateKey(), generateKey()
+	cert1, cert2 := generateCert(key1, 1, "example.com", validUntil), generateCert(key2, 2, "example.com", validUntil)
+
+	// Both non-CA certificates of the entry are trusted directly
+	register("pinned", map[string]interface{}{
+		"allowed_common_names": "example.com",
+	}, cert1, cert2)
+	// The constraints are checked against the registered certificate
+	register("other-name", map[string]interface{}{
+		"allowed_common_names": "other.example.com",
+	}, cert1)
+	// So is the validity period
+	register("expired", map[string]interface{}{}, generateCert(expiredKey, 3, "example.com", time.Now().Add(-time.Hour)))
+
+	login := func(name string, cert *x509.Certificate) *logical.Response {
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "login",
+			Storage:   storage,
+			Connection: &logical.Connection{
+				ConnState: &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{cert},
+				},
+			},
+			Data: map[string]interface{}{
+				"name": name,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for name, tc := range map[string]struct {
+		entry   string
+		cert    *x509.Certificate
+		allowed bool
+	}{
+		"first":  {"pinned", cert1, true},
+		"second": {"pinned", cert2, true},
+		// A certificate reissued for a trusted key
+		"reissued": {"pinned", generateCert(key1, 99, "example.com", validUntil), true},
+		// A certificate reissued for a trusted key with another name, which
+		// doesn't matter as the registered certificate is allowed
+		"reissued other name": {"pinned", generateCert(key1, 99, "other.example.com", validUntil), true},
+		// A certificate reissued with the allowed name, which isn't the name
+		// of the registered certificate
+		"reissued allowed name": {"other-name", generateCert(key1, 99, "other.example.com", validUntil), false},
+		// A valid certificate reissued for the key of an expired one
+		"reissued expired": {"expired", generateCert(expiredKey, 99, "example.com", validUntil), false},
+		// A certificate copying the serial number and authority key ID of
+		// a trusted one, but with another key
+		"forged": {"pinned", generateCert(generateKey(), 1, "example.com", validUntil), false},
+	} {
+		resp := login(tc.entry, tc.cert)
+		switch {
+		case tc.allowed && (resp == nil || resp.IsError() || resp.Auth == nil):
+			t.Fatalf("%s: expected login to succeed, got: %#v", name, resp)
+		case !tc.allowed && (resp == nil || !resp.IsError()):
+			t.Fatalf("%s: expected login to fail, got: %#v", name, resp)
+		}
+	}
+}
+
 func TestBackend_CRLs(t *testing.T) {
 	config := logical.TestBackendConfig()
 	storage := &logical.InmemStorage{}
@@ -1163,8 +1285,8 @@ func TestBackend_email_singleCert(t *testing.T) {
 		Subject: pkix.Name{
 			CommonName: "example.com",
 		},
-		EmailAddresses:    []string{"valid@example.com"},
-		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		EmailAddresses: []string{"valid@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("127.0.0.1")},
 		ExtKeyUsage: []x509.ExtKeyUsage{
 			x509.ExtKeyUsageServerAuth,
 			x509.ExtKeyUsageClientAuth,
@@ -39,7 +39,8 @@ func pathCerts(b *backend) *framework.Path {
 			"certificate": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `The public certificate that should be trusted.
-Must be x509 PEM encoded.`,
+Must be x509 PEM encoded. Either CA certificates, or non-CA certificates
+trusted directly by their public key.`,
 			},
 
 			"allowed_names": &framework.FieldSchema{
@@ -267,17 +268,23 @@ func (b *backend) pathCertWrite(ctx context.Context, req *logical.Request, d *fr
 		return logical.ErrorResponse("failed to parse certificate"), nil
 	}
 
-	// If the certificate is not a CA cert, then ensure that x509.ExtKeyUsageClientAuth is set
-	if !parsed[0].IsCA && parsed[0].ExtKeyUsage != nil {
-		var clientAuth bool
-		for _, usage := range parsed[0].ExtKeyUsage {
-			if usage == x509.ExtKeyUsageClientAuth || usage == x509.ExtKeyUsageAny {
-				clientAuth = true
-				break
+	// If the certificates are not CA certs, they are trusted directly; ensure
+	// that x509.ExtKeyUsageClientAuth is set on each of them
+	if !parsed[0].IsCA {
+		for _, cert := range parsed {
+			if cert.IsCA || cert.ExtKeyUsage == nil {
+				continue
+			}
+			var clientAuth bool
+			for _, usage := range cert.ExtKeyUsage {
+				if usage == x509.ExtKeyUsageClientAuth || usage == x509.ExtKeyUsageAny {
+					clientAuth = true
+					break
+				}
+			}
+			if !clientAuth {
+				return logical.ErrorResponse("non-CA certificates should have TLS client authentication set as an extended key usage"), nil
 			}
-		}
-		if !clientAuth {
-			return logical.ErrorResponse("non-CA certificates should have TLS client authentication set as an extended key usage"), nil
 		}
 	}
 
@@ -344,7 +351,9 @@ Manage trusted certificates used for authentication.
 
 const pathCertHelpDesc = `
 This endpoint allows you to create, read, update, and delete trusted certificates
-that are allowed to authenticate.
+that are allowed to authenticate. CA certificates allow the certificates they
+issue to authenticate; non-CA certificates are trusted directly, allowing client
+certificates with the same public key to authenticate.
 
 Deleting a certificate will not revoke auth for prior authenticated connections.
 To do this, do a revoke on "login". If you don't need to revoke login immediately,
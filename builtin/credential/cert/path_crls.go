@@ -2,7 +2,6 @@ package cert
 
 import (
 	"context"
-	"crypto/x509"
 	"fmt"
 	"math/big"
 	"strings"
@@ -191,7 +190,7 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 	}
 	crl := d.Get("crl").(string)
 
-	certList, err := x509.ParseCRL([]byte(crl))
+	certList, err := certutil.ParseCRL([]byte(crl))
 	if err != nil {
 		return logical.ErrorResponse(fmt.Sprintf("failed to parse CRL: %v", err)), nil
 	}
@@ -1,8 +1,8 @@
 package cert
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/asn1"
@@ -11,10 +11,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/hashicorp/vault/sdk/helper/cidrutil"
@@ -200,16 +202,25 @@ func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, d
 		return nil, nil, err
 	}
 
-	// If trustedNonCAs is not empty it means that client had registered a non-CA cert
-	// with the backend.
+	// If trustedNonCAs is not empty it means that client had registered non-CA certs
+	// with the backend. These are trusted directly: the client cert must have
+	// the same public key as one of them, which the TLS handshake proved the
+	// client holds. As the client can issue itself any cert for that key, the
+	// names, extensions and validity period checked are those of the
+	// registered cert.
 	if len(trustedNonCAs) != 0 {
+		clientSPKIHash := sha256.Sum256(clientCert.RawSubjectPublicKeyInfo)
+		now := time.Now()
 		for _, trustedNonCA := range trustedNonCAs {
-			tCert := trustedNonCA.Certificates[0]
-			// Check for client cert being explicitly listed in the config (and matching other constraints)
-			if tCert.SerialNumber.Cmp(clientCert.SerialNumber) == 0 &&
-				bytes.Equal(tCert.AuthorityKeyId, clientCert.AuthorityKeyId) &&
-				b.matchesConstraints(clientCert, trustedNonCA.Certificates, trustedNonCA) {
-				return trustedNonCA, nil, nil
+			for _, tCert := range trustedNonCA.Certificates {
+				if tCert.IsCA || now.Before(tCert.NotBefore) || now.After(tCert.NotAfter) {
+					continue
+				}
+				// Check for client cert being explicitly listed in the config (and matching other constraints)
+				if sha256.Sum256(tCert.RawSubjectPublicKeyInfo) == clientSPKIHash &&
+					b.matchesConstraints(tCert, []*x509.Certificate{clientCert, tCert}, trustedNonCA) {
+					return trustedNonCA, nil, nil
+				}
 			}
 		}
 	}
@@ -264,25 +275,9 @@ func (b *backend) matchesNames(clientCert *x509.Certificate, config *ParsedCert)
 		return true
 	}
 	// At least one pattern must match at least one name if any patterns are specified
-	for _, allowedName := range config.Entry.AllowedNames {
-		if glob.Glob(allowedName, clientCert.Subject.CommonName) {
-			return true
-		}
-
-		for _, name := range clientCert.DNSNames {
-			if glob.Glob(allowedName, name) {
-				return true
-			}
-		}
-
-		for _, name := range clientCert.EmailAddresses {
-			if glob.Glob(allowedName, name) {
-				return true
-			}
-		}
-
-	}
-	return false
+	return strutil.StrListContainsGlob(config.Entry.AllowedNames, clientCert.Subject.CommonName) ||
+		strutil.StrListContainsAnyGlob(config.Entry.AllowedNames, clientCert.DNSNames) ||
+		strutil.StrListContainsAnyGlob(config.Entry.AllowedNames, clientCert.EmailAddresses)
 }
 
 // matchesCommonName verifies that the certificate matches at least one configured
@@ -293,13 +288,7 @@ func (b *backend) matchesCommonName(clientCert *x509.Certificate, config *Parsed
 		return true
 	}
 	// At least one pattern must match at least one name if any patterns are specified
-	for _, allowedCommonName := range config.Entry.AllowedCommonNames {
-		if glob.Glob(allowedCommonName, clientCert.Subject.CommonName) {
-			return true
-		}
-	}
-
-	return false
+	return strutil.StrListContainsGlob(config.Entry.AllowedCommonNames, clientCert.Subject.CommonName)
 }
 
 // matchesDNSSANs verifies that the certificate matches at least one configured
@@ -310,15 +299,7 @@ func (b *backend) matchesDNSSANs(clientCert *x509.Certificate, config *ParsedCer
 		return true
 	}
 	// At least one pattern must match at least one name if any patterns are specified
-	for _, allowedDNS := range config.Entry.AllowedDNSSANs {
-		for _, name := range clientCert.DNSNames {
-			if glob.Glob(allowedDNS, name) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return strutil.StrListContainsAnyGlob(config.Entry.AllowedDNSSANs, clientCert.DNSNames)
 }
 
 // matchesEmailSANs verifies that the certificate matches at least one configured
@@ -329,15 +310,7 @@ func (b *backend) matchesEmailSANs(clientCert *x509.Certificate, config *ParsedC
 		return true
 	}
 	// At least one pattern must match at least one name if any patterns are specified
-	for _, allowedEmail := range config.Entry.AllowedEmailSANs {
-		for _, email := range clientCert.EmailAddresses {
-			if glob.Glob(allowedEmail, email) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return strutil.StrListContainsAnyGlob(config.Entry.AllowedEmailSANs, clientCert.EmailAddresses)
 }
 
 // matchesURISANs verifies that the certificate matches at least one configured
@@ -367,15 +340,7 @@ func (b *backend) matchesOrganizationalUnits(clientCert *x509.Certificate, confi
 	}
 
 	// At least one pattern must match at least one name if any patterns are specified
-	for _, allowedOrganizationalUnits := range config.Entry.AllowedOrganizationalUnits {
-		for _, ou := range clientCert.Subject.OrganizationalUnit {
-			if glob.Glob(allowedOrganizationalUnits, ou) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return strutil.StrListContainsAnyGlob(config.Entry.AllowedOrganizationalUnits, clientCert.Subject.OrganizationalUnit)
 }
 
 // matchesCertificateExtensions verifies that the certificate matches configured
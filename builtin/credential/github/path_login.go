@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/github"
@@ -90,6 +91,12 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *fra
 		},
 	}
 
+	if verifyResp.User.ID != nil {
+		resp.Auth.Alias.Metadata = map[string]string{
+			logical.AliasMetadataKeyUpstreamID: strconv.FormatInt(*verifyResp.User.ID, 10),
+		}
+	}
+
 	for _, teamName := range verifyResp.TeamNames {
 		if teamName == "" {
 			continue
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/vault/helper/mfa"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -39,6 +40,7 @@ func Backend() *backend {
 
 		Paths: append([]*framework.Path{
 			pathConfig(&b),
+			pathConfigRotateRoot(&b),
 			pathGroups(&b),
 			pathGroupsList(&b),
 			pathUsers(&b),
@@ -49,6 +51,7 @@ func Backend() *backend {
 
 		AuthRenew:   b.pathLoginRenew,
 		BackendType: logical.TypeCredential,
+		Clean:       b.cleanup,
 	}
 
 	return &b
@@ -56,6 +59,25 @@ func Backend() *backend {
 
 type backend struct {
 	*framework.Backend
+
+	poolOnce sync.Once
+	pool     *ldaputil.ConnectionPool
+}
+
+// connectionPool returns the pool of connections to the LDAP server, shared
+// by logins and credential rotations
+func (b *backend) connectionPool() *ldaputil.ConnectionPool {
+	b.poolOnce.Do(func() {
+		b.pool = ldaputil.NewConnectionPool(&ldaputil.Client{
+			Logger: b.Logger(),
+			LDAP:   ldaputil.NewLDAP(),
+		}, ldaputil.DefaultPoolMaxIdle, ldaputil.DefaultPoolIdleTimeout)
+	})
+	return b.pool
+}
+
+func (b *backend) cleanup(ctx context.Context) {
+	b.connectionPool().Close()
 }
 
 func (b *backend) Login(ctx context.Context, req *logical.Request, username string, password string) ([]string, *logical.Response, []string, error) {
@@ -77,7 +99,8 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		LDAP:   ldaputil.NewLDAP(),
 	}
 
-	c, err := ldapClient.DialLDAP(cfg)
+	pool := b.connectionPool()
+	c, err := pool.Get(cfg)
 	if err != nil {
 		return nil, logical.ErrorResponse(err.Error()), nil, nil
 	}
@@ -85,8 +108,16 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		return nil, logical.ErrorResponse("invalid connection returned from LDAP dial"), nil, nil
 	}
 
-	// Clean connection
-	defer c.Close()
+	// The connection is returned to the pool only once it is bound as the
+	// BindDN again, otherwise it is cleaned
+	var reusable bool
+	defer func() {
+		if reusable {
+			pool.Put(cfg, c)
+		} else {
+			c.Close()
+		}
+	}()
 
 	userBindDN, err := ldapClient.GetUserBindDN(cfg, c, username)
 	if err != nil {
@@ -136,6 +167,7 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	if err != nil {
 		return nil, logical.ErrorResponse(err.Error()), nil, nil
 	}
+	reusable = cfg.BindDN != "" && cfg.BindPassword != ""
 	if b.Logger().IsDebug() {
 		b.Logger().Debug("groups fetched from server", "num_server_groups", len(ldapGroups), "server_groups", ldapGroups)
 	}
@@ -190,6 +222,16 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	// Policies from each group may overlap
 	policies = strutil.RemoveDuplicates(policies, true)
 
+	// The alias carries the DN the user was bound with as its upstream ID
+	ldapResponse.Auth = &logical.Auth{
+		Alias: &logical.Alias{
+			Name: username,
+			Metadata: map[string]string{
+				logical.AliasMetadataKeyUpstreamID: userBindDN,
+			},
+		},
+	}
+
 	return policies, ldapResponse, allGroups, nil
 }
 
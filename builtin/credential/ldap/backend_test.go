@@ -398,6 +398,37 @@ func factory(t *testing.T) logical.Backend {
 	return b
 }
 
+func TestLdapAuthBackend_RotateRootRequiresBindCredentials(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	req := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Data: map[string]interface{}{
+			"url":    "ldap://127.0.0.1",
+			"userdn": "ou=users,dc=example,dc=com",
+		},
+		Storage: storage,
+	}
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+
+	req = &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/rotate-root",
+		Storage:   storage,
+	}
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error response when binddn and bindpass are not configured, got: %#v", resp)
+	}
+}
+
 func TestBackend_basic(t *testing.T) {
 	b := factory(t)
 
@@ -0,0 +1,103 @@
+package ldap
+
+import (
+	"context"
+
+	"github.com/go-ldap/ldap"
+	"github.com/hashicorp/errwrap"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/base62"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// rotatedBindPasswordLength is the length of the password generated when the
+// bind credentials are rotated.
+const rotatedBindPasswordLength = 32
+
+func pathConfigRotateRoot(b *backend) *framework.Path {
+	p := framework.PathRotateRoot("config/", b.pathConfigRotateRootUpdate)
+	p.HelpSynopsis = pathConfigRotateRootHelpSyn
+	p.HelpDescription = pathConfigRotateRootHelpDesc
+	return p
+}
+
+func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.Config(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return logical.ErrorResponse("ldap backend not configured"), nil
+	}
+	if cfg.BindDN == "" || cfg.BindPassword == "" {
+		return logical.ErrorResponse("cannot call config/rotate-root when either binddn or bindpass is empty"), nil
+	}
+
+	newPassword, err := base62.Random(rotatedBindPasswordLength)
+	if err != nil {
+		return nil, errwrap.Wrapf("error generating new bind password: {{err}}", err)
+	}
+
+	pool := b.connectionPool()
+	c, err := pool.Get(cfg)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if c == nil {
+		return logical.ErrorResponse("invalid connection returned from LDAP dial"), nil
+	}
+
+	// The connection is bound as the BindDN, so it can be reused once done
+	var reusable bool
+	defer func() {
+		if reusable {
+			pool.Put(cfg, c)
+		} else {
+			c.Close()
+		}
+	}()
+
+	if err := c.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		return nil, errwrap.Wrapf("error binding with current bind credentials: {{err}}", err)
+	}
+
+	modifyReq := ldap.NewModifyRequest(cfg.BindDN, nil)
+	modifyReq.Replace("userPassword", []string{newPassword})
+	if err := c.Modify(modifyReq); err != nil {
+		return nil, errwrap.Wrapf("error setting new bind password: {{err}}", err)
+	}
+	reusable = true
+
+	oldPassword := cfg.BindPassword
+	cfg.BindPassword = newPassword
+	entry, err := logical.StorageEntryJSON("config", cfg)
+	if err == nil {
+		err = req.Storage.Put(ctx, entry)
+	}
+	if err != nil {
+		// The new password is lost unless it is stored, so put the old one
+		// back on the server rather than locking Vault out of the account
+		err = errwrap.Wrapf("error saving new config: {{err}}", err)
+		rollbackReq := ldap.NewModifyRequest(cfg.BindDN, nil)
+		rollbackReq.Replace("userPassword", []string{oldPassword})
+		if rollbackErr := c.Modify(rollbackReq); rollbackErr != nil {
+			return nil, multierror.Append(err, errwrap.Wrapf("error restoring the previous bind password: {{err}}", rollbackErr))
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+const pathConfigRotateRootHelpSyn = `
+Request to rotate the LDAP bind credentials used by Vault
+`
+
+const pathConfigRotateRootHelpDesc = `
+This path generates a new password for the account configured as the binddn,
+sets it on the LDAP server by replacing the account's userPassword attribute
+and stores it as the new bindpass. If the new bindpass can't be stored, the
+previous password is set back on the LDAP server. It is only valid if both
+binddn and bindpass have been configured via the config endpoint.
+`
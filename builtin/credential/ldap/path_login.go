@@ -70,6 +70,14 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 
 	sort.Strings(policies)
 
+	// The alias, along with its metadata, is set by Login
+	alias := &logical.Alias{
+		Name: username,
+	}
+	if resp.Auth != nil && resp.Auth.Alias != nil {
+		alias = resp.Auth.Alias
+	}
+
 	resp.Auth = &logical.Auth{
 		Policies: policies,
 		Metadata: map[string]string{
@@ -82,9 +90,7 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 		LeaseOptions: logical.LeaseOptions{
 			Renewable: true,
 		},
-		Alias: &logical.Alias{
-			Name: username,
-		},
+		Alias: alias,
 	}
 
 	for _, groupName := range groupNames {
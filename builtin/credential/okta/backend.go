@@ -3,11 +3,15 @@ package okta
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/chrismalek/oktasdk-go/okta"
+	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/helper/mfa"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -45,8 +49,9 @@ func Backend() *backend {
 			mfa.MFAPaths(b.Backend, pathLogin(&b))...,
 		),
 
-		AuthRenew:   b.pathLoginRenew,
-		BackendType: logical.TypeCredential,
+		PeriodicFunc: b.periodicFunc,
+		AuthRenew:    b.pathLoginRenew,
+		BackendType:  logical.TypeCredential,
 	}
 
 	return &b
@@ -54,9 +59,18 @@ func Backend() *backend {
 
 type backend struct {
 	*framework.Backend
+
+	// groupMutex guards the group entries, which the group sync updates, and
+	// lastGroupSync
+	groupMutex sync.RWMutex
+
+	// lastGroupSync is when the groups were last synced with Okta
+	lastGroupSync time.Time
 }
 
-func (b *backend) Login(ctx context.Context, req *logical.Request, username string, password string) ([]string, *logical.Response, []string, error) {
+// Login authenticates the user with Okta. On renewal the user isn't
+// challenged for MFA again, as there is nobody to answer the challenge.
+func (b *backend) Login(ctx context.Context, req *logical.Request, username, password, totp string, renew bool) ([]string, *logical.Response, []string, error) {
 	cfg, err := b.Config(ctx, req.Storage)
 	if err != nil {
 		return nil, nil, nil, err
@@ -147,70 +161,135 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 			break
 		}
 
-		factorAvailable := false
+		// The credentials are still checked on renewal, but the MFA was
+		// performed at login
+		if renew {
+			result.Status = "SUCCESS"
+			break
+		}
 
-		var selectedFactor mfaFactor
-		// only okta push is currently supported
-		for _, v := range result.Embedded.Factors {
-			if v.Type == "push" && v.Provider == "OKTA" {
-				factorAvailable = true
-				selectedFactor = v
+		// Members of the bypass groups aren't challenged either
+		if len(cfg.BypassGroups) > 0 {
+			bypass, err := b.bypassesMFA(client, cfg, &result.Embedded.User)
+			if err != nil {
+				return nil, logical.ErrorResponse(fmt.Sprintf("okta failure retrieving groups: %v", err)), nil, nil
+			}
+			if bypass {
+				if b.Logger().IsDebug() {
+					b.Logger().Debug("bypassing mfa for member of a bypass group", "user", username)
+				}
+				result.Status = "SUCCESS"
+				break
 			}
 		}
 
-		if !factorAvailable {
-			return nil, logical.ErrorResponse("Okta Verify Push factor is required in order to perform MFA"), nil, nil
+		// Okta Verify push and TOTP (Okta Verify or Google Authenticator) are
+		// supported. The factors are copied, as result is decoded into again.
+		var pushFactor, totpFactor mfaFactor
+		for _, v := range result.Embedded.Factors {
+			switch {
+			case v.Type == "push" && v.Provider == "OKTA":
+				pushFactor = v
+			case v.Type == "token:software:totp":
+				totpFactor = v
+			}
 		}
 
-		requestPath := fmt.Sprintf("authn/factors/%s/verify", selectedFactor.Id)
-		payload := map[string]interface{}{
-			"stateToken": result.StateToken,
-		}
-		verifyReq, err := client.NewRequest("POST", requestPath, payload)
-		if err != nil {
-			return nil, nil, nil, err
+		// The hint given when the push can't be answered and a TOTP passcode
+		// could be used instead
+		totpHint := ""
+		if totpFactor.Id != "" {
+			totpHint = `; retry with a TOTP passcode in the "totp" field`
 		}
 
-		rsp, err := client.Do(verifyReq, &result)
-		if err != nil {
-			return nil, logical.ErrorResponse(fmt.Sprintf("Okta auth failed: %v", err)), nil, nil
-		}
-		if rsp == nil {
-			return nil, logical.ErrorResponse("okta auth backend unexpected failure"), nil, nil
-		}
-		for result.Status == "MFA_CHALLENGE" {
-			switch result.FactorResult {
-			case "WAITING":
-				verifyReq, err := client.NewRequest("POST", requestPath, payload)
-				if err != nil {
-					return nil, logical.ErrorResponse(fmt.Sprintf("okta auth failed creating verify request: %v", err)), nil, nil
-				}
-				rsp, err := client.Do(verifyReq, &result)
-				if err != nil {
-					return nil, logical.ErrorResponse(fmt.Sprintf("Okta auth failed checking loop: %v", err)), nil, nil
-				}
-				if rsp == nil {
-					return nil, logical.ErrorResponse("okta auth backend unexpected failure"), nil, nil
-				}
+		switch {
+		case totp != "":
+			if totpFactor.Id == "" {
+				return nil, logical.ErrorResponse("a TOTP factor is required in order to perform MFA with a passcode"), nil, nil
+			}
 
-				select {
-				case <-time.After(500 * time.Millisecond):
-					// Continue
-				case <-ctx.Done():
-					return nil, logical.ErrorResponse("exiting pending mfa challenge"), nil, nil
-				}
-			case "REJECTED":
+			verifyReq, err := client.NewRequest("POST", fmt.Sprintf("authn/factors/%s/verify", totpFactor.Id), map[string]interface{}{
+				"stateToken": result.StateToken,
+				"passCode":   totp,
+			})
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			rsp, err := client.Do(verifyReq, &result)
+			if err != nil {
+				return nil, logical.ErrorResponse(fmt.Sprintf("Okta auth failed: %v", err)), nil, nil
+			}
+			if rsp == nil {
+				return nil, logical.ErrorResponse("okta auth backend unexpected failure"), nil, nil
+			}
+			if result.Status != "SUCCESS" {
 				return nil, logical.ErrorResponse("multi-factor authentication denied"), nil, nil
-			case "TIMEOUT":
-				return nil, logical.ErrorResponse("failed to complete multi-factor authentication"), nil, nil
-			case "SUCCESS":
-				// Allowed
-			default:
-				if b.Logger().IsDebug() {
-					b.Logger().Debug("unhandled result status", "status", result.Status, "factorstatus", result.FactorResult)
+			}
+
+		case pushFactor.Id != "":
+			requestPath := fmt.Sprintf("authn/factors/%s/verify", pushFactor.Id)
+			payload := map[string]interface{}{
+				"stateToken": result.StateToken,
+			}
+			verifyReq, err := client.NewRequest("POST", requestPath, payload)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			rsp, err := client.Do(verifyReq, &result)
+			if err != nil {
+				return nil, logical.ErrorResponse(fmt.Sprintf("Okta auth failed: %v", err)), nil, nil
+			}
+			if rsp == nil {
+				return nil, logical.ErrorResponse("okta auth backend unexpected failure"), nil, nil
+			}
+
+			timeout := time.NewTimer(cfg.MFAPushTimeout)
+			defer timeout.Stop()
+
+			for result.Status == "MFA_CHALLENGE" {
+				switch result.FactorResult {
+				case "WAITING":
+					verifyReq, err := client.NewRequest("POST", requestPath, payload)
+					if err != nil {
+						return nil, logical.ErrorResponse(fmt.Sprintf("okta auth failed creating verify request: %v", err)), nil, nil
+					}
+					rsp, err := client.Do(verifyReq, &result)
+					if err != nil {
+						return nil, logical.ErrorResponse(fmt.Sprintf("Okta auth failed checking loop: %v", err)), nil, nil
+					}
+					if rsp == nil {
+						return nil, logical.ErrorResponse("okta auth backend unexpected failure"), nil, nil
+					}
+
+					select {
+					case <-time.After(500 * time.Millisecond):
+						// Continue
+					case <-timeout.C:
+						return nil, logical.ErrorResponse("timed out waiting for the Okta Verify push to be answered" + totpHint), nil, nil
+					case <-ctx.Done():
+						return nil, logical.ErrorResponse("exiting pending mfa challenge"), nil, nil
+					}
+				case "REJECTED":
+					return nil, logical.ErrorResponse("multi-factor authentication denied"), nil, nil
+				case "TIMEOUT":
+					return nil, logical.ErrorResponse("failed to complete multi-factor authentication" + totpHint), nil, nil
+				case "SUCCESS":
+					// Allowed
+				default:
+					if b.Logger().IsDebug() {
+						b.Logger().Debug("unhandled result status", "status", result.Status, "factorstatus", result.FactorResult)
+					}
+					return nil, logical.ErrorResponse("okta authentication failed"), nil, nil
 				}
-				return nil, logical.ErrorResponse("okta authentication failed"), nil, nil
 			}
+
+		case totpFactor.Id != "":
+			return nil, logical.ErrorResponse(`a TOTP passcode is required in the "totp" field in order to perform MFA`), nil, nil
+
+		default:
+			return nil, logical.ErrorResponse("Okta Verify Push or TOTP factor is required in order to perform MFA"), nil, nil
 		}
 
 	case "SUCCESS":
@@ -269,6 +348,7 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 
 	// Retrieve policies
 	var policies []string
+	b.groupMutex.RLock()
 	for _, groupName := range allGroups {
 		entry, _, err := b.Group(ctx, req.Storage, groupName)
 		if err != nil {
@@ -280,12 +360,23 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 			policies = append(policies, entry.Policies...)
 		}
 	}
+	b.groupMutex.RUnlock()
 
 	// Merge local Policies into Okta Policies
 	if user != nil && user.Policies != nil {
 		policies = append(policies, user.Policies...)
 	}
 
+	// The alias carries the Okta user ID as its upstream ID
+	oktaResponse.Auth = &logical.Auth{
+		Alias: &logical.Alias{
+			Name: username,
+			Metadata: map[string]string{
+				logical.AliasMetadataKeyUpstreamID: result.Embedded.User.ID,
+			},
+		},
+	}
+
 	return policies, oktaResponse, allGroups, nil
 }
 
@@ -307,6 +398,132 @@ func (b *backend) getOktaGroups(client *okta.Client, user *okta.User) ([]string,
 	return oktaGroups, nil
 }
 
+// bypassesMFA returns whether the user is a member of one of the groups that
+// aren't challenged for MFA
+func (b *backend) bypassesMFA(client *okta.Client, cfg *ConfigEntry, user *okta.User) (bool, error) {
+	if cfg.Token == "" {
+		return false, nil
+	}
+	oktaGroups, err := b.getOktaGroups(client, user)
+	if err != nil {
+		return false, err
+	}
+	for _, oktaGroup := range oktaGroups {
+		for _, bypassGroup := range cfg.BypassGroups {
+			if strings.EqualFold(oktaGroup, bypassGroup) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// periodicFunc of the backend will be invoked once a minute by the
+// RollbackManager. The groups are synced with Okta from it, once per
+// group_sync_interval.
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	if !b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary|consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	cfg, err := b.Config(ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if cfg == nil || cfg.Token == "" || cfg.GroupSyncInterval <= 0 {
+		return nil
+	}
+	b.groupMutex.Lock()
+	if time.Since(b.lastGroupSync) < cfg.GroupSyncInterval {
+		b.groupMutex.Unlock()
+		return nil
+	}
+	b.lastGroupSync = time.Now()
+	b.groupMutex.Unlock()
+
+	oktaGroups, rsp, err := cfg.OktaClient().Groups.ListWithFilter(&okta.GroupFilterOptions{
+		GetAllPages: true,
+	})
+	if err != nil {
+		return errwrap.Wrapf("failed to list okta groups: {{err}}", err)
+	}
+	if rsp == nil {
+		return fmt.Errorf("okta auth method unexpected failure")
+	}
+
+	return b.syncGroups(ctx, req.Storage, oktaGroups)
+}
+
+// syncGroups links the groups configured in Vault to the Okta groups of the
+// same name, by ID, and moves the configuration of the linked groups along
+// when they are renamed in Okta.
+func (b *backend) syncGroups(ctx context.Context, s logical.Storage, oktaGroups []okta.Group) error {
+	b.groupMutex.Lock()
+	defer b.groupMutex.Unlock()
+
+	oktaGroupsByID := make(map[string]okta.Group, len(oktaGroups))
+	for _, oktaGroup := range oktaGroups {
+		oktaGroupsByID[oktaGroup.ID] = oktaGroup
+	}
+
+	names, err := groupList(ctx, s)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		entry, _, err := b.Group(ctx, s, name)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			continue
+		}
+
+		if entry.OktaGroupID == "" {
+			for _, oktaGroup := range oktaGroups {
+				if strings.EqualFold(oktaGroup.Profile.Name, name) {
+					entry.OktaGroupID = oktaGroup.ID
+					if err := putGroup(ctx, s, name, entry); err != nil {
+						return err
+					}
+					break
+				}
+			}
+			continue
+		}
+
+		oktaGroup, ok := oktaGroupsByID[entry.OktaGroupID]
+		if !ok {
+			b.Logger().Warn("okta group no longer exists", "group", name, "okta_group_id", entry.OktaGroupID)
+			continue
+		}
+		if strings.EqualFold(oktaGroup.Profile.Name, name) {
+			continue
+		}
+
+		// Don't overwrite the configuration of a group of the new name
+		existing, newName, err := b.Group(ctx, s, oktaGroup.Profile.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			b.Logger().Warn("not moving the configuration of renamed okta group, as a group of the new name exists", "group", name, "new_name", newName)
+			continue
+		}
+
+		newName = strings.ToLower(oktaGroup.Profile.Name)
+		if err := putGroup(ctx, s, newName, entry); err != nil {
+			return err
+		}
+		if err := s.Delete(ctx, "group/"+name); err != nil {
+			return err
+		}
+		b.Logger().Info("moved the configuration of renamed okta group", "group", name, "new_name", newName)
+	}
+
+	return nil
+}
+
 const backendHelp = `
 The Okta credential provider allows authentication querying,
 checking username and password, and associating policies.  If an api token is
@@ -46,6 +46,10 @@ func (h *CLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, erro
 	if ok {
 		data["passcode"] = mfa_passcode
 	}
+	totp, ok := m["totp"]
+	if ok {
+		data["totp"] = totp
+	}
 
 	path := fmt.Sprintf("auth/%s/login/%s", mount, username)
 	secret, err := c.Logical().Write(path, data)
@@ -75,12 +79,20 @@ Usage: vault login -method=okta [CONFIG K=V...]
 
       $ vault login -method=okta username=bob password=password
 
+  Authenticate as "bob" with a TOTP passcode instead of an Okta Verify push:
+
+      $ vault login -method=okta username=bob totp=123456
+
 Configuration:
 
   password=<string>
       Okta password to use for authentication. If not provided, the CLI will
       prompt for this on stdin.
 
+  totp=<string>
+      TOTP passcode to verify, when MFA is required, instead of sending an
+      Okta Verify push.
+
   username=<string>
       Okta username to use for authentication.
 `
@@ -16,6 +16,10 @@ import (
 const (
 	defaultBaseURL = "okta.com"
 	previewBaseURL = "oktapreview.com"
+
+	// defaultMFAPushTimeout is how long logins wait for an Okta Verify push
+	// to be answered by default
+	defaultMFAPushTimeout = 60 * time.Second
 )
 
 func pathConfig(b *backend) *framework.Path {
@@ -65,6 +69,22 @@ func pathConfig(b *backend) *framework.Path {
 				Description: `When set true, requests by Okta for a MFA check will be bypassed. This also disallows certain status checks on the account, such as whether the password is expired.`,
 				DisplayName: "Bypass Okta MFA",
 			},
+			"bypass_groups": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: `Comma-separated list of Okta groups whose members are not challenged for MFA. Requires api_token.`,
+				DisplayName: "Bypass Groups",
+			},
+			"mfa_push_timeout": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Default:     int(defaultMFAPushTimeout.Seconds()),
+				Description: `Duration to wait for an Okta Verify push to be answered before failing the login.`,
+				DisplayName: "MFA Push Timeout",
+			},
+			"group_sync_interval": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: `Interval at which the groups configured in Vault are synced with Okta, following renamed groups. Sync is disabled when not set. Requires api_token.`,
+				DisplayName: "Group Sync Interval",
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -96,6 +116,11 @@ func (b *backend) Config(ctx context.Context, s logical.Storage) (*ConfigEntry,
 		}
 	}
 
+	// Configurations written before the push timeout was introduced
+	if result.MFAPushTimeout == 0 {
+		result.MFAPushTimeout = defaultMFAPushTimeout
+	}
+
 	return &result, nil
 }
 
@@ -110,11 +135,14 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, d *f
 
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"organization":    cfg.Org,
-			"org_name":        cfg.Org,
-			"ttl":             cfg.TTL.Seconds(),
-			"max_ttl":         cfg.MaxTTL.Seconds(),
-			"bypass_okta_mfa": cfg.BypassOktaMFA,
+			"organization":        cfg.Org,
+			"org_name":            cfg.Org,
+			"ttl":                 cfg.TTL.Seconds(),
+			"max_ttl":             cfg.MaxTTL.Seconds(),
+			"bypass_okta_mfa":     cfg.BypassOktaMFA,
+			"bypass_groups":       cfg.BypassGroups,
+			"mfa_push_timeout":    cfg.MFAPushTimeout.Seconds(),
+			"group_sync_interval": cfg.GroupSyncInterval.Seconds(),
 		},
 	}
 	if cfg.BaseURL != "" {
@@ -192,6 +220,36 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 		cfg.BypassOktaMFA = bypass.(bool)
 	}
 
+	bypassGroups, ok := d.GetOk("bypass_groups")
+	if ok {
+		cfg.BypassGroups = bypassGroups.([]string)
+	}
+
+	mfaPushTimeout, ok := d.GetOk("mfa_push_timeout")
+	if ok {
+		cfg.MFAPushTimeout = time.Duration(mfaPushTimeout.(int)) * time.Second
+	} else if req.Operation == logical.CreateOperation {
+		cfg.MFAPushTimeout = time.Duration(d.Get("mfa_push_timeout").(int)) * time.Second
+	}
+	if cfg.MFAPushTimeout <= 0 {
+		return logical.ErrorResponse("mfa_push_timeout must be greater than zero"), nil
+	}
+
+	groupSyncInterval, ok := d.GetOk("group_sync_interval")
+	if ok {
+		cfg.GroupSyncInterval = time.Duration(groupSyncInterval.(int)) * time.Second
+	}
+
+	// Group memberships can only be read from Okta with an API token
+	if cfg.Token == "" {
+		if len(cfg.BypassGroups) > 0 {
+			return logical.ErrorResponse("api_token is required to set bypass_groups"), nil
+		}
+		if cfg.GroupSyncInterval > 0 {
+			return logical.ErrorResponse("api_token is required to set group_sync_interval"), nil
+		}
+	}
+
 	ttl, ok := d.GetOk("ttl")
 	if ok {
 		cfg.TTL = time.Duration(ttl.(int)) * time.Second
@@ -258,6 +316,10 @@ type ConfigEntry struct {
 	TTL           time.Duration `json:"ttl"`
 	MaxTTL        time.Duration `json:"max_ttl"`
 	BypassOktaMFA bool          `json:"bypass_okta_mfa"`
+
+	BypassGroups      []string      `json:"bypass_groups"`
+	MFAPushTimeout    time.Duration `json:"mfa_push_timeout"`
+	GroupSyncInterval time.Duration `json:"group_sync_interval"`
 }
 
 const pathConfigHelp = `
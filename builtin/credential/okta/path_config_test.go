@@ -0,0 +1,73 @@
+package okta
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestConfig_MFAAndGroupSync(t *testing.T) {
+	b, storage := getBackend(t)
+
+	write := func(op logical.Operation, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: op,
+			Path:      "config",
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	read := func() map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "config",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		return resp.Data
+	}
+
+	// Group memberships can't be read without an API token
+	for _, data := range []map[string]interface{}{
+		{"org_name": "example", "bypass_groups": "admins"},
+		{"org_name": "example", "group_sync_interval": "1h"},
+		{"org_name": "example", "mfa_push_timeout": "0"},
+	} {
+		if resp := write(logical.CreateOperation, data); resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error for %v, got %#v", data, resp)
+		}
+	}
+
+	if resp := write(logical.CreateOperation, map[string]interface{}{"org_name": "example"}); resp != nil && resp.IsError() {
+		t.Fatal(resp.Error())
+	}
+	if data := read(); data["mfa_push_timeout"] != float64(60) || data["group_sync_interval"] != float64(0) {
+		t.Fatalf("unexpected defaults: %#v", data)
+	}
+
+	if resp := write(logical.UpdateOperation, map[string]interface{}{
+		"api_token":           "token",
+		"bypass_groups":       "admins,break-glass",
+		"mfa_push_timeout":    "2m",
+		"group_sync_interval": "1h",
+	}); resp != nil && resp.IsError() {
+		t.Fatal(resp.Error())
+	}
+	data := read()
+	if diff := deep.Equal(data["bypass_groups"], []string{"admins", "break-glass"}); diff != nil {
+		t.Fatal(diff)
+	}
+	if data["mfa_push_timeout"] != float64(120) || data["group_sync_interval"] != float64(3600) {
+		t.Fatalf("unexpected durations: %#v", data)
+	}
+}
@@ -92,6 +92,9 @@ func (b *backend) pathGroupDelete(ctx context.Context, req *logical.Request, d *
 		return logical.ErrorResponse("'name' must be supplied"), nil
 	}
 
+	b.groupMutex.Lock()
+	defer b.groupMutex.Unlock()
+
 	entry, canonicalName, err := b.Group(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
@@ -112,6 +115,9 @@ func (b *backend) pathGroupRead(ctx context.Context, req *logical.Request, d *fr
 		return logical.ErrorResponse("'name' must be supplied"), nil
 	}
 
+	b.groupMutex.RLock()
+	defer b.groupMutex.RUnlock()
+
 	group, _, err := b.Group(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
@@ -133,9 +139,12 @@ func (b *backend) pathGroupWrite(ctx context.Context, req *logical.Request, d *f
 		return logical.ErrorResponse("'name' must be supplied"), nil
 	}
 
+	b.groupMutex.Lock()
+	defer b.groupMutex.Unlock()
+
 	// Check for an existing group, possibly lowercased so that we keep using
 	// existing user set values
-	_, canonicalName, err := b.Group(ctx, req.Storage, name)
+	existing, canonicalName, err := b.Group(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
 	}
@@ -145,19 +154,28 @@ func (b *backend) pathGroupWrite(ctx context.Context, req *logical.Request, d *f
 		name = strings.ToLower(name)
 	}
 
-	entry, err := logical.StorageEntryJSON("group/"+name, &GroupEntry{
+	entry := &GroupEntry{
 		Policies: policyutil.ParsePolicies(d.Get("policies")),
-	})
-	if err != nil {
-		return nil, err
 	}
-	if err := req.Storage.Put(ctx, entry); err != nil {
+	// Keep the link to the Okta group made by the group sync
+	if existing != nil {
+		entry.OktaGroupID = existing.OktaGroupID
+	}
+	if err := putGroup(ctx, req.Storage, name, entry); err != nil {
 		return nil, err
 	}
 
 	return nil, nil
 }
 
+func putGroup(ctx context.Context, s logical.Storage, name string, group *GroupEntry) error {
+	entry, err := logical.StorageEntryJSON("group/"+name, group)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
 func (b *backend) pathGroupList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	groups, err := groupList(ctx, req.Storage)
 	if err != nil {
@@ -182,6 +200,10 @@ func groupList(ctx context.Context, s logical.Storage) ([]string, error) {
 
 type GroupEntry struct {
 	Policies []string
+
+	// OktaGroupID is the ID of the Okta group of the same name, set when
+	// groups are synced with Okta
+	OktaGroupID string
 }
 
 const pathGroupHelpSyn = `
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/chrismalek/oktasdk-go/okta"
 	"github.com/go-test/deep"
 
 	log "github.com/hashicorp/go-hclog"
@@ -106,3 +107,83 @@ func getBackend(t *testing.T) (logical.Backend, logical.Storage) {
 
 	return b, config.StorageView
 }
+
+func TestGroupsSync(t *testing.T) {
+	b, storage := getBackend(t)
+	ctx := context.Background()
+
+	for _, group := range []string{"admins", "developers", "removed"} {
+		req := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "groups/" + group,
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"policies": group,
+			},
+		}
+		resp, err := b.HandleRequest(ctx, req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%s resp:%#v\n", err, resp)
+		}
+	}
+
+	oktaGroup := func(id, name string) okta.Group {
+		var group okta.Group
+		group.ID = id
+		group.Profile.Name = name
+		return group
+	}
+
+	// The groups are linked to the Okta groups of the same name
+	err := b.(*backend).syncGroups(ctx, storage, []okta.Group{
+		oktaGroup("00g1", "Admins"),
+		oktaGroup("00g2", "Developers"),
+		oktaGroup("00g3", "Removed"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _, err := b.(*backend).Group(ctx, storage, "admins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.OktaGroupID != "00g1" {
+		t.Fatalf("expected the group to be linked, got %#v", entry)
+	}
+
+	// The link survives updates of the group
+	req := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "groups/admins",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"policies": "admins,root-like",
+		},
+	}
+	if resp, err := b.HandleRequest(ctx, req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%s resp:%#v\n", err, resp)
+	}
+
+	// Renamed groups are followed, removed groups are kept
+	err = b.(*backend).syncGroups(ctx, storage, []okta.Group{
+		oktaGroup("00g1", "Platform Admins"),
+		oktaGroup("00g2", "Developers"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := groupList(ctx, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(names, []string{"developers", "platform admins", "removed"}); diff != nil {
+		t.Fatal(diff)
+	}
+	entry, _, err = b.(*backend).Group(ctx, storage, "Platform Admins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(entry, &GroupEntry{Policies: []string{"admins", "root-like"}, OktaGroupID: "00g1"}); diff != nil {
+		t.Fatal(diff)
+	}
+}
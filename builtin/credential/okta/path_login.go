@@ -25,6 +25,11 @@ func pathLogin(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Password for this user.",
 			},
+
+			"totp": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "TOTP passcode for the user, verified instead of sending an Okta Verify push.",
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -55,8 +60,9 @@ func (b *backend) pathLoginAliasLookahead(ctx context.Context, req *logical.Requ
 func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	username := d.Get("username").(string)
 	password := d.Get("password").(string)
+	totp := d.Get("totp").(string)
 
-	policies, resp, groupNames, err := b.Login(ctx, req, username, password)
+	policies, resp, groupNames, err := b.Login(ctx, req, username, password, totp, false)
 	// Handle an internal error
 	if err != nil {
 		return nil, err
@@ -77,6 +83,14 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 		return nil, err
 	}
 
+	// The alias, along with its metadata, is set by Login
+	alias := &logical.Alias{
+		Name: username,
+	}
+	if resp.Auth != nil && resp.Auth.Alias != nil {
+		alias = resp.Auth.Alias
+	}
+
 	resp.Auth = &logical.Auth{
 		Policies: policies,
 		Metadata: map[string]string{
@@ -92,9 +106,7 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 			MaxTTL:    cfg.MaxTTL,
 			Renewable: true,
 		},
-		Alias: &logical.Alias{
-			Name: username,
-		},
+		Alias: alias,
 	}
 
 	for _, groupName := range groupNames {
@@ -113,7 +125,7 @@ func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *f
 	username := req.Auth.Metadata["username"]
 	password := req.Auth.InternalData["password"].(string)
 
-	loginPolicies, resp, groupNames, err := b.Login(ctx, req, username, password)
+	loginPolicies, resp, groupNames, err := b.Login(ctx, req, username, password, "", true)
 	if len(loginPolicies) == 0 {
 		return resp, err
 	}
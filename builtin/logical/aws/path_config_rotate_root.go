@@ -12,15 +12,10 @@ import (
 )
 
 func pathConfigRotateRoot(b *backend) *framework.Path {
-	return &framework.Path{
-		Pattern: "config/rotate-root",
-		Callbacks: map[logical.Operation]framework.OperationFunc{
-			logical.UpdateOperation: b.pathConfigRotateRootUpdate,
-		},
-
-		HelpSynopsis:    pathConfigRotateRootHelpSyn,
-		HelpDescription: pathConfigRotateRootHelpDesc,
-	}
+	p := framework.PathRotateRoot("config/", b.pathConfigRotateRootUpdate)
+	p.HelpSynopsis = pathConfigRotateRootHelpSyn
+	p.HelpDescription = pathConfigRotateRootHelpDesc
+	return p
 }
 
 func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
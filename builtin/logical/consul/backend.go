@@ -26,6 +26,7 @@ func Backend() *backend {
 
 		Paths: []*framework.Path{
 			pathConfigAccess(&b),
+			pathConfigRotateRoot(&b),
 			pathListRoles(&b),
 			pathRoles(&b),
 			pathToken(&b),
@@ -76,6 +76,79 @@ func testBackendConfigAccess(t *testing.T, version string) {
 	}
 }
 
+func TestBackend_Config_RotateRoot(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup, connURL, connToken := consul.PrepareTestContainer(t, "1.4.0-rc1")
+	defer cleanup()
+
+	req := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/access",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"address": connURL,
+			"token":   connToken,
+		},
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write configuration: resp:%#v err:%s", resp, err)
+	}
+
+	req = &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/rotate-root",
+		Storage:   config.StorageView,
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to rotate root token: resp:%#v err:%s", resp, err)
+	}
+	if resp == nil || resp.Data["accessor"] == "" {
+		t.Fatalf("expected accessor of the new token in the response, got: %#v", resp)
+	}
+
+	entry, err := config.StorageView.Get(context.Background(), "config/access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf accessConfig
+	if err := entry.DecodeJSON(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Token == "" || conf.Token == connToken {
+		t.Fatalf("expected stored token to be rotated")
+	}
+
+	// The old token should no longer be accepted by Consul
+	consulConfig := consulapi.DefaultNonPooledConfig()
+	consulConfig.Address = connURL
+	consulConfig.Token = connToken
+	client, err := consulapi.NewClient(consulConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.ACL().TokenReadSelf(nil); err == nil {
+		t.Fatal("expected old token to have been deleted")
+	}
+
+	// The new token should work
+	consulConfig.Token = conf.Token
+	client, err = consulapi.NewClient(consulConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.ACL().TokenReadSelf(nil); err != nil {
+		t.Fatalf("expected new token to be valid: %s", err)
+	}
+}
+
 func TestBackend_Renew_Revoke(t *testing.T) {
 	t.Run("renew_revoke", func(t *testing.T) {
 		t.Parallel()
@@ -0,0 +1,93 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathConfigRotateRoot(b *backend) *framework.Path {
+	p := framework.PathRotateRoot("config/", b.pathConfigRotateRootUpdate)
+	p.HelpSynopsis = pathConfigRotateRootHelpSyn
+	p.HelpDescription = pathConfigRotateRootHelpDesc
+	return p
+}
+
+func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	conf, userErr, intErr := b.readConfigAccess(ctx, req.Storage)
+	if intErr != nil {
+		return nil, intErr
+	}
+	if userErr != nil {
+		return logical.ErrorResponse(userErr.Error()), nil
+	}
+	if conf == nil {
+		return nil, fmt.Errorf("no user error reported but consul access configuration not found")
+	}
+	if conf.Token == "" {
+		return logical.ErrorResponse("cannot call config/rotate-root when token is empty"), nil
+	}
+
+	c, userErr, intErr := b.client(ctx, req.Storage)
+	if intErr != nil {
+		return nil, intErr
+	}
+	if userErr != nil {
+		return logical.ErrorResponse(userErr.Error()), nil
+	}
+
+	queryOpts := &api.QueryOptions{}
+	queryOpts = queryOpts.WithContext(ctx)
+	writeOpts := &api.WriteOptions{}
+	writeOpts = writeOpts.WithContext(ctx)
+
+	self, _, err := c.ACL().TokenReadSelf(queryOpts)
+	if err != nil {
+		return nil, errwrap.Wrapf("error reading current token: {{err}}", err)
+	}
+	if self == nil || self.AccessorID == "" {
+		return nil, fmt.Errorf("nil token or empty accessor returned from TokenReadSelf")
+	}
+
+	newToken, _, err := c.ACL().TokenClone(self.AccessorID, self.Description, writeOpts)
+	if err != nil {
+		return nil, errwrap.Wrapf("error cloning current token: {{err}}", err)
+	}
+	if newToken == nil || newToken.SecretID == "" {
+		return nil, fmt.Errorf("nil token or empty secret returned from TokenClone")
+	}
+
+	conf.Token = newToken.SecretID
+	entry, err := logical.StorageEntryJSON("config/access", conf)
+	if err != nil {
+		return nil, errwrap.Wrapf("error generating new config/access JSON: {{err}}", err)
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, errwrap.Wrapf("error saving new config/access: {{err}}", err)
+	}
+
+	if _, err := c.ACL().TokenDelete(self.AccessorID, writeOpts); err != nil {
+		return nil, errwrap.Wrapf("error deleting old token: {{err}}", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"accessor": newToken.AccessorID,
+		},
+	}, nil
+}
+
+const pathConfigRotateRootHelpSyn = `
+Request to rotate the Consul token used by Vault
+`
+
+const pathConfigRotateRootHelpDesc = `
+This path clones the Consul ACL token used by Vault for this mount, stores the
+clone as the new token and then deletes the old one. It requires Consul 1.4 or
+later and is only valid if a token has been configured via the config/access
+endpoint.
+`
@@ -68,18 +68,21 @@ func Backend(conf *logical.BackendConfig) *databaseBackend {
 			pathCredsCreate(&b),
 			pathResetConnection(&b),
 			pathRotateCredentials(&b),
+			pathVerifyConnection(&b),
 		},
 
 		Secrets: []*framework.Secret{
 			secretCreds(&b),
 		},
-		Clean:       b.closeAllDBs,
-		Invalidate:  b.invalidate,
-		BackendType: logical.TypeLogical,
+		Clean:        b.closeAllDBs,
+		Invalidate:   b.invalidate,
+		PeriodicFunc: b.periodicFunc,
+		BackendType:  logical.TypeLogical,
 	}
 
 	b.logger = conf.Logger
 	b.connections = make(map[string]*dbPluginInstance)
+	b.health = make(map[string]*connectionHealth)
 	return &b
 }
 
@@ -87,6 +90,10 @@ type databaseBackend struct {
 	connections map[string]*dbPluginInstance
 	logger      log.Logger
 
+	// health holds the outcome of the last verification of each connection
+	health     map[string]*connectionHealth
+	healthLock sync.RWMutex
+
 	*framework.Backend
 	sync.RWMutex
 }
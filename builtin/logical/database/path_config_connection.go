@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/fatih/structs"
 	uuid "github.com/hashicorp/go-uuid"
@@ -29,6 +30,10 @@ type DatabaseConfig struct {
 	AllowedRoles      []string               `json:"allowed_roles" structs:"allowed_roles" mapstructure:"allowed_roles"`
 
 	RootCredentialsRotateStatements []string `json:"root_credentials_rotate_statements" structs:"root_credentials_rotate_statements" mapstructure:"root_credentials_rotate_statements"`
+
+	// HealthCheckInterval is how often the connection is verified, zero
+	// disables the periodic checks
+	HealthCheckInterval time.Duration `json:"health_check_interval" structs:"-" mapstructure:"health_check_interval"`
 }
 
 // pathResetConnection configures a path to reset a plugin.
@@ -113,6 +118,14 @@ func pathConfigurePluginConnection(b *databaseBackend) *framework.Path {
 				page for more information on support and formatting for this 
 				parameter.`,
 			},
+
+			"health_check_interval": &framework.FieldSchema{
+				Type: framework.TypeDurationSecond,
+				Description: `How often to verify the connection to the
+				database. The outcome of the last check is returned when
+				reading the connection. Checks run at most once a minute.
+				Defaults to 0, which disables the checks.`,
+			},
 		},
 
 		ExistenceCheck: b.connectionExistenceCheck(),
@@ -201,9 +214,15 @@ func (b *databaseBackend) connectionReadHandler() framework.OperationFunc {
 
 		delete(config.ConnectionDetails, "password")
 
-		return &logical.Response{
+		resp := &logical.Response{
 			Data: structs.New(config).Map(),
-		}, nil
+		}
+		resp.Data["health_check_interval"] = int64(config.HealthCheckInterval.Seconds())
+		if health := b.connectionHealth(name); health != nil {
+			resp.Data["health"] = health.toResponseData()
+		}
+
+		return resp, nil
 	}
 }
 
@@ -223,6 +242,7 @@ func (b *databaseBackend) connectionDeleteHandler() framework.OperationFunc {
 		if err := b.ClearConnection(name); err != nil {
 			return nil, err
 		}
+		b.setConnectionHealth(name, nil)
 
 		return nil, nil
 	}
@@ -273,6 +293,13 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 			config.RootCredentialsRotateStatements = data.Get("root_rotation_statements").([]string)
 		}
 
+		if healthCheckIntervalRaw, ok := data.GetOk("health_check_interval"); ok {
+			config.HealthCheckInterval = time.Duration(healthCheckIntervalRaw.(int)) * time.Second
+		}
+		if config.HealthCheckInterval < 0 {
+			return logical.ErrorResponse("health_check_interval can't be negative"), nil
+		}
+
 		// Remove these entries from the data before we store it keyed under
 		// ConnectionDetails.
 		delete(data.Raw, "name")
@@ -280,6 +307,7 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 		delete(data.Raw, "allowed_roles")
 		delete(data.Raw, "verify_connection")
 		delete(data.Raw, "root_rotation_statements")
+		delete(data.Raw, "health_check_interval")
 
 		// Create a database plugin and initialize it.
 		db, err := dbplugin.PluginFactory(ctx, config.PluginName, b.System(), b.logger)
@@ -333,6 +361,17 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 			return nil, err
 		}
 
+		// A verified connection is healthy, otherwise its health is unknown
+		// until it is checked
+		var health *connectionHealth
+		if verifyConnection {
+			health = &connectionHealth{
+				Healthy:     true,
+				LastChecked: time.Now(),
+			}
+		}
+		b.setConnectionHealth(name, health)
+
 		resp := &logical.Response{}
 
 		// This is a simple test to to check for passwords in the connection_url paramater. If one exists,
@@ -369,6 +408,10 @@ accepts:
 	* "verify_connection" (default: true) - A boolean value denoting if the plugin should verify
 	   it is able to connect to the database using the provided connection
        details.
+
+	* "health_check_interval" (default: 0) - How often to verify the connection
+	   to the database. The outcome of the last check is returned when reading
+	   the connection. Zero disables the checks.
 `
 
 const pathResetConnectionHelpSyn = `
@@ -9,22 +9,17 @@ import (
 )
 
 func pathRotateCredentials(b *databaseBackend) *framework.Path {
-	return &framework.Path{
-		Pattern: "rotate-root/" + framework.GenericNameRegex("name"),
-		Fields: map[string]*framework.FieldSchema{
-			"name": &framework.FieldSchema{
-				Type:        framework.TypeString,
-				Description: "Name of this database connection",
-			},
+	p := framework.PathRotateRoot("", b.pathRotateCredentialsUpdate())
+	p.Pattern = p.Pattern + "/" + framework.GenericNameRegex("name")
+	p.Fields = map[string]*framework.FieldSchema{
+		"name": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Name of this database connection",
 		},
-
-		Callbacks: map[logical.Operation]framework.OperationFunc{
-			logical.UpdateOperation: b.pathRotateCredentialsUpdate(),
-		},
-
-		HelpSynopsis:    pathCredsCreateReadHelpSyn,
-		HelpDescription: pathCredsCreateReadHelpDesc,
 	}
+	p.HelpSynopsis = pathRotateCredentialsUpdateHelpSyn
+	p.HelpDescription = pathRotateCredentialsUpdateHelpDesc
+	return p
 }
 
 func (b *databaseBackend) pathRotateCredentialsUpdate() framework.OperationFunc {
@@ -44,44 +39,62 @@ func (b *databaseBackend) pathRotateCredentialsUpdate() framework.OperationFunc
 			return nil, err
 		}
 
-		// Take out the backend lock since we are swapping out the connection
-		b.Lock()
-		defer b.Unlock()
-
-		// Take the write lock on the instance
-		db.Lock()
-		defer db.Unlock()
-
-		connectionDetails, err := db.RotateRootCredentials(ctx, config.RootCredentialsRotateStatements)
-		if err != nil {
-			return nil, err
-		}
-
-		config.ConnectionDetails = connectionDetails
-		entry, err := logical.StorageEntryJSON(fmt.Sprintf("config/%s", name), config)
-		if err != nil {
-			return nil, err
-		}
-		if err := req.Storage.Put(ctx, entry); err != nil {
+		if err := b.rotateRootCredentials(ctx, req.Storage, name, config, db); err != nil {
 			return nil, err
 		}
 
-		// Close the plugin
-		db.closed = true
-		if err := db.Database.Close(); err != nil {
-			b.Logger().Error("error closing the database plugin connection", "err", err)
+		// Connect with the new credentials, so that a rotation breaking the
+		// connection is reported now rather than when credentials are next
+		// requested
+		if health := b.verifyConnection(ctx, name, config); !health.Healthy {
+			return logical.ErrorResponse(fmt.Sprintf("the root credentials were rotated but connecting with them failed: %s", health.Error)), nil
 		}
-		// Even on error, still remove the connection
-		delete(b.connections, name)
 
 		return nil, nil
 	}
 }
 
+// rotateRootCredentials rotates the root credentials of the connection and
+// stores them, dropping the plugin instance using the previous ones
+func (b *databaseBackend) rotateRootCredentials(ctx context.Context, s logical.Storage, name string, config *DatabaseConfig, db *dbPluginInstance) error {
+	// Take out the backend lock since we are swapping out the connection
+	b.Lock()
+	defer b.Unlock()
+
+	// Take the write lock on the instance
+	db.Lock()
+	defer db.Unlock()
+
+	connectionDetails, err := db.RotateRootCredentials(ctx, config.RootCredentialsRotateStatements)
+	if err != nil {
+		return err
+	}
+
+	config.ConnectionDetails = connectionDetails
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("config/%s", name), config)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return err
+	}
+
+	// Close the plugin
+	db.closed = true
+	if err := db.Database.Close(); err != nil {
+		b.Logger().Error("error closing the database plugin connection", "err", err)
+	}
+	// Even on error, still remove the connection
+	delete(b.connections, name)
+
+	return nil
+}
+
 const pathRotateCredentialsUpdateHelpSyn = `
 Request to rotate the root credentials for a certain database connection.
 `
 
 const pathRotateCredentialsUpdateHelpDesc = `
-This path attempts to rotate the root credentials for the given database. 
+This path attempts to rotate the root credentials for the given database, then
+verifies that the database can be connected to with the new credentials.
 `
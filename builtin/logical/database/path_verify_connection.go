@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/database/dbplugin"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// healthCheckTimeout bounds how long verifying a connection can take, so that
+// an unreachable database doesn't stall the periodic checks
+const healthCheckTimeout = 30 * time.Second
+
+// connectionHealth is the outcome of the last verification of a connection.
+// It is kept in memory, so each node reports its own checks.
+type connectionHealth struct {
+	Healthy     bool
+	LastChecked time.Time
+	Error       string
+}
+
+func (h *connectionHealth) toResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"healthy":      h.Healthy,
+		"last_checked": h.LastChecked.Format(time.RFC3339),
+		"error":        h.Error,
+	}
+}
+
+func pathVerifyConnection(b *databaseBackend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("verify/%s", framework.GenericNameRegex("name")),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of this database connection",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathVerifyConnectionUpdate(),
+		},
+
+		HelpSynopsis:    pathVerifyConnectionHelpSyn,
+		HelpDescription: pathVerifyConnectionHelpDesc,
+	}
+}
+
+// pathVerifyConnectionUpdate connects to the database with the stored
+// connection details and returns the outcome
+func (b *databaseBackend) pathVerifyConnectionUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		name := data.Get("name").(string)
+		if name == "" {
+			return logical.ErrorResponse(respErrEmptyName), nil
+		}
+
+		config, err := b.DatabaseConfig(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+
+		health := b.verifyConnection(ctx, name, config)
+		if !health.Healthy {
+			return logical.ErrorResponse(fmt.Sprintf("error verifying connection: %s", health.Error)), nil
+		}
+
+		return &logical.Response{
+			Data: health.toResponseData(),
+		}, nil
+	}
+}
+
+// verifyConnection connects to the database with a new plugin instance, so
+// that the instance serving requests isn't disturbed, and records the outcome
+func (b *databaseBackend) verifyConnection(ctx context.Context, name string, config *DatabaseConfig) *connectionHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	health := &connectionHealth{
+		Healthy:     true,
+		LastChecked: time.Now(),
+	}
+
+	db, err := dbplugin.PluginFactory(ctx, config.PluginName, b.System(), b.logger)
+	if err == nil {
+		_, err = db.Init(ctx, config.ConnectionDetails, true)
+		db.Close()
+	}
+	if err != nil {
+		health.Healthy = false
+		health.Error = err.Error()
+		b.logger.Warn("database connection verification failed", "name", name, "error", err)
+	}
+
+	b.setConnectionHealth(name, health)
+	return health
+}
+
+func (b *databaseBackend) connectionHealth(name string) *connectionHealth {
+	b.healthLock.RLock()
+	defer b.healthLock.RUnlock()
+
+	return b.health[name]
+}
+
+// setConnectionHealth records the outcome of the last verification of a
+// connection, or forgets it if health is nil
+func (b *databaseBackend) setConnectionHealth(name string, health *connectionHealth) {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	if health == nil {
+		delete(b.health, name)
+		return
+	}
+	b.health[name] = health
+}
+
+// periodicFunc verifies the connections whose health check interval elapsed
+// since their last verification
+func (b *databaseBackend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	names, err := req.Storage.List(ctx, "config/")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		config, err := b.DatabaseConfig(ctx, req.Storage, name)
+		if err != nil {
+			b.logger.Error("failed to load connection configuration for its health check", "name", name, "error", err)
+			continue
+		}
+		if config.HealthCheckInterval <= 0 {
+			continue
+		}
+		if health := b.connectionHealth(name); health != nil && time.Since(health.LastChecked) < config.HealthCheckInterval {
+			continue
+		}
+
+		b.verifyConnection(ctx, name, config)
+	}
+
+	return nil
+}
+
+const pathVerifyConnectionHelpSyn = `
+Verifies the connection to a database.
+`
+
+const pathVerifyConnectionHelpDesc = `
+This path connects to the database with the configured connection details and
+returns whether it succeeded. The outcome is also reported by reading the
+connection configuration, along with the outcome of the periodic health checks
+enabled by "health_check_interval".
+`
@@ -19,6 +19,12 @@ func secretCreds(b *databaseBackend) *framework.Secret {
 
 		Renew:  b.secretCredsRenew(),
 		Revoke: b.secretCredsRevoke(),
+
+		// Database connections can drop transiently, so give revocations a
+		// few attempts before failing them
+		RevokeRetry: &framework.RetryPolicy{
+			MaxAttempts: 3,
+		},
 	}
 }
 
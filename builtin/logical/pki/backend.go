@@ -39,6 +39,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 				"revoked/",
 				"crl",
 				"certs/",
+				"export/",
 			},
 
 			Root: []string{
@@ -75,6 +76,8 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathFetchListCerts(&b),
 			pathRevoke(&b),
 			pathTidy(&b),
+			pathHealthCheck(&b),
+			pathExportCerts(&b),
 		},
 
 		Secrets: []*framework.Secret{
@@ -1930,6 +1930,219 @@ func TestBackend_SignIntermediate_AllowedPastCA(t *testing.T) {
 	}
 }
 
+func TestBackend_UsePSS(t *testing.T) {
+	// create the backend
+	config := logical.TestBackendConfig()
+	storage := &logical.InmemStorage{}
+	config.StorageView = storage
+
+	b := Backend(config)
+	err := b.Setup(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(path string, data map[string]interface{}) *logical.Response {
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if resp != nil && resp.IsError() {
+			t.Fatalf("failed to write %s, %#v", path, *resp)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	parseCert := func(pemCert interface{}) *x509.Certificate {
+		block, _ := pem.Decode([]byte(pemCert.(string)))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+
+	resp := request("root/generate/internal", map[string]interface{}{
+		"common_name": "test.com",
+		"ttl":         "172800",
+		"use_pss":     true,
+	})
+	if cert := parseCert(resp.Data["certificate"]); cert.SignatureAlgorithm != x509.SHA256WithRSAPSS {
+		t.Fatalf("bad root signature algorithm %v", cert.SignatureAlgorithm)
+	}
+
+	// Generate the CSR of an intermediate with another mount's key
+	csrKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrBytes, err := certutil.CreateCertificateRequest(&x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "int.test.com"},
+	}, csrKey, crypto.SHA256, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csr.SignatureAlgorithm != x509.SHA256WithRSAPSS {
+		t.Fatalf("bad csr signature algorithm %v", csr.SignatureAlgorithm)
+	}
+
+	for _, usePSS := range []bool{true, false} {
+		resp = request("root/sign-intermediate", map[string]interface{}{
+			"common_name": "int.test.com",
+			"csr":         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes})),
+			"use_pss":     usePSS,
+		})
+		expected := x509.SHA256WithRSA
+		if usePSS {
+			expected = x509.SHA256WithRSAPSS
+		}
+		if cert := parseCert(resp.Data["certificate"]); cert.SignatureAlgorithm != expected {
+			t.Fatalf("bad intermediate signature algorithm %v with use_pss %v", cert.SignatureAlgorithm, usePSS)
+		}
+	}
+
+	resp = request("intermediate/generate/internal", map[string]interface{}{
+		"common_name": "int.test.com",
+		"use_pss":     true,
+	})
+	block, _ := pem.Decode([]byte(resp.Data["csr"].(string)))
+	if csr, err = x509.ParseCertificateRequest(block.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	if csr.SignatureAlgorithm != x509.SHA256WithRSAPSS {
+		t.Fatalf("bad generated csr signature algorithm %v", csr.SignatureAlgorithm)
+	}
+
+	// PSS is only available with RSA keys
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "intermediate/generate/internal",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"common_name": "int.test.com",
+			"key_type":    "ec",
+			"use_pss":     true,
+		},
+	})
+	if err == nil && (resp == nil || !resp.IsError()) {
+		t.Fatal("expected error generating a PSS signed CSR with an EC key")
+	}
+}
+
+func TestBackend_ChainInCertificate(t *testing.T) {
+	// create the backend
+	config := logical.TestBackendConfig()
+	storage := &logical.InmemStorage{}
+	config.StorageView = storage
+
+	b := Backend(config)
+	err := b.Setup(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: operation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if resp != nil && resp.IsError() {
+			t.Fatalf("failed to write %s, %#v", path, *resp)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := request(logical.UpdateOperation, "root/generate/internal", map[string]interface{}{
+		"common_name": "test.com",
+		"ttl":         "172800",
+	})
+	rootCert := resp.Data["certificate"].(string)
+
+	// Issue from an intermediate, as the chain of certificates issued by
+	// the root CA is empty
+	intKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "int.test.com"},
+	}, intKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = request(logical.UpdateOperation, "root/sign-intermediate", map[string]interface{}{
+		"common_name": "int.test.com",
+		"csr":         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes})),
+	})
+	intCert := resp.Data["certificate"].(string)
+	intKeyBytes, err := x509.MarshalECPrivateKey(intKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request(logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"pem_bundle": strings.Join([]string{
+			string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: intKeyBytes})),
+			intCert,
+			rootCert,
+		}, "\n"),
+	})
+
+	request(logical.UpdateOperation, "roles/test", map[string]interface{}{
+		"allowed_domains":      "test.com",
+		"allow_subdomains":     true,
+		"ttl":                  "1h",
+		"chain_in_certificate": true,
+	})
+	resp = request(logical.ReadOperation, "roles/test", nil)
+	if !resp.Data["chain_in_certificate"].(bool) {
+		t.Fatal("expected chain_in_certificate to be set on the role")
+	}
+
+	resp = request(logical.UpdateOperation, "issue/test", map[string]interface{}{
+		"common_name": "foo.test.com",
+	})
+	leaf, chain, err := certutil.SplitCertificateChain(resp.Data["certificate"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chain, []string{intCert, rootCert}) || !reflect.DeepEqual(chain, resp.Data["ca_chain"]) {
+		t.Fatalf("expected the CA chain in the certificate, got %#v", chain)
+	}
+	if cert, err := certutil.ParsePEMBundle(leaf); err != nil || cert.Certificate.Subject.CommonName != "foo.test.com" {
+		t.Fatalf("bad leaf certificate: %v", err)
+	}
+
+	// The request overrides the role
+	resp = request(logical.UpdateOperation, "issue/test", map[string]interface{}{
+		"common_name":          "foo.test.com",
+		"chain_in_certificate": false,
+	})
+	if _, chain, err := certutil.SplitCertificateChain(resp.Data["certificate"].(string)); err != nil || len(chain) != 0 {
+		t.Fatalf("expected only the leaf in the certificate, got %d chain certificates: %v", len(chain), err)
+	}
+
+	resp = request(logical.UpdateOperation, "issue/test", map[string]interface{}{
+		"common_name": "foo.test.com",
+		"format":      "der",
+	})
+	if len(resp.Warnings) == 0 {
+		t.Fatal("expected a warning with the der format")
+	}
+}
+
 func TestBackend_SignSelfIssued(t *testing.T) {
 	// create the backend
 	config := logical.TestBackendConfig()
@@ -2066,7 +2279,7 @@ func TestBackend_SignSelfIssued(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	signingBundle, err := fetchCAInfo(context.Background(), &logical.Request{Storage: storage})
+	signingBundle, err := fetchCAInfo(context.Background(), b, &logical.Request{Storage: storage})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2569,6 +2782,100 @@ func TestBackend_URI_SANs(t *testing.T) {
 			cert.URIs)
 	}
 }
+
+func TestBackend_IssueKubernetesFormat(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"common_name": "myvault.com",
+			"ttl":         "40h",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	caCert := resp.Data["certificate"].(string)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "roles/test",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"allowed_domains":  "myvault.com",
+			"allow_subdomains": true,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+
+	for _, keyFormat := range []string{"der", "pkcs8"} {
+		resp, err = b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "issue/test",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"common_name":            "foo.myvault.com",
+				"format":                 "kubernetes",
+				"private_key_format":     keyFormat,
+				"kubernetes_secret_name": "foo-tls",
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+
+		secret := resp.Data["kubernetes_secret"].(map[string]interface{})
+		if secret["type"] != "kubernetes.io/tls" || secret["kind"] != "Secret" {
+			t.Fatalf("unexpected secret: %#v", secret)
+		}
+		if secret["metadata"].(map[string]interface{})["name"] != "foo-tls" {
+			t.Fatalf("unexpected secret metadata: %#v", secret["metadata"])
+		}
+
+		secretData := secret["data"].(map[string]interface{})
+		decode := func(key string) string {
+			val, err := base64.StdEncoding.DecodeString(secretData[key].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strings.TrimSpace(string(val))
+		}
+
+		if decode("tls.key") != resp.Data["private_key"].(string) {
+			t.Fatalf("%s: tls.key does not match the issued private key", keyFormat)
+		}
+		if decode("ca.crt") != caCert {
+			t.Fatalf("%s: ca.crt does not match the issuing CA", keyFormat)
+		}
+		if decode("tls.crt") != resp.Data["certificate"].(string) {
+			t.Fatalf("%s: tls.crt does not match the issued certificate", keyFormat)
+		}
+	}
+
+	// The secret name defaults to the serial number
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issue/test",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"common_name": "foo.myvault.com",
+			"format":      "kubernetes",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	name := resp.Data["kubernetes_secret"].(map[string]interface{})["metadata"].(map[string]interface{})["name"]
+	if name != normalizeSerial(resp.Data["serial_number"].(string)) {
+		t.Fatalf("unexpected default secret name %q", name)
+	}
+}
+
 func setCerts() {
 	cak, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
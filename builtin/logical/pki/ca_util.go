@@ -14,10 +14,10 @@ func (b *backend) getGenerationParams(
 	switch exportedStr {
 	case "exported":
 		exported = true
-	case "internal":
+	case "internal", "kms":
 	default:
 		errorResp = logical.ErrorResponse(
-			`the "exported" path parameter must be "internal" or "exported"`)
+			`the "exported" path parameter must be "internal", "exported" or "kms"`)
 		return
 	}
 
@@ -48,12 +48,7 @@ func (b *backend) getGenerationParams(
 		PostalCode:           data.Get("postal_code").([]string),
 	}
 
-	if role.KeyType == "rsa" && role.KeyBits < 2048 {
-		errorResp = logical.ErrorResponse("RSA keys < 2048 bits are unsafe and not supported")
-		return
-	}
-
-	errorResp = validateKeyTypeLength(role.KeyType, role.KeyBits)
+	role.KeyBits, errorResp = validateKeyTypeLength(role.KeyType, role.KeyBits)
 
 	return
 }
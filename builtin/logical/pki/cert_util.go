@@ -5,7 +5,6 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/x509"
@@ -59,6 +58,10 @@ type dataBundle struct {
 	role          *roleEntry
 	req           *logical.Request
 	apiData       *framework.FieldData
+
+	// managedKey, if set, is used as the private key instead of generating
+	// one
+	managedKey logical.ManagedKey
 }
 
 type creationParameters struct {
@@ -81,6 +84,7 @@ type creationParameters struct {
 	// Only used when signing a CA cert
 	UseCSRValues        bool
 	PermittedDNSDomains []string
+	ExcludedDNSDomains  []string
 
 	// URLs to encode into the certificate
 	URLs *urlEntries
@@ -88,8 +92,14 @@ type creationParameters struct {
 	// The maximum path length to encode
 	MaxPathLength int
 
+	// Whether RSA signing keys sign with RSA-PSS
+	UsePSS bool
+
 	// The duration the certificate will use NotBefore
 	NotBeforeDuration time.Duration
+
+	// Warnings about the adjustments made to the requested parameters
+	Warnings []string
 }
 
 type caInfoBundle struct {
@@ -148,39 +158,27 @@ func getFormat(data *framework.FieldData) string {
 	return format
 }
 
-func validateKeyTypeLength(keyType string, keyBits int) *logical.Response {
+// validateKeyTypeLength checks the key type and bits of a role, returning the
+// key bits defaulted by certutil when zero
+func validateKeyTypeLength(keyType string, keyBits int) (int, *logical.Response) {
 	switch keyType {
-	case "rsa":
-		switch keyBits {
-		case 2048:
-		case 4096:
-		case 8192:
-		default:
-			return logical.ErrorResponse(fmt.Sprintf(
-				"unsupported bit length for RSA key: %d", keyBits))
-		}
-	case "ec":
-		switch keyBits {
-		case 224:
-		case 256:
-		case 384:
-		case 521:
-		default:
-			return logical.ErrorResponse(fmt.Sprintf(
-				"unsupported bit length for EC key: %d", keyBits))
+	case "rsa", "ec":
+		keyBits, err := certutil.ValidateKeyTypeLength(certutil.PrivateKeyType(keyType), keyBits)
+		if err != nil {
+			return 0, logical.ErrorResponse(err.Error())
 		}
+		return keyBits, nil
 	case "any":
-	default:
-		return logical.ErrorResponse(fmt.Sprintf(
-			"unknown key type %s", keyType))
+		return keyBits, nil
 	}
 
-	return nil
+	return 0, logical.ErrorResponse(fmt.Sprintf(
+		"unknown key type %s", keyType))
 }
 
 // Fetches the CA info. Unlike other certificates, the CA info is stored
 // in the backend as a CertBundle, because we are storing its private key
-func fetchCAInfo(ctx context.Context, req *logical.Request) (*caInfoBundle, error) {
+func fetchCAInfo(ctx context.Context, b *backend, req *logical.Request) (*caInfoBundle, error) {
 	bundleEntry, err := req.Storage.Get(ctx, "config/ca_bundle")
 	if err != nil {
 		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to fetch local CA certificate/key: %v", err)}
@@ -203,6 +201,21 @@ func fetchCAInfo(ctx context.Context, req *logical.Request) (*caInfoBundle, erro
 		return nil, errutil.InternalError{Err: "stored CA information not able to be parsed"}
 	}
 
+	// The private key isn't in the bundle if it's a managed key
+	if parsedBundle.PrivateKey == nil {
+		managedKey, err := b.fetchManagedKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if managedKey != nil {
+			parsedBundle.PrivateKey = managedKey
+			parsedBundle.PrivateKeyType, _, err = managedKeyTypeAndBits(managedKey)
+			if err != nil {
+				return nil, errutil.InternalError{Err: err.Error()}
+			}
+		}
+	}
+
 	caInfo := &caInfoBundle{*parsedBundle, nil}
 
 	entries, err := getURLs(ctx, req)
@@ -246,7 +259,11 @@ func fetchCertBySerial(ctx context.Context, req *logical.Request, prefix, serial
 		path = "certs/" + hyphenSerial
 	}
 
-	certEntry, err = req.Storage.Get(ctx, path)
+	storage := req.Storage
+	if path == "crl" {
+		storage = crlStorage(storage)
+	}
+	certEntry, err = storage.Get(ctx, path)
 	if err != nil {
 		return nil, errutil.InternalError{Err: fmt.Sprintf("error fetching certificate %s: %s", serial, err)}
 	}
@@ -569,6 +586,7 @@ func generateCert(ctx context.Context,
 	if isCA {
 		data.params.IsCA = isCA
 		data.params.PermittedDNSDomains = data.apiData.Get("permitted_dns_domains").([]string)
+		data.params.ExcludedDNSDomains = data.apiData.Get("excluded_dns_domains").([]string)
 
 		if data.signingBundle == nil {
 			// Generating a self-signed root certificate
@@ -670,6 +688,15 @@ func signCert(b *backend,
 				pubKey.N.BitLen())}
 		}
 
+		// Verify that the bit size is exactly the size specified in the role,
+		// if required
+		if data.role.EnforceCSRKeyBits && pubKey.N.BitLen() != data.role.KeyBits {
+			return nil, errutil.UserError{Err: fmt.Sprintf(
+				"role requires a %d-bit key, but CSR's key is %d bits",
+				data.role.KeyBits,
+				pubKey.N.BitLen())}
+		}
+
 	case "ec":
 		// Verify that the key matches the role type
 		if csr.PublicKeyAlgorithm != x509.ECDSA {
@@ -690,6 +717,15 @@ func signCert(b *backend,
 				pubKey.Params().BitSize)}
 		}
 
+		// Verify that the curve is exactly the one specified in the role, if
+		// required
+		if data.role.EnforceCSRKeyBits && pubKey.Params().BitSize != data.role.KeyBits {
+			return nil, errutil.UserError{Err: fmt.Sprintf(
+				"role requires a key on the P-%d curve, but CSR's key is on the %s curve",
+				data.role.KeyBits,
+				pubKey.Params().Name)}
+		}
+
 	case "any":
 		// We only care about running RSA < 2048 bit checks, so if not RSA
 		// break out
@@ -723,6 +759,7 @@ func signCert(b *backend,
 
 	if isCA {
 		data.params.PermittedDNSDomains = data.apiData.Get("permitted_dns_domains").([]string)
+		data.params.ExcludedDNSDomains = data.apiData.Get("excluded_dns_domains").([]string)
 	}
 
 	parsedBundle, err := signCertificate(data)
@@ -983,6 +1020,7 @@ func generateCreationBundle(b *backend, data *dataBundle) error {
 	var ttl time.Duration
 	var maxTTL time.Duration
 	var notAfter time.Time
+	var warnings []string
 	{
 		ttl = time.Duration(data.apiData.Get("ttl").(int)) * time.Second
 
@@ -1001,18 +1039,27 @@ func generateCreationBundle(b *backend, data *dataBundle) error {
 			maxTTL = b.System().MaxLeaseTTL()
 		}
 		if ttl > maxTTL {
+			if _, ok := data.apiData.GetOk("ttl"); ok {
+				warnings = append(warnings, fmt.Sprintf("the requested TTL of %s is beyond the max TTL of %s; the max TTL was used instead", ttl, maxTTL))
+			}
 			ttl = maxTTL
 		}
 
 		notAfter = time.Now().Add(ttl)
 
 		// If it's not self-signed, verify that the issued certificate won't be
-		// valid past the lifetime of the CA certificate
+		// valid past the lifetime of the CA certificate, truncating its
+		// lifetime if the role allows it
 		if data.signingBundle != nil &&
 			notAfter.After(data.signingBundle.Certificate.NotAfter) && !data.role.AllowExpirationPastCA {
 
-			return errutil.UserError{Err: fmt.Sprintf(
-				"cannot satisfy request, as TTL would result in notAfter %s that is beyond the expiration of the CA certificate at %s", notAfter.Format(time.RFC3339Nano), data.signingBundle.Certificate.NotAfter.Format(time.RFC3339Nano))}
+			if data.role.TruncateTTLToCA {
+				notAfter = data.signingBundle.Certificate.NotAfter
+				warnings = append(warnings, fmt.Sprintf("the TTL was truncated so that the certificate expires with the CA certificate at %s", notAfter.Format(time.RFC3339)))
+			} else {
+				return errutil.UserError{Err: fmt.Sprintf(
+					"cannot satisfy request, as TTL would result in notAfter %s that is beyond the expiration of the CA certificate at %s", notAfter.Format(time.RFC3339Nano), data.signingBundle.Certificate.NotAfter.Format(time.RFC3339Nano))}
+			}
 		}
 	}
 
@@ -1032,6 +1079,13 @@ func generateCreationBundle(b *backend, data *dataBundle) error {
 		PolicyIdentifiers:             data.role.PolicyIdentifiers,
 		BasicConstraintsValidForNonCA: data.role.BasicConstraintsValidForNonCA,
 		NotBeforeDuration:             data.role.NotBeforeDuration,
+		Warnings:                      warnings,
+	}
+
+	if data.apiData != nil {
+		if usePSS, ok := data.apiData.GetOk("use_pss"); ok {
+			data.params.UsePSS = usePSS.(bool)
+		}
 	}
 
 	// Don't deal with URLs or max path length if it's self-signed, as these
@@ -1144,6 +1198,17 @@ func addPolicyIdentifiers(data *dataBundle, certTemplate *x509.Certificate) {
 	}
 }
 
+// addNameConstraints adds the critical name constraints extension for the
+// permitted and excluded DNS domains
+func addNameConstraints(data *dataBundle, certTemplate *x509.Certificate) {
+	if len(data.params.PermittedDNSDomains) == 0 && len(data.params.ExcludedDNSDomains) == 0 {
+		return
+	}
+	certTemplate.PermittedDNSDomains = data.params.PermittedDNSDomains
+	certTemplate.ExcludedDNSDomains = data.params.ExcludedDNSDomains
+	certTemplate.PermittedDNSDomainsCritical = true
+}
+
 // addExtKeyUsageOids adds custom extended key usage OIDs to certificate
 func addExtKeyUsageOids(data *dataBundle, certTemplate *x509.Certificate) {
 	for _, oidstr := range data.params.ExtKeyUsageOIDs {
@@ -1165,7 +1230,13 @@ func createCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 		return nil, err
 	}
 
-	if err := certutil.GeneratePrivateKey(data.params.KeyType,
+	if data.managedKey != nil {
+		result.PrivateKey = data.managedKey
+		result.PrivateKeyType, _, err = managedKeyTypeAndBits(data.managedKey)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := certutil.GeneratePrivateKey(certutil.PrivateKeyType(data.params.KeyType),
 		data.params.KeyBits,
 		result); err != nil {
 		return nil, err
@@ -1205,10 +1276,7 @@ func createCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 	}
 
 	// This will only be filled in from the generation paths
-	if len(data.params.PermittedDNSDomains) > 0 {
-		certTemplate.PermittedDNSDomains = data.params.PermittedDNSDomains
-		certTemplate.PermittedDNSDomainsCritical = true
-	}
+	addNameConstraints(data, certTemplate)
 
 	addPolicyIdentifiers(data, certTemplate)
 
@@ -1222,17 +1290,15 @@ func createCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 
 	var certBytes []byte
 	if data.signingBundle != nil {
-		switch data.signingBundle.PrivateKeyType {
-		case certutil.RSAPrivateKey:
-			certTemplate.SignatureAlgorithm = x509.SHA256WithRSA
-		case certutil.ECPrivateKey:
-			certTemplate.SignatureAlgorithm = x509.ECDSAWithSHA256
+		certTemplate.SignatureAlgorithm, err = certutil.SelectSignatureAlgorithm(data.signingBundle.PrivateKey, crypto.SHA256, data.params.UsePSS)
+		if err != nil {
+			return nil, err
 		}
 
 		caCert := data.signingBundle.Certificate
 		certTemplate.AuthorityKeyId = caCert.SubjectKeyId
 
-		certBytes, err = x509.CreateCertificate(rand.Reader, certTemplate, caCert, result.PrivateKey.Public(), data.signingBundle.PrivateKey)
+		certBytes, err = certutil.CreateCertificate(certTemplate, caCert, result.PrivateKey.Public(), data.signingBundle.PrivateKey, crypto.SHA256, data.params.UsePSS)
 	} else {
 		// Creating a self-signed root
 		if data.params.MaxPathLength == 0 {
@@ -1242,16 +1308,14 @@ func createCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 			certTemplate.MaxPathLen = data.params.MaxPathLength
 		}
 
-		switch data.params.KeyType {
-		case "rsa":
-			certTemplate.SignatureAlgorithm = x509.SHA256WithRSA
-		case "ec":
-			certTemplate.SignatureAlgorithm = x509.ECDSAWithSHA256
+		certTemplate.SignatureAlgorithm, err = certutil.SelectSignatureAlgorithm(result.PrivateKey, crypto.SHA256, data.params.UsePSS)
+		if err != nil {
+			return nil, err
 		}
 
 		certTemplate.AuthorityKeyId = subjKeyID
 		certTemplate.BasicConstraintsValid = true
-		certBytes, err = x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, result.PrivateKey.Public(), result.PrivateKey)
+		certBytes, err = certutil.CreateCertificate(certTemplate, certTemplate, result.PrivateKey.Public(), result.PrivateKey, crypto.SHA256, data.params.UsePSS)
 	}
 
 	if err != nil {
@@ -1284,25 +1348,23 @@ func createCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 // Creates a CSR. This is currently only meant for use when
 // generating an intermediate certificate.
 func createCSR(data *dataBundle) (*certutil.ParsedCSRBundle, error) {
-	var err error
-	result := &certutil.ParsedCSRBundle{}
-
-	if err := certutil.GeneratePrivateKey(data.params.KeyType,
-		data.params.KeyBits,
-		result); err != nil {
-		return nil, err
-	}
-
 	// Like many root CAs, other information is ignored
-	csrTemplate := &x509.CertificateRequest{
+	params := &certutil.CSRParameters{
 		Subject:        data.params.Subject,
 		DNSNames:       data.params.DNSNames,
 		EmailAddresses: data.params.EmailAddresses,
 		IPAddresses:    data.params.IPAddresses,
 		URIs:           data.params.URIs,
+		KeyType:        certutil.PrivateKeyType(data.params.KeyType),
+		KeyBits:        data.params.KeyBits,
+		HashAlgorithm:  crypto.SHA256,
+		UsePSS:         data.params.UsePSS,
+	}
+	if data.managedKey != nil {
+		params.PrivateKey = data.managedKey
 	}
 
-	if err := handleOtherCSRSANs(csrTemplate, data.params.OtherSANs); err != nil {
+	if err := handleOtherCSRSANs(params, data.params.OtherSANs); err != nil {
 		return nil, errutil.InternalError{Err: errwrap.Wrapf("error marshaling other SANs: {{err}}", err).Error()}
 	}
 
@@ -1320,25 +1382,19 @@ func createCSR(data *dataBundle) (*certutil.ParsedCSRBundle, error) {
 			Value:    val,
 			Critical: true,
 		}
-		csrTemplate.ExtraExtensions = append(csrTemplate.ExtraExtensions, ext)
-	}
-
-	switch data.params.KeyType {
-	case "rsa":
-		csrTemplate.SignatureAlgorithm = x509.SHA256WithRSA
-	case "ec":
-		csrTemplate.SignatureAlgorithm = x509.ECDSAWithSHA256
+		params.ExtraExtensions = append(params.ExtraExtensions, ext)
 	}
 
-	csr, err := x509.CreateCertificateRequest(rand.Reader, csrTemplate, result.PrivateKey)
+	result, err := certutil.CreateCSR(params)
 	if err != nil {
-		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to create certificate: %s", err)}
+		return nil, err
 	}
 
-	result.CSRBytes = csr
-	result.CSR, err = x509.ParseCertificateRequest(csr)
-	if err != nil {
-		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse created certificate: %v", err)}
+	if data.managedKey != nil {
+		result.PrivateKeyType, _, err = managedKeyTypeAndBits(data.managedKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
@@ -1390,11 +1446,9 @@ func signCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 		certTemplate.NotBefore = time.Now().Add(-1 * data.params.NotBeforeDuration)
 	}
 
-	switch data.signingBundle.PrivateKeyType {
-	case certutil.RSAPrivateKey:
-		certTemplate.SignatureAlgorithm = x509.SHA256WithRSA
-	case certutil.ECPrivateKey:
-		certTemplate.SignatureAlgorithm = x509.ECDSAWithSHA256
+	certTemplate.SignatureAlgorithm, err = certutil.SelectSignatureAlgorithm(data.signingBundle.PrivateKey, crypto.SHA256, data.params.UsePSS)
+	if err != nil {
+		return nil, err
 	}
 
 	if data.params.UseCSRValues {
@@ -1443,6 +1497,12 @@ func signCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 			data.signingBundle.Certificate.MaxPathLenZero {
 			return nil, errutil.UserError{Err: "signing certificate has a max path length of zero, and cannot issue further CA certificates"}
 		}
+		// The signed certificate can't extend the path further than the
+		// signing certificate allows
+		if caMaxPathLen := data.signingBundle.Certificate.MaxPathLen; caMaxPathLen > 0 &&
+			(data.params.MaxPathLength < 0 || data.params.MaxPathLength >= caMaxPathLen) {
+			return nil, errutil.UserError{Err: fmt.Sprintf("max_path_length must be less than the max path length of the signing certificate (%d)", caMaxPathLen)}
+		}
 
 		certTemplate.MaxPathLen = data.params.MaxPathLength
 		if certTemplate.MaxPathLen == 0 {
@@ -1453,12 +1513,9 @@ func signCertificate(data *dataBundle) (*certutil.ParsedCertBundle, error) {
 		certTemplate.IsCA = false
 	}
 
-	if len(data.params.PermittedDNSDomains) > 0 {
-		certTemplate.PermittedDNSDomains = data.params.PermittedDNSDomains
-		certTemplate.PermittedDNSDomainsCritical = true
-	}
+	addNameConstraints(data, certTemplate)
 
-	certBytes, err = x509.CreateCertificate(rand.Reader, certTemplate, caCert, data.csr.PublicKey, data.signingBundle.PrivateKey)
+	certBytes, err = certutil.CreateCertificate(certTemplate, caCert, data.csr.PublicKey, data.signingBundle.PrivateKey, crypto.SHA256, data.params.UsePSS)
 
 	if err != nil {
 		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to create certificate: %s", err)}
@@ -1538,7 +1595,39 @@ func convertRespToPKCS8(resp *logical.Response) error {
 	return nil
 }
 
-func handleOtherCSRSANs(in *x509.CertificateRequest, sans map[string][]string) error {
+// kubernetesTLSSecret returns the given bundle formatted as a
+// kubernetes.io/tls Secret. As expected by most consumers of such secrets,
+// the certificate includes the CA chain, while ca.crt holds the issuing CA
+// and the rest of its chain.
+func kubernetesTLSSecret(name string, cb *certutil.CertBundle, issuingCA, privateKey string) map[string]interface{} {
+	certs := append([]string{cb.Certificate}, cb.CAChain...)
+
+	caCerts := cb.CAChain
+	if len(caCerts) == 0 {
+		caCerts = []string{issuingCA}
+	}
+
+	secretData := map[string]interface{}{
+		"tls.crt": base64.StdEncoding.EncodeToString([]byte(strings.Join(certs, "\n") + "\n")),
+		"tls.key": "",
+		"ca.crt":  base64.StdEncoding.EncodeToString([]byte(strings.Join(caCerts, "\n") + "\n")),
+	}
+	if privateKey != "" {
+		secretData["tls.key"] = base64.StdEncoding.EncodeToString([]byte(privateKey + "\n"))
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/tls",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"data": secretData,
+	}
+}
+
+func handleOtherCSRSANs(in *certutil.CSRParameters, sans map[string][]string) error {
 	certTemplate := &x509.Certificate{
 		DNSNames:       in.DNSNames,
 		IPAddresses:    in.IPAddresses,
@@ -1651,6 +1740,17 @@ func marshalSANs(dnsNames, emailAddresses []string, ipAddresses []net.IP, uris [
 	return rawValues
 }
 
+// checkPolicyIdentifiers returns an error for the first policy oid that can't
+// be parsed
+func checkPolicyIdentifiers(oids []string) error {
+	for _, oidstr := range oids {
+		if _, err := stringToOid(oidstr); err != nil {
+			return errutil.UserError{Err: fmt.Sprintf("%q could not be parsed as a valid oid for a policy identifier", oidstr)}
+		}
+	}
+	return nil
+}
+
 func stringToOid(in string) (asn1.ObjectIdentifier, error) {
 	split := strings.Split(in, ".")
 	ret := make(asn1.ObjectIdentifier, 0, len(split))
@@ -1,6 +1,7 @@
 package pki
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
@@ -123,3 +124,34 @@ func TestPki_FetchCertBySerial(t *testing.T) {
 		}
 	}
 }
+
+func TestPki_FetchCompressedCRL(t *testing.T) {
+	storage := &logical.InmemStorage{}
+	req := &logical.Request{Storage: storage}
+
+	// A DER SEQUENCE large enough to be compressed
+	crlBytes := append([]byte{0x30, 0x83, 0x01, 0x00, 0x00}, make([]byte, 0x10000)...)
+	err := crlStorage(storage).Put(context.Background(), &logical.StorageEntry{
+		Key:   "crl",
+		Value: crlBytes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := storage.Get(context.Background(), "crl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Value) >= len(crlBytes) {
+		t.Fatalf("expected the crl to be stored compressed, got %d bytes", len(raw.Value))
+	}
+
+	certEntry, err := fetchCertBySerial(context.Background(), req, "", "crl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(certEntry.Value, crlBytes) {
+		t.Fatal("expected the decompressed crl")
+	}
+}
@@ -2,7 +2,6 @@ package pki
 
 import (
 	"context"
-	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
@@ -12,10 +11,15 @@ import (
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/compressutil"
 	"github.com/hashicorp/vault/sdk/helper/errutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
+// CRLs of at least this size, listing thousands of revoked certificates, are
+// stored compressed
+const crlCompressionThreshold = 32 * 1024
+
 type revocationInfo struct {
 	CertificateBytes  []byte    `json:"certificate_bytes"`
 	RevocationTime    int64     `json:"revocation_time"`
@@ -33,7 +37,7 @@ func revokeCert(ctx context.Context, b *backend, req *logical.Request, serial st
 		return nil, nil
 	}
 
-	signingBundle, caErr := fetchCAInfo(ctx, req)
+	signingBundle, caErr := fetchCAInfo(ctx, b, req)
 	switch caErr.(type) {
 	case errutil.UserError:
 		return logical.ErrorResponse(fmt.Sprintf("could not fetch the CA certificate: %s", caErr)), nil
@@ -118,6 +122,10 @@ func revokeCert(ctx context.Context, b *backend, req *logical.Request, serial st
 			return nil, fmt.Errorf("error saving revoked certificate to new location")
 		}
 
+		if err := indexExportRecord(ctx, req.Storage, exportRecordRevoked, currTime, serial); err != nil {
+			return nil, err
+		}
+
 	}
 
 	crlErr := buildCRL(ctx, b, req, false)
@@ -213,7 +221,7 @@ func buildCRL(ctx context.Context, b *backend, req *logical.Request, forceNew bo
 	}
 
 WRITE:
-	signingBundle, caErr := fetchCAInfo(ctx, req)
+	signingBundle, caErr := fetchCAInfo(ctx, b, req)
 	switch caErr.(type) {
 	case errutil.UserError:
 		return errutil.UserError{Err: fmt.Sprintf("could not fetch the CA certificate: %s", caErr)}
@@ -221,12 +229,14 @@ WRITE:
 		return errutil.InternalError{Err: fmt.Sprintf("error fetching CA certificate: %s", caErr)}
 	}
 
-	crlBytes, err := signingBundle.Certificate.CreateCRL(rand.Reader, signingBundle.PrivateKey, revokedCerts, time.Now(), time.Now().Add(crlLifetime))
+	crlBytes, err := certutil.CreateCRL(&signingBundle.ParsedCertBundle, revokedCerts, certutil.CRLOptions{
+		NextUpdate: time.Now().Add(crlLifetime),
+	})
 	if err != nil {
 		return errutil.InternalError{Err: fmt.Sprintf("error creating new CRL: %s", err)}
 	}
 
-	err = req.Storage.Put(ctx, &logical.StorageEntry{
+	err = crlStorage(req.Storage).Put(ctx, &logical.StorageEntry{
 		Key:   "crl",
 		Value: crlBytes,
 	})
@@ -236,3 +246,12 @@ WRITE:
 
 	return nil
 }
+
+// crlStorage returns the storage to read and write the CRL through. Being DER
+// encoded, the CRL never starts with a compression canary, so the CRLs stored
+// uncompressed are read as they are.
+func crlStorage(s logical.Storage) logical.Storage {
+	return logical.NewCompressedStorage(s, &compressutil.CompressionConfig{
+		Type: compressutil.CompressionTypeSnappy,
+	}, crlCompressionThreshold)
+}
@@ -65,6 +65,33 @@ comma-delimited list.`,
 func addNonCACommonFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
 	fields = addIssueAndSignCommonFields(fields)
 
+	fields["format"] = &framework.FieldSchema{
+		Type:    framework.TypeString,
+		Default: "pem",
+		Description: `Format for returned data. Can be "pem", "der",
+"pem_bundle", or "kubernetes". If "pem_bundle" any
+private key and issuing cert will be appended to the
+certificate pem. If "kubernetes" the PEM values are
+returned along with a "kubernetes_secret" value
+containing a kubernetes.io/tls Secret. Defaults to "pem".`,
+		AllowedValues: []interface{}{"pem", "der", "pem_bundle", "kubernetes"},
+	}
+
+	fields["kubernetes_secret_name"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `The name of the Secret returned when "format"
+is "kubernetes". Defaults to the serial number
+of the issued certificate.`,
+	}
+
+	fields["chain_in_certificate"] = &framework.FieldSchema{
+		Type: framework.TypeBool,
+		Description: `If true, the "certificate" field holds the issued
+certificate followed by its CA chain, in PEM. Only
+applies to the "pem" and "kubernetes" formats.
+Defaults to the value of the role.`,
+	}
+
 	fields["role"] = &framework.FieldSchema{
 		Type: framework.TypeString,
 		Description: `The desired role with configuration for this
@@ -197,6 +224,15 @@ more than one, specify alternative names in
 the alt_names map using OID 2.5.4.5.`,
 	}
 
+	fields["use_pss"] = &framework.FieldSchema{
+		Type:    framework.TypeBool,
+		Default: false,
+		Description: `If set, the certificate or CSR is signed
+with RSA-PSS rather than PKCS #1 v1.5 when the
+signing key is an RSA key. Defaults to false.`,
+		DisplayName: "Use PSS",
+	}
+
 	return fields
 }
 
@@ -205,10 +241,19 @@ the alt_names map using OID 2.5.4.5.`,
 func addCAKeyGenerationFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
 	fields["exported"] = &framework.FieldSchema{
 		Type: framework.TypeString,
-		Description: `Must be "internal" or "exported". If set to
+		Description: `Must be "internal", "exported" or "kms". If set to
 "exported", the generated private key will be
 returned. This is your *only* chance to retrieve
-the private key!`,
+the private key! If set to "kms", the managed key
+named by "managed_key_name" is used instead of
+generating a private key.`,
+	}
+
+	fields["managed_key_name"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `The name of the managed key to use when
+"exported" is "kms". The key type and size are
+those of the managed key.`,
 	}
 
 	fields["key_bits"] = &framework.FieldSchema{
@@ -244,5 +289,16 @@ func addCAIssueFields(fields map[string]*framework.FieldSchema) map[string]*fram
 		DisplayName: "Permitted DNS Domains",
 	}
 
+	fields["excluded_dns_domains"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `Domains for which this certificate is not allowed to sign or issue child certificates. If set, no DNS name (subject or alt) on child certs may be an exact match or subset of the given domains (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayName: "Excluded DNS Domains",
+	}
+
+	fields["policy_identifiers"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `A comma-separated string or list of policy oids to encode into the certificate.`,
+	}
+
 	return fields
 }
@@ -0,0 +1,115 @@
+package pki
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// managedKeyConfigPath holds the name of the managed key of the CA, when its
+// private key lives in a KMS or HSM rather than in the CA bundle
+const managedKeyConfigPath = "config/managed_key"
+
+type managedKeyConfig struct {
+	Name string `json:"name"`
+}
+
+// getManagedKey returns the managed key with the given name, if the mount is
+// allowed to use it
+func (b *backend) getManagedKey(ctx context.Context, name string) (logical.ManagedKey, error) {
+	if name == "" {
+		return nil, errutil.UserError{Err: `"managed_key_name" is required to use a managed key`}
+	}
+
+	sysView, ok := b.System().(logical.ManagedKeySystemView)
+	if !ok {
+		return nil, errutil.UserError{Err: "managed keys are not supported by this mount"}
+	}
+
+	key, err := sysView.ManagedKey(ctx, name)
+	if err != nil {
+		return nil, errutil.UserError{Err: err.Error()}
+	}
+	return key, nil
+}
+
+// getManagedKeyGenerationParams returns the managed key named by the request
+// if the "kms" generation type was requested, and sets the key type and size
+// of the role to those of the key
+func (b *backend) getManagedKeyGenerationParams(ctx context.Context, data *framework.FieldData, role *roleEntry) (logical.ManagedKey, *logical.Response) {
+	if data.Get("exported").(string) != "kms" {
+		return nil, nil
+	}
+
+	key, err := b.getManagedKey(ctx, data.Get("managed_key_name").(string))
+	if err != nil {
+		return nil, logical.ErrorResponse(err.Error())
+	}
+
+	keyType, keyBits, err := managedKeyTypeAndBits(key)
+	if err != nil {
+		return nil, logical.ErrorResponse(err.Error())
+	}
+	role.KeyType = string(keyType)
+	role.KeyBits = keyBits
+
+	return key, nil
+}
+
+// fetchManagedKey returns the managed key of the CA, or nil if its private key
+// is stored in the CA bundle
+func (b *backend) fetchManagedKey(ctx context.Context, req *logical.Request) (logical.ManagedKey, error) {
+	entry, err := req.Storage.Get(ctx, managedKeyConfigPath)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to fetch the managed key config: %v", err)}
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config managedKeyConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode the managed key config: %v", err)}
+	}
+
+	key, err := b.getManagedKey(ctx, config.Name)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to fetch managed key %q: %v", config.Name, err)}
+	}
+	return key, nil
+}
+
+// storeManagedKeyConfig records the name of the managed key of the CA, or
+// removes it if the name is empty
+func storeManagedKeyConfig(ctx context.Context, req *logical.Request, name string) error {
+	if name == "" {
+		return req.Storage.Delete(ctx, managedKeyConfigPath)
+	}
+
+	entry, err := logical.StorageEntryJSON(managedKeyConfigPath, &managedKeyConfig{
+		Name: name,
+	})
+	if err != nil {
+		return err
+	}
+	return req.Storage.Put(ctx, entry)
+}
+
+// managedKeyTypeAndBits returns the type and size of a managed key, from its
+// public key
+func managedKeyTypeAndBits(key logical.ManagedKey) (certutil.PrivateKeyType, int, error) {
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		return certutil.RSAPrivateKey, pub.N.BitLen(), nil
+	case *ecdsa.PublicKey:
+		return certutil.ECPrivateKey, pub.Curve.Params().BitSize, nil
+	default:
+		return certutil.UnknownPrivateKey, 0, errutil.UserError{Err: fmt.Sprintf("unsupported managed key type %T", pub)}
+	}
+}
@@ -0,0 +1,186 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	vaulthttp "github.com/hashicorp/vault/http"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+	"github.com/hashicorp/vault/vault/managedkeys"
+)
+
+func TestBackend_ManagedKeys(t *testing.T) {
+	factory := managedkeys.NewTestFactory()
+	coreConfig := &vault.CoreConfig{
+		LogicalBackends: map[string]logical.Factory{
+			"pki": Factory,
+		},
+		ManagedKeyTypes: map[string]managedkeys.Factory{
+			"test": factory,
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: vaulthttp.Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	client := cluster.Cores[0].Client
+
+	for _, mount := range []string{"pki", "pki-int"} {
+		if err := client.Sys().Mount(mount, &api.MountInput{
+			Type: "pki",
+			Config: api.MountConfigInput{
+				MaxLeaseTTL: "87600h",
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := client.Logical().Write("sys/managed-keys/root", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "root"},
+		"allowed_mounts": "pki/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("sys/managed-keys/intermediate", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "intermediate", "key_type": "ec"},
+		"allowed_mounts": "pki-int/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mounts can only use the keys they are allowed to
+	_, err = client.Logical().Write("pki-int/root/generate/kms", map[string]interface{}{
+		"common_name":      "root.example.com",
+		"managed_key_name": "root",
+	})
+	if err == nil || !strings.Contains(err.Error(), "is not allowed to use managed key") {
+		t.Fatalf("expected the mount not to be allowed to use the key, got: %v", err)
+	}
+
+	resp, err := client.Logical().Write("pki/root/generate/kms", map[string]interface{}{
+		"common_name":      "root.example.com",
+		"ttl":              "8760h",
+		"managed_key_name": "root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.Data["private_key"]; ok {
+		t.Fatal("expected no private key to be returned")
+	}
+	rootCert := parseManagedKeyTestCert(t, resp.Data["certificate"].(string))
+
+	rootKey, err := factory(context.Background(), map[string]string{"key_id": "root"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal, err := certutil.ComparePublicKeys(rootCert.PublicKey, rootKey.Public())
+	if err != nil || !equal {
+		t.Fatalf("expected the root certificate to have the public key of the managed key, err: %v", err)
+	}
+
+	// The intermediate CA key is a managed key too
+	resp, err = client.Logical().Write("pki-int/intermediate/generate/kms", map[string]interface{}{
+		"common_name":      "intermediate.example.com",
+		"managed_key_name": "intermediate",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.Data["private_key"]; ok {
+		t.Fatal("expected no private key to be returned")
+	}
+
+	resp, err = client.Logical().Write("pki/root/sign-intermediate", map[string]interface{}{
+		"csr":    resp.Data["csr"],
+		"format": "pem_bundle",
+		"ttl":    "4380h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	intCert := parseManagedKeyTestCert(t, resp.Data["certificate"].(string))
+	if err := intCert.CheckSignatureFrom(rootCert); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Logical().Write("pki-int/intermediate/set-signed", map[string]interface{}{
+		"certificate": resp.Data["certificate"],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Logical().Write("pki-int/roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Logical().Write("pki-int/issue/example", map[string]interface{}{
+		"common_name": "foo.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert := parseManagedKeyTestCert(t, resp.Data["certificate"].(string))
+	if err := leafCert.CheckSignatureFrom(intCert); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CRL is signed with the managed key as well
+	_, err = client.Logical().Write("pki-int/revoke", map[string]interface{}{
+		"serial_number": resp.Data["serial_number"],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crlResp, err := client.RawRequest(client.NewRequest("GET", "/v1/pki-int/crl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer crlResp.Body.Close()
+	crlBytes, err := ioutil.ReadAll(crlResp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := x509.ParseCRL(crlBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := intCert.CheckCRLSignature(crl); err != nil {
+		t.Fatal(err)
+	}
+	if len(crl.TBSCertList.RevokedCertificates) != 1 {
+		t.Fatalf("expected 1 revoked certificate, got %d", len(crl.TBSCertList.RevokedCertificates))
+	}
+}
+
+func parseManagedKeyTestCert(t *testing.T, pemCert string) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode([]byte(pemCert))
+	if block == nil {
+		t.Fatal("failed to decode the certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
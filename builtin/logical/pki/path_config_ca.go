@@ -2,6 +2,7 @@ package pki
 
 import (
 	"context"
+	"encoding/base64"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -19,6 +20,16 @@ func pathConfigCA(b *backend) *framework.Path {
 				Description: `PEM-format, concatenated unencrypted
 secret key and certificate.`,
 			},
+			"pkcs12_bundle": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Base64-encoded PKCS#12 (.pfx) container
+holding the secret key and certificate, as an alternative to
+"pem_bundle".`,
+			},
+			"pkcs12_password": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Password of the PKCS#12 container.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -32,12 +43,24 @@ secret key and certificate.`,
 
 func (b *backend) pathCAWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	pemBundle := data.Get("pem_bundle").(string)
-
-	if pemBundle == "" {
+	pkcs12Bundle := data.Get("pkcs12_bundle").(string)
+
+	var parsedBundle *certutil.ParsedCertBundle
+	var err error
+	switch {
+	case pemBundle != "" && pkcs12Bundle != "":
+		return logical.ErrorResponse("only one of 'pem_bundle' and 'pkcs12_bundle' can be given"), nil
+	case pkcs12Bundle != "":
+		pkcs12Bytes, decodeErr := base64.StdEncoding.DecodeString(pkcs12Bundle)
+		if decodeErr != nil {
+			return logical.ErrorResponse("'pkcs12_bundle' must be base64-encoded"), nil
+		}
+		parsedBundle, err = certutil.ParsePKCS12Bundle(pkcs12Bytes, data.Get("pkcs12_password").(string))
+	case pemBundle != "":
+		parsedBundle, err = certutil.ParsePEMBundle(pemBundle)
+	default:
 		return logical.ErrorResponse("'pem_bundle' was empty"), nil
 	}
-
-	parsedBundle, err := certutil.ParsePEMBundle(pemBundle)
 	if err != nil {
 		switch err.(type) {
 		case errutil.InternalError:
@@ -49,11 +72,11 @@ func (b *backend) pathCAWrite(ctx context.Context, req *logical.Request, data *f
 
 	if parsedBundle.PrivateKey == nil ||
 		parsedBundle.PrivateKeyType == certutil.UnknownPrivateKey {
-		return logical.ErrorResponse("private key not found in the PEM bundle"), nil
+		return logical.ErrorResponse("private key not found in the bundle"), nil
 	}
 
 	if parsedBundle.Certificate == nil {
-		return logical.ErrorResponse("no certificate found in the PEM bundle"), nil
+		return logical.ErrorResponse("no certificate found in the bundle"), nil
 	}
 
 	if !parsedBundle.Certificate.IsCA {
@@ -73,6 +96,9 @@ func (b *backend) pathCAWrite(ctx context.Context, req *logical.Request, data *f
 	if err != nil {
 		return nil, err
 	}
+	if err := storeManagedKeyConfig(ctx, req, ""); err != nil {
+		return nil, err
+	}
 
 	// For ease of later use, also store just the certificate at a known
 	// location, plus a fresh CRL
@@ -95,7 +121,8 @@ Set the CA certificate and private key used for generated credentials.
 const pathConfigCAHelpDesc = `
 This sets the CA information used for credentials generated by this
 by this mount. This must be a PEM-format, concatenated unencrypted
-secret key and certificate.
+secret key and certificate, or a base64-encoded PKCS#12 container
+given with its password.
 
 For security reasons, the secret key cannot be retrieved later.
 `
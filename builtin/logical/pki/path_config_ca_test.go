@@ -0,0 +1,61 @@
+package pki
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPki_ConfigCAPKCS12(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	pkcs12Bytes, err := ioutil.ReadFile("test-fixtures/ca.p12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs12Bundle := base64.StdEncoding.EncodeToString(pkcs12Bytes)
+
+	write := func(data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config/ca",
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+
+	for _, data := range []map[string]interface{}{
+		{"pkcs12_bundle": pkcs12Bundle, "pkcs12_password": "wrong"},
+		{"pkcs12_bundle": "not base64", "pkcs12_password": "vault"},
+		{"pkcs12_bundle": pkcs12Bundle, "pkcs12_password": "vault", "pem_bundle": "foo"},
+	} {
+		resp, err := write(data)
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error response, got err: %v resp: %#v", err, resp)
+		}
+	}
+
+	resp, err := write(map[string]interface{}{
+		"pkcs12_bundle":   pkcs12Bundle,
+		"pkcs12_password": "vault",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "ca_chain",
+		Storage:   storage,
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	if chain := string(resp.Data[logical.HTTPRawBody].([]byte)); strings.Count(chain, "BEGIN CERTIFICATE") != 3 {
+		t.Fatalf("expected the ca chain to hold the three certificates, got:\n%s", chain)
+	}
+}
@@ -0,0 +1,297 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	exportRecordIssued  = "issued"
+	exportRecordRevoked = "revoked"
+)
+
+// exportRecord is a single line of the certificate export
+type exportRecord struct {
+	Type         string    `json:"type"`
+	SerialNumber string    `json:"serial_number"`
+	Time         time.Time `json:"time"`
+	CommonName   string    `json:"common_name"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	Certificate  string    `json:"certificate"`
+}
+
+func pathExportCerts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "certs/export",
+		Fields: map[string]*framework.FieldSchema{
+			"since": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Only export certificates issued or revoked at
+or after this time, given as an RFC 3339 timestamp or as
+seconds since the Unix epoch. Defaults to exporting all
+certificates.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathExportCertsRead,
+		},
+
+		HelpSynopsis:    pathExportCertsHelpSyn,
+		HelpDescription: pathExportCertsHelpDesc,
+	}
+}
+
+func (b *backend) pathExportCertsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	since, err := parseExportSince(data.Get("since").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entries, err := listExportIndex(ctx, req.Storage, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var certBytes []byte
+		switch entry.recordType {
+		case exportRecordIssued:
+			certEntry, err := req.Storage.Get(ctx, "certs/"+entry.serial)
+			if err != nil {
+				return nil, err
+			}
+			if certEntry != nil {
+				certBytes = certEntry.Value
+			}
+		case exportRecordRevoked:
+			// Only hold the lock for a single entry so that revocations
+			// aren't blocked for the whole export
+			b.revokeStorageLock.RLock()
+			revEntry, err := req.Storage.Get(ctx, "revoked/"+entry.serial)
+			b.revokeStorageLock.RUnlock()
+			if err != nil {
+				return nil, err
+			}
+			if revEntry != nil {
+				var revInfo revocationInfo
+				if err := revEntry.DecodeJSON(&revInfo); err != nil {
+					return nil, fmt.Errorf("error decoding revocation entry for serial %s: %s", entry.serial, err)
+				}
+				certBytes = revInfo.CertificateBytes
+			}
+		}
+		// The certificate was tidied since it was indexed
+		if len(certBytes) == 0 {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(certBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse stored certificate with serial %s: %s", entry.serial, err)
+		}
+		encoded, err := json.Marshal(newExportRecord(entry.recordType, entry.time, cert))
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, encoded...)
+		body = append(body, '\n')
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/x-ndjson",
+			logical.HTTPRawBody:     body,
+			logical.HTTPStatusCode:  200,
+		},
+	}, nil
+}
+
+// exportIndexPrefix is the storage prefix of the export index, which records
+// when each certificate was stored or revoked so that exports only read the
+// certificates of the requested period
+const exportIndexPrefix = "export/"
+
+// exportIndexBucket is the period covered by each prefix of the export
+// index, so that exports only list the prefixes of the requested period
+const exportIndexBucket = time.Hour
+
+// exportIndexEntry is a parsed key of the export index
+type exportIndexEntry struct {
+	key        string
+	recordType string
+	time       time.Time
+	serial     string
+}
+
+// exportIndexKey returns the key of the export index entry for the given
+// record. Times are zero padded so that keys sort chronologically, and
+// issuance sorts before revocation of the same certificate at the same time.
+func exportIndexKey(recordType string, t time.Time, serial string) string {
+	return fmt.Sprintf("%s%020d/%020d-%s-%s", exportIndexPrefix, t.Truncate(exportIndexBucket).UnixNano(), t.UnixNano(), recordType, normalizeSerial(serial))
+}
+
+// indexExportRecord adds the given record to the export index
+func indexExportRecord(ctx context.Context, s logical.Storage, recordType string, t time.Time, serial string) error {
+	if err := s.Put(ctx, &logical.StorageEntry{
+		Key: exportIndexKey(recordType, t, serial),
+	}); err != nil {
+		return errwrap.Wrapf("unable to add certificate to the export index: {{err}}", err)
+	}
+	return nil
+}
+
+// storeCert stores the certificate of the given serial number, so it can be
+// fetched and revoked, and adds its issuance to the export index
+func storeCert(ctx context.Context, s logical.Storage, serial string, certBytes []byte) error {
+	if err := s.Put(ctx, &logical.StorageEntry{
+		Key:   "certs/" + normalizeSerial(serial),
+		Value: certBytes,
+	}); err != nil {
+		return err
+	}
+	return indexExportRecord(ctx, s, exportRecordIssued, time.Now(), serial)
+}
+
+// listExportIndex returns the entries of the export index at or after since,
+// in chronological order. Only the buckets from since onward are listed.
+func listExportIndex(ctx context.Context, s logical.Storage, since time.Time) ([]*exportIndexEntry, error) {
+	buckets, err := s.List(ctx, exportIndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(buckets)
+
+	var first string
+	if !since.IsZero() {
+		first = fmt.Sprintf("%020d/", since.Truncate(exportIndexBucket).UnixNano())
+	}
+
+	var entries []*exportIndexEntry
+	for _, bucket := range buckets {
+		if bucket < first {
+			continue
+		}
+		keys, err := s.List(ctx, exportIndexPrefix+bucket)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			entry, err := parseExportIndexKey(exportIndexPrefix + bucket + key)
+			if err != nil {
+				return nil, err
+			}
+			if entry.time.Before(since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// tidyExportIndex removes the given entries of the export index whose
+// certificates are no longer stored
+func tidyExportIndex(ctx context.Context, s logical.Storage, entries []*exportIndexEntry) error {
+	for _, entry := range entries {
+		prefix := "certs/"
+		if entry.recordType == exportRecordRevoked {
+			prefix = "revoked/"
+		}
+		stored, err := s.Get(ctx, prefix+entry.serial)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			continue
+		}
+		if err := s.Delete(ctx, entry.key); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("error deleting export index entry %q: {{err}}", entry.key), err)
+		}
+	}
+	return nil
+}
+
+// parseExportIndexKey parses the given key of the export index
+func parseExportIndexKey(key string) (*exportIndexEntry, error) {
+	parts := strings.SplitN(key[strings.LastIndex(key, "/")+1:], "-", 3)
+	if len(parts) != 3 || (parts[1] != exportRecordIssued && parts[1] != exportRecordRevoked) {
+		return nil, fmt.Errorf("invalid export index entry %q", key)
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export index entry %q", key)
+	}
+	return &exportIndexEntry{
+		key:        key,
+		recordType: parts[1],
+		time:       time.Unix(0, nanos),
+		serial:     parts[2],
+	}, nil
+}
+
+func newExportRecord(recordType string, t time.Time, cert *x509.Certificate) *exportRecord {
+	return &exportRecord{
+		Type:         recordType,
+		SerialNumber: certutil.GetHexFormatted(cert.SerialNumber.Bytes(), ":"),
+		Time:         t.UTC(),
+		CommonName:   cert.Subject.CommonName,
+		NotBefore:    cert.NotBefore.UTC(),
+		NotAfter:     cert.NotAfter.UTC(),
+		Certificate: strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Raw,
+		}))),
+	}
+}
+
+func parseExportSince(since string) (time.Time, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(since, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since must be an RFC 3339 timestamp or a number of seconds since the Unix epoch")
+	}
+	return t, nil
+}
+
+const pathExportCertsHelpSyn = `
+Export the certificates issued or revoked since a given time.
+`
+
+const pathExportCertsHelpDesc = `
+This endpoint returns one JSON record per line for every certificate issued or
+revoked at or after the time given in "since", ordered by time, so that
+external inventories can be kept in sync incrementally. Records are dated by
+when the certificate was stored or revoked, and only the certificates of the
+requested period are read, so callers can pass the time of their previous
+export as "since".
+Certificates of roles with "no_store" set are not included. Certificates
+stored before the export index existed are added to it by the next tidy of
+the certificate store, dated by the start of their validity.
+`
@@ -0,0 +1,213 @@
+package pki
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPki_ExportCerts(t *testing.T) {
+	b, backendStorage := createBackendWithStorage(t)
+	storage := &exportTestStorage{Storage: backendStorage}
+
+	write := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	export := func(since string) []*exportRecord {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "certs/export",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"since": since,
+			},
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		if resp.Data[logical.HTTPContentType] != "application/x-ndjson" {
+			t.Fatalf("bad content type: %#v", resp.Data)
+		}
+
+		var records []*exportRecord
+		scanner := bufio.NewScanner(bytes.NewReader(resp.Data[logical.HTTPRawBody].([]byte)))
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			var record exportRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, &record)
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+
+	write("root/generate/internal", map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "8760h",
+	})
+	write("roles/example", map[string]interface{}{
+		"allowed_domains":     "myvault.com",
+		"allow_subdomains":    true,
+		"not_before_duration": "1h",
+	})
+
+	// Records are dated by when the certificates were stored, not by their
+	// backdated validity, so the time right after the CA was generated only
+	// excludes the CA
+	since := time.Now()
+
+	first := write("issue/example", map[string]interface{}{
+		"common_name": "first.myvault.com",
+	})
+	second := write("issue/example", map[string]interface{}{
+		"common_name": "second.myvault.com",
+	})
+	write("revoke", map[string]interface{}{
+		"serial_number": first.Data["serial_number"],
+	})
+
+	// Everything, including the CA
+	if records := export(""); len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(records))
+	}
+
+	storage.lists = nil
+	records := export(since.UTC().Format(time.RFC3339Nano))
+	var actual []string
+	for _, record := range records {
+		actual = append(actual, record.Type+" "+record.SerialNumber+" "+record.CommonName)
+		if record.Certificate == "" {
+			t.Fatalf("missing certificate: %#v", record)
+		}
+	}
+	expected := []string{
+		fmt.Sprintf("issued %s first.myvault.com", first.Data["serial_number"]),
+		fmt.Sprintf("issued %s second.myvault.com", second.Data["serial_number"]),
+		fmt.Sprintf("revoked %s first.myvault.com", first.Data["serial_number"]),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	// Only the index buckets of the requested period are listed
+	sinceBucket := fmt.Sprintf("%s%020d/", exportIndexPrefix, since.Truncate(exportIndexBucket).UnixNano())
+	for _, prefix := range storage.lists {
+		if prefix != exportIndexPrefix && (!strings.HasPrefix(prefix, exportIndexPrefix) || prefix < sinceBucket) {
+			t.Fatalf("unexpected listing of %q", prefix)
+		}
+	}
+
+	// Nothing happened after the revocation
+	if records := export(fmt.Sprintf("%d", records[2].Time.Unix()+1)); len(records) != 0 {
+		t.Fatalf("expected no records, got %d", len(records))
+	}
+
+	// Records of earlier buckets are only exported when asked for, and
+	// index entries of certificates that are no longer stored are skipped
+	ctx := context.Background()
+	secondSerial := normalizeSerial(second.Data["serial_number"].(string))
+	earlier := since.Add(-3 * time.Hour)
+	if err := indexExportRecord(ctx, storage, exportRecordIssued, earlier, secondSerial); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexExportRecord(ctx, storage, exportRecordIssued, earlier, "00-11"); err != nil {
+		t.Fatal(err)
+	}
+	if records := export(since.UTC().Format(time.RFC3339Nano)); len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	records = export(earlier.UTC().Format(time.RFC3339Nano))
+	if len(records) != 5 || records[0].CommonName != "second.myvault.com" || !records[0].Time.Equal(earlier) {
+		t.Fatalf("expected the earlier record first among 5, got %#v", records)
+	}
+
+	// Tidying adds certificates missing from the index, dated by their
+	// validity, and removes the entries of certificates no longer stored
+	entries, err := listExportIndex(ctx, storage, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.recordType == exportRecordIssued && entry.serial == secondSerial {
+			if err := storage.Delete(ctx, entry.key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "tidy",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"tidy_cert_store":    true,
+			"tidy_revoked_certs": true,
+		},
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	for atomic.LoadUint32(b.tidyCASGuard) != 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if entry, err := storage.Get(ctx, exportIndexKey(exportRecordIssued, earlier, "00-11")); err != nil || entry != nil {
+		t.Fatalf("expected the dangling index entry to be removed: err: %v entry: %#v", err, entry)
+	}
+	backfilled := false
+	for _, record := range export("") {
+		if record.Type == exportRecordIssued && record.CommonName == "second.myvault.com" && record.Time.Equal(record.NotBefore) {
+			backfilled = true
+		}
+	}
+	if !backfilled {
+		t.Fatal("expected the certificate missing from the index to be added by tidy")
+	}
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "certs/export",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"since": "yesterday",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error: err: %v resp: %#v", err, resp)
+	}
+}
+
+// exportTestStorage records the prefixes listed by the export
+type exportTestStorage struct {
+	logical.Storage
+	lists []string
+}
+
+func (s *exportTestStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.lists = append(s.lists, prefix)
+	return s.Storage.List(ctx, prefix)
+}
@@ -158,7 +158,7 @@ func (b *backend) pathFetchRead(ctx context.Context, req *logical.Request, data
 	}
 
 	if serial == "ca_chain" {
-		caInfo, err := fetchCAInfo(ctx, req)
+		caInfo, err := fetchCAInfo(ctx, b, req)
 		switch err.(type) {
 		case errutil.UserError:
 			response = logical.ErrorResponse(err.Error())
@@ -0,0 +1,314 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	healthSeverityCritical = "critical"
+	healthSeverityWarning  = "warning"
+	healthSeverityInfo     = "info"
+)
+
+// healthFinding is a single issue found by the health check
+type healthFinding struct {
+	Check    string `json:"check" structs:"check" mapstructure:"check"`
+	Severity string `json:"severity" structs:"severity" mapstructure:"severity"`
+	Resource string `json:"resource" structs:"resource" mapstructure:"resource"`
+	Message  string `json:"message" structs:"message" mapstructure:"message"`
+}
+
+func pathHealthCheck(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "health-check",
+		Fields: map[string]*framework.FieldSchema{
+			"expiry_window": &framework.FieldSchema{
+				Type: framework.TypeDurationSecond,
+				Description: `How long before the expiration of the CA
+certificate a warning is reported. Defaults to 30 days.`,
+				Default: 2592000, // 30d, but TypeDurationSecond currently requires defaults to be int
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathHealthCheckRead,
+		},
+
+		HelpSynopsis:    pathHealthCheckHelpSyn,
+		HelpDescription: pathHealthCheckHelpDesc,
+	}
+}
+
+func (b *backend) pathHealthCheckRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	expiryWindow := time.Duration(data.Get("expiry_window").(int)) * time.Second
+	if expiryWindow < 0 {
+		return logical.ErrorResponse("expiry_window must not be negative"), nil
+	}
+
+	now := time.Now()
+	findings := []*healthFinding{}
+
+	caCert, caFindings, err := b.healthCheckCA(ctx, req, now, expiryWindow)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, caFindings...)
+
+	crlFindings, err := b.healthCheckCRL(ctx, req, caCert, now)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, crlFindings...)
+
+	roleFindings, err := b.healthCheckRoles(ctx, req, caCert, now)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, roleFindings...)
+
+	ready, healthy := true, true
+	for _, finding := range findings {
+		switch finding.Severity {
+		case healthSeverityCritical:
+			ready = false
+			healthy = false
+		case healthSeverityWarning:
+			healthy = false
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"ready":      ready,
+			"healthy":    healthy,
+			"checked_at": now.UTC().Format(time.RFC3339),
+			"findings":   findings,
+		},
+	}, nil
+}
+
+// healthCheckCA verifies the CA certificate of the mount is present and
+// valid. The returned certificate is nil if the mount has no usable CA.
+func (b *backend) healthCheckCA(ctx context.Context, req *logical.Request, now time.Time, expiryWindow time.Duration) (*x509.Certificate, []*healthFinding, error) {
+	caInfo, err := fetchCAInfo(ctx, b, req)
+	switch err.(type) {
+	case errutil.UserError:
+		return nil, []*healthFinding{{
+			Check:    "ca_missing",
+			Severity: healthSeverityCritical,
+			Resource: "ca",
+			Message:  "no CA certificate is configured; certificates cannot be issued",
+		}}, nil
+	case errutil.InternalError:
+		return nil, nil, err
+	}
+
+	caCert := caInfo.Certificate
+	switch {
+	case now.Before(caCert.NotBefore):
+		return caCert, []*healthFinding{{
+			Check:    "ca_not_yet_valid",
+			Severity: healthSeverityCritical,
+			Resource: "ca",
+			Message:  fmt.Sprintf("the CA certificate is not valid before %s", caCert.NotBefore.UTC().Format(time.RFC3339)),
+		}}, nil
+	case !now.Before(caCert.NotAfter):
+		return caCert, []*healthFinding{{
+			Check:    "ca_expired",
+			Severity: healthSeverityCritical,
+			Resource: "ca",
+			Message:  fmt.Sprintf("the CA certificate expired at %s", caCert.NotAfter.UTC().Format(time.RFC3339)),
+		}}, nil
+	case now.Add(expiryWindow).After(caCert.NotAfter):
+		return caCert, []*healthFinding{{
+			Check:    "ca_expiring",
+			Severity: healthSeverityWarning,
+			Resource: "ca",
+			Message:  fmt.Sprintf("the CA certificate expires at %s", caCert.NotAfter.UTC().Format(time.RFC3339)),
+		}}, nil
+	}
+
+	return caCert, nil, nil
+}
+
+// healthCheckCRL verifies the stored CRL is current and signed by the CA
+func (b *backend) healthCheckCRL(ctx context.Context, req *logical.Request, caCert *x509.Certificate, now time.Time) ([]*healthFinding, error) {
+	config, err := b.CRL(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil && config.Disable {
+		return []*healthFinding{{
+			Check:    "crl_disabled",
+			Severity: healthSeverityInfo,
+			Resource: "crl",
+			Message:  "CRL generation is disabled; revoked certificates are not published",
+		}}, nil
+	}
+	if caCert == nil {
+		return nil, nil
+	}
+
+	entry, err := crlStorage(req.Storage).Get(ctx, "crl")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || len(entry.Value) == 0 {
+		return []*healthFinding{{
+			Check:    "crl_missing",
+			Severity: healthSeverityWarning,
+			Resource: "crl",
+			Message:  "no CRL has been generated; rotate the CRL to create one",
+		}}, nil
+	}
+
+	crl, err := certutil.ParseCRL(entry.Value)
+	if err != nil {
+		return []*healthFinding{{
+			Check:    "crl_invalid",
+			Severity: healthSeverityCritical,
+			Resource: "crl",
+			Message:  fmt.Sprintf("the stored CRL could not be parsed: %v", err),
+		}}, nil
+	}
+
+	if err := caCert.CheckCRLSignature(crl); err != nil {
+		return []*healthFinding{{
+			Check:    "crl_invalid",
+			Severity: healthSeverityCritical,
+			Resource: "crl",
+			Message:  "the stored CRL is not signed by the current CA certificate; rotate the CRL",
+		}}, nil
+	}
+
+	thisUpdate := crl.TBSCertList.ThisUpdate
+	nextUpdate := crl.TBSCertList.NextUpdate
+	switch {
+	case !now.Before(nextUpdate):
+		return []*healthFinding{{
+			Check:    "crl_expired",
+			Severity: healthSeverityCritical,
+			Resource: "crl",
+			Message:  fmt.Sprintf("the CRL expired at %s; rotate the CRL", nextUpdate.UTC().Format(time.RFC3339)),
+		}}, nil
+	case nextUpdate.Sub(now) < nextUpdate.Sub(thisUpdate)/4:
+		// The CRL is only rebuilt on revocation or rotation, so warn once most
+		// of its lifetime has passed
+		return []*healthFinding{{
+			Check:    "crl_expiring",
+			Severity: healthSeverityWarning,
+			Resource: "crl",
+			Message:  fmt.Sprintf("the CRL expires at %s; rotate the CRL", nextUpdate.UTC().Format(time.RFC3339)),
+		}}, nil
+	}
+
+	return nil, nil
+}
+
+// healthCheckRoles looks for role settings that are overly permissive or
+// that cannot be satisfied by the CA
+func (b *backend) healthCheckRoles(ctx context.Context, req *logical.Request, caCert *x509.Certificate, now time.Time) ([]*healthFinding, error) {
+	names, err := req.Storage.List(ctx, "role/")
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*healthFinding
+	for _, name := range names {
+		role, err := b.getRole(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			continue
+		}
+		resource := "roles/" + name
+
+		if role.AllowAnyName {
+			findings = append(findings, &healthFinding{
+				Check:    "role_allow_any_name",
+				Severity: healthSeverityWarning,
+				Resource: resource,
+				Message:  "the role allows certificates to be issued for any name",
+			})
+		}
+		if role.AllowGlobDomains {
+			for _, domain := range role.AllowedDomains {
+				if domain == "*" {
+					findings = append(findings, &healthFinding{
+						Check:    "role_allow_any_name",
+						Severity: healthSeverityWarning,
+						Resource: resource,
+						Message:  `the role allows glob domains and "*" is an allowed domain, which matches any name`,
+					})
+					break
+				}
+			}
+		}
+		if !role.EnforceHostnames {
+			findings = append(findings, &healthFinding{
+				Check:    "role_hostnames_not_enforced",
+				Severity: healthSeverityInfo,
+				Resource: resource,
+				Message:  "the role does not require common names to be valid hostnames",
+			})
+		}
+
+		if caCert == nil || !now.Before(caCert.NotAfter) {
+			continue
+		}
+
+		// Mirror the TTL selection used when issuing certificates
+		ttl := role.TTL
+		if ttl == 0 {
+			ttl = b.System().DefaultLeaseTTL()
+		}
+		maxTTL := role.MaxTTL
+		if maxTTL == 0 {
+			maxTTL = b.System().MaxLeaseTTL()
+		}
+		if ttl > maxTTL {
+			ttl = maxTTL
+		}
+
+		remaining := caCert.NotAfter.Sub(now)
+		switch {
+		case ttl > remaining:
+			findings = append(findings, &healthFinding{
+				Check:    "role_ttl_exceeds_ca",
+				Severity: healthSeverityWarning,
+				Resource: resource,
+				Message:  fmt.Sprintf("the default TTL of %s extends beyond the expiration of the CA certificate; requests without a shorter TTL will fail", ttl),
+			})
+		case maxTTL > remaining:
+			findings = append(findings, &healthFinding{
+				Check:    "role_max_ttl_exceeds_ca",
+				Severity: healthSeverityInfo,
+				Resource: resource,
+				Message:  fmt.Sprintf("the max TTL of %s extends beyond the expiration of the CA certificate", maxTTL),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+const pathHealthCheckHelpSyn = `
+Check the configuration of this mount for problems.
+`
+
+const pathHealthCheckHelpDesc = `
+This endpoint checks the CA certificate, the CRL and the roles of this mount
+and returns a list of findings, each with a severity of "critical", "warning"
+or "info". "ready" is false if there are critical findings, meaning the mount
+cannot issue certificates or publish revocations; "healthy" is false if there
+are critical or warning findings.
+`
@@ -0,0 +1,104 @@
+package pki
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPki_HealthCheck(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	healthCheck := func(data map[string]interface{}) (bool, bool, map[string]string) {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "health-check",
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		checks := make(map[string]string)
+		for _, finding := range resp.Data["findings"].([]*healthFinding) {
+			checks[finding.Resource+":"+finding.Check] = finding.Severity
+		}
+		return resp.Data["ready"].(bool), resp.Data["healthy"].(bool), checks
+	}
+
+	write := func(path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+	}
+
+	// Without a CA the mount isn't ready
+	ready, healthy, checks := healthCheck(nil)
+	if ready || healthy || checks["ca:ca_missing"] != healthSeverityCritical {
+		t.Fatalf("bad: ready: %t healthy: %t findings: %v", ready, healthy, checks)
+	}
+
+	// A CA close to its expiration, which also generates the CRL
+	write("root/generate/internal", map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "240h",
+	})
+	ready, healthy, checks = healthCheck(nil)
+	if !ready || healthy || len(checks) != 1 || checks["ca:ca_expiring"] != healthSeverityWarning {
+		t.Fatalf("bad: ready: %t healthy: %t findings: %v", ready, healthy, checks)
+	}
+
+	// With a shorter window the CA is fine
+	ready, healthy, _ = healthCheck(map[string]interface{}{
+		"expiry_window": "24h",
+	})
+	if !ready || !healthy {
+		t.Fatalf("bad: ready: %t healthy: %t", ready, healthy)
+	}
+
+	write("roles/permissive", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"ttl":               "720h",
+	})
+	write("roles/strict", map[string]interface{}{
+		"allowed_domains":  "myvault.com",
+		"allow_subdomains": true,
+		"ttl":              "1h",
+		"max_ttl":          "8760h",
+	})
+	_, _, checks = healthCheck(nil)
+	expected := map[string]string{
+		"ca:ca_expiring":                               healthSeverityWarning,
+		"roles/permissive:role_allow_any_name":         healthSeverityWarning,
+		"roles/permissive:role_hostnames_not_enforced": healthSeverityInfo,
+		"roles/permissive:role_ttl_exceeds_ca":         healthSeverityWarning,
+		"roles/strict:role_max_ttl_exceeds_ca":         healthSeverityInfo,
+	}
+	if len(checks) != len(expected) {
+		t.Fatalf("bad: findings: %v", checks)
+	}
+	for check, severity := range expected {
+		if checks[check] != severity {
+			t.Fatalf("expected %s to be %s, findings: %v", check, severity, checks)
+		}
+	}
+
+	// A disabled CRL is reported but doesn't affect health
+	write("config/crl", map[string]interface{}{
+		"disable": true,
+	})
+	_, _, checks = healthCheck(nil)
+	if checks["crl:crl_disabled"] != healthSeverityInfo {
+		t.Fatalf("bad: findings: %v", checks)
+	}
+}
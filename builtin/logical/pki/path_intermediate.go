@@ -69,12 +69,17 @@ func (b *backend) pathGenerateIntermediate(ctx context.Context, req *logical.Req
 	if errorResp != nil {
 		return errorResp, nil
 	}
+	managedKey, errorResp := b.getManagedKeyGenerationParams(ctx, data, role)
+	if errorResp != nil {
+		return errorResp, nil
+	}
 
 	var resp *logical.Response
 	input := &dataBundle{
-		role:    role,
-		req:     req,
-		apiData: data,
+		role:       role,
+		req:        req,
+		apiData:    data,
+		managedKey: managedKey,
 	}
 	parsedBundle, err := generateIntermediateCSR(b, input)
 	if err != nil {
@@ -138,6 +143,13 @@ func (b *backend) pathGenerateIntermediate(ctx context.Context, req *logical.Req
 	if err != nil {
 		return nil, err
 	}
+	var managedKeyName string
+	if managedKey != nil {
+		managedKeyName = data.Get("managed_key_name").(string)
+	}
+	if err := storeManagedKeyConfig(ctx, req, managedKeyName); err != nil {
+		return nil, err
+	}
 
 	return resp, nil
 }
@@ -178,20 +190,33 @@ func (b *backend) pathSetSignedIntermediate(ctx context.Context, req *logical.Re
 	}
 
 	if len(cb.PrivateKey) == 0 || cb.PrivateKeyType == "" {
-		return logical.ErrorResponse("could not find an existing private key"), nil
-	}
+		// The key was generated with a managed key, which isn't stored
+		managedKey, err := b.fetchManagedKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if managedKey == nil {
+			return logical.ErrorResponse("could not find an existing private key"), nil
+		}
 
-	parsedCB, err := cb.ToParsedCertBundle()
-	if err != nil {
-		return nil, err
-	}
-	if parsedCB.PrivateKey == nil {
-		return nil, fmt.Errorf("saved key could not be parsed successfully")
-	}
+		inputBundle.PrivateKey = managedKey
+		inputBundle.PrivateKeyType, _, err = managedKeyTypeAndBits(managedKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		parsedCB, err := cb.ToParsedCertBundle()
+		if err != nil {
+			return nil, err
+		}
+		if parsedCB.PrivateKey == nil {
+			return nil, fmt.Errorf("saved key could not be parsed successfully")
+		}
 
-	inputBundle.PrivateKey = parsedCB.PrivateKey
-	inputBundle.PrivateKeyType = parsedCB.PrivateKeyType
-	inputBundle.PrivateKeyBytes = parsedCB.PrivateKeyBytes
+		inputBundle.PrivateKey = parsedCB.PrivateKey
+		inputBundle.PrivateKeyType = parsedCB.PrivateKeyType
+		inputBundle.PrivateKeyBytes = parsedCB.PrivateKeyBytes
+	}
 
 	if !inputBundle.Certificate.IsCA {
 		return logical.ErrorResponse("the given certificate is not marked for CA use and cannot be used with this backend"), nil
@@ -215,9 +240,7 @@ func (b *backend) pathSetSignedIntermediate(ctx context.Context, req *logical.Re
 		return nil, err
 	}
 
-	entry.Key = "certs/" + normalizeSerial(cb.SerialNumber)
-	entry.Value = inputBundle.CertificateBytes
-	err = req.Storage.Put(ctx, entry)
+	err = storeCert(ctx, req.Storage, cb.SerialNumber, inputBundle.CertificateBytes)
 	if err != nil {
 		return nil, err
 	}
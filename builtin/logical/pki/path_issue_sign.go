@@ -182,20 +182,25 @@ func (b *backend) pathSignVerbatim(ctx context.Context, req *logical.Request, da
 			*entry.GenerateLease = *role.GenerateLease
 		}
 		entry.NoStore = role.NoStore
+		entry.TruncateTTLToCA = role.TruncateTTLToCA
+		entry.ChainInCertificate = role.ChainInCertificate
 	}
 
 	return b.pathIssueSignCert(ctx, req, data, entry, true, true)
 }
 
 func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, data *framework.FieldData, role *roleEntry, useCSR, useCSRValues bool) (*logical.Response, error) {
-	format := getFormat(data)
+	format := data.Get("format").(string)
+	if format != "kubernetes" {
+		format = getFormat(data)
+	}
 	if format == "" {
 		return logical.ErrorResponse(
-			`the "format" path parameter must be "pem", "der", or "pem_bundle"`), nil
+			`the "format" path parameter must be "pem", "der", "pem_bundle", or "kubernetes"`), nil
 	}
 
 	var caErr error
-	signingBundle, caErr := fetchCAInfo(ctx, req)
+	signingBundle, caErr := fetchCAInfo(ctx, b, req)
 	switch caErr.(type) {
 	case errutil.UserError:
 		return nil, errutil.UserError{Err: fmt.Sprintf(
@@ -237,15 +242,23 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		return nil, errwrap.Wrapf("error converting raw cert bundle to cert bundle: {{err}}", err)
 	}
 
+	chainInCertificate := role.ChainInCertificate
+	if chainInCertificateRaw, ok := data.GetOk("chain_in_certificate"); ok {
+		chainInCertificate = chainInCertificateRaw.(bool)
+	}
+
 	respData := map[string]interface{}{
 		"expiration":    int64(parsedBundle.Certificate.NotAfter.Unix()),
 		"serial_number": cb.SerialNumber,
 	}
 
 	switch format {
-	case "pem":
+	case "pem", "kubernetes":
 		respData["issuing_ca"] = signingCB.Certificate
 		respData["certificate"] = cb.Certificate
+		if chainInCertificate {
+			respData["certificate"] = cb.ToFullChainPEM()
+		}
 		if cb.CAChain != nil && len(cb.CAChain) > 0 {
 			respData["ca_chain"] = cb.CAChain
 		}
@@ -309,16 +322,33 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	// Built last so that the secret contains the final private key encoding
+	if format == "kubernetes" {
+		secretName := data.Get("kubernetes_secret_name").(string)
+		if secretName == "" {
+			secretName = normalizeSerial(cb.SerialNumber)
+		}
+		privateKey, _ := resp.Data["private_key"].(string)
+		resp.Data["kubernetes_secret"] = kubernetesTLSSecret(secretName, cb, signingCB.Certificate, privateKey)
+	}
+
 	if !role.NoStore {
-		err = req.Storage.Put(ctx, &logical.StorageEntry{
-			Key:   "certs/" + normalizeSerial(cb.SerialNumber),
-			Value: parsedBundle.CertificateBytes,
-		})
+		err = storeCert(ctx, req.Storage, cb.SerialNumber, parsedBundle.CertificateBytes)
 		if err != nil {
 			return nil, errwrap.Wrapf("unable to store certificate locally: {{err}}", err)
 		}
 	}
 
+	if input.params != nil {
+		for _, warning := range input.params.Warnings {
+			resp.AddWarning(warning)
+		}
+	}
+
+	if chainInCertificate && format == "der" {
+		resp.AddWarning("chain_in_certificate has no effect with the \"der\" format")
+	}
+
 	if useCSR {
 		if role.UseCSRCommonName && data.Get("common_name").(string) != "" {
 			resp.AddWarning("the common_name field was provided but the role is set with \"use_csr_common_name\" set to true")
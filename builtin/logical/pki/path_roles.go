@@ -177,6 +177,16 @@ certainly want to change this if you adjust
 the key_type.`,
 			},
 
+			"enforce_csr_key_bits": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, CSRs signed under this role must use
+a key of exactly key_bits bits (the RSA modulus
+size or the EC curve size) instead of at least
+key_bits bits. Has no effect if key_type is
+"any". Defaults to false.`,
+				DisplayName: "Enforce CSR Key Bits",
+			},
+
 			"key_usage": &framework.FieldSchema{
 				Type:    framework.TypeCommaStringSlice,
 				Default: []string{"DigitalSignature", "KeyAgreement", "KeyEncipherment"},
@@ -316,6 +326,22 @@ for "generate_lease".`,
 				Default:     30,
 				Description: `The duration before now the cert needs to be created / signed.`,
 			},
+
+			"truncate_ttl_to_ca": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, TTLs that would make the certificate outlive the issuing CA
+certificate are shortened to its expiration, with a warning, instead of
+failing the request.`,
+				DisplayName: "Truncate TTL to CA",
+			},
+
+			"chain_in_certificate": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, the "certificate" field of PEM responses holds the
+issued certificate followed by its CA chain, for consumers that only accept a
+single PEM input. Can be overridden per request.`,
+				DisplayName: "Chain in Certificate",
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -505,6 +531,7 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		EmailProtectionFlag:           data.Get("email_protection_flag").(bool),
 		KeyType:                       data.Get("key_type").(string),
 		KeyBits:                       data.Get("key_bits").(int),
+		EnforceCSRKeyBits:             data.Get("enforce_csr_key_bits").(bool),
 		UseCSRCommonName:              data.Get("use_csr_common_name").(bool),
 		UseCSRSANs:                    data.Get("use_csr_sans").(bool),
 		KeyUsage:                      data.Get("key_usage").([]string),
@@ -524,6 +551,8 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		PolicyIdentifiers:             data.Get("policy_identifiers").([]string),
 		BasicConstraintsValidForNonCA: data.Get("basic_constraints_valid_for_non_ca").(bool),
 		NotBeforeDuration:             time.Duration(data.Get("not_before_duration").(int)) * time.Second,
+		TruncateTTLToCA:               data.Get("truncate_ttl_to_ca").(bool),
+		ChainInCertificate:            data.Get("chain_in_certificate").(bool),
 	}
 
 	otherSANs := data.Get("allowed_other_sans").([]string)
@@ -542,17 +571,14 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		*entry.GenerateLease = data.Get("generate_lease").(bool)
 	}
 
-	if entry.KeyType == "rsa" && entry.KeyBits < 2048 {
-		return logical.ErrorResponse("RSA keys < 2048 bits are unsafe and not supported"), nil
-	}
-
 	if entry.MaxTTL > 0 && entry.TTL > entry.MaxTTL {
 		return logical.ErrorResponse(
 			`"ttl" value must be less than "max_ttl" value`,
 		), nil
 	}
 
-	if errResp := validateKeyTypeLength(entry.KeyType, entry.KeyBits); errResp != nil {
+	var errResp *logical.Response
+	if entry.KeyBits, errResp = validateKeyTypeLength(entry.KeyType, entry.KeyBits); errResp != nil {
 		return errResp, nil
 	}
 
@@ -565,13 +591,8 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		}
 	}
 
-	if len(entry.PolicyIdentifiers) > 0 {
-		for _, oidstr := range entry.PolicyIdentifiers {
-			_, err := stringToOid(oidstr)
-			if err != nil {
-				return logical.ErrorResponse(fmt.Sprintf("%q could not be parsed as a valid oid for a policy identifier", oidstr)), nil
-			}
-		}
+	if err := checkPolicyIdentifiers(entry.PolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
 	}
 
 	// Store it
@@ -692,6 +713,7 @@ type roleEntry struct {
 	UseCSRSANs                    bool          `json:"use_csr_sans" mapstructure:"use_csr_sans"`
 	KeyType                       string        `json:"key_type" mapstructure:"key_type"`
 	KeyBits                       int           `json:"key_bits" mapstructure:"key_bits"`
+	EnforceCSRKeyBits             bool          `json:"enforce_csr_key_bits" mapstructure:"enforce_csr_key_bits"`
 	MaxPathLength                 *int          `json:",omitempty" mapstructure:"max_path_length"`
 	KeyUsageOld                   string        `json:"key_usage,omitempty"`
 	KeyUsage                      []string      `json:"key_usage_list" mapstructure:"key_usage"`
@@ -715,6 +737,8 @@ type roleEntry struct {
 	ExtKeyUsageOIDs               []string      `json:"ext_key_usage_oids" mapstructure:"ext_key_usage_oids"`
 	BasicConstraintsValidForNonCA bool          `json:"basic_constraints_valid_for_non_ca" mapstructure:"basic_constraints_valid_for_non_ca"`
 	NotBeforeDuration             time.Duration `json:"not_before_duration" mapstructure:"not_before_duration"`
+	TruncateTTLToCA               bool          `json:"truncate_ttl_to_ca" mapstructure:"truncate_ttl_to_ca"`
+	ChainInCertificate            bool          `json:"chain_in_certificate" mapstructure:"chain_in_certificate"`
 
 	// Used internally for signing intermediates
 	AllowExpirationPastCA bool
@@ -741,6 +765,7 @@ func (r *roleEntry) ToResponseData() map[string]interface{} {
 		"use_csr_sans":                       r.UseCSRSANs,
 		"key_type":                           r.KeyType,
 		"key_bits":                           r.KeyBits,
+		"enforce_csr_key_bits":               r.EnforceCSRKeyBits,
 		"key_usage":                          r.KeyUsage,
 		"ext_key_usage":                      r.ExtKeyUsage,
 		"ext_key_usage_oids":                 r.ExtKeyUsageOIDs,
@@ -759,6 +784,8 @@ func (r *roleEntry) ToResponseData() map[string]interface{} {
 		"policy_identifiers":                 r.PolicyIdentifiers,
 		"basic_constraints_valid_for_non_ca": r.BasicConstraintsValidForNonCA,
 		"not_before_duration":                int64(r.NotBeforeDuration.Seconds()),
+		"truncate_ttl_to_ca":                 r.TruncateTTLToCA,
+		"chain_in_certificate":               r.ChainInCertificate,
 	}
 	if r.MaxPathLength != nil {
 		responseData["max_path_length"] = r.MaxPathLength
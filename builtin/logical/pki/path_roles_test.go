@@ -2,6 +2,15 @@ package pki
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"strings"
 	"testing"
 	"time"
 
@@ -739,3 +748,204 @@ func TestPki_CertsLease(t *testing.T) {
 		t.Fatalf("expected a response that contains a secret")
 	}
 }
+
+func TestPki_RoleEnforceCSRKeyBits(t *testing.T) {
+	var resp *logical.Response
+	var err error
+	b, storage := createBackendWithStorage(t)
+
+	caData := map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "5h",
+	}
+	caReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   storage,
+		Data:      caData,
+	}
+	resp, err = b.HandleRequest(context.Background(), caReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+
+	csrPEM := func(key crypto.Signer) string {
+		csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject: pkix.Name{
+				CommonName: "cert.myvault.com",
+			},
+		}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	}
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		keyType string
+		keyBits int
+		enforce bool
+		key     crypto.Signer
+		allowed bool
+	}{
+		{"ec", 256, false, p256Key, true},
+		{"ec", 256, false, p384Key, true},
+		{"ec", 256, true, p256Key, true},
+		{"ec", 256, true, p384Key, false},
+		{"rsa", 2048, false, rsaKey, true},
+		{"rsa", 2048, true, rsaKey, false},
+		{"ec", 256, true, rsaKey, false},
+	}
+
+	for i, tc := range cases {
+		roleReq := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/testrole",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"allowed_domains":      "myvault.com",
+				"allow_subdomains":     true,
+				"key_type":             tc.keyType,
+				"key_bits":             tc.keyBits,
+				"enforce_csr_key_bits": tc.enforce,
+				"ttl":                  "1h",
+			},
+		}
+		resp, err = b.HandleRequest(context.Background(), roleReq)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+
+		roleReq.Operation = logical.ReadOperation
+		resp, err = b.HandleRequest(context.Background(), roleReq)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		if resp.Data["enforce_csr_key_bits"].(bool) != tc.enforce {
+			t.Fatalf("case %d: enforce_csr_key_bits was not stored", i)
+		}
+
+		signReq := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "sign/testrole",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"common_name": "cert.myvault.com",
+				"csr":         csrPEM(tc.key),
+			},
+		}
+		resp, err = b.HandleRequest(context.Background(), signReq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tc.allowed && resp.IsError():
+			t.Fatalf("case %d: expected CSR to be signed, got: %#v", i, resp)
+		case !tc.allowed && !resp.IsError():
+			t.Fatalf("case %d: expected CSR to be rejected", i)
+		}
+	}
+}
+
+func TestPki_RoleTruncateTTLToCA(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	write := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+
+	resp, err := write("root/generate/internal", map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "2h",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	block, _ := pem.Decode([]byte(resp.Data["certificate"].(string)))
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roleData := map[string]interface{}{
+		"allowed_domains":  "myvault.com",
+		"allow_subdomains": true,
+	}
+	resp, err = write("roles/testrole", roleData)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	issueData := map[string]interface{}{
+		"common_name": "cert.myvault.com",
+		"ttl":         "5h",
+	}
+
+	// TTLs past the expiration of the CA fail by default
+	resp, err = write("issue/testrole", issueData)
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error response, got err: %v resp: %#v", err, resp)
+	}
+
+	roleData["truncate_ttl_to_ca"] = true
+	resp, err = write("roles/testrole", roleData)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	resp, err = write("issue/testrole", issueData)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Fatalf("expected a warning about the truncated ttl, got %q", resp.Warnings)
+	}
+	block, _ = pem.Decode([]byte(resp.Data["certificate"].(string)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.NotAfter.Equal(caCert.NotAfter) {
+		t.Fatalf("expected the certificate to expire with the ca at %s, got %s", caCert.NotAfter, cert.NotAfter)
+	}
+
+	// TTLs within the lifetime of the CA are left alone
+	issueData["ttl"] = "1h"
+	resp, err = write("issue/testrole", issueData)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Fatalf("expected no warning, got %q", resp.Warnings)
+	}
+
+	// TTLs capped at the max TTL of the role are reported as well
+	roleData["max_ttl"] = "30m"
+	resp, err = write("roles/testrole", roleData)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	resp, err = write("issue/testrole", issueData)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "max TTL") {
+		t.Fatalf("expected a warning about the max ttl, got %q", resp.Warnings)
+	}
+}
@@ -112,7 +112,10 @@ func pathSignSelfIssued(b *backend) *framework.Path {
 }
 
 func (b *backend) pathCADeleteRoot(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	return nil, req.Storage.Delete(ctx, "config/ca_bundle")
+	if err := req.Storage.Delete(ctx, "config/ca_bundle"); err != nil {
+		return nil, err
+	}
+	return nil, storeManagedKeyConfig(ctx, req, "")
 }
 
 func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -132,6 +135,10 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 	if errorResp != nil {
 		return errorResp, nil
 	}
+	managedKey, errorResp := b.getManagedKeyGenerationParams(ctx, data, role)
+	if errorResp != nil {
+		return errorResp, nil
+	}
 
 	maxPathLengthIface, ok := data.GetOk("max_path_length")
 	if ok {
@@ -139,10 +146,16 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 		role.MaxPathLength = &maxPathLength
 	}
 
+	role.PolicyIdentifiers = data.Get("policy_identifiers").([]string)
+	if err := checkPolicyIdentifiers(role.PolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	input := &dataBundle{
-		req:     req,
-		apiData: data,
-		role:    role,
+		req:        req,
+		apiData:    data,
+		role:       role,
+		managedKey: managedKey,
 	}
 	parsedBundle, err := generateCert(ctx, b, input, true)
 	if err != nil {
@@ -166,6 +179,10 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 		},
 	}
 
+	for _, warning := range input.params.Warnings {
+		resp.AddWarning(warning)
+	}
+
 	switch format {
 	case "pem":
 		resp.Data["certificate"] = cb.Certificate
@@ -211,13 +228,17 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 	if err != nil {
 		return nil, err
 	}
+	var managedKeyName string
+	if managedKey != nil {
+		managedKeyName = data.Get("managed_key_name").(string)
+	}
+	if err := storeManagedKeyConfig(ctx, req, managedKeyName); err != nil {
+		return nil, err
+	}
 
 	// Also store it as just the certificate identified by serial number, so it
 	// can be revoked
-	err = req.Storage.Put(ctx, &logical.StorageEntry{
-		Key:   "certs/" + normalizeSerial(cb.SerialNumber),
-		Value: parsedBundle.CertificateBytes,
-	})
+	err = storeCert(ctx, req.Storage, cb.SerialNumber, parsedBundle.CertificateBytes)
 	if err != nil {
 		return nil, errwrap.Wrapf("unable to store certificate locally: {{err}}", err)
 	}
@@ -278,7 +299,7 @@ func (b *backend) pathCASignIntermediate(ctx context.Context, req *logical.Reque
 	}
 
 	var caErr error
-	signingBundle, caErr := fetchCAInfo(ctx, req)
+	signingBundle, caErr := fetchCAInfo(ctx, b, req)
 	switch caErr.(type) {
 	case errutil.UserError:
 		return nil, errutil.UserError{Err: fmt.Sprintf(
@@ -296,6 +317,11 @@ func (b *backend) pathCASignIntermediate(ctx context.Context, req *logical.Reque
 		role.MaxPathLength = &maxPathLength
 	}
 
+	role.PolicyIdentifiers = data.Get("policy_identifiers").([]string)
+	if err := checkPolicyIdentifiers(role.PolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	input := &dataBundle{
 		req:           req,
 		apiData:       data,
@@ -333,6 +359,10 @@ func (b *backend) pathCASignIntermediate(ctx context.Context, req *logical.Reque
 		},
 	}
 
+	for _, warning := range input.params.Warnings {
+		resp.AddWarning(warning)
+	}
+
 	if signingBundle.Certificate.NotAfter.Before(parsedBundle.Certificate.NotAfter) {
 		resp.AddWarning("The expiration time for the signed certificate is after the CA's expiration time. If the new certificate is not treated as a root, validation paths with the certificate past the issuing CA's expiration time will fail.")
 	}
@@ -365,10 +395,7 @@ func (b *backend) pathCASignIntermediate(ctx context.Context, req *logical.Reque
 		}
 	}
 
-	err = req.Storage.Put(ctx, &logical.StorageEntry{
-		Key:   "certs/" + normalizeSerial(cb.SerialNumber),
-		Value: parsedBundle.CertificateBytes,
-	})
+	err = storeCert(ctx, req.Storage, cb.SerialNumber, parsedBundle.CertificateBytes)
 	if err != nil {
 		return nil, errwrap.Wrapf("unable to store certificate locally: {{err}}", err)
 	}
@@ -405,7 +432,7 @@ func (b *backend) pathCASignSelfIssued(ctx context.Context, req *logical.Request
 	}
 
 	var caErr error
-	signingBundle, caErr := fetchCAInfo(ctx, req)
+	signingBundle, caErr := fetchCAInfo(ctx, b, req)
 	switch caErr.(type) {
 	case errutil.UserError:
 		return nil, errutil.UserError{Err: fmt.Sprintf(
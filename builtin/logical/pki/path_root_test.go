@@ -0,0 +1,97 @@
+package pki
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPki_SignIntermediateConstraints(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	write := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+
+	resp, err := write("root/generate/internal", map[string]interface{}{
+		"common_name":     "root.example.com",
+		"ttl":             "40h",
+		"max_path_length": 2,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "int.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+
+	for _, data := range []map[string]interface{}{
+		{"max_path_length": 2},
+		{"max_path_length": -1},
+		{"policy_identifiers": "1.2.foo"},
+	} {
+		data["csr"] = csrPem
+		data["common_name"] = "int.example.com"
+		resp, err := write("root/sign-intermediate", data)
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error response for %v, got err: %v resp: %#v", data, err, resp)
+		}
+	}
+
+	resp, err = write("root/sign-intermediate", map[string]interface{}{
+		"csr":                   csrPem,
+		"common_name":           "int.example.com",
+		"max_path_length":       1,
+		"permitted_dns_domains": "example.com",
+		"excluded_dns_domains":  "secret.example.com,internal.example.com",
+		"policy_identifiers":    "1.2.3.4,1.3.6.1.4.1.7",
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+
+	block, _ := pem.Decode([]byte(resp.Data["certificate"].(string)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.IsCA || cert.MaxPathLen != 1 {
+		t.Fatalf("bad basic constraints, is ca: %t, max path length: %d", cert.IsCA, cert.MaxPathLen)
+	}
+	if !cert.PermittedDNSDomainsCritical {
+		t.Fatal("expected the name constraints to be critical")
+	}
+	if !reflect.DeepEqual(cert.PermittedDNSDomains, []string{"example.com"}) {
+		t.Fatalf("bad permitted dns domains %q", cert.PermittedDNSDomains)
+	}
+	if !reflect.DeepEqual(cert.ExcludedDNSDomains, []string{"secret.example.com", "internal.example.com"}) {
+		t.Fatalf("bad excluded dns domains %q", cert.ExcludedDNSDomains)
+	}
+	expectedPolicies := []asn1.ObjectIdentifier{{1, 2, 3, 4}, {1, 3, 6, 1, 4, 1, 7}}
+	if !reflect.DeepEqual(cert.PolicyIdentifiers, expectedPolicies) {
+		t.Fatalf("bad policy identifiers %v", cert.PolicyIdentifiers)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -27,6 +28,7 @@ the certificate store`,
 			"tidy_revocation_list": &framework.FieldSchema{
 				Type:        framework.TypeBool,
 				Description: `Deprecated; synonym for 'tidy_revoked_certs`,
+				Deprecated:  true,
 			},
 
 			"tidy_revoked_certs": &framework.FieldSchema{
@@ -93,6 +95,25 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 		logger := b.Logger().Named("tidy")
 
 		doTidy := func() error {
+			// Certificates stored before the export index existed are
+			// added to it, and the entries of tidied certificates removed
+			var indexed map[string]bool
+			if tidyCertStore || tidyRevokedCerts || tidyRevocationList {
+				entries, err := listExportIndex(ctx, req.Storage, time.Time{})
+				if err != nil {
+					return errwrap.Wrapf("error fetching the export index: {{err}}", err)
+				}
+				indexed = make(map[string]bool, len(entries))
+				for _, entry := range entries {
+					indexed[entry.recordType+"/"+entry.serial] = true
+				}
+				defer func() {
+					if err := tidyExportIndex(ctx, req.Storage, entries); err != nil {
+						logger.Error("error tidying the export index", "error", err)
+					}
+				}()
+			}
+
 			if tidyCertStore {
 				serials, err := req.Storage.List(ctx, "certs/")
 				if err != nil {
@@ -129,6 +150,12 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 						if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 							return errwrap.Wrapf(fmt.Sprintf("error deleting serial %q from storage: {{err}}", serial), err)
 						}
+					} else if !indexed[exportRecordIssued+"/"+serial] && !strings.Contains(serial, ":") {
+						// The issuance time isn't known, so the start of
+						// the validity is the best approximation
+						if err := indexExportRecord(ctx, req.Storage, exportRecordIssued, cert.NotBefore, serial); err != nil {
+							return err
+						}
 					}
 				}
 			}
@@ -165,6 +192,7 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 						}
 					}
 
+					revInfo = revocationInfo{}
 					err = revokedEntry.DecodeJSON(&revInfo)
 					if err != nil {
 						return errwrap.Wrapf(fmt.Sprintf("error decoding revocation entry for serial %q: {{err}}", serial), err)
@@ -183,6 +211,14 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 							return errwrap.Wrapf(fmt.Sprintf("error deleting serial %q from store when tidying revoked: {{err}}", serial), err)
 						}
 						tidiedRevoked = true
+					} else if !indexed[exportRecordRevoked+"/"+serial] && !strings.Contains(serial, ":") {
+						revocationTime := revInfo.RevocationTimeUTC
+						if revocationTime.IsZero() {
+							revocationTime = time.Unix(revInfo.RevocationTime, 0)
+						}
+						if err := indexExportRecord(ctx, req.Storage, exportRecordRevoked, revocationTime, serial); err != nil {
+							return err
+						}
 					}
 				}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/passwordpolicy"
 	"github.com/hashicorp/vault/sdk/logical"
 	rabbithole "github.com/michaelklishin/rabbit-hole"
 )
@@ -30,6 +31,14 @@ func pathConfigConnection(b *backend) *framework.Path {
 				Default:     true,
 				Description: `If set, connection_uri is verified by actually connecting to the RabbitMQ management API`,
 			},
+			"password_policy": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Policy in HCL or JSON describing the passwords of the generated users. If not set, passwords are random UUIDs.`,
+			},
+			"username_template": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Template describing the usernames of the generated users. If not set, usernames are the display name of the token followed by a random UUID.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -57,6 +66,20 @@ func (b *backend) pathConnectionUpdate(ctx context.Context, req *logical.Request
 		return logical.ErrorResponse("missing password"), nil
 	}
 
+	passwordPolicy := data.Get("password_policy").(string)
+	if passwordPolicy != "" {
+		if _, err := passwordpolicy.ParsePolicy(passwordPolicy); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	usernameTemplate := data.Get("username_template").(string)
+	if usernameTemplate != "" {
+		if _, err := passwordpolicy.NewUsernameTemplate(usernameTemplate); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
 	// Don't check the connection_url if verification is disabled
 	verifyConnection := data.Get("verify_connection").(bool)
 	if verifyConnection {
@@ -74,9 +97,11 @@ func (b *backend) pathConnectionUpdate(ctx context.Context, req *logical.Request
 
 	// Store it
 	entry, err := logical.StorageEntryJSON("config/connection", connectionConfig{
-		URI:      uri,
-		Username: username,
-		Password: password,
+		URI:              uri,
+		Username:         username,
+		Password:         password,
+		PasswordPolicy:   passwordPolicy,
+		UsernameTemplate: usernameTemplate,
 	})
 	if err != nil {
 		return nil, err
@@ -101,6 +126,12 @@ type connectionConfig struct {
 
 	// Password for the Username
 	Password string `json:"password"`
+
+	// PasswordPolicy describes the passwords of the generated users
+	PasswordPolicy string `json:"password_policy"`
+
+	// UsernameTemplate describes the usernames of the generated users
+	UsernameTemplate string `json:"username_template"`
 }
 
 const pathConfigConnectionHelpSyn = `
@@ -112,7 +143,8 @@ This path configures the connection properties used to connect to RabbitMQ manag
 The "connection_uri" parameter is a string that is used to connect to the API. The "username"
 and "password" parameters are strings that are used as credentials to the API. The "verify_connection"
 parameter is a boolean that is used to verify whether the provided connection URI, username, and password
-are valid.
+are valid. The optional "password_policy" and "username_template" parameters
+describe the credentials of the generated users.
 
 The URI looks like:
 "http://localhost:15672"
@@ -8,6 +8,7 @@ import (
 	multierror "github.com/hashicorp/go-multierror"
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/passwordpolicy"
 	"github.com/hashicorp/vault/sdk/logical"
 	rabbithole "github.com/michaelklishin/rabbit-hole"
 )
@@ -47,14 +48,17 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		return logical.ErrorResponse(fmt.Sprintf("unknown role: %s", name)), nil
 	}
 
-	// Ensure username is unique
-	uuidVal, err := uuid.GenerateUUID()
+	connConfig, err := b.connectionConfig(ctx, req.Storage)
 	if err != nil {
 		return nil, err
 	}
-	username := fmt.Sprintf("%s-%s", req.DisplayName, uuidVal)
 
-	password, err := uuid.GenerateUUID()
+	username, err := generateUsername(connConfig, req.DisplayName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := generatePassword(connConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +119,38 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 	return resp, nil
 }
 
+func generateUsername(connConfig *connectionConfig, displayName, roleName string) (string, error) {
+	if connConfig.UsernameTemplate == "" {
+		// Ensure username is unique
+		uuidVal, err := uuid.GenerateUUID()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s-%s", displayName, uuidVal), nil
+	}
+
+	tmpl, err := passwordpolicy.NewUsernameTemplate(connConfig.UsernameTemplate)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Generate(passwordpolicy.UsernameData{
+		DisplayName: displayName,
+		RoleName:    roleName,
+	})
+}
+
+func generatePassword(connConfig *connectionConfig) (string, error) {
+	if connConfig.PasswordPolicy == "" {
+		return uuid.GenerateUUID()
+	}
+
+	policy, err := passwordpolicy.ParsePolicy(connConfig.PasswordPolicy)
+	if err != nil {
+		return "", err
+	}
+	return policy.Generate()
+}
+
 const pathRoleCreateReadHelpSyn = `
 Request RabbitMQ credentials for a certain role.
 `
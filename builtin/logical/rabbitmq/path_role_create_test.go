@@ -0,0 +1,86 @@
+package rabbitmq
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestBackend_config_connection_credentials(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	configReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/connection",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"connection_uri":    "http://127.0.0.1:15672",
+			"username":          "guest",
+			"password":          "guest",
+			"verify_connection": false,
+			"password_policy":   `length = 8 rule "charset" { charset = "abc" min_chars = 8 }`,
+			"username_template": "{{.RoleName}}-{{random 4}}",
+		},
+	}
+	resp, err := b.HandleRequest(context.Background(), configReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v\nerr:%s", resp, err)
+	}
+
+	connConfig, err := b.connectionConfig(context.Background(), config.StorageView)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	username, err := generateUsername(connConfig, "token", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^web-[a-zA-Z0-9]{4}$`).MatchString(username) {
+		t.Fatalf("bad username: %q", username)
+	}
+
+	password, err := generatePassword(connConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[abc]{8}$`).MatchString(password) {
+		t.Fatalf("bad password: %q", password)
+	}
+
+	// Without a policy or template, credentials keep their previous format
+	connConfig.PasswordPolicy = ""
+	connConfig.UsernameTemplate = ""
+	username, err = generateUsername(connConfig, "token", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^token-[0-9a-f-]{36}$`).MatchString(username) {
+		t.Fatalf("bad username: %q", username)
+	}
+
+	// Invalid policies and templates are rejected
+	for field, value := range map[string]string{
+		"password_policy":   "length = 0 rule",
+		"username_template": "{{.RoleName",
+	} {
+		configReq.Data = map[string]interface{}{
+			"connection_uri":    "http://127.0.0.1:15672",
+			"username":          "guest",
+			"password":          "guest",
+			"verify_connection": false,
+			field:               value,
+		}
+		resp, err = b.HandleRequest(context.Background(), configReq)
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("%s: expected error response, got: %#v\nerr:%s", field, resp, err)
+		}
+	}
+}
@@ -38,6 +38,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			Unauthenticated: []string{
 				"verify",
 				"public_key",
+				"public_keys",
 			},
 
 			LocalStorage: []string{
@@ -62,6 +63,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			pathConfigCA(&b),
 			pathSign(&b),
 			pathFetchPublicKey(&b),
+			pathFetchPublicKeys(&b),
 		},
 
 		Secrets: []*framework.Secret{
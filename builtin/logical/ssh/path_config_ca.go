@@ -83,6 +83,9 @@ func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, da
 }
 
 func (b *backend) pathConfigCADelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := retireCAPublicKeyVersion(ctx, req.Storage); err != nil {
+		return nil, err
+	}
 	if err := req.Storage.Delete(ctx, caPrivateKeyStoragePath); err != nil {
 		return nil, err
 	}
@@ -252,6 +255,10 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 		return nil, err
 	}
 
+	if err := addCAPublicKeyVersion(ctx, req.Storage, publicKey); err != nil {
+		return nil, err
+	}
+
 	if generateSigningKey {
 		response := &logical.Response{
 			Data: map[string]interface{}{
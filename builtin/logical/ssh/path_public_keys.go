@@ -0,0 +1,247 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const caPublicKeyVersionsStoragePath = "config/ca_public_key_versions"
+
+// caPublicKeyVersion is a CA public key that this mount has been configured
+// with. Keys are retired when the CA is deleted, but are kept so that hosts
+// can keep trusting certificates signed by them while rotating.
+type caPublicKeyVersion struct {
+	Version     int       `json:"version" structs:"version" mapstructure:"version"`
+	Key         string    `json:"key" structs:"key" mapstructure:"key"`
+	CreatedTime time.Time `json:"created_time" structs:"created_time" mapstructure:"created_time"`
+	RetiredTime time.Time `json:"retired_time" structs:"retired_time" mapstructure:"retired_time"`
+}
+
+type caPublicKeyVersions struct {
+	Versions []*caPublicKeyVersion `json:"versions" structs:"versions" mapstructure:"versions"`
+}
+
+func pathFetchPublicKeys(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `public_keys`,
+		Fields: map[string]*framework.FieldSchema{
+			"format": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `If set to "authorized_keys" or "known_hosts",
+the keys are returned as plain text in that format rather than as JSON.`,
+			},
+			"host_pattern": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `The host pattern of the known_hosts lines.`,
+				Default:     "*",
+			},
+			"include_retired": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, keys that were retired by deleting the
+CA are included as well.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathFetchPublicKeys,
+		},
+
+		HelpSynopsis: `Retrieve the CA public keys for SSH clients and servers.`,
+		HelpDescription: `This returns the versioned CA public keys of this mount, along with
+lines ready to be used in a TrustedUserCAKeys file (which uses the
+authorized_keys format) and as @cert-authority lines in a known_hosts file.`,
+	}
+}
+
+func (b *backend) pathFetchPublicKeys(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	format := data.Get("format").(string)
+	switch format {
+	case "", "authorized_keys", "known_hosts":
+	default:
+		return logical.ErrorResponse(`format must be "authorized_keys" or "known_hosts"`), nil
+	}
+
+	hostPattern := strings.TrimSpace(data.Get("host_pattern").(string))
+	if hostPattern == "" || strings.ContainsAny(hostPattern, " \t\r\n") {
+		return logical.ErrorResponse("host_pattern must be a single known_hosts pattern list"), nil
+	}
+	includeRetired := data.Get("include_retired").(bool)
+
+	versions, err := caPublicKeyVersionsRead(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentVersion int
+	var authorizedKeys, knownHosts []string
+	keys := []map[string]interface{}{}
+	for _, version := range versions.Versions {
+		retired := !version.RetiredTime.IsZero()
+		if !retired {
+			currentVersion = version.Version
+		}
+		if retired && !includeRetired {
+			continue
+		}
+
+		publicKey, err := parsePublicSSHKey(strings.TrimSpace(version.Key))
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to parse CA public key version %d: {{err}}", version.Version), err)
+		}
+		key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+		comment := fmt.Sprintf("vault-ssh-ca-v%d", version.Version)
+
+		authorizedKeys = append(authorizedKeys, fmt.Sprintf("%s %s", key, comment))
+		knownHosts = append(knownHosts, fmt.Sprintf("@cert-authority %s %s %s", hostPattern, key, comment))
+
+		keyData := map[string]interface{}{
+			"version":     version.Version,
+			"public_key":  key,
+			"fingerprint": ssh.FingerprintSHA256(publicKey),
+		}
+		if !version.CreatedTime.IsZero() {
+			keyData["created_time"] = version.CreatedTime.Format(time.RFC3339)
+		}
+		if retired {
+			keyData["retired_time"] = version.RetiredTime.Format(time.RFC3339)
+		}
+		keys = append(keys, keyData)
+	}
+
+	switch format {
+	case "authorized_keys":
+		return rawPublicKeysResponse(authorizedKeys), nil
+	case "known_hosts":
+		return rawPublicKeysResponse(knownHosts), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"current_version": currentVersion,
+			"keys":            keys,
+			"authorized_keys": strings.Join(authorizedKeys, "\n"),
+			"known_hosts":     strings.Join(knownHosts, "\n"),
+		},
+	}, nil
+}
+
+func rawPublicKeysResponse(lines []string) *logical.Response {
+	var body []byte
+	if len(lines) > 0 {
+		body = []byte(strings.Join(lines, "\n") + "\n")
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "text/plain",
+			logical.HTTPRawBody:     body,
+			logical.HTTPStatusCode:  200,
+		},
+	}
+}
+
+// caPublicKeyVersionsRead returns the CA public key versions of the mount.
+// Mounts configured before keys were versioned get their current key as
+// version 1.
+func caPublicKeyVersionsRead(ctx context.Context, storage logical.Storage) (*caPublicKeyVersions, error) {
+	versions, err := caPublicKeyVersionsGet(ctx, storage)
+	if err != nil || versions != nil {
+		return versions, err
+	}
+
+	versions = &caPublicKeyVersions{}
+	publicKeyEntry, err := caKey(ctx, storage, caPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if publicKeyEntry != nil && publicKeyEntry.Key != "" {
+		versions.Versions = append(versions.Versions, &caPublicKeyVersion{
+			Version: 1,
+			Key:     publicKeyEntry.Key,
+		})
+	}
+
+	return versions, nil
+}
+
+func caPublicKeyVersionsGet(ctx context.Context, storage logical.Storage) (*caPublicKeyVersions, error) {
+	entry, err := storage.Get(ctx, caPublicKeyVersionsStoragePath)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read CA public key versions: {{err}}", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var versions caPublicKeyVersions
+	if err := entry.DecodeJSON(&versions); err != nil {
+		return nil, err
+	}
+	return &versions, nil
+}
+
+func caPublicKeyVersionsWrite(ctx context.Context, storage logical.Storage, versions *caPublicKeyVersions) error {
+	entry, err := logical.StorageEntryJSON(caPublicKeyVersionsStoragePath, versions)
+	if err != nil {
+		return err
+	}
+	if err := storage.Put(ctx, entry); err != nil {
+		return errwrap.Wrapf("failed to store CA public key versions: {{err}}", err)
+	}
+	return nil
+}
+
+// addCAPublicKeyVersion records a newly configured CA public key as the
+// current version. The key must already have been stored.
+func addCAPublicKeyVersion(ctx context.Context, storage logical.Storage, publicKey string) error {
+	// Any previous key was retired when the CA was deleted, so there is
+	// nothing to carry over when no versions were recorded yet
+	versions, err := caPublicKeyVersionsGet(ctx, storage)
+	if err != nil {
+		return err
+	}
+	if versions == nil {
+		versions = &caPublicKeyVersions{}
+	}
+
+	now := time.Now().UTC()
+	version := 1
+	for _, v := range versions.Versions {
+		if v.RetiredTime.IsZero() {
+			v.RetiredTime = now
+		}
+		if v.Version >= version {
+			version = v.Version + 1
+		}
+	}
+	versions.Versions = append(versions.Versions, &caPublicKeyVersion{
+		Version:     version,
+		Key:         publicKey,
+		CreatedTime: now,
+	})
+
+	return caPublicKeyVersionsWrite(ctx, storage, versions)
+}
+
+// retireCAPublicKeyVersion marks the current CA public key as retired
+func retireCAPublicKeyVersion(ctx context.Context, storage logical.Storage) error {
+	versions, err := caPublicKeyVersionsRead(ctx, storage)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, v := range versions.Versions {
+		if v.RetiredTime.IsZero() {
+			v.RetiredTime = now
+		}
+	}
+
+	return caPublicKeyVersionsWrite(ctx, storage, versions)
+}
@@ -0,0 +1,109 @@
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestSSH_PublicKeys(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b, err := Backend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: operation,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	// A key configured before keys were versioned is reported as version 1
+	entry, err := logical.StorageEntryJSON(caPublicKeyStoragePath, &keyStorageEntry{
+		Key: publicKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.StorageView.Put(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	entry, err = logical.StorageEntryJSON(caPrivateKeyStoragePath, &keyStorageEntry{
+		Key: privateKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.StorageView.Put(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := request(logical.ReadOperation, "public_keys", nil)
+	if resp.Data["current_version"] != 1 || len(resp.Data["keys"].([]map[string]interface{})) != 1 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	key := strings.Join(strings.Fields(publicKey)[:2], " ")
+	if resp.Data["authorized_keys"] != key+" vault-ssh-ca-v1" {
+		t.Fatalf("bad authorized_keys: %q", resp.Data["authorized_keys"])
+	}
+	if resp.Data["known_hosts"] != "@cert-authority * "+key+" vault-ssh-ca-v1" {
+		t.Fatalf("bad known_hosts: %q", resp.Data["known_hosts"])
+	}
+
+	// Rotate the CA
+	request(logical.DeleteOperation, "config/ca", nil)
+	resp = request(logical.ReadOperation, "public_keys", nil)
+	if resp.Data["current_version"] != 0 || len(resp.Data["keys"].([]map[string]interface{})) != 0 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	resp = request(logical.UpdateOperation, "config/ca", nil)
+	newKey := strings.TrimSpace(resp.Data["public_key"].(string))
+
+	resp = request(logical.ReadOperation, "public_keys", nil)
+	keys := resp.Data["keys"].([]map[string]interface{})
+	if resp.Data["current_version"] != 2 || len(keys) != 1 || keys[0]["public_key"] != newKey {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	if keys[0]["created_time"] == nil || keys[0]["fingerprint"] == "" {
+		t.Fatalf("bad: %#v", keys[0])
+	}
+
+	// Retired keys can still be distributed while rotating
+	resp = request(logical.ReadOperation, "public_keys", map[string]interface{}{
+		"format":          "known_hosts",
+		"host_pattern":    "*.example.com",
+		"include_retired": true,
+	})
+	if resp.Data[logical.HTTPContentType] != "text/plain" {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	expected := "@cert-authority *.example.com " + key + " vault-ssh-ca-v1\n" +
+		"@cert-authority *.example.com " + newKey + " vault-ssh-ca-v2\n"
+	if actual := string(resp.Data[logical.HTTPRawBody].([]byte)); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+
+	resp = request(logical.ReadOperation, "public_keys", map[string]interface{}{
+		"format": "authorized_keys",
+	})
+	if actual := string(resp.Data[logical.HTTPRawBody].([]byte)); actual != newKey+" vault-ssh-ca-v2\n" {
+		t.Fatalf("bad authorized_keys: %q", actual)
+	}
+}
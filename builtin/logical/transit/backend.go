@@ -38,12 +38,16 @@ func Backend(conf *logical.BackendConfig) *backend {
 			b.pathExportKeys(),
 			b.pathEncrypt(),
 			b.pathDecrypt(),
+			b.pathStreamEncrypt(),
+			b.pathStreamDecrypt(),
 			b.pathDatakey(),
 			b.pathRandom(),
 			b.pathHash(),
 			b.pathHMAC(),
 			b.pathSign(),
 			b.pathVerify(),
+			b.pathSignJWT(),
+			b.pathVerifyJWT(),
 			b.pathBackup(),
 			b.pathRestore(),
 			b.pathTrim(),
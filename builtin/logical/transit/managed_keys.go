@@ -0,0 +1,61 @@
+package transit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// getManagedKey returns the managed key with the given name, if the mount is
+// allowed to use it
+func (b *backend) getManagedKey(ctx context.Context, name string) (logical.ManagedKey, error) {
+	sysView, ok := b.System().(logical.ManagedKeySystemView)
+	if !ok {
+		return nil, errutil.UserError{Err: "managed keys are not supported by this mount"}
+	}
+
+	key, err := sysView.ManagedKey(ctx, name)
+	if err != nil {
+		return nil, errutil.UserError{Err: err.Error()}
+	}
+	return key, nil
+}
+
+// loadManagedKey sets the managed key of a policy backed by one, before
+// signing or decrypting with it
+func (b *backend) loadManagedKey(ctx context.Context, p *keysutil.Policy) error {
+	if p.ManagedKeyName == "" {
+		return nil
+	}
+
+	key, err := b.getManagedKey(ctx, p.ManagedKeyName)
+	if err != nil {
+		return err
+	}
+	p.SetManagedKey(key)
+	return nil
+}
+
+// managedKeyType returns the key type matching the public key of a managed
+// key
+func managedKeyType(key logical.ManagedKey) (keysutil.KeyType, error) {
+	switch pub := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		if pub.Curve == elliptic.P256() {
+			return keysutil.KeyType_ECDSA_P256, nil
+		}
+	case *rsa.PublicKey:
+		switch pub.N.BitLen() {
+		case 2048:
+			return keysutil.KeyType_RSA2048, nil
+		case 4096:
+			return keysutil.KeyType_RSA4096, nil
+		}
+	}
+	return 0, errutil.UserError{Err: "managed keys must be P-256 ECDSA keys, or 2048 or 4096 bit RSA keys"}
+}
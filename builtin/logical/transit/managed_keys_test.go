@@ -0,0 +1,180 @@
+package transit_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/builtin/logical/transit"
+	vaulthttp "github.com/hashicorp/vault/http"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+	"github.com/hashicorp/vault/vault/managedkeys"
+)
+
+func TestTransit_ManagedKeys(t *testing.T) {
+	coreConfig := &vault.CoreConfig{
+		LogicalBackends: map[string]logical.Factory{
+			"transit": transit.Factory,
+		},
+		ManagedKeyTypes: map[string]managedkeys.Factory{
+			"test": managedkeys.NewTestFactory(),
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: vaulthttp.Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	client := cluster.Cores[0].Client
+
+	if err := client.Sys().Mount("transit", &api.MountInput{
+		Type: "transit",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, keyType := range map[string]string{"rsa": "rsa", "ec": "ec"} {
+		_, err := client.Logical().Write("sys/managed-keys/"+name, map[string]interface{}{
+			"type":           "test",
+			"config":         map[string]interface{}{"key_id": name, "key_type": keyType},
+			"allowed_mounts": "transit/",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The type of the key is the one of the managed key
+	_, err := client.Logical().Write("transit/keys/rsa", map[string]interface{}{
+		"type":             "ecdsa-p256",
+		"managed_key_name": "rsa",
+	})
+	if err == nil {
+		t.Fatal("expected an error creating a key of another type than the managed key")
+	}
+	_, err = client.Logical().Write("transit/keys/rsa", map[string]interface{}{
+		"managed_key_name": "rsa",
+		"exportable":       true,
+	})
+	if err == nil {
+		t.Fatal("expected an error creating an exportable key")
+	}
+
+	_, err = client.Logical().Write("transit/keys/rsa", map[string]interface{}{
+		"managed_key_name": "rsa",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("transit/keys/ec", map[string]interface{}{
+		"managed_key_name": "ec",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Logical().Read("transit/keys/rsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["type"] != "rsa-2048" || resp.Data["managed_key_name"] != "rsa" {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	keys := resp.Data["keys"].(map[string]interface{})
+	if pub := keys["1"].(map[string]interface{})["public_key"].(string); !strings.Contains(pub, "PUBLIC KEY") {
+		t.Fatalf("expected the public key to be returned, got %q", pub)
+	}
+
+	// Encryption uses the stored public key, decryption the managed key
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+	resp, err = client.Logical().Write("transit/encrypt/rsa", map[string]interface{}{
+		"plaintext": plaintext,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := resp.Data["ciphertext"].(string)
+
+	resp, err = client.Logical().Write("transit/decrypt/rsa", map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["plaintext"] != plaintext {
+		t.Fatalf("bad: expected %q, got %q", plaintext, resp.Data["plaintext"])
+	}
+
+	input := base64.StdEncoding.EncodeToString([]byte("sign me"))
+	for _, tc := range []struct {
+		key  string
+		data map[string]interface{}
+	}{
+		{"rsa", map[string]interface{}{"signature_algorithm": "pss"}},
+		{"rsa", map[string]interface{}{"signature_algorithm": "pkcs1v15"}},
+		{"ec", map[string]interface{}{"marshaling_algorithm": "asn1"}},
+		{"ec", map[string]interface{}{"marshaling_algorithm": "jws"}},
+	} {
+		tc.data["input"] = input
+		resp, err = client.Logical().Write("transit/sign/"+tc.key, tc.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tc.data["signature"] = resp.Data["signature"]
+		resp, err = client.Logical().Write("transit/verify/"+tc.key, tc.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data["valid"] != true {
+			t.Fatalf("expected the signature of %s with %v to be valid", tc.key, tc.data)
+		}
+	}
+
+	// The private key is held by the managed key, so it can't be rotated or
+	// exported
+	if _, err := client.Logical().Write("transit/keys/rsa/rotate", nil); err == nil {
+		t.Fatal("expected an error rotating the key")
+	}
+	_, err = client.Logical().Write("transit/keys/rsa/config", map[string]interface{}{
+		"exportable": true,
+	})
+	if err == nil {
+		t.Fatal("expected an error making the key exportable")
+	}
+
+	// The mount has to be allowed to use the managed key
+	_, err = client.Logical().Write("sys/managed-keys/rsa", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "rsa"},
+		"allowed_mounts": "other/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("transit/decrypt/rsa", map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	if err == nil || !strings.Contains(err.Error(), "is not allowed to use managed key") {
+		t.Fatalf("expected the mount not to be allowed to use the key, got: %v", err)
+	}
+
+	// A managed key holding another private key is rejected
+	_, err = client.Logical().Write("sys/managed-keys/ec", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "other", "key_type": "ec"},
+		"allowed_mounts": "transit/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("transit/sign/ec", map[string]interface{}{
+		"input": input,
+	})
+	if err == nil || !strings.Contains(err.Error(), "doesn't hold the private key") {
+		t.Fatalf("expected the managed key not to match the key, got: %v", err)
+	}
+}
@@ -47,6 +47,13 @@ the latest version of the key is allowed.`,
 				Type:        framework.TypeBool,
 				Description: `Enables taking a backup of the named key in plaintext format. Once set, this cannot be disabled.`,
 			},
+
+			"stream_max_chunk_size": &framework.FieldSchema{
+				Type: framework.TypeInt,
+				Description: `The largest chunk, in bytes, that can be encrypted
+or decrypted with the stream endpoints. If set to zero,
+the stream endpoints are disabled for this key.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -84,6 +91,7 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 	originalDeletionAllowed := p.DeletionAllowed
 	originalExportable := p.Exportable
 	originalAllowPlaintextBackup := p.AllowPlaintextBackup
+	originalStreamMaxChunkSize := p.StreamMaxChunkSize
 
 	defer func() {
 		if retErr != nil || (resp != nil && resp.IsError()) {
@@ -92,6 +100,7 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 			p.DeletionAllowed = originalDeletionAllowed
 			p.Exportable = originalExportable
 			p.AllowPlaintextBackup = originalAllowPlaintextBackup
+			p.StreamMaxChunkSize = originalStreamMaxChunkSize
 		}
 	}()
 
@@ -170,6 +179,9 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 		exportable := exportableRaw.(bool)
 		// Don't unset the already set value
 		if exportable && !p.Exportable {
+			if p.ManagedKeyName != "" {
+				return logical.ErrorResponse("keys backed by a managed key can't be exported"), nil
+			}
 			p.Exportable = exportable
 			persistNeeded = true
 		}
@@ -180,11 +192,31 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 		allowPlaintextBackup := allowPlaintextBackupRaw.(bool)
 		// Don't unset the already set value
 		if allowPlaintextBackup && !p.AllowPlaintextBackup {
+			if p.ManagedKeyName != "" {
+				return logical.ErrorResponse("keys backed by a managed key can't be backed up"), nil
+			}
 			p.AllowPlaintextBackup = allowPlaintextBackup
 			persistNeeded = true
 		}
 	}
 
+	streamMaxChunkSizeRaw, ok := d.GetOk("stream_max_chunk_size")
+	if ok {
+		streamMaxChunkSize := streamMaxChunkSizeRaw.(int)
+
+		if streamMaxChunkSize < 0 || streamMaxChunkSize > maxStreamChunkSize {
+			return logical.ErrorResponse(fmt.Sprintf("stream max chunk size must be between 0 and %d", maxStreamChunkSize)), nil
+		}
+
+		if streamMaxChunkSize != p.StreamMaxChunkSize {
+			if streamMaxChunkSize > 0 && !p.Type.EncryptionSupported() {
+				return logical.ErrorResponse("stream encryption is not supported by the key type"), nil
+			}
+			p.StreamMaxChunkSize = streamMaxChunkSize
+			persistNeeded = true
+		}
+	}
+
 	if !persistNeeded {
 		return nil, nil
 	}
@@ -193,7 +225,7 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 	case p.MinAvailableVersion > p.MinEncryptionVersion:
 		return logical.ErrorResponse("min encryption version should not be less than min available version"), nil
 	case p.MinAvailableVersion > p.MinDecryptionVersion:
-		return logical.ErrorResponse("min decryption version should not be less then min available version"), nil
+		return logical.ErrorResponse("min decryption version should not be less than min available version"), nil
 	}
 
 	if len(resp.Warnings) == 0 {
@@ -0,0 +1,327 @@
+package transit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+func (b *backend) pathSignJWT() *framework.Path {
+	return &framework.Path{
+		Pattern: "sign-jwt/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The key to use",
+			},
+
+			"claims": {
+				Type:        framework.TypeMap,
+				Description: "The claims to include in the JWT",
+			},
+
+			"ttl": {
+				Type: framework.TypeDurationSecond,
+				Description: `If set, the "iat" claim is set to the current time
+and the "exp" claim to the current time plus this value, overriding any
+values provided in claims.`,
+			},
+
+			"key_version": {
+				Type: framework.TypeInt,
+				Description: `The version of the key to use for signing.
+Must be 0 (for latest) or a value greater than or equal
+to the min_encryption_version configured on the key.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathSignJWTWrite,
+		},
+
+		HelpSynopsis:    pathSignJWTHelpSyn,
+		HelpDescription: pathSignJWTHelpDesc,
+	}
+}
+
+func (b *backend) pathVerifyJWT() *framework.Path {
+	return &framework.Path{
+		Pattern: "verify-jwt/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The key to use",
+			},
+
+			"token": {
+				Type:        framework.TypeString,
+				Description: "The JWT to verify",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathVerifyJWTWrite,
+		},
+
+		HelpSynopsis:    pathVerifyJWTHelpSyn,
+		HelpDescription: pathVerifyJWTHelpDesc,
+	}
+}
+
+// jwtAlgorithm returns the JWS algorithm used for JWTs signed with keys of the
+// given policy.
+func jwtAlgorithm(p *keysutil.Policy) (string, error) {
+	if p.Derived {
+		return "", fmt.Errorf("JWT signing is not supported with derived keys")
+	}
+
+	switch p.Type {
+	case keysutil.KeyType_ECDSA_P256:
+		return "ES256", nil
+	case keysutil.KeyType_ED25519:
+		return "EdDSA", nil
+	case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA4096:
+		return "RS256", nil
+	default:
+		return "", fmt.Errorf("key type %v does not support signing", p.Type)
+	}
+}
+
+// jwtSignatureInput returns the value passed to the policy to sign or verify
+// the given JWT signing input.
+func jwtSignatureInput(p *keysutil.Policy, signingInput string) []byte {
+	if !p.Type.HashSignatureInput() {
+		return []byte(signingInput)
+	}
+	sum := sha256.Sum256([]byte(signingInput))
+	return sum[:]
+}
+
+// jwtVersionPrefix returns the prefix transit prepends to signatures made with
+// the given key version.
+func jwtVersionPrefix(p *keysutil.Policy, ver int) string {
+	template := p.VersionTemplate
+	if template == "" {
+		template = keysutil.DefaultVersionTemplate
+	}
+	return strings.Replace(template, "{{version}}", strconv.Itoa(ver), -1)
+}
+
+func (b *backend) pathSignJWTWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	ver := d.Get("key_version").(int)
+
+	claims := d.Get("claims").(map[string]interface{})
+	if claims == nil {
+		claims = map[string]interface{}{}
+	}
+	if ttl := d.Get("ttl").(int); ttl > 0 {
+		now := time.Now()
+		claims["iat"] = now.Unix()
+		claims["exp"] = now.Add(time.Duration(ttl) * time.Second).Unix()
+	}
+
+	// Get the policy
+	p, _, err := b.lm.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+	defer p.Unlock()
+
+	alg, err := jwtAlgorithm(p)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	if err := b.loadManagedKey(ctx, p); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	if ver == 0 {
+		ver = p.LatestVersion
+	}
+
+	headerJSON, err := json.Marshal(jwtHeader{
+		Algorithm: alg,
+		Type:      "JWT",
+		KeyID:     strconv.Itoa(ver),
+	})
+	if err != nil {
+		return nil, err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("unable to encode claims: %v", err)), logical.ErrInvalidRequest
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	sig, err := p.Sign(ver, nil, jwtSignatureInput(p, signingInput), keysutil.HashTypeSHA2256, "pkcs1v15", keysutil.MarshalingTypeJWS)
+	if err != nil {
+		return nil, err
+	}
+	if sig == nil {
+		return nil, fmt.Errorf("signature could not be computed")
+	}
+
+	// The policy's signature carries the vault version prefix and, for JWS
+	// marshaling, is already unpadded url-safe base64
+	signature := strings.TrimPrefix(sig.Signature, jwtVersionPrefix(p, ver))
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"token":       signingInput + "." + signature,
+			"key_version": ver,
+		},
+	}, nil
+}
+
+func (b *backend) pathVerifyJWTWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	token := d.Get("token").(string)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return logical.ErrorResponse("token is not a valid JWT"), logical.ErrInvalidRequest
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return logical.ErrorResponse("unable to decode JWT header"), logical.ErrInvalidRequest
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return logical.ErrorResponse("unable to parse JWT header"), logical.ErrInvalidRequest
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return logical.ErrorResponse("unable to decode JWT claims"), logical.ErrInvalidRequest
+	}
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(claimsJSON)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return logical.ErrorResponse("unable to parse JWT claims"), logical.ErrInvalidRequest
+	}
+
+	ver, err := strconv.Atoi(header.KeyID)
+	if err != nil || ver <= 0 {
+		return logical.ErrorResponse("JWT header does not contain a valid key version"), logical.ErrInvalidRequest
+	}
+
+	// Get the policy
+	p, _, err := b.lm.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+	defer p.Unlock()
+
+	alg, err := jwtAlgorithm(p)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	// Never trust the algorithm in the header beyond checking that it is the
+	// one this key produces
+	if header.Algorithm != alg {
+		return invalidJWTResponse(), nil
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	valid, err := p.VerifySignature(nil, jwtSignatureInput(p, signingInput), keysutil.HashTypeSHA2256, "pkcs1v15", keysutil.MarshalingTypeJWS, jwtVersionPrefix(p, ver)+parts[2])
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if !valid {
+		return invalidJWTResponse(), nil
+	}
+
+	now := time.Now().Unix()
+	if exp, ok := jwtNumericClaim(claims, "exp"); ok && now >= exp {
+		return invalidJWTResponse(), nil
+	}
+	if nbf, ok := jwtNumericClaim(claims, "nbf"); ok && now < nbf {
+		return invalidJWTResponse(), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"valid":       true,
+			"claims":      claims,
+			"key_version": ver,
+		},
+	}, nil
+}
+
+func invalidJWTResponse() *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"valid": false,
+		},
+	}
+}
+
+func jwtNumericClaim(claims map[string]interface{}, name string) (int64, bool) {
+	num, ok := claims[name].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if v, err := num.Int64(); err == nil {
+		return v, true
+	}
+	f, err := num.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+const pathSignJWTHelpSyn = `Generate a JWT signed by a named key`
+
+const pathSignJWTHelpDesc = `
+Signs the given claims as a JWT using the named key. The key version used is
+recorded in the "kid" header so that the token can be verified after the key
+is rotated. ECDSA P-256 keys produce ES256 tokens, ed25519 keys EdDSA tokens
+and RSA keys RS256 tokens.
+`
+
+const pathVerifyJWTHelpSyn = `Verify a JWT signed by a named key`
+
+const pathVerifyJWTHelpDesc = `
+Verifies that the given JWT was signed by the key version named in its "kid"
+header and that it has not expired, returning its claims if it is valid.
+`
@@ -0,0 +1,156 @@
+package transit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+func TestTransit_SignVerifyJWT(t *testing.T) {
+	for _, keyType := range []string{"ecdsa-p256", "ed25519", "rsa-2048"} {
+		t.Run(keyType, func(t *testing.T) {
+			testTransit_SignVerifyJWT(t, keyType)
+		})
+	}
+}
+
+func testTransit_SignVerifyJWT(t *testing.T, keyType string) {
+	b, storage := createBackendWithSysView(t)
+
+	doReq := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: path %s, err: %v, resp: %#v", path, err, resp)
+		}
+		return resp
+	}
+
+	doReq("keys/foo", map[string]interface{}{"type": keyType})
+
+	resp := doReq("sign-jwt/foo", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "web"},
+		"ttl":    "1m",
+	})
+	token := resp.Data["token"].(string)
+	if resp.Data["key_version"].(int) != 1 {
+		t.Fatalf("bad key version: %#v", resp.Data)
+	}
+
+	resp = doReq("verify-jwt/foo", map[string]interface{}{"token": token})
+	if !resp.Data["valid"].(bool) {
+		t.Fatal("expected token to be valid")
+	}
+	if resp.Data["claims"].(map[string]interface{})["sub"] != "web" {
+		t.Fatalf("bad claims: %#v", resp.Data["claims"])
+	}
+
+	// Tokens issued before a rotation remain verifiable
+	doReq("keys/foo/rotate", nil)
+	resp = doReq("sign-jwt/foo", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "web"},
+	})
+	if resp.Data["key_version"].(int) != 2 {
+		t.Fatalf("bad key version after rotation: %#v", resp.Data)
+	}
+	resp = doReq("verify-jwt/foo", map[string]interface{}{"token": token})
+	if !resp.Data["valid"].(bool) {
+		t.Fatal("expected token signed with the previous version to be valid")
+	}
+
+	// Tampering with the claims invalidates the signature
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + jwtEncode(t, map[string]interface{}{"sub": "admin"}) + "." + parts[2]
+	resp = doReq("verify-jwt/foo", map[string]interface{}{"token": tampered})
+	if resp.Data["valid"].(bool) {
+		t.Fatal("expected tampered token to be invalid")
+	}
+
+	// Expired tokens are invalid
+	resp = doReq("sign-jwt/foo", map[string]interface{}{
+		"claims": map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()},
+	})
+	resp = doReq("verify-jwt/foo", map[string]interface{}{"token": resp.Data["token"]})
+	if resp.Data["valid"].(bool) {
+		t.Fatal("expected expired token to be invalid")
+	}
+}
+
+func TestTransit_SignJWT_interop(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	_, err := b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "keys/foo",
+		Data:      map[string]interface{}{"type": "ecdsa-p256"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "sign-jwt/foo",
+		Data: map[string]interface{}{
+			"claims": map[string]interface{}{"sub": "web"},
+		},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	p, _, err := b.lm.GetPolicy(context.Background(), keysutil.PolicyRequest{
+		Storage: storage,
+		Name:    "foo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyEntry := p.Keys[strconv.Itoa(p.LatestVersion)]
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     keyEntry.EC_X,
+		Y:     keyEntry.EC_Y,
+	}
+
+	sig, err := jose.ParseSigned(resp.Data["token"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Signatures[0].Header.KeyID != "1" || sig.Signatures[0].Header.Algorithm != "ES256" {
+		t.Fatalf("bad header: %#v", sig.Signatures[0].Header)
+	}
+	payload, err := sig.Verify(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != `{"sub":"web"}` {
+		t.Fatalf("bad payload: %s", payload)
+	}
+}
+
+func jwtEncode(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -88,6 +88,15 @@ key in plaintext format. Once set,
 this cannot be disabled.`,
 			},
 
+			"managed_key_name": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `The name of the managed key holding the
+private key, instead of generating one. The
+type of the key is the one of the managed
+key, which must be an ECDSA P-256 or an
+RSA 2048 or 4096 bit key.`,
+			},
+
 			"context": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `Base64 encoded context for key derivation.
@@ -155,6 +164,28 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		return logical.ErrorResponse(fmt.Sprintf("unknown key type %v", keyType)), logical.ErrInvalidRequest
 	}
 
+	if managedKeyName := d.Get("managed_key_name").(string); managedKeyName != "" {
+		if derived || exportable || allowPlaintextBackup {
+			return logical.ErrorResponse("keys backed by a managed key can't be derived, exported or backed up"), logical.ErrInvalidRequest
+		}
+
+		key, err := b.getManagedKey(ctx, managedKeyName)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		managedType, err := managedKeyType(key)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		if _, ok := d.GetOk("type"); ok && managedType != polReq.KeyType {
+			return logical.ErrorResponse(fmt.Sprintf("managed key %q is a key of type %v", managedKeyName, managedType)), logical.ErrInvalidRequest
+		}
+
+		polReq.KeyType = managedType
+		polReq.ManagedKeyName = managedKeyName
+		polReq.ManagedKey = key
+	}
+
 	p, upserted, err := b.lm.GetPolicy(ctx, polReq)
 	if err != nil {
 		return nil, err
@@ -212,6 +243,7 @@ func (b *backend) pathPolicyRead(ctx context.Context, req *logical.Request, d *f
 			"latest_version":         p.LatestVersion,
 			"exportable":             p.Exportable,
 			"allow_plaintext_backup": p.AllowPlaintextBackup,
+			"stream_max_chunk_size":  p.StreamMaxChunkSize,
 			"supports_encryption":    p.Type.EncryptionSupported(),
 			"supports_decryption":    p.Type.DecryptionSupported(),
 			"supports_signing":       p.Type.SigningSupported(),
@@ -219,6 +251,10 @@ func (b *backend) pathPolicyRead(ctx context.Context, req *logical.Request, d *f
 		},
 	}
 
+	if p.ManagedKeyName != "" {
+		resp.Data["managed_key_name"] = p.ManagedKeyName
+	}
+
 	if p.BackupInfo != nil {
 		resp.Data["backup_info"] = map[string]interface{}{
 			"time":    p.BackupInfo.Time,
@@ -303,7 +339,11 @@ func (b *backend) pathPolicyRead(ctx context.Context, req *logical.Request, d *f
 
 				// Encode the RSA public key in PEM format to return over the
 				// API
-				derBytes, err := x509.MarshalPKIXPublicKey(v.RSAKey.Public())
+				pubKey := v.RSAPublicKey
+				if v.RSAKey != nil {
+					pubKey = &v.RSAKey.PublicKey
+				}
+				derBytes, err := x509.MarshalPKIXPublicKey(pubKey)
 				if err != nil {
 					return nil, errwrap.Wrapf("error marshaling RSA public key: {{err}}", err)
 				}
@@ -129,6 +129,11 @@ func (b *backend) pathRewrapWrite(ctx context.Context, req *logical.Request, d *
 		p.Lock(false)
 	}
 
+	if err := b.loadManagedKey(ctx, p); err != nil {
+		p.Unlock()
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
 	for i, item := range batchInputItems {
 		if batchResponseItems[i].Error != "" {
 			continue
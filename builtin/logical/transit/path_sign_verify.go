@@ -95,6 +95,7 @@ including ed25519.`,
 				Type:        framework.TypeString,
 				Default:     "sha2-256",
 				Description: `Deprecated: use "hash_algorithm" instead.`,
+				Deprecated:  true,
 			},
 
 			"urlalgorithm": {
@@ -189,6 +190,7 @@ Defaults to "sha2-256". Not valid for all key types.`,
 				Type:        framework.TypeString,
 				Default:     "sha2-256",
 				Description: `Deprecated: use "hash_algorithm" instead.`,
+				Deprecated:  true,
 			},
 
 			"prehashed": {
@@ -218,16 +220,25 @@ Options are 'pss' or 'pkcs1v15'. Defaults to 'pss'`,
 	}
 }
 
+// hashAlgorithmParam returns the hash algorithm of the URL, or else of the
+// hash_algorithm parameter, falling back to the deprecated algorithm parameter
+// when only that one is set
+func hashAlgorithmParam(d *framework.FieldData) string {
+	if hashAlgorithmStr := d.Get("urlalgorithm").(string); hashAlgorithmStr != "" {
+		return hashAlgorithmStr
+	}
+	if _, ok := d.GetOk("hash_algorithm"); !ok {
+		if hashAlgorithmRaw, ok := d.GetOk("algorithm"); ok {
+			return hashAlgorithmRaw.(string)
+		}
+	}
+	return d.Get("hash_algorithm").(string)
+}
+
 func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := d.Get("name").(string)
 	ver := d.Get("key_version").(int)
-	hashAlgorithmStr := d.Get("urlalgorithm").(string)
-	if hashAlgorithmStr == "" {
-		hashAlgorithmStr = d.Get("hash_algorithm").(string)
-		if hashAlgorithmStr == "" {
-			hashAlgorithmStr = d.Get("algorithm").(string)
-		}
-	}
+	hashAlgorithmStr := hashAlgorithmParam(d)
 
 	hashAlgorithm, ok := keysutil.HashTypeMap[hashAlgorithmStr]
 	if !ok {
@@ -263,6 +274,11 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 		return logical.ErrorResponse(fmt.Sprintf("key type %v does not support signing", p.Type)), logical.ErrInvalidRequest
 	}
 
+	if err := b.loadManagedKey(ctx, p); err != nil {
+		p.Unlock()
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
 	batchInputRaw := d.Raw["batch_input"]
 	var batchInputItems []batchRequestSignItem
 	if batchInputRaw != nil {
@@ -430,13 +446,7 @@ func (b *backend) pathVerifyWrite(ctx context.Context, req *logical.Request, d *
 	}
 
 	name := d.Get("name").(string)
-	hashAlgorithmStr := d.Get("urlalgorithm").(string)
-	if hashAlgorithmStr == "" {
-		hashAlgorithmStr = d.Get("hash_algorithm").(string)
-		if hashAlgorithmStr == "" {
-			hashAlgorithmStr = d.Get("algorithm").(string)
-		}
-	}
+	hashAlgorithmStr := hashAlgorithmParam(d)
 
 	hashAlgorithm, ok := keysutil.HashTypeMap[hashAlgorithmStr]
 	if !ok {
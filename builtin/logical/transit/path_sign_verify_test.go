@@ -616,3 +616,58 @@ func TestTransit_SignVerify_ED25519(t *testing.T) {
 	outcome[1].valid = false
 	verifyRequest(req, false, outcome, "bar", goodsig, true)
 }
+
+func TestTransit_SignVerify_DeprecatedAlgorithm(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	write := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	write("keys/foo", map[string]interface{}{"type": "ecdsa-p256"})
+
+	// The deprecated parameter is honored, with a warning
+	resp := write("sign/foo", map[string]interface{}{
+		"input":     "dGhlIHF1aWNrIGJyb3duIGZveA==",
+		"algorithm": "sha2-512",
+	})
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], `"algorithm"`) {
+		t.Fatalf("expected a warning about the deprecated parameter, got %q", resp.Warnings)
+	}
+	sig := resp.Data["signature"].(string)
+
+	for algorithm, valid := range map[string]bool{"sha2-512": true, "sha2-256": false} {
+		resp = write("verify/foo", map[string]interface{}{
+			"input":          "dGhlIHF1aWNrIGJyb3duIGZveA==",
+			"signature":      sig,
+			"hash_algorithm": algorithm,
+		})
+		if resp.Data["valid"].(bool) != valid {
+			t.Fatalf("%s: expected valid to be %t", algorithm, valid)
+		}
+	}
+
+	// but hash_algorithm takes precedence
+	resp = write("sign/foo", map[string]interface{}{
+		"input":          "dGhlIHF1aWNrIGJyb3duIGZveA==",
+		"algorithm":      "sha2-512",
+		"hash_algorithm": "sha2-256",
+	})
+	resp = write("verify/foo", map[string]interface{}{
+		"input":     "dGhlIHF1aWNrIGJyb3duIGZveA==",
+		"signature": resp.Data["signature"].(string),
+	})
+	if !resp.Data["valid"].(bool) {
+		t.Fatal("expected the signature to be made with hash_algorithm")
+	}
+}
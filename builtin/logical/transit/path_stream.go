@@ -0,0 +1,345 @@
+package transit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// maxStreamChunkSize is the upper bound of the per-key stream max chunk
+	// size, which keeps each request reasonably small
+	maxStreamChunkSize = 32 * 1024 * 1024
+
+	streamKeySize = 32
+)
+
+// streamState is what the stream header protects: the key the chunks of a
+// stream are encrypted with. Each chunk is sealed with a random nonce, which
+// is prepended to its ciphertext, so re-encrypting a chunk never reuses a
+// nonce. The chunk index and a flag marking the last chunk are bound as
+// additional data, so chunks can't be reordered, dropped or have the stream
+// truncated without decryption failing.
+type streamState struct {
+	aead cipher.AEAD
+}
+
+// additionalData returns the additional data binding a chunk to its position
+// in the stream
+func (s *streamState) additionalData(index int, final bool) []byte {
+	ad := make([]byte, 5)
+	binary.BigEndian.PutUint32(ad, uint32(index))
+	if final {
+		ad[4] = 1
+	}
+	return ad
+}
+
+func (s *streamState) seal(index int, final bool, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plaintext, s.additionalData(index, final)), nil
+}
+
+func (s *streamState) open(index int, final bool, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < s.aead.NonceSize()+s.aead.Overhead() {
+		return nil, fmt.Errorf("invalid ciphertext length")
+	}
+	nonce, ciphertext := ciphertext[:s.aead.NonceSize()], ciphertext[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, s.additionalData(index, final))
+}
+
+// chunkSize returns the size of the plaintext of the given chunk ciphertext
+func (s *streamState) chunkSize(ciphertext []byte) int {
+	return len(ciphertext) - s.aead.NonceSize() - s.aead.Overhead()
+}
+
+func newStreamState(raw []byte) (*streamState, error) {
+	if len(raw) != streamKeySize {
+		return nil, errutil.UserError{Err: "invalid stream header"}
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, errutil.InternalError{Err: err.Error()}
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errutil.InternalError{Err: err.Error()}
+	}
+	return &streamState{
+		aead: aead,
+	}, nil
+}
+
+func streamFields(dataField, dataDescription string) map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"name": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Name of the key",
+		},
+
+		"header": &framework.FieldSchema{
+			Type: framework.TypeString,
+			Description: `The stream header returned when encrypting the first
+chunk of the stream. When encrypting, leave empty to start a new stream.`,
+		},
+
+		"index": &framework.FieldSchema{
+			Type:        framework.TypeInt,
+			Description: "The position of the chunk in the stream, starting at 0",
+		},
+
+		"final": &framework.FieldSchema{
+			Type:        framework.TypeBool,
+			Description: "Whether this is the last chunk of the stream",
+		},
+
+		"context": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Base64 encoded context for key derivation. Required if key derivation is enabled",
+		},
+
+		dataField: &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: dataDescription,
+		},
+	}
+}
+
+func (b *backend) pathStreamEncrypt() *framework.Path {
+	return &framework.Path{
+		Pattern: "stream/encrypt/" + framework.GenericNameRegex("name"),
+		Fields:  streamFields("plaintext", "Base64 encoded plaintext of the chunk"),
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathStreamEncryptWrite,
+		},
+
+		HelpSynopsis:    pathStreamEncryptHelpSyn,
+		HelpDescription: pathStreamHelpDesc,
+	}
+}
+
+func (b *backend) pathStreamDecrypt() *framework.Path {
+	return &framework.Path{
+		Pattern: "stream/decrypt/" + framework.GenericNameRegex("name"),
+		Fields:  streamFields("ciphertext", "Base64 encoded ciphertext of the chunk"),
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathStreamDecryptWrite,
+		},
+
+		HelpSynopsis:    pathStreamDecryptHelpSyn,
+		HelpDescription: pathStreamHelpDesc,
+	}
+}
+
+// streamPolicy fetches and read locks the policy for a stream request. The
+// caller must unlock the returned policy.
+func (b *backend) streamPolicy(ctx context.Context, req *logical.Request, d *framework.FieldData) (*keysutil.Policy, *logical.Response, error) {
+	p, _, err := b.lm.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    d.Get("name").(string),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+
+	if p.StreamMaxChunkSize == 0 {
+		p.Unlock()
+		return nil, logical.ErrorResponse("stream encryption is not enabled for this key; set stream_max_chunk_size in the key config"), logical.ErrInvalidRequest
+	}
+	if p.ConvergentEncryption {
+		p.Unlock()
+		return nil, logical.ErrorResponse("stream encryption is not supported for keys with convergent encryption"), logical.ErrInvalidRequest
+	}
+
+	return p, nil, nil
+}
+
+func streamIndex(d *framework.FieldData) (int, error) {
+	index := d.Get("index").(int)
+	if index < 0 || int64(index) > math.MaxUint32 {
+		return 0, fmt.Errorf("index must be between 0 and %d", uint64(math.MaxUint32))
+	}
+	return index, nil
+}
+
+func streamContext(d *framework.FieldData) ([]byte, error) {
+	encodedContext := d.Get("context").(string)
+	if encodedContext == "" {
+		return nil, nil
+	}
+	decodedContext, err := base64.StdEncoding.DecodeString(encodedContext)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to base64-decode context: {{err}}", err)
+	}
+	return decodedContext, nil
+}
+
+// openStreamHeader unwraps the stream state protected by the header
+func openStreamHeader(p *keysutil.Policy, decodedContext []byte, header string) (*streamState, error) {
+	encoded, err := p.Decrypt(decodedContext, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errutil.InternalError{Err: err.Error()}
+	}
+	return newStreamState(raw)
+}
+
+func (b *backend) pathStreamEncryptWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	index, err := streamIndex(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	decodedContext, err := streamContext(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(d.Get("plaintext").(string))
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to base64-decode plaintext: %v", err)), logical.ErrInvalidRequest
+	}
+
+	p, resp, err := b.streamPolicy(ctx, req, d)
+	if p == nil {
+		return resp, err
+	}
+	defer p.Unlock()
+
+	if len(plaintext) > p.StreamMaxChunkSize {
+		return logical.ErrorResponse(fmt.Sprintf("chunk of %d bytes exceeds the stream max chunk size of %d bytes", len(plaintext), p.StreamMaxChunkSize)), logical.ErrInvalidRequest
+	}
+
+	var state *streamState
+	header := d.Get("header").(string)
+	if header == "" {
+		if index != 0 {
+			return logical.ErrorResponse("a header is required to encrypt chunks after the first one"), logical.ErrInvalidRequest
+		}
+
+		raw := make([]byte, streamKeySize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		header, err = p.Encrypt(0, decodedContext, nil, base64.StdEncoding.EncodeToString(raw))
+		if err != nil {
+			switch err.(type) {
+			case errutil.UserError:
+				return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+			default:
+				return nil, err
+			}
+		}
+		state, err = newStreamState(raw)
+	} else {
+		state, err = openStreamHeader(p, decodedContext, header)
+	}
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		default:
+			return nil, err
+		}
+	}
+
+	ciphertext, err := state.seal(index, d.Get("final").(bool), plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"header":     header,
+			"ciphertext": base64.StdEncoding.EncodeToString(ciphertext),
+		},
+	}, nil
+}
+
+func (b *backend) pathStreamDecryptWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	header := d.Get("header").(string)
+	if header == "" {
+		return logical.ErrorResponse("missing header"), logical.ErrInvalidRequest
+	}
+	index, err := streamIndex(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	decodedContext, err := streamContext(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(d.Get("ciphertext").(string))
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to base64-decode ciphertext: %v", err)), logical.ErrInvalidRequest
+	}
+
+	p, resp, err := b.streamPolicy(ctx, req, d)
+	if p == nil {
+		return resp, err
+	}
+	defer p.Unlock()
+
+	state, err := openStreamHeader(p, decodedContext, header)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		default:
+			return nil, err
+		}
+	}
+
+	if state.chunkSize(ciphertext) > p.StreamMaxChunkSize {
+		return logical.ErrorResponse(fmt.Sprintf("chunk exceeds the stream max chunk size of %d bytes", p.StreamMaxChunkSize)), logical.ErrInvalidRequest
+	}
+
+	plaintext, err := state.open(index, d.Get("final").(bool), ciphertext)
+	if err != nil {
+		return logical.ErrorResponse("failed to decrypt chunk; the chunk, its index or final flag do not match the stream"), logical.ErrInvalidRequest
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+		},
+	}, nil
+}
+
+const pathStreamEncryptHelpSyn = `Encrypt a chunk of a large payload using a named key`
+
+const pathStreamDecryptHelpSyn = `Decrypt a chunk of a large payload using a named key`
+
+const pathStreamHelpDesc = `
+These paths encrypt and decrypt payloads too large for a single request as a
+stream of chunks, each no larger than the stream_max_chunk_size configured on
+the key. Encrypting the first chunk without a header starts a new stream and
+returns its header, a regular ciphertext of the named key protecting the
+stream's data key, which must be passed along with every other chunk of the
+stream. Each chunk is encrypted with a random nonce and is bound to its index
+and to whether it is the final chunk, so chunks that are reordered, dropped or
+truncated from the end of the stream fail to decrypt.
+`
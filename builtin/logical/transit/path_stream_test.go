@@ -0,0 +1,173 @@
+package transit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestTransit_Stream(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+
+	doReq := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	mustReq := func(path string, data map[string]interface{}) *logical.Response {
+		resp, err := doReq(path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("path %q: bad: err: %v\nresp: %#v", path, err, resp)
+		}
+		return resp
+	}
+	mustFail := func(path string, data map[string]interface{}) {
+		resp, err := doReq(path, data)
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("path %q: expected error; resp: %#v", path, resp)
+		}
+	}
+
+	mustReq("keys/stream", nil)
+
+	chunks := [][]byte{
+		bytes.Repeat([]byte("a"), 16),
+		bytes.Repeat([]byte("b"), 16),
+		[]byte("end"),
+	}
+	firstChunk := map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(chunks[0]),
+	}
+
+	// Chunked mode must be enabled explicitly
+	mustFail("stream/encrypt/stream", firstChunk)
+
+	mustFail("keys/stream/config", map[string]interface{}{
+		"stream_max_chunk_size": -1,
+	})
+	mustReq("keys/stream/config", map[string]interface{}{
+		"stream_max_chunk_size": 16,
+	})
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "keys/stream",
+		Storage:   s,
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %v\nresp: %#v", err, resp)
+	}
+	if resp.Data["stream_max_chunk_size"].(int) != 16 {
+		t.Fatalf("bad: stream_max_chunk_size: %v", resp.Data["stream_max_chunk_size"])
+	}
+
+	// Chunks larger than the configured maximum are rejected
+	mustFail("stream/encrypt/stream", map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(make([]byte, 17)),
+	})
+
+	// Later chunks need the header of the stream
+	mustFail("stream/encrypt/stream", map[string]interface{}{
+		"index":     1,
+		"plaintext": firstChunk["plaintext"],
+	})
+
+	resp = mustReq("stream/encrypt/stream", firstChunk)
+	header := resp.Data["header"].(string)
+	ciphertexts := []string{resp.Data["ciphertext"].(string)}
+	for i, chunk := range chunks[1:] {
+		resp = mustReq("stream/encrypt/stream", map[string]interface{}{
+			"header":    header,
+			"index":     i + 1,
+			"final":     i+2 == len(chunks),
+			"plaintext": base64.StdEncoding.EncodeToString(chunk),
+		})
+		if resp.Data["header"].(string) != header {
+			t.Fatalf("header changed within the stream")
+		}
+		ciphertexts = append(ciphertexts, resp.Data["ciphertext"].(string))
+	}
+
+	for i, ciphertext := range ciphertexts {
+		resp = mustReq("stream/decrypt/stream", map[string]interface{}{
+			"header":     header,
+			"index":      i,
+			"final":      i+1 == len(chunks),
+			"ciphertext": ciphertext,
+		})
+		plaintext, err := base64.StdEncoding.DecodeString(resp.Data["plaintext"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, chunks[i]) {
+			t.Fatalf("chunk %d: expected %q, got %q", i, chunks[i], plaintext)
+		}
+	}
+
+	// Encrypting a chunk again uses a fresh nonce
+	resp = mustReq("stream/encrypt/stream", map[string]interface{}{
+		"header":    header,
+		"index":     1,
+		"plaintext": base64.StdEncoding.EncodeToString(chunks[1]),
+	})
+	if resp.Data["ciphertext"].(string) == ciphertexts[1] {
+		t.Fatalf("re-encrypting a chunk produced the same ciphertext")
+	}
+	mustReq("stream/decrypt/stream", map[string]interface{}{
+		"header":     header,
+		"index":      1,
+		"ciphertext": resp.Data["ciphertext"],
+	})
+
+	// Reordered chunks fail to decrypt
+	mustFail("stream/decrypt/stream", map[string]interface{}{
+		"header":     header,
+		"index":      0,
+		"ciphertext": ciphertexts[1],
+	})
+
+	// Truncating the stream by marking an earlier chunk as final fails
+	mustFail("stream/decrypt/stream", map[string]interface{}{
+		"header":     header,
+		"index":      1,
+		"final":      true,
+		"ciphertext": ciphertexts[1],
+	})
+
+	// Chunks of other streams fail to decrypt
+	resp = mustReq("stream/encrypt/stream", firstChunk)
+	mustFail("stream/decrypt/stream", map[string]interface{}{
+		"header":     resp.Data["header"],
+		"index":      0,
+		"ciphertext": ciphertexts[0],
+	})
+
+	// Streams stay readable after rotation, until the key version they were
+	// started with can no longer be decrypted
+	mustReq("keys/stream/rotate", nil)
+	mustReq("stream/decrypt/stream", map[string]interface{}{
+		"header":     header,
+		"index":      0,
+		"ciphertext": ciphertexts[0],
+	})
+	mustReq("keys/stream/config", map[string]interface{}{
+		"min_decryption_version": 2,
+	})
+	mustFail("stream/decrypt/stream", map[string]interface{}{
+		"header":     header,
+		"index":      0,
+		"ciphertext": ciphertexts[0],
+	})
+
+	// Disabling chunked mode rejects further requests
+	mustReq("keys/stream/config", map[string]interface{}{
+		"stream_max_chunk_size": 0,
+	})
+	mustFail("stream/encrypt/stream", firstChunk)
+}
@@ -179,7 +179,10 @@ func (c *AuthEnableCommand) Flags() *FlagSets {
 	f.StringVar(&StringVar{
 		Name:   flagNameTokenType,
 		Target: &c.flagTokenType,
-		Usage:  "Sets a forced token type for the mount.",
+		Usage: "Sets the type of tokens issued by the mount: \"service\" or " +
+			"\"batch\" to force the type, or \"default-service\" or " +
+			"\"default-batch\" to only set the type when the auth method " +
+			"doesn't request one.",
 	})
 
 	f.IntVar(&IntVar{
@@ -17,7 +17,8 @@ var _ cli.CommandAutocomplete = (*AuthListCommand)(nil)
 type AuthListCommand struct {
 	*BaseCommand
 
-	flagDetailed bool
+	flagDetailed        bool
+	flagUnauthenticated bool
 }
 
 func (c *AuthListCommand) Synopsis() string {
@@ -40,6 +41,10 @@ Usage: vault auth list [options]
 
       $ vault auth list -detailed
 
+  List the auth methods available for login without using a token:
+
+      $ vault auth list -unauthenticated
+
 ` + c.Flags().Help()
 
 	return strings.TrimSpace(helpText)
@@ -59,6 +64,15 @@ func (c *AuthListCommand) Flags() *FlagSets {
 			"table-formatted output.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:    "unauthenticated",
+		Target:  &c.flagUnauthenticated,
+		Default: false,
+		Usage: "List only the auth methods whose listing visibility is set to " +
+			"\"unauth\". No token is sent with the request, so this can be used " +
+			"to discover login methods before authenticating.",
+	})
+
 	return set
 }
 
@@ -90,7 +104,12 @@ func (c *AuthListCommand) Run(args []string) int {
 		return 2
 	}
 
-	auths, err := client.Sys().ListAuth()
+	var auths map[string]*api.AuthMount
+	if c.flagUnauthenticated {
+		auths, err = c.unauthenticatedMounts(client)
+	} else {
+		auths, err = client.Sys().ListAuth()
+	}
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error listing enabled authentications: %s", err))
 		return 2
@@ -109,6 +128,19 @@ func (c *AuthListCommand) Run(args []string) int {
 	}
 }
 
+// unauthenticatedMounts lists the auth methods visible without a token. The
+// endpoint only returns the type, description and options of each method.
+func (c *AuthListCommand) unauthenticatedMounts(client *api.Client) (map[string]*api.AuthMount, error) {
+	client.ClearToken()
+
+	mounts, err := client.Sys().ListUIMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	return mounts.Auth, nil
+}
+
 func (c *AuthListCommand) simpleMounts(auths map[string]*api.AuthMount) []string {
 	paths := make([]string, 0, len(auths))
 	for path := range auths {
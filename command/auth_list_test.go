@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/cli"
 )
 
@@ -75,6 +76,43 @@ func TestAuthListCommand_Run(t *testing.T) {
 		}
 	})
 
+	t.Run("unauthenticated", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		if err := client.Sys().EnableAuthWithOptions("visible", &api.EnableAuthOptions{
+			Type:   "userpass",
+			Config: api.AuthConfigInput{ListingVisibility: "unauth"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Sys().EnableAuthWithOptions("hidden", &api.EnableAuthOptions{
+			Type: "userpass",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		ui, cmd := testAuthListCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{"-unauthenticated"})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d: %s", code, exp, ui.ErrorWriter.String())
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, "visible/") {
+			t.Errorf("expected %q to contain %q", combined, "visible/")
+		}
+		for _, path := range []string{"hidden/", "token/"} {
+			if strings.Contains(combined, path) {
+				t.Errorf("expected %q not to contain %q", combined, path)
+			}
+		}
+	})
+
 	t.Run("communication_failure", func(t *testing.T) {
 		t.Parallel()
 
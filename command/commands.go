@@ -10,6 +10,8 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/hashicorp/vault/sdk/version"
+	"github.com/hashicorp/vault/vault/managedkeys"
+	"github.com/hashicorp/vault/vault/managedkeys/gcpckms"
 	"github.com/mitchellh/cli"
 
 	/*
@@ -58,6 +60,9 @@ import (
 	physZooKeeper "github.com/hashicorp/vault/physical/zookeeper"
 	physFile "github.com/hashicorp/vault/sdk/physical/file"
 	physInmem "github.com/hashicorp/vault/sdk/physical/inmem"
+
+	"github.com/hashicorp/vault/serviceregistration"
+	srKubernetes "github.com/hashicorp/vault/serviceregistration/kubernetes"
 )
 
 const (
@@ -111,6 +116,10 @@ var (
 		"plugin": plugin.Factory,
 	}
 
+	managedKeyTypes = map[string]managedkeys.Factory{
+		"gcpckms": gcpckms.NewKey,
+	}
+
 	logicalBackends = map[string]logical.Factory{
 		"plugin":   plugin.Factory,
 		"database": logicalDb.Factory,
@@ -146,6 +155,11 @@ var (
 		"swift":                  physSwift.NewSwiftBackend,
 		"zookeeper":              physZooKeeper.NewZooKeeperBackend,
 	}
+
+	serviceRegistrations = map[string]serviceregistration.Factory{
+		"consul":     physConsul.NewConsulServiceRegistration,
+		"kubernetes": srKubernetes.NewServiceRegistration,
+	}
 )
 
 // Commands is the mapping of all the available commands.
@@ -316,6 +330,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"operator keyring-backup": func() (cli.Command, error) {
+			return &OperatorKeyringBackupCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"operator migrate": func() (cli.Command, error) {
 			return &OperatorMigrateCommand{
 				BaseCommand:      getBaseCommand(),
@@ -460,13 +479,15 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 					tokenHelper: runOpts.TokenHelper,
 					flagAddress: runOpts.Address,
 				},
-				AuditBackends:      auditBackends,
-				CredentialBackends: credentialBackends,
-				LogicalBackends:    logicalBackends,
-				PhysicalBackends:   physicalBackends,
-				ShutdownCh:         MakeShutdownCh(),
-				SighupCh:           MakeSighupCh(),
-				SigUSR2Ch:          MakeSigUSR2Ch(),
+				AuditBackends:        auditBackends,
+				CredentialBackends:   credentialBackends,
+				LogicalBackends:      logicalBackends,
+				ManagedKeyTypes:      managedKeyTypes,
+				PhysicalBackends:     physicalBackends,
+				ServiceRegistrations: serviceRegistrations,
+				ShutdownCh:           MakeShutdownCh(),
+				SighupCh:             MakeSighupCh(),
+				SigUSR2Ch:            MakeSigUSR2Ch(),
 			}, nil
 		},
 		"ssh": func() (cli.Command, error) {
@@ -514,6 +535,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"validate": func() (cli.Command, error) {
+			return &ValidateCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"version": func() (cli.Command, error) {
 			return &VersionCommand{
 				VersionInfo: version.GetVersion(),
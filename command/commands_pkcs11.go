@@ -0,0 +1,12 @@
+//go:build pkcs11
+// +build pkcs11
+
+package command
+
+import (
+	"github.com/hashicorp/vault/vault/managedkeys/pkcs11"
+)
+
+func init() {
+	managedKeyTypes["pkcs11"] = pkcs11.NewKey
+}
@@ -24,7 +24,7 @@ type VaultUI struct {
 
 // setupEnv parses args and may replace them and sets some env vars to known
 // values based on format options
-func setupEnv(args []string) (retArgs []string, format string, outputCurlString bool) {
+func setupEnv(args []string) (retArgs []string, format string, outputCurlString bool, outputPolicy bool) {
 	var nextArgFormat bool
 
 	for _, arg := range args {
@@ -48,6 +48,11 @@ func setupEnv(args []string) (retArgs []string, format string, outputCurlString
 			continue
 		}
 
+		if arg == "-output-policy" {
+			outputPolicy = true
+			continue
+		}
+
 		// Parse a given flag here, which overrides the env var
 		if strings.HasPrefix(arg, "--format=") {
 			format = strings.TrimPrefix(arg, "--format=")
@@ -72,7 +77,7 @@ func setupEnv(args []string) (retArgs []string, format string, outputCurlString
 		format = "table"
 	}
 
-	return args, format, outputCurlString
+	return args, format, outputCurlString, outputPolicy
 }
 
 type RunOptions struct {
@@ -95,8 +100,8 @@ func RunCustom(args []string, runOpts *RunOptions) int {
 	}
 
 	var format string
-	var outputCurlString bool
-	args, format, outputCurlString = setupEnv(args)
+	var outputCurlString, outputPolicy bool
+	args, format, outputCurlString, outputPolicy = setupEnv(args)
 
 	// Don't use color if disabled
 	useColor := true
@@ -125,7 +130,7 @@ func RunCustom(args []string, runOpts *RunOptions) int {
 	}
 
 	uiErrWriter := runOpts.Stderr
-	if outputCurlString {
+	if outputCurlString || outputPolicy {
 		uiErrWriter = ioutil.Discard
 	}
 
@@ -195,6 +200,26 @@ func RunCustom(args []string, runOpts *RunOptions) int {
 			runOpts.Stdout.Write([]byte(fmt.Sprintf("%s\n", api.LastOutputStringError.CurlString())))
 			return 0
 		}
+	} else if outputPolicy {
+		if exitCode == 0 {
+			fmt.Fprint(runOpts.Stderr, "Could not generate policy")
+			return 1
+		} else {
+			if api.LastOutputPolicyError == nil {
+				if exitCode == 127 {
+					// Usage, just pass it through
+					return exitCode
+				}
+				fmt.Fprint(runOpts.Stderr, "Policy not set by API operation; run without -output-policy to see the generated error\n")
+				return exitCode
+			}
+			if api.LastOutputPolicyError.Error() != api.ErrOutputPolicyRequest {
+				runOpts.Stdout.Write([]byte(fmt.Sprintf("Error creating policy: %s\n", api.LastOutputPolicyError.Error())))
+				return 1
+			}
+			runOpts.Stdout.Write([]byte(fmt.Sprintf("%s\n", api.LastOutputPolicyError.HCLString())))
+			return 0
+		}
 	} else if err != nil {
 		fmt.Fprintf(runOpts.Stderr, "Error executing CLI: %s\n", err.Error())
 		return 1
@@ -493,6 +493,12 @@ func (c *OperatorGenerateRootCommand) printStatus(status *api.GenerateRootStatus
 	out = append(out, fmt.Sprintf("Started | %t", status.Started))
 	out = append(out, fmt.Sprintf("Progress | %d/%d", status.Progress, status.Required))
 	out = append(out, fmt.Sprintf("Complete | %t", status.Complete))
+	if status.OperationType != "" {
+		out = append(out, fmt.Sprintf("Operation Type | %s", status.OperationType))
+	}
+	if len(status.KeyFingerprints) > 0 {
+		out = append(out, fmt.Sprintf("Key Fingerprints | %s", strings.Join(status.KeyFingerprints, ", ")))
+	}
 	if status.PGPFingerprint != "" {
 		out = append(out, fmt.Sprintf("PGP Fingerprint | %s", status.PGPFingerprint))
 	}
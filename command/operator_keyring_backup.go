@@ -0,0 +1,153 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*OperatorKeyringBackupCommand)(nil)
+var _ cli.CommandAutocomplete = (*OperatorKeyringBackupCommand)(nil)
+
+type OperatorKeyringBackupCommand struct {
+	*BaseCommand
+
+	flagRestore bool
+
+	testStdin io.Reader // for tests
+}
+
+func (c *OperatorKeyringBackupCommand) Synopsis() string {
+	return "Backs up or restores the barrier keyring"
+}
+
+func (c *OperatorKeyringBackupCommand) Help() string {
+	helpText := `
+Usage: vault operator keyring-backup [options] [PATH]
+
+  Exports a backup of the barrier keyring and the root of trust protecting it,
+  or restores such a backup into an uninitialized Vault. This is intended for
+  disaster recovery: a restored Vault is unsealed using the same unseal keys or
+  seal as the Vault the backup was taken from, and can then read a copy of its
+  storage.
+
+  The backup only contains material that is already protected by the seal,
+  but it should still be stored as carefully as the storage data itself. It
+  only covers encryption keys installed before it was taken, so take a new
+  backup after every key rotation or rekey. Exporting a backup requires a
+  token with sudo capability on the sys/keyring/backup path.
+
+  If PATH is omitted or "-", the backup is written to or read from stdin.
+
+  Export a backup:
+
+      $ vault operator keyring-backup keyring.backup
+
+  Restore a backup into a new storage backend:
+
+      $ vault operator keyring-backup -restore keyring.backup
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorKeyringBackupCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "restore",
+		Target:  &c.flagRestore,
+		Default: false,
+		Usage: "Restore the backup at the given path instead of exporting one. " +
+			"This is only allowed if Vault is not yet initialized.",
+	})
+
+	return set
+}
+
+func (c *OperatorKeyringBackupCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *OperatorKeyringBackupCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *OperatorKeyringBackupCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	path := "-"
+	args = f.Args()
+	switch {
+	case len(args) > 1:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0-1, got %d)", len(args)))
+		return 1
+	case len(args) == 1:
+		path = strings.TrimSpace(args[0])
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	if c.flagRestore {
+		var buf []byte
+		if path == "-" {
+			stdin := (io.Reader)(os.Stdin)
+			if c.testStdin != nil {
+				stdin = c.testStdin
+			}
+			buf, err = ioutil.ReadAll(stdin)
+		} else {
+			buf, err = ioutil.ReadFile(path)
+		}
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading keyring backup: %s", err))
+			return 1
+		}
+
+		if err := client.Sys().KeyringRestore(strings.TrimSpace(string(buf))); err != nil {
+			c.UI.Error(fmt.Sprintf("Error restoring keyring: %s", err))
+			return 2
+		}
+
+		c.UI.Output("Success! Restored the keyring. Vault is sealed and must be " +
+			"unsealed using the unseal keys or seal of the Vault the backup was " +
+			"taken from.")
+		return 0
+	}
+
+	backup, err := client.Sys().KeyringBackup()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error backing up keyring: %s", err))
+		return 2
+	}
+
+	if path == "-" {
+		c.UI.Output(backup.Backup)
+		return 0
+	}
+
+	if err := ioutil.WriteFile(path, []byte(backup.Backup+"\n"), 0600); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing keyring backup: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Success! Wrote keyring backup for key term %d to: %s", backup.Term, path))
+	return 0
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -18,6 +19,8 @@ var _ cli.CommandAutocomplete = (*PolicyFmtCommand)(nil)
 
 type PolicyFmtCommand struct {
 	*BaseCommand
+
+	flagCheck bool
 }
 
 func (c *PolicyFmtCommand) Synopsis() string {
@@ -36,13 +39,31 @@ Usage: vault policy fmt [options] PATH
 
       $ vault policy fmt my-policy.hcl
 
+  Check that the local file "my-policy.hcl" is formatted, without modifying
+  it, for instance before committing it:
+
+      $ vault policy fmt -check my-policy.hcl
+
 ` + c.Flags().Help()
 
 	return strings.TrimSpace(helpText)
 }
 
 func (c *PolicyFmtCommand) Flags() *FlagSets {
-	return c.flagSet(FlagSetNone)
+	set := c.flagSet(FlagSetNone)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "check",
+		Target:  &c.flagCheck,
+		Default: false,
+		Usage: "Check that the policy is valid and formatted instead of " +
+			"formatting it. The command exits with a non-zero status if it " +
+			"isn't.",
+	})
+
+	return set
 }
 
 func (c *PolicyFmtCommand) AutocompleteArgs() complete.Predictor {
@@ -100,6 +121,15 @@ func (c *PolicyFmtCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.flagCheck {
+		if !bytes.Equal(result, b) {
+			c.UI.Error(fmt.Sprintf("Policy is not formatted: %s", path))
+			return 1
+		}
+		c.UI.Output(fmt.Sprintf("Success! Policy is formatted: %s", path))
+		return 0
+	}
+
 	// Write them back out
 	if err := ioutil.WriteFile(path, result, 0644); err != nil {
 		c.UI.Error(fmt.Sprintf("Error writing result: %s", err))
@@ -119,6 +119,61 @@ path "secret" {
 		}
 	})
 
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+
+		policy := `path "secret" {  capabilities = ["read"] }`
+
+		f, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.Write([]byte(policy)); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		ui, cmd := testPolicyFmtCommand(t)
+
+		code := cmd.Run([]string{
+			"-check",
+			f.Name(),
+		})
+		if exp := 1; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		stderr := ui.ErrorWriter.String()
+		expected := "Policy is not formatted"
+		if !strings.Contains(stderr, expected) {
+			t.Errorf("expected %q to include %q", stderr, expected)
+		}
+
+		// The file must be left untouched
+		contents, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != policy {
+			t.Errorf("expected %q to be %q", string(contents), policy)
+		}
+
+		formatted := "path \"secret\" {\n  capabilities = [\"read\"]\n}\n"
+		if err := ioutil.WriteFile(f.Name(), []byte(formatted), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, cmd = testPolicyFmtCommand(t)
+		code = cmd.Run([]string{
+			"-check",
+			f.Name(),
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+	})
+
 	t.Run("bad_hcl", func(t *testing.T) {
 		t.Parallel()
 
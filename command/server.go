@@ -37,6 +37,7 @@ import (
 	gatedwriter "github.com/hashicorp/vault/helper/gated-writer"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/reload"
+	"github.com/hashicorp/vault/helper/tracing"
 	vaulthttp "github.com/hashicorp/vault/http"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/logging"
@@ -45,7 +46,9 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/hashicorp/vault/sdk/version"
+	"github.com/hashicorp/vault/serviceregistration"
 	"github.com/hashicorp/vault/vault"
+	"github.com/hashicorp/vault/vault/managedkeys"
 	vaultseal "github.com/hashicorp/vault/vault/seal"
 	"github.com/mitchellh/cli"
 	testing "github.com/mitchellh/go-testing-interface"
@@ -60,14 +63,21 @@ var memProfilerEnabled = false
 
 const storageMigrationLock = "core/migration"
 
+// defaultShutdownGracePeriod is how long in-flight requests are given to
+// complete on shutdown when shutdown_grace_period is not configured.
+const defaultShutdownGracePeriod = 10 * time.Second
+
 type ServerCommand struct {
 	*BaseCommand
 
 	AuditBackends      map[string]audit.Factory
 	CredentialBackends map[string]logical.Factory
 	LogicalBackends    map[string]logical.Factory
+	ManagedKeyTypes    map[string]managedkeys.Factory
 	PhysicalBackends   map[string]physical.Factory
 
+	ServiceRegistrations map[string]serviceregistration.Factory
+
 	ShutdownCh chan struct{}
 	SighupCh   chan struct{}
 	SigUSR2Ch  chan struct{}
@@ -80,6 +90,13 @@ type ServerCommand struct {
 
 	cleanupGuard sync.Once
 
+	// telemetrySink holds the push-based telemetry sinks so that they can be
+	// replaced on SIGHUP
+	telemetrySink *metricsutil.ReloadableSink
+	// pathLabeler labels the request path metrics, following the allowlist
+	// of the configuration on SIGHUP
+	pathLabeler *metricsutil.PathLabeler
+
 	reloadFuncsLock *sync.RWMutex
 	reloadFuncs     *map[string][]reload.ReloadFunc
 	startedCh       chan (struct{}) // for tests
@@ -110,9 +127,10 @@ type ServerCommand struct {
 
 type ServerListener struct {
 	net.Listener
-	config             map[string]interface{}
-	maxRequestSize     int64
-	maxRequestDuration time.Duration
+	config                map[string]interface{}
+	maxRequestSize        int64
+	maxRequestDuration    time.Duration
+	customResponseHeaders map[string]http.Header
 }
 
 func (c *ServerCommand) Synopsis() string {
@@ -473,12 +491,37 @@ func (c *ServerCommand) Run(args []string) int {
 				"in a Docker container, provide the IPC_LOCK cap to the container."))
 	}
 
+	// Disabling mlock entirely leaves all of Vault's memory, including keys,
+	// eligible to be swapped to disk. Unless the operator has acknowledged
+	// that, point them at the safer alternatives.
+	if !c.flagDev && config.DisableMlock && !config.MlockKeyMaterialOnly && !config.DisableMlockAcknowledgeRisk {
+		c.UI.Warn(wrapAtLength(
+			"WARNING! mlock is disabled. Memory containing encryption keys and " +
+				"secrets may be swapped to disk. If mlock is unavailable in this " +
+				"environment, consider setting \"mlock_key_material_only\" to lock " +
+				"only key material and \"disable_core_dumps\" to keep memory out of " +
+				"core dumps. Set \"disable_mlock_acknowledge_risk\" to silence this " +
+				"warning."))
+	}
+
 	metricsHelper, err := c.setupTelemetry(config)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error initializing telemetry: %s", err))
 		return 1
 	}
 
+	tracingExporter, err := c.setupTracing(config)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing tracing: %s", err))
+		return 1
+	}
+	if tracingExporter != nil {
+		defer func() {
+			tracing.Disable(tracingExporter)
+			tracingExporter.Stop()
+		}()
+	}
+
 	// Initialize the backend
 	factory, exists := c.PhysicalBackends[config.Storage.Type]
 	if !exists {
@@ -577,9 +620,12 @@ func (c *ServerCommand) Run(args []string) int {
 		AuditBackends:             c.AuditBackends,
 		CredentialBackends:        c.CredentialBackends,
 		LogicalBackends:           c.LogicalBackends,
+		ManagedKeyTypes:           c.ManagedKeyTypes,
 		Logger:                    c.logger,
 		DisableCache:              config.DisableCache,
-		DisableMlock:              config.DisableMlock,
+		DisableMlock:              config.DisableMlock || config.MlockKeyMaterialOnly,
+		LockKeyMaterial:           config.MlockKeyMaterialOnly,
+		DisableCoreDumps:          config.DisableCoreDumps,
 		MaxLeaseTTL:               config.MaxLeaseTTL,
 		DefaultLeaseTTL:           config.DefaultLeaseTTL,
 		ClusterName:               config.ClusterName,
@@ -594,6 +640,7 @@ func (c *ServerCommand) Run(args []string) int {
 		BuiltinRegistry:           builtinplugins.Registry,
 		DisableKeyEncodingChecks:  config.DisablePrintableCheck,
 		MetricsHelper:             metricsHelper,
+		MetricsPathLabeler:        c.pathLabeler,
 	}
 	if c.flagDev {
 		coreConfig.DevToken = c.flagDevRootTokenID
@@ -771,6 +818,25 @@ CLUSTER_SYNTHESIS_COMPLETE:
 		}
 	}
 
+	// Initialize the service registration, if configured separately from
+	// the storage backend
+	if config.ServiceRegistration != nil {
+		srType := config.ServiceRegistration.Type
+		factory, exists := c.ServiceRegistrations[srType]
+		if !exists {
+			c.UI.Error(fmt.Sprintf("Unknown service_registration type %s", srType))
+			return 1
+		}
+		namedSRLogger := c.logger.Named("service_registration." + srType)
+		allLoggers = append(allLoggers, namedSRLogger)
+		sr, err := factory(config.ServiceRegistration.Config, namedSRLogger)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error initializing service_registration of type %s: %s", srType, err))
+			return 1
+		}
+		coreConfig.ServiceRegistration = sr
+	}
+
 	// Initialize the core
 	core, newCoreError := vault.NewCore(coreConfig)
 	if newCoreError != nil {
@@ -784,11 +850,17 @@ CLUSTER_SYNTHESIS_COMPLETE:
 	c.reloadFuncs = coreConfig.ReloadFuncs
 	c.reloadFuncsLock = coreConfig.ReloadFuncsLock
 
+	core.SetSanitizedConfig(config.Sanitized())
+
 	// Compile server information for output later
 	info["storage"] = config.Storage.Type
+	mlockMode := strconv.FormatBool(!config.DisableMlock && mlock.Supported())
+	if config.MlockKeyMaterialOnly && mlock.Supported() {
+		mlockMode = "key material only"
+	}
 	info["mlock"] = fmt.Sprintf(
 		"supported: %v, enabled: %v",
-		mlock.Supported(), !config.DisableMlock && mlock.Supported())
+		mlock.Supported(), mlockMode)
 	infoKeys = append(infoKeys, "mlock", "storage")
 
 	if coreConfig.ClusterAddr != "" {
@@ -888,11 +960,16 @@ CLUSTER_SYNTHESIS_COMPLETE:
 		}
 		props["max_request_duration"] = fmt.Sprintf("%s", maxRequestDuration.String())
 
+		// We perform validation on the config in the listener factory, we can
+		// just cast here
+		customResponseHeaders, _ := lnConfig.Config["custom_response_headers"].(map[string]http.Header)
+
 		lns = append(lns, ServerListener{
-			Listener:           ln,
-			config:             lnConfig.Config,
-			maxRequestSize:     maxRequestSize,
-			maxRequestDuration: maxRequestDuration,
+			Listener:              ln,
+			config:                lnConfig.Config,
+			maxRequestSize:        maxRequestSize,
+			maxRequestDuration:    maxRequestDuration,
+			customResponseHeaders: customResponseHeaders,
 		})
 
 		// Store the listener props for output later
@@ -1003,21 +1080,28 @@ CLUSTER_SYNTHESIS_COMPLETE:
 	// Instantiate the wait group
 	c.WaitGroup = &sync.WaitGroup{}
 
-	// If the backend supports service discovery, run service discovery
-	if coreConfig.HAPhysical != nil && coreConfig.HAPhysical.HAEnabled() {
-		sd, ok := coreConfig.HAPhysical.(physical.ServiceDiscovery)
-		if ok {
-			activeFunc := func() bool {
-				if isLeader, _, _, err := core.Leader(); err == nil {
-					return isLeader
-				}
-				return false
+	// Run service discovery using the configured service registration, or
+	// the HA backend if it supports service discovery
+	sd := coreConfig.ServiceRegistration
+	if sd == nil && coreConfig.HAPhysical != nil && coreConfig.HAPhysical.HAEnabled() {
+		sd, _ = coreConfig.HAPhysical.(physical.ServiceDiscovery)
+	}
+	if sd != nil {
+		activeFunc := func() bool {
+			isLeader, _, _, err := core.Leader()
+			if errwrap.Contains(err, vault.ErrHANotEnabled.Error()) {
+				// Without HA an unsealed node is always the active one
+				return !core.Sealed()
 			}
-
-			if err := sd.RunServiceDiscovery(c.WaitGroup, c.ShutdownCh, coreConfig.RedirectAddr, activeFunc, core.Sealed, core.PerfStandby); err != nil {
-				c.UI.Error(fmt.Sprintf("Error initializing service discovery: %v", err))
-				return 1
+			if err == nil {
+				return isLeader
 			}
+			return false
+		}
+
+		if err := sd.RunServiceDiscovery(c.WaitGroup, c.ShutdownCh, coreConfig.RedirectAddr, activeFunc, core.Sealed, core.PerfStandby); err != nil {
+			c.UI.Error(fmt.Sprintf("Error initializing service discovery: %v", err))
+			return 1
 		}
 	}
 
@@ -1127,12 +1211,14 @@ CLUSTER_SYNTHESIS_COMPLETE:
 	}
 
 	// Initialize the HTTP servers
+	servers := make([]*http.Server, 0, len(lns))
 	for _, ln := range lns {
 		handler := vaulthttp.Handler(&vault.HandlerProperties{
 			Core:                  core,
 			MaxRequestSize:        ln.maxRequestSize,
 			MaxRequestDuration:    ln.maxRequestDuration,
 			DisablePrintableCheck: config.DisablePrintableCheck,
+			CustomResponseHeaders: ln.customResponseHeaders,
 		})
 
 		// We perform validation on the config earlier, we can just cast here
@@ -1153,6 +1239,7 @@ CLUSTER_SYNTHESIS_COMPLETE:
 			IdleTimeout:       5 * time.Minute,
 			ErrorLog:          c.logger.StandardLogger(nil),
 		}
+		servers = append(servers, server)
 		go server.Serve(ln.Listener)
 	}
 
@@ -1209,12 +1296,19 @@ CLUSTER_SYNTHESIS_COMPLETE:
 		case <-c.ShutdownCh:
 			c.UI.Output("==> Vault shutdown triggered")
 
-			// Stop the listeners so that we don't process further client requests.
+			// Stop accepting new client requests and give in-flight ones a
+			// chance to complete before closing the listeners.
+			gracePeriod := defaultShutdownGracePeriod
+			if config.ShutdownGracePeriodRaw != nil {
+				gracePeriod = config.ShutdownGracePeriod
+			}
+			c.drainHTTPServers(servers, gracePeriod)
 			c.cleanupGuard.Do(listenerCloseFunc)
 
 			// Shutdown will wait until after Vault is sealed, which means the
 			// request forwarding listeners will also be closed (and also
-			// waited for).
+			// waited for). Sealing also releases the HA lock if held, so a
+			// standby can take over without waiting for the lock to expire.
 			if err := core.Shutdown(); err != nil {
 				c.UI.Error(fmt.Sprintf("Error with core shutdown: %s", err))
 			}
@@ -1267,6 +1361,12 @@ CLUSTER_SYNTHESIS_COMPLETE:
 				core.SetLogLevel(level)
 			}
 
+			if err := c.reloadTelemetry(config); err != nil {
+				c.logger.Error("could not reload telemetry configuration", "error", err)
+			}
+
+			core.SetSanitizedConfig(config.Sanitized())
+
 		RUNRELOADFUNCS:
 			if err := c.Reload(c.reloadFuncsLock, c.reloadFuncs, c.flagConfigs); err != nil {
 				c.UI.Error(fmt.Sprintf("Error(s) were encountered during reload: %s", err))
@@ -1284,6 +1384,29 @@ CLUSTER_SYNTHESIS_COMPLETE:
 	return 0
 }
 
+// drainHTTPServers stops the given servers from accepting new connections
+// and waits up to gracePeriod for in-flight requests to complete, after which
+// any remaining connections are closed.
+func (c *ServerCommand) drainHTTPServers(servers []*http.Server, gracePeriod time.Duration) {
+	c.logger.Info("draining in-flight requests", "grace_period", gracePeriod.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				c.logger.Warn("in-flight requests did not complete within the shutdown grace period", "error", err)
+				server.Close()
+			}
+		}(server)
+	}
+	wg.Wait()
+}
+
 func (c *ServerCommand) enableDev(core *vault.Core, coreConfig *vault.CoreConfig) (*vault.InitResult, error) {
 	ctx := namespace.ContextWithNamespace(context.Background(), namespace.RootNamespace)
 
@@ -1767,12 +1890,67 @@ func (c *ServerCommand) setupTelemetry(config *server.Config) (*metricsutil.Metr
 
 	metricHelper := metricsutil.NewMetricsHelper(inm, prometheusEnabled)
 
+	pushSinks, err := telemetryPushSinks(telConfig, metricsConf.HostName)
+	if err != nil {
+		return nil, err
+	}
+	c.telemetrySink = metricsutil.NewReloadableSink(pushSinks)
+	fanout = append(fanout, c.telemetrySink)
+	c.pathLabeler = metricsutil.NewPathLabeler(telConfig.PathLabelAllowlist)
+
+	// Initialize the global sink
+	if len(fanout)-1+len(pushSinks) > 1 {
+		// Hostname enabled will create poor quality metrics name for prometheus
+		if !telConfig.DisableHostname {
+			c.UI.Warn("telemetry.disable_hostname has been set to false. Recommended setting is true for Prometheus to avoid poorly named metrics.")
+		}
+	} else {
+		metricsConf.EnableHostname = false
+	}
+	fanout = append(fanout, inm)
+	_, err = metrics.NewGlobal(metricsConf, fanout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return metricHelper, nil
+}
+
+// setupTracing registers the exporter sending request traces to the
+// configured collector. It returns nil if tracing isn't configured.
+func (c *ServerCommand) setupTracing(config *server.Config) (*tracing.OTLPExporter, error) {
+	if config.Tracing == nil {
+		return nil, nil
+	}
+
+	exporter, err := tracing.NewOTLPExporter(&tracing.OTLPExporterConfig{
+		Endpoint:    config.Tracing.OTLPEndpoint,
+		Headers:     config.Tracing.OTLPHeaders,
+		ServiceName: config.Tracing.ServiceName,
+		Logger:      c.logger.Named("tracing"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tracing.Enable(exporter, config.Tracing.SampleRate)
+	return exporter, nil
+}
+
+// telemetryPushSinks creates the sinks that push metrics to external
+// systems. Unlike the Prometheus and in-memory sinks these can be replaced on
+// reload.
+func telemetryPushSinks(telConfig *server.Telemetry, hostName string) (metrics.FanoutSink, error) {
+	var sinks metrics.FanoutSink
+
+	// Configure the statsite sink
 	if telConfig.StatsiteAddr != "" {
 		sink, err := metrics.NewStatsiteSink(telConfig.StatsiteAddr)
 		if err != nil {
 			return nil, err
 		}
-		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
 	}
 
 	// Configure the statsd sink
@@ -1781,7 +1959,7 @@ func (c *ServerCommand) setupTelemetry(config *server.Config) (*metricsutil.Metr
 		if err != nil {
 			return nil, err
 		}
-		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
 	}
 
 	// Configure the Circonus sink
@@ -1818,7 +1996,7 @@ func (c *ServerCommand) setupTelemetry(config *server.Config) (*metricsutil.Metr
 			return nil, err
 		}
 		sink.Start()
-		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
 	}
 
 	if telConfig.DogStatsDAddr != "" {
@@ -1828,31 +2006,49 @@ func (c *ServerCommand) setupTelemetry(config *server.Config) (*metricsutil.Metr
 			tags = telConfig.DogStatsDTags
 		}
 
-		sink, err := datadog.NewDogStatsdSink(telConfig.DogStatsDAddr, metricsConf.HostName)
+		sink, err := datadog.NewDogStatsdSink(telConfig.DogStatsDAddr, hostName)
 		if err != nil {
 			return nil, errwrap.Wrapf("failed to start DogStatsD sink: {{err}}", err)
 		}
 		sink.SetTags(tags)
-		fanout = append(fanout, sink)
+		sinks = append(sinks, sink)
 	}
 
-	// Initialize the global sink
-	if len(fanout) > 1 {
-		// Hostname enabled will create poor quality metrics name for prometheus
-		if !telConfig.DisableHostname {
-			c.UI.Warn("telemetry.disable_hostname has been set to false. Recommended setting is true for Prometheus to avoid poorly named metrics.")
-		}
-	} else {
-		metricsConf.EnableHostname = false
+	return sinks, nil
+}
+
+// reloadTelemetry replaces the push-based telemetry sinks with ones built
+// from the given configuration and shuts down the previous ones. Changes to
+// the Prometheus retention time and disable_hostname require a restart.
+func (c *ServerCommand) reloadTelemetry(config *server.Config) error {
+	if c.telemetrySink == nil {
+		return nil
 	}
-	fanout = append(fanout, inm)
-	_, err := metrics.NewGlobal(metricsConf, fanout)
 
+	telConfig := config.Telemetry
+	if telConfig == nil {
+		telConfig = &server.Telemetry{}
+	}
+
+	sinks, err := telemetryPushSinks(telConfig, metrics.DefaultConfig("vault").HostName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return metricHelper, nil
+	if c.pathLabeler != nil {
+		c.pathLabeler.SetPatterns(telConfig.PathLabelAllowlist)
+	}
+
+	for _, sink := range c.telemetrySink.Swap(sinks) {
+		switch sink := sink.(type) {
+		case interface{ Shutdown() }:
+			sink.Shutdown()
+		case interface{ Flush() }:
+			sink.Flush()
+		}
+	}
+
+	return nil
 }
 
 func (c *ServerCommand) Reload(lock *sync.RWMutex, reloadFuncs *map[string][]reload.ReloadFunc, configPath []string) error {
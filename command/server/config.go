@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -32,11 +34,21 @@ type Config struct {
 
 	Seals []*Seal `hcl:"-"`
 
-	CacheSize                int         `hcl:"cache_size"`
-	DisableCache             bool        `hcl:"-"`
-	DisableCacheRaw          interface{} `hcl:"disable_cache"`
-	DisableMlock             bool        `hcl:"-"`
-	DisableMlockRaw          interface{} `hcl:"disable_mlock"`
+	ServiceRegistration *ServiceRegistration `hcl:"-"`
+
+	CacheSize       int         `hcl:"cache_size"`
+	DisableCache    bool        `hcl:"-"`
+	DisableCacheRaw interface{} `hcl:"disable_cache"`
+	DisableMlock    bool        `hcl:"-"`
+	DisableMlockRaw interface{} `hcl:"disable_mlock"`
+
+	DisableMlockAcknowledgeRisk    bool        `hcl:"-"`
+	DisableMlockAcknowledgeRiskRaw interface{} `hcl:"disable_mlock_acknowledge_risk"`
+	MlockKeyMaterialOnly           bool        `hcl:"-"`
+	MlockKeyMaterialOnlyRaw        interface{} `hcl:"mlock_key_material_only"`
+	DisableCoreDumps               bool        `hcl:"-"`
+	DisableCoreDumpsRaw            interface{} `hcl:"disable_core_dumps"`
+
 	DisablePrintableCheck    bool        `hcl:"-"`
 	DisablePrintableCheckRaw interface{} `hcl:"disable_printable_check"`
 
@@ -44,6 +56,7 @@ type Config struct {
 	EnableUIRaw interface{} `hcl:"ui"`
 
 	Telemetry *Telemetry `hcl:"telemetry"`
+	Tracing   *Tracing   `hcl:"tracing"`
 
 	MaxLeaseTTL        time.Duration `hcl:"-"`
 	MaxLeaseTTLRaw     interface{}   `hcl:"max_lease_ttl"`
@@ -53,6 +66,9 @@ type Config struct {
 	DefaultMaxRequestDuration    time.Duration `hcl:"-"`
 	DefaultMaxRequestDurationRaw interface{}   `hcl:"default_max_request_duration"`
 
+	ShutdownGracePeriod    time.Duration `hcl:"-"`
+	ShutdownGracePeriodRaw interface{}   `hcl:"shutdown_grace_period"`
+
 	ClusterName         string `hcl:"cluster_name"`
 	ClusterCipherSuites string `hcl:"cluster_cipher_suites"`
 
@@ -155,6 +171,17 @@ func (h *Seal) GoString() string {
 	return fmt.Sprintf("*%#v", *h)
 }
 
+// ServiceRegistration is the service registration configuration for the
+// server.
+type ServiceRegistration struct {
+	Type   string
+	Config map[string]string
+}
+
+func (s *ServiceRegistration) GoString() string {
+	return fmt.Sprintf("*%#v", *s)
+}
+
 // Telemetry is the telemetry configuration for the server
 type Telemetry struct {
 	StatsiteAddr string `hcl:"statsite_address"`
@@ -242,6 +269,12 @@ type Telemetry struct {
 	// It is a list of strings, where each string looks like "my_tag_name:my_tag_value"
 	DogStatsDTags []string `hcl:"dogstatsd_tags"`
 
+	// PathLabelAllowlist are the patterns of the request paths labeling the
+	// path metrics; other paths are labeled with their mount. The path
+	// metrics are only emitted when it is set.
+	// Default: none
+	PathLabelAllowlist []string `hcl:"path_label_allowlist"`
+
 	// Prometheus:
 	// PrometheusRetentionTime is the retention time for prometheus metrics if greater than 0.
 	// Default: 24h
@@ -253,6 +286,136 @@ func (s *Telemetry) GoString() string {
 	return fmt.Sprintf("*%#v", *s)
 }
 
+// Tracing is the configuration for exporting request traces to an
+// OpenTelemetry collector
+type Tracing struct {
+	// OTLPEndpoint is the URL spans are sent to using OTLP over HTTP, e.g.
+	// "http://localhost:4318/v1/traces"
+	OTLPEndpoint string `hcl:"otlp_endpoint"`
+
+	// OTLPHeaders are sent along with every export request, typically to
+	// authenticate to the collector
+	OTLPHeaders map[string]string `hcl:"otlp_headers"`
+
+	// ServiceName is reported as the service.name resource attribute.
+	// Default: vault
+	ServiceName string `hcl:"service_name"`
+
+	// SampleRate is the fraction of requests traced when the caller didn't
+	// send a sampled trace context. Default: 1
+	SampleRate    float64     `hcl:"-"`
+	SampleRateRaw interface{} `hcl:"sample_rate"`
+}
+
+func (s *Tracing) GoString() string {
+	return fmt.Sprintf("*%#v", *s)
+}
+
+// Sanitized returns a representation of the config suitable for display.
+// Storage and seal configuration maps and telemetry credentials are omitted
+// since they routinely contain secrets.
+func (c *Config) Sanitized() map[string]interface{} {
+	result := map[string]interface{}{
+		"cache_size":                   c.CacheSize,
+		"disable_cache":                c.DisableCache,
+		"disable_mlock":                c.DisableMlock,
+		"mlock_key_material_only":      c.MlockKeyMaterialOnly,
+		"disable_core_dumps":           c.DisableCoreDumps,
+		"disable_printable_check":      c.DisablePrintableCheck,
+		"ui":                           c.EnableUI,
+		"max_lease_ttl":                int64(c.MaxLeaseTTL.Seconds()),
+		"default_lease_ttl":            int64(c.DefaultLeaseTTL.Seconds()),
+		"default_max_request_duration": int64(c.DefaultMaxRequestDuration.Seconds()),
+		"shutdown_grace_period":        int64(c.ShutdownGracePeriod.Seconds()),
+		"cluster_name":                 c.ClusterName,
+		"cluster_cipher_suites":        c.ClusterCipherSuites,
+		"plugin_directory":             c.PluginDirectory,
+		"log_level":                    c.LogLevel,
+		"pid_file":                     c.PidFile,
+		"raw_storage_endpoint":         c.EnableRawEndpoint,
+		"api_addr":                     c.APIAddr,
+		"cluster_addr":                 c.ClusterAddr,
+		"disable_clustering":           c.DisableClustering,
+		"disable_performance_standby":  c.DisablePerformanceStandby,
+		"disable_sealwrap":             c.DisableSealWrap,
+		"disable_indexing":             c.DisableIndexing,
+	}
+
+	listeners := make([]interface{}, 0, len(c.Listeners))
+	for _, ln := range c.Listeners {
+		lnConfig := make(map[string]interface{}, len(ln.Config))
+		for k, v := range ln.Config {
+			switch v.(type) {
+			case string, bool, int, int64, float64:
+				lnConfig[k] = v
+			default:
+				lnConfig[k] = fmt.Sprintf("%v", v)
+			}
+		}
+		listeners = append(listeners, map[string]interface{}{
+			"type":   ln.Type,
+			"config": lnConfig,
+		})
+	}
+	result["listeners"] = listeners
+
+	sanitizeStorage := func(s *Storage) map[string]interface{} {
+		return map[string]interface{}{
+			"type":               s.Type,
+			"redirect_addr":      s.RedirectAddr,
+			"cluster_addr":       s.ClusterAddr,
+			"disable_clustering": s.DisableClustering,
+		}
+	}
+	if c.Storage != nil {
+		result["storage"] = sanitizeStorage(c.Storage)
+	}
+	if c.HAStorage != nil {
+		result["ha_storage"] = sanitizeStorage(c.HAStorage)
+	}
+
+	if c.ServiceRegistration != nil {
+		result["service_registration"] = map[string]interface{}{
+			"type": c.ServiceRegistration.Type,
+		}
+	}
+
+	seals := make([]interface{}, 0, len(c.Seals))
+	for _, seal := range c.Seals {
+		seals = append(seals, map[string]interface{}{
+			"type":     seal.Type,
+			"disabled": seal.Disabled,
+		})
+	}
+	result["seals"] = seals
+
+	if t := c.Telemetry; t != nil {
+		result["telemetry"] = map[string]interface{}{
+			"statsite_address":          t.StatsiteAddr,
+			"statsd_address":            t.StatsdAddr,
+			"disable_hostname":          t.DisableHostname,
+			"circonus_api_app":          t.CirconusAPIApp,
+			"circonus_api_url":          t.CirconusAPIURL,
+			"circonus_check_id":         t.CirconusCheckID,
+			"circonus_broker_id":        t.CirconusBrokerID,
+			"dogstatsd_addr":            t.DogStatsDAddr,
+			"dogstatsd_tags":            t.DogStatsDTags,
+			"path_label_allowlist":      t.PathLabelAllowlist,
+			"prometheus_retention_time": int64(t.PrometheusRetentionTime.Seconds()),
+		}
+	}
+
+	if t := c.Tracing; t != nil {
+		result["tracing"] = map[string]interface{}{
+			"otlp_endpoint": t.OTLPEndpoint,
+			"service_name":  t.ServiceName,
+			"sample_rate":   t.SampleRate,
+		}
+	}
+
+	return result
+}
+
 // Merge merges two configurations.
 func (c *Config) Merge(c2 *Config) *Config {
 	if c2 == nil {
@@ -277,6 +440,11 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.HAStorage = c2.HAStorage
 	}
 
+	result.ServiceRegistration = c.ServiceRegistration
+	if c2.ServiceRegistration != nil {
+		result.ServiceRegistration = c2.ServiceRegistration
+	}
+
 	for _, s := range c.Seals {
 		result.Seals = append(result.Seals, s)
 	}
@@ -289,6 +457,11 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.Telemetry = c2.Telemetry
 	}
 
+	result.Tracing = c.Tracing
+	if c2.Tracing != nil {
+		result.Tracing = c2.Tracing
+	}
+
 	result.CacheSize = c.CacheSize
 	if c2.CacheSize != 0 {
 		result.CacheSize = c2.CacheSize
@@ -305,6 +478,21 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.DisableMlock = c2.DisableMlock
 	}
 
+	result.DisableMlockAcknowledgeRisk = c.DisableMlockAcknowledgeRisk
+	if c2.DisableMlockAcknowledgeRisk {
+		result.DisableMlockAcknowledgeRisk = c2.DisableMlockAcknowledgeRisk
+	}
+
+	result.MlockKeyMaterialOnly = c.MlockKeyMaterialOnly
+	if c2.MlockKeyMaterialOnly {
+		result.MlockKeyMaterialOnly = c2.MlockKeyMaterialOnly
+	}
+
+	result.DisableCoreDumps = c.DisableCoreDumps
+	if c2.DisableCoreDumps {
+		result.DisableCoreDumps = c2.DisableCoreDumps
+	}
+
 	result.DisablePrintableCheck = c.DisablePrintableCheck
 	if c2.DisablePrintableCheckRaw != nil {
 		result.DisablePrintableCheck = c2.DisablePrintableCheck
@@ -326,6 +514,14 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.DefaultMaxRequestDuration = c2.DefaultMaxRequestDuration
 	}
 
+	// Retain raw value so that an explicit zero grace period survives merging
+	result.ShutdownGracePeriod = c.ShutdownGracePeriod
+	result.ShutdownGracePeriodRaw = c.ShutdownGracePeriodRaw
+	if c2.ShutdownGracePeriodRaw != nil {
+		result.ShutdownGracePeriod = c2.ShutdownGracePeriod
+		result.ShutdownGracePeriodRaw = c2.ShutdownGracePeriodRaw
+	}
+
 	result.LogLevel = c.LogLevel
 	if c2.LogLevel != "" {
 		result.LogLevel = c2.LogLevel
@@ -436,16 +632,152 @@ func LoadConfig(path string, logger log.Logger) (*Config, error) {
 	return LoadConfigFile(path, logger)
 }
 
-// LoadConfigFile loads the configuration from the given file.
+// LoadConfigFile loads the configuration from the given file. References of
+// the form ${VAR} are replaced with the value of the corresponding environment
+// variable before the file is parsed.
 func LoadConfigFile(path string, logger log.Logger) (*Config, error) {
 	// Read the file
 	d, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return ParseConfig(string(d), logger)
+
+	interpolated, err := interpolateEnv(string(d))
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(interpolated, logger)
+}
+
+var envReferenceRe = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateEnv replaces ${VAR} references in d with the value of the named
+// environment variable, escaped so that it can be placed inside a quoted HCL
+// or JSON string. A literal "${" can be written as "$${". Referencing a
+// variable that is not set is an error, so that a missing secret does not
+// silently turn into an empty value. Comments are left as they are.
+func interpolateEnv(d string) (string, error) {
+	var missing []string
+	replace := func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		name := match[2 : len(match)-1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+
+		return envValueReplacer.Replace(value)
+	}
+
+	var result strings.Builder
+	var offset int
+	for _, span := range hclCommentSpans(d) {
+		result.WriteString(envReferenceRe.ReplaceAllStringFunc(d[offset:span[0]], replace))
+		result.WriteString(d[span[0]:span[1]])
+		offset = span[1]
+	}
+	result.WriteString(envReferenceRe.ReplaceAllStringFunc(d[offset:], replace))
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables referenced in configuration are not set: %s", strings.Join(missing, ", "))
+	}
+
+	return result.String(), nil
+}
+
+// hclCommentSpans returns the start and end offsets of the comments of the
+// HCL document d. Comment markers within strings and heredocs don't start a
+// comment.
+func hclCommentSpans(d string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(d); i++ {
+		switch {
+		case d[i] == '"':
+			// Skip to the closing quote. Quotes nested in interpolations, as
+			// in "${lookup(m, "k")}", don't end the string.
+			var depth int
+		str:
+			for i++; i < len(d) && d[i] != '\n'; i++ {
+				switch {
+				case d[i] == '\\':
+					i++
+				case strings.HasPrefix(d[i:], "${"):
+					depth++
+					i++
+				case d[i] == '}' && depth > 0:
+					depth--
+				case d[i] == '"' && depth == 0:
+					break str
+				}
+			}
+
+		case strings.HasPrefix(d[i:], "<<"):
+			start := i + 2
+			if start < len(d) && d[start] == '-' {
+				start++
+			}
+			end := start
+			for end < len(d) && isHeredocMarkerChar(d[end]) {
+				end++
+			}
+			marker := d[start:end]
+			if marker == "" {
+				continue
+			}
+
+			// Skip to the end of the line closing the heredoc
+			i = len(d)
+			for pos := end; ; {
+				nl := strings.IndexByte(d[pos:], '\n')
+				if nl < 0 {
+					break
+				}
+				lineStart := pos + nl + 1
+				lineEnd := len(d)
+				if n := strings.IndexByte(d[lineStart:], '\n'); n >= 0 {
+					lineEnd = lineStart + n
+				}
+				if strings.TrimSpace(d[lineStart:lineEnd]) == marker {
+					i = lineEnd
+					break
+				}
+				pos = lineStart
+			}
+
+		case d[i] == '#' || strings.HasPrefix(d[i:], "//"):
+			end := strings.IndexByte(d[i:], '\n')
+			if end < 0 {
+				end = len(d)
+			} else {
+				end += i
+			}
+			spans = append(spans, [2]int{i, end})
+			i = end
+
+		case strings.HasPrefix(d[i:], "/*"):
+			end := strings.Index(d[i+2:], "*/")
+			if end < 0 {
+				end = len(d)
+			} else {
+				end += i + 4
+			}
+			spans = append(spans, [2]int{i, end})
+			i = end - 1
+		}
+	}
+	return spans
 }
 
+func isHeredocMarkerChar(c byte) bool {
+	return c == '_' || c == '-' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+var envValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
 func ParseConfig(d string, logger log.Logger) (*Config, error) {
 	// Parse!
 	obj, err := hcl.Parse(d)
@@ -476,6 +808,12 @@ func ParseConfig(d string, logger log.Logger) (*Config, error) {
 		}
 	}
 
+	if result.ShutdownGracePeriodRaw != nil {
+		if result.ShutdownGracePeriod, err = parseutil.ParseDurationSecond(result.ShutdownGracePeriodRaw); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.EnableUIRaw != nil {
 		if result.EnableUI, err = parseutil.ParseBool(result.EnableUIRaw); err != nil {
 			return nil, err
@@ -494,6 +832,24 @@ func ParseConfig(d string, logger log.Logger) (*Config, error) {
 		}
 	}
 
+	if result.DisableMlockAcknowledgeRiskRaw != nil {
+		if result.DisableMlockAcknowledgeRisk, err = parseutil.ParseBool(result.DisableMlockAcknowledgeRiskRaw); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.MlockKeyMaterialOnlyRaw != nil {
+		if result.MlockKeyMaterialOnly, err = parseutil.ParseBool(result.MlockKeyMaterialOnlyRaw); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.DisableCoreDumpsRaw != nil {
+		if result.DisableCoreDumps, err = parseutil.ParseBool(result.DisableCoreDumpsRaw); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.DisablePrintableCheckRaw != nil {
 		if result.DisablePrintableCheck, err = parseutil.ParseBool(result.DisablePrintableCheckRaw); err != nil {
 			return nil, err
@@ -566,6 +922,12 @@ func ParseConfig(d string, logger log.Logger) (*Config, error) {
 		}
 	}
 
+	if o := list.Filter("service_registration"); len(o.Items) > 0 {
+		if err := parseServiceRegistration(&result, o, "service_registration"); err != nil {
+			return nil, errwrap.Wrapf("error parsing 'service_registration': {{err}}", err)
+		}
+	}
+
 	if o := list.Filter("seal"); len(o.Items) > 0 {
 		if err := parseSeals(&result, o, "seal"); err != nil {
 			return nil, errwrap.Wrapf("error parsing 'seal': {{err}}", err)
@@ -584,6 +946,12 @@ func ParseConfig(d string, logger log.Logger) (*Config, error) {
 		}
 	}
 
+	if o := list.Filter("tracing"); len(o.Items) > 0 {
+		if err := parseTracing(&result, o); err != nil {
+			return nil, errwrap.Wrapf("error parsing 'tracing': {{err}}", err)
+		}
+	}
+
 	return &result, nil
 }
 
@@ -636,6 +1004,10 @@ func LoadConfigDir(dir string, logger log.Logger) (*Config, error) {
 		}
 	}
 
+	// Merge in lexical order so that later files reliably override earlier
+	// ones regardless of the order the filesystem returns them in
+	sort.Strings(files)
+
 	var result *Config
 	for _, f := range files {
 		config, err := LoadConfigFile(f, logger)
@@ -798,6 +1170,30 @@ func parseHAStorage(result *Config, list *ast.ObjectList, name string) error {
 	return nil
 }
 
+func parseServiceRegistration(result *Config, list *ast.ObjectList, name string) error {
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one %q block is permitted", name)
+	}
+
+	// Get our item
+	item := list.Items[0]
+	if len(item.Keys) == 0 {
+		return fmt.Errorf("%q block must specify a type", name)
+	}
+	key := item.Keys[0].Token.Value().(string)
+
+	var m map[string]string
+	if err := hcl.DecodeObject(&m, item.Val); err != nil {
+		return multierror.Prefix(err, fmt.Sprintf("%s.%s:", name, key))
+	}
+
+	result.ServiceRegistration = &ServiceRegistration{
+		Type:   strings.ToLower(key),
+		Config: m,
+	}
+	return nil
+}
+
 func parseSeals(result *Config, list *ast.ObjectList, blockName string) error {
 	if len(list.Items) > 2 {
 		return fmt.Errorf("only two or less %q blocks are permitted", blockName)
@@ -898,3 +1294,40 @@ func parseTelemetry(result *Config, list *ast.ObjectList) error {
 
 	return nil
 }
+
+func parseTracing(result *Config, list *ast.ObjectList) error {
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'tracing' block is permitted")
+	}
+
+	// Get our one item
+	item := list.Items[0]
+
+	var t Tracing
+	if err := hcl.DecodeObject(&t, item.Val); err != nil {
+		return multierror.Prefix(err, "tracing:")
+	}
+
+	if t.OTLPEndpoint == "" {
+		return fmt.Errorf("otlp_endpoint must be set")
+	}
+
+	if t.ServiceName == "" {
+		t.ServiceName = "vault"
+	}
+
+	t.SampleRate = 1
+	if t.SampleRateRaw != nil {
+		rate, err := strconv.ParseFloat(fmt.Sprintf("%v", t.SampleRateRaw), 64)
+		if err != nil {
+			return errwrap.Wrapf("error parsing sample_rate: {{err}}", err)
+		}
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("sample_rate must be between 0 and 1")
+		}
+		t.SampleRate = rate
+	}
+
+	result.Tracing = &t
+	return nil
+}
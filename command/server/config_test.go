@@ -1,6 +1,7 @@
 package server
 
 import (
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -77,6 +78,9 @@ func TestLoadConfigFile(t *testing.T) {
 		DefaultLeaseTTLRaw: "10h",
 		ClusterName:        "testcluster",
 
+		ShutdownGracePeriod:    15 * time.Second,
+		ShutdownGracePeriodRaw: "15s",
+
 		PidFile: "./pidfile",
 	}
 	if !reflect.DeepEqual(config, expected) {
@@ -408,3 +412,229 @@ listener "tcp" {
 	}
 
 }
+
+func TestConfig_Sanitized(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	config, err := LoadConfigFile("./test-fixtures/config.hcl", logger)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	sanitized := config.Sanitized()
+
+	expectedStorage := map[string]interface{}{
+		"type":               "consul",
+		"redirect_addr":      "foo",
+		"cluster_addr":       "",
+		"disable_clustering": false,
+	}
+	if !reflect.DeepEqual(sanitized["storage"], expectedStorage) {
+		t.Fatalf("expected storage to be %#v, got %#v", expectedStorage, sanitized["storage"])
+	}
+	if sanitized["cluster_name"] != "testcluster" {
+		t.Fatalf("bad cluster_name: %#v", sanitized["cluster_name"])
+	}
+	if sanitized["max_lease_ttl"] != int64(36000) {
+		t.Fatalf("bad max_lease_ttl: %#v", sanitized["max_lease_ttl"])
+	}
+	if _, ok := sanitized["telemetry"].(map[string]interface{})["circonus_api_token"]; ok {
+		t.Fatal("expected circonus_api_token to be omitted")
+	}
+}
+
+func TestLoadConfigFile_envInterpolation(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	for k, v := range map[string]string{
+		"VAULT_TEST_LISTENER_ADDR":  "127.0.0.1:8200",
+		"VAULT_TEST_CONSUL_TOKEN":   `s3cr"et\`,
+		"VAULT_TEST_CLUSTER_SUFFIX": "a",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	config, err := LoadConfigFile("./test-fixtures/config_env.hcl", logger)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if addr := config.Listeners[0].Config["address"]; addr != "127.0.0.1:8200" {
+		t.Fatalf("bad listener address: %#v", addr)
+	}
+	if token := config.Storage.Config["token"]; token != `s3cr"et\` {
+		t.Fatalf("bad storage token: %q", token)
+	}
+	if path := config.Storage.Config["path"]; path != "${not_interpolated}" {
+		t.Fatalf("bad storage path: %q", path)
+	}
+	if config.ClusterName != "env-a" {
+		t.Fatalf("bad cluster name: %q", config.ClusterName)
+	}
+
+	os.Unsetenv("VAULT_TEST_CONSUL_TOKEN")
+	_, err = LoadConfigFile("./test-fixtures/config_env.hcl", logger)
+	if err == nil || !strings.Contains(err.Error(), "VAULT_TEST_CONSUL_TOKEN") {
+		t.Fatalf("expected error naming the unset variable, got: %v", err)
+	}
+}
+
+func TestInterpolateEnv_comments(t *testing.T) {
+	os.Setenv("VAULT_TEST_PATH", "/vault/data")
+	defer os.Unsetenv("VAULT_TEST_PATH")
+
+	input := `# Storage for ${VAULT_TEST_UNSET}
+storage "file" {
+  path = "${VAULT_TEST_PATH}" // not ${VAULT_TEST_UNSET} either
+  /* nor ${VAULT_TEST_UNSET}
+     here */
+  node_id = "node#${VAULT_TEST_PATH}//"
+  note = <<EOT
+# ${VAULT_TEST_PATH}
+EOT
+}
+`
+	expected := `# Storage for ${VAULT_TEST_UNSET}
+storage "file" {
+  path = "/vault/data" // not ${VAULT_TEST_UNSET} either
+  /* nor ${VAULT_TEST_UNSET}
+     here */
+  node_id = "node#/vault/data//"
+  note = <<EOT
+# /vault/data
+EOT
+}
+`
+	actual, err := interpolateEnv(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != expected {
+		t.Fatalf("bad interpolation:\n%s", actual)
+	}
+}
+
+func TestParseConfig_serviceRegistration(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	config, err := ParseConfig(`
+storage "file" {
+	path = "/tmp/vault"
+}
+
+service_registration "Kubernetes" {
+	namespace = "vault"
+	pod_name  = "vault-0"
+}
+`, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ServiceRegistration{
+		Type: "kubernetes",
+		Config: map[string]string{
+			"namespace": "vault",
+			"pod_name":  "vault-0",
+		},
+	}
+	if !reflect.DeepEqual(config.ServiceRegistration, expected) {
+		t.Fatalf("expected \n\n%#v\n\n to be \n\n%#v\n\n", config.ServiceRegistration, expected)
+	}
+
+	_, err = ParseConfig(`
+service_registration "consul" {}
+service_registration "kubernetes" {}
+`, logger)
+	if err == nil {
+		t.Fatal("expected error with multiple service_registration blocks")
+	}
+}
+
+func TestParseConfig_memoryProtection(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	config, err := ParseConfig(`
+disable_mlock = true
+disable_mlock_acknowledge_risk = "true"
+mlock_key_material_only = true
+disable_core_dumps = true
+`, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.DisableMlock || !config.DisableMlockAcknowledgeRisk || !config.MlockKeyMaterialOnly || !config.DisableCoreDumps {
+		t.Fatalf("bad: %#v", config)
+	}
+
+	merged := (&Config{}).Merge(config)
+	if !merged.DisableMlockAcknowledgeRisk || !merged.MlockKeyMaterialOnly || !merged.DisableCoreDumps {
+		t.Fatalf("bad merge: %#v", merged)
+	}
+
+	if _, err := ParseConfig(`disable_core_dumps = "sometimes"`, logger); err == nil {
+		t.Fatal("expected error parsing non-boolean value")
+	}
+}
+
+func TestParseConfig_tracing(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	config, err := ParseConfig(`
+tracing {
+	otlp_endpoint = "http://127.0.0.1:4318/v1/traces"
+	otlp_headers = {
+		"Authorization" = "Bearer foo"
+	}
+	sample_rate = 0.25
+}
+`, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Tracing{
+		OTLPEndpoint:  "http://127.0.0.1:4318/v1/traces",
+		OTLPHeaders:   map[string]string{"Authorization": "Bearer foo"},
+		ServiceName:   "vault",
+		SampleRate:    0.25,
+		SampleRateRaw: 0.25,
+	}
+	if !reflect.DeepEqual(config.Tracing, expected) {
+		t.Fatalf("expected \n\n%#v\n\n to be \n\n%#v\n\n", config.Tracing, expected)
+	}
+
+	sanitized := config.Sanitized()["tracing"].(map[string]interface{})
+	if _, ok := sanitized["otlp_headers"]; ok {
+		t.Fatal("expected otlp_headers to be omitted")
+	}
+
+	config, err = ParseConfig(`
+tracing {
+	otlp_endpoint = "http://127.0.0.1:4318/v1/traces"
+}
+`, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Tracing.SampleRate != 1 {
+		t.Fatalf("bad default sample rate: %v", config.Tracing.SampleRate)
+	}
+
+	for _, bad := range []string{
+		`tracing {}`,
+		`tracing {
+	otlp_endpoint = "http://127.0.0.1:4318/v1/traces"
+	sample_rate = 2
+}`,
+		`tracing {
+	otlp_endpoint = "http://127.0.0.1:4318/v1/traces"
+	sample_rate = "often"
+}`,
+	} {
+		if _, err := ParseConfig(bad, logger); err == nil {
+			t.Fatalf("expected error parsing %q", bad)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/vault/helper/proxyutil"
 	"github.com/hashicorp/vault/helper/reload"
@@ -66,3 +69,85 @@ func listenerWrapProxy(ln net.Listener, config map[string]interface{}) (net.List
 
 	return newLn, nil
 }
+
+// parseCustomResponseHeaders parses the custom_response_headers listener
+// stanza. Each key is either "default", a status code such as "404", or a
+// status class such as "4xx", and maps header names to one or more values.
+func parseCustomResponseHeaders(raw interface{}) (map[string]http.Header, error) {
+	statuses, err := flattenHCLMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]http.Header, len(statuses))
+	for status, headersRaw := range statuses {
+		if !validCustomResponseHeaderStatus(status) {
+			return nil, fmt.Errorf("invalid status %q: must be \"default\", a status code or a status class such as \"4xx\"", status)
+		}
+
+		headers, err := flattenHCLMap(headersRaw)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("invalid headers for status %q: {{err}}", status), err)
+		}
+
+		h := make(http.Header, len(headers))
+		for name, valuesRaw := range headers {
+			if strings.HasPrefix(strings.ToLower(name), "x-vault-") {
+				return nil, fmt.Errorf("header %q cannot be overridden", name)
+			}
+
+			// Each value of a list is sent as a separate header line
+			switch values := valuesRaw.(type) {
+			case string:
+				h.Add(name, values)
+			case []interface{}:
+				for _, v := range values {
+					str, ok := v.(string)
+					if !ok {
+						return nil, fmt.Errorf("value of header %q must be a string or a list of strings", name)
+					}
+					h.Add(name, str)
+				}
+			default:
+				return nil, fmt.Errorf("value of header %q must be a string or a list of strings", name)
+			}
+		}
+		result[status] = h
+	}
+
+	return result, nil
+}
+
+func validCustomResponseHeaderStatus(status string) bool {
+	if status == "default" {
+		return true
+	}
+	if len(status) != 3 {
+		return false
+	}
+	if strings.HasSuffix(status, "xx") {
+		return status[0] >= '1' && status[0] <= '5'
+	}
+	code, err := strconv.Atoi(status)
+	return err == nil && code >= 100 && code <= 599
+}
+
+// flattenHCLMap returns the given HCL object as a single map. HCL decodes
+// nested blocks as lists of maps, whereas JSON configuration decodes them as
+// plain maps.
+func flattenHCLMap(raw interface{}) (map[string]interface{}, error) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case []map[string]interface{}:
+		result := make(map[string]interface{})
+		for _, m := range v {
+			for key, val := range m {
+				result[key] = val
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected an object, got %T", raw)
+	}
+}
@@ -95,6 +95,14 @@ func tcpListenerFactory(config map[string]interface{}, _ io.Writer, ui cli.Ui) (
 		config["x_forwarded_for_reject_not_authorized"] = true
 	}
 
+	if headersRaw, ok := config["custom_response_headers"]; ok {
+		headers, err := parseCustomResponseHeaders(headersRaw)
+		if err != nil {
+			return nil, nil, nil, errwrap.Wrapf("error parsing \"custom_response_headers\": {{err}}", err)
+		}
+		config["custom_response_headers"] = headers
+	}
+
 	ln, props, reloadFunc, _, err := listenerutil.WrapTLS(ln, props, config, ui)
 	if err != nil {
 		return nil, nil, nil, err
@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"io"
 	"net"
+	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -62,3 +64,53 @@ func testListenerImpl(t *testing.T, ln net.Listener, connFn testListenerConnFn,
 		t.Fatalf("bad: %v", buf.String())
 	}
 }
+
+func TestParseCustomResponseHeaders(t *testing.T) {
+	config, err := ParseConfig(`
+listener "tcp" {
+  address = "127.0.0.1:8200"
+  custom_response_headers {
+    "default" = {
+      "Strict-Transport-Security" = "max-age=31536000; includeSubDomains"
+      "Content-Security-Policy"   = ["default-src 'none'", "frame-ancestors 'none'"]
+    }
+    "4xx" = {
+      "Cache-Control" = "no-cache"
+    }
+  }
+}
+`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers, err := parseCustomResponseHeaders(config.Listeners[0].Config["custom_response_headers"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]http.Header{
+		"default": {
+			"Strict-Transport-Security": []string{"max-age=31536000; includeSubDomains"},
+			"Content-Security-Policy":   []string{"default-src 'none'", "frame-ancestors 'none'"},
+		},
+		"4xx": {
+			"Cache-Control": []string{"no-cache"},
+		},
+	}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Fatalf("bad: expected %#v, got %#v", expected, headers)
+	}
+
+	for _, raw := range []map[string]interface{}{
+		{"6xx": map[string]interface{}{"Foo": "bar"}},
+		{"40": map[string]interface{}{"Foo": "bar"}},
+		{"default": map[string]interface{}{"X-Vault-Token": "bar"}},
+		{"default": map[string]interface{}{"Foo": 5}},
+		{"default": "bar"},
+	} {
+		if _, err := parseCustomResponseHeaders(raw); err == nil {
+			t.Fatalf("expected error parsing %#v", raw)
+		}
+	}
+}
@@ -29,6 +29,7 @@ max_lease_ttl = "10h"
 default_lease_ttl = "10h"
 cluster_name = "testcluster"
 pid_file = "./pidfile"
+shutdown_grace_period = "15s"
 raw_storage_endpoint = true
 disable_sealwrap = true
 disable_printable_check = true
@@ -0,0 +1,10 @@
+listener "tcp" {
+    address = "${VAULT_TEST_LISTENER_ADDR}"
+}
+
+storage "consul" {
+    token = "${VAULT_TEST_CONSUL_TOKEN}"
+    path = "$${not_interpolated}"
+}
+
+cluster_name = "env-${VAULT_TEST_CLUSTER_SUFFIX}"
@@ -2,7 +2,6 @@ package command
 
 import (
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -20,6 +19,7 @@ import (
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/testhelpers"
 	vaulthttp "github.com/hashicorp/vault/http"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/version"
 	"github.com/hashicorp/vault/vault"
@@ -49,11 +49,12 @@ func (c *ServerCommand) enableFourClusterDev(base *vault.CoreConfig, info map[st
 		}
 	}
 
-	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
+	var keyBundle certutil.ParsedCSRBundle
+	if err := certutil.GeneratePrivateKey(certutil.ECPrivateKey, 256, &keyBundle); err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to generate CA key: %s", err))
 		return 1
 	}
+	caKey := keyBundle.PrivateKey.(*ecdsa.PrivateKey)
 	certIPs := []net.IP{
 		net.IPv6loopback,
 		net.ParseIP("127.0.0.1"),
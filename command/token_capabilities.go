@@ -14,6 +14,8 @@ var _ cli.CommandAutocomplete = (*TokenCapabilitiesCommand)(nil)
 
 type TokenCapabilitiesCommand struct {
 	*BaseCommand
+
+	flagAccessor bool
 }
 
 func (c *TokenCapabilitiesCommand) Synopsis() string {
@@ -22,12 +24,14 @@ func (c *TokenCapabilitiesCommand) Synopsis() string {
 
 func (c *TokenCapabilitiesCommand) Help() string {
 	helpText := `
-Usage: vault token capabilities [options] [TOKEN] PATH
+Usage: vault token capabilities [options] [TOKEN | ACCESSOR] PATH
 
   Fetches the capabilities of a token for a given path. If a TOKEN is provided
   as an argument, the "/sys/capabilities" endpoint and permission is used. If
-  no TOKEN is provided, the "/sys/capabilities-self" endpoint and permission
-  is used with the locally authenticated token.
+  an ACCESSOR is provided with -accessor, the "/sys/capabilities-accessor"
+  endpoint and permission is used. If no TOKEN is provided, the
+  "/sys/capabilities-self" endpoint and permission is used with the locally
+  authenticated token.
 
   List capabilities for the local token on the "secret/foo" path:
 
@@ -37,6 +41,10 @@ Usage: vault token capabilities [options] [TOKEN] PATH
 
       $ vault token capabilities 96ddf4bc-d217-f3ba-f9bd-017055595017 cubbyhole/foo
 
+  List capabilities for a token on the "cubbyhole/foo" path via its accessor:
+
+      $ vault token capabilities -accessor 9793c9b3-e04a-46f3-e7b8-748d7da248da cubbyhole/foo
+
   For a full list of examples, please see the documentation.
 
 ` + c.Flags().Help()
@@ -45,7 +53,20 @@ Usage: vault token capabilities [options] [TOKEN] PATH
 }
 
 func (c *TokenCapabilitiesCommand) Flags() *FlagSets {
-	return c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:       "accessor",
+		Target:     &c.flagAccessor,
+		Default:    false,
+		EnvVar:     "",
+		Completion: complete.PredictNothing,
+		Usage:      "Treat the argument as an accessor instead of a token.",
+	})
+
+	return set
 }
 
 func (c *TokenCapabilitiesCommand) AutocompleteArgs() complete.Predictor {
@@ -67,13 +88,16 @@ func (c *TokenCapabilitiesCommand) Run(args []string) int {
 	token := ""
 	path := ""
 	args = f.Args()
-	switch len(args) {
-	case 0:
+	switch {
+	case c.flagAccessor && len(args) < 2:
+		c.UI.Error(fmt.Sprintf("Not enough arguments with -accessor (expected 2, got %d)", len(args)))
+		return 1
+	case len(args) == 0:
 		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1-2, got 0)"))
 		return 1
-	case 1:
+	case len(args) == 1:
 		path = args[0]
-	case 2:
+	case len(args) == 2:
 		token, path = args[0], args[1]
 	default:
 		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1-2, got %d)", len(args)))
@@ -87,9 +111,12 @@ func (c *TokenCapabilitiesCommand) Run(args []string) int {
 	}
 
 	var capabilities []string
-	if token == "" {
+	switch {
+	case token == "":
 		capabilities, err = client.Sys().CapabilitiesSelf(path)
-	} else {
+	case c.flagAccessor:
+		capabilities, err = client.Sys().CapabilitiesAccessor(token, path)
+	default:
 		capabilities, err = client.Sys().Capabilities(token, path)
 	}
 	if err != nil {
@@ -34,6 +34,12 @@ func TestTokenCapabilitiesCommand_Run(t *testing.T) {
 			"Too many arguments",
 			1,
 		},
+		{
+			"accessor_no_path",
+			[]string{"-accessor", "foo"},
+			"Not enough arguments",
+			1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -100,6 +106,46 @@ func TestTokenCapabilitiesCommand_Run(t *testing.T) {
 		}
 	})
 
+	t.Run("accessor", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		policy := `path "secret/foo" { capabilities = ["read"] }`
+		if err := client.Sys().PutPolicy("policy", policy); err != nil {
+			t.Error(err)
+		}
+
+		secret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+			Policies: []string{"policy"},
+			TTL:      "30m",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret == nil || secret.Auth == nil || secret.Auth.Accessor == "" {
+			t.Fatalf("missing auth data: %#v", secret)
+		}
+		accessor := secret.Auth.Accessor
+
+		ui, cmd := testTokenCapabilitiesCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-accessor", accessor, "secret/foo",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "read"
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
 	t.Run("local", func(t *testing.T) {
 		t.Parallel()
 
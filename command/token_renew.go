@@ -16,6 +16,7 @@ var _ cli.CommandAutocomplete = (*TokenRenewCommand)(nil)
 type TokenRenewCommand struct {
 	*BaseCommand
 
+	flagAccessor  bool
 	flagIncrement time.Duration
 }
 
@@ -25,7 +26,7 @@ func (c *TokenRenewCommand) Synopsis() string {
 
 func (c *TokenRenewCommand) Help() string {
 	helpText := `
-Usage: vault token renew [options] [TOKEN]
+Usage: vault token renew [options] [TOKEN | ACCESSOR]
 
   Renews a token's lease, extending the amount of time it can be used. If a
   TOKEN is not provided, the locally authenticated token is used. Lease renewal
@@ -45,6 +46,11 @@ Usage: vault token renew [options] [TOKEN]
 
       $ vault token renew -increment=30m 96ddf4bc-d217-f3ba-f9bd-017055595017
 
+  Renew a token via its accessor (this uses the /auth/token/renew-accessor
+  endpoint and permission):
+
+      $ vault token renew -accessor 9793c9b3-e04a-46f3-e7b8-748d7da248da
+
   For a full list of examples, please see the documentation.
 
 ` + c.Flags().Help()
@@ -56,6 +62,16 @@ func (c *TokenRenewCommand) Flags() *FlagSets {
 	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
 	f := set.NewFlagSet("Command Options")
 
+	f.BoolVar(&BoolVar{
+		Name:       "accessor",
+		Target:     &c.flagAccessor,
+		Default:    false,
+		EnvVar:     "",
+		Completion: complete.PredictNothing,
+		Usage: "Treat the argument as an accessor instead of a token. When " +
+			"this option is selected, the output will NOT include the token.",
+	})
+
 	f.DurationVar(&DurationVar{
 		Name:       "increment",
 		Aliases:    []string{"i"},
@@ -92,10 +108,13 @@ func (c *TokenRenewCommand) Run(args []string) int {
 	increment := c.flagIncrement
 
 	args = f.Args()
-	switch len(args) {
-	case 0:
+	switch {
+	case c.flagAccessor && len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments with -accessor (expected 1, got %d)", len(args)))
+		return 1
+	case len(args) == 0:
 		// Use the local token
-	case 1:
+	case len(args) == 1:
 		token = strings.TrimSpace(args[0])
 	default:
 		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
@@ -110,9 +129,12 @@ func (c *TokenRenewCommand) Run(args []string) int {
 
 	var secret *api.Secret
 	inc := truncateToSeconds(increment)
-	if token == "" {
+	switch {
+	case token == "":
 		secret, err = client.Auth().Token().RenewSelf(inc)
-	} else {
+	case c.flagAccessor:
+		secret, err = client.Auth().Token().RenewAccessor(token, inc)
+	default:
 		secret, err = client.Auth().Token().Renew(token, inc)
 	}
 	if err != nil {
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/hashicorp/vault/command/server"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/vault"
+	"github.com/mitchellh/cli"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*ValidateCommand)(nil)
+var _ cli.CommandAutocomplete = (*ValidateCommand)(nil)
+
+type ValidateCommand struct {
+	*BaseCommand
+
+	flagType string
+}
+
+func (c *ValidateCommand) Synopsis() string {
+	return "Validates configuration and policy files"
+}
+
+func (c *ValidateCommand) Help() string {
+	helpText := `
+Usage: vault validate [options] PATH [PATH ...]
+
+  Validates local server configuration, agent configuration or policy files
+  without starting Vault or contacting a Vault server. Parsing errors are
+  reported with the line on which they occur, and the command exits with a
+  non-zero status if any of the files is invalid.
+
+  Validate the server configuration file "config.hcl":
+
+      $ vault validate config.hcl
+
+  Validate all the server configuration files in the "config" directory:
+
+      $ vault validate config/
+
+  Validate the policy files "my-policy.hcl" and "other-policy.hcl":
+
+      $ vault validate -type=policy my-policy.hcl other-policy.hcl
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ValidateCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetNone)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "type",
+		Target:     &c.flagType,
+		Default:    "server",
+		Completion: complete.PredictSet("server", "agent", "policy"),
+		Usage: "Type of the files to validate. This can be \"server\" for " +
+			"server configuration files or directories, \"agent\" for agent " +
+			"configuration files, or \"policy\" for policy files.",
+	})
+
+	return set
+}
+
+func (c *ValidateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(
+		complete.PredictFiles("*.hcl"),
+		complete.PredictFiles("*.json"),
+		complete.PredictDirs("*"),
+	)
+}
+
+func (c *ValidateCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *ValidateCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) < 1 {
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected at least 1, got %d)", len(args)))
+		return 1
+	}
+
+	var validate func(string) error
+	switch c.flagType {
+	case "server":
+		validate = validateServerConfig
+	case "agent":
+		validate = validateAgentConfig
+	case "policy":
+		validate = validatePolicy
+	default:
+		c.UI.Error(fmt.Sprintf("Invalid type %q: must be \"server\", \"agent\" or \"policy\"", c.flagType))
+		return 1
+	}
+
+	code := 0
+	for _, arg := range args {
+		// Get the filepath, accounting for ~ and stuff
+		path, err := homedir.Expand(strings.TrimSpace(arg))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to expand path %q: %s", arg, err))
+			code = 1
+			continue
+		}
+
+		if err := validate(path); err != nil {
+			c.UI.Error(fmt.Sprintf("Error validating %s: %s", path, err))
+			code = 1
+			continue
+		}
+
+		c.UI.Output(fmt.Sprintf("Success! Valid %s file: %s", c.flagType, path))
+	}
+
+	return code
+}
+
+func validateServerConfig(path string) error {
+	cfg, err := server.LoadConfig(path, log.NewNullLogger())
+	if err != nil {
+		return err
+	}
+
+	// These are checked when the server starts, outside of parsing
+	if cfg.Storage == nil {
+		return fmt.Errorf("a storage backend must be specified")
+	}
+	if _, ok := physicalBackends[cfg.Storage.Type]; !ok {
+		return fmt.Errorf("unknown storage type %q", cfg.Storage.Type)
+	}
+	if cfg.HAStorage != nil {
+		if _, ok := physicalBackends[cfg.HAStorage.Type]; !ok {
+			return fmt.Errorf("unknown HA storage type %q", cfg.HAStorage.Type)
+		}
+	}
+
+	return nil
+}
+
+func validateAgentConfig(path string) error {
+	_, err := config.LoadConfig(path, log.NewNullLogger())
+	return err
+}
+
+func validatePolicy(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// We always use the root namespace here because the namespace a policy is
+	// written to does not affect whether it is valid.
+	_, err = vault.ParseACLPolicy(namespace.RootNamespace, string(b))
+	return err
+}
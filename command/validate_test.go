@@ -0,0 +1,157 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func testValidateCommand(tb testing.TB) (*cli.MockUi, *ValidateCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &ValidateCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestValidateCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	writeFile := func(t *testing.T, contents string) string {
+		f, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		return f.Name()
+	}
+
+	goodServer := writeFile(t, `
+storage "inmem" {}
+
+listener "tcp" {
+  address = "127.0.0.1:8200"
+}
+`)
+	defer os.Remove(goodServer)
+
+	noStorage := writeFile(t, `
+listener "tcp" {
+  address = "127.0.0.1:8200"
+}
+`)
+	defer os.Remove(noStorage)
+
+	badStorage := writeFile(t, `storage "banana" {}`)
+	defer os.Remove(badStorage)
+
+	badHCL := writeFile(t, `
+storage "inmem" {}
+
+listener "tcp" {
+  address =
+}
+`)
+	defer os.Remove(badHCL)
+
+	goodPolicy := writeFile(t, `path "secret/*" { capabilities = ["read"] }`)
+	defer os.Remove(goodPolicy)
+
+	badPolicy := writeFile(t, `path "secret/*" { capabilities = ["bogus"] }`)
+	defer os.Remove(badPolicy)
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"not_enough_args",
+			[]string{},
+			"Not enough arguments",
+			1,
+		},
+		{
+			"bad_type",
+			[]string{"-type", "banana", goodServer},
+			"Invalid type",
+			1,
+		},
+		{
+			"missing_file",
+			[]string{"/nope/not/a/file.hcl"},
+			"Error validating",
+			1,
+		},
+		{
+			"server",
+			[]string{goodServer},
+			"Success! Valid server file",
+			0,
+		},
+		{
+			"server_no_storage",
+			[]string{noStorage},
+			"a storage backend must be specified",
+			1,
+		},
+		{
+			"server_unknown_storage",
+			[]string{badStorage},
+			`unknown storage type "banana"`,
+			1,
+		},
+		{
+			"server_bad_hcl",
+			[]string{badHCL},
+			"At 7:1",
+			1,
+		},
+		{
+			"policy",
+			[]string{"-type", "policy", goodPolicy},
+			"Success! Valid policy file",
+			0,
+		},
+		{
+			"policy_invalid",
+			[]string{"-type", "policy", goodPolicy, badPolicy},
+			"failed to parse policy",
+			1,
+		},
+	}
+
+	// The files are removed once this returns, after all the parallel
+	// subtests are done
+	t.Run("group", func(t *testing.T) {
+		for _, tc := range cases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				ui, cmd := testValidateCommand(t)
+
+				code := cmd.Run(tc.args)
+				if code != tc.code {
+					t.Errorf("expected %d to be %d", code, tc.code)
+				}
+
+				combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+				if !strings.Contains(combined, tc.out) {
+					t.Errorf("expected %q to contain %q", combined, tc.out)
+				}
+			})
+		}
+	})
+}
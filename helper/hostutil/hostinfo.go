@@ -0,0 +1,91 @@
+// Package hostutil collects information about the host Vault is running on,
+// for use by support and autoscaling tooling.
+package hostutil
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// HostInfo holds information about the host. Fields that cannot be collected
+// on the current platform are left empty.
+type HostInfo struct {
+	Timestamp time.Time   `json:"timestamp" structs:"timestamp" mapstructure:"timestamp"`
+	Host      *HostStat   `json:"host" structs:"host" mapstructure:"host"`
+	CPU       *CPUStat    `json:"cpu" structs:"cpu" mapstructure:"cpu"`
+	Memory    *MemoryStat `json:"memory,omitempty" structs:"memory,omitempty" mapstructure:"memory"`
+	Disk      []*DiskStat `json:"disk,omitempty" structs:"disk,omitempty" mapstructure:"disk"`
+}
+
+// HostStat describes the host itself.
+type HostStat struct {
+	Hostname string `json:"hostname" structs:"hostname" mapstructure:"hostname"`
+	OS       string `json:"os" structs:"os" mapstructure:"os"`
+	Arch     string `json:"arch" structs:"arch" mapstructure:"arch"`
+
+	// Uptime is the number of seconds since the host booted
+	Uptime   uint64 `json:"uptime" structs:"uptime" mapstructure:"uptime"`
+	BootTime uint64 `json:"boot_time" structs:"boot_time" mapstructure:"boot_time"`
+}
+
+// CPUStat describes the processors of the host and their load averages.
+type CPUStat struct {
+	Count  int     `json:"count" structs:"count" mapstructure:"count"`
+	Load1  float64 `json:"load1" structs:"load1" mapstructure:"load1"`
+	Load5  float64 `json:"load5" structs:"load5" mapstructure:"load5"`
+	Load15 float64 `json:"load15" structs:"load15" mapstructure:"load15"`
+}
+
+// MemoryStat describes the memory of the host, in bytes. Available includes
+// memory used for caches that can be reclaimed, and Used excludes it.
+type MemoryStat struct {
+	Total       uint64  `json:"total" structs:"total" mapstructure:"total"`
+	Available   uint64  `json:"available" structs:"available" mapstructure:"available"`
+	Free        uint64  `json:"free" structs:"free" mapstructure:"free"`
+	Used        uint64  `json:"used" structs:"used" mapstructure:"used"`
+	UsedPercent float64 `json:"used_percent" structs:"used_percent" mapstructure:"used_percent"`
+}
+
+// DiskStat describes the usage of the file system holding a path, in bytes.
+type DiskStat struct {
+	Path        string  `json:"path" structs:"path" mapstructure:"path"`
+	Total       uint64  `json:"total" structs:"total" mapstructure:"total"`
+	Free        uint64  `json:"free" structs:"free" mapstructure:"free"`
+	Used        uint64  `json:"used" structs:"used" mapstructure:"used"`
+	UsedPercent float64 `json:"used_percent" structs:"used_percent" mapstructure:"used_percent"`
+}
+
+// CollectHostInfo returns information about the host, including the usage of
+// the file systems holding each of the given paths.
+func CollectHostInfo(paths ...string) (*HostInfo, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &HostInfo{
+		Timestamp: time.Now().UTC(),
+		Host: &HostStat{
+			Hostname: hostname,
+			OS:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+		},
+		CPU: &CPUStat{
+			Count: runtime.NumCPU(),
+		},
+	}
+
+	if err := collectPlatform(info, paths); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func usedPercent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total) * 100
+}
@@ -0,0 +1,99 @@
+//go:build linux
+// +build linux
+
+package hostutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"golang.org/x/sys/unix"
+)
+
+// loadScale is the fixed-point scale of the load averages returned by
+// sysinfo(2)
+const loadScale = 1 << 16
+
+func collectPlatform(info *HostInfo, paths []string) error {
+	var si unix.Sysinfo_t
+	if err := unix.Sysinfo(&si); err != nil {
+		return errwrap.Wrapf("failed to read system information: {{err}}", err)
+	}
+
+	info.Host.Uptime = uint64(si.Uptime)
+	info.Host.BootTime = uint64(time.Now().Unix() - int64(si.Uptime))
+
+	info.CPU.Load1 = float64(si.Loads[0]) / loadScale
+	info.CPU.Load5 = float64(si.Loads[1]) / loadScale
+	info.CPU.Load15 = float64(si.Loads[2]) / loadScale
+
+	unit := uint64(si.Unit)
+	total := uint64(si.Totalram) * unit
+	free := uint64(si.Freeram) * unit
+	available, err := memAvailable()
+	if err != nil {
+		// Fall back to what sysinfo(2) reports, which does not account for
+		// the page cache
+		available = free + uint64(si.Bufferram)*unit
+	}
+	used := total - available
+	info.Memory = &MemoryStat{
+		Total:       total,
+		Available:   available,
+		Free:        free,
+		Used:        used,
+		UsedPercent: usedPercent(used, total),
+	}
+
+	for _, path := range paths {
+		var fs unix.Statfs_t
+		if err := unix.Statfs(path, &fs); err != nil {
+			return errwrap.Wrapf("failed to read file system information: {{err}}", err)
+		}
+
+		bsize := uint64(fs.Bsize)
+		total := fs.Blocks * bsize
+		free := fs.Bavail * bsize
+		used := (fs.Blocks - fs.Bfree) * bsize
+		info.Disk = append(info.Disk, &DiskStat{
+			Path:        path,
+			Total:       total,
+			Free:        free,
+			Used:        used,
+			UsedPercent: usedPercent(used, total),
+		})
+	}
+
+	return nil
+}
+
+// memAvailable returns the MemAvailable estimate of the kernel, in bytes
+func memAvailable() (uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("MemAvailable not found in /proc/meminfo")
+}
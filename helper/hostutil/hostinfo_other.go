@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package hostutil
+
+// collectPlatform is a no-op on platforms where only the portable
+// information is available.
+func collectPlatform(info *HostInfo, paths []string) error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package hostutil
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestCollectHostInfo(t *testing.T) {
+	info, err := CollectHostInfo("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Host.Hostname == "" || info.Host.OS != runtime.GOOS {
+		t.Fatalf("bad host: %#v", info.Host)
+	}
+	if info.CPU.Count != runtime.NumCPU() {
+		t.Fatalf("bad cpu: %#v", info.CPU)
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	if info.Host.Uptime == 0 {
+		t.Fatalf("expected uptime: %#v", info.Host)
+	}
+	if info.Memory == nil || info.Memory.Total == 0 || info.Memory.Used > info.Memory.Total {
+		t.Fatalf("bad memory: %#v", info.Memory)
+	}
+	if len(info.Disk) != 1 || info.Disk[0].Path != "/" || info.Disk[0].Total == 0 {
+		t.Fatalf("bad disk: %#v", info.Disk)
+	}
+}
@@ -0,0 +1,70 @@
+package metricsutil
+
+import (
+	"sync"
+
+	glob "github.com/ryanuber/go-glob"
+)
+
+// PathLabeler maps request paths to the labels of path metrics while keeping
+// their cardinality bounded, whatever serial numbers, token accessors or
+// other identifiers the paths hold. A path matching a pattern of the
+// allowlist is labeled with that pattern, and any other path with its mount
+// followed by "*".
+//
+// Patterns are matched against the full request paths, namespace included,
+// and may hold "*" wildcards, e.g. "pki/issue/*". A PathLabeler without
+// patterns is disabled, and no path metric should be emitted for it.
+type PathLabeler struct {
+	l        sync.RWMutex
+	patterns []string
+}
+
+// NewPathLabeler returns a PathLabeler with the given allowlist of patterns
+func NewPathLabeler(patterns []string) *PathLabeler {
+	p := &PathLabeler{}
+	p.SetPatterns(patterns)
+	return p
+}
+
+// SetPatterns replaces the allowlist of patterns, e.g. when the telemetry
+// configuration is reloaded
+func (p *PathLabeler) SetPatterns(patterns []string) {
+	// Empty patterns would only match empty paths
+	filtered := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern != "" {
+			filtered = append(filtered, pattern)
+		}
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.patterns = filtered
+}
+
+// Enabled returns whether path metrics should be emitted, that is whether the
+// allowlist has any pattern
+func (p *PathLabeler) Enabled() bool {
+	if p == nil {
+		return false
+	}
+
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return len(p.patterns) > 0
+}
+
+// Label returns the label of the given request path under the given mount.
+// The first pattern of the allowlist matching the path wins.
+func (p *PathLabeler) Label(mount, path string) string {
+	p.l.RLock()
+	defer p.l.RUnlock()
+
+	for _, pattern := range p.patterns {
+		if glob.Glob(pattern, path) {
+			return pattern
+		}
+	}
+	return mount + "*"
+}
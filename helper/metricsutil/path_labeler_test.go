@@ -0,0 +1,36 @@
+package metricsutil
+
+import (
+	"testing"
+)
+
+func TestPathLabeler(t *testing.T) {
+	var disabled *PathLabeler
+	if disabled.Enabled() {
+		t.Fatal("expected a nil labeler to be disabled")
+	}
+
+	labeler := NewPathLabeler([]string{"", "pki/issue/*", "pki/cert/ca", "ns1/secret/*"})
+	if !labeler.Enabled() {
+		t.Fatal("expected the labeler to be enabled")
+	}
+
+	for _, tc := range []struct {
+		mount, path, expected string
+	}{
+		{"pki/", "pki/issue/web", "pki/issue/*"},
+		{"pki/", "pki/cert/ca", "pki/cert/ca"},
+		{"pki/", "pki/cert/17-ab-9f-01", "pki/*"},
+		{"auth/token/", "auth/token/lookup-accessor/8609694a", "auth/token/*"},
+		{"ns1/secret/", "ns1/secret/foo", "ns1/secret/*"},
+	} {
+		if label := labeler.Label(tc.mount, tc.path); label != tc.expected {
+			t.Fatalf("expected label %q for %q, got %q", tc.expected, tc.path, label)
+		}
+	}
+
+	labeler.SetPatterns(nil)
+	if labeler.Enabled() {
+		t.Fatal("expected the labeler to be disabled without patterns")
+	}
+}
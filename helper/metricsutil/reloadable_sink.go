@@ -0,0 +1,69 @@
+package metricsutil
+
+import (
+	"sync"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// ReloadableSink is a metrics.MetricSink that forwards to a set of sinks that
+// can be replaced at runtime, allowing telemetry destinations to be changed
+// without re-creating the global metrics instance.
+type ReloadableSink struct {
+	l     sync.RWMutex
+	sinks metrics.FanoutSink
+}
+
+var _ metrics.MetricSink = (*ReloadableSink)(nil)
+
+// NewReloadableSink returns a ReloadableSink forwarding to the given sinks
+func NewReloadableSink(sinks metrics.FanoutSink) *ReloadableSink {
+	return &ReloadableSink{
+		sinks: sinks,
+	}
+}
+
+// Swap replaces the sinks metrics are forwarded to and returns the previous
+// set so that the caller can shut them down.
+func (r *ReloadableSink) Swap(sinks metrics.FanoutSink) metrics.FanoutSink {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	old := r.sinks
+	r.sinks = sinks
+	return old
+}
+
+func (r *ReloadableSink) current() metrics.FanoutSink {
+	r.l.RLock()
+	defer r.l.RUnlock()
+	return r.sinks
+}
+
+func (r *ReloadableSink) SetGauge(key []string, val float32) {
+	r.current().SetGauge(key, val)
+}
+
+func (r *ReloadableSink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	r.current().SetGaugeWithLabels(key, val, labels)
+}
+
+func (r *ReloadableSink) EmitKey(key []string, val float32) {
+	r.current().EmitKey(key, val)
+}
+
+func (r *ReloadableSink) IncrCounter(key []string, val float32) {
+	r.current().IncrCounter(key, val)
+}
+
+func (r *ReloadableSink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	r.current().IncrCounterWithLabels(key, val, labels)
+}
+
+func (r *ReloadableSink) AddSample(key []string, val float32) {
+	r.current().AddSample(key, val)
+}
+
+func (r *ReloadableSink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	r.current().AddSampleWithLabels(key, val, labels)
+}
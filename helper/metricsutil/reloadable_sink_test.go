@@ -0,0 +1,42 @@
+package metricsutil
+
+import (
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+func TestReloadableSink_Swap(t *testing.T) {
+	first := metrics.NewInmemSink(time.Minute, time.Minute)
+	second := metrics.NewInmemSink(time.Minute, time.Minute)
+
+	sink := NewReloadableSink(metrics.FanoutSink{first})
+	sink.IncrCounter([]string{"foo"}, 1)
+
+	old := sink.Swap(metrics.FanoutSink{second})
+	if len(old) != 1 || old[0] != first {
+		t.Fatalf("expected previous sinks to be returned, got %#v", old)
+	}
+	sink.IncrCounter([]string{"bar"}, 1)
+
+	firstData := first.Data()
+	if _, ok := firstData[0].Counters["foo"]; !ok {
+		t.Fatalf("expected foo in first sink")
+	}
+	if _, ok := firstData[0].Counters["bar"]; ok {
+		t.Fatalf("did not expect bar in first sink")
+	}
+
+	secondData := second.Data()
+	if _, ok := secondData[0].Counters["bar"]; !ok {
+		t.Fatalf("expected bar in second sink")
+	}
+	if _, ok := secondData[0].Counters["foo"]; ok {
+		t.Fatalf("did not expect foo in second sink")
+	}
+
+	// An empty set of sinks should be safe to emit to
+	sink.Swap(nil)
+	sink.IncrCounter([]string{"baz"}, 1)
+}
@@ -0,0 +1,355 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	log "github.com/hashicorp/go-hclog"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// otlpBatchSize is the number of queued spans that triggers an export
+	// before the flush interval has elapsed
+	otlpBatchSize = 512
+
+	// otlpMaxQueued is the number of spans queued while the collector is
+	// unreachable; spans beyond it are dropped
+	otlpMaxQueued = 4096
+
+	otlpFlushInterval = 5 * time.Second
+	otlpTimeout       = 10 * time.Second
+)
+
+// OTLPExporterConfig is the configuration of an OTLPExporter
+type OTLPExporterConfig struct {
+	// Endpoint is the URL the spans are posted to
+	Endpoint string
+
+	// Headers are added to every export request
+	Headers map[string]string
+
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+
+	Logger log.Logger
+
+	// HTTPClient is used to send the spans. If nil, a client with a short
+	// timeout is used.
+	HTTPClient *http.Client
+}
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector using
+// OTLP over HTTP, with JSON encoding
+type OTLPExporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	logger      log.Logger
+	client      *http.Client
+
+	l       sync.Mutex
+	spans   []*trace.SpanData
+	dropped int
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+var _ trace.Exporter = (*OTLPExporter)(nil)
+
+// NewOTLPExporter returns an exporter sending spans to the configured
+// endpoint in the background. Stop must be called to send the remaining
+// spans and free its resources.
+func NewOTLPExporter(conf *OTLPExporterConfig) (*OTLPExporter, error) {
+	if conf.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+
+	e := &OTLPExporter{
+		endpoint:    conf.Endpoint,
+		headers:     conf.Headers,
+		serviceName: conf.ServiceName,
+		logger:      conf.Logger,
+		client:      conf.HTTPClient,
+		flushCh:     make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	if e.serviceName == "" {
+		e.serviceName = "vault"
+	}
+	if e.logger == nil {
+		e.logger = log.NewNullLogger()
+	}
+	if e.client == nil {
+		e.client = cleanhttp.DefaultClient()
+		e.client.Timeout = otlpTimeout
+	}
+
+	go e.run()
+
+	return e, nil
+}
+
+// ExportSpan queues a finished span to be sent to the collector
+func (e *OTLPExporter) ExportSpan(sd *trace.SpanData) {
+	e.l.Lock()
+	if len(e.spans) >= otlpMaxQueued {
+		e.dropped++
+		e.l.Unlock()
+		return
+	}
+	e.spans = append(e.spans, sd)
+	full := len(e.spans) >= otlpBatchSize
+	e.l.Unlock()
+
+	if full {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.doneCh)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flushCh:
+		case <-e.stopCh:
+			if err := e.Flush(); err != nil {
+				e.logger.Error("failed to export spans", "error", err)
+			}
+			return
+		}
+
+		if err := e.Flush(); err != nil {
+			e.logger.Error("failed to export spans", "error", err)
+		}
+	}
+}
+
+// Stop sends the queued spans and stops the exporter. Spans exported after
+// Stop returns are never sent.
+func (e *OTLPExporter) Stop() {
+	close(e.stopCh)
+	<-e.doneCh
+}
+
+// Flush sends the queued spans to the collector. Spans which fail to be sent
+// are dropped rather than retried.
+func (e *OTLPExporter) Flush() error {
+	e.l.Lock()
+	spans := e.spans
+	dropped := e.dropped
+	e.spans = nil
+	e.dropped = 0
+	e.l.Unlock()
+
+	if dropped > 0 {
+		e.logger.Warn("dropped spans because the export queue was full", "dropped", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.exportRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector returned status %d for %d spans", resp.StatusCode, len(spans))
+	}
+
+	return nil
+}
+
+// The following types are the JSON encoding of the OTLP trace export
+// request, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+
+	otlpStatusCodeError = 2
+)
+
+func (e *OTLPExporter) exportRequest(spans []*trace.SpanData) *otlpExportRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, sd := range spans {
+		otlpSpans = append(otlpSpans, otlpSpanFromSpanData(sd))
+	}
+
+	return &otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: otlpAttributes(map[string]interface{}{
+						"service.name": e.serviceName,
+					}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/hashicorp/vault"},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpSpanFromSpanData(sd *trace.SpanData) otlpSpan {
+	span := otlpSpan{
+		TraceID:           sd.TraceID.String(),
+		SpanID:            sd.SpanID.String(),
+		Name:              sd.Name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(sd.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(sd.EndTime.UnixNano(), 10),
+		Attributes:        otlpAttributes(sd.Attributes),
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		span.ParentSpanID = sd.ParentSpanID.String()
+	}
+
+	switch sd.SpanKind {
+	case trace.SpanKindServer:
+		span.Kind = otlpSpanKindServer
+	case trace.SpanKindClient:
+		span.Kind = otlpSpanKindClient
+	}
+
+	if sd.Code != trace.StatusCodeOK {
+		span.Status = &otlpStatus{
+			Code:    otlpStatusCodeError,
+			Message: sd.Message,
+		}
+	}
+
+	for _, a := range sd.Annotations {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: strconv.FormatInt(a.Time.UnixNano(), 10),
+			Name:         a.Message,
+			Attributes:   otlpAttributes(a.Attributes),
+		})
+	}
+
+	return span
+}
+
+func otlpAttributes(attributes map[string]interface{}) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]otlpKeyValue, 0, len(attributes))
+	for _, k := range keys {
+		var value map[string]interface{}
+		switch v := attributes[k].(type) {
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int64:
+			// 64 bit integers are encoded as strings in JSON
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprintf("%v", v)}
+		}
+		result = append(result, otlpKeyValue{Key: k, Value: value})
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var l sync.Mutex
+	var requests []map[string]interface{}
+	var authHeaders []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		var req map[string]interface{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+		}
+
+		l.Lock()
+		requests = append(requests, req)
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		l.Unlock()
+	}))
+	defer ts.Close()
+
+	exporter, err := NewOTLPExporter(&OTLPExporterConfig{
+		Endpoint:    ts.URL,
+		Headers:     map[string]string{"Authorization": "Bearer foo"},
+		ServiceName: "vault-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Enable(exporter, 1)
+	defer Disable(exporter)
+
+	r, err := http.NewRequest("GET", "http://127.0.0.1:8200/v1/secret/foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	ctx, serverSpan := StartServerSpan(context.Background(), r)
+	if serverSpan == nil {
+		t.Fatal("expected a server span")
+	}
+	_, childSpan := StartSpan(ctx, "child")
+	if childSpan == nil {
+		t.Fatal("expected a child span")
+	}
+	EndSpan(childSpan, errors.New("boom"))
+	serverSpan.End()
+
+	if _, span := StartSpan(context.Background(), "orphan"); span != nil {
+		t.Fatal("expected no span without a parent")
+	}
+
+	exporter.Stop()
+
+	l.Lock()
+	defer l.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 export request, got %d", len(requests))
+	}
+	if authHeaders[0] != "Bearer foo" {
+		t.Fatalf("bad authorization header: %q", authHeaders[0])
+	}
+
+	resourceSpans := requests[0]["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resourceAttrs := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})
+	serviceName := resourceAttrs[0].(map[string]interface{})
+	if serviceName["key"] != "service.name" || serviceName["value"].(map[string]interface{})["stringValue"] != "vault-test" {
+		t.Fatalf("bad resource attributes: %#v", resourceAttrs)
+	}
+
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	child := spans[0].(map[string]interface{})
+	server := spans[1].(map[string]interface{})
+	for _, span := range []map[string]interface{}{child, server} {
+		if span["traceId"] != "0af7651916cd43dd8448eb211c80319c" {
+			t.Fatalf("bad trace ID: %#v", span)
+		}
+	}
+	if server["parentSpanId"] != "b7ad6b7169203331" || server["kind"].(float64) != otlpSpanKindServer || server["name"] != "http GET" {
+		t.Fatalf("bad server span: %#v", server)
+	}
+	if child["parentSpanId"] != server["spanId"] || child["kind"].(float64) != otlpSpanKindInternal {
+		t.Fatalf("bad child span: %#v", child)
+	}
+	status := child["status"].(map[string]interface{})
+	if status["code"].(float64) != otlpStatusCodeError || status["message"] != "boom" {
+		t.Fatalf("bad child status: %#v", status)
+	}
+	if _, ok := server["status"]; ok {
+		t.Fatalf("expected no status on the server span: %#v", server)
+	}
+}
+
+func TestOTLPAttributes(t *testing.T) {
+	attrs := otlpAttributes(map[string]interface{}{
+		"d": "foo",
+		"c": int64(42),
+		"b": true,
+		"a": 1.5,
+	})
+
+	expected := []otlpKeyValue{
+		{Key: "a", Value: map[string]interface{}{"doubleValue": 1.5}},
+		{Key: "b", Value: map[string]interface{}{"boolValue": true}},
+		{Key: "c", Value: map[string]interface{}{"intValue": "42"}},
+		{Key: "d", Value: map[string]interface{}{"stringValue": "foo"}},
+	}
+
+	actual, _ := json.Marshal(attrs)
+	exp, _ := json.Marshal(expected)
+	if string(actual) != string(exp) {
+		t.Fatalf("expected %s, got %s", exp, actual)
+	}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"go.opencensus.io/trace"
+)
+
+// enabled is set once an exporter is registered. Until then no spans are
+// started, so that tracing costs nothing when it isn't configured.
+var enabled uint32
+
+// Enable registers the exporter spans are sent to and starts recording spans.
+// Requests whose caller didn't send a sampled trace context are traced at the
+// given rate.
+func Enable(exporter trace.Exporter, sampleRate float64) {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(sampleRate)})
+	trace.RegisterExporter(exporter)
+	atomic.StoreUint32(&enabled, 1)
+}
+
+// Disable stops recording spans and unregisters the exporter
+func Disable(exporter trace.Exporter) {
+	atomic.StoreUint32(&enabled, 0)
+	trace.UnregisterExporter(exporter)
+}
+
+// Enabled returns whether spans are being recorded
+func Enabled() bool {
+	return atomic.LoadUint32(&enabled) == 1
+}
+
+// StartServerSpan returns a context holding the trace context sent by the
+// client, if any, and starts the span covering the handling of the request.
+// The span is nil if tracing isn't enabled.
+func StartServerSpan(ctx context.Context, r *http.Request) (context.Context, *trace.Span) {
+	sc, hasParent := SpanContextFromRequest(r)
+	if hasParent {
+		ctx = ContextWithRemoteParent(ctx, sc)
+	}
+
+	if !Enabled() {
+		return ctx, nil
+	}
+
+	name := "http " + r.Method
+	var span *trace.Span
+	if hasParent {
+		ctx, span = trace.StartSpanWithRemoteParent(ctx, name, sc, trace.WithSpanKind(trace.SpanKindServer))
+	} else {
+		ctx, span = trace.StartSpan(ctx, name, trace.WithSpanKind(trace.SpanKindServer))
+	}
+	span.AddAttributes(
+		trace.StringAttribute("http.method", r.Method),
+		trace.StringAttribute("http.target", r.URL.Path),
+	)
+	return ctx, span
+}
+
+// StartSpan starts a child of the span held by the context. The span is nil,
+// and the context returned unchanged, if tracing isn't enabled or the context
+// doesn't hold a span. The methods of a nil span do nothing.
+func StartSpan(ctx context.Context, name string, attributes ...trace.Attribute) (context.Context, *trace.Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	if parent := trace.FromContext(ctx); parent == nil || !parent.IsRecordingEvents() {
+		return ctx, nil
+	}
+
+	ctx, span := trace.StartSpan(ctx, name)
+	span.AddAttributes(attributes...)
+	return ctx, span
+}
+
+// EndSpan records the error, if any, as the status of the span and ends it
+func EndSpan(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	span.End()
+}
@@ -0,0 +1,62 @@
+// Package tracing propagates the trace context of requests, so that the work
+// Vault does on behalf of a client can be joined to the client's traces.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+)
+
+type contextKeyRemoteParent struct{}
+
+// SpanContextFromRequest returns the span context given by the W3C
+// traceparent and tracestate headers of the request, if they are present and
+// valid
+func SpanContextFromRequest(r *http.Request) (trace.SpanContext, bool) {
+	var format tracecontext.HTTPFormat
+	return format.SpanContextFromRequest(r)
+}
+
+// ContextWithRemoteParent returns a context holding the span context a
+// client sent the request with
+func ContextWithRemoteParent(ctx context.Context, sc trace.SpanContext) context.Context {
+	return context.WithValue(ctx, contextKeyRemoteParent{}, sc)
+}
+
+// RemoteParentFromContext returns the span context the client sent the
+// request with, if any
+func RemoteParentFromContext(ctx context.Context) (trace.SpanContext, bool) {
+	sc, ok := ctx.Value(contextKeyRemoteParent{}).(trace.SpanContext)
+	return sc, ok
+}
+
+// CopyContext returns dst with the trace context of src, for when the work
+// for a request continues under a context which isn't derived from the
+// request's
+func CopyContext(dst, src context.Context) context.Context {
+	if sc, ok := RemoteParentFromContext(src); ok {
+		dst = ContextWithRemoteParent(dst, sc)
+	}
+	if span := trace.FromContext(src); span != nil {
+		dst = trace.NewContext(dst, span)
+	}
+	return dst
+}
+
+// TraceID returns the ID of the trace the context belongs to, or an empty
+// string if it doesn't belong to one
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if span := trace.FromContext(ctx); span != nil {
+		return span.SpanContext().TraceID.String()
+	}
+	if sc, ok := RemoteParentFromContext(ctx); ok {
+		return sc.TraceID.String()
+	}
+	return ""
+}
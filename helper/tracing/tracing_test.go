@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSpanContextFromRequest(t *testing.T) {
+	cases := map[string]bool{
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01": true,
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01": false,
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01": false,
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331":    false,
+		"banana": false,
+		"":       false,
+	}
+
+	for header, valid := range cases {
+		r, err := http.NewRequest("GET", "http://127.0.0.1:8200/v1/sys/health", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			r.Header.Set("traceparent", header)
+		}
+
+		sc, ok := SpanContextFromRequest(r)
+		if ok != valid {
+			t.Fatalf("%q: expected valid to be %t", header, valid)
+		}
+		if ok && sc.TraceID.String() != "0af7651916cd43dd8448eb211c80319c" {
+			t.Fatalf("%q: bad trace ID: %s", header, sc.TraceID)
+		}
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	ctx := context.Background()
+	if id := TraceID(ctx); id != "" {
+		t.Fatalf("bad: %q", id)
+	}
+
+	r, err := http.NewRequest("GET", "http://127.0.0.1:8200/v1/sys/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	sc, ok := SpanContextFromRequest(r)
+	if !ok {
+		t.Fatal("expected a span context")
+	}
+
+	ctx = ContextWithRemoteParent(ctx, sc)
+	if id := TraceID(ctx); id != "0af7651916cd43dd8448eb211c80319c" {
+		t.Fatalf("bad: %q", id)
+	}
+
+	ctx = CopyContext(context.Background(), ctx)
+	if id := TraceID(ctx); id != "0af7651916cd43dd8448eb211c80319c" {
+		t.Fatalf("bad: %q", id)
+	}
+}
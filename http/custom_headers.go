@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// wrapCustomResponseHeadersHandler adds the configured custom headers to
+// every response, including errors returned before a request reaches the
+// router. Headers for a specific status code take precedence over those for
+// its status class, which in turn take precedence over the defaults.
+func wrapCustomResponseHeadersHandler(h http.Handler, headers map[string]http.Header) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(&customHeadersResponseWriter{
+			ResponseWriter: w,
+			headers:        headers,
+		}, r)
+	})
+}
+
+type customHeadersResponseWriter struct {
+	http.ResponseWriter
+	headers     map[string]http.Header
+	wroteHeader bool
+}
+
+func (w *customHeadersResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setHeaders(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *customHeadersResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so that streamed responses are not buffered
+// by the wrapper
+func (w *customHeadersResponseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	flusher.Flush()
+}
+
+// Hijack implements http.Hijacker so that connections can be taken over
+// through the wrapper
+func (w *customHeadersResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *customHeadersResponseWriter) setHeaders(status int) {
+	code := strconv.Itoa(status)
+	headers := make(http.Header)
+	for _, key := range []string{"default", code[:1] + "xx", code} {
+		for name, values := range w.headers[key] {
+			headers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
+	// Configured headers replace those set by the handler, and each of
+	// their values is sent as a separate header line
+	for name, values := range headers {
+		w.Header().Del(name)
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+}
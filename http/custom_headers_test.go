@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestHandler_customResponseHeaders(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestListener(t)
+	defer ln.Close()
+	TestServerWithListenerAndProperties(t, ln, addr, core, &vault.HandlerProperties{
+		Core: core,
+		CustomResponseHeaders: map[string]http.Header{
+			"default": {
+				"Strict-Transport-Security": []string{"max-age=31536000"},
+				"Cache-Control":             []string{"no-cache"},
+			},
+			"4xx": {
+				"Content-Security-Policy": []string{"default-src 'none'"},
+			},
+			"404": {
+				"Cache-Control": []string{"max-age=60"},
+			},
+		},
+	})
+
+	cases := []struct {
+		path         string
+		status       int
+		cacheControl string
+		csp          string
+	}{
+		{"/v1/sys/mounts", http.StatusOK, "no-cache", ""},
+		{"/v1/secret/missing", http.StatusNotFound, "max-age=60", "default-src 'none'"},
+		{"/v1/sys/policy/missing", http.StatusNotFound, "max-age=60", "default-src 'none'"},
+		{"/invalid", http.StatusNotFound, "max-age=60", "default-src 'none'"},
+	}
+
+	for _, tc := range cases {
+		resp := testHttpGet(t, token, addr+tc.path)
+		testResponseStatus(t, resp, tc.status)
+
+		if v := resp.Header.Get("Strict-Transport-Security"); v != "max-age=31536000" {
+			t.Fatalf("%s: bad Strict-Transport-Security header: %q", tc.path, v)
+		}
+		if v := resp.Header.Get("Cache-Control"); v != tc.cacheControl {
+			t.Fatalf("%s: bad Cache-Control header: %q", tc.path, v)
+		}
+		if v := resp.Header.Get("Content-Security-Policy"); v != tc.csp {
+			t.Fatalf("%s: bad Content-Security-Policy header: %q", tc.path, v)
+		}
+	}
+}
+
+func TestCustomHeadersResponseWriter(t *testing.T) {
+	handler := wrapCustomResponseHeadersHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Fatal("response writer does not implement http.Hijacker")
+		}
+		w.(http.Flusher).Flush()
+	}), map[string]http.Header{
+		"default": {
+			"Content-Security-Policy": []string{"default-src 'none'", "frame-ancestors 'none'"},
+			"Cache-Control":           []string{"no-cache"},
+		},
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/sys/health", nil))
+	if !recorder.Flushed {
+		t.Fatal("response was not flushed")
+	}
+	if v := recorder.Header()["Content-Security-Policy"]; !reflect.DeepEqual(v, []string{"default-src 'none'", "frame-ancestors 'none'"}) {
+		t.Fatalf("bad Content-Security-Policy headers: %q", v)
+	}
+	if v := recorder.Header()["Cache-Control"]; !reflect.DeepEqual(v, []string{"no-cache"}) {
+		t.Fatalf("bad Cache-Control headers: %q", v)
+	}
+}
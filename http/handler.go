@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net"
 	"net/http"
 	"net/textproto"
@@ -21,13 +22,16 @@ import (
 	"github.com/hashicorp/errwrap"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	sockaddr "github.com/hashicorp/go-sockaddr"
+	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/helper/pathmanager"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/vault"
+	"go.opencensus.io/trace"
 )
 
 const (
@@ -108,9 +112,13 @@ func Handler(props *vault.HandlerProperties) http.Handler {
 	mux.Handle("/v1/sys/step-down", handleRequestForwarding(core, handleSysStepDown(core)))
 	mux.Handle("/v1/sys/unseal", handleSysUnseal(core))
 	mux.Handle("/v1/sys/leader", handleSysLeader(core))
+	mux.Handle("/v1/sys/ha-status", handleSysHAStatus(core))
 	mux.Handle("/v1/sys/health", handleSysHealth(core))
+	mux.Handle("/v1/sys/keyring/restore", handleSysKeyringRestore(core))
 	mux.Handle("/v1/sys/generate-root/attempt", handleRequestForwarding(core, handleSysGenerateRootAttempt(core, vault.GenerateStandardRootTokenStrategy)))
 	mux.Handle("/v1/sys/generate-root/update", handleRequestForwarding(core, handleSysGenerateRootUpdate(core, vault.GenerateStandardRootTokenStrategy)))
+	mux.Handle("/v1/sys/replication/dr/secondary/generate-operation-token/attempt", handleRequestForwarding(core, handleSysGenerateRootAttempt(core, vault.GenerateDROperationTokenStrategy)))
+	mux.Handle("/v1/sys/replication/dr/secondary/generate-operation-token/update", handleRequestForwarding(core, handleSysGenerateRootUpdate(core, vault.GenerateDROperationTokenStrategy)))
 	mux.Handle("/v1/sys/rekey/init", handleRequestForwarding(core, handleSysRekeyInit(core, false)))
 	mux.Handle("/v1/sys/rekey/update", handleRequestForwarding(core, handleSysRekeyUpdate(core, false)))
 	mux.Handle("/v1/sys/rekey/verify", handleRequestForwarding(core, handleSysRekeyVerify(core, false)))
@@ -120,6 +128,7 @@ func Handler(props *vault.HandlerProperties) http.Handler {
 	for _, path := range injectDataIntoTopRoutes {
 		mux.Handle(path, handleRequestForwarding(core, handleLogicalWithInjector(core)))
 	}
+	mux.Handle("/.well-known/", handleRequestForwarding(core, handleWellKnown(core, handleLogical(core))))
 	mux.Handle("/v1/sys/", handleRequestForwarding(core, handleLogical(core)))
 	mux.Handle("/v1/", handleRequestForwarding(core, handleLogical(core)))
 	if core.UIEnabled() == true {
@@ -148,6 +157,12 @@ func Handler(props *vault.HandlerProperties) http.Handler {
 		printablePathCheckHandler = cleanhttp.PrintablePathCheckHandler(genericWrappedHandler, nil)
 	}
 
+	// Wrap the handler to add any configured custom response headers, so
+	// that they are applied to all responses, including errors
+	if len(props.CustomResponseHeaders) > 0 {
+		return wrapCustomResponseHeadersHandler(printablePathCheckHandler, props.CustomResponseHeaders)
+	}
+
 	return printablePathCheckHandler
 }
 
@@ -173,6 +188,26 @@ func wrapGenericHandler(core *vault.Core, h http.Handler, maxRequestSize int64,
 			ctx = context.WithValue(ctx, "max_request_size", maxRequestSize)
 		}
 		ctx = context.WithValue(ctx, "original_request_path", r.URL.Path)
+
+		// Identify the request, so that its response can be matched to its
+		// audit entries
+		requestID, err := uuid.GenerateUUID()
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, errwrap.Wrapf("failed to generate identifier for the request: {{err}}", err))
+			cancelFunc()
+			return
+		}
+		w.Header().Set(consts.RequestIDHeaderName, requestID)
+		ctx = context.WithValue(ctx, "request_id", requestID)
+
+		// Join the trace of the client if it sent one
+		ctx, span := tracing.StartServerSpan(ctx, r)
+		if span != nil {
+			span.AddAttributes(trace.StringAttribute("vault.request_id", requestID))
+			w = &tracingResponseWriter{ResponseWriter: w, span: span}
+			defer span.End()
+		}
+
 		r = r.WithContext(ctx)
 
 		switch {
@@ -185,6 +220,11 @@ func wrapGenericHandler(core *vault.Core, h http.Handler, maxRequestSize int64,
 			}
 			r = newR
 
+		case strings.HasPrefix(r.URL.Path, "/.well-known/"):
+			// The URLs of well-known paths are fixed by the protocols using
+			// them, so they are always served from the root namespace
+			r = r.WithContext(namespace.ContextWithNamespace(r.Context(), namespace.RootNamespace))
+
 		case strings.HasPrefix(r.URL.Path, "/ui"), r.URL.Path == "/robots.txt", r.URL.Path == "/":
 		default:
 			respondError(w, http.StatusNotFound, nil)
@@ -468,7 +508,9 @@ func parseRequest(core *vault.Core, r *http.Request, w http.ResponseWriter, out
 		origBody = new(bytes.Buffer)
 		reader = ioutil.NopCloser(io.TeeReader(reader, origBody))
 	}
-	err := jsonutil.DecodeJSONFromReader(reader, out)
+	// The nesting depth of the request is bounded too, as decoding recurses
+	// once per level
+	err := jsonutil.DecodeJSONFromReaderWithOptions(reader, out, jsonutil.DecodeOptions{})
 	if err != nil && err != io.EOF {
 		return nil, errwrap.Wrapf("failed to parse JSON input: {{err}}", err)
 	}
@@ -831,6 +873,10 @@ func respondErrorCommon(w http.ResponseWriter, req *logical.Request, resp *logic
 		return false
 	}
 
+	if rlErr, ok := errwrap.GetType(err, new(vault.RateLimitedError)).(*vault.RateLimitedError); ok {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int64(math.Ceil(rlErr.RetryAfter.Seconds()))))
+	}
+
 	respondError(w, statusCode, newErr)
 	return true
 }
@@ -4,20 +4,29 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/audit"
+	auditFile "github.com/hashicorp/vault/builtin/audit/file"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/vault"
+	"go.opencensus.io/trace"
 )
 
 func TestHandler_parseMFAHandler(t *testing.T) {
@@ -241,6 +250,238 @@ func TestHandler_Accepted(t *testing.T) {
 	testResponseStatus(t, resp, 202)
 }
 
+func TestHandler_parseRequestDepth(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	for depth, code := range map[int]int{
+		10:                           204,
+		jsonutil.DefaultMaxDepth + 1: 400,
+	} {
+		body := `{"value":` + strings.Repeat("[", depth-1) + strings.Repeat("]", depth-1) + "}"
+		req, err := http.NewRequest("PUT", addr+"/v1/secret/foo", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		req.Header.Set(consts.AuthHeaderName, token)
+
+		client := cleanhttp.DefaultClient()
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		testResponseStatus(t, resp, code)
+	}
+}
+
+func TestHandler_RequestID(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	resp := testHttpGet(t, token, addr+"/v1/auth/token/lookup-self")
+	testResponseStatus(t, resp, 200)
+
+	requestID := resp.Header.Get(consts.RequestIDHeaderName)
+	if requestID == "" {
+		t.Fatal("missing request ID header")
+	}
+	var actual map[string]interface{}
+	testResponseBody(t, resp, &actual)
+	if actual["request_id"] != requestID {
+		t.Fatalf("expected request ID %q, got %q", requestID, actual["request_id"])
+	}
+
+	// Errors include the request ID too
+	resp = testHttpGet(t, "", addr+"/v1/auth/token/lookup-self")
+	testResponseStatus(t, resp, 400)
+
+	requestID = resp.Header.Get(consts.RequestIDHeaderName)
+	if requestID == "" {
+		t.Fatal("missing request ID header")
+	}
+	actual = nil
+	testResponseBody(t, resp, &actual)
+	if actual["request_id"] != requestID {
+		t.Fatalf("expected request ID %q, got %q", requestID, actual["request_id"])
+	}
+}
+
+func TestHandler_TraceParent(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		AuditBackends: map[string]audit.Factory{
+			"file": auditFile.Factory,
+		},
+	})
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	resp := testHttpPost(t, token, addr+"/v1/sys/audit/file", map[string]interface{}{
+		"type": "file",
+		"options": map[string]interface{}{
+			"file_path": f.Name(),
+		},
+	})
+	testResponseStatus(t, resp, 204)
+
+	req, err := http.NewRequest("GET", addr+"/v1/auth/token/lookup-self", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(consts.AuthHeaderName, token)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	resp, err = cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResponseStatus(t, resp, 200)
+	requestID := resp.Header.Get(consts.RequestIDHeaderName)
+
+	contents, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found int
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		var entry struct {
+			Request struct {
+				ID      string `json:"id"`
+				TraceID string `json:"trace_id"`
+			} `json:"request"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Request.ID != requestID {
+			continue
+		}
+		if entry.Request.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+			t.Fatalf("bad trace ID: %q", entry.Request.TraceID)
+		}
+		found++
+	}
+
+	// Both the request and the response are logged
+	if found != 2 {
+		t.Fatalf("expected 2 audit entries for request %q, found %d", requestID, found)
+	}
+}
+
+type recordingExporter struct {
+	l     sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(sd *trace.SpanData) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	e.spans = append(e.spans, sd)
+}
+
+func TestHandler_Tracing(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	exporter := &recordingExporter{}
+	tracing.Enable(exporter, 0)
+	defer tracing.Disable(exporter)
+
+	body := strings.NewReader(`{"foo": "bar"}`)
+	req, err := http.NewRequest("PUT", addr+"/v1/secret/foo", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(consts.AuthHeaderName, token)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	resp, err := cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResponseStatus(t, resp, 204)
+
+	// Other tests may run requests concurrently, so only the spans of our
+	// trace are considered
+	exporter.l.Lock()
+	defer exporter.l.Unlock()
+	names := make(map[string]*trace.SpanData)
+	for _, sd := range exporter.spans {
+		if sd.TraceID.String() == "0af7651916cd43dd8448eb211c80319c" {
+			names[sd.Name] = sd
+		}
+	}
+
+	for _, name := range []string{"http PUT", "router.route", "backend.handle_request", "barrier.put", "storage.put"} {
+		if _, ok := names[name]; !ok {
+			t.Fatalf("expected a %q span, got %v", name, names)
+		}
+	}
+	if names["http PUT"].Attributes["http.status_code"] != int64(204) {
+		t.Fatalf("bad attributes: %#v", names["http PUT"].Attributes)
+	}
+	if names["backend.handle_request"].Attributes["vault.mount_type"] != "kv" {
+		t.Fatalf("bad attributes: %#v", names["backend.handle_request"].Attributes)
+	}
+	if names["storage.put"].ParentSpanID != names["barrier.put"].SpanID {
+		t.Fatal("expected the storage span to be a child of the barrier span")
+	}
+}
+
+func TestHandler_tracingResponseWriter(t *testing.T) {
+	_, span := trace.StartSpan(context.Background(), "test")
+	defer span.End()
+
+	recorder := httptest.NewRecorder()
+	var w http.ResponseWriter = &tracingResponseWriter{
+		ResponseWriter: recorder,
+		span:           span,
+	}
+	if _, ok := w.(http.Hijacker); !ok {
+		t.Fatal("response writer does not implement http.Hijacker")
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatal("response writer does not implement http.Flusher")
+	}
+	flusher.Flush()
+	if !recorder.Flushed || recorder.Code != http.StatusOK {
+		t.Fatalf("response was not flushed: %d", recorder.Code)
+	}
+}
+
+func TestHandler_maxRequestDuration(t *testing.T) {
+	core, _, _ := vault.TestCoreUnsealed(t)
+
+	var ctxErr error
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Fatal("expected request context to have a deadline")
+		}
+		select {
+		case <-r.Context().Done():
+			ctxErr = r.Context().Err()
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	h := wrapGenericHandler(core, inner, 0, 50*time.Millisecond)
+	req := httptest.NewRequest("GET", "/v1/sys/health", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if ctxErr != context.DeadlineExceeded {
+		t.Fatalf("expected request context to time out, got: %v", ctxErr)
+	}
+}
+
 // We use this test to verify header auth
 func TestSysMounts_headerAuth(t *testing.T) {
 	core, _, token := vault.TestCoreUnsealed(t)
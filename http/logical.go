@@ -101,9 +101,15 @@ func buildLogicalRequest(core *vault.Core, w http.ResponseWriter, r *http.Reques
 		return nil, nil, http.StatusMethodNotAllowed, nil
 	}
 
-	request_id, err := uuid.GenerateUUID()
-	if err != nil {
-		return nil, nil, http.StatusBadRequest, errwrap.Wrapf("failed to generate identifier for the request: {{err}}", err)
+	// The request ID is generated when the request is received, but requests
+	// may be built without going through the generic handler
+	request_id, ok := r.Context().Value("request_id").(string)
+	if !ok {
+		var err error
+		request_id, err = uuid.GenerateUUID()
+		if err != nil {
+			return nil, nil, http.StatusBadRequest, errwrap.Wrapf("failed to generate identifier for the request: {{err}}", err)
+		}
 	}
 
 	req, err := requestAuth(core, r, &logical.Request{
@@ -16,6 +16,7 @@ import (
 	"github.com/go-test/deep"
 	log "github.com/hashicorp/go-hclog"
 
+	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/logging"
@@ -231,6 +232,59 @@ func TestLogical_CreateToken(t *testing.T) {
 	}
 }
 
+func TestLogical_LoginAliasMetadata(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": credUserpass.Factory,
+		},
+	})
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPost(t, token, addr+"/v1/sys/auth/userpass", map[string]interface{}{
+		"type": "userpass",
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpPost(t, token, addr+"/v1/auth/userpass/users/foo", map[string]interface{}{
+		"password": "bar",
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpPost(t, "", addr+"/v1/auth/userpass/login/foo", map[string]interface{}{
+		"password": "bar",
+	})
+	testResponseStatus(t, resp, 200)
+
+	var actual map[string]interface{}
+	testResponseBody(t, resp, &actual)
+	entityID := actual["auth"].(map[string]interface{})["entity_id"].(string)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/auth")
+	testResponseStatus(t, resp, 200)
+	actual = nil
+	testResponseBody(t, resp, &actual)
+	accessor := actual["data"].(map[string]interface{})["userpass/"].(map[string]interface{})["accessor"].(string)
+
+	resp = testHttpGet(t, token, addr+"/v1/identity/entity/id/"+entityID)
+	testResponseStatus(t, resp, 200)
+	actual = nil
+	testResponseBody(t, resp, &actual)
+	aliases := actual["data"].(map[string]interface{})["aliases"].([]interface{})
+	if len(aliases) != 1 {
+		t.Fatalf("bad: %#v", aliases)
+	}
+
+	expected := map[string]interface{}{
+		logical.AliasMetadataKeyMountAccessor: accessor,
+	}
+	metadata := aliases[0].(map[string]interface{})["metadata"]
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Fatalf("bad:\nexpected:\n%#v\nactual:\n%#v", expected, metadata)
+	}
+}
+
 func TestLogical_RawHTTP(t *testing.T) {
 	core, _, token := vault.TestCoreUnsealed(t)
 	ln, addr := TestServer(t, core)
@@ -3,8 +3,11 @@ package http
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/vault/audit"
+	auditFile "github.com/hashicorp/vault/builtin/audit/file"
 	"github.com/hashicorp/vault/vault"
 )
 
@@ -130,3 +133,44 @@ func TestSysAuditHash(t *testing.T) {
 		t.Fatalf("bad: expected:\n%#v\n, got:\n%#v\n", expected, actual)
 	}
 }
+
+func TestSysAuditHash_HMACType(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		AuditBackends: map[string]audit.Factory{
+			"file": auditFile.Factory,
+		},
+	})
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPost(t, token, addr+"/v1/sys/audit/invalid", map[string]interface{}{
+		"type": "file",
+		"options": map[string]interface{}{
+			"file_path": "discard",
+			"hmac_type": "hmac-md5",
+		},
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpPost(t, token, addr+"/v1/sys/audit/sha512", map[string]interface{}{
+		"type": "file",
+		"options": map[string]interface{}{
+			"file_path": "discard",
+			"hmac_type": "hmac-sha512",
+		},
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpPost(t, token, addr+"/v1/sys/audit-hash/sha512", map[string]interface{}{
+		"input": "bar",
+	})
+	testResponseStatus(t, resp, 200)
+
+	var actual map[string]interface{}
+	testResponseBody(t, resp, &actual)
+	hash := actual["data"].(map[string]interface{})["hash"].(string)
+	if !strings.HasPrefix(hash, "hmac-sha512:") || len(hash) != len("hmac-sha512:")+128 {
+		t.Fatalf("bad: %s", hash)
+	}
+}
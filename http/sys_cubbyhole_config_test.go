@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysCubbyholeConfig(t *testing.T) {
+	core, keys, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPut(t, token, addr+"/v1/sys/config/cubbyhole", map[string]interface{}{
+		"max_token_entries": -1,
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpPut(t, token, addr+"/v1/sys/config/cubbyhole", map[string]interface{}{
+		"max_token_entries": 1,
+		"max_entry_ttl":     "1h",
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpPut(t, token, addr+"/v1/cubbyhole/foo", map[string]interface{}{
+		"value": "bar",
+	})
+	testResponseStatus(t, resp, 204)
+	resp = testHttpPut(t, token, addr+"/v1/cubbyhole/bar", map[string]interface{}{
+		"value": "bar",
+	})
+	testResponseStatus(t, resp, 400)
+
+	// Response wrapping tokens aren't limited by the quota
+	req, err := http.NewRequest("POST", addr+"/v1/sys/wrapping/wrap", strings.NewReader(`{"value": "bar"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Wrap-TTL", "60")
+	resp, err = cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResponseStatus(t, resp, 200)
+
+	// The config survives a restart
+	if err := core.Seal(token); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, err := core.Unseal(vault.TestKeyCopy(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/config/cubbyhole")
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	data := actual["data"].(map[string]interface{})
+	if data["max_token_entries"] != json.Number("1") || data["max_token_bytes"] != json.Number("0") || data["max_entry_ttl"] != json.Number("3600") {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	resp = testHttpPut(t, token, addr+"/v1/cubbyhole/bar", map[string]interface{}{
+		"value": "bar",
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpDelete(t, token, addr+"/v1/sys/config/cubbyhole")
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpPut(t, token, addr+"/v1/cubbyhole/bar", map[string]interface{}{
+		"value": "bar",
+	})
+	testResponseStatus(t, resp, 204)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysFeatures(t *testing.T) {
+	if err := vault.RegisterFeatureFlag(&vault.FeatureFlag{
+		Name:        "test-sys-features",
+		Description: "A feature flag used by the sys/features tests.",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	core, keys, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	readFeature := func() map[string]interface{} {
+		t.Helper()
+		resp := testHttpGet(t, token, addr+"/v1/sys/features")
+		var actual map[string]interface{}
+		testResponseStatus(t, resp, 200)
+		testResponseBody(t, resp, &actual)
+		features := actual["data"].(map[string]interface{})["features"].(map[string]interface{})
+		feature, ok := features["test-sys-features"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("feature not listed: %#v", features)
+		}
+		return feature
+	}
+
+	feature := readFeature()
+	if feature["enabled"] != false || feature["default"] != false {
+		t.Fatalf("bad: %#v", feature)
+	}
+
+	resp := testHttpPut(t, token, addr+"/v1/sys/features/test-sys-features", map[string]interface{}{
+		"enabled": true,
+	})
+	testResponseStatus(t, resp, 204)
+	if !core.FeatureEnabled("test-sys-features") {
+		t.Fatal("expected feature to be enabled")
+	}
+
+	// Unknown features can't be toggled
+	resp = testHttpPut(t, token, addr+"/v1/sys/features/unknown", map[string]interface{}{
+		"enabled": true,
+	})
+	testResponseStatus(t, resp, 400)
+
+	// The override survives a seal/unseal cycle
+	if err := core.Seal(token); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, err := core.Unseal(vault.TestKeyCopy(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !core.FeatureEnabled("test-sys-features") {
+		t.Fatal("expected feature to stay enabled after unseal")
+	}
+	if feature := readFeature(); feature["enabled"] != true {
+		t.Fatalf("bad: %#v", feature)
+	}
+
+	resp = testHttpDelete(t, token, addr+"/v1/sys/features/test-sys-features")
+	testResponseStatus(t, resp, 204)
+	if feature := readFeature(); feature["enabled"] != false {
+		t.Fatalf("bad: %#v", feature)
+	}
+}
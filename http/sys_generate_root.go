@@ -16,7 +16,7 @@ func handleSysGenerateRootAttempt(core *vault.Core, generateStrategy vault.Gener
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case "GET":
-			handleSysGenerateRootAttemptGet(core, w, r, "")
+			handleSysGenerateRootAttemptGet(core, w, r, generateStrategy, "")
 		case "POST", "PUT":
 			handleSysGenerateRootAttemptPut(core, w, r, generateStrategy)
 		case "DELETE":
@@ -27,7 +27,7 @@ func handleSysGenerateRootAttempt(core *vault.Core, generateStrategy vault.Gener
 	})
 }
 
-func handleSysGenerateRootAttemptGet(core *vault.Core, w http.ResponseWriter, r *http.Request, otp string) {
+func handleSysGenerateRootAttemptGet(core *vault.Core, w http.ResponseWriter, r *http.Request, generateStrategy vault.GenerateRootStrategy, otp string) {
 	ctx, cancel := core.GetContext()
 	defer cancel()
 
@@ -65,19 +65,32 @@ func handleSysGenerateRootAttemptGet(core *vault.Core, w http.ResponseWriter, r
 		return
 	}
 
+	// Get the fingerprints of the shares provided so far
+	fingerprints, err := core.GenerateRootKeyFingerprints()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	// Format the status
 	status := &GenerateRootStatusResponse{
-		Started:   false,
-		Progress:  progress,
-		Required:  sealConfig.SecretThreshold,
-		Complete:  false,
-		OTPLength: vault.TokenLength + 2,
-		OTP:       otp,
+		Started:         false,
+		Progress:        progress,
+		Required:        sealConfig.SecretThreshold,
+		Complete:        false,
+		OTPLength:       vault.TokenLength + 2,
+		OTP:             otp,
+		OperationType:   generateRootOperationType(generateStrategy),
+		KeyFingerprints: fingerprints,
 	}
 	if generationConfig != nil {
 		status.Nonce = generationConfig.Nonce
 		status.Started = true
 		status.PGPFingerprint = generationConfig.PGPFingerprint
+
+		// Report the operation in progress, which may have been started
+		// through the other endpoint
+		status.OperationType = generateRootOperationType(generationConfig.Strategy)
 	}
 
 	respondOk(w, status)
@@ -112,11 +125,11 @@ func handleSysGenerateRootAttemptPut(core *vault.Core, w http.ResponseWriter, r
 	}
 
 	if genned {
-		handleSysGenerateRootAttemptGet(core, w, r, req.OTP)
+		handleSysGenerateRootAttemptGet(core, w, r, generateStrategy, req.OTP)
 		return
 	}
 
-	handleSysGenerateRootAttemptGet(core, w, r, "")
+	handleSysGenerateRootAttemptGet(core, w, r, generateStrategy, "")
 }
 
 func handleSysGenerateRootAttemptDelete(core *vault.Core, w http.ResponseWriter, r *http.Request) {
@@ -170,13 +183,15 @@ func handleSysGenerateRootUpdate(core *vault.Core, generateStrategy vault.Genera
 		}
 
 		resp := &GenerateRootStatusResponse{
-			Complete:       result.Progress == result.Required,
-			Nonce:          req.Nonce,
-			Progress:       result.Progress,
-			Required:       result.Required,
-			Started:        true,
-			EncodedToken:   result.EncodedToken,
-			PGPFingerprint: result.PGPFingerprint,
+			Complete:        result.Progress == result.Required,
+			Nonce:           req.Nonce,
+			Progress:        result.Progress,
+			Required:        result.Required,
+			Started:         true,
+			EncodedToken:    result.EncodedToken,
+			PGPFingerprint:  result.PGPFingerprint,
+			OperationType:   generateRootOperationType(generateStrategy),
+			KeyFingerprints: result.KeyFingerprints,
 		}
 
 		if generateStrategy == vault.GenerateStandardRootTokenStrategy {
@@ -187,22 +202,33 @@ func handleSysGenerateRootUpdate(core *vault.Core, generateStrategy vault.Genera
 	})
 }
 
+// generateRootOperationType returns the name of the kind of token the given
+// strategy generates
+func generateRootOperationType(generateStrategy vault.GenerateRootStrategy) string {
+	if generateStrategy == vault.GenerateDROperationTokenStrategy {
+		return "dr_operation_token"
+	}
+	return "root_token"
+}
+
 type GenerateRootInitRequest struct {
 	OTP    string `json:"otp"`
 	PGPKey string `json:"pgp_key"`
 }
 
 type GenerateRootStatusResponse struct {
-	Nonce            string `json:"nonce"`
-	Started          bool   `json:"started"`
-	Progress         int    `json:"progress"`
-	Required         int    `json:"required"`
-	Complete         bool   `json:"complete"`
-	EncodedToken     string `json:"encoded_token"`
-	EncodedRootToken string `json:"encoded_root_token"`
-	PGPFingerprint   string `json:"pgp_fingerprint"`
-	OTP              string `json:"otp"`
-	OTPLength        int    `json:"otp_length"`
+	Nonce            string   `json:"nonce"`
+	Started          bool     `json:"started"`
+	Progress         int      `json:"progress"`
+	Required         int      `json:"required"`
+	Complete         bool     `json:"complete"`
+	EncodedToken     string   `json:"encoded_token"`
+	EncodedRootToken string   `json:"encoded_root_token"`
+	PGPFingerprint   string   `json:"pgp_fingerprint"`
+	OTP              string   `json:"otp"`
+	OTPLength        int      `json:"otp_length"`
+	OperationType    string   `json:"operation_type"`
+	KeyFingerprints  []string `json:"key_fingerprints"`
 }
 
 type GenerateRootUpdateRequest struct {
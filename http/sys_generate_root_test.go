@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 	"github.com/hashicorp/vault/helper/pgpkeys"
@@ -37,6 +38,8 @@ func TestSysGenerateRootAttempt_Status(t *testing.T) {
 		"pgp_fingerprint":    "",
 		"nonce":              "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -65,6 +68,8 @@ func TestSysGenerateRootAttempt_Setup_OTP(t *testing.T) {
 		"encoded_root_token": "",
 		"pgp_fingerprint":    "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -90,6 +95,8 @@ func TestSysGenerateRootAttempt_Setup_OTP(t *testing.T) {
 		"pgp_fingerprint":    "",
 		"otp":                "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -126,6 +133,8 @@ func TestSysGenerateRootAttempt_Setup_PGP(t *testing.T) {
 		"pgp_fingerprint":    "816938b8a29146fbe245dd29e7cbaf8e011db793",
 		"otp":                "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -156,6 +165,8 @@ func TestSysGenerateRootAttempt_Cancel(t *testing.T) {
 		"encoded_root_token": "",
 		"pgp_fingerprint":    "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -188,6 +199,8 @@ func TestSysGenerateRootAttempt_Cancel(t *testing.T) {
 		"nonce":              "",
 		"otp":                "",
 		"otp_length":         json.Number("26"),
+		"operation_type":     "root_token",
+		"key_fingerprints":   []interface{}{},
 	}
 	testResponseStatus(t, resp, 200)
 	testResponseBody(t, resp, &actual)
@@ -241,7 +254,9 @@ func TestSysGenerateRoot_Update_OTP(t *testing.T) {
 
 	var actual map[string]interface{}
 	var expected map[string]interface{}
+	var fingerprints []interface{}
 	for i, key := range keys {
+		fingerprints = append(fingerprints, vault.KeyShareFingerprint(key))
 		resp = testHttpPut(t, token, addr+"/v1/sys/generate-root/update", map[string]interface{}{
 			"nonce": rootGenerationStatus["nonce"].(string),
 			"key":   hex.EncodeToString(key),
@@ -249,14 +264,16 @@ func TestSysGenerateRoot_Update_OTP(t *testing.T) {
 
 		actual = map[string]interface{}{}
 		expected = map[string]interface{}{
-			"complete":        false,
-			"nonce":           rootGenerationStatus["nonce"].(string),
-			"progress":        json.Number(fmt.Sprintf("%d", i+1)),
-			"required":        json.Number(fmt.Sprintf("%d", len(keys))),
-			"started":         true,
-			"pgp_fingerprint": "",
-			"otp":             "",
-			"otp_length":      json.Number("0"),
+			"complete":         false,
+			"nonce":            rootGenerationStatus["nonce"].(string),
+			"progress":         json.Number(fmt.Sprintf("%d", i+1)),
+			"required":         json.Number(fmt.Sprintf("%d", len(keys))),
+			"started":          true,
+			"pgp_fingerprint":  "",
+			"otp":              "",
+			"otp_length":       json.Number("0"),
+			"operation_type":   "root_token",
+			"key_fingerprints": fingerprints,
 		}
 		if i+1 == len(keys) {
 			expected["complete"] = true
@@ -341,7 +358,9 @@ func TestSysGenerateRoot_Update_PGP(t *testing.T) {
 
 	var actual map[string]interface{}
 	var expected map[string]interface{}
+	var fingerprints []interface{}
 	for i, key := range keys {
+		fingerprints = append(fingerprints, vault.KeyShareFingerprint(key))
 		resp = testHttpPut(t, token, addr+"/v1/sys/generate-root/update", map[string]interface{}{
 			"nonce": rootGenerationStatus["nonce"].(string),
 			"key":   hex.EncodeToString(key),
@@ -349,14 +368,16 @@ func TestSysGenerateRoot_Update_PGP(t *testing.T) {
 
 		actual = map[string]interface{}{}
 		expected = map[string]interface{}{
-			"complete":        false,
-			"nonce":           rootGenerationStatus["nonce"].(string),
-			"progress":        json.Number(fmt.Sprintf("%d", i+1)),
-			"required":        json.Number(fmt.Sprintf("%d", len(keys))),
-			"started":         true,
-			"pgp_fingerprint": "816938b8a29146fbe245dd29e7cbaf8e011db793",
-			"otp":             "",
-			"otp_length":      json.Number("0"),
+			"complete":         false,
+			"nonce":            rootGenerationStatus["nonce"].(string),
+			"progress":         json.Number(fmt.Sprintf("%d", i+1)),
+			"required":         json.Number(fmt.Sprintf("%d", len(keys))),
+			"started":          true,
+			"pgp_fingerprint":  "816938b8a29146fbe245dd29e7cbaf8e011db793",
+			"otp":              "",
+			"otp_length":       json.Number("0"),
+			"operation_type":   "root_token",
+			"key_fingerprints": fingerprints,
 		}
 		if i+1 == len(keys) {
 			expected["complete"] = true
@@ -417,3 +438,85 @@ func TestSysGenerateRoot_Update_PGP(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestSysGenerateDROperationToken_singleUse(t *testing.T) {
+	core, keys, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPut(t, token, addr+"/v1/sys/replication/dr/secondary/generate-operation-token/attempt", map[string]interface{}{})
+	var status map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &status)
+	if status["operation_type"] != "dr_operation_token" {
+		t.Fatalf("bad: %#v", status)
+	}
+	otp := status["otp"].(string)
+	if len(otp) != vault.TokenLength+2 {
+		t.Fatalf("bad otp length: %d", len(otp))
+	}
+
+	// Providing a share shows up as its fingerprint in the status
+	resp = testHttpPut(t, token, addr+"/v1/sys/replication/dr/secondary/generate-operation-token/update", map[string]interface{}{
+		"nonce": status["nonce"].(string),
+		"key":   hex.EncodeToString(keys[0]),
+	})
+	testResponseStatus(t, resp, 200)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/replication/dr/secondary/generate-operation-token/attempt")
+	status = map[string]interface{}{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &status)
+	if diff := deep.Equal(status["key_fingerprints"], []interface{}{vault.KeyShareFingerprint(keys[0])}); diff != nil {
+		t.Fatal(diff)
+	}
+
+	// The root generation endpoint reports the operation in progress
+	resp = testHttpGet(t, token, addr+"/v1/sys/generate-root/attempt")
+	rootStatus := map[string]interface{}{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &rootStatus)
+	if rootStatus["operation_type"] != "dr_operation_token" {
+		t.Fatalf("bad: %#v", rootStatus)
+	}
+
+	var actual map[string]interface{}
+	for _, key := range keys[1:] {
+		resp = testHttpPut(t, token, addr+"/v1/sys/replication/dr/secondary/generate-operation-token/update", map[string]interface{}{
+			"nonce": status["nonce"].(string),
+			"key":   hex.EncodeToString(key),
+		})
+		actual = map[string]interface{}{}
+		testResponseStatus(t, resp, 200)
+		testResponseBody(t, resp, &actual)
+	}
+	if actual["complete"] != true || actual["encoded_root_token"] != "" {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	tokenBytes, err := base64.RawStdEncoding.DecodeString(actual["encoded_token"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenBytes, err = xor.XORBytes(tokenBytes, []byte(otp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drToken := string(tokenBytes)
+
+	// The token can be used exactly once, and expires if it isn't
+	resp = testHttpGet(t, drToken, addr+"/v1/auth/token/lookup-self")
+	lookup := map[string]interface{}{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &lookup)
+	ttl, err := lookup["data"].(map[string]interface{})["ttl"].(json.Number).Int64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > int64((30*time.Minute).Seconds()) {
+		t.Fatalf("bad ttl: %d", ttl)
+	}
+	resp = testHttpGet(t, drToken, addr+"/v1/auth/token/lookup-self")
+	testResponseStatus(t, resp, 403)
+}
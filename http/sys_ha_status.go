@@ -0,0 +1,117 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/vault"
+)
+
+func handleSysHAStatus(core *vault.Core) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			handleSysHAStatusGet(core, w, r)
+		default:
+			respondError(w, http.StatusMethodNotAllowed, nil)
+		}
+	})
+}
+
+func handleSysHAStatusGet(core *vault.Core, w http.ResponseWriter, r *http.Request) {
+	if core.Sealed() {
+		respondError(w, http.StatusServiceUnavailable, consts.ErrSealed)
+		return
+	}
+
+	cluster, err := core.Cluster(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if cluster == nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("failed to fetch cluster details"))
+		return
+	}
+
+	haEnabled := true
+	isLeader, address, clusterAddr, err := core.Leader()
+	if errwrap.Contains(err, vault.ErrHANotEnabled.Error()) {
+		haEnabled = false
+		err = nil
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := &HAStatusResponse{
+		HAEnabled:            haEnabled,
+		ClusterName:          cluster.Name,
+		ClusterID:            cluster.ID,
+		IsSelf:               isLeader,
+		LeaderAddress:        address,
+		LeaderClusterAddress: clusterAddr,
+		Nodes:                []HAStatusNode{},
+	}
+	if isLeader || !haEnabled {
+		resp.LastWAL = vault.LastWAL(core)
+	}
+
+	if !haEnabled {
+		respondOk(w, resp)
+		return
+	}
+
+	// The active node is always listed first. Only the active node receives
+	// heartbeats, so a standby can report on itself and the active node but
+	// not its fellow standbys.
+	if clusterAddr != "" {
+		resp.Nodes = append(resp.Nodes, HAStatusNode{
+			ClusterAddress: clusterAddr,
+			APIAddress:     address,
+			ActiveNode:     true,
+		})
+	}
+	if isLeader {
+		for _, peer := range core.HAPeerNodes() {
+			node := HAStatusNode{
+				ClusterAddress: peer.ClusterAddress,
+			}
+			if !peer.LastEcho.IsZero() {
+				lastEcho := peer.LastEcho.UTC()
+				node.LastEcho = &lastEcho
+			}
+			resp.Nodes = append(resp.Nodes, node)
+		}
+	} else if selfAddr := core.ClusterAddr(); selfAddr != "" {
+		resp.Nodes = append(resp.Nodes, HAStatusNode{
+			ClusterAddress: selfAddr,
+			PerfStandby:    core.PerfStandby(),
+		})
+	}
+
+	respondOk(w, resp)
+}
+
+type HAStatusResponse struct {
+	HAEnabled            bool           `json:"ha_enabled"`
+	ClusterName          string         `json:"cluster_name"`
+	ClusterID            string         `json:"cluster_id"`
+	IsSelf               bool           `json:"is_self"`
+	LeaderAddress        string         `json:"leader_address"`
+	LeaderClusterAddress string         `json:"leader_cluster_address"`
+	LastWAL              uint64         `json:"last_wal,omitempty"`
+	Nodes                []HAStatusNode `json:"nodes"`
+}
+
+type HAStatusNode struct {
+	ClusterAddress string     `json:"cluster_address"`
+	APIAddress     string     `json:"api_address,omitempty"`
+	ActiveNode     bool       `json:"active_node"`
+	PerfStandby    bool       `json:"performance_standby,omitempty"`
+	LastEcho       *time.Time `json:"last_echo,omitempty"`
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysHAStatus_get(t *testing.T) {
+	core, _, _ := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	resp, err := http.Get(addr + "/v1/sys/ha-status")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+
+	if actual["ha_enabled"] != false {
+		t.Fatalf("expected HA to be disabled: %#v", actual)
+	}
+	if actual["cluster_name"] == "" || actual["cluster_id"] == "" {
+		t.Fatalf("expected cluster name and ID: %#v", actual)
+	}
+	if nodes, ok := actual["nodes"].([]interface{}); !ok || len(nodes) != 0 {
+		t.Fatalf("expected empty node list: %#v", actual)
+	}
+}
+
+func TestSysHAStatus_sealed(t *testing.T) {
+	core := vault.TestCore(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+
+	resp, err := http.Get(addr + "/v1/sys/ha-status")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	testResponseStatus(t, resp, 503)
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysHostInfo(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpGet(t, token, addr+"/v1/sys/host-info")
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+
+	data := actual["data"].(map[string]interface{})
+	if data["timestamp"] == "" {
+		t.Fatalf("missing timestamp: %#v", data)
+	}
+	if data["host"].(map[string]interface{})["os"] != runtime.GOOS {
+		t.Fatalf("bad host: %#v", data["host"])
+	}
+	if data["cpu"].(map[string]interface{})["count"] != json.Number(fmt.Sprintf("%d", runtime.NumCPU())) {
+		t.Fatalf("bad cpu: %#v", data["cpu"])
+	}
+	if runtime.GOOS == "linux" && (data["memory"] == nil || data["disk"] == nil) {
+		t.Fatalf("expected memory and disk information: %#v", data)
+	}
+
+	// The endpoint requires sudo
+	resp = testHttpPost(t, token, addr+"/v1/auth/token/create", map[string]interface{}{
+		"policies": []string{"default"},
+	})
+	testResponseStatus(t, resp, 200)
+	actual = map[string]interface{}{}
+	testResponseBody(t, resp, &actual)
+	nonRoot := actual["auth"].(map[string]interface{})["client_token"].(string)
+
+	resp = testHttpGet(t, nonRoot, addr+"/v1/sys/host-info")
+	testResponseStatus(t, resp, 403)
+}
@@ -24,7 +24,7 @@ func handleSysInit(core *vault.Core) http.Handler {
 }
 
 func handleSysInitGet(core *vault.Core, w http.ResponseWriter, r *http.Request) {
-	init, err := core.Initialized(context.Background())
+	init, err := core.Initialized(r.Context())
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err)
 		return
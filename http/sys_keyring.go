@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/vault"
+)
+
+// handleSysKeyringRestore restores a backup taken from sys/keyring/backup.
+// Like sys/init it is unauthenticated, as it is only allowed while Vault is
+// not yet initialized.
+func handleSysKeyringRestore(core *vault.Core) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT", "POST":
+			handleSysKeyringRestorePut(core, w, r)
+		default:
+			respondError(w, http.StatusMethodNotAllowed, nil)
+		}
+	})
+}
+
+func handleSysKeyringRestorePut(core *vault.Core, w http.ResponseWriter, r *http.Request) {
+	// Parse the request
+	var req KeyringRestoreRequest
+	if _, err := parseRequest(core, r, w, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Backup == "" {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("missing backup"))
+		return
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(req.Backup)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, errwrap.Wrapf("failed to decode backup: {{err}}", err))
+		return
+	}
+	var backup vault.KeyringBackup
+	if err := json.Unmarshal(buf, &backup); err != nil {
+		respondError(w, http.StatusBadRequest, errwrap.Wrapf("failed to decode backup: {{err}}", err))
+		return
+	}
+
+	if err := core.RestoreKeyring(r.Context(), &backup); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondOk(w, nil)
+}
+
+type KeyringRestoreRequest struct {
+	Backup string `json:"backup"`
+}
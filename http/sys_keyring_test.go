@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysKeyring_backupRestore(t *testing.T) {
+	core, keys, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPost(t, token, addr+"/v1/sys/rotate", nil)
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/keyring/backup")
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	data := actual["data"].(map[string]interface{})
+	if data["term"] != json.Number("2") {
+		t.Fatalf("bad: %#v", data)
+	}
+	backup := data["backup"].(string)
+
+	// Restoring over an initialized Vault is not allowed
+	resp = testHttpPut(t, "", addr+"/v1/sys/keyring/restore", map[string]interface{}{
+		"backup": backup,
+	})
+	testResponseStatus(t, resp, 400)
+
+	// Restore into a fresh storage backend and unseal with the original keys
+	core2 := vault.TestCore(t)
+	ln2, addr2 := TestServer(t, core2)
+	defer ln2.Close()
+
+	resp = testHttpPut(t, "", addr2+"/v1/sys/keyring/restore", map[string]interface{}{
+		"backup": backup,
+	})
+	testResponseStatus(t, resp, 204)
+
+	if init, err := core2.Initialized(context.Background()); err != nil || !init {
+		t.Fatalf("expected core to be initialized, err: %v", err)
+	}
+	if !core2.Sealed() {
+		t.Fatal("expected core to be sealed")
+	}
+	for _, key := range keys {
+		if _, err := vault.TestCoreUnseal(core2, vault.TestKeyCopy(key)); err != nil {
+			t.Fatalf("unseal err: %s", err)
+		}
+	}
+	if core2.Sealed() {
+		t.Fatal("expected core to be unsealed")
+	}
+
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	kv "github.com/hashicorp/vault-plugin-secrets-kv"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysKVWatch(t *testing.T) {
+	coreConfig := &vault.CoreConfig{
+		LogicalBackends: map[string]logical.Factory{
+			"kv": kv.Factory,
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	core := cluster.Cores[0].Core
+	vault.TestWaitActive(t, core)
+	client := cluster.Cores[0].Client
+
+	err := client.Sys().Mount("kv", &api.MountInput{
+		Type:    "kv",
+		Options: map[string]string{"version": "2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mount upgrades itself in the background before accepting writes
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, err = client.Logical().Write("kv/data/foo", map[string]interface{}{
+			"data": map[string]interface{}{"a": "b"},
+		})
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	watch := func(client *api.Client, version string) (bool, int64, error) {
+		secret, err := client.Logical().ReadWithData("sys/kv-watch", map[string][]string{
+			"path":    {"kv/data/foo"},
+			"version": {version},
+			"timeout": {"2s"},
+		})
+		if err != nil {
+			return false, 0, err
+		}
+		current, err := secret.Data["current_version"].(json.Number).Int64()
+		if err != nil {
+			return false, 0, err
+		}
+		return secret.Data["changed"].(bool), current, nil
+	}
+
+	// Nothing changes, the watch times out
+	start := time.Now()
+	changed, current, err := watch(client, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || current != 1 {
+		t.Fatalf("bad: changed %t, current version %d", changed, current)
+	}
+	if time.Since(start) < 2*time.Second {
+		t.Fatal("expected the watch to wait for the timeout")
+	}
+
+	// A new version wakes the watch up
+	type result struct {
+		changed bool
+		current int64
+		err     error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		changed, current, err := watch(client, "1")
+		resultCh <- result{changed, current, err}
+	}()
+	time.Sleep(500 * time.Millisecond)
+	_, err = client.Logical().Write("kv/data/foo", map[string]interface{}{
+		"data": map[string]interface{}{"a": "c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := <-resultCh
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if !res.changed || res.current != 2 {
+		t.Fatalf("bad: changed %t, current version %d", res.changed, res.current)
+	}
+
+	// A stale version returns at once
+	start = time.Now()
+	changed, current, err = watch(client, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || current != 2 {
+		t.Fatalf("bad: changed %t, current version %d", changed, current)
+	}
+	if time.Since(start) >= 2*time.Second {
+		t.Fatal("expected the watch to return at once")
+	}
+
+	// Only kv-v2 data paths can be watched
+	for _, path := range []string{"kv/metadata/foo", "secret/foo", "sys/mounts"} {
+		_, err = client.Logical().ReadWithData("sys/kv-watch", map[string][]string{
+			"path": {path},
+		})
+		if err == nil {
+			t.Fatalf("expected an error watching %q", path)
+		}
+	}
+
+	// The token must be able to read the secret
+	if err := client.Sys().PutPolicy("foo", `path "kv/data/foo" { capabilities = ["read"] }`); err != nil {
+		t.Fatal(err)
+	}
+	for policy, allowed := range map[string]bool{"default": false, "foo": true} {
+		secret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+			Policies: []string{policy},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokenClient, err := client.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokenClient.SetToken(secret.Auth.ClientToken)
+
+		_, _, err = watch(tokenClient, "1")
+		switch {
+		case allowed && err != nil:
+			t.Fatal(err)
+		case !allowed && (err == nil || !strings.Contains(err.Error(), "permission denied")):
+			t.Fatalf("expected permission to be denied, got: %v", err)
+		}
+	}
+}
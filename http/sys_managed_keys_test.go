@@ -0,0 +1,84 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+	"github.com/hashicorp/vault/vault/managedkeys"
+)
+
+func TestSysManagedKeys(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		ManagedKeyTypes: map[string]managedkeys.Factory{
+			"test": managedkeys.NewTestFactory(),
+		},
+	})
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPut(t, token, addr+"/v1/sys/managed-keys/ca", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "ca", "key_type": "ec", "pin": "1234"},
+		"allowed_mounts": "pki,/pki-int/",
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/managed-keys/ca")
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	// The PIN isn't returned
+	expected := map[string]interface{}{
+		"name":           "ca",
+		"type":           "test",
+		"config":         map[string]interface{}{"key_id": "ca", "key_type": "ec"},
+		"allowed_mounts": []interface{}{"pki-int/", "pki/"},
+	}
+	if !reflect.DeepEqual(actual["data"], expected) {
+		t.Fatalf("bad: expected %#v, got %#v", expected, actual["data"])
+	}
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/managed-keys?list=true")
+	actual = map[string]interface{}{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	keys := actual["data"].(map[string]interface{})["keys"]
+	if !reflect.DeepEqual(keys, []interface{}{"ca"}) {
+		t.Fatalf("bad: %#v", keys)
+	}
+
+	// Keys are checked when they are configured
+	resp = testHttpPut(t, token, addr+"/v1/sys/managed-keys/bad", map[string]interface{}{
+		"type":           "test",
+		"config":         map[string]interface{}{"key_type": "ec"},
+		"allowed_mounts": "pki/",
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpPut(t, token, addr+"/v1/sys/managed-keys/bad", map[string]interface{}{
+		"type":           "banana",
+		"config":         map[string]interface{}{"key_id": "bad"},
+		"allowed_mounts": "pki/",
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpPut(t, token, addr+"/v1/sys/managed-keys/bad", map[string]interface{}{
+		"type":   "test",
+		"config": map[string]interface{}{"key_id": "bad"},
+	})
+	testResponseStatus(t, resp, 400)
+
+	// The type of a key can't change
+	resp = testHttpPut(t, token, addr+"/v1/sys/managed-keys/ca", map[string]interface{}{
+		"type": "other",
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpDelete(t, token, addr+"/v1/sys/managed-keys/ca")
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/managed-keys/ca")
+	testResponseStatus(t, resp, 404)
+}
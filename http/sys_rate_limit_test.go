@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+func TestSysRateLimits(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp := testHttpPut(t, token, addr+"/v1/sys/config/rate-limits/secrets", map[string]interface{}{
+		"path":  "secret/",
+		"rate":  "0.01",
+		"burst": 2,
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/config/rate-limits/secrets")
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	data := actual["data"].(map[string]interface{})
+	if data["path"] != "secret/" || data["rate"] != json.Number("0.01") || data["burst"] != json.Number("2") {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	resp = testHttpPost(t, token, addr+"/v1/auth/token/create", map[string]interface{}{
+		"policies": []string{"root"},
+	})
+	testResponseStatus(t, resp, 200)
+	actual = map[string]interface{}{}
+	testResponseBody(t, resp, &actual)
+	other := actual["auth"].(map[string]interface{})["client_token"].(string)
+
+	// The burst is used up by the first client
+	for i := 0; i < 2; i++ {
+		resp = testHttpPut(t, token, addr+"/v1/secret/foo", map[string]interface{}{
+			"data": "bar",
+		})
+		testResponseStatus(t, resp, 204)
+	}
+	resp = testHttpGet(t, token, addr+"/v1/secret/foo")
+	testResponseStatus(t, resp, 429)
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+
+	// Other paths and other clients have their own budgets
+	resp = testHttpGet(t, token, addr+"/v1/sys/mounts")
+	testResponseStatus(t, resp, 200)
+	resp = testHttpGet(t, other, addr+"/v1/secret/foo")
+	testResponseStatus(t, resp, 200)
+
+	resp = testHttpGet(t, token, addr+"/v1/sys/config/rate-limits")
+	actual = map[string]interface{}{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	rules := actual["data"].(map[string]interface{})["rules"].(map[string]interface{})
+	if _, ok := rules["secrets"]; !ok || len(rules) != 1 {
+		t.Fatalf("bad: %#v", rules)
+	}
+
+	resp = testHttpPut(t, token, addr+"/v1/sys/config/rate-limits/bad", map[string]interface{}{
+		"rate": "0",
+	})
+	testResponseStatus(t, resp, 400)
+
+	resp = testHttpDelete(t, token, addr+"/v1/sys/config/rate-limits/secrets")
+	testResponseStatus(t, resp, 204)
+	resp = testHttpGet(t, token, addr+"/v1/secret/foo")
+	testResponseStatus(t, resp, 200)
+}
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
@@ -176,7 +175,7 @@ func handleSysSealStatus(core *vault.Core) http.Handler {
 }
 
 func handleSysSealStatusRaw(core *vault.Core, w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	sealed := core.Sealed()
 
@@ -202,8 +201,10 @@ func handleSysSealStatusRaw(core *vault.Core, w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Fetch the local cluster name and identifier
-	var clusterName, clusterID string
+	// Fetch the local cluster name and identifier, along with the address of
+	// the active node and, on the active node, the last WAL index
+	var clusterName, clusterID, leaderAddress string
+	var lastWAL uint64
 	if !sealed {
 		cluster, err := core.Cluster(ctx)
 		if err != nil {
@@ -216,39 +217,56 @@ func handleSysSealStatusRaw(core *vault.Core, w http.ResponseWriter, r *http.Req
 		}
 		clusterName = cluster.Name
 		clusterID = cluster.ID
+
+		// The seal status must be available even when the active node can't
+		// be looked up, so errors only leave these fields out
+		isLeader, address, _, err := core.Leader()
+		switch {
+		case errwrap.Contains(err, vault.ErrHANotEnabled.Error()):
+			lastWAL = vault.LastWAL(core)
+		case err == nil:
+			leaderAddress = address
+			if isLeader {
+				lastWAL = vault.LastWAL(core)
+			}
+		}
 	}
 
 	progress, nonce := core.SecretProgress()
 
 	respondOk(w, &SealStatusResponse{
-		Type:         sealConfig.Type,
-		Initialized:  true,
-		Sealed:       sealed,
-		T:            sealConfig.SecretThreshold,
-		N:            sealConfig.SecretShares,
-		Progress:     progress,
-		Nonce:        nonce,
-		Version:      version.GetVersion().VersionNumber(),
-		Migration:    core.IsInSealMigration(),
-		ClusterName:  clusterName,
-		ClusterID:    clusterID,
-		RecoverySeal: core.SealAccess().RecoveryKeySupported(),
+		Type:          sealConfig.Type,
+		Initialized:   true,
+		Sealed:        sealed,
+		T:             sealConfig.SecretThreshold,
+		N:             sealConfig.SecretShares,
+		Progress:      progress,
+		Nonce:         nonce,
+		Version:       version.GetVersion().VersionNumber(),
+		Migration:     core.IsInSealMigration(),
+		ClusterName:   clusterName,
+		ClusterID:     clusterID,
+		LeaderAddress: leaderAddress,
+		LastWAL:       lastWAL,
+		RecoverySeal:  core.SealAccess().RecoveryKeySupported(),
 	})
 }
 
 type SealStatusResponse struct {
-	Type         string `json:"type"`
-	Initialized  bool   `json:"initialized"`
-	Sealed       bool   `json:"sealed"`
-	T            int    `json:"t"`
-	N            int    `json:"n"`
-	Progress     int    `json:"progress"`
-	Nonce        string `json:"nonce"`
-	Version      string `json:"version"`
-	Migration    bool   `json:"migration"`
-	ClusterName  string `json:"cluster_name,omitempty"`
-	ClusterID    string `json:"cluster_id,omitempty"`
-	RecoverySeal bool   `json:"recovery_seal"`
+	Type          string `json:"type"`
+	Initialized   bool   `json:"initialized"`
+	Sealed        bool   `json:"sealed"`
+	T             int    `json:"t"`
+	N             int    `json:"n"`
+	Progress      int    `json:"progress"`
+	Nonce         string `json:"nonce"`
+	Version       string `json:"version"`
+	Migration     bool   `json:"migration"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+	ClusterID     string `json:"cluster_id,omitempty"`
+	LeaderAddress string `json:"leader_address,omitempty"`
+	LastWAL       uint64 `json:"last_wal,omitempty"`
+	RecoverySeal  bool   `json:"recovery_seal"`
 }
 
 // Note: because we didn't provide explicit tagging in the past we can't do it
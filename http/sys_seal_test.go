@@ -388,3 +388,24 @@ func TestSysStepDown(t *testing.T) {
 	resp := testHttpPut(t, token, addr+"/v1/sys/step-down", nil)
 	testResponseStatus(t, resp, 204)
 }
+
+func TestSysSealStatus_leader(t *testing.T) {
+	cluster := vault.NewTestCluster(t, &vault.CoreConfig{}, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	core := cluster.Cores[0].Core
+	vault.TestWaitActive(t, core)
+
+	for i, c := range cluster.Cores {
+		status, err := c.Client.Sys().SealStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.LeaderAddress != cluster.Cores[0].Client.Address() {
+			t.Fatalf("core %d: bad leader address %q", i, status.LeaderAddress)
+		}
+	}
+}
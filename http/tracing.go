@@ -0,0 +1,62 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.opencensus.io/trace"
+)
+
+// tracingResponseWriter records the status code of the response in the span
+// of the request
+type tracingResponseWriter struct {
+	http.ResponseWriter
+	span        *trace.Span
+	wroteHeader bool
+}
+
+func (w *tracingResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.span.AddAttributes(trace.Int64Attribute("http.status_code", int64(status)))
+		if status >= 500 {
+			w.span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeUnknown,
+				Message: http.StatusText(status),
+			})
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *tracingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so that streamed responses are not buffered
+// by the wrapper
+func (w *tracingResponseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	flusher.Flush()
+}
+
+// Hijack implements http.Hijacker so that connections can be taken over
+// through the wrapper
+func (w *tracingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
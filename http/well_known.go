@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/vault/vault"
+)
+
+// handleWellKnown routes requests under /.well-known/ to the mount that has
+// claimed the requested label, as if they had been made to that mount's API
+// path directly.
+func handleWellKnown(core *vault.Core, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/.well-known/")
+		target, ok := core.WellKnownRedirect(path)
+		if !ok {
+			respondError(w, http.StatusNotFound, nil)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = "/v1/" + target
+		r2.URL.RawPath = ""
+		h.ServeHTTP(w, r2)
+	})
+}
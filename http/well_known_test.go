@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+)
+
+func testWellKnownBackendFactory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := &framework.Backend{
+		BackendType: logical.TypeLogical,
+		PathsSpecial: &logical.Paths{
+			Unauthenticated: []string{"discovery/*"},
+			WellKnown: map[string]string{
+				"test": "discovery",
+			},
+		},
+		Paths: []*framework.Path{
+			{
+				Pattern: "discovery/" + framework.GenericNameRegex("name"),
+				Fields: map[string]*framework.FieldSchema{
+					"name": {Type: framework.TypeString},
+				},
+				Callbacks: map[logical.Operation]framework.OperationFunc{
+					logical.ReadOperation: func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+						return &logical.Response{
+							Data: map[string]interface{}{
+								"name": data.Get("name"),
+							},
+						}, nil
+					},
+				},
+			},
+		},
+	}
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func TestHandler_wellKnown(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealedWithConfig(t, &vault.CoreConfig{
+		LogicalBackends: map[string]logical.Factory{
+			"wellknown": testWellKnownBackendFactory,
+		},
+	})
+	ln, addr := TestServer(t, core)
+	defer ln.Close()
+	TestServerAuth(t, addr, token)
+
+	resp, err := http.Get(addr + "/.well-known/test/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResponseStatus(t, resp, 404)
+
+	resp = testHttpPost(t, token, addr+"/v1/sys/mounts/discover", map[string]interface{}{
+		"type": "wellknown",
+	})
+	testResponseStatus(t, resp, 204)
+
+	resp, err = http.Get(addr + "/.well-known/test/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual map[string]interface{}
+	testResponseStatus(t, resp, 200)
+	testResponseBody(t, resp, &actual)
+	if actual["data"].(map[string]interface{})["name"] != "foo" {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	resp = testHttpDelete(t, token, addr+"/v1/sys/mounts/discover")
+	testResponseStatus(t, resp, 204)
+
+	resp, err = http.Get(addr + "/.well-known/test/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResponseStatus(t, resp, 404)
+}
@@ -2,9 +2,13 @@ package azure
 
 import (
 	"context"
+	"crypto/md5"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"sort"
 	"strconv"
@@ -12,11 +16,13 @@ import (
 	"time"
 
 	storage "github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/errwrap"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/physical"
 )
@@ -26,8 +32,69 @@ const (
 	MaxBlobSize = 1024 * 1024 * 4
 	// MaxListResults is the current default value, setting explicitly
 	MaxListResults = 5000
+	// DefaultMaxRetries matches the number of retries of the Azure SDK
+	DefaultMaxRetries = 4
+	// DefaultRetryDuration is the initial delay between retries, which
+	// doubles with each attempt
+	DefaultRetryDuration = 1 * time.Second
 )
 
+// retryStatusCodes are the responses on which a request is retried
+var retryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retrySender sends requests with exponential backoff between attempts. The
+// default sender of the Azure SDK only retries on the status code of a
+// response, so it gives up on the network errors and timeouts it's most
+// needed for.
+type retrySender struct {
+	attempts int
+	duration time.Duration
+}
+
+func (s *retrySender) Send(c *storage.Client, req *http.Request) (resp *http.Response, err error) {
+	rr := autorest.NewRetriableRequest(req)
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		if attempt > 0 {
+			if !autorest.DelayForBackoff(s.duration, attempt-1, req.Context().Done()) {
+				return nil, req.Context().Err()
+			}
+		}
+
+		if err := rr.Prepare(); err != nil {
+			return nil, err
+		}
+		resp, err = c.HTTPClient.Do(rr.Request())
+		if !shouldRetry(req, resp, err) || attempt == s.attempts-1 {
+			break
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
+
+// shouldRetry returns whether a request failed with a network error, a
+// timeout, or a retryable status code
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		_, ok := err.(net.Error)
+		return ok
+	}
+	return autorest.ResponseHasStatusCode(resp, retryStatusCodes...)
+}
+
 // AzureBackend is a physical backend that stores data
 // within an Azure blob container.
 type AzureBackend struct {
@@ -87,6 +154,30 @@ func NewAzureBackend(conf map[string]string, logger log.Logger) (physical.Backen
 	}
 	client.HTTPClient = cleanhttp.DefaultPooledClient()
 
+	if timeoutStr, ok := conf["request_timeout"]; ok {
+		timeout, err := parseutil.ParseDurationSecond(timeoutStr)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed parsing request_timeout parameter: {{err}}", err)
+		}
+		client.HTTPClient.Timeout = timeout
+	}
+
+	maxRetries := DefaultMaxRetries
+	if maxRetriesStr, ok := conf["max_retries"]; ok {
+		maxRetries, err = strconv.Atoi(maxRetriesStr)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed parsing max_retries parameter: {{err}}", err)
+		}
+		if maxRetries < 0 {
+			return nil, fmt.Errorf("max_retries cannot be negative")
+		}
+	}
+
+	client.Sender = &retrySender{
+		attempts: maxRetries + 1,
+		duration: DefaultRetryDuration,
+	}
+
 	blobClient := client.GetBlobService()
 	container := blobClient.GetContainerReference(name)
 	_, err = container.CreateIfNotExists(&storage.CreateContainerOptions{
@@ -131,11 +222,21 @@ func (a *AzureBackend) Put(ctx context.Context, entry *physical.Entry) error {
 	a.permitPool.Acquire()
 	defer a.permitPool.Release()
 
+	// Store the MD5 of the content so that it can be verified on reads, and
+	// have the service verify the block on upload
+	sum := md5.Sum(entry.Value)
+	contentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+
 	blob := &storage.Blob{
 		Container: a.container,
 		Name:      entry.Key,
+		Properties: storage.BlobProperties{
+			ContentMD5: contentMD5,
+		},
 	}
-	if err := blob.PutBlock(blockID, entry.Value, nil); err != nil {
+	if err := blob.PutBlock(blockID, entry.Value, &storage.PutBlockOptions{
+		ContentMD5: contentMD5,
+	}); err != nil {
 		return err
 	}
 
@@ -167,13 +268,24 @@ func (a *AzureBackend) Get(ctx context.Context, key string) (*physical.Entry, er
 	}
 	defer reader.Close()
 	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blobs written before content MD5s were stored have none to verify
+	if blob.Properties.ContentMD5 != "" {
+		sum := md5.Sum(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != blob.Properties.ContentMD5 {
+			return nil, fmt.Errorf("content MD5 mismatch for %q", key)
+		}
+	}
 
 	ent := &physical.Entry{
 		Key:   key,
 		Value: data,
 	}
 
-	return ent, err
+	return ent, nil
 }
 
 // Delete is used to permanently delete an entry
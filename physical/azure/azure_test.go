@@ -3,8 +3,13 @@ package azure
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -123,3 +128,68 @@ func TestAzureBackend_ListPaging(t *testing.T) {
 		t.Fatalf("expected %d, got %d", MaxListResults+100, len(results))
 	}
 }
+
+func TestAzureBackend_retrySender(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			// Drop the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+		case 2:
+			// Outlast the client timeout
+			time.Sleep(200 * time.Millisecond)
+		case 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Write(body)
+		}
+	}))
+	defer ts.Close()
+
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = 100 * time.Millisecond
+	client := &storage.Client{HTTPClient: httpClient}
+	sender := &retrySender{attempts: 4, duration: time.Millisecond}
+
+	req, err := http.NewRequest("PUT", ts.URL, strings.NewReader("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := sender.Send(client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "foo" {
+		t.Fatalf("bad response: %d %q", resp.StatusCode, body)
+	}
+	if n := atomic.LoadInt32(&requests); n != 4 {
+		t.Fatalf("expected 4 requests, got %d", n)
+	}
+
+	// The last response is returned once the retries are exhausted
+	atomic.StoreInt32(&requests, 2)
+	sender.attempts = 1
+	req, err = http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = sender.Send(client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last response to be returned, got %d", resp.StatusCode)
+	}
+}
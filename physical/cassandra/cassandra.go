@@ -24,6 +24,9 @@ type CassandraBackend struct {
 	sess  *gocql.Session
 	table string
 
+	readConsistency  gocql.Consistency
+	writeConsistency gocql.Consistency
+
 	logger log.Logger
 }
 
@@ -75,27 +78,27 @@ func NewCassandraBackend(conf map[string]string, logger log.Logger) (physical.Ba
 		table = "entries"
 	}
 	if cs, ok := conf["consistency"]; ok {
-		switch cs {
-		case "ANY":
-			consistency = gocql.Any
-		case "ONE":
-			consistency = gocql.One
-		case "TWO":
-			consistency = gocql.Two
-		case "THREE":
-			consistency = gocql.Three
-		case "QUORUM":
-			consistency = gocql.Quorum
-		case "ALL":
-			consistency = gocql.All
-		case "LOCAL_QUORUM":
-			consistency = gocql.LocalQuorum
-		case "EACH_QUORUM":
-			consistency = gocql.EachQuorum
-		case "LOCAL_ONE":
-			consistency = gocql.LocalOne
-		default:
-			return nil, fmt.Errorf("'consistency' must be one of {ANY, ONE, TWO, THREE, QUORUM, ALL, LOCAL_QUORUM, EACH_QUORUM, LOCAL_ONE}")
+		var err error
+		consistency, err = parseConsistency("consistency", cs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Reads and writes default to the general consistency level
+	readConsistency, writeConsistency := consistency, consistency
+	if cs, ok := conf["read_consistency"]; ok {
+		var err error
+		readConsistency, err = parseConsistency("read_consistency", cs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cs, ok := conf["write_consistency"]; ok {
+		var err error
+		writeConsistency, err = parseConsistency("write_consistency", cs)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -144,13 +147,41 @@ func NewCassandraBackend(conf map[string]string, logger log.Logger) (physical.Ba
 	sess.SetConsistency(consistency)
 
 	impl := &CassandraBackend{
-		sess:   sess,
-		table:  table,
-		logger: logger,
+		sess:             sess,
+		table:            table,
+		readConsistency:  readConsistency,
+		writeConsistency: writeConsistency,
+		logger:           logger,
 	}
 	return impl, nil
 }
 
+// parseConsistency parses the consistency level set by the given parameter
+func parseConsistency(param, cs string) (gocql.Consistency, error) {
+	switch cs {
+	case "ANY":
+		return gocql.Any, nil
+	case "ONE":
+		return gocql.One, nil
+	case "TWO":
+		return gocql.Two, nil
+	case "THREE":
+		return gocql.Three, nil
+	case "QUORUM":
+		return gocql.Quorum, nil
+	case "ALL":
+		return gocql.All, nil
+	case "LOCAL_QUORUM":
+		return gocql.LocalQuorum, nil
+	case "EACH_QUORUM":
+		return gocql.EachQuorum, nil
+	case "LOCAL_ONE":
+		return gocql.LocalOne, nil
+	default:
+		return 0, fmt.Errorf("'%s' must be one of {ANY, ONE, TWO, THREE, QUORUM, ALL, LOCAL_QUORUM, EACH_QUORUM, LOCAL_ONE}", param)
+	}
+}
+
 func setupCassandraTLS(conf map[string]string, cluster *gocql.ClusterConfig) error {
 	tlsOnStr, ok := conf["tls"]
 	if !ok {
@@ -260,7 +291,7 @@ func (c *CassandraBackend) Put(ctx context.Context, entry *physical.Entry) error
 	buckets := c.buckets(entry.Key)
 	for _, _bucket := range buckets {
 		go func(bucket string) {
-			results <- c.sess.Query(stmt, bucket, entry.Key, entry.Value).Exec()
+			results <- c.sess.Query(stmt, bucket, entry.Key, entry.Value).Consistency(c.writeConsistency).Exec()
 		}(_bucket)
 	}
 	for i := 0; i < len(buckets); i++ {
@@ -277,7 +308,7 @@ func (c *CassandraBackend) Get(ctx context.Context, key string) (*physical.Entry
 
 	v := []byte(nil)
 	stmt := fmt.Sprintf(`SELECT value FROM "%s" WHERE bucket = ? AND key = ? LIMIT 1`, c.table)
-	q := c.sess.Query(stmt, c.bucket(key), key)
+	q := c.sess.Query(stmt, c.bucket(key), key).Consistency(c.readConsistency)
 	if err := q.Scan(&v); err != nil {
 		if err == gocql.ErrNotFound {
 			return nil, nil
@@ -301,7 +332,7 @@ func (c *CassandraBackend) Delete(ctx context.Context, key string) error {
 
 	for _, bucket := range buckets {
 		go func(bucket string) {
-			results <- c.sess.Query(stmt, bucket, key).Exec()
+			results <- c.sess.Query(stmt, bucket, key).Consistency(c.writeConsistency).Exec()
 		}(bucket)
 	}
 
@@ -319,7 +350,7 @@ func (c *CassandraBackend) List(ctx context.Context, prefix string) ([]string, e
 	defer metrics.MeasureSince([]string{"cassandra", "list"}, time.Now())
 
 	stmt := fmt.Sprintf(`SELECT key FROM "%s" WHERE bucket = ?`, c.table)
-	q := c.sess.Query(stmt, c.bucketName(prefix))
+	q := c.sess.Query(stmt, c.bucketName(prefix)).Consistency(c.readConsistency)
 	iter := q.Iter()
 	k, keys := "", []string{}
 	for iter.Scan(&k) {
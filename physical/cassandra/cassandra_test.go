@@ -39,6 +39,35 @@ func TestCassandraBackend(t *testing.T) {
 	physical.ExerciseBackend_ListPrefix(t, b)
 }
 
+func TestCassandraBackend_ReadWriteConsistency(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping in short mode")
+	}
+
+	cleanup, hosts := prepareCassandraTestContainer(t)
+	defer cleanup()
+
+	logger := logging.NewVaultLogger(log.Debug)
+	b, err := NewCassandraBackend(map[string]string{
+		"hosts":             hosts,
+		"protocol_version":  "3",
+		"read_consistency":  "ONE",
+		"write_consistency": "QUORUM",
+	}, logger)
+
+	if err != nil {
+		t.Fatalf("Failed to create new backend: %v", err)
+	}
+
+	c := b.(*CassandraBackend)
+	if c.readConsistency != gocql.One || c.writeConsistency != gocql.Quorum {
+		t.Fatalf("bad: read consistency %v, write consistency %v", c.readConsistency, c.writeConsistency)
+	}
+
+	physical.ExerciseBackend(t, b)
+	physical.ExerciseBackend_ListPrefix(t, b)
+}
+
 func TestCassandraBackendBuckets(t *testing.T) {
 	expectations := map[string][]string{
 		"":          {"."},
@@ -55,6 +84,28 @@ func TestCassandraBackendBuckets(t *testing.T) {
 	}
 }
 
+func TestCassandraBackendConsistency(t *testing.T) {
+	expectations := map[string]gocql.Consistency{
+		"ONE":          gocql.One,
+		"QUORUM":       gocql.Quorum,
+		"LOCAL_QUORUM": gocql.LocalQuorum,
+		"LOCAL_ONE":    gocql.LocalOne,
+	}
+	for input, expected := range expectations {
+		actual, err := parseConsistency("consistency", input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf("bad: %v expected: %v", actual, expected)
+		}
+	}
+
+	if _, err := parseConsistency("read_consistency", "local_quorum"); err == nil {
+		t.Fatal("expected error for invalid consistency")
+	}
+}
+
 func prepareCassandraTestContainer(t *testing.T) (func(), string) {
 	if os.Getenv("CASSANDRA_HOSTS") != "" {
 		return func() {}, os.Getenv("CASSANDRA_HOSTS")
@@ -63,6 +63,14 @@ const (
 	// consistencyModeStrong is the configuration value used to tell
 	// consul to use strong consistency.
 	consistencyModeStrong = "strong"
+
+	// consistencyModeStale is the configuration value used to tell
+	// consul to allow stale reads from any server.
+	consistencyModeStale = "stale"
+
+	// maxTxnOps is the maximum number of operations in a Consul
+	// transaction.
+	maxTxnOps = 64
 )
 
 type notifyEvent struct{}
@@ -96,6 +104,8 @@ type ConsulBackend struct {
 	disableRegistration bool
 	checkTimeout        time.Duration
 	consistencyMode     string
+	getConsistencyMode  string
+	listConsistencyMode string
 
 	notifyActiveCh      chan notifyEvent
 	notifySealedCh      chan notifyEvent
@@ -267,15 +277,22 @@ func NewConsulBackend(conf map[string]string, logger log.Logger) (physical.Backe
 		}
 	}
 
-	consistencyMode, ok := conf["consistency_mode"]
-	if ok {
-		switch consistencyMode {
-		case consistencyModeDefault, consistencyModeStrong:
-		default:
-			return nil, fmt.Errorf("invalid consistency_mode value: %q", consistencyMode)
-		}
-	} else {
-		consistencyMode = consistencyModeDefault
+	// The consistency mode of each read operation defaults to the one of all
+	// of them
+	consistencyMode, err := parseConsistencyMode(conf, "consistency_mode", consistencyModeDefault)
+	if err != nil {
+		return nil, err
+	}
+	getConsistencyMode, err := parseConsistencyMode(conf, "get_consistency_mode", consistencyMode)
+	if err != nil {
+		return nil, err
+	}
+	listConsistencyMode, err := parseConsistencyMode(conf, "list_consistency_mode", consistencyMode)
+	if err != nil {
+		return nil, err
+	}
+	if logger.IsDebug() {
+		logger.Debug("config consistency modes set", "get", getConsistencyMode, "list", listConsistencyMode)
 	}
 
 	// Setup the backend
@@ -291,6 +308,8 @@ func NewConsulBackend(conf map[string]string, logger log.Logger) (physical.Backe
 		checkTimeout:        checkTimeout,
 		disableRegistration: disableRegistration,
 		consistencyMode:     consistencyMode,
+		getConsistencyMode:  getConsistencyMode,
+		listConsistencyMode: listConsistencyMode,
 		notifyActiveCh:      make(chan notifyEvent),
 		notifySealedCh:      make(chan notifyEvent),
 		notifyPerfStandbyCh: make(chan notifyEvent),
@@ -300,6 +319,48 @@ func NewConsulBackend(conf map[string]string, logger log.Logger) (physical.Backe
 	return c, nil
 }
 
+// parseConsistencyMode returns the consistency mode set by the given key of
+// the configuration, or the default one
+func parseConsistencyMode(conf map[string]string, key, defaultMode string) (string, error) {
+	mode, ok := conf[key]
+	if !ok {
+		return defaultMode, nil
+	}
+	switch mode {
+	case consistencyModeDefault, consistencyModeStrong, consistencyModeStale:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s value: %q", key, mode)
+	}
+}
+
+// setConsistencyMode sets the query options reading with the given
+// consistency mode
+func setConsistencyMode(queryOpts *api.QueryOptions, mode string) {
+	switch mode {
+	case consistencyModeStrong:
+		queryOpts.RequireConsistent = true
+	case consistencyModeStale:
+		queryOpts.AllowStale = true
+	}
+}
+
+// NewConsulServiceRegistration constructs a Consul service registration that
+// is not tied to Consul being used as the storage backend. It accepts the same
+// connection and service parameters as the storage backend; the storage
+// specific parameters are ignored.
+func NewConsulServiceRegistration(conf map[string]string, logger log.Logger) (physical.ServiceDiscovery, error) {
+	if _, ok := conf["disable_registration"]; ok {
+		return nil, fmt.Errorf("disable_registration is not valid for Consul service registration")
+	}
+
+	b, err := NewConsulBackend(conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	return b.(*ConsulBackend), nil
+}
+
 func setupTLSConfig(conf map[string]string) (*tls.Config, error) {
 	serverName, _, err := net.SplitHostPort(conf["address"])
 	switch {
@@ -373,6 +434,9 @@ func (c *ConsulBackend) Transaction(ctx context.Context, txns []*physical.TxnEnt
 	if len(txns) == 0 {
 		return nil
 	}
+	if len(txns) > maxTxnOps {
+		return fmt.Errorf("%d operations exceed the maximum of %d in a Consul transaction", len(txns), maxTxnOps)
+	}
 
 	ops := make([]*api.KVTxnOp, 0, len(txns))
 
@@ -453,9 +517,7 @@ func (c *ConsulBackend) Get(ctx context.Context, key string) (*physical.Entry, e
 	queryOpts := &api.QueryOptions{}
 	queryOpts = queryOpts.WithContext(ctx)
 
-	if c.consistencyMode == consistencyModeStrong {
-		queryOpts.RequireConsistent = true
-	}
+	setConsistencyMode(queryOpts, c.getConsistencyMode)
 
 	pair, _, err := c.kv.Get(c.path+key, queryOpts)
 	if err != nil {
@@ -503,6 +565,7 @@ func (c *ConsulBackend) List(ctx context.Context, prefix string) ([]string, erro
 
 	queryOpts := &api.QueryOptions{}
 	queryOpts = queryOpts.WithContext(ctx)
+	setConsistencyMode(queryOpts, c.listConsistencyMode)
 
 	out, _, err := c.kv.Keys(scan, "/", queryOpts)
 	for idx, val := range out {
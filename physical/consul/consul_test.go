@@ -179,33 +179,37 @@ func TestConsul_ServiceAddress(t *testing.T) {
 
 func TestConsul_newConsulBackend(t *testing.T) {
 	tests := []struct {
-		name            string
-		consulConfig    map[string]string
-		fail            bool
-		redirectAddr    string
-		checkTimeout    time.Duration
-		path            string
-		service         string
-		address         string
-		scheme          string
-		token           string
-		max_parallel    int
-		disableReg      bool
-		consistencyMode string
+		name                string
+		consulConfig        map[string]string
+		fail                bool
+		redirectAddr        string
+		checkTimeout        time.Duration
+		path                string
+		service             string
+		address             string
+		scheme              string
+		token               string
+		max_parallel        int
+		disableReg          bool
+		consistencyMode     string
+		getConsistencyMode  string
+		listConsistencyMode string
 	}{
 		{
-			name:            "Valid default config",
-			consulConfig:    map[string]string{},
-			checkTimeout:    5 * time.Second,
-			redirectAddr:    "http://127.0.0.1:8200",
-			path:            "vault/",
-			service:         "vault",
-			address:         "127.0.0.1:8500",
-			scheme:          "http",
-			token:           "",
-			max_parallel:    4,
-			disableReg:      false,
-			consistencyMode: "default",
+			name:                "Valid default config",
+			consulConfig:        map[string]string{},
+			checkTimeout:        5 * time.Second,
+			redirectAddr:        "http://127.0.0.1:8200",
+			path:                "vault/",
+			service:             "vault",
+			address:             "127.0.0.1:8500",
+			scheme:              "http",
+			token:               "",
+			max_parallel:        4,
+			disableReg:          false,
+			consistencyMode:     "default",
+			getConsistencyMode:  "default",
+			listConsistencyMode: "default",
 		},
 		{
 			name: "Valid modified config",
@@ -221,15 +225,38 @@ func TestConsul_newConsulBackend(t *testing.T) {
 				"disable_registration": "false",
 				"consistency_mode":     "strong",
 			},
-			checkTimeout:    6 * time.Second,
-			path:            "seaTech/",
-			service:         "astronomy",
-			redirectAddr:    "http://127.0.0.2:8200",
-			address:         "127.0.0.2",
-			scheme:          "https",
-			token:           "deadbeef-cafeefac-deadc0de-feedface",
-			max_parallel:    4,
-			consistencyMode: "strong",
+			checkTimeout:        6 * time.Second,
+			path:                "seaTech/",
+			service:             "astronomy",
+			redirectAddr:        "http://127.0.0.2:8200",
+			address:             "127.0.0.2",
+			scheme:              "https",
+			token:               "deadbeef-cafeefac-deadc0de-feedface",
+			max_parallel:        4,
+			consistencyMode:     "strong",
+			getConsistencyMode:  "strong",
+			listConsistencyMode: "strong",
+		},
+		{
+			name: "Valid per operation consistency modes",
+			consulConfig: map[string]string{
+				"consistency_mode":      "strong",
+				"list_consistency_mode": "stale",
+			},
+			checkTimeout:        5 * time.Second,
+			redirectAddr:        "http://127.0.0.1:8200",
+			path:                "vault/",
+			service:             "vault",
+			consistencyMode:     "strong",
+			getConsistencyMode:  "strong",
+			listConsistencyMode: "stale",
+		},
+		{
+			name: "invalid consistency mode",
+			fail: true,
+			consulConfig: map[string]string{
+				"get_consistency_mode": "eventual",
+			},
 		},
 		{
 			name: "check timeout too short",
@@ -289,6 +316,14 @@ func TestConsul_newConsulBackend(t *testing.T) {
 			t.Errorf("bad consistency_mode value: %v != %v", test.consistencyMode, c.consistencyMode)
 		}
 
+		if test.getConsistencyMode != c.getConsistencyMode {
+			t.Errorf("bad get_consistency_mode value: %v != %v", test.getConsistencyMode, c.getConsistencyMode)
+		}
+
+		if test.listConsistencyMode != c.listConsistencyMode {
+			t.Errorf("bad list_consistency_mode value: %v != %v", test.listConsistencyMode, c.listConsistencyMode)
+		}
+
 		// FIXME(sean@): Unable to test max_parallel
 		// if test.max_parallel != cap(c.permitPool) {
 		// 	t.Errorf("bad: %v != %v", test.max_parallel, cap(c.permitPool))
@@ -648,3 +683,29 @@ func TestConsulHABackend(t *testing.T) {
 		t.Fatalf("bad addr: %v", host)
 	}
 }
+
+func TestConsul_TransactionTooManyOps(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	b, err := NewConsulBackend(map[string]string{}, logger)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	txns := make([]*physical.TxnEntry, 0, maxTxnOps+1)
+	for i := 0; i <= maxTxnOps; i++ {
+		txns = append(txns, &physical.TxnEntry{
+			Operation: physical.PutOperation,
+			Entry: &physical.Entry{
+				Key:   fmt.Sprintf("foo/%d", i),
+				Value: []byte("bar"),
+			},
+		})
+	}
+
+	// The transaction is rejected before reaching Consul
+	err = b.(physical.Transactional).Transaction(context.Background(), txns)
+	if err == nil || !strings.Contains(err.Error(), "exceed the maximum") {
+		t.Fatalf("expected too many operations error, got %v", err)
+	}
+}
@@ -155,6 +155,19 @@ func NewDynamoDBBackend(conf map[string]string, logger log.Logger) (physical.Bac
 		writeCapacity = DefaultDynamoDBWriteCapacity
 	}
 
+	billingMode := os.Getenv("AWS_DYNAMODB_BILLING_MODE")
+	if billingMode == "" {
+		billingMode = conf["billing_mode"]
+		if billingMode == "" {
+			billingMode = dynamodb.BillingModeProvisioned
+		}
+	}
+	switch billingMode {
+	case dynamodb.BillingModeProvisioned, dynamodb.BillingModePayPerRequest:
+	default:
+		return nil, fmt.Errorf("invalid billing mode: %q", billingMode)
+	}
+
 	endpoint := os.Getenv("AWS_DYNAMODB_ENDPOINT")
 	if endpoint == "" {
 		endpoint = conf["endpoint"]
@@ -212,7 +225,7 @@ func NewDynamoDBBackend(conf map[string]string, logger log.Logger) (physical.Bac
 
 	client := dynamodb.New(awsSession)
 
-	if err := ensureTableExists(client, table, readCapacity, writeCapacity); err != nil {
+	if err := ensureTableExists(client, table, billingMode, readCapacity, writeCapacity); err != nil {
 		return nil, err
 	}
 
@@ -765,19 +778,17 @@ WatchLoop:
 
 // ensureTableExists creates a DynamoDB table with a given
 // DynamoDB client. If the table already exists, it is not
-// being reconfigured.
-func ensureTableExists(client *dynamodb.DynamoDB, table string, readCapacity, writeCapacity int) error {
+// being reconfigured. The read and write capacity are only
+// used with the provisioned billing mode.
+func ensureTableExists(client *dynamodb.DynamoDB, table, billingMode string, readCapacity, writeCapacity int) error {
 	_, err := client.DescribeTable(&dynamodb.DescribeTableInput{
 		TableName: aws.String(table),
 	})
 	if awsError, ok := err.(awserr.Error); ok {
 		if awsError.Code() == "ResourceNotFoundException" {
-			_, err = client.CreateTable(&dynamodb.CreateTableInput{
-				TableName: aws.String(table),
-				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(int64(readCapacity)),
-					WriteCapacityUnits: aws.Int64(int64(writeCapacity)),
-				},
+			input := &dynamodb.CreateTableInput{
+				TableName:   aws.String(table),
+				BillingMode: aws.String(billingMode),
 				KeySchema: []*dynamodb.KeySchemaElement{{
 					AttributeName: aws.String("Path"),
 					KeyType:       aws.String("HASH"),
@@ -792,7 +803,15 @@ func ensureTableExists(client *dynamodb.DynamoDB, table string, readCapacity, wr
 					AttributeName: aws.String("Key"),
 					AttributeType: aws.String("S"),
 				}},
-			})
+			}
+			if billingMode == dynamodb.BillingModeProvisioned {
+				input.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(int64(readCapacity)),
+					WriteCapacityUnits: aws.Int64(int64(writeCapacity)),
+				}
+			}
+
+			_, err = client.CreateTable(input)
 			if err != nil {
 				return err
 			}
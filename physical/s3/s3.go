@@ -3,6 +3,8 @@ package s3
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"net/http"
@@ -35,6 +37,7 @@ var _ physical.Backend = (*S3Backend)(nil)
 type S3Backend struct {
 	bucket     string
 	kmsKeyId   string
+	shardCount int
 	client     *s3.S3
 	logger     log.Logger
 	permitPool *physical.PermitPool
@@ -141,10 +144,25 @@ func NewS3Backend(conf map[string]string, logger log.Logger) (physical.Backend,
 		kmsKeyId = ""
 	}
 
+	var shardCount int
+	if shardCountStr, ok := conf["shard_count"]; ok {
+		shardCount, err = strconv.Atoi(shardCountStr)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed parsing shard_count parameter: {{err}}", err)
+		}
+		if shardCount < 0 || shardCount > 256 {
+			return nil, fmt.Errorf("shard_count must be between 0 and 256")
+		}
+		if logger.IsDebug() {
+			logger.Debug("shard_count set", "shard_count", shardCount)
+		}
+	}
+
 	s := &S3Backend{
 		client:     s3conn,
 		bucket:     bucket,
 		kmsKeyId:   kmsKeyId,
+		shardCount: shardCount,
 		logger:     logger,
 		permitPool: physical.NewPermitPool(maxParInt),
 	}
@@ -160,7 +178,7 @@ func (s *S3Backend) Put(ctx context.Context, entry *physical.Entry) error {
 
 	putObjectInput := &s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(entry.Key),
+		Key:    aws.String(s.objectKey(entry.Key)),
 		Body:   bytes.NewReader(entry.Value),
 	}
 
@@ -187,7 +205,7 @@ func (s *S3Backend) Get(ctx context.Context, key string) (*physical.Entry, error
 
 	resp, err := s.client.GetObject(&s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.objectKey(key)),
 	})
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
@@ -232,7 +250,7 @@ func (s *S3Backend) Delete(ctx context.Context, key string) error {
 
 	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.objectKey(key)),
 	})
 
 	if err != nil {
@@ -250,46 +268,80 @@ func (s *S3Backend) List(ctx context.Context, prefix string) ([]string, error) {
 	s.permitPool.Acquire()
 	defer s.permitPool.Release()
 
-	params := &s3.ListObjectsV2Input{
-		Bucket:    aws.String(s.bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
+	// Keys in different shards may share a parent 'folder', so de-duplicate
+	// the results
+	seen := make(map[string]struct{})
+	keys := []string{}
+	addKey := func(key string) {
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
 	}
 
-	keys := []string{}
+	for _, shardPrefix := range s.shardPrefixes() {
+		params := &s3.ListObjectsV2Input{
+			Bucket:    aws.String(s.bucket),
+			Prefix:    aws.String(shardPrefix + prefix),
+			Delimiter: aws.String("/"),
+		}
 
-	err := s.client.ListObjectsV2Pages(params,
-		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
-			if page != nil {
-				// Add truncated 'folder' paths
-				for _, commonPrefix := range page.CommonPrefixes {
-					// Avoid panic
-					if commonPrefix == nil {
-						continue
+		err := s.client.ListObjectsV2PagesWithContext(ctx, params,
+			func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+				if page != nil {
+					// Add truncated 'folder' paths
+					for _, commonPrefix := range page.CommonPrefixes {
+						// Avoid panic
+						if commonPrefix == nil {
+							continue
+						}
+
+						addKey(strings.TrimPrefix(*commonPrefix.Prefix, shardPrefix+prefix))
 					}
-
-					commonPrefix := strings.TrimPrefix(*commonPrefix.Prefix, prefix)
-					keys = append(keys, commonPrefix)
-				}
-				// Add objects only from the current 'folder'
-				for _, key := range page.Contents {
-					// Avoid panic
-					if key == nil {
-						continue
+					// Add objects only from the current 'folder'
+					for _, key := range page.Contents {
+						// Avoid panic
+						if key == nil {
+							continue
+						}
+
+						addKey(strings.TrimPrefix(*key.Key, shardPrefix+prefix))
 					}
-
-					key := strings.TrimPrefix(*key.Key, prefix)
-					keys = append(keys, key)
 				}
-			}
-			return true
-		})
+				return true
+			})
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	sort.Strings(keys)
 
 	return keys, nil
 }
+
+// objectKey returns the name of the object storing the given key. When
+// sharding is enabled, keys are spread across prefixes derived from their
+// hash so that writes are not concentrated on a few S3 partitions.
+func (s *S3Backend) objectKey(key string) string {
+	if s.shardCount == 0 {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	shard := binary.BigEndian.Uint32(sum[:4]) % uint32(s.shardCount)
+	return fmt.Sprintf("%02x/%s", shard, key)
+}
+
+// shardPrefixes returns the object name prefix of every shard.
+func (s *S3Backend) shardPrefixes() []string {
+	if s.shardCount == 0 {
+		return []string{""}
+	}
+	prefixes := make([]string, s.shardCount)
+	for i := range prefixes {
+		prefixes[i] = fmt.Sprintf("%02x/", i)
+	}
+	return prefixes
+}
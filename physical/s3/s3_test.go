@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -105,3 +106,46 @@ func DoS3BackendTest(t *testing.T, kmsKeyId string) {
 	physical.ExerciseBackend(t, b)
 	physical.ExerciseBackend_ListPrefix(t, b)
 }
+
+func TestS3Backend_objectKey(t *testing.T) {
+	unsharded := &S3Backend{}
+	if key := unsharded.objectKey("foo/bar"); key != "foo/bar" {
+		t.Fatalf("bad: %q", key)
+	}
+	if prefixes := unsharded.shardPrefixes(); len(prefixes) != 1 || prefixes[0] != "" {
+		t.Fatalf("bad: %#v", prefixes)
+	}
+
+	sharded := &S3Backend{shardCount: 16}
+	prefixes := sharded.shardPrefixes()
+	if len(prefixes) != 16 || prefixes[0] != "00/" || prefixes[15] != "0f/" {
+		t.Fatalf("bad: %#v", prefixes)
+	}
+
+	used := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("sys/expire/id/%d", i)
+		objectKey := sharded.objectKey(key)
+		if objectKey != sharded.objectKey(key) {
+			t.Fatal("expected object key to be stable")
+		}
+		if !strings.HasSuffix(objectKey, "/"+key) {
+			t.Fatalf("bad: %q", objectKey)
+		}
+		used[objectKey[:3]] = true
+	}
+	if len(used) < 2 {
+		t.Fatal("expected keys to be spread across shards")
+	}
+	for shard := range used {
+		found := false
+		for _, prefix := range prefixes {
+			if prefix == shard {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("unknown shard %q", shard)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package swift
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"sort"
 	"strconv"
@@ -14,6 +15,7 @@ import (
 	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/errwrap"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/ncw/swift"
@@ -22,6 +24,16 @@ import (
 // Verify SwiftBackend satisfies the correct interfaces
 var _ physical.Backend = (*SwiftBackend)(nil)
 
+const (
+	// DefaultMaxRetries is the number of times a failed request is retried
+	DefaultMaxRetries = 3
+
+	// initialRetryDelay is the delay before the first retry, which doubles
+	// with each further attempt up to maxRetryDelay
+	initialRetryDelay = 250 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+)
+
 // SwiftBackend is a physical backend that stores data
 // within an OpenStack Swift container.
 type SwiftBackend struct {
@@ -29,6 +41,7 @@ type SwiftBackend struct {
 	client     *swift.Connection
 	logger     log.Logger
 	permitPool *physical.PermitPool
+	maxRetries int
 }
 
 // NewSwiftBackend constructs a Swift backend using a pre-existing
@@ -121,6 +134,26 @@ func NewSwiftBackend(conf map[string]string, logger log.Logger) (physical.Backen
 		Transport:    cleanhttp.DefaultPooledTransport(),
 	}
 
+	if timeoutStr, ok := conf["request_timeout"]; ok {
+		timeout, err := parseutil.ParseDurationSecond(timeoutStr)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed parsing request_timeout parameter: {{err}}", err)
+		}
+		c.Timeout = timeout
+	}
+
+	maxRetries := DefaultMaxRetries
+	if maxRetriesStr, ok := conf["max_retries"]; ok {
+		var err error
+		maxRetries, err = strconv.Atoi(maxRetriesStr)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed parsing max_retries parameter: {{err}}", err)
+		}
+		if maxRetries < 0 {
+			return nil, fmt.Errorf("max_retries cannot be negative")
+		}
+	}
+
 	err := c.Authenticate()
 	if err != nil {
 		return nil, err
@@ -148,10 +181,56 @@ func NewSwiftBackend(conf map[string]string, logger log.Logger) (physical.Backen
 		container:  container,
 		logger:     logger,
 		permitPool: physical.NewPermitPool(maxParInt),
+		maxRetries: maxRetries,
 	}
 	return s, nil
 }
 
+// retry runs the given operation, retrying transient failures with
+// exponential backoff until maxRetries is reached or the context is done.
+func (s *SwiftBackend) retry(ctx context.Context, op func() error) error {
+	delay := initialRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= s.maxRetries || !retryableError(err) {
+			return err
+		}
+
+		if s.logger.IsDebug() {
+			s.logger.Debug("retrying request", "attempt", attempt+1, "error", err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// retryableError returns whether a request that failed with the given error
+// may succeed if retried. This includes objects whose content does not match
+// their ETag, which the client reports as corrupted.
+func retryableError(err error) bool {
+	switch err := err.(type) {
+	case *swift.Error:
+		switch err.StatusCode {
+		case 408, 422, 429, 498, 500, 502, 503, 504:
+			return true
+		}
+		return false
+	case net.Error:
+		return true
+	default:
+		return false
+	}
+}
+
 // Put is used to insert or update an entry
 func (s *SwiftBackend) Put(ctx context.Context, entry *physical.Entry) error {
 	defer metrics.MeasureSince([]string{"swift", "put"}, time.Now())
@@ -159,7 +238,9 @@ func (s *SwiftBackend) Put(ctx context.Context, entry *physical.Entry) error {
 	s.permitPool.Acquire()
 	defer s.permitPool.Release()
 
-	err := s.client.ObjectPutBytes(s.container, entry.Key, entry.Value, "")
+	err := s.retry(ctx, func() error {
+		return s.client.ObjectPutBytes(s.container, entry.Key, entry.Value, "")
+	})
 
 	if err != nil {
 		return err
@@ -175,23 +256,33 @@ func (s *SwiftBackend) Get(ctx context.Context, key string) (*physical.Entry, er
 	s.permitPool.Acquire()
 	defer s.permitPool.Release()
 
-	//Do a list of names with the key first since eventual consistency means
-	//it might be deleted, but a node might return a read of bytes which fails
-	//the physical test
-	list, err := s.client.ObjectNames(s.container, &swift.ObjectsOpts{Prefix: key})
+	var data []byte
+	err := s.retry(ctx, func() error {
+		//Do a list of names with the key first since eventual consistency means
+		//it might be deleted, but a node might return a read of bytes which fails
+		//the physical test
+		list, err := s.client.ObjectNames(s.container, &swift.ObjectsOpts{Prefix: key})
+		if err != nil {
+			return err
+		}
+		if 0 == len(list) {
+			data = nil
+			return nil
+		}
+		// This verifies the content against the object's ETag
+		data, err = s.client.ObjectGetBytes(s.container, key)
+		if err == swift.ObjectNotFound {
+			data = nil
+			return nil
+		}
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if 0 == len(list) {
-		return nil, nil
-	}
-	data, err := s.client.ObjectGetBytes(s.container, key)
-	if err == swift.ObjectNotFound {
+	if data == nil {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
 	ent := &physical.Entry{
 		Key:   key,
 		Value: data,
@@ -207,9 +298,15 @@ func (s *SwiftBackend) Delete(ctx context.Context, key string) error {
 	s.permitPool.Acquire()
 	defer s.permitPool.Release()
 
-	err := s.client.ObjectDelete(s.container, key)
+	err := s.retry(ctx, func() error {
+		err := s.client.ObjectDelete(s.container, key)
+		if err == swift.ObjectNotFound {
+			return nil
+		}
+		return err
+	})
 
-	if err != nil && err != swift.ObjectNotFound {
+	if err != nil {
 		return err
 	}
 
@@ -224,7 +321,12 @@ func (s *SwiftBackend) List(ctx context.Context, prefix string) ([]string, error
 	s.permitPool.Acquire()
 	defer s.permitPool.Release()
 
-	list, err := s.client.ObjectNamesAll(s.container, &swift.ObjectsOpts{Prefix: prefix})
+	var list []string
+	err := s.retry(ctx, func() error {
+		var err error
+		list, err = s.client.ObjectNamesAll(s.container, &swift.ObjectsOpts{Prefix: prefix})
+		return err
+	})
 	if nil != err {
 		return nil, err
 	}
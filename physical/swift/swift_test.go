@@ -1,6 +1,7 @@
 package swift
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -89,3 +90,49 @@ func TestSwiftBackend(t *testing.T) {
 	physical.ExerciseBackend(t, b)
 	physical.ExerciseBackend_ListPrefix(t, b)
 }
+
+func TestSwiftBackend_retry(t *testing.T) {
+	s := &SwiftBackend{
+		logger:     logging.NewVaultLogger(log.Debug),
+		maxRetries: 2,
+	}
+
+	attempts := 0
+	err := s.retry(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return &swift.Error{StatusCode: 503}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	err = s.retry(context.Background(), func() error {
+		attempts++
+		return swift.ObjectCorrupted
+	})
+	if err != swift.ObjectCorrupted {
+		t.Fatalf("expected corrupted object error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	err = s.retry(context.Background(), func() error {
+		attempts++
+		return swift.ContainerNotFound
+	})
+	if err != swift.ContainerNotFound {
+		t.Fatalf("expected container not found error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected non-retryable error to be attempted once, got %d", attempts)
+	}
+}
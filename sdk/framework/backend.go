@@ -2,7 +2,6 @@ package framework
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -235,7 +234,30 @@ func (b *Backend) HandleRequest(ctx context.Context, req *logical.Request) (*log
 		}
 	}
 
-	return callback(ctx, req, &fd)
+	resp, err := callback(ctx, req, &fd)
+	if err != nil || req.Operation == logical.HelpOperation {
+		return resp, err
+	}
+
+	// Warn about the deprecated fields set by the request, so that they don't
+	// go unnoticed until they are removed
+	var deprecated []string
+	for k := range req.Data {
+		if schema, ok := path.Fields[k]; ok && schema.Deprecated {
+			deprecated = append(deprecated, k)
+		}
+	}
+	if len(deprecated) > 0 {
+		sort.Strings(deprecated)
+		if resp == nil {
+			resp = &logical.Response{}
+		}
+		for _, k := range deprecated {
+			resp.AddWarning(fmt.Sprintf("the %q parameter is deprecated; see the path help for its replacement", k))
+		}
+	}
+
+	return resp, nil
 }
 
 // SpecialPaths is the logical.Backend implementation.
@@ -540,34 +562,18 @@ func (s *FieldSchema) DefaultOrZero() interface{} {
 	if s.Default != nil {
 		switch s.Type {
 		case TypeDurationSecond:
-			var result int
-			switch inp := s.Default.(type) {
-			case nil:
+			dur, err := parseutil.ParseDurationSecond(s.Default)
+			if err != nil {
 				return s.Type.Zero()
-			case int:
-				result = inp
-			case int64:
-				result = int(inp)
-			case float32:
-				result = int(inp)
-			case float64:
-				result = int(inp)
-			case string:
-				dur, err := parseutil.ParseDurationSecond(inp)
-				if err != nil {
-					return s.Type.Zero()
-				}
-				result = int(dur.Seconds())
-			case json.Number:
-				valInt64, err := inp.Int64()
-				if err != nil {
-					return s.Type.Zero()
-				}
-				result = int(valInt64)
-			default:
+			}
+			return int(dur.Seconds())
+
+		case TypeSize:
+			size, err := parseutil.ParseSize(s.Default)
+			if err != nil {
 				return s.Type.Zero()
 			}
-			return result
+			return size
 
 		default:
 			return s.Default
@@ -600,6 +606,8 @@ func (t FieldType) Zero() interface{} {
 		return []int{}
 	case TypeHeader:
 		return http.Header{}
+	case TypeSize:
+		return int64(0)
 	default:
 		panic("unknown type: " + t.String())
 	}
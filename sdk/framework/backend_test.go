@@ -114,6 +114,63 @@ func TestBackendHandleRequest(t *testing.T) {
 	}
 }
 
+func TestBackendHandleRequest_deprecatedFields(t *testing.T) {
+	var resp *logical.Response
+	callback := func(ctx context.Context, req *logical.Request, data *FieldData) (*logical.Response, error) {
+		return resp, nil
+	}
+
+	b := &Backend{
+		Paths: []*Path{
+			{
+				Pattern: "foo/" + GenericNameRegex("name"),
+				Fields: map[string]*FieldSchema{
+					"name":   &FieldSchema{Type: TypeString, Deprecated: true},
+					"value":  &FieldSchema{Type: TypeInt},
+					"amount": &FieldSchema{Type: TypeInt, Deprecated: true},
+					"old":    &FieldSchema{Type: TypeInt, Deprecated: true, Default: 1},
+				},
+				Callbacks: map[logical.Operation]OperationFunc{
+					logical.UpdateOperation: callback,
+				},
+			},
+		},
+	}
+
+	write := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "foo/bar",
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return resp
+	}
+
+	// Deprecated fields that aren't set by the request, including URL
+	// parameters and defaults, aren't warned about
+	if resp := write(map[string]interface{}{"value": 1}); resp != nil {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Warnings are added to nil responses
+	got := write(map[string]interface{}{"value": 1, "old": 2, "amount": 3})
+	if got == nil || len(got.Warnings) != 2 ||
+		!strings.Contains(got.Warnings[0], `"amount"`) || !strings.Contains(got.Warnings[1], `"old"`) {
+		t.Fatalf("bad: %#v", got)
+	}
+
+	// and appended to the warnings of the response
+	resp = &logical.Response{Warnings: []string{"existing"}}
+	got = write(map[string]interface{}{"amount": 3})
+	if len(got.Warnings) != 2 || got.Warnings[0] != "existing" {
+		t.Fatalf("bad: %#v", got)
+	}
+}
+
 func TestBackendHandleRequest_badwrite(t *testing.T) {
 	callback := func(ctx context.Context, req *logical.Request, data *FieldData) (*logical.Response, error) {
 		return &logical.Response{
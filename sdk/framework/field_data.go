@@ -40,7 +40,7 @@ func (d *FieldData) Validate() error {
 		switch schema.Type {
 		case TypeBool, TypeInt, TypeMap, TypeDurationSecond, TypeString, TypeLowerCaseString,
 			TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice,
-			TypeKVPairs, TypeCommaIntSlice, TypeHeader:
+			TypeKVPairs, TypeCommaIntSlice, TypeHeader, TypeSize:
 			_, _, err := d.getPrimitive(field, schema)
 			if err != nil {
 				return errwrap.Wrapf(fmt.Sprintf("error converting input %v for field %q: {{err}}", value, field), err)
@@ -133,7 +133,7 @@ func (d *FieldData) GetOkErr(k string) (interface{}, bool, error) {
 	switch schema.Type {
 	case TypeBool, TypeInt, TypeMap, TypeDurationSecond, TypeString, TypeLowerCaseString,
 		TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice,
-		TypeKVPairs, TypeCommaIntSlice, TypeHeader:
+		TypeKVPairs, TypeCommaIntSlice, TypeHeader, TypeSize:
 		return d.getPrimitive(k, schema)
 	default:
 		return nil, false,
@@ -149,8 +149,8 @@ func (d *FieldData) getPrimitive(k string, schema *FieldSchema) (interface{}, bo
 
 	switch schema.Type {
 	case TypeBool:
-		var result bool
-		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+		result, err := parseutil.ParseBool(raw)
+		if err != nil {
 			return nil, false, err
 		}
 		return result, true, nil
@@ -198,46 +198,29 @@ func (d *FieldData) getPrimitive(k string, schema *FieldSchema) (interface{}, bo
 		return result, true, nil
 
 	case TypeDurationSecond:
-		var result int
-		switch inp := raw.(type) {
-		case nil:
+		if raw == nil {
 			return nil, false, nil
-		case int:
-			result = inp
-		case int32:
-			result = int(inp)
-		case int64:
-			result = int(inp)
-		case uint:
-			result = int(inp)
-		case uint32:
-			result = int(inp)
-		case uint64:
-			result = int(inp)
-		case float32:
-			result = int(inp)
-		case float64:
-			result = int(inp)
-		case string:
-			dur, err := parseutil.ParseDurationSecond(inp)
-			if err != nil {
-				return nil, false, err
-			}
-			result = int(dur.Seconds())
-		case json.Number:
-			valInt64, err := inp.Int64()
-			if err != nil {
-				return nil, false, err
-			}
-			result = int(valInt64)
-		default:
-			return nil, false, fmt.Errorf("invalid input '%v'", raw)
 		}
+		dur, err := parseutil.ParseDurationSecond(raw)
+		if err != nil {
+			return nil, false, err
+		}
+		result := int(dur.Seconds())
 		if result < 0 {
 			return nil, false, fmt.Errorf("cannot provide negative value '%d'", result)
 		}
 		return result, true, nil
 
+	case TypeSize:
+		if raw == nil {
+			return nil, false, nil
+		}
+		result, err := parseutil.ParseSize(raw)
+		if err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
 	case TypeCommaIntSlice:
 		var result []int
 		config := &mapstructure.DecoderConfig{
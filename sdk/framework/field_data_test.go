@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"encoding/json"
 	"net/http"
 	"reflect"
 	"testing"
@@ -202,6 +203,105 @@ func TestFieldDataGet(t *testing.T) {
 			0,
 		},
 
+		"duration type, compound duration value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeDurationSecond},
+			},
+			map[string]interface{}{
+				"foo": "1h30m",
+			},
+			"foo",
+			5400,
+		},
+
+		"duration type, json number value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeDurationSecond},
+			},
+			map[string]interface{}{
+				"foo": json.Number("90"),
+			},
+			"foo",
+			90,
+		},
+
+		"duration type, unset value with string default": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{
+					Type:    TypeDurationSecond,
+					Default: "1h",
+				},
+			},
+			map[string]interface{}{},
+			"foo",
+			3600,
+		},
+
+		"bool type, string value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeBool},
+			},
+			map[string]interface{}{
+				"foo": " TRUE ",
+			},
+			"foo",
+			true,
+		},
+
+		"bool type, json number value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeBool},
+			},
+			map[string]interface{}{
+				"foo": json.Number("0"),
+			},
+			"foo",
+			false,
+		},
+
+		"size type, string value with unit": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeSize},
+			},
+			map[string]interface{}{
+				"foo": "512kb",
+			},
+			"foo",
+			int64(512000),
+		},
+
+		"size type, int value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeSize},
+			},
+			map[string]interface{}{
+				"foo": 42,
+			},
+			"foo",
+			int64(42),
+		},
+
+		"size type, unset value with string default": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{
+					Type:    TypeSize,
+					Default: "1MiB",
+				},
+			},
+			map[string]interface{}{},
+			"foo",
+			int64(1 << 20),
+		},
+
+		"size type, unset value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeSize},
+			},
+			map[string]interface{}{},
+			"foo",
+			int64(0),
+		},
+
 		"slice type, empty slice": {
 			map[string]*FieldSchema{
 				"foo": &FieldSchema{Type: TypeSlice},
@@ -735,6 +835,33 @@ func TestFieldDataGet_Error(t *testing.T) {
 			},
 			"foo",
 		},
+		"duration type, invalid duration": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeDurationSecond},
+			},
+			map[string]interface{}{
+				"foo": "1 hour",
+			},
+			"foo",
+		},
+		"bool type, invalid value": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeBool},
+			},
+			map[string]interface{}{
+				"foo": "maybe",
+			},
+			"foo",
+		},
+		"size type, unknown unit": {
+			map[string]*FieldSchema{
+				"foo": &FieldSchema{Type: TypeSize},
+			},
+			map[string]interface{}{
+				"foo": "5 parsecs",
+			},
+			"foo",
+		},
 		"keypair type, csv version empty key name": {
 			map[string]*FieldSchema{
 				"foo": &FieldSchema{Type: TypeKVPairs},
@@ -48,6 +48,10 @@ const (
 	// benevolent MITM for a request, and the headers are sent through and
 	// parsed.
 	TypeHeader
+
+	// TypeSize represents a size in bytes, this can be either an integer or
+	// a string with a unit (e.g. 512kb or 1GiB)
+	TypeSize
 )
 
 func (t FieldType) String() string {
@@ -72,6 +76,8 @@ func (t FieldType) String() string {
 		return "slice"
 	case TypeHeader:
 		return "header"
+	case TypeSize:
+		return "size (bytes)"
 	default:
 		return "unknown type"
 	}
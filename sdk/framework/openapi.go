@@ -554,6 +554,9 @@ func convertType(t FieldType) schemaType {
 	case TypeDurationSecond:
 		ret.baseType = "integer"
 		ret.format = "seconds"
+	case TypeSize:
+		ret.baseType = "integer"
+		ret.format = "bytes"
 	case TypeBool:
 		ret.baseType = "boolean"
 	case TypeMap:
@@ -0,0 +1,96 @@
+package framework
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// DefaultRetryInitialBackoff is the wait before the first retry if the
+	// policy doesn't set one
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+
+	// DefaultRetryMaxBackoff is the longest wait between retries if the policy
+	// doesn't set one
+	DefaultRetryMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy describes how an operation on a secret is retried when it
+// fails, for instance when the external system holding the credentials is
+// briefly unavailable. Vault core retries the revocation of expired leases
+// too, but with backoffs of tens of seconds and for a limited number of
+// attempts; retrying within the request rides out brief failures, and also
+// covers explicit revoke requests, which core doesn't retry.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the operation is attempted,
+	// including the first attempt. Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. Each following
+	// wait is doubled, up to MaxBackoff. Defaults to
+	// DefaultRetryInitialBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the longest wait between attempts. Defaults to
+	// DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+
+	// Retryable reports whether the operation should be retried after it
+	// failed with the given error. If nil, all errors except
+	// logical.ErrUnsupportedOperation and logical.ErrInvalidRequest are
+	// retried. Error responses without an error are never retried.
+	Retryable func(error) bool
+}
+
+// Run calls op until it succeeds, it fails with an error the policy doesn't
+// retry, the attempts are used up or the context is done. The result of the
+// last attempt is returned. Run may be called on a nil policy, in which case
+// op is called once.
+func (p *RetryPolicy) Run(ctx context.Context, op func() (*logical.Response, error)) (*logical.Response, error) {
+	resp, err := op()
+	if p == nil {
+		return resp, err
+	}
+
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	for attempt := 1; attempt < p.MaxAttempts && err != nil && p.retryable(err); attempt++ {
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+
+		resp, err = op()
+		backoff *= 2
+	}
+
+	return resp, err
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	switch err {
+	case logical.ErrUnsupportedOperation, logical.ErrInvalidRequest:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestRetryPolicy_Run(t *testing.T) {
+	errTransient := errors.New("transient")
+
+	cases := map[string]struct {
+		policy   *RetryPolicy
+		errs     []error
+		calls    int
+		expected error
+	}{
+		"nil policy": {
+			nil,
+			[]error{errTransient, nil},
+			1,
+			errTransient,
+		},
+		"no retries": {
+			&RetryPolicy{MaxAttempts: 1},
+			[]error{errTransient, nil},
+			1,
+			errTransient,
+		},
+		"succeeds after retry": {
+			&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			[]error{errTransient, errTransient, nil},
+			3,
+			nil,
+		},
+		"attempts used up": {
+			&RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			[]error{errTransient, errTransient, nil},
+			2,
+			errTransient,
+		},
+		"invalid request not retried": {
+			&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			[]error{logical.ErrInvalidRequest, nil},
+			1,
+			logical.ErrInvalidRequest,
+		},
+		"custom retryable": {
+			&RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				Retryable: func(err error) bool {
+					return err != errTransient
+				},
+			},
+			[]error{errTransient, nil},
+			1,
+			errTransient,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			_, err := tc.policy.Run(context.Background(), func() (*logical.Response, error) {
+				err := tc.errs[calls]
+				calls++
+				return nil, err
+			})
+			if err != tc.expected {
+				t.Fatalf("expected error %v, got %v", tc.expected, err)
+			}
+			if calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_RunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Hour,
+	}
+
+	var calls int
+	start := time.Now()
+	_, err := policy.Run(ctx, func() (*logical.Response, error) {
+		calls++
+		cancel()
+		return nil, errors.New("transient")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+	if time.Since(start) > time.Minute {
+		t.Fatal("expected the wait to be cut short")
+	}
+}
+
+func TestSecret_RevokeRetry(t *testing.T) {
+	var calls int
+	secret := &Secret{
+		Type: "foo",
+		Revoke: func(context.Context, *logical.Request, *FieldData) (*logical.Response, error) {
+			calls++
+			if calls < 2 {
+				return nil, errors.New("transient")
+			}
+			return nil, nil
+		},
+		RevokeRetry: &RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+
+	if _, err := secret.HandleRevoke(context.Background(), &logical.Request{}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
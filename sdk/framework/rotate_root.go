@@ -0,0 +1,48 @@
+package framework
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// RotateRootSuffix is the conventional final path segment of the endpoint a
+// backend exposes to rotate the credential it uses to manage an external
+// system.
+const RotateRootSuffix = "rotate-root"
+
+// PathRotateRoot returns a Path implementing the rotate-root convention
+// beneath the given prefix; for example a prefix of "config/" results in the
+// pattern "config/rotate-root". Only the update operation is exposed, and
+// invocations of the callback are serialized so that two concurrent rotations
+// cannot race each other to replace the stored credential.
+//
+// Callers may set Fields or override the help text on the returned Path.
+func PathRotateRoot(prefix string, callback OperationFunc) *Path {
+	var lock sync.Mutex
+	return &Path{
+		Pattern: prefix + RotateRootSuffix,
+		Callbacks: map[logical.Operation]OperationFunc{
+			logical.UpdateOperation: func(ctx context.Context, req *logical.Request, data *FieldData) (*logical.Response, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				return callback(ctx, req, data)
+			},
+		},
+
+		HelpSynopsis:    pathRotateRootHelpSyn,
+		HelpDescription: pathRotateRootHelpDesc,
+	}
+}
+
+const pathRotateRootHelpSyn = `
+Request to rotate the credentials used by Vault for this mount
+`
+
+const pathRotateRootHelpDesc = `
+This path generates a new credential for the account Vault uses to manage the
+external system backing this mount, stores it in place of the old one and then
+revokes the old credential. Once rotated, the new credential is known only to
+Vault.
+`
@@ -0,0 +1,67 @@
+package framework
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPathRotateRoot(t *testing.T) {
+	var running, maxRunning int32
+	var calls int32
+	callback := func(ctx context.Context, req *logical.Request, data *FieldData) (*logical.Response, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		if n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	}
+
+	p := PathRotateRoot("config/", callback)
+	if p.Pattern != "config/rotate-root" {
+		t.Fatalf("bad pattern: %q", p.Pattern)
+	}
+	if len(p.Callbacks) != 1 {
+		t.Fatalf("expected only the update callback, got: %#v", p.Callbacks)
+	}
+	if p.HelpSynopsis == "" || p.HelpDescription == "" {
+		t.Fatal("expected default help text")
+	}
+
+	b := &Backend{Paths: []*Path{p}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "config/rotate-root",
+			})
+		}()
+	}
+	wg.Wait()
+
+	if calls != 5 {
+		t.Fatalf("expected 5 calls, got %d", calls)
+	}
+	if maxRunning != 1 {
+		t.Fatalf("expected rotations to be serialized, saw %d running at once", maxRunning)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config/rotate-root",
+	})
+	if err != logical.ErrUnsupportedOperation {
+		t.Fatalf("expected unsupported operation on read, got resp: %#v, err: %v", resp, err)
+	}
+}
@@ -35,6 +35,13 @@ type Secret struct {
 
 	// Revoke is the callback called to revoke this secret. This is required.
 	Revoke OperationFunc
+
+	// RenewRetry and RevokeRetry, if set, are how failing calls to Renew and
+	// Revoke are retried. Backends managing credentials in external systems
+	// should set RevokeRetry, so that transient failures don't leave
+	// credentials behind.
+	RenewRetry  *RetryPolicy
+	RevokeRetry *RetryPolicy
 }
 
 func (s *Secret) Renewable() bool {
@@ -73,7 +80,9 @@ func (s *Secret) HandleRenew(ctx context.Context, req *logical.Request) (*logica
 		Schema: s.Fields,
 	}
 
-	return s.Renew(ctx, req, data)
+	return s.RenewRetry.Run(ctx, func() (*logical.Response, error) {
+		return s.Renew(ctx, req, data)
+	})
 }
 
 // HandleRevoke is the request handler for revoking this secret.
@@ -84,7 +93,9 @@ func (s *Secret) HandleRevoke(ctx context.Context, req *logical.Request) (*logic
 	}
 
 	if s.Revoke != nil {
-		return s.Revoke(ctx, req, data)
+		return s.RevokeRetry.Run(ctx, func() (*logical.Response, error) {
+			return s.Revoke(ctx, req, data)
+		})
 	}
 
 	return nil, logical.ErrUnsupportedOperation
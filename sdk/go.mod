@@ -1,6 +1,6 @@
 module github.com/hashicorp/vault/sdk
 
-go 1.12
+go 1.13
 
 require (
 	github.com/armon/go-metrics v0.0.0-20180917152333-f0300d1749da
@@ -0,0 +1,182 @@
+package certutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+const (
+	// DefaultAIAFetchTimeout is the default timeout for each request made
+	// while fetching an issuing certificate
+	DefaultAIAFetchTimeout = 10 * time.Second
+
+	// DefaultAIAMaxCertificateSize is the default maximum size, in bytes, of
+	// a fetched issuing certificate
+	DefaultAIAMaxCertificateSize = 64 * 1024
+
+	// DefaultAIAMaxChainLength is the default maximum number of issuing
+	// certificates that will be fetched to complete a chain
+	DefaultAIAMaxChainLength = 10
+)
+
+// AIAFetchOptions controls how issuing certificates are fetched when
+// completing a certificate chain. Zero values are replaced by defaults.
+type AIAFetchOptions struct {
+	// Client is the HTTP client used to fetch certificates. If nil, a client
+	// with Timeout set is used.
+	Client *http.Client
+
+	// Timeout bounds each individual fetch
+	Timeout time.Duration
+
+	// MaxCertificateSize is the maximum number of bytes read for a single
+	// fetched certificate
+	MaxCertificateSize int64
+
+	// MaxChainLength is the maximum number of certificates that will be
+	// fetched and appended to the chain
+	MaxChainLength int
+}
+
+func (o *AIAFetchOptions) withDefaults() *AIAFetchOptions {
+	ret := &AIAFetchOptions{}
+	if o != nil {
+		*ret = *o
+	}
+	if ret.Timeout <= 0 {
+		ret.Timeout = DefaultAIAFetchTimeout
+	}
+	if ret.MaxCertificateSize <= 0 {
+		ret.MaxCertificateSize = DefaultAIAMaxCertificateSize
+	}
+	if ret.MaxChainLength <= 0 {
+		ret.MaxChainLength = DefaultAIAMaxChainLength
+	}
+	if ret.Client == nil {
+		ret.Client = &http.Client{
+			Timeout: ret.Timeout,
+		}
+	}
+	return ret
+}
+
+// CompleteChainFromAIA follows the Authority Information Access issuing
+// certificate URLs of the last certificate in the bundle's path, appending
+// each fetched issuer to the CA chain, until a self-signed certificate is
+// reached or no further issuer can be found. Only fetched certificates that
+// actually signed the previous certificate in the path are accepted. It
+// returns the number of certificates added to the chain.
+func CompleteChainFromAIA(ctx context.Context, bundle *ParsedCertBundle, opts *AIAFetchOptions) (int, error) {
+	if bundle == nil || bundle.Certificate == nil {
+		return 0, errutil.UserError{Err: "bundle does not contain a certificate"}
+	}
+	opts = opts.withDefaults()
+
+	certPath := bundle.GetCertificatePath()
+	current := certPath[len(certPath)-1].Certificate
+
+	added := 0
+	for !isSelfSigned(current) && len(current.IssuingCertificateURL) > 0 {
+		if added >= opts.MaxChainLength {
+			return added, errutil.UserError{Err: fmt.Sprintf("certificate chain exceeds the maximum length of %d fetched certificates", opts.MaxChainLength)}
+		}
+
+		var issuer *CertBlock
+		var errs []string
+		for _, issuerURL := range current.IssuingCertificateURL {
+			candidate, err := fetchIssuingCertificate(ctx, issuerURL, opts)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			if err := current.CheckSignatureFrom(candidate.Certificate); err != nil {
+				errs = append(errs, fmt.Sprintf("certificate fetched from %q did not sign %q: %v", issuerURL, current.Subject.CommonName, err))
+				continue
+			}
+			issuer = candidate
+			break
+		}
+		if issuer == nil {
+			return added, errutil.UserError{Err: fmt.Sprintf("unable to fetch issuer of %q: %v", current.Subject.CommonName, errs)}
+		}
+
+		if bundle.hasCACertificate(issuer.Bytes) {
+			break
+		}
+
+		bundle.CAChain = append(bundle.CAChain, issuer)
+		added++
+		current = issuer.Certificate
+	}
+
+	return added, nil
+}
+
+func fetchIssuingCertificate(ctx context.Context, issuerURL string, opts *AIAFetchOptions) (*CertBlock, error) {
+	u, err := url.Parse(issuerURL)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error parsing issuing certificate URL %q: {{err}}", issuerURL), err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme in issuing certificate URL %q", issuerURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := opts.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error fetching issuing certificate from %q: {{err}}", issuerURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d fetching issuing certificate from %q", resp.StatusCode, issuerURL)
+	}
+
+	// Read one byte more than allowed to detect oversized responses
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, opts.MaxCertificateSize+1))
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error reading issuing certificate from %q: {{err}}", issuerURL), err)
+	}
+	if int64(len(body)) > opts.MaxCertificateSize {
+		return nil, fmt.Errorf("issuing certificate from %q exceeds the maximum size of %d bytes", issuerURL, opts.MaxCertificateSize)
+	}
+
+	// Certificates are commonly served DER-encoded, but some servers use PEM
+	if pemBlock, _ := pem.Decode(body); pemBlock != nil {
+		body = pemBlock.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(body)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error parsing issuing certificate from %q: {{err}}", issuerURL), err)
+	}
+
+	return &CertBlock{
+		Certificate: cert,
+		Bytes:       body,
+	}, nil
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignatureFrom(cert) == nil
+}
@@ -0,0 +1,143 @@
+package certutil
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	mathrand "math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createAIATestCert(t *testing.T, cn string, isCA bool, aiaURL string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjKeyID, err := GetSubjKeyID(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: cn,
+		},
+		SubjectKeyId:          subjKeyID,
+		SerialNumber:          big.NewInt(mathrand.Int63()),
+		NotBefore:             time.Now().Add(-30 * time.Second),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+	if aiaURL != "" {
+		template.IssuingCertificateURL = []string{aiaURL}
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestCompleteChainFromAIA(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	rootCert, rootKey := createAIATestCert(t, "root", true, "", nil, nil)
+	intCert, intKey := createAIATestCert(t, "int", true, server.URL+"/root", rootCert, rootKey)
+	leafCert, leafKey := createAIATestCert(t, "leaf", false, server.URL+"/int", intCert, intKey)
+	otherCert, _ := createAIATestCert(t, "other", true, "", nil, nil)
+
+	// Serve DER and PEM to exercise both encodings
+	mux.HandleFunc("/int", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(intCert.Raw)
+	})
+	mux.HandleFunc("/root", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootCert.Raw}))
+	})
+	mux.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(otherCert.Raw)
+	})
+
+	newBundle := func(cert *x509.Certificate) *ParsedCertBundle {
+		return &ParsedCertBundle{
+			Certificate:      cert,
+			CertificateBytes: cert.Raw,
+			PrivateKey:       leafKey,
+			PrivateKeyType:   ECPrivateKey,
+		}
+	}
+
+	bundle := newBundle(leafCert)
+	added, err := CompleteChainFromAIA(context.Background(), bundle, &AIAFetchOptions{Client: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 || len(bundle.CAChain) != 2 {
+		t.Fatalf("expected 2 fetched certificates, got %d (chain length %d)", added, len(bundle.CAChain))
+	}
+	if !bundle.CAChain[0].Certificate.Equal(intCert) || !bundle.CAChain[1].Certificate.Equal(rootCert) {
+		t.Fatal("fetched chain is not in trust path order")
+	}
+	if err := bundle.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An already complete chain should not fetch anything
+	added, err = CompleteChainFromAIA(context.Background(), bundle, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 0 {
+		t.Fatalf("expected no fetched certificates, got %d", added)
+	}
+
+	// Honor the maximum chain length
+	bundle = newBundle(leafCert)
+	if _, err := CompleteChainFromAIA(context.Background(), bundle, &AIAFetchOptions{MaxChainLength: 1}); err == nil {
+		t.Fatal("expected error exceeding the maximum chain length")
+	}
+	if len(bundle.CAChain) != 1 {
+		t.Fatalf("expected partial chain of length 1, got %d", len(bundle.CAChain))
+	}
+
+	// Honor the maximum certificate size
+	bundle = newBundle(leafCert)
+	if _, err := CompleteChainFromAIA(context.Background(), bundle, &AIAFetchOptions{MaxCertificateSize: 16}); err == nil {
+		t.Fatal("expected error exceeding the maximum certificate size")
+	}
+
+	// Reject certificates that did not sign the previous certificate
+	mismatchCert, _ := createAIATestCert(t, "mismatch", false, server.URL+"/other", intCert, intKey)
+	if _, err := CompleteChainFromAIA(context.Background(), newBundle(mismatchCert), nil); err == nil {
+		t.Fatal("expected error when the fetched certificate is not the issuer")
+	}
+
+	// Only HTTP(S) URLs are followed
+	fileCert, _ := createAIATestCert(t, "file", false, "file:///etc/passwd", intCert, intKey)
+	if _, err := CompleteChainFromAIA(context.Background(), newBundle(fileCert), nil); err == nil {
+		t.Fatal("expected error with an unsupported URL scheme")
+	}
+}
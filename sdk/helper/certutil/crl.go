@@ -0,0 +1,322 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// CRLPEMType is the PEM block type of CRLs
+const CRLPEMType = "X509 CRL"
+
+// ParseCRL takes a DER or PEM encoded CRL and parses it. Its signature isn't
+// checked; use VerifyCRL for that.
+func ParseCRL(data []byte) (*pkix.CertificateList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != CRLPEMType {
+			return nil, errutil.UserError{Err: fmt.Sprintf("unexpected PEM block type %q in crl", block.Type)}
+		}
+		data = block.Bytes
+	}
+	if len(data) == 0 {
+		return nil, errutil.UserError{Err: "empty crl"}
+	}
+
+	crl, err := x509.ParseDERCRL(data)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("unable to parse crl: %v", err)}
+	}
+	return crl, nil
+}
+
+// VerifyCRL checks the signature of the CRL against the CA certificates of
+// the certificate path of the bundle, returning the one that issued it
+func (p *ParsedCertBundle) VerifyCRL(crl *pkix.CertificateList) (*x509.Certificate, error) {
+	if crl == nil {
+		return nil, errutil.UserError{Err: "no crl given"}
+	}
+
+	for _, certBlock := range p.GetCertificatePath() {
+		cert := certBlock.Certificate
+		if cert == nil || !cert.IsCA {
+			continue
+		}
+		if err := cert.CheckCRLSignature(crl); err == nil {
+			return cert, nil
+		}
+	}
+	return nil, errutil.UserError{Err: "crl is not signed by a ca certificate of the bundle"}
+}
+
+// IsRevoked checks whether the certificate of the bundle is listed in the CRL.
+// The CRL must be signed by the issuer of the certificate, which must be part
+// of the CA chain.
+func (p *ParsedCertBundle) IsRevoked(crl *pkix.CertificateList) (bool, error) {
+	if p.Certificate == nil {
+		return false, errutil.UserError{Err: "no certificate found in the bundle"}
+	}
+
+	issuer, err := p.VerifyCRL(crl)
+	if err != nil {
+		return false, err
+	}
+	if err := p.Certificate.CheckSignatureFrom(issuer); err != nil {
+		return false, errutil.UserError{Err: fmt.Sprintf("crl issuer %q is not the issuer of the certificate", issuer.Subject.CommonName)}
+	}
+
+	return CRLContainsSerial(crl, p.Certificate.SerialNumber), nil
+}
+
+// CRLContainsSerial returns whether the serial number is listed in the CRL
+func CRLContainsSerial(crl *pkix.CertificateList, serial *big.Int) bool {
+	for _, revokedCert := range crl.TBSCertList.RevokedCertificates {
+		if revokedCert.SerialNumber != nil && revokedCert.SerialNumber.Cmp(serial) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	oidExtensionAuthorityKeyID = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtensionCRLNumber      = asn1.ObjectIdentifier{2, 5, 29, 20}
+
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidMGF1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 8}
+)
+
+// crlSignatureAlgorithm describes how CRLs are signed with one of the
+// x509.SignatureAlgorithm values
+type crlSignatureAlgorithm struct {
+	keyAlgorithm x509.PublicKeyAlgorithm
+	oid          asn1.ObjectIdentifier
+	hash         crypto.Hash
+	pss          bool
+
+	// nullParams is set for the algorithms with NULL parameters
+	nullParams bool
+}
+
+var crlSignatureAlgorithms = map[x509.SignatureAlgorithm]crlSignatureAlgorithm{
+	x509.SHA256WithRSA:    {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}, hash: crypto.SHA256, nullParams: true},
+	x509.SHA384WithRSA:    {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}, hash: crypto.SHA384, nullParams: true},
+	x509.SHA512WithRSA:    {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}, hash: crypto.SHA512, nullParams: true},
+	x509.SHA256WithRSAPSS: {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}, hash: crypto.SHA256, pss: true},
+	x509.SHA384WithRSAPSS: {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}, hash: crypto.SHA384, pss: true},
+	x509.SHA512WithRSAPSS: {keyAlgorithm: x509.RSA, oid: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}, hash: crypto.SHA512, pss: true},
+	x509.ECDSAWithSHA256:  {keyAlgorithm: x509.ECDSA, oid: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}, hash: crypto.SHA256},
+	x509.ECDSAWithSHA384:  {keyAlgorithm: x509.ECDSA, oid: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}, hash: crypto.SHA384},
+	x509.ECDSAWithSHA512:  {keyAlgorithm: x509.ECDSA, oid: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}, hash: crypto.SHA512},
+	x509.PureEd25519:      {keyAlgorithm: x509.Ed25519, oid: asn1.ObjectIdentifier{1, 3, 101, 112}},
+}
+
+// algorithmIdentifier returns the AlgorithmIdentifier of the signature
+// algorithm; RSA algorithms have NULL parameters and RSA-PSS ones their
+// hash, MGF1 with the same hash and a salt as long as the hash (RFC 4055)
+func (a crlSignatureAlgorithm) algorithmIdentifier() (pkix.AlgorithmIdentifier, error) {
+	ai := pkix.AlgorithmIdentifier{Algorithm: a.oid}
+	var hashOID asn1.ObjectIdentifier
+	switch a.hash {
+	case crypto.SHA256:
+		hashOID = oidSHA256
+	case crypto.SHA384:
+		hashOID = oidSHA384
+	case crypto.SHA512:
+		hashOID = oidSHA512
+	}
+
+	switch {
+	case a.pss:
+		hashAI := pkix.AlgorithmIdentifier{Algorithm: hashOID, Parameters: asn1.NullRawValue}
+		hashAIBytes, err := asn1.Marshal(hashAI)
+		if err != nil {
+			return ai, err
+		}
+		params, err := asn1.Marshal(struct {
+			Hash       pkix.AlgorithmIdentifier `asn1:"explicit,tag:0"`
+			MGF        pkix.AlgorithmIdentifier `asn1:"explicit,tag:1"`
+			SaltLength int                      `asn1:"explicit,tag:2"`
+		}{
+			Hash:       hashAI,
+			MGF:        pkix.AlgorithmIdentifier{Algorithm: oidMGF1, Parameters: asn1.RawValue{FullBytes: hashAIBytes}},
+			SaltLength: a.hash.Size(),
+		})
+		if err != nil {
+			return ai, err
+		}
+		ai.Parameters = asn1.RawValue{FullBytes: params}
+	case a.nullParams:
+		ai.Parameters = asn1.NullRawValue
+	}
+	return ai, nil
+}
+
+// CRLOptions holds the options of CreateCRL
+type CRLOptions struct {
+	// ThisUpdate is the issue time of the CRL; the current time if zero
+	ThisUpdate time.Time
+
+	// NextUpdate is the time by which the next CRL will be issued; it is
+	// required
+	NextUpdate time.Time
+
+	// Number is encoded into the CRL number extension; the extension is
+	// omitted if nil
+	Number *big.Int
+
+	// SignatureAlgorithm is the algorithm the CRL is signed with. If unknown,
+	// it is selected with SelectSignatureAlgorithm, which doesn't support
+	// Ed25519 keys; pass x509.PureEd25519 for those.
+	SignatureAlgorithm x509.SignatureAlgorithm
+}
+
+type tbsCertList struct {
+	Version             int `asn1:"optional,default:0"`
+	Signature           pkix.AlgorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time                 `asn1:"optional"`
+	RevokedCertificates []pkix.RevokedCertificate `asn1:"optional"`
+	Extensions          []pkix.Extension          `asn1:"tag:0,optional,explicit"`
+}
+
+type certList struct {
+	TBSCertList        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// CreateCRL returns a DER encoded v2 CRL listing the revoked certificates,
+// signed with the private key of the CA bundle. The CRL identifies its issuer
+// by the subject key ID of the CA certificate, if it has one.
+func CreateCRL(ca *ParsedCertBundle, revoked []pkix.RevokedCertificate, opts CRLOptions) ([]byte, error) {
+	if ca == nil || ca.Certificate == nil {
+		return nil, errutil.UserError{Err: "no ca certificate given to sign the crl with"}
+	}
+	if ca.PrivateKey == nil {
+		return nil, errutil.UserError{Err: "no private key given to sign the crl with"}
+	}
+
+	thisUpdate := opts.ThisUpdate
+	if thisUpdate.IsZero() {
+		thisUpdate = time.Now()
+	}
+	if opts.NextUpdate.IsZero() {
+		return nil, errutil.UserError{Err: "no next update time given for the crl"}
+	}
+	if opts.NextUpdate.Before(thisUpdate) {
+		return nil, errutil.UserError{Err: "the next update time of the crl is before its issue time"}
+	}
+	if opts.Number != nil && opts.Number.Sign() < 0 {
+		return nil, errutil.UserError{Err: "the crl number is negative"}
+	}
+
+	sigAlg := opts.SignatureAlgorithm
+	if sigAlg == x509.UnknownSignatureAlgorithm {
+		var err error
+		sigAlg, err = SelectSignatureAlgorithm(ca.PrivateKey, 0, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	alg, ok := crlSignatureAlgorithms[sigAlg]
+	if !ok {
+		return nil, errutil.UserError{Err: fmt.Sprintf("unsupported signature algorithm %v for crls", sigAlg)}
+	}
+	var keyAlgorithm x509.PublicKeyAlgorithm
+	switch ca.PrivateKey.Public().(type) {
+	case *rsa.PublicKey:
+		keyAlgorithm = x509.RSA
+	case *ecdsa.PublicKey:
+		keyAlgorithm = x509.ECDSA
+	case ed25519.PublicKey:
+		keyAlgorithm = x509.Ed25519
+	}
+	if keyAlgorithm != alg.keyAlgorithm {
+		return nil, errutil.UserError{Err: fmt.Sprintf("signature algorithm %v does not match the %v key of the ca", sigAlg, keyAlgorithm)}
+	}
+	signatureAlgorithm, err := alg.algorithmIdentifier()
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding the signature algorithm of the crl: %v", err)}
+	}
+
+	var extensions []pkix.Extension
+	if len(ca.Certificate.SubjectKeyId) > 0 {
+		akid, err := asn1.Marshal(struct {
+			ID []byte `asn1:"optional,tag:0"`
+		}{ca.Certificate.SubjectKeyId})
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding the authority key id of the crl: %v", err)}
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionAuthorityKeyID, Value: akid})
+	}
+	if opts.Number != nil {
+		number, err := asn1.Marshal(opts.Number)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding the crl number: %v", err)}
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionCRLNumber, Value: number})
+	}
+
+	// Revocation times are encoded in UTC, as the issue and update times. The
+	// list is omitted when empty.
+	var revokedUTC []pkix.RevokedCertificate
+	for _, rc := range revoked {
+		if rc.SerialNumber == nil {
+			return nil, errutil.UserError{Err: "revoked certificate without a serial number"}
+		}
+		rc.RevocationTime = rc.RevocationTime.UTC()
+		revokedUTC = append(revokedUTC, rc)
+	}
+
+	tbs, err := asn1.Marshal(tbsCertList{
+		Version:             1,
+		Signature:           signatureAlgorithm,
+		Issuer:              asn1.RawValue{FullBytes: ca.Certificate.RawSubject},
+		ThisUpdate:          thisUpdate.UTC(),
+		NextUpdate:          opts.NextUpdate.UTC(),
+		RevokedCertificates: revokedUTC,
+		Extensions:          extensions,
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding the crl: %v", err)}
+	}
+
+	digest := tbs
+	var signerOpts crypto.SignerOpts = alg.hash
+	if alg.hash != 0 {
+		h := alg.hash.New()
+		h.Write(tbs)
+		digest = h.Sum(nil)
+	}
+	if alg.pss {
+		signerOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: alg.hash}
+	}
+	signature, err := ca.PrivateKey.Sign(rand.Reader, digest, signerOpts)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error signing the crl: %v", err)}
+	}
+
+	crlBytes, err := asn1.Marshal(certList{
+		TBSCertList:        asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: signatureAlgorithm,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding the crl: %v", err)}
+	}
+	return crlBytes, nil
+}
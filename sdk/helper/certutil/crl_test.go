@@ -0,0 +1,289 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestCRL(t *testing.T) {
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caKey, otherKey := newKey(), newKey()
+	ca := testCertBlock(t, caTemplate, caTemplate, caKey, caKey)
+	other := testCertBlock(t, caTemplate, caTemplate, otherKey, otherKey)
+
+	newLeaf := func(serial int64) *ParsedCertBundle {
+		leaf := testCertBlock(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "Leaf"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+		}, ca.Certificate, newKey(), caKey)
+		return &ParsedCertBundle{
+			Certificate:      leaf.Certificate,
+			CertificateBytes: leaf.Bytes,
+			CAChain:          []*CertBlock{ca},
+		}
+	}
+	revoked, valid := newLeaf(2), newLeaf(3)
+
+	crlBytes, err := ca.Certificate.CreateCRL(rand.Reader, caKey, []pkix.RevokedCertificate{
+		{SerialNumber: big.NewInt(2), RevocationTime: time.Now()},
+	}, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DER and PEM CRLs are parsed alike
+	if _, err := ParseCRL(crlBytes); err != nil {
+		t.Fatal(err)
+	}
+	crl, err := ParseCRL(pem.EncodeToMemory(&pem.Block{Type: CRLPEMType, Bytes: crlBytes}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{
+		nil,
+		[]byte("not a crl"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crlBytes}),
+	} {
+		if _, err := ParseCRL(data); err == nil {
+			t.Fatalf("expected error parsing %q", data)
+		}
+	}
+
+	issuer, err := revoked.VerifyCRL(crl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issuer != ca.Certificate {
+		t.Fatalf("bad issuer %q", issuer.Subject.CommonName)
+	}
+
+	isRevoked, err := revoked.IsRevoked(crl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRevoked {
+		t.Fatal("expected the certificate to be revoked")
+	}
+	isRevoked, err = valid.IsRevoked(crl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isRevoked {
+		t.Fatal("expected the certificate not to be revoked")
+	}
+
+	// CRLs of other CAs are rejected, even with the same name
+	otherCRLBytes, err := other.Certificate.CreateCRL(rand.Reader, otherKey, nil, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCRL, err := ParseCRL(otherCRLBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := revoked.IsRevoked(otherCRL); err == nil {
+		t.Fatal("expected error checking a crl of another ca")
+	}
+	revoked.CAChain = append(revoked.CAChain, other)
+	if _, err := revoked.IsRevoked(otherCRL); err == nil {
+		t.Fatal("expected error checking a crl not issued by the issuer of the certificate")
+	}
+}
+
+func TestCreateCRL(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCA := func(key crypto.Signer) *ParsedCertBundle {
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Root CA"},
+			SubjectKeyId:          []byte("root"),
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		ca := testCertBlock(t, template, template, key, key)
+		return &ParsedCertBundle{
+			Certificate:      ca.Certificate,
+			CertificateBytes: ca.Bytes,
+			PrivateKey:       key,
+		}
+	}
+	rsaCA, ecCA, edCA := newCA(rsaKey), newCA(ecKey), newCA(edKey)
+
+	thisUpdate := time.Now().Add(-time.Minute).Truncate(time.Second)
+	revoked := []pkix.RevokedCertificate{
+		{SerialNumber: big.NewInt(2), RevocationTime: thisUpdate},
+		{SerialNumber: big.NewInt(3), RevocationTime: thisUpdate},
+	}
+
+	for _, tc := range []struct {
+		ca       *ParsedCertBundle
+		alg      x509.SignatureAlgorithm
+		expected x509.SignatureAlgorithm
+	}{
+		{ca: rsaCA, expected: x509.SHA256WithRSA},
+		{ca: rsaCA, alg: x509.SHA512WithRSA, expected: x509.SHA512WithRSA},
+		{ca: rsaCA, alg: x509.SHA384WithRSAPSS, expected: x509.SHA384WithRSAPSS},
+		{ca: ecCA, expected: x509.ECDSAWithSHA384},
+		{ca: ecCA, alg: x509.ECDSAWithSHA256, expected: x509.ECDSAWithSHA256},
+		{ca: edCA, alg: x509.PureEd25519, expected: x509.PureEd25519},
+	} {
+		crlBytes, err := CreateCRL(tc.ca, revoked, CRLOptions{
+			ThisUpdate:         thisUpdate,
+			NextUpdate:         thisUpdate.Add(time.Hour),
+			Number:             big.NewInt(42),
+			SignatureAlgorithm: tc.alg,
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", tc.expected, err)
+		}
+		crl, err := ParseCRL(crlBytes)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.expected, err)
+		}
+		if err := tc.ca.Certificate.CheckCRLSignature(crl); err != nil {
+			t.Fatalf("%v: %v", tc.expected, err)
+		}
+		tbs := crl.TBSCertList
+		switch {
+		case !bytes.Equal(testMarshal(t, crl.SignatureAlgorithm), testMarshal(t, testAlgorithmIdentifier(t, tc.expected))):
+			t.Fatalf("expected signature algorithm %v, got %v", tc.expected, crl.SignatureAlgorithm.Algorithm)
+		case testCRLNumber(t, crl) == nil || testCRLNumber(t, crl).Int64() != 42:
+			t.Fatalf("%v: bad crl number %v", tc.expected, testCRLNumber(t, crl))
+		case !bytes.Equal(testAuthorityKeyID(t, crl), []byte("root")):
+			t.Fatalf("%v: bad authority key id %q", tc.expected, testAuthorityKeyID(t, crl))
+		case !bytes.Equal(testMarshal(t, tbs.Issuer), tc.ca.Certificate.RawSubject):
+			t.Fatalf("%v: bad issuer %v", tc.expected, tbs.Issuer)
+		case !tbs.ThisUpdate.Equal(thisUpdate) || !tbs.NextUpdate.Equal(thisUpdate.Add(time.Hour)):
+			t.Fatalf("%v: bad update times %s/%s", tc.expected, tbs.ThisUpdate, tbs.NextUpdate)
+		case len(tbs.RevokedCertificates) != 2 || tbs.RevokedCertificates[1].SerialNumber.Int64() != 3:
+			t.Fatalf("%v: bad revoked certificates %v", tc.expected, tbs.RevokedCertificates)
+		}
+	}
+
+	// Without a number nor revoked certificates
+	crlBytes, err := CreateCRL(ecCA, nil, CRLOptions{NextUpdate: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := ParseCRL(crlBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number := testCRLNumber(t, crl); number != nil {
+		t.Fatalf("expected no crl number extension, got %v", number)
+	}
+	if len(crl.TBSCertList.RevokedCertificates) != 0 {
+		t.Fatalf("expected no revoked certificates, got %v", crl.TBSCertList.RevokedCertificates)
+	}
+
+	for name, tc := range map[string]struct {
+		ca   *ParsedCertBundle
+		opts CRLOptions
+	}{
+		"no next update":      {ca: ecCA},
+		"next update past":    {ca: ecCA, opts: CRLOptions{NextUpdate: time.Now().Add(-time.Hour)}},
+		"no private key":      {ca: &ParsedCertBundle{Certificate: ecCA.Certificate}, opts: CRLOptions{NextUpdate: time.Now().Add(time.Hour)}},
+		"key mismatch":        {ca: ecCA, opts: CRLOptions{NextUpdate: time.Now().Add(time.Hour), SignatureAlgorithm: x509.SHA256WithRSA}},
+		"ed25519 without alg": {ca: edCA, opts: CRLOptions{NextUpdate: time.Now().Add(time.Hour)}},
+		"negative number":     {ca: ecCA, opts: CRLOptions{NextUpdate: time.Now().Add(time.Hour), Number: big.NewInt(-1)}},
+	} {
+		if _, err := CreateCRL(tc.ca, revoked, tc.opts); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func testMarshal(t *testing.T, val interface{}) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testAlgorithmIdentifier(t *testing.T, alg x509.SignatureAlgorithm) pkix.AlgorithmIdentifier {
+	t.Helper()
+	ai, err := crlSignatureAlgorithms[alg].algorithmIdentifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ai
+}
+
+// testCRLNumber returns the number of the CRL, or nil if it has none
+func testCRLNumber(t *testing.T, crl *pkix.CertificateList) *big.Int {
+	t.Helper()
+	for _, ext := range crl.TBSCertList.Extensions {
+		if ext.Id.Equal(oidExtensionCRLNumber) {
+			number := new(big.Int)
+			if _, err := asn1.Unmarshal(ext.Value, &number); err != nil {
+				t.Fatal(err)
+			}
+			return number
+		}
+	}
+	return nil
+}
+
+// testAuthorityKeyID returns the authority key ID of the CRL, or nil if it
+// has none
+func testAuthorityKeyID(t *testing.T, crl *pkix.CertificateList) []byte {
+	t.Helper()
+	for _, ext := range crl.TBSCertList.Extensions {
+		if ext.Id.Equal(oidExtensionAuthorityKeyID) {
+			var akid struct {
+				ID []byte `asn1:"optional,tag:0"`
+			}
+			if _, err := asn1.Unmarshal(ext.Value, &akid); err != nil {
+				t.Fatal(err)
+			}
+			return akid.ID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// CSRParameters holds the subject, subject alternative names and key of the
+// CSRs created by CreateCSR
+type CSRParameters struct {
+	Subject        pkix.Name
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// ExtraExtensions are requested along with the extensions of the SANs.
+	// A subjectAltName extension, e.g. one carrying other names, replaces
+	// the one built from the SANs above, and must include them.
+	ExtraExtensions []pkix.Extension
+
+	// PrivateKey signs the CSR. When nil, a key of KeyType and KeyBits is
+	// generated with GeneratePrivateKey.
+	PrivateKey crypto.Signer
+	KeyType    PrivateKeyType
+	KeyBits    int
+
+	// HashAlgorithm and UsePSS select the signature algorithm of the CSR,
+	// see SelectSignatureAlgorithm
+	HashAlgorithm crypto.Hash
+	UsePSS        bool
+}
+
+// CreateCSR creates a CSR for the given parameters, signed by their private
+// key or by a newly generated one, and returns it along with the key. Signers
+// other than RSA and EC private keys, e.g. those of HSMs, have the
+// ManagedPrivateKey type and are not marshalled into the bundle.
+func CreateCSR(params *CSRParameters) (*ParsedCSRBundle, error) {
+	if params == nil {
+		return nil, errutil.InternalError{Err: "no CSR parameters given"}
+	}
+
+	result := &ParsedCSRBundle{}
+	if params.PrivateKey == nil {
+		if err := GeneratePrivateKey(params.KeyType, params.KeyBits, result); err != nil {
+			return nil, err
+		}
+	} else if err := setCSRPrivateKey(result, params.PrivateKey); err != nil {
+		return nil, err
+	}
+
+	for _, email := range params.EmailAddresses {
+		if email == "" {
+			return nil, errutil.UserError{Err: "empty email address in CSR SANs"}
+		}
+	}
+	for _, ip := range params.IPAddresses {
+		if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+			return nil, errutil.UserError{Err: fmt.Sprintf("invalid IP address %v in CSR SANs", ip)}
+		}
+	}
+	for _, uri := range params.URIs {
+		if uri == nil || uri.String() == "" {
+			return nil, errutil.UserError{Err: "empty URI in CSR SANs"}
+		}
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:         params.Subject,
+		DNSNames:        params.DNSNames,
+		EmailAddresses:  params.EmailAddresses,
+		IPAddresses:     params.IPAddresses,
+		URIs:            params.URIs,
+		ExtraExtensions: params.ExtraExtensions,
+	}
+
+	csr, err := CreateCertificateRequest(template, result.PrivateKey, params.HashAlgorithm, params.UsePSS)
+	switch err.(type) {
+	case nil:
+	case errutil.UserError:
+		return nil, err
+	default:
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to create CSR: %v", err)}
+	}
+
+	result.CSRBytes = csr
+	result.CSR, err = x509.ParseCertificateRequest(csr)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse created CSR: %v", err)}
+	}
+
+	return result, nil
+}
+
+// setCSRPrivateKey sets the given key on the bundle, marshalled as
+// GeneratePrivateKey does
+func setCSRPrivateKey(result *ParsedCSRBundle, key crypto.Signer) error {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		result.SetParsedPrivateKey(k, RSAPrivateKey, x509.MarshalPKCS1PrivateKey(k))
+	case *ecdsa.PrivateKey:
+		keyBytes, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return errutil.InternalError{Err: fmt.Sprintf("error marshalling EC private key: %v", err)}
+		}
+		result.SetParsedPrivateKey(k, ECPrivateKey, keyBytes)
+	default:
+		result.SetParsedPrivateKey(key, ManagedPrivateKey, nil)
+	}
+	return nil
+}
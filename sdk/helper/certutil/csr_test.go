@@ -0,0 +1,174 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+func TestCreateCSR(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://example.com/service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extension := pkix.Extension{
+		Id:    asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2},
+		Value: []byte{0x0c, 0x03, 'f', 'o', 'o'},
+	}
+
+	newParams := func() *CSRParameters {
+		return &CSRParameters{
+			Subject:         pkix.Name{CommonName: "example.com", Organization: []string{"Example"}},
+			DNSNames:        []string{"example.com", "www.example.com"},
+			EmailAddresses:  []string{"admin@example.com"},
+			IPAddresses:     []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+			URIs:            []*url.URL{spiffe},
+			ExtraExtensions: []pkix.Extension{extension},
+		}
+	}
+
+	cases := map[string]struct {
+		modify  func(*CSRParameters)
+		keyType PrivateKeyType
+		sigAlg  x509.SignatureAlgorithm
+	}{
+		"generated rsa": {
+			modify:  func(p *CSRParameters) { p.KeyType = RSAPrivateKey },
+			keyType: RSAPrivateKey,
+			sigAlg:  x509.SHA256WithRSA,
+		},
+		"generated rsa pss": {
+			modify: func(p *CSRParameters) {
+				p.KeyType, p.HashAlgorithm, p.UsePSS = RSAPrivateKey, crypto.SHA384, true
+			},
+			keyType: RSAPrivateKey,
+			sigAlg:  x509.SHA384WithRSAPSS,
+		},
+		"generated ec": {
+			modify:  func(p *CSRParameters) { p.KeyType = ECPrivateKey },
+			keyType: ECPrivateKey,
+			sigAlg:  x509.ECDSAWithSHA256,
+		},
+		"given ec": {
+			modify:  func(p *CSRParameters) { p.PrivateKey = ecKey },
+			keyType: ECPrivateKey,
+			sigAlg:  x509.ECDSAWithSHA384,
+		},
+		"managed": {
+			modify:  func(p *CSRParameters) { p.PrivateKey = &testManagedSigner{key: ecKey} },
+			keyType: ManagedPrivateKey,
+			sigAlg:  x509.ECDSAWithSHA384,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			params := newParams()
+			tc.modify(params)
+
+			bundle, err := CreateCSR(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bundle.PrivateKeyType != tc.keyType {
+				t.Fatalf("bad private key type %s", bundle.PrivateKeyType)
+			}
+
+			csr := bundle.CSR
+			if csr.SignatureAlgorithm != tc.sigAlg {
+				t.Fatalf("bad signature algorithm %v", csr.SignatureAlgorithm)
+			}
+			if err := csr.CheckSignature(); err != nil {
+				t.Fatal(err)
+			}
+			if equal, err := ComparePublicKeys(csr.PublicKey, bundle.PrivateKey.Public()); err != nil || !equal {
+				t.Fatalf("CSR public key does not match the private key: %v", err)
+			}
+
+			expected := newParams()
+			if csr.Subject.CommonName != expected.Subject.CommonName || !reflect.DeepEqual(csr.Subject.Organization, expected.Subject.Organization) {
+				t.Fatalf("bad subject %v", csr.Subject)
+			}
+			if !reflect.DeepEqual(csr.DNSNames, expected.DNSNames) {
+				t.Fatalf("bad DNS names %v", csr.DNSNames)
+			}
+			if !reflect.DeepEqual(csr.EmailAddresses, expected.EmailAddresses) {
+				t.Fatalf("bad email addresses %v", csr.EmailAddresses)
+			}
+			if len(csr.IPAddresses) != 2 || !csr.IPAddresses[0].Equal(expected.IPAddresses[0]) || !csr.IPAddresses[1].Equal(expected.IPAddresses[1]) {
+				t.Fatalf("bad IP addresses %v", csr.IPAddresses)
+			}
+			if len(csr.URIs) != 1 || csr.URIs[0].String() != spiffe.String() {
+				t.Fatalf("bad URIs %v", csr.URIs)
+			}
+			found := false
+			for _, ext := range csr.Extensions {
+				if ext.Id.Equal(extension.Id) {
+					found = reflect.DeepEqual(ext.Value, extension.Value)
+				}
+			}
+			if !found {
+				t.Fatal("extra extension not requested by the CSR")
+			}
+
+			// Only software keys are part of the PEM bundle
+			cb, err := bundle.ToCSRBundle()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (cb.PrivateKey != "") != (tc.keyType != ManagedPrivateKey) {
+				t.Fatalf("unexpected private key in the CSR bundle: %q", cb.PrivateKey)
+			}
+			if tc.keyType != ManagedPrivateKey {
+				parsed, err := cb.ToParsedCSRBundle()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(parsed.CSRBytes, bundle.CSRBytes) || parsed.PrivateKeyType != tc.keyType {
+					t.Fatal("CSR bundle does not round trip")
+				}
+			}
+		})
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, modify := range map[string]func(*CSRParameters){
+		"weak key":      func(p *CSRParameters) { p.KeyType, p.KeyBits = RSAPrivateKey, 1024 },
+		"no key type":   func(p *CSRParameters) {},
+		"pss ec":        func(p *CSRParameters) { p.PrivateKey, p.UsePSS = ecKey, true },
+		"bad hash":      func(p *CSRParameters) { p.PrivateKey, p.HashAlgorithm = rsaKey, crypto.SHA1 },
+		"empty email":   func(p *CSRParameters) { p.PrivateKey, p.EmailAddresses = ecKey, []string{""} },
+		"bad IP":        func(p *CSRParameters) { p.PrivateKey, p.IPAddresses = ecKey, []net.IP{{127, 0, 1}} },
+		"empty URI":     func(p *CSRParameters) { p.PrivateKey, p.URIs = ecKey, []*url.URL{{}} },
+		"no parameters": nil,
+	} {
+		var params *CSRParameters
+		if modify != nil {
+			params = newParams()
+			modify(params)
+		}
+		_, err := CreateCSR(params)
+		switch err.(type) {
+		case errutil.UserError, errutil.InternalError:
+		default:
+			t.Fatalf("%s: expected an errutil error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// +build gofuzz
+
+package certutil
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// Fuzz is the entry point for go-fuzz. It feeds the input through every
+// user-facing parsing path and panics if any of them returns an error that is
+// not a UserError, since malformed input must never be reported as an
+// internal failure. To run it:
+//
+//	go-fuzz-build github.com/hashicorp/vault/sdk/helper/certutil
+//	go-fuzz -bin=certutil-fuzz.zip -workdir=test-fixtures/fuzz
+func Fuzz(data []byte) int {
+	input := string(data)
+	interesting := 0
+
+	check := func(err error) {
+		if err == nil {
+			interesting = 1
+			return
+		}
+		if _, ok := err.(errutil.UserError); !ok {
+			panic(fmt.Sprintf("non-user error returned for malformed input: %T: %v", err, err))
+		}
+	}
+
+	_, err := ParsePEMBundle(input)
+	check(err)
+
+	for _, cb := range []*CertBundle{
+		{Certificate: input},
+		{PrivateKey: input},
+		{CAChain: []string{input}},
+		{IssuingCA: input},
+	} {
+		_, err = cb.ToParsedCertBundle()
+		check(err)
+	}
+
+	for _, csrb := range []*CSRBundle{
+		{CSR: input},
+		{PrivateKey: input},
+	} {
+		_, err = csrb.ToParsedCSRBundle()
+		check(err)
+	}
+
+	return interesting
+}
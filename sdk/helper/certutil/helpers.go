@@ -4,17 +4,22 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/helper/errutil"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
@@ -103,14 +108,17 @@ func ParsePKIJSON(input []byte) (*ParsedCertBundle, error) {
 
 // ParsePEMBundle takes a string of concatenated PEM-format certificate
 // and private key values and decodes/parses them, checking validity along
-// the way. The first certificate must be the subject certificate and issuing
-// certificates may follow.  There must be at most one private key.
+// the way. The blocks may come in any order: the certificate matching the
+// private key is the subject certificate and the others make up its issuing
+// chain. Without a private key, the first certificate is the subject
+// certificate. There must be at most one private key.
 func ParsePEMBundle(pemBundle string) (*ParsedCertBundle, error) {
 	if len(pemBundle) == 0 {
 		return nil, errutil.UserError{Err: "empty pem bundle"}
 	}
 
 	pemBytes := []byte(pemBundle)
+	defer zeroizeBytes(pemBytes)
 	var pemBlock *pem.Block
 	parsedBundle := &ParsedCertBundle{}
 	var certPath []*CertBlock
@@ -138,7 +146,7 @@ func ParsePEMBundle(pemBundle string) (*ParsedCertBundle, error) {
 			parsedBundle.PrivateKeyFormat = PKCS1Block
 			parsedBundle.PrivateKeyBytes = pemBlock.Bytes
 			parsedBundle.PrivateKey = signer
-		} else if signer, err := x509.ParsePKCS8PrivateKey(pemBlock.Bytes); err == nil {
+		} else if signer, err := parsePKCS8PrivateKey(pemBlock.Bytes); err == nil {
 			parsedBundle.PrivateKeyFormat = PKCS8Block
 
 			if parsedBundle.PrivateKeyType != UnknownPrivateKey {
@@ -153,8 +161,15 @@ func ParsePEMBundle(pemBundle string) (*ParsedCertBundle, error) {
 				parsedBundle.PrivateKey = signer
 				parsedBundle.PrivateKeyType = ECPrivateKey
 				parsedBundle.PrivateKeyBytes = pemBlock.Bytes
+			case ed25519.PrivateKey:
+				parsedBundle.PrivateKey = signer
+				parsedBundle.PrivateKeyType = Ed25519PrivateKey
+				parsedBundle.PrivateKeyBytes = pemBlock.Bytes
 			}
 		} else if certificates, err := x509.ParseCertificates(pemBlock.Bytes); err == nil {
+			if len(certificates) == 0 {
+				return nil, errutil.UserError{Err: "no certificate found in PEM block"}
+			}
 			certPath = append(certPath, &CertBlock{
 				Certificate: certificates[0],
 				Bytes:       pemBlock.Bytes,
@@ -162,6 +177,14 @@ func ParsePEMBundle(pemBundle string) (*ParsedCertBundle, error) {
 		}
 	}
 
+	if parsedBundle.PrivateKey == nil && len(certPath) == 0 {
+		return nil, errutil.UserError{Err: "no private key or certificate found in pem bundle"}
+	}
+
+	certPath, err := orderCertPath(certPath, parsedBundle)
+	if err != nil {
+		return nil, err
+	}
 	for i, certBlock := range certPath {
 		if i == 0 {
 			parsedBundle.Certificate = certBlock.Certificate
@@ -178,35 +201,188 @@ func ParsePEMBundle(pemBundle string) (*ParsedCertBundle, error) {
 	return parsedBundle, nil
 }
 
-// GeneratePrivateKey generates a private key with the specified type and key bits
-func GeneratePrivateKey(keyType string, keyBits int, container ParsedPrivateKeyContainer) error {
-	var err error
-	var privateKeyType PrivateKeyType
+// orderCertPath orders the certificates of a bundle from the subject
+// certificate, the one matching the private key of the bundle if any, up to
+// the root. Certificates that aren't part of the trust path are left at the
+// end, for Verify to reject.
+func orderCertPath(certs []*CertBlock, parsedBundle *ParsedCertBundle) ([]*CertBlock, error) {
+	if len(certs) == 0 {
+		return certs, nil
+	}
+
+	// The subject certificate is the one matching the private key
+	leaf := 0
+	if parsedBundle.PrivateKey != nil {
+		leaf = -1
+		for i, cert := range certs {
+			// Keys of other types, e.g. of issuers, can't be compared and
+			// don't match
+			equal, err := ComparePublicKeys(cert.Certificate.PublicKey, parsedBundle.PrivateKey.Public())
+			if err == nil && equal {
+				leaf = i
+				break
+			}
+		}
+		if leaf == -1 {
+			return nil, errutil.UserError{Err: "no certificate of the bundle matches its private key"}
+		}
+	}
+
+	return sortCertPath(certs, leaf), nil
+}
+
+// SortCertificateChain orders certs, e.g. a certificate chain from an
+// external CA, from the subject certificate up to the root. The subject
+// certificate is the one issuing none of the others; issuers are found by
+// their key identifiers, or subjects, and their signatures. Certificates that
+// aren't part of the trust path of the subject certificate are left at the
+// end, in the given order.
+func SortCertificateChain(certs []*CertBlock) []*CertBlock {
+	if len(certs) == 0 {
+		return certs
+	}
+
+	leaf := 0
+	for i, cert := range certs {
+		issuing := false
+		for _, other := range certs {
+			if isIssuerOf(cert, other) {
+				issuing = true
+				break
+			}
+		}
+		if !issuing {
+			leaf = i
+			break
+		}
+	}
+
+	return sortCertPath(certs, leaf)
+}
+
+// sortCertPath orders certs from certs[leaf] up to the root, leaving the
+// certificates that aren't part of the trust path at the end
+func sortCertPath(certs []*CertBlock, leaf int) []*CertBlock {
+	ordered := []*CertBlock{certs[leaf]}
+	remaining := append(append([]*CertBlock{}, certs[:leaf]...), certs[leaf+1:]...)
+	for len(remaining) > 0 {
+		current := ordered[len(ordered)-1]
+		next := -1
+		for i, cert := range remaining {
+			if isIssuerOf(cert, current) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		ordered = append(ordered, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+
+	return append(ordered, remaining...)
+}
+
+// isIssuerOf returns whether issuer is the issuing certificate of cert,
+// excluding self-signed certificates. The signature of cert is checked too,
+// as subjects may be shared by unrelated CAs, e.g. when a CA is re-keyed.
+func isIssuerOf(issuer, cert *CertBlock) bool {
+	if bytes.Equal(issuer.Bytes, cert.Bytes) {
+		return false
+	}
+	if len(cert.Certificate.AuthorityKeyId) > 0 {
+		if !bytes.Equal(cert.Certificate.AuthorityKeyId, issuer.Certificate.SubjectKeyId) {
+			return false
+		}
+	} else if !bytes.Equal(cert.Certificate.RawIssuer, issuer.Certificate.RawSubject) {
+		return false
+	}
+	return cert.Certificate.CheckSignatureFrom(issuer.Certificate) == nil
+}
+
+const (
+	// DefaultRSAKeyBits is the size of RSA keys generated without a size
+	DefaultRSAKeyBits = 2048
+	// DefaultECKeyBits is the size of EC keys generated without a size,
+	// selecting the P-256 curve
+	DefaultECKeyBits = 256
+	// MinRSAKeyBits is the size below which RSA keys are rejected as unsafe
+	MinRSAKeyBits = 2048
+)
+
+// GetECCurve returns the NIST curve of EC keys of the given bit length
+func GetECCurve(keyBits int) (elliptic.Curve, error) {
+	switch keyBits {
+	case 224:
+		return elliptic.P224(), nil
+	case 256:
+		return elliptic.P256(), nil
+	case 384:
+		return elliptic.P384(), nil
+	case 521:
+		return elliptic.P521(), nil
+	}
+	return nil, errutil.UserError{Err: fmt.Sprintf("unsupported bit length for EC key: %d", keyBits)}
+}
+
+// ValidateKeyTypeLength checks that keys of the given type and bit length can
+// be generated, and returns the bit length, defaulted when zero to
+// DefaultRSAKeyBits or DefaultECKeyBits. Ed25519 keys have no bit length.
+func ValidateKeyTypeLength(keyType PrivateKeyType, keyBits int) (int, error) {
+	switch keyType {
+	case RSAPrivateKey:
+		if keyBits == 0 {
+			return DefaultRSAKeyBits, nil
+		}
+		if keyBits < MinRSAKeyBits {
+			return 0, errutil.UserError{Err: fmt.Sprintf("RSA keys < %d bits are unsafe and not supported", MinRSAKeyBits)}
+		}
+		switch keyBits {
+		case 2048, 3072, 4096, 8192:
+			return keyBits, nil
+		}
+		return 0, errutil.UserError{Err: fmt.Sprintf("unsupported bit length for RSA key: %d", keyBits)}
+	case ECPrivateKey:
+		if keyBits == 0 {
+			return DefaultECKeyBits, nil
+		}
+		if _, err := GetECCurve(keyBits); err != nil {
+			return 0, err
+		}
+		return keyBits, nil
+	case Ed25519PrivateKey:
+		if keyBits != 0 {
+			return 0, errutil.UserError{Err: fmt.Sprintf("unsupported bit length for Ed25519 key: %d", keyBits)}
+		}
+		return 0, nil
+	}
+	return 0, errutil.UserError{Err: fmt.Sprintf("unknown key type: %s", keyType)}
+}
+
+// GeneratePrivateKey generates a private key with the specified type and key
+// bits, validated and defaulted as by ValidateKeyTypeLength, and sets it on
+// the container
+func GeneratePrivateKey(keyType PrivateKeyType, keyBits int, container ParsedPrivateKeyContainer) error {
+	keyBits, err := ValidateKeyTypeLength(keyType, keyBits)
+	if err != nil {
+		return err
+	}
+
 	var privateKeyBytes []byte
 	var privateKey crypto.Signer
 
 	switch keyType {
-	case "rsa":
-		privateKeyType = RSAPrivateKey
+	case RSAPrivateKey:
 		privateKey, err = rsa.GenerateKey(rand.Reader, keyBits)
 		if err != nil {
 			return errutil.InternalError{Err: fmt.Sprintf("error generating RSA private key: %v", err)}
 		}
 		privateKeyBytes = x509.MarshalPKCS1PrivateKey(privateKey.(*rsa.PrivateKey))
-	case "ec":
-		privateKeyType = ECPrivateKey
-		var curve elliptic.Curve
-		switch keyBits {
-		case 224:
-			curve = elliptic.P224()
-		case 256:
-			curve = elliptic.P256()
-		case 384:
-			curve = elliptic.P384()
-		case 521:
-			curve = elliptic.P521()
-		default:
-			return errutil.UserError{Err: fmt.Sprintf("unsupported bit length for EC key: %d", keyBits)}
+	case ECPrivateKey:
+		curve, err := GetECCurve(keyBits)
+		if err != nil {
+			return err
 		}
 		privateKey, err = ecdsa.GenerateKey(curve, rand.Reader)
 		if err != nil {
@@ -216,11 +392,18 @@ func GeneratePrivateKey(keyType string, keyBits int, container ParsedPrivateKeyC
 		if err != nil {
 			return errutil.InternalError{Err: fmt.Sprintf("error marshalling EC private key: %v", err)}
 		}
-	default:
-		return errutil.UserError{Err: fmt.Sprintf("unknown key type: %s", keyType)}
+	case Ed25519PrivateKey:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return errutil.InternalError{Err: fmt.Sprintf("error generating Ed25519 private key: %v", err)}
+		}
+		privateKeyBytes, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return errutil.InternalError{Err: fmt.Sprintf("error marshalling Ed25519 private key: %v", err)}
+		}
 	}
 
-	container.SetParsedPrivateKey(privateKey, privateKeyType, privateKeyBytes)
+	container.SetParsedPrivateKey(privateKey, keyType, privateKeyBytes)
 	return nil
 }
 
@@ -233,7 +416,98 @@ func GenerateSerialNumber() (*big.Int, error) {
 	return serial, nil
 }
 
-// ComparePublicKeys compares two public keys and returns true if they match
+// GenerateMemberBundle generates a fresh P-256 key and a certificate for it,
+// signed by the given cluster CA bundle, that is suitable for both server and
+// client authentication between members of a cluster. The first hostname, if
+// any, is used as the common name. The certificate is valid from slightly in
+// the past (to account for clock skew between members) until ttl from now.
+func GenerateMemberBundle(clusterCA *ParsedCertBundle, hostnames []string, ips []net.IP, ttl time.Duration) (*ParsedCertBundle, error) {
+	if clusterCA == nil || clusterCA.Certificate == nil || clusterCA.PrivateKey == nil {
+		return nil, errutil.InternalError{Err: "cluster CA bundle must contain a certificate and a private key"}
+	}
+	if !clusterCA.Certificate.IsCA {
+		return nil, errutil.UserError{Err: "cluster CA bundle certificate is not a certificate authority"}
+	}
+	if len(hostnames) == 0 && len(ips) == 0 {
+		return nil, errutil.UserError{Err: "at least one hostname or IP address must be provided"}
+	}
+	if ttl <= 0 {
+		return nil, errutil.UserError{Err: "ttl must be greater than zero"}
+	}
+
+	result := &ParsedCertBundle{}
+	if err := GeneratePrivateKey(ECPrivateKey, DefaultECKeyBits, result); err != nil {
+		return nil, err
+	}
+	result.PrivateKeyFormat = ECBlock
+
+	serialNumber, err := GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	subjKeyID, err := GetSubjKeyID(result.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var commonName string
+	if len(hostnames) > 0 {
+		commonName = hostnames[0]
+	}
+
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		SerialNumber: serialNumber,
+		SubjectKeyId: subjKeyID,
+		DNSNames:     hostnames,
+		IPAddresses:  ips,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+		KeyUsage:  x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement,
+		NotBefore: time.Now().Add(-30 * time.Second),
+		NotAfter:  time.Now().Add(ttl),
+	}
+
+	template.SignatureAlgorithm, err = SelectSignatureAlgorithm(clusterCA.PrivateKey, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, clusterCA.Certificate, result.PrivateKey.Public(), clusterCA.PrivateKey)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to create member certificate: %v", err)}
+	}
+
+	result.CertificateBytes = certBytes
+	result.Certificate, err = x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse created member certificate: %v", err)}
+	}
+
+	result.CAChain = append(result.CAChain, &CertBlock{
+		Certificate: clusterCA.Certificate,
+		Bytes:       clusterCA.CertificateBytes,
+	})
+	for _, caCert := range clusterCA.CAChain {
+		// Root CAs put themselves in their own chain
+		if bytes.Equal(caCert.Bytes, clusterCA.CertificateBytes) {
+			continue
+		}
+		result.CAChain = append(result.CAChain, caCert)
+	}
+
+	return result, nil
+}
+
+// ComparePublicKeys compares two public keys and returns true if they match.
+// Keys other than RSA, ECDSA and Ed25519 ones are compared with their Equal
+// method, as implemented by the public keys of the standard library, or else
+// with their PKIX encodings.
 func ComparePublicKeys(key1Iface, key2Iface crypto.PublicKey) (bool, error) {
 	switch key1Iface.(type) {
 	case *rsa.PublicKey:
@@ -270,9 +544,81 @@ func ComparePublicKeys(key1Iface, key2Iface crypto.PublicKey) (bool, error) {
 		}
 		return true, nil
 
+	case ed25519.PublicKey:
+		key1 := key1Iface.(ed25519.PublicKey)
+		key2, ok := key2Iface.(ed25519.PublicKey)
+		if !ok {
+			return compareGenericPublicKeys(key1Iface, key2Iface)
+		}
+		return bytes.Equal(key1, key2), nil
+
 	default:
+		return compareGenericPublicKeys(key1Iface, key2Iface)
+	}
+}
+
+// compareGenericPublicKeys compares public keys of types ComparePublicKeys
+// doesn't know about
+func compareGenericPublicKeys(key1Iface, key2Iface crypto.PublicKey) (bool, error) {
+	if key1Iface == nil || key2Iface == nil {
+		return false, fmt.Errorf("cannot compare key with type %T to key with type %T", key1Iface, key2Iface)
+	}
+
+	if reflect.TypeOf(key1Iface) == reflect.TypeOf(key2Iface) {
+		if key1, ok := key1Iface.(interface {
+			Equal(crypto.PublicKey) bool
+		}); ok {
+			return key1.Equal(key2Iface), nil
+		}
+	}
+
+	// Keys held as raw bytes, such as the Ed25519 keys of both crypto/ed25519
+	// and golang.org/x/crypto/ed25519, are equal when their bytes are
+	value1, value2 := reflect.ValueOf(key1Iface), reflect.ValueOf(key2Iface)
+	if isByteSlice(value1) && isByteSlice(value2) {
+		return bytes.Equal(value1.Bytes(), value2.Bytes()), nil
+	}
+
+	der1, err := x509.MarshalPKIXPublicKey(key1Iface)
+	if err != nil {
 		return false, fmt.Errorf("cannot compare key with type %T", key1Iface)
 	}
+	der2, err := x509.MarshalPKIXPublicKey(key2Iface)
+	if err != nil {
+		return false, fmt.Errorf("cannot compare key with type %T", key2Iface)
+	}
+	return bytes.Equal(der1, der2), nil
+}
+
+func isByteSlice(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// SplitCertificateChain splits PEM certificates concatenated leaf first, e.g.
+// the "certificate" field of PKI responses with chain_in_certificate set, into
+// the PEM leaf certificate and the PEM certificates of its chain
+func SplitCertificateChain(fullChain string) (string, []string, error) {
+	var certs []string
+	rest := []byte(fullChain)
+	for len(bytes.TrimSpace(rest)) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return "", nil, errutil.UserError{Err: "error decoding PEM certificate chain"}
+		}
+		if block.Type != "CERTIFICATE" {
+			return "", nil, errutil.UserError{Err: fmt.Sprintf("unexpected PEM block of type %q in certificate chain", block.Type)}
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return "", nil, errutil.UserError{Err: fmt.Sprintf("error parsing certificate of the chain: %v", err)}
+		}
+		certs = append(certs, strings.TrimSpace(string(pem.EncodeToMemory(block))))
+	}
+	if len(certs) == 0 {
+		return "", nil, errutil.UserError{Err: "no certificate found in the PEM certificate chain"}
+	}
+
+	return certs[0], certs[1:], nil
 }
 
 // PasrsePublicKeyPEM is used to parse RSA and ECDSA public keys from PEMs
@@ -0,0 +1,182 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPResponseOptions holds the contents of an OCSP response created with
+// CreateOCSPResponse
+type OCSPResponseOptions struct {
+	// Status is one of ocsp.Good, ocsp.Revoked or ocsp.Unknown
+	Status int
+
+	// RevokedAt and RevocationReason are only used by revoked statuses, for
+	// which RevokedAt is required
+	RevokedAt        time.Time
+	RevocationReason int
+
+	// ThisUpdate defaults to the current time. NextUpdate is optional;
+	// responses without one tell that newer information is always available.
+	ThisUpdate time.Time
+	NextUpdate time.Time
+
+	// IssuerHash is the hash identifying the issuer of the certificate; it
+	// defaults to SHA-1, as nearly universally used in OCSP
+	IssuerHash crypto.Hash
+
+	// SignatureAlgorithm defaults to the one the ocsp package picks for the
+	// key of the responder
+	SignatureAlgorithm x509.SignatureAlgorithm
+}
+
+// CreateOCSPRequest returns a DER encoded OCSP request for the status of the
+// certificate of the bundle, whose issuer is the first certificate of its CA
+// chain. A zero hash defaults to SHA-1.
+func (p *ParsedCertBundle) CreateOCSPRequest(hash crypto.Hash) ([]byte, error) {
+	issuer, err := p.getOCSPIssuer()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := ocsp.CreateRequest(p.Certificate, issuer, &ocsp.RequestOptions{Hash: hash})
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("error creating ocsp request: %v", err)}
+	}
+	return request, nil
+}
+
+// CreateOCSPResponse returns a DER encoded OCSP response about the certificate
+// with the given serial number, signed with the private key of the bundle. The
+// bundle is either the issuing CA itself or a responder it delegated to, whose
+// certificate is issued by the first certificate of the CA chain and has the
+// OCSP signing extended key usage. The certificate of a delegated responder is
+// embedded in the response, for clients to validate it.
+func (p *ParsedCertBundle) CreateOCSPResponse(serial *big.Int, opts OCSPResponseOptions) ([]byte, error) {
+	if p.Certificate == nil {
+		return nil, errutil.UserError{Err: "no certificate found in the responder bundle"}
+	}
+	if p.PrivateKey == nil {
+		return nil, errutil.UserError{Err: "no private key found in the responder bundle"}
+	}
+	if serial == nil {
+		return nil, errutil.UserError{Err: "no serial number given for the ocsp response"}
+	}
+
+	template := ocsp.Response{
+		Status:             opts.Status,
+		SerialNumber:       serial,
+		ThisUpdate:         opts.ThisUpdate,
+		NextUpdate:         opts.NextUpdate,
+		IssuerHash:         opts.IssuerHash,
+		SignatureAlgorithm: opts.SignatureAlgorithm,
+	}
+	if template.ThisUpdate.IsZero() {
+		template.ThisUpdate = time.Now()
+	}
+	if !template.NextUpdate.IsZero() && !template.NextUpdate.After(template.ThisUpdate) {
+		return nil, errutil.UserError{Err: "the next update of the ocsp response must be after its this update"}
+	}
+
+	switch opts.Status {
+	case ocsp.Good, ocsp.Unknown:
+	case ocsp.Revoked:
+		if opts.RevokedAt.IsZero() {
+			return nil, errutil.UserError{Err: "the revocation time is required for revoked statuses"}
+		}
+		template.RevokedAt = opts.RevokedAt
+		template.RevocationReason = opts.RevocationReason
+	default:
+		return nil, errutil.UserError{Err: fmt.Sprintf("unknown ocsp status %d", opts.Status)}
+	}
+
+	issuer := p.Certificate
+	if isDelegatedOCSPResponder(p.Certificate) {
+		if len(p.CAChain) == 0 || p.CAChain[0].Certificate == nil {
+			return nil, errutil.UserError{Err: "the issuer of the delegated ocsp responder must be the first certificate of the ca chain"}
+		}
+		issuer = p.CAChain[0].Certificate
+		if err := p.Certificate.CheckSignatureFrom(issuer); err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("the delegated ocsp responder is not issued by the first certificate of the ca chain: %v", err)}
+		}
+		template.Certificate = p.Certificate
+	} else if !p.Certificate.IsCA {
+		return nil, errutil.UserError{Err: "the ocsp responder must be a ca or have the ocsp signing extended key usage"}
+	}
+
+	response, err := ocsp.CreateResponse(issuer, p.Certificate, template, p.PrivateKey)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error creating ocsp response: %v", err)}
+	}
+	return response, nil
+}
+
+// VerifyOCSPResponse parses a DER encoded OCSP response about the certificate
+// of the bundle and validates it against the chain: it must be signed by the
+// issuer of the certificate, the first certificate of the CA chain, or by a
+// responder the issuer delegated to, and be current. The status of the
+// certificate is in the returned response; error responses of the responder
+// are returned as errors.
+func (p *ParsedCertBundle) VerifyOCSPResponse(data []byte) (*ocsp.Response, error) {
+	issuer, err := p.getOCSPIssuer()
+	if err != nil {
+		return nil, err
+	}
+
+	// The signatures of the response, and of the embedded responder
+	// certificate if any, are checked against the issuer while parsing
+	response, err := ocsp.ParseResponseForCert(data, p.Certificate, issuer)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("invalid ocsp response: %v", err)}
+	}
+
+	now := time.Now()
+	if responder := response.Certificate; responder != nil && !responder.Equal(issuer) {
+		if !isDelegatedOCSPResponder(responder) {
+			return nil, errutil.UserError{Err: fmt.Sprintf("the ocsp responder %q does not have the ocsp signing extended key usage", responder.Subject.CommonName)}
+		}
+		if now.Before(responder.NotBefore) || now.After(responder.NotAfter) {
+			return nil, errutil.UserError{Err: fmt.Sprintf("the certificate of the ocsp responder %q is not valid at %s", responder.Subject.CommonName, now.Format(time.RFC3339))}
+		}
+	}
+
+	if response.ThisUpdate.After(now) {
+		return nil, errutil.UserError{Err: fmt.Sprintf("the ocsp response is not valid before %s", response.ThisUpdate.Format(time.RFC3339))}
+	}
+	if !response.NextUpdate.IsZero() && response.NextUpdate.Before(now) {
+		return nil, errutil.UserError{Err: fmt.Sprintf("the ocsp response expired at %s", response.NextUpdate.Format(time.RFC3339))}
+	}
+
+	return response, nil
+}
+
+func (p *ParsedCertBundle) getOCSPIssuer() (*x509.Certificate, error) {
+	if p.Certificate == nil {
+		return nil, errutil.UserError{Err: "no certificate found in the bundle"}
+	}
+	if len(p.CAChain) == 0 || p.CAChain[0].Certificate == nil {
+		return nil, errutil.UserError{Err: "the issuer of the certificate must be the first certificate of the ca chain"}
+	}
+	return p.CAChain[0].Certificate, nil
+}
+
+// isDelegatedOCSPResponder returns whether the certificate is that of a
+// responder authorized by its issuer to sign OCSP responses, see RFC 6960
+// section 4.2.2.2
+func isDelegatedOCSPResponder(cert *x509.Certificate) bool {
+	if cert.IsCA {
+		return false
+	}
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageOCSPSigning {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOCSP(t *testing.T) {
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caKey, otherKey := newKey(), newKey()
+	ca := testCertBlock(t, caTemplate, caTemplate, caKey, caKey)
+	other := testCertBlock(t, caTemplate, caTemplate, otherKey, otherKey)
+
+	newBundle := func(serial int64, extKeyUsage []x509.ExtKeyUsage) *ParsedCertBundle {
+		key := newKey()
+		cert := testCertBlock(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "Leaf"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  extKeyUsage,
+		}, ca.Certificate, key, caKey)
+		return &ParsedCertBundle{
+			Certificate:      cert.Certificate,
+			CertificateBytes: cert.Bytes,
+			PrivateKey:       key,
+			CAChain:          []*CertBlock{ca},
+		}
+	}
+	leaf := newBundle(2, nil)
+	caResponder := &ParsedCertBundle{
+		Certificate:      ca.Certificate,
+		CertificateBytes: ca.Bytes,
+		PrivateKey:       caKey,
+	}
+	delegatedResponder := newBundle(3, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning})
+
+	for hash, expected := range map[crypto.Hash]crypto.Hash{0: crypto.SHA1, crypto.SHA256: crypto.SHA256} {
+		requestBytes, err := leaf.CreateOCSPRequest(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request, err := ocsp.ParseRequest(requestBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if request.SerialNumber.Cmp(leaf.Certificate.SerialNumber) != 0 {
+			t.Fatalf("bad serial number %v", request.SerialNumber)
+		}
+		if request.HashAlgorithm != expected {
+			t.Fatalf("bad hash algorithm %v", request.HashAlgorithm)
+		}
+		h := request.HashAlgorithm.New()
+		h.Write(ca.Certificate.RawSubject)
+		if !bytes.Equal(request.IssuerNameHash, h.Sum(nil)) {
+			t.Fatal("bad issuer name hash")
+		}
+	}
+	if _, err := (&ParsedCertBundle{Certificate: leaf.Certificate}).CreateOCSPRequest(0); err == nil {
+		t.Fatal("expected error creating a request without the issuer")
+	}
+
+	for name, tc := range map[string]struct {
+		responder  *ParsedCertBundle
+		opts       OCSPResponseOptions
+		embeds     bool
+		statusWant int
+	}{
+		"ca good": {
+			responder:  caResponder,
+			opts:       OCSPResponseOptions{Status: ocsp.Good, NextUpdate: time.Now().Add(time.Hour)},
+			statusWant: ocsp.Good,
+		},
+		"delegated revoked": {
+			responder: delegatedResponder,
+			opts: OCSPResponseOptions{
+				Status:           ocsp.Revoked,
+				RevokedAt:        time.Now().Add(-time.Minute).Truncate(time.Second),
+				RevocationReason: ocsp.KeyCompromise,
+				IssuerHash:       crypto.SHA256,
+			},
+			embeds:     true,
+			statusWant: ocsp.Revoked,
+		},
+		"ca unknown": {
+			responder:  caResponder,
+			opts:       OCSPResponseOptions{Status: ocsp.Unknown},
+			statusWant: ocsp.Unknown,
+		},
+	} {
+		responseBytes, err := tc.responder.CreateOCSPResponse(leaf.Certificate.SerialNumber, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		response, err := leaf.VerifyOCSPResponse(responseBytes)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		switch {
+		case response.Status != tc.statusWant:
+			t.Fatalf("%s: expected status %d, got %d", name, tc.statusWant, response.Status)
+		case tc.embeds != (response.Certificate != nil):
+			t.Fatalf("%s: unexpected embedded certificate %v", name, response.Certificate)
+		case tc.opts.Status == ocsp.Revoked && (!response.RevokedAt.Equal(tc.opts.RevokedAt) || response.RevocationReason != tc.opts.RevocationReason):
+			t.Fatalf("%s: bad revocation %s/%d", name, response.RevokedAt, response.RevocationReason)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		responder *ParsedCertBundle
+		opts      OCSPResponseOptions
+	}{
+		"no private key":       {responder: &ParsedCertBundle{Certificate: ca.Certificate}},
+		"not a responder":      {responder: newBundle(4, nil)},
+		"no revocation time":   {responder: caResponder, opts: OCSPResponseOptions{Status: ocsp.Revoked}},
+		"unknown status":       {responder: caResponder, opts: OCSPResponseOptions{Status: 42}},
+		"next update too soon": {responder: caResponder, opts: OCSPResponseOptions{ThisUpdate: time.Now(), NextUpdate: time.Now().Add(-time.Hour)}},
+		"delegated without ca": {responder: &ParsedCertBundle{Certificate: delegatedResponder.Certificate, PrivateKey: delegatedResponder.PrivateKey}},
+	} {
+		if _, err := tc.responder.CreateOCSPResponse(leaf.Certificate.SerialNumber, tc.opts); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	// Responses of other CAs, of responders not authorized by the issuer and
+	// outdated ones are rejected
+	otherResponder := &ParsedCertBundle{
+		Certificate:      other.Certificate,
+		CertificateBytes: other.Bytes,
+		PrivateKey:       otherKey,
+	}
+	otherResponse, err := otherResponder.CreateOCSPResponse(leaf.Certificate.SerialNumber, OCSPResponseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unauthorized := newBundle(5, nil)
+	unauthorizedResponse, err := ocsp.CreateResponse(ca.Certificate, unauthorized.Certificate, ocsp.Response{
+		SerialNumber: leaf.Certificate.SerialNumber,
+		ThisUpdate:   time.Now(),
+		Certificate:  unauthorized.Certificate,
+	}, unauthorized.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredResponse, err := caResponder.CreateOCSPResponse(leaf.Certificate.SerialNumber, OCSPResponseOptions{
+		ThisUpdate: time.Now().Add(-2 * time.Hour),
+		NextUpdate: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongSerialResponse, err := caResponder.CreateOCSPResponse(big.NewInt(42), OCSPResponseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"other ca":     otherResponse,
+		"unauthorized": unauthorizedResponse,
+		"expired":      expiredResponse,
+		"wrong serial": wrongSerialResponse,
+		"error":        ocsp.UnauthorizedErrorResponse,
+		"garbage":      []byte("not a response"),
+	} {
+		if _, err := leaf.VerifyOCSPResponse(data); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// PKCS11Mechanism is a PKCS#11 signing mechanism, CKM_*
+type PKCS11Mechanism uint
+
+const (
+	// PKCS11MechanismRSAPKCS is CKM_RSA_PKCS, signing DigestInfo structures
+	// with PKCS #1 v1.5 padding
+	PKCS11MechanismRSAPKCS PKCS11Mechanism = 0x0001
+	// PKCS11MechanismRSAPKCSPSS is CKM_RSA_PKCS_PSS, signing digests with
+	// PSS padding
+	PKCS11MechanismRSAPKCSPSS PKCS11Mechanism = 0x000d
+	// PKCS11MechanismECDSA is CKM_ECDSA, signing digests into the
+	// concatenation of the r and s values of the signature
+	PKCS11MechanismECDSA PKCS11Mechanism = 0x1041
+)
+
+// PKCS11PSSParams holds the CK_RSA_PKCS_PSS_PARAMS of PSS signatures, whose
+// MGF1 function uses the same hash as the digest
+type PKCS11PSSParams struct {
+	Hash       crypto.Hash
+	SaltLength int
+}
+
+// PKCS11Object is the handle of an object of a PKCS#11 token
+type PKCS11Object uint
+
+// PKCS11Module is the part of a PKCS#11 library used by PKCS11Signer,
+// e.g. implemented on top of the bindings of github.com/miekg/pkcs11, so that
+// certutil itself does not depend on cgo.
+type PKCS11Module interface {
+	// OpenSession opens a session on the token of the given slot and logs
+	// the user in with the given PIN
+	OpenSession(slot uint, pin string) (PKCS11Session, error)
+}
+
+// PKCS11Session is a logged in session of a PKCS#11 token. PKCS11Signer
+// serializes its calls, as PKCS#11 sessions are not safe for concurrent use.
+type PKCS11Session interface {
+	// FindKeyPair returns the handle of the private key with the given
+	// CKA_LABEL, and its public key, parsed from the matching public key
+	// object or certificate
+	FindKeyPair(label string) (PKCS11Object, crypto.PublicKey, error)
+
+	// Sign signs data with the given private key and mechanism. pss is only
+	// set with PKCS11MechanismRSAPKCSPSS.
+	Sign(key PKCS11Object, mechanism PKCS11Mechanism, pss *PKCS11PSSParams, data []byte) ([]byte, error)
+
+	// Close logs out and closes the session
+	Close() error
+}
+
+// PKCS11Config locates the private key of a PKCS11Signer
+type PKCS11Config struct {
+	Slot     uint   `json:"slot" mapstructure:"slot"`
+	PIN      string `json:"pin" mapstructure:"pin"`
+	KeyLabel string `json:"key_label" mapstructure:"key_label"`
+}
+
+// PKCS11Signer is a crypto.Signer whose RSA or ECDSA private key remains in a
+// PKCS#11 token, e.g. an HSM. It can be set as the managed key of bundles,
+// see CertBundleParseOptions.ManagedKey, to issue certificates and serve TLS
+// connections with the key.
+type PKCS11Signer struct {
+	l       sync.Mutex
+	session PKCS11Session
+	key     PKCS11Object
+	public  crypto.PublicKey
+}
+
+var _ crypto.Signer = (*PKCS11Signer)(nil)
+
+// NewPKCS11Signer opens a session on the token of the configured slot of the
+// given module and returns a signer for the private key with the configured
+// label. The session is held until the signer is closed.
+func NewPKCS11Signer(module PKCS11Module, config PKCS11Config) (*PKCS11Signer, error) {
+	if module == nil {
+		return nil, errutil.InternalError{Err: "no PKCS#11 module given"}
+	}
+	if config.KeyLabel == "" {
+		return nil, errutil.UserError{Err: "the label of the PKCS#11 key must be set"}
+	}
+
+	session, err := module.OpenSession(config.Slot, config.PIN)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error opening PKCS#11 session on slot %d: {{err}}", config.Slot), err)
+	}
+
+	key, public, err := session.FindKeyPair(config.KeyLabel)
+	if err != nil {
+		session.Close()
+		return nil, errwrap.Wrapf(fmt.Sprintf("error finding PKCS#11 key %q: {{err}}", config.KeyLabel), err)
+	}
+	switch public.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		session.Close()
+		return nil, errutil.UserError{Err: fmt.Sprintf("unsupported type %T of PKCS#11 key %q", public, config.KeyLabel)}
+	}
+
+	return &PKCS11Signer{
+		session: session,
+		key:     key,
+		public:  public,
+	}, nil
+}
+
+// Public returns the public key of the signer
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign signs the given digest in the token. RSA keys produce PSS signatures
+// when opts is a *rsa.PSSOptions, and PKCS #1 v1.5 signatures otherwise.
+func (s *PKCS11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if hash != 0 && len(digest) != hash.Size() {
+		return nil, fmt.Errorf("digest length %d does not match the size %d of the hash", len(digest), hash.Size())
+	}
+
+	switch public := s.public.(type) {
+	case *rsa.PublicKey:
+		if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+			if hash == 0 {
+				return nil, errors.New("PSS signatures require a hash")
+			}
+			params := &PKCS11PSSParams{
+				Hash:       hash,
+				SaltLength: pssSaltLength(public, hash, pssOpts.SaltLength),
+			}
+			return s.sign(PKCS11MechanismRSAPKCSPSS, params, digest)
+		}
+
+		data := digest
+		if hash != 0 {
+			prefix, ok := pkcs1HashPrefixes[hash]
+			if !ok {
+				return nil, fmt.Errorf("unsupported hash %v", hash)
+			}
+			data = append(append(make([]byte, 0, len(prefix)+len(digest)), prefix...), digest...)
+		}
+		return s.sign(PKCS11MechanismRSAPKCS, nil, data)
+
+	case *ecdsa.PublicKey:
+		raw, err := s.sign(PKCS11MechanismECDSA, nil, digest)
+		if err != nil {
+			return nil, err
+		}
+		// PKCS#11 returns r and s padded to the size of the curve, while
+		// crypto.Signer callers expect an ASN.1 ECDSA-Sig-Value
+		size := (public.Curve.Params().BitSize + 7) / 8
+		if len(raw) != 2*size {
+			return nil, fmt.Errorf("invalid ECDSA signature length %d returned by PKCS#11 token", len(raw))
+		}
+		return asn1.Marshal(struct {
+			R, S *big.Int
+		}{
+			R: new(big.Int).SetBytes(raw[:size]),
+			S: new(big.Int).SetBytes(raw[size:]),
+		})
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T", s.public)
+}
+
+func (s *PKCS11Signer) sign(mechanism PKCS11Mechanism, pss *PKCS11PSSParams, data []byte) ([]byte, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.session == nil {
+		return nil, errors.New("PKCS#11 session is closed")
+	}
+	sig, err := s.session.Sign(s.key, mechanism, pss, data)
+	if err != nil {
+		return nil, errwrap.Wrapf("error signing with PKCS#11 key: {{err}}", err)
+	}
+	return sig, nil
+}
+
+// Close closes the session of the signer, which cannot sign afterwards
+func (s *PKCS11Signer) Close() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.session == nil {
+		return nil
+	}
+	err := s.session.Close()
+	s.session = nil
+	return err
+}
+
+// pssSaltLength returns the explicit salt length PKCS#11 tokens expect for
+// the salt length of rsa.PSSOptions
+func pssSaltLength(public *rsa.PublicKey, hash crypto.Hash, saltLength int) int {
+	switch saltLength {
+	case rsa.PSSSaltLengthAuto:
+		// As crypto/rsa, use the largest salt the key allows
+		return (public.N.BitLen()-1+7)/8 - 2 - hash.Size()
+	case rsa.PSSSaltLengthEqualsHash:
+		return hash.Size()
+	}
+	return saltLength
+}
+
+// pkcs1HashPrefixes are the DER encoded DigestInfo prefixes of the digests
+// signed with CKM_RSA_PKCS, as in crypto/rsa
+var pkcs1HashPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA224: {0x30, 0x2d, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x04, 0x05, 0x00, 0x04, 0x1c},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
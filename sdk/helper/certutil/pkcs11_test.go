@@ -0,0 +1,179 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testPKCS11Module emulates a PKCS#11 token holding software keys
+type testPKCS11Module struct {
+	pin  string
+	keys map[string]crypto.Signer
+
+	closed bool
+}
+
+func (m *testPKCS11Module) OpenSession(slot uint, pin string) (PKCS11Session, error) {
+	if slot != 1 {
+		return nil, errors.New("CKR_SLOT_ID_INVALID")
+	}
+	if pin != m.pin {
+		return nil, errors.New("CKR_PIN_INCORRECT")
+	}
+	return m, nil
+}
+
+func (m *testPKCS11Module) FindKeyPair(label string) (PKCS11Object, crypto.PublicKey, error) {
+	key, ok := m.keys[label]
+	if !ok {
+		return 0, nil, errors.New("no such key")
+	}
+	return PKCS11Object(len(label)), key.Public(), nil
+}
+
+func (m *testPKCS11Module) Sign(object PKCS11Object, mechanism PKCS11Mechanism, pss *PKCS11PSSParams, data []byte) ([]byte, error) {
+	var key crypto.Signer
+	for label, k := range m.keys {
+		if PKCS11Object(len(label)) == object {
+			key = k
+		}
+	}
+
+	switch mechanism {
+	case PKCS11MechanismRSAPKCS:
+		return rsa.SignPKCS1v15(nil, key.(*rsa.PrivateKey), 0, data)
+	case PKCS11MechanismRSAPKCSPSS:
+		return rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), pss.Hash, data, &rsa.PSSOptions{SaltLength: pss.SaltLength})
+	case PKCS11MechanismECDSA:
+		ecKey := key.(*ecdsa.PrivateKey)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, data)
+		if err != nil {
+			return nil, err
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(sig[size-len(rBytes):size], rBytes)
+		copy(sig[2*size-len(sBytes):], sBytes)
+		return sig, nil
+	}
+	return nil, errors.New("CKR_MECHANISM_INVALID")
+}
+
+func (m *testPKCS11Module) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestPKCS11Signer(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := &testPKCS11Module{
+		pin: "1234",
+		keys: map[string]crypto.Signer{
+			"rsa-ca":   rsaKey,
+			"ecdsa-ca": ecKey,
+		},
+	}
+
+	cases := map[string]struct {
+		label  string
+		sigAlg x509.SignatureAlgorithm
+	}{
+		"rsa":     {"rsa-ca", x509.SHA256WithRSA},
+		"rsa pss": {"rsa-ca", x509.SHA384WithRSAPSS},
+		"ecdsa":   {"ecdsa-ca", x509.ECDSAWithSHA384},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewPKCS11Signer(module, PKCS11Config{Slot: 1, PIN: "1234", KeyLabel: tc.label})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer signer.Close()
+
+			// Self-sign a CA certificate in the token
+			template := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "Root CA"},
+				NotBefore:             time.Now().Add(-time.Minute),
+				NotAfter:              time.Now().Add(time.Hour),
+				KeyUsage:              x509.KeyUsageCertSign,
+				BasicConstraintsValid: true,
+				IsCA:                  true,
+				SignatureAlgorithm:    tc.sigAlg,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cert.CheckSignatureFrom(cert); err != nil {
+				t.Fatal(err)
+			}
+
+			// The signer is usable as the managed key of bundles
+			cb, err := (&ParsedCertBundle{
+				Certificate:      cert,
+				CertificateBytes: der,
+				PrivateKeyType:   ManagedPrivateKey,
+			}).ToCertBundle()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := cb.ToParsedCertBundleWithOptions(CertBundleParseOptions{ManagedKey: signer})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := parsed.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// Invalid configs
+	for name, config := range map[string]PKCS11Config{
+		"no label":  {Slot: 1, PIN: "1234"},
+		"bad slot":  {Slot: 2, PIN: "1234", KeyLabel: "rsa-ca"},
+		"bad pin":   {Slot: 1, PIN: "0000", KeyLabel: "rsa-ca"},
+		"bad label": {Slot: 1, PIN: "1234", KeyLabel: "missing"},
+	} {
+		if _, err := NewPKCS11Signer(module, config); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	// Closed signers cannot sign
+	module.closed = false
+	signer, err := NewPKCS11Signer(module, PKCS11Config{Slot: 1, PIN: "1234", KeyLabel: "ecdsa-ca"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !module.closed {
+		t.Fatal("session should be closed")
+	}
+	if _, err := signer.Sign(rand.Reader, make([]byte, 48), crypto.SHA384); err == nil {
+		t.Fatal("expected error signing with a closed signer")
+	}
+}
@@ -0,0 +1,101 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// ParsePKCS12Bundle takes a PKCS#12 (.pfx/.p12) container and decodes/parses
+// its private key and certificates into a ParsedCertBundle, checking validity
+// along the way. The certificate matching the private key is the subject
+// certificate; the others make up its issuing chain. There must be at most one
+// private key.
+//
+// Only containers protected with the legacy PKCS#12 algorithms (3DES or RC2
+// with SHA-1) are supported; OpenSSL 3 creates them with the -legacy flag.
+func ParsePKCS12Bundle(data []byte, password string) (*ParsedCertBundle, error) {
+	if len(data) == 0 {
+		return nil, errutil.UserError{Err: "empty pkcs#12 bundle"}
+	}
+
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		if err == pkcs12.ErrIncorrectPassword {
+			return nil, errutil.UserError{Err: "incorrect password for the pkcs#12 bundle"}
+		}
+		return nil, errutil.UserError{Err: fmt.Sprintf("error decoding pkcs#12 bundle: %v", err)}
+	}
+	defer func() {
+		for _, block := range blocks {
+			zeroizeBytes(block.Bytes)
+		}
+	}()
+
+	parsedBundle := &ParsedCertBundle{}
+	var certPath []*CertBlock
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "PRIVATE KEY":
+			if parsedBundle.PrivateKeyType != UnknownPrivateKey {
+				return nil, errutil.UserError{Err: "more than one private key given; provide only one private key in the bundle"}
+			}
+
+			// The keys are converted to their PKCS#1 or EC form
+			keyBytes := make([]byte, len(block.Bytes))
+			copy(keyBytes, block.Bytes)
+			if signer, err := x509.ParseECPrivateKey(keyBytes); err == nil {
+				parsedBundle.PrivateKeyFormat = ECBlock
+				parsedBundle.PrivateKeyType = ECPrivateKey
+				parsedBundle.PrivateKey = signer
+			} else if signer, err := x509.ParsePKCS1PrivateKey(keyBytes); err == nil {
+				parsedBundle.PrivateKeyFormat = PKCS1Block
+				parsedBundle.PrivateKeyType = RSAPrivateKey
+				parsedBundle.PrivateKey = signer
+			} else {
+				return nil, errutil.UserError{Err: "unable to parse the private key of the pkcs#12 bundle"}
+			}
+			parsedBundle.PrivateKeyBytes = keyBytes
+
+		case "CERTIFICATE":
+			// The certificate refers to the bytes it is parsed from, so it
+			// is parsed from a copy of the zeroized block
+			certBytes := make([]byte, len(block.Bytes))
+			copy(certBytes, block.Bytes)
+			certificate, err := x509.ParseCertificate(certBytes)
+			if err != nil {
+				return nil, errutil.UserError{Err: fmt.Sprintf("unable to parse a certificate of the pkcs#12 bundle: %v", err)}
+			}
+			certPath = append(certPath, &CertBlock{
+				Certificate: certificate,
+				Bytes:       certBytes,
+			})
+		}
+	}
+
+	if parsedBundle.PrivateKey == nil && len(certPath) == 0 {
+		return nil, errutil.UserError{Err: "no private key or certificate found in pkcs#12 bundle"}
+	}
+
+	certPath, err = orderCertPath(certPath, parsedBundle)
+	if err != nil {
+		return nil, err
+	}
+	for i, certBlock := range certPath {
+		if i == 0 {
+			parsedBundle.Certificate = certBlock.Certificate
+			parsedBundle.CertificateBytes = certBlock.Bytes
+		} else {
+			parsedBundle.CAChain = append(parsedBundle.CAChain, certBlock)
+		}
+	}
+
+	if err := parsedBundle.Verify(); err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("verification of parsed bundle failed: %s", err)}
+	}
+
+	return parsedBundle, nil
+}
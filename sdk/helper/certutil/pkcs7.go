@@ -0,0 +1,133 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+var (
+	oidPKCS7Data       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+// PKCS7PEMType is the PEM block type of PKCS#7 bundles, as written by OpenSSL
+const PKCS7PEMType = "PKCS7"
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// pkcs7SignedData is a degenerate SignedData, conveying certificates without
+// any content or signature
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      asn1.RawValue
+}
+
+// ParsePKCS7Certificates takes a PKCS#7 certificate-only bundle (.p7b/.p7c),
+// DER or PEM encoded, and returns its certificates in the order they are
+// encoded, e.g. to be used as the CAChain of a ParsedCertBundle. Signatures
+// and CRLs in the bundle, if any, are ignored.
+func ParsePKCS7Certificates(data []byte) ([]*CertBlock, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != PKCS7PEMType {
+			return nil, errutil.UserError{Err: fmt.Sprintf("unexpected PEM block type %q in pkcs#7 bundle", block.Type)}
+		}
+		data = block.Bytes
+	}
+
+	var contentInfo pkcs7ContentInfo
+	rest, err := asn1.Unmarshal(data, &contentInfo)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("error decoding pkcs#7 bundle: %v", err)}
+	}
+	if len(rest) != 0 {
+		return nil, errutil.UserError{Err: "trailing data after pkcs#7 bundle"}
+	}
+	if !contentInfo.ContentType.Equal(oidPKCS7SignedData) {
+		return nil, errutil.UserError{Err: fmt.Sprintf("unsupported pkcs#7 content type %s", contentInfo.ContentType)}
+	}
+
+	// The optional fields of SignedData are told apart by their tags, so they
+	// are walked one by one
+	var signedData asn1.RawValue
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("error decoding pkcs#7 signed data: %v", err)}
+	}
+	var certificates []byte
+	for fields := signedData.Bytes; len(fields) > 0; {
+		var field asn1.RawValue
+		fields, err = asn1.Unmarshal(fields, &field)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("error decoding pkcs#7 signed data: %v", err)}
+		}
+		// certificates [0] IMPLICIT CertificateSet OPTIONAL
+		if field.Class == asn1.ClassContextSpecific && field.Tag == 0 {
+			certificates = field.Bytes
+		}
+	}
+
+	parsed, err := x509.ParseCertificates(certificates)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("unable to parse the certificates of the pkcs#7 bundle: %v", err)}
+	}
+	if len(parsed) == 0 {
+		return nil, errutil.UserError{Err: "no certificate found in pkcs#7 bundle"}
+	}
+
+	certBlocks := make([]*CertBlock, 0, len(parsed))
+	for _, cert := range parsed {
+		certBlocks = append(certBlocks, &CertBlock{
+			Certificate: cert,
+			Bytes:       cert.Raw,
+		})
+	}
+	return certBlocks, nil
+}
+
+// ToPKCS7 returns the certificate of the bundle and its CA chain as a DER
+// encoded, degenerate PKCS#7 bundle, as exchanged by Microsoft CAs and SCEP
+// servers. Use pem.EncodeToMemory with PKCS7PEMType for the PEM encoding.
+func (p *ParsedCertBundle) ToPKCS7() ([]byte, error) {
+	if p.Certificate == nil {
+		return nil, errutil.UserError{Err: "no certificate found in the bundle"}
+	}
+
+	var certificates bytes.Buffer
+	for _, cert := range p.GetCertificatePath() {
+		certificates.Write(cert.Bytes)
+	}
+
+	signedData, err := asn1.Marshal(pkcs7SignedData{
+		Version: 1,
+		// An empty SET OF DigestAlgorithmIdentifier
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo: pkcs7ContentInfo{
+			ContentType: oidPKCS7Data,
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates.Bytes()},
+		// An empty SET OF SignerInfo
+		SignerInfos: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding pkcs#7 signed data: %v", err)}
+	}
+
+	contentInfo, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding pkcs#7 bundle: %v", err)}
+	}
+	return contentInfo, nil
+}
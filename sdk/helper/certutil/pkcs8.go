@@ -0,0 +1,372 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/hashicorp/errwrap"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+const (
+	// encryptedPKCS8Iterations is the PBKDF2 iteration count of the
+	// encrypted PKCS#8 keys written by Vault
+	encryptedPKCS8Iterations = 100000
+
+	// maxEncryptedPKCS8Iterations bounds the PBKDF2 iteration count of the
+	// encrypted PKCS#8 keys read, as the key is derived before the password
+	// can be checked
+	maxEncryptedPKCS8Iterations = 10000000
+)
+
+// ErrIncorrectPassword is returned when an encrypted private key can't be
+// decrypted with the given password
+var ErrIncorrectPassword = errors.New("incorrect password for the encrypted private key")
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo of RFC 5208
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params are the PBES2-params of RFC 8018
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the PBKDF2-params of RFC 8018. The pseudorandom function
+// defaults to HMAC-SHA1.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPEMBlock returns the decrypted block of a password protected private
+// key, either a legacy encrypted PEM block (with a DEK-Info header) or an
+// encrypted PKCS#8 key, and the block itself otherwise
+func decryptPEMBlock(block *pem.Block, password []byte) (*pem.Block, error) {
+	encryptedPKCS8 := BlockType(block.Type) == EncryptedPKCS8Block
+	if !encryptedPKCS8 && !x509.IsEncryptedPEMBlock(block) {
+		return block, nil
+	}
+	if len(password) == 0 {
+		return nil, errors.New("the private key is encrypted but no password was provided")
+	}
+
+	if encryptedPKCS8 {
+		der, err := decryptPKCS8PrivateKey(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{
+			Type:  string(PKCS8Block),
+			Bytes: der,
+		}, nil
+	}
+
+	der, err := x509.DecryptPEMBlock(block, password)
+	if err != nil {
+		if err == x509.IncorrectPasswordError {
+			return nil, ErrIncorrectPassword
+		}
+		return nil, err
+	}
+
+	// The padding check of legacy encrypted blocks misses some wrong
+	// passwords, which then yield a key failing to parse
+	switch BlockType(block.Type) {
+	case PKCS1Block:
+		_, err = x509.ParsePKCS1PrivateKey(der)
+	case ECBlock:
+		_, err = x509.ParseECPrivateKey(der)
+	case PKCS8Block:
+		_, err = parsePKCS8PrivateKey(der)
+	}
+	if err != nil {
+		zeroizeBytes(der)
+		return nil, ErrIncorrectPassword
+	}
+
+	return &pem.Block{
+		Type:  block.Type,
+		Bytes: der,
+	}, nil
+}
+
+// privateKeyInfo is the PrivateKeyInfo of RFC 5208
+type privateKeyInfo struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// parsePKCS8PrivateKey is x509.ParsePKCS8PrivateKey, also parsing the RSA keys
+// restricted to RSA-PSS signatures. The restriction itself isn't kept: the
+// signature algorithms of such keys must be selected with usePSS set.
+func parsePKCS8PrivateKey(der []byte) (interface{}, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err == nil {
+		return key, nil
+	}
+
+	var info privateKeyInfo
+	if _, asn1Err := asn1.Unmarshal(der, &info); asn1Err != nil || !info.Algo.Algorithm.Equal(oidRSASSAPSS) {
+		return nil, err
+	}
+	rsaKey, err := x509.ParsePKCS1PrivateKey(info.PrivateKey)
+	if err != nil {
+		return nil, errwrap.Wrapf("error parsing RSA-PSS private key embedded in PKCS#8: {{err}}", err)
+	}
+	return rsaKey, nil
+}
+
+// encryptPEMBlock returns an encrypted PKCS#8 block holding the private key
+// of the given block, which can be a PKCS#1, EC or PKCS#8 key
+func encryptPEMBlock(block *pem.Block, password []byte) (*pem.Block, error) {
+	var key interface{}
+	var err error
+	switch BlockType(block.Type) {
+	case PKCS1Block:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case ECBlock:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case PKCS8Block:
+		key, err = parsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroizeBytes(der)
+
+	encrypted, err := encryptPKCS8PrivateKey(der, password)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{
+		Type:  string(EncryptedPKCS8Block),
+		Bytes: encrypted,
+	}, nil
+}
+
+// encryptPKCS8PrivateKey returns an encrypted PKCS#8 key holding the given
+// PKCS#8 key, using PBES2 with PBKDF2-HMAC-SHA256 and AES-256-CBC
+func encryptPKCS8PrivateKey(der, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("a password is required to encrypt the private key")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2.Key(password, salt, encryptedPKCS8Iterations, 32, sha256.New)
+	defer zeroizeBytes(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plaintext := make([]byte, len(der), len(der)+padding)
+	copy(plaintext, der)
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	defer zeroizeBytes(plaintext)
+
+	encrypted := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plaintext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: encryptedPKCS8Iterations,
+		PRF: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: encrypted,
+	})
+}
+
+// decryptPKCS8PrivateKey returns the PKCS#8 key held by an encrypted PKCS#8
+// key. Only the PBES2 scheme is supported, with PBKDF2 and AES or Triple DES
+// in CBC mode, which is what OpenSSL produces by default.
+func decryptPKCS8PrivateKey(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("error parsing the encrypted PKCS#8 key: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after the encrypted PKCS#8 key")
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encrypted PKCS#8 key scheme %s, only PBES2 is supported", info.Algo.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("error parsing the PBES2 parameters: %v", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %s, only PBKDF2 is supported", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, fmt.Errorf("error parsing the PBKDF2 parameters: %v", err)
+	}
+	if kdfParams.IterationCount <= 0 {
+		return nil, errors.New("invalid PBKDF2 iteration count")
+	}
+	if kdfParams.IterationCount > maxEncryptedPKCS8Iterations {
+		return nil, fmt.Errorf("PBKDF2 iteration count %d exceeds the maximum of %d", kdfParams.IterationCount, maxEncryptedPKCS8Iterations)
+	}
+	prf, err := pbkdf2PRF(kdfParams.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newCipher, keyLen, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if kdfParams.KeyLength != 0 && kdfParams.KeyLength != keyLen {
+		return nil, fmt.Errorf("invalid PBKDF2 key length %d for the encryption scheme", kdfParams.KeyLength)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("error parsing the initialization vector: %v", err)
+	}
+
+	key := pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, keyLen, prf)
+	defer zeroizeBytes(key)
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, errors.New("invalid initialization vector length")
+	}
+	data := info.EncryptedData
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid encrypted PKCS#8 key length")
+	}
+
+	plaintext := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, data)
+
+	// A wrong password is usually caught by the padding check, and otherwise
+	// by the key failing to parse
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		zeroizeBytes(plaintext)
+		return nil, ErrIncorrectPassword
+	}
+	plaintext = plaintext[:len(plaintext)-padding]
+	if _, err := getPKCS8Type(plaintext); err != nil {
+		zeroizeBytes(plaintext)
+		return nil, ErrIncorrectPassword
+	}
+
+	return plaintext, nil
+}
+
+func pbkdf2PRF(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 pseudorandom function %s", oid)
+	}
+}
+
+func pbes2Cipher(oid asn1.ObjectIdentifier) (func([]byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported encryption scheme %s", oid)
+	}
+}
@@ -0,0 +1,193 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// oidRSASSAPSS identifies both RSA-PSS signatures and RSA keys restricted to
+// them, as generated by e.g. openssl genpkey -algorithm RSA-PSS
+var oidRSASSAPSS = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+
+// SelectSignatureAlgorithm returns the signature algorithm to use when
+// signing certificates, CSRs or CRLs with the given key. If hashAlg is zero a
+// hash matching the strength of the key is chosen: SHA-256 for RSA keys and
+// P-224/P-256 curves, SHA-384 for P-384 and SHA-512 for P-521. Otherwise
+// hashAlg must be one of SHA-256, SHA-384 or SHA-512. If usePSS is set, an
+// RSA-PSS algorithm is returned; this is only valid for RSA keys.
+func SelectSignatureAlgorithm(signer crypto.Signer, hashAlg crypto.Hash, usePSS bool) (x509.SignatureAlgorithm, error) {
+	if signer == nil {
+		return x509.UnknownSignatureAlgorithm, errutil.InternalError{Err: "no signing key given to select a signature algorithm for"}
+	}
+
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		if hashAlg == 0 {
+			hashAlg = crypto.SHA256
+		}
+		switch hashAlg {
+		case crypto.SHA256:
+			if usePSS {
+				return x509.SHA256WithRSAPSS, nil
+			}
+			return x509.SHA256WithRSA, nil
+		case crypto.SHA384:
+			if usePSS {
+				return x509.SHA384WithRSAPSS, nil
+			}
+			return x509.SHA384WithRSA, nil
+		case crypto.SHA512:
+			if usePSS {
+				return x509.SHA512WithRSAPSS, nil
+			}
+			return x509.SHA512WithRSA, nil
+		}
+
+	case *ecdsa.PublicKey:
+		if usePSS {
+			return x509.UnknownSignatureAlgorithm, errutil.UserError{Err: "PSS signatures are only supported with RSA keys"}
+		}
+		if hashAlg == 0 {
+			switch pub.Curve.Params().BitSize {
+			case 384:
+				hashAlg = crypto.SHA384
+			case 521:
+				hashAlg = crypto.SHA512
+			default:
+				hashAlg = crypto.SHA256
+			}
+		}
+		switch hashAlg {
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+
+	default:
+		return x509.UnknownSignatureAlgorithm, errutil.UserError{Err: fmt.Sprintf("unsupported signing key type %T", pub)}
+	}
+
+	return x509.UnknownSignatureAlgorithm, errutil.UserError{Err: fmt.Sprintf("unsupported hash algorithm %v for signature", hashAlg)}
+}
+
+// CreateCertificate creates a DER encoded certificate for the given public
+// key from the template, like x509.CreateCertificate, signed by signer as
+// parent. Unless the template sets one, the signature algorithm is selected
+// with SelectSignatureAlgorithm from hashAlg and usePSS. The signature is
+// checked before the certificate is returned, as signers such as HSMs may
+// silently fall back to another padding than the one asked for.
+func CreateCertificate(template, parent *x509.Certificate, pub crypto.PublicKey, signer crypto.Signer, hashAlg crypto.Hash, usePSS bool) ([]byte, error) {
+	tmpl := *template
+	if tmpl.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		var err error
+		tmpl.SignatureAlgorithm, err = SelectSignatureAlgorithm(signer, hashAlg, usePSS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if parent == template {
+		parent = &tmpl
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, &tmpl, parent, pub, signer)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, errwrap.Wrapf("error parsing created certificate: {{err}}", err)
+	}
+	if err := checkSignature(signer.Public(), cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, errwrap.Wrapf("error checking the signature of the created certificate: {{err}}", err)
+	}
+	return certBytes, nil
+}
+
+// CreateCertificateRequest creates a DER encoded CSR from the template, like
+// x509.CreateCertificateRequest, choosing and checking its signature as
+// CreateCertificate does
+func CreateCertificateRequest(template *x509.CertificateRequest, signer crypto.Signer, hashAlg crypto.Hash, usePSS bool) ([]byte, error) {
+	tmpl := *template
+	if tmpl.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		var err error
+		tmpl.SignatureAlgorithm, err = SelectSignatureAlgorithm(signer, hashAlg, usePSS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &tmpl, signer)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		return nil, errwrap.Wrapf("error parsing created CSR: {{err}}", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errwrap.Wrapf("error checking the signature of the created CSR: {{err}}", err)
+	}
+	return csrBytes, nil
+}
+
+// checkSignature checks that signature is a valid signature of signed with
+// the given algorithm and public key
+func checkSignature(pub crypto.PublicKey, algo x509.SignatureAlgorithm, signed, signature []byte) error {
+	return (&x509.Certificate{PublicKey: pub}).CheckSignature(algo, signed, signature)
+}
+
+// certificatePublicKey returns the public key of the certificate, including
+// the RSA keys restricted to RSA-PSS signatures which crypto/x509 leaves
+// unparsed
+func certificatePublicKey(cert *x509.Certificate) (crypto.PublicKey, error) {
+	if cert.PublicKey != nil {
+		return cert.PublicKey, nil
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, errwrap.Wrapf("error parsing the public key of the certificate: {{err}}", err)
+	}
+	if !spki.Algorithm.Algorithm.Equal(oidRSASSAPSS) {
+		return nil, fmt.Errorf("unsupported public key algorithm %v", spki.Algorithm.Algorithm)
+	}
+	return x509.ParsePKCS1PublicKey(spki.PublicKey.RightAlign())
+}
+
+// checkSignatureFrom checks the signature of the certificate against its
+// issuer, like x509.Certificate.CheckSignatureFrom, also when the issuer has
+// an RSA-PSS public key
+func checkSignatureFrom(cert, issuer *x509.Certificate) error {
+	if issuer.PublicKey != nil {
+		return cert.CheckSignatureFrom(issuer)
+	}
+
+	// Only the checks of CheckSignatureFrom holding for the issuers of
+	// chains are repeated here
+	if (issuer.Version == 3 && !issuer.BasicConstraintsValid) || (issuer.BasicConstraintsValid && !issuer.IsCA) {
+		return x509.ConstraintViolationError{}
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return x509.ConstraintViolationError{}
+	}
+	pub, err := certificatePublicKey(issuer)
+	if err != nil {
+		return x509.ErrUnsupportedAlgorithm
+	}
+	return checkSignature(pub, cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+}
@@ -0,0 +1,314 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestSelectSignatureAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		signer   crypto.Signer
+		hash     crypto.Hash
+		usePSS   bool
+		expected x509.SignatureAlgorithm
+		err      bool
+	}{
+		{"rsa default", rsaKey, 0, false, x509.SHA256WithRSA, false},
+		{"rsa sha384", rsaKey, crypto.SHA384, false, x509.SHA384WithRSA, false},
+		{"rsa sha512", rsaKey, crypto.SHA512, false, x509.SHA512WithRSA, false},
+		{"rsa pss default", rsaKey, 0, true, x509.SHA256WithRSAPSS, false},
+		{"rsa pss sha384", rsaKey, crypto.SHA384, true, x509.SHA384WithRSAPSS, false},
+		{"rsa pss sha512", rsaKey, crypto.SHA512, true, x509.SHA512WithRSAPSS, false},
+		{"rsa sha1", rsaKey, crypto.SHA1, false, x509.UnknownSignatureAlgorithm, true},
+		{"p256 default", p256Key, 0, false, x509.ECDSAWithSHA256, false},
+		{"p384 default", p384Key, 0, false, x509.ECDSAWithSHA384, false},
+		{"p521 default", p521Key, 0, false, x509.ECDSAWithSHA512, false},
+		{"p384 sha256", p384Key, crypto.SHA256, false, x509.ECDSAWithSHA256, false},
+		{"ec pss", p256Key, 0, true, x509.UnknownSignatureAlgorithm, true},
+		{"ec md5", p256Key, crypto.MD5, false, x509.UnknownSignatureAlgorithm, true},
+		{"nil signer", nil, 0, false, x509.UnknownSignatureAlgorithm, true},
+	}
+
+	for _, tc := range cases {
+		algo, err := SelectSignatureAlgorithm(tc.signer, tc.hash, tc.usePSS)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%s: expected error", tc.name)
+		case !tc.err && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case algo != tc.expected:
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, algo)
+		}
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SelectSignatureAlgorithm(edKey, 0, false); err == nil {
+		t.Fatal("expected error with an unsupported key type")
+	}
+}
+
+// testPKCS1v15Signer ignores the PSS options it is given, as some HSMs do
+type testPKCS1v15Signer struct {
+	key *rsa.PrivateKey
+}
+
+func (s *testPKCS1v15Signer) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *testPKCS1v15Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return rsa.SignPKCS1v15(rand, s.key, opts.HashFunc(), digest)
+}
+
+func testCATemplate(serial int64, cn string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+func TestCreateCertificate_PSS(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caTemplate := testCATemplate(1, "Root CA")
+	caBytes, err := CreateCertificate(caTemplate, caTemplate, caKey.Public(), caKey, crypto.SHA384, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caTemplate.SignatureAlgorithm != x509.UnknownSignatureAlgorithm {
+		t.Fatal("the template should not be modified")
+	}
+	ca, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca.SignatureAlgorithm != x509.SHA384WithRSAPSS {
+		t.Fatalf("bad signature algorithm %v", ca.SignatureAlgorithm)
+	}
+
+	certBytes, err := CreateCertificate(testCATemplate(2, "Intermediate CA"), ca, key.Public(), caKey, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.SignatureAlgorithm != x509.SHA256WithRSAPSS {
+		t.Fatalf("bad signature algorithm %v", cert.SignatureAlgorithm)
+	}
+
+	bundle := &ParsedCertBundle{
+		Certificate:      cert,
+		CertificateBytes: certBytes,
+		CAChain:          []*CertBlock{{Certificate: ca, Bytes: caBytes}},
+	}
+	bundle.SetParsedPrivateKey(key, RSAPrivateKey, x509.MarshalPKCS1PrivateKey(key))
+	if err := bundle.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	csrBytes, err := CreateCertificateRequest(&x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "Intermediate CA"},
+	}, key, crypto.SHA512, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csr.SignatureAlgorithm != x509.SHA512WithRSAPSS {
+		t.Fatalf("bad signature algorithm %v", csr.SignatureAlgorithm)
+	}
+
+	// Signatures that don't match the requested algorithm are caught
+	badSigner := &testPKCS1v15Signer{key: caKey}
+	if _, err := CreateCertificate(caTemplate, caTemplate, caKey.Public(), badSigner, 0, true); err == nil {
+		t.Fatal("expected error creating a certificate with a mismatched signature")
+	}
+	if _, err := CreateCertificateRequest(&x509.CertificateRequest{}, badSigner, 0, true); err == nil {
+		t.Fatal("expected error creating a CSR with a mismatched signature")
+	}
+}
+
+// testPSSKeyCertificate returns the DER bytes of a certificate like the one
+// created from the template, whose public key is restricted to RSA-PSS
+// signatures
+func testPSSKeyCertificate(t *testing.T, template, parent *x509.Certificate, key, parentKey *rsa.PrivateKey) []byte {
+	t.Helper()
+	template.SignatureAlgorithm = x509.SHA256WithRSAPSS
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cert struct {
+		TBSCertificate     asn1.RawValue
+		SignatureAlgorithm asn1.RawValue
+		SignatureValue     asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &cert); err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the subject public key info, after the version, serial number,
+	// signature, issuer, validity and subject
+	var tbs []byte
+	rest := cert.TBSCertificate.Bytes
+	for i := 0; len(rest) > 0; i++ {
+		var field asn1.RawValue
+		if rest, err = asn1.Unmarshal(rest, &field); err != nil {
+			t.Fatal(err)
+		}
+		if i != 6 {
+			tbs = append(tbs, field.FullBytes...)
+			continue
+		}
+		pub := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+		spki, err := asn1.Marshal(struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSASSAPSS},
+			PublicKey: asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tbs = append(tbs, spki...)
+	}
+	cert.TBSCertificate = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: tbs}
+	tbsBytes, err := asn1.Marshal(cert.TBSCertificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(tbsBytes)
+	sig, err := rsa.SignPSS(rand.Reader, parentKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.SignatureValue = asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)}
+	der, err = asn1.Marshal(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParsedCertBundle_PSSKeys(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caTemplate := testCATemplate(1, "Root CA")
+	caTemplate.SubjectKeyId = []byte{1, 2, 3, 4}
+	caBytes := testPSSKeyCertificate(t, caTemplate, caTemplate, caKey, caKey)
+	ca, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certBytes := testPSSKeyCertificate(t, testCATemplate(2, "Intermediate CA"), ca, key, caKey)
+
+	// An RSA-PSS PKCS#8 key, as written by openssl genpkey -algorithm RSA-PSS
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info privateKeyInfo
+	if _, err := asn1.Unmarshal(pkcs8Key, &info); err != nil {
+		t.Fatal(err)
+	}
+	info.Algo = pkix.AlgorithmIdentifier{Algorithm: oidRSASSAPSS}
+	if pkcs8Key, err = asn1.Marshal(info); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(pkcs8Key); err == nil {
+		t.Fatal("RSA-PSS keys should not be parsed by crypto/x509")
+	}
+
+	cb := &CertBundle{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key})),
+		CAChain:     []string{string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caBytes}))},
+	}
+	bundle, err := cb.ToParsedCertBundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.PrivateKeyType != RSAPrivateKey {
+		t.Fatalf("bad private key type %q", bundle.PrivateKeyType)
+	}
+	if err := bundle.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain is checked against the RSA-PSS key of the issuer, not only
+	// its key ID
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCABytes := testPSSKeyCertificate(t, caTemplate, caTemplate, otherKey, otherKey)
+	otherCA, err := x509.ParseCertificate(otherCABytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.CAChain = []*CertBlock{{Certificate: otherCA, Bytes: otherCABytes}}
+	if err := bundle.Verify(); err == nil {
+		t.Fatal("expected error verifying a chain with the wrong issuer")
+	}
+}
@@ -0,0 +1,252 @@
+package certutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// TLSConfigOptions holds the settings of the TLS configs returned by
+// GetTLSConfigWithOptions. Zero values keep the defaults of GetTLSConfig.
+type TLSConfigOptions struct {
+	// MinVersion defaults to tls.VersionTLS12, and MaxVersion to the highest
+	// version supported by crypto/tls, e.g. tls.VersionTLS13
+	MinVersion uint16
+	MaxVersion uint16
+
+	// CipherSuites, e.g. parsed with tlsutil.ParseCiphers, only apply up to
+	// TLS 1.2; the cipher suites of TLS 1.3 are not configurable
+	CipherSuites []uint16
+
+	CurvePreferences []tls.CurveID
+
+	// NextProtos lists the ALPN protocols, in order of preference
+	NextProtos []string
+
+	// ClientAuth sets how servers request and verify client certificates. It
+	// defaults to tls.VerifyClientCertIfGiven when there are client CAs, and
+	// to tls.NoClientCert otherwise.
+	ClientAuth *tls.ClientAuthType
+
+	// RootCAs and ClientCAs replace the pools against which clients verify
+	// servers and servers verify clients, e.g. when the issuer of the bundle
+	// is not the trust anchor of its peers. Otherwise, both pools hold the
+	// issuing CA of the bundle, unless DisableBundleCA is set, along with the
+	// PEM encoded CA certificates of ExtraCAPEM. Clients left without any
+	// root CA use the root CAs of the host.
+	RootCAs         *x509.CertPool
+	ClientCAs       *x509.CertPool
+	ExtraCAPEM      []byte
+	DisableBundleCA bool
+}
+
+func (o TLSConfigOptions) apply(tlsConfig *tls.Config) error {
+	if o.MinVersion != 0 {
+		tlsConfig.MinVersion = o.MinVersion
+	}
+	if o.MaxVersion != 0 {
+		if o.MaxVersion < tlsConfig.MinVersion {
+			return fmt.Errorf("max TLS version %#04x is lower than min TLS version %#04x", o.MaxVersion, tlsConfig.MinVersion)
+		}
+		tlsConfig.MaxVersion = o.MaxVersion
+	}
+	tlsConfig.CipherSuites = o.CipherSuites
+	tlsConfig.CurvePreferences = o.CurvePreferences
+	tlsConfig.NextProtos = o.NextProtos
+	return nil
+}
+
+// clientAuth returns the client authentication of servers, given whether they
+// have client CAs to verify client certificates against
+func (o TLSConfigOptions) clientAuth(hasCAPool bool) (tls.ClientAuthType, error) {
+	if o.ClientAuth == nil {
+		if hasCAPool {
+			return tls.VerifyClientCertIfGiven, nil
+		}
+		return tls.NoClientCert, nil
+	}
+	if *o.ClientAuth >= tls.VerifyClientCertIfGiven && !hasCAPool {
+		return tls.NoClientCert, errors.New("verifying client certificates requires client CAs")
+	}
+	return *o.ClientAuth, nil
+}
+
+// extraCAs returns the certificates of ExtraCAPEM
+func (o TLSConfigOptions) extraCAs() ([]*x509.Certificate, error) {
+	if len(o.ExtraCAPEM) == 0 {
+		return nil, nil
+	}
+
+	var certs []*x509.Certificate
+	rest := o.ExtraCAPEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errwrap.Wrapf("error parsing extra CA certificate: {{err}}", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found in the extra CA PEM")
+	}
+	return certs, nil
+}
+
+// trustPool returns the pool used to verify peers: override when set, or else
+// the pool of the issuing CA of the bundle, unless disabled, and extraCAs
+func (o TLSConfigOptions) trustPool(override *x509.CertPool, p *ParsedCertBundle, bundlePool *x509.CertPool, extraCAs []*x509.Certificate) *x509.CertPool {
+	switch {
+	case override != nil:
+		return override
+	case len(extraCAs) == 0 && o.DisableBundleCA:
+		return nil
+	case len(extraCAs) == 0:
+		return bundlePool
+	}
+
+	pool := x509.NewCertPool()
+	if !o.DisableBundleCA && len(p.CAChain) > 0 {
+		pool.AddCert(p.CAChain[0].Certificate)
+	}
+	for _, cert := range extraCAs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// ReloadableBundle holds a ParsedCertBundle that can be swapped at any time.
+// The TLS configs it returns look the bundle up on every handshake, so that
+// rotated certificates are used without restarting listeners or clients.
+type ReloadableBundle struct {
+	// state holds the *reloadableState of the current bundle
+	state atomic.Value
+}
+
+type reloadableState struct {
+	bundle *ParsedCertBundle
+	cert   *tls.Certificate
+	caPool *x509.CertPool
+}
+
+// NewReloadableBundle returns a ReloadableBundle holding the given bundle
+func NewReloadableBundle(p *ParsedCertBundle) (*ReloadableBundle, error) {
+	r := &ReloadableBundle{}
+	if err := r.SetBundle(p); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetBundle swaps the bundle used by the next handshakes; the established
+// connections are left alone
+func (r *ReloadableBundle) SetBundle(p *ParsedCertBundle) error {
+	if p == nil {
+		return errors.New("nil bundle")
+	}
+
+	tlsCert, caPool, err := p.getTLSCertificate()
+	if err != nil {
+		return err
+	}
+
+	r.state.Store(&reloadableState{
+		bundle: p,
+		cert:   tlsCert,
+		caPool: caPool,
+	})
+	return nil
+}
+
+// Bundle returns the current bundle
+func (r *ReloadableBundle) Bundle() *ParsedCertBundle {
+	return r.load().bundle
+}
+
+func (r *ReloadableBundle) load() *reloadableState {
+	return r.state.Load().(*reloadableState)
+}
+
+// GetTLSConfig returns a TLS config like ParsedCertBundle.GetTLSConfig, whose
+// certificate is that of the current bundle, set through the GetCertificate
+// and GetClientCertificate callbacks. Servers also verify the client
+// certificates given against the CA chain of the current bundle. The root CAs
+// of clients, however, are those of the bundle when the config is returned.
+func (r *ReloadableBundle) GetTLSConfig(usage TLSUsage) (*tls.Config, error) {
+	return r.GetTLSConfigWithOptions(usage, TLSConfigOptions{})
+}
+
+// GetTLSConfigWithOptions returns a TLS config like GetTLSConfig, with the
+// given options applied. A ClientAuth verifying client certificates fails the
+// handshakes while the current bundle has no CA chain.
+func (r *ReloadableBundle) GetTLSConfigWithOptions(usage TLSUsage, opts TLSConfigOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if err := opts.apply(tlsConfig); err != nil {
+		return nil, err
+	}
+	extraCAs, err := opts.extraCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	// clientCAs returns the client CAs of the given bundle state
+	clientCAs := func(state *reloadableState) *x509.CertPool {
+		return opts.trustPool(opts.ClientCAs, state.bundle, state.caPool, extraCAs)
+	}
+
+	if usage&TLSServer > 0 {
+		if _, err := opts.clientAuth(clientCAs(r.load()) != nil); err != nil {
+			return nil, err
+		}
+
+		tlsConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert := r.load().cert
+			if cert == nil {
+				return nil, errors.New("no certificate found in the bundle")
+			}
+			return cert, nil
+		}
+
+		serverConfig := tlsConfig.Clone()
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			caPool := clientCAs(r.load())
+			clientAuth, err := opts.clientAuth(caPool != nil)
+			if err != nil {
+				return nil, err
+			}
+			config := serverConfig.Clone()
+			config.ClientCAs = caPool
+			config.ClientAuth = clientAuth
+			return config, nil
+		}
+	}
+
+	if usage&TLSClient > 0 {
+		state := r.load()
+		tlsConfig.RootCAs = opts.trustPool(opts.RootCAs, state.bundle, state.caPool, extraCAs)
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			// Sending no certificate is left to the server to accept or not
+			cert := r.load().cert
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,436 @@
+package certutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReloadableBundle(t *testing.T) {
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caKey := newKey()
+	ca := testCertBlock(t, caTemplate, caTemplate, caKey, caKey)
+
+	newBundle := func(serial int64) *ParsedCertBundle {
+		key := newKey()
+		cert := testCertBlock(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+		}, ca.Certificate, key, caKey)
+		return &ParsedCertBundle{
+			Certificate:      cert.Certificate,
+			CertificateBytes: cert.Bytes,
+			PrivateKey:       key,
+			CAChain:          []*CertBlock{ca},
+		}
+	}
+
+	server, err := NewReloadableBundle(newBundle(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewReloadableBundle(newBundle(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig, err := server.GetTLSConfig(TLSServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig, err := client.GetTLSConfig(TLSClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig.ServerName = "localhost"
+
+	// handshake returns the serial numbers of the certificates of the server
+	// and of the client
+	handshake := func() (int64, int64) {
+		serverConn, clientConn := net.Pipe()
+		defer serverConn.Close()
+		defer clientConn.Close()
+
+		tlsServer := tls.Server(serverConn, serverConfig)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- tlsServer.Handshake()
+		}()
+		tlsClient := tls.Client(clientConn, clientConfig)
+		if err := tlsClient.Handshake(); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		serverCerts := tlsClient.ConnectionState().PeerCertificates
+		clientCerts := tlsServer.ConnectionState().PeerCertificates
+		if len(serverCerts) == 0 || len(clientCerts) == 0 {
+			t.Fatalf("expected both certificates, got %d and %d", len(serverCerts), len(clientCerts))
+		}
+		return serverCerts[0].SerialNumber.Int64(), clientCerts[0].SerialNumber.Int64()
+	}
+
+	if serverSerial, clientSerial := handshake(); serverSerial != 2 || clientSerial != 3 {
+		t.Fatalf("bad serial numbers %d and %d", serverSerial, clientSerial)
+	}
+
+	// Rotated bundles are used by the next handshakes with the same configs
+	if err := server.SetBundle(newBundle(4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetBundle(newBundle(5)); err != nil {
+		t.Fatal(err)
+	}
+	if serverSerial, clientSerial := handshake(); serverSerial != 4 || clientSerial != 5 {
+		t.Fatalf("bad serial numbers %d and %d after rotation", serverSerial, clientSerial)
+	}
+	if server.Bundle().Certificate.SerialNumber.Int64() != 4 {
+		t.Fatal("expected the rotated bundle")
+	}
+
+	if err := server.SetBundle(nil); err == nil {
+		t.Fatal("expected error setting a nil bundle")
+	}
+}
+
+func TestGetTLSConfigWithOptions(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert := testCertBlock(t, template, template, key, key)
+	withCA := &ParsedCertBundle{
+		Certificate:      cert.Certificate,
+		CertificateBytes: cert.Bytes,
+		PrivateKey:       key,
+		CAChain:          []*CertBlock{cert},
+	}
+	withoutCA := &ParsedCertBundle{
+		Certificate:      cert.Certificate,
+		CertificateBytes: cert.Bytes,
+		PrivateKey:       key,
+	}
+	requireAndVerify := tls.RequireAndVerifyClientCert
+	requestOnly := tls.RequestClientCert
+
+	tlsConfig, err := withCA.GetTLSConfig(TLSServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 || tlsConfig.MaxVersion != 0 || tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Fatalf("bad default config %#v", tlsConfig)
+	}
+
+	opts := TLSConfigOptions{
+		MinVersion:       tls.VersionTLS11,
+		MaxVersion:       tls.VersionTLS13,
+		CipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		CurvePreferences: []tls.CurveID{tls.CurveP256},
+		NextProtos:       []string{"h2", "http/1.1"},
+		ClientAuth:       &requireAndVerify,
+	}
+	tlsConfig, err = withCA.GetTLSConfigWithOptions(TLSServer|TLSClient, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case tlsConfig.MinVersion != tls.VersionTLS11 || tlsConfig.MaxVersion != tls.VersionTLS13:
+		t.Fatalf("bad versions %#04x-%#04x", tlsConfig.MinVersion, tlsConfig.MaxVersion)
+	case len(tlsConfig.CipherSuites) != 1 || len(tlsConfig.CurvePreferences) != 1 || len(tlsConfig.NextProtos) != 2:
+		t.Fatalf("bad config %#v", tlsConfig)
+	case tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert || tlsConfig.ClientCAs == nil || tlsConfig.RootCAs == nil:
+		t.Fatalf("bad client authentication %#v", tlsConfig)
+	}
+
+	tlsConfig, err = withoutCA.GetTLSConfigWithOptions(TLSServer, TLSConfigOptions{ClientAuth: &requestOnly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ClientAuth != tls.RequestClientCert {
+		t.Fatalf("bad client authentication %v", tlsConfig.ClientAuth)
+	}
+
+	for name, tc := range map[string]struct {
+		bundle *ParsedCertBundle
+		opts   TLSConfigOptions
+	}{
+		"max lower than min": {bundle: withCA, opts: TLSConfigOptions{MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12}},
+		"max lower than 1.2": {bundle: withCA, opts: TLSConfigOptions{MaxVersion: tls.VersionTLS11}},
+		"verify without ca":  {bundle: withoutCA, opts: TLSConfigOptions{ClientAuth: &requireAndVerify}},
+	} {
+		if _, err := tc.bundle.GetTLSConfigWithOptions(TLSServer, tc.opts); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+		reloadable, err := NewReloadableBundle(tc.bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reloadable.GetTLSConfigWithOptions(TLSServer, tc.opts); err == nil {
+			t.Fatalf("%s: expected error from the reloadable bundle", name)
+		}
+	}
+
+	// Reloadable servers requiring client certificates reject clients without
+	// one, and negotiate the ALPN protocol
+	reloadable, err := NewReloadableBundle(withCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig, err := reloadable.GetTLSConfigWithOptions(TLSServer, TLSConfigOptions{
+		MinVersion: tls.VersionTLS13,
+		NextProtos: []string{"h2"},
+		ClientAuth: &requireAndVerify,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handshake := func(clientCert bool) (tls.ConnectionState, error) {
+		clientConfig, err := withoutCA.GetTLSConfigWithOptions(TLSClient, TLSConfigOptions{NextProtos: []string{"h2"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientConfig.RootCAs = x509.NewCertPool()
+		clientConfig.RootCAs.AddCert(cert.Certificate)
+		clientConfig.ServerName = "localhost"
+		if !clientCert {
+			clientConfig.Certificates = nil
+		}
+
+		serverConn, clientConn := net.Pipe()
+		defer serverConn.Close()
+		defer clientConn.Close()
+
+		tlsServer := tls.Server(serverConn, serverConfig)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- tlsServer.Handshake()
+			serverConn.Close()
+		}()
+		tlsClient := tls.Client(clientConn, clientConfig)
+		if err := tlsClient.Handshake(); err != nil {
+			return tls.ConnectionState{}, err
+		}
+		// With TLS 1.3, client certificates are only verified once the client
+		// handshake is done, so read the server alerts until it hangs up
+		ioutil.ReadAll(tlsClient)
+		if err := <-errCh; err != nil {
+			return tls.ConnectionState{}, err
+		}
+		return tlsServer.ConnectionState(), nil
+	}
+
+	state, err := handshake(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != tls.VersionTLS13 || state.NegotiatedProtocol != "h2" || len(state.PeerCertificates) == 0 {
+		t.Fatalf("bad connection state %#v", state)
+	}
+	if _, err := handshake(false); err == nil {
+		t.Fatal("expected error without a client certificate")
+	}
+}
+
+func TestGetTLSConfigWithOptions_TrustPools(t *testing.T) {
+	newCA := func(name string) (*CertBlock, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		return testCertBlock(t, template, template, key, key), key
+	}
+	newLeaf := func(ca *CertBlock, caKey *ecdsa.PrivateKey) *ParsedCertBundle {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert := testCertBlock(t, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+		}, ca.Certificate, key, caKey)
+		return &ParsedCertBundle{
+			Certificate:      cert.Certificate,
+			CertificateBytes: cert.Bytes,
+			PrivateKey:       key,
+			CAChain:          []*CertBlock{ca},
+		}
+	}
+	issuer, issuerKey := newCA("Issuing CA")
+	peerCA, peerKey := newCA("Peer CA")
+	bundle, peer := newLeaf(issuer, issuerKey), newLeaf(peerCA, peerKey)
+	peerCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: peerCA.Bytes})
+
+	// trusts returns whether the pool verifies the certificate of the bundle
+	trusts := func(pool *x509.CertPool, p *ParsedCertBundle) bool {
+		if pool == nil {
+			return false
+		}
+		_, err := p.Certificate.Verify(x509.VerifyOptions{
+			Roots:     pool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err == nil
+	}
+
+	overridden := x509.NewCertPool()
+	overridden.AddCert(peerCA.Certificate)
+
+	for name, tc := range map[string]struct {
+		opts         TLSConfigOptions
+		trustsBundle bool
+		trustsPeer   bool
+		clientAuth   tls.ClientAuthType
+	}{
+		"default": {
+			trustsBundle: true,
+			clientAuth:   tls.VerifyClientCertIfGiven,
+		},
+		"extra ca": {
+			opts:         TLSConfigOptions{ExtraCAPEM: peerCAPEM},
+			trustsBundle: true,
+			trustsPeer:   true,
+			clientAuth:   tls.VerifyClientCertIfGiven,
+		},
+		"extra ca without the bundle ca": {
+			opts:       TLSConfigOptions{ExtraCAPEM: peerCAPEM, DisableBundleCA: true},
+			trustsPeer: true,
+			clientAuth: tls.VerifyClientCertIfGiven,
+		},
+		"without the bundle ca": {
+			opts:       TLSConfigOptions{DisableBundleCA: true},
+			clientAuth: tls.NoClientCert,
+		},
+		"distinct pools": {
+			opts:       TLSConfigOptions{RootCAs: overridden, ClientCAs: overridden},
+			trustsPeer: true,
+			clientAuth: tls.VerifyClientCertIfGiven,
+		},
+	} {
+		tlsConfig, err := bundle.GetTLSConfigWithOptions(TLSServer|TLSClient, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for pool, poolName := range map[*x509.CertPool]string{tlsConfig.RootCAs: "root CAs", tlsConfig.ClientCAs: "client CAs"} {
+			if trusts(pool, bundle) != tc.trustsBundle || trusts(pool, peer) != tc.trustsPeer {
+				t.Fatalf("%s: bad %s", name, poolName)
+			}
+		}
+		if tlsConfig.ClientAuth != tc.clientAuth {
+			t.Fatalf("%s: bad client authentication %v", name, tlsConfig.ClientAuth)
+		}
+	}
+
+	// Only the root CAs are replaced
+	tlsConfig, err := bundle.GetTLSConfigWithOptions(TLSServer|TLSClient, TLSConfigOptions{RootCAs: overridden})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trusts(tlsConfig.RootCAs, peer) || trusts(tlsConfig.RootCAs, bundle) || !trusts(tlsConfig.ClientCAs, bundle) {
+		t.Fatal("bad pools")
+	}
+
+	if _, err := bundle.GetTLSConfigWithOptions(TLSClient, TLSConfigOptions{ExtraCAPEM: []byte("garbage")}); err == nil {
+		t.Fatal("expected error with an invalid extra CA PEM")
+	}
+
+	// Servers verify clients of other CAs than the issuer of their certificate
+	server, err := NewReloadableBundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireAndVerify := tls.RequireAndVerifyClientCert
+	serverConfig, err := server.GetTLSConfigWithOptions(TLSServer, TLSConfigOptions{
+		ExtraCAPEM:      peerCAPEM,
+		DisableBundleCA: true,
+		ClientAuth:      &requireAndVerify,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewReloadableBundle(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig, err := client.GetTLSConfigWithOptions(TLSClient, TLSConfigOptions{
+		RootCAs: x509.NewCertPool(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig.RootCAs.AddCert(issuer.Certificate)
+	clientConfig.ServerName = "localhost"
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	tlsServer := tls.Server(serverConn, serverConfig)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- tlsServer.Handshake()
+		serverConn.Close()
+	}()
+	tlsClient := tls.Client(clientConn, clientConfig)
+	if err := tlsClient.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(tlsClient)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if certs := tlsServer.ConnectionState().PeerCertificates; len(certs) == 0 || !certs[0].Equal(peer.Certificate) {
+		t.Fatal("expected the certificate of the peer")
+	}
+}
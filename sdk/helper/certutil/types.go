@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
@@ -19,6 +20,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/helper/errutil"
@@ -38,9 +40,9 @@ type Secret struct {
 	Data map[string]interface{} `json:"data"`
 }
 
-// PrivateKeyType holds a string representation of the type of private key (ec
-// or rsa) referenced in CertBundle and ParsedCertBundle. This uses colloquial
-// names rather than official names, to eliminate confusion
+// PrivateKeyType holds a string representation of the type of private key (ec,
+// rsa, ed25519 or managed) referenced in CertBundle and ParsedCertBundle. This
+// uses colloquial names rather than official names, to eliminate confusion
 type PrivateKeyType string
 
 //Well-known PrivateKeyTypes
@@ -48,6 +50,13 @@ const (
 	UnknownPrivateKey PrivateKeyType = ""
 	RSAPrivateKey     PrivateKeyType = "rsa"
 	ECPrivateKey      PrivateKeyType = "ec"
+	Ed25519PrivateKey PrivateKeyType = "ed25519"
+
+	// ManagedPrivateKey is the type of private keys living in a KMS or HSM.
+	// Their bundles hold no key material: the PrivateKey of a
+	// ParsedCertBundle is a crypto.Signer backed by the key, and its
+	// PrivateKeyBytes are empty.
+	ManagedPrivateKey PrivateKeyType = "managed"
 )
 
 // TLSUsage controls whether the intended usage of a *tls.Config
@@ -70,6 +79,9 @@ const (
 	PKCS1Block BlockType = "RSA PRIVATE KEY"
 	PKCS8Block BlockType = "PRIVATE KEY"
 	ECBlock    BlockType = "EC PRIVATE KEY"
+
+	// EncryptedPKCS8Block is a password protected PKCS#8 key
+	EncryptedPKCS8Block BlockType = "ENCRYPTED PRIVATE KEY"
 )
 
 //ParsedPrivateKeyContainer allows common key setting for certs and CSRs
@@ -145,19 +157,76 @@ func (c *CertBundle) ToPEMBundle() string {
 	return strings.Join(result, "\n")
 }
 
+// ToFullChainPEM returns the PEM certificate of the bundle followed by its CA
+// chain, leaf certificate first, without the private key. SplitCertificateChain
+// reverses it.
+func (c *CertBundle) ToFullChainPEM() string {
+	var result []string
+
+	if len(c.Certificate) > 0 {
+		result = append(result, c.Certificate)
+	}
+	if len(c.CAChain) > 0 {
+		result = append(result, c.CAChain...)
+	}
+
+	return strings.Join(result, "\n")
+}
+
 // ToParsedCertBundle converts a string-based certificate bundle
-// to a byte-based raw certificate bundle
+// to a byte-based raw certificate bundle. If the certificate contains
+// additional concatenated PEM certificates after the leaf, they are
+// placed into the CA chain.
 func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
+	return c.ToParsedCertBundleWithPassword(nil)
+}
+
+// ToParsedCertBundleWithPassword converts a string-based certificate bundle
+// to a byte-based raw certificate bundle, decrypting the private key with the
+// given password if it is a legacy encrypted PEM block or an encrypted PKCS#8
+// key. The parsed bundle holds the decrypted key.
+func (c *CertBundle) ToParsedCertBundleWithPassword(password []byte) (*ParsedCertBundle, error) {
+	return c.ToParsedCertBundleWithOptions(CertBundleParseOptions{
+		Password: password,
+	})
+}
+
+// CertBundleParseOptions holds the options of ToParsedCertBundleWithOptions
+type CertBundleParseOptions struct {
+	// Password decrypts the private key, as in ToParsedCertBundleWithPassword
+	Password []byte
+
+	// SortCAChain orders the CA chain from the issuer of the certificate up to
+	// the root, for chains not given in trust path order
+	SortCAChain bool
+
+	// ManagedKey is set as the private key of bundles of the ManagedPrivateKey
+	// type
+	ManagedKey crypto.Signer
+}
+
+// ToParsedCertBundleWithOptions converts a string-based certificate bundle to
+// a byte-based raw certificate bundle with the given options
+func (c *CertBundle) ToParsedCertBundleWithOptions(opts CertBundleParseOptions) (*ParsedCertBundle, error) {
+	password := opts.Password
 	result := &ParsedCertBundle{}
 	var err error
 	var pemBlock *pem.Block
 
 	if len(c.PrivateKey) > 0 {
-		pemBlock, _ = pem.Decode([]byte(c.PrivateKey))
+		keyPEM := []byte(c.PrivateKey)
+		defer zeroizeBytes(keyPEM)
+
+		pemBlock, _ = pem.Decode(keyPEM)
 		if pemBlock == nil {
 			return nil, errutil.UserError{Err: "Error decoding private key from cert bundle"}
 		}
 
+		pemBlock, err = decryptPEMBlock(pemBlock, password)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("Error decrypting private key from cert bundle: %v", err)}
+		}
+
 		result.PrivateKeyBytes = pemBlock.Bytes
 		result.PrivateKeyFormat = BlockType(strings.TrimSpace(pemBlock.Type))
 
@@ -177,6 +246,8 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 				c.PrivateKeyType = ECPrivateKey
 			case RSAPrivateKey:
 				c.PrivateKeyType = RSAPrivateKey
+			case Ed25519PrivateKey:
+				c.PrivateKeyType = Ed25519PrivateKey
 			}
 		default:
 			return nil, errutil.UserError{Err: fmt.Sprintf("Unsupported key block type: %s", pemBlock.Type)}
@@ -186,10 +257,14 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 		if err != nil {
 			return nil, errutil.UserError{Err: fmt.Sprintf("Error getting signer: %s", err)}
 		}
+	} else if c.PrivateKeyType == ManagedPrivateKey {
+		result.PrivateKeyType = ManagedPrivateKey
+		result.PrivateKey = opts.ManagedKey
 	}
 
 	if len(c.Certificate) > 0 {
-		pemBlock, _ = pem.Decode([]byte(c.Certificate))
+		var rest []byte
+		pemBlock, rest = pem.Decode([]byte(c.Certificate))
 		if pemBlock == nil {
 			return nil, errutil.UserError{Err: "Error decoding certificate from cert bundle"}
 		}
@@ -198,6 +273,26 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 		if err != nil {
 			return nil, errutil.UserError{Err: fmt.Sprintf("Error encountered parsing certificate bytes from raw bundle: %v", err)}
 		}
+
+		// The certificate may be a full chain (e.g. the contents of a
+		// fullchain.pem file), in which case the issuing certificates follow
+		// the leaf and are moved into the CA chain
+		for len(bytes.TrimSpace(rest)) > 0 {
+			pemBlock, rest = pem.Decode(rest)
+			if pemBlock == nil {
+				return nil, errutil.UserError{Err: "Error decoding certificate chain from certificate in cert bundle"}
+			}
+
+			parsedCert, err := x509.ParseCertificate(pemBlock.Bytes)
+			if err != nil {
+				return nil, errutil.UserError{Err: fmt.Sprintf("Error encountered parsing certificate bytes from raw bundle via certificate chain: %v", err)}
+			}
+
+			result.CAChain = append(result.CAChain, &CertBlock{
+				Bytes:       pemBlock.Bytes,
+				Certificate: parsedCert,
+			})
+		}
 	}
 	switch {
 	case len(c.CAChain) > 0:
@@ -207,6 +302,11 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 				return nil, errutil.UserError{Err: "Error decoding certificate from cert bundle"}
 			}
 
+			// Skip certificates already split out of a full chain certificate
+			if result.hasCACertificate(pemBlock.Bytes) {
+				continue
+			}
+
 			parsedCert, err := x509.ParseCertificate(pemBlock.Bytes)
 			if err != nil {
 				return nil, errutil.UserError{Err: fmt.Sprintf("Error encountered parsing certificate bytes from raw bundle via CA chain: %v", err)}
@@ -226,6 +326,10 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 			return nil, errutil.UserError{Err: "Error decoding ca certificate from cert bundle"}
 		}
 
+		if result.hasCACertificate(pemBlock.Bytes) {
+			break
+		}
+
 		parsedCert, err := x509.ParseCertificate(pemBlock.Bytes)
 		if err != nil {
 			return nil, errutil.UserError{Err: fmt.Sprintf("Error encountered parsing certificate bytes from raw bundle via issuing CA: %v", err)}
@@ -243,12 +347,24 @@ func (c *CertBundle) ToParsedCertBundle() (*ParsedCertBundle, error) {
 		c.SerialNumber = GetHexFormatted(result.Certificate.SerialNumber.Bytes(), ":")
 	}
 
+	if opts.SortCAChain && result.Certificate != nil && len(result.CAChain) > 0 {
+		certPath := sortCertPath(result.GetCertificatePath(), 0)
+		result.CAChain = certPath[1:]
+	}
+
 	return result, nil
 }
 
 // ToCertBundle converts a byte-based raw DER certificate bundle
 // to a PEM-based string certificate bundle
 func (p *ParsedCertBundle) ToCertBundle() (*CertBundle, error) {
+	return p.ToCertBundleWithPassword(nil)
+}
+
+// ToCertBundleWithPassword converts a byte-based raw DER certificate bundle
+// to a PEM-based string certificate bundle. If a password is given, the
+// private key is written as an encrypted PKCS#8 block protected by it.
+func (p *ParsedCertBundle) ToCertBundleWithPassword(password []byte) (*CertBundle, error) {
 	result := &CertBundle{}
 	block := pem.Block{
 		Type: "CERTIFICATE",
@@ -270,7 +386,10 @@ func (p *ParsedCertBundle) ToCertBundle() (*CertBundle, error) {
 		result.CAChain = append(result.CAChain, certificate)
 	}
 
-	if p.PrivateKeyBytes != nil && len(p.PrivateKeyBytes) > 0 {
+	if p.PrivateKeyType == ManagedPrivateKey {
+		// The key material of managed keys never leaves their KMS or HSM
+		result.PrivateKeyType = ManagedPrivateKey
+	} else if p.PrivateKeyBytes != nil && len(p.PrivateKeyBytes) > 0 {
 		block.Type = string(p.PrivateKeyFormat)
 		block.Bytes = p.PrivateKeyBytes
 		result.PrivateKeyType = p.PrivateKeyType
@@ -282,22 +401,90 @@ func (p *ParsedCertBundle) ToCertBundle() (*CertBundle, error) {
 				block.Type = string(ECBlock)
 			case RSAPrivateKey:
 				block.Type = string(PKCS1Block)
+			case Ed25519PrivateKey:
+				block.Type = string(PKCS8Block)
 			}
 		}
 
-		result.PrivateKey = strings.TrimSpace(string(pem.EncodeToMemory(&block)))
+		keyBlock := &block
+		if len(password) > 0 {
+			var err error
+			keyBlock, err = encryptPEMBlock(keyBlock, password)
+			if err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("Error encrypting private key: %v", err)}
+			}
+		}
+
+		keyPEM := pem.EncodeToMemory(keyBlock)
+		result.PrivateKey = strings.TrimSpace(string(keyPEM))
+		zeroizeBytes(keyPEM)
 	}
 
 	return result, nil
 }
 
+// VerifyOptions holds the options of VerifyWithOptions
+type VerifyOptions struct {
+	// CheckValidityPeriod enables checking that every certificate of the
+	// certificate path is valid at CurrentTime
+	CheckValidityPeriod bool
+
+	// CurrentTime is the time the validity periods are checked against; the
+	// current time if zero
+	CurrentTime time.Time
+
+	// ClockSkew is tolerated on both ends of the validity periods
+	ClockSkew time.Duration
+}
+
+// ValidityPeriodError is returned by VerifyWithOptions for a certificate of
+// the certificate path that expired or is not yet valid
+type ValidityPeriodError struct {
+	// Index is the position of the certificate in the certificate path, the
+	// certificate of the bundle being 0
+	Index int
+
+	Certificate *x509.Certificate
+
+	// CurrentTime is the time the validity period was checked against
+	CurrentTime time.Time
+
+	// Expired is set if the certificate expired, unset if it is not yet
+	// valid
+	Expired bool
+}
+
+func (e *ValidityPeriodError) Error() string {
+	if e.Expired {
+		return fmt.Sprintf("certificate %d of certificate chain (%q) expired: current time %s is after %s",
+			e.Index, e.Certificate.Subject.CommonName, e.CurrentTime.UTC().Format(time.RFC3339), e.Certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("certificate %d of certificate chain (%q) is not yet valid: current time %s is before %s",
+		e.Index, e.Certificate.Subject.CommonName, e.CurrentTime.UTC().Format(time.RFC3339), e.Certificate.NotBefore.UTC().Format(time.RFC3339))
+}
+
 // Verify checks if the parsed bundle is valid.  It validates the public
 // key of the certificate to the private key and checks the certificate trust
 // chain for path issues.
 func (p *ParsedCertBundle) Verify() error {
+	return p.VerifyWithOptions(VerifyOptions{})
+}
+
+// VerifyWithOptions is Verify, additionally checking the validity periods of
+// the certificate path if asked to. A *ValidityPeriodError is returned for the
+// first certificate that isn't valid.
+func (p *ParsedCertBundle) VerifyWithOptions(opts VerifyOptions) error {
+	if p.PrivateKeyType == ManagedPrivateKey && p.PrivateKey == nil {
+		return fmt.Errorf("managed private key of bundle is not set")
+	}
+
 	// If private key exists, check if it matches the public key of cert
 	if p.PrivateKey != nil && p.Certificate != nil {
-		equal, err := ComparePublicKeys(p.Certificate.PublicKey, p.PrivateKey.Public())
+		pub, err := certificatePublicKey(p.Certificate)
+		if err != nil {
+			return err
+		}
+		equal, err := ComparePublicKeys(pub, p.PrivateKey.Public())
 		if err != nil {
 			return errwrap.Wrapf("could not compare public and private keys: {{err}}", err)
 		}
@@ -306,15 +493,49 @@ func (p *ParsedCertBundle) Verify() error {
 		}
 	}
 
+	if p.Certificate == nil && len(p.CAChain) > 0 {
+		return fmt.Errorf("certificate chain given without a certificate")
+	}
+
 	certPath := p.GetCertificatePath()
 	if len(certPath) > 1 {
 		for i, caCert := range certPath[1:] {
+			if caCert == nil || caCert.Certificate == nil {
+				return fmt.Errorf("certificate %d of certificate chain is empty", i+1)
+			}
 			if !caCert.Certificate.IsCA {
 				return fmt.Errorf("certificate %d of certificate chain is not a certificate authority", i+1)
 			}
-			if !bytes.Equal(certPath[i].Certificate.AuthorityKeyId, caCert.Certificate.SubjectKeyId) {
-				return fmt.Errorf("certificate %d of certificate chain ca trust path is incorrect (%q/%q)",
-					i+1, certPath[i].Certificate.Subject.CommonName, caCert.Certificate.Subject.CommonName)
+			// The signature of each certificate is checked against its
+			// issuer, falling back to comparing the key IDs for the signature
+			// algorithms that can't be checked, e.g. insecure ones
+			subject := certPath[i].Certificate
+			err := checkSignatureFrom(subject, caCert.Certificate)
+			if _, insecure := err.(x509.InsecureAlgorithmError); insecure || err == x509.ErrUnsupportedAlgorithm {
+				if !bytes.Equal(subject.AuthorityKeyId, caCert.Certificate.SubjectKeyId) {
+					return fmt.Errorf("certificate %d of certificate chain ca trust path is incorrect (%q/%q)",
+						i+1, subject.Subject.CommonName, caCert.Certificate.Subject.CommonName)
+				}
+			} else if err != nil {
+				return fmt.Errorf("certificate %d of certificate chain ca trust path is incorrect (%q/%q): %v",
+					i+1, subject.Subject.CommonName, caCert.Certificate.Subject.CommonName, err)
+			}
+		}
+	}
+
+	if opts.CheckValidityPeriod {
+		now := opts.CurrentTime
+		if now.IsZero() {
+			now = time.Now()
+		}
+		for i, certBlock := range certPath {
+			cert := certBlock.Certificate
+			switch {
+			case cert == nil:
+			case now.Add(opts.ClockSkew).Before(cert.NotBefore):
+				return &ValidityPeriodError{Index: i, Certificate: cert, CurrentTime: now}
+			case now.Add(-opts.ClockSkew).After(cert.NotAfter):
+				return &ValidityPeriodError{Index: i, Certificate: cert, CurrentTime: now, Expired: true}
 			}
 		}
 	}
@@ -322,6 +543,17 @@ func (p *ParsedCertBundle) Verify() error {
 	return nil
 }
 
+// hasCACertificate returns true if the CA chain of the bundle already
+// contains a certificate with the given DER bytes
+func (p *ParsedCertBundle) hasCACertificate(certBytes []byte) bool {
+	for _, caCert := range p.CAChain {
+		if bytes.Equal(caCert.Bytes, certBytes) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetCertificatePath returns a slice of certificates making up a path, pulled
 // from the parsed cert bundle
 func (p *ParsedCertBundle) GetCertificatePath() []*CertBlock {
@@ -332,9 +564,9 @@ func (p *ParsedCertBundle) GetCertificatePath() []*CertBlock {
 		Bytes:       p.CertificateBytes,
 	})
 
-	if len(p.CAChain) > 0 {
+	if len(p.CAChain) > 0 && p.Certificate != nil {
 		// Root CA puts itself in the chain
-		if p.CAChain[0].Certificate.SerialNumber != p.Certificate.SerialNumber {
+		if p.CAChain[0].Certificate == nil || p.CAChain[0].Certificate.SerialNumber != p.Certificate.SerialNumber {
 			certPath = append(certPath, p.CAChain...)
 		}
 	}
@@ -368,9 +600,9 @@ func (p *ParsedCertBundle) getSigner() (crypto.Signer, error) {
 		}
 
 	case PKCS8Block:
-		if k, err := x509.ParsePKCS8PrivateKey(p.PrivateKeyBytes); err == nil {
+		if k, err := parsePKCS8PrivateKey(p.PrivateKeyBytes); err == nil {
 			switch k := k.(type) {
-			case *rsa.PrivateKey, *ecdsa.PrivateKey:
+			case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
 				return k.(crypto.Signer), nil
 			default:
 				return nil, errutil.UserError{Err: "Found unknown private key type in pkcs#8 wrapping"}
@@ -378,7 +610,7 @@ func (p *ParsedCertBundle) getSigner() (crypto.Signer, error) {
 		}
 		return nil, errutil.UserError{Err: fmt.Sprintf("Failed to parse pkcs#8 key: %v", err)}
 	default:
-		return nil, errutil.UserError{Err: "Unable to determine type of private key; only RSA and EC are supported"}
+		return nil, errutil.UserError{Err: "Unable to determine type of private key; only RSA, EC and Ed25519 are supported"}
 	}
 	return signer, nil
 }
@@ -391,7 +623,7 @@ func (p *ParsedCertBundle) SetParsedPrivateKey(privateKey crypto.Signer, private
 }
 
 func getPKCS8Type(bs []byte) (PrivateKeyType, error) {
-	k, err := x509.ParsePKCS8PrivateKey(bs)
+	k, err := parsePKCS8PrivateKey(bs)
 	if err != nil {
 		return UnknownPrivateKey, errutil.UserError{Err: fmt.Sprintf("Failed to parse pkcs#8 key: %v", err)}
 	}
@@ -401,6 +633,8 @@ func getPKCS8Type(bs []byte) (PrivateKeyType, error) {
 		return ECPrivateKey, nil
 	case *rsa.PrivateKey:
 		return RSAPrivateKey, nil
+	case ed25519.PrivateKey:
+		return Ed25519PrivateKey, nil
 	default:
 		return UnknownPrivateKey, errutil.UserError{Err: "Found unknown private key type in pkcs#8 wrapping"}
 	}
@@ -409,15 +643,30 @@ func getPKCS8Type(bs []byte) (PrivateKeyType, error) {
 // ToParsedCSRBundle converts a string-based CSR bundle
 // to a byte-based raw CSR bundle
 func (c *CSRBundle) ToParsedCSRBundle() (*ParsedCSRBundle, error) {
+	return c.ToParsedCSRBundleWithPassword(nil)
+}
+
+// ToParsedCSRBundleWithPassword converts a string-based CSR bundle to a
+// byte-based raw CSR bundle, decrypting the private key with the given
+// password if it is a legacy encrypted PEM block or an encrypted PKCS#8 key
+func (c *CSRBundle) ToParsedCSRBundleWithPassword(password []byte) (*ParsedCSRBundle, error) {
 	result := &ParsedCSRBundle{}
 	var err error
 	var pemBlock *pem.Block
 
 	if len(c.PrivateKey) > 0 {
-		pemBlock, _ = pem.Decode([]byte(c.PrivateKey))
+		keyPEM := []byte(c.PrivateKey)
+		defer zeroizeBytes(keyPEM)
+
+		pemBlock, _ = pem.Decode(keyPEM)
 		if pemBlock == nil {
 			return nil, errutil.UserError{Err: "Error decoding private key from cert bundle"}
 		}
+
+		pemBlock, err = decryptPEMBlock(pemBlock, password)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("Error decrypting private key from CSR bundle: %v", err)}
+		}
 		result.PrivateKeyBytes = pemBlock.Bytes
 
 		switch BlockType(pemBlock.Type) {
@@ -425,6 +674,28 @@ func (c *CSRBundle) ToParsedCSRBundle() (*ParsedCSRBundle, error) {
 			result.PrivateKeyType = ECPrivateKey
 		case PKCS1Block:
 			result.PrivateKeyType = RSAPrivateKey
+		case PKCS8Block:
+			// RSA and EC keys are kept in their own encoding, such as those of
+			// decrypted pkcs#8 keys. Ed25519 keys only have a pkcs#8 encoding.
+			key, err := parsePKCS8PrivateKey(pemBlock.Bytes)
+			if err != nil {
+				return nil, errutil.UserError{Err: fmt.Sprintf("Error getting key type from pkcs#8: %v", err)}
+			}
+			switch key := key.(type) {
+			case *rsa.PrivateKey:
+				result.PrivateKeyBytes = x509.MarshalPKCS1PrivateKey(key)
+				result.PrivateKeyType, c.PrivateKeyType = RSAPrivateKey, RSAPrivateKey
+			case *ecdsa.PrivateKey:
+				result.PrivateKeyBytes, err = x509.MarshalECPrivateKey(key)
+				if err != nil {
+					return nil, errutil.UserError{Err: fmt.Sprintf("Error converting EC key from pkcs#8: %v", err)}
+				}
+				result.PrivateKeyType, c.PrivateKeyType = ECPrivateKey, ECPrivateKey
+			case ed25519.PrivateKey:
+				result.PrivateKeyType, c.PrivateKeyType = Ed25519PrivateKey, Ed25519PrivateKey
+			default:
+				return nil, errutil.UserError{Err: fmt.Sprintf("Unsupported pkcs#8 wrapped private key type in bundle: %T", key)}
+			}
 		default:
 			// Try to figure it out and correct
 			if _, err := x509.ParseECPrivateKey(pemBlock.Bytes); err == nil {
@@ -462,6 +733,13 @@ func (c *CSRBundle) ToParsedCSRBundle() (*ParsedCSRBundle, error) {
 // ToCSRBundle converts a byte-based raw DER certificate bundle
 // to a PEM-based string certificate bundle
 func (p *ParsedCSRBundle) ToCSRBundle() (*CSRBundle, error) {
+	return p.ToCSRBundleWithPassword(nil)
+}
+
+// ToCSRBundleWithPassword converts a byte-based raw DER CSR bundle to a
+// PEM-based string CSR bundle. If a password is given, the private key is
+// written as an encrypted PKCS#8 block protected by it.
+func (p *ParsedCSRBundle) ToCSRBundleWithPassword(password []byte) (*CSRBundle, error) {
 	result := &CSRBundle{}
 	block := pem.Block{
 		Type: "CERTIFICATE REQUEST",
@@ -481,10 +759,25 @@ func (p *ParsedCSRBundle) ToCSRBundle() (*CSRBundle, error) {
 		case ECPrivateKey:
 			result.PrivateKeyType = "ec"
 			block.Type = "EC PRIVATE KEY"
+		case Ed25519PrivateKey:
+			result.PrivateKeyType = "ed25519"
+			block.Type = "PRIVATE KEY"
 		default:
 			return nil, errutil.InternalError{Err: "Could not determine private key type when creating block"}
 		}
-		result.PrivateKey = strings.TrimSpace(string(pem.EncodeToMemory(&block)))
+
+		keyBlock := &block
+		if len(password) > 0 {
+			var err error
+			keyBlock, err = encryptPEMBlock(keyBlock, password)
+			if err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("Error encrypting private key: %v", err)}
+			}
+		}
+
+		keyPEM := pem.EncodeToMemory(keyBlock)
+		result.PrivateKey = strings.TrimSpace(string(keyPEM))
+		zeroizeBytes(keyPEM)
 	}
 
 	return result, nil
@@ -515,8 +808,19 @@ func (p *ParsedCSRBundle) getSigner() (crypto.Signer, error) {
 			return nil, errutil.UserError{Err: fmt.Sprintf("Unable to parse CA's private RSA key: %s", err)}
 		}
 
+	case Ed25519PrivateKey:
+		k, err := parsePKCS8PrivateKey(p.PrivateKeyBytes)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("Unable to parse CA's private Ed25519 key: %s", err)}
+		}
+		edKey, ok := k.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errutil.UserError{Err: "Found unknown private key type in pkcs#8 wrapping"}
+		}
+		signer = edKey
+
 	default:
-		return nil, errutil.UserError{Err: "Unable to determine type of private key; only RSA and EC are supported"}
+		return nil, errutil.UserError{Err: "Unable to determine type of private key; only RSA, EC and Ed25519 are supported"}
 	}
 	return signer, nil
 }
@@ -531,16 +835,64 @@ func (p *ParsedCSRBundle) SetParsedPrivateKey(privateKey crypto.Signer, privateK
 // getTLSConfig returns a TLS config generally suitable for client
 // authentication. The returned TLS config can be modified slightly
 // to be made suitable for a server requiring client authentication;
-// specifically, you should set the value of ClientAuth in the returned
-// config to match your needs.
+// specifically, you should set ClientAuth in the options of
+// GetTLSConfigWithOptions to match your needs. The issuing CA of the bundle is
+// trusted by both clients and servers, unless the options set other pools.
 func (p *ParsedCertBundle) GetTLSConfig(usage TLSUsage) (*tls.Config, error) {
-	tlsCert := tls.Certificate{
-		Certificate: [][]byte{},
-	}
+	return p.GetTLSConfigWithOptions(usage, TLSConfigOptions{})
+}
 
+// GetTLSConfigWithOptions returns a TLS config like GetTLSConfig, with the
+// given options applied
+func (p *ParsedCertBundle) GetTLSConfigWithOptions(usage TLSUsage, opts TLSConfigOptions) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 	}
+	if err := opts.apply(tlsConfig); err != nil {
+		return nil, err
+	}
+
+	extraCAs, err := opts.extraCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCert, caPool, err := p.getTLSCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	if usage&TLSServer > 0 {
+		clientCAs := opts.trustPool(opts.ClientCAs, p, caPool, extraCAs)
+		clientAuth, err := opts.clientAuth(clientCAs != nil)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = clientAuth
+	}
+	if usage&TLSClient > 0 {
+		tlsConfig.RootCAs = opts.trustPool(opts.RootCAs, p, caPool, extraCAs)
+	}
+
+	if tlsCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*tlsCert}
+		tlsConfig.BuildNameToCertificate()
+	}
+
+	return tlsConfig, nil
+}
+
+// getTLSCertificate returns the certificate of the bundle with its CA chain,
+// if any, and a pool of the first certificate of the CA chain, if any
+func (p *ParsedCertBundle) getTLSCertificate() (*tls.Certificate, *x509.CertPool, error) {
+	if p.PrivateKeyType == ManagedPrivateKey && p.PrivateKey == nil {
+		return nil, nil, fmt.Errorf("managed private key of bundle is not set")
+	}
+
+	tlsCert := tls.Certificate{
+		Certificate: [][]byte{},
+	}
 
 	if p.Certificate != nil {
 		tlsCert.Leaf = p.Certificate
@@ -554,6 +906,7 @@ func (p *ParsedCertBundle) GetTLSConfig(usage TLSUsage) (*tls.Config, error) {
 		tlsCert.Certificate = append(tlsCert.Certificate, p.CertificateBytes)
 	}
 
+	var caPool *x509.CertPool
 	if len(p.CAChain) > 0 {
 		for _, cert := range p.CAChain {
 			tlsCert.Certificate = append(tlsCert.Certificate, cert.Bytes)
@@ -562,30 +915,20 @@ func (p *ParsedCertBundle) GetTLSConfig(usage TLSUsage) (*tls.Config, error) {
 		// Technically we only need one cert, but this doesn't duplicate code
 		certBundle, err := p.ToCertBundle()
 		if err != nil {
-			return nil, errwrap.Wrapf("error converting parsed bundle to string bundle when getting TLS config: {{err}}", err)
+			return nil, nil, errwrap.Wrapf("error converting parsed bundle to string bundle when getting TLS config: {{err}}", err)
 		}
 
-		caPool := x509.NewCertPool()
+		caPool = x509.NewCertPool()
 		ok := caPool.AppendCertsFromPEM([]byte(certBundle.CAChain[0]))
 		if !ok {
-			return nil, fmt.Errorf("could not append CA certificate")
-		}
-
-		if usage&TLSServer > 0 {
-			tlsConfig.ClientCAs = caPool
-			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
-		}
-		if usage&TLSClient > 0 {
-			tlsConfig.RootCAs = caPool
+			return nil, nil, fmt.Errorf("could not append CA certificate")
 		}
 	}
 
-	if tlsCert.Certificate != nil && len(tlsCert.Certificate) > 0 {
-		tlsConfig.Certificates = []tls.Certificate{tlsCert}
-		tlsConfig.BuildNameToCertificate()
+	if len(tlsCert.Certificate) == 0 {
+		return nil, caPool, nil
 	}
-
-	return tlsConfig, nil
+	return &tlsCert, caPool, nil
 }
 
 // IssueData is a structure that is suitable for marshaling into a request;
@@ -0,0 +1,80 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"math/big"
+)
+
+// Zeroize wipes the private key material held by the bundle, both the raw
+// key bytes and the secret values of the parsed key, and removes the
+// references to them. The bundle must not be used for signing afterwards.
+func (p *ParsedCertBundle) Zeroize() {
+	if p == nil {
+		return
+	}
+	zeroizeBytes(p.PrivateKeyBytes)
+	zeroizeSigner(p.PrivateKey)
+	p.PrivateKeyBytes = nil
+	p.PrivateKey = nil
+}
+
+// Zeroize wipes the private key material held by the bundle, both the raw
+// key bytes and the secret values of the parsed key, and removes the
+// references to them. The bundle must not be used for signing afterwards.
+func (p *ParsedCSRBundle) Zeroize() {
+	if p == nil {
+		return
+	}
+	zeroizeBytes(p.PrivateKeyBytes)
+	zeroizeSigner(p.PrivateKey)
+	p.PrivateKeyBytes = nil
+	p.PrivateKey = nil
+}
+
+// zeroizeBytes overwrites the given buffer with zeros
+func zeroizeBytes(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// zeroizeBigInt overwrites the words backing the given integer with zeros
+// and sets it to zero
+func zeroizeBigInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+	words := i.Bits()
+	for j := range words {
+		words[j] = 0
+	}
+	i.SetInt64(0)
+}
+
+// zeroizeSigner wipes the secret values of the known private key types. Keys
+// of other types, such as ones backed by hardware, are left untouched.
+func zeroizeSigner(signer crypto.Signer) {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		zeroizeBigInt(key.D)
+		for _, prime := range key.Primes {
+			zeroizeBigInt(prime)
+		}
+		zeroizeBigInt(key.Precomputed.Dp)
+		zeroizeBigInt(key.Precomputed.Dq)
+		zeroizeBigInt(key.Precomputed.Qinv)
+		for _, crt := range key.Precomputed.CRTValues {
+			zeroizeBigInt(crt.Exp)
+			zeroizeBigInt(crt.Coeff)
+			zeroizeBigInt(crt.R)
+		}
+	case *ecdsa.PrivateKey:
+		zeroizeBigInt(key.D)
+	case ed25519.PrivateKey:
+		// The seed and the public key it derives are wiped alike
+		zeroizeBytes(key)
+	}
+}
@@ -0,0 +1,100 @@
+package certutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestZeroize(t *testing.T) {
+	for i, cbut := range []*CertBundle{
+		refreshRSACertBundle(),
+		refreshECCertBundle(),
+		refreshEd25519CertBundle(),
+	} {
+		pcbut, err := cbut.ToParsedCertBundle()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyBytes := pcbut.PrivateKeyBytes
+		signer := pcbut.PrivateKey
+		pcbut.Zeroize()
+
+		if pcbut.PrivateKey != nil || pcbut.PrivateKeyBytes != nil {
+			t.Fatalf("bundle %d: private key references were not removed", i)
+		}
+		for _, b := range keyBytes {
+			if b != 0 {
+				t.Fatalf("bundle %d: private key bytes were not wiped", i)
+			}
+		}
+
+		switch key := signer.(type) {
+		case *rsa.PrivateKey:
+			if key.D.Sign() != 0 {
+				t.Fatalf("bundle %d: RSA private exponent was not wiped", i)
+			}
+			for _, prime := range key.Primes {
+				if prime.Sign() != 0 {
+					t.Fatalf("bundle %d: RSA prime was not wiped", i)
+				}
+			}
+		case *ecdsa.PrivateKey:
+			if key.D.Sign() != 0 {
+				t.Fatalf("bundle %d: EC private scalar was not wiped", i)
+			}
+		case ed25519.PrivateKey:
+			for _, b := range key {
+				if b != 0 {
+					t.Fatalf("bundle %d: Ed25519 private key was not wiped", i)
+				}
+			}
+		default:
+			t.Fatalf("bundle %d: unexpected key type %T", i, signer)
+		}
+
+		// The public portion must remain usable
+		if pcbut.Certificate == nil {
+			t.Fatalf("bundle %d: certificate was removed", i)
+		}
+	}
+
+	for i, csrbut := range []*CSRBundle{
+		refreshRSACSRBundle(),
+		refreshECCSRBundle(),
+		refreshEd25519CSRBundle(),
+	} {
+		pcsrbut, err := csrbut.ToParsedCSRBundle()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyBytes := pcsrbut.PrivateKeyBytes
+		signer := pcsrbut.PrivateKey
+		pcsrbut.Zeroize()
+
+		if pcsrbut.PrivateKey != nil || pcsrbut.PrivateKeyBytes != nil {
+			t.Fatalf("csr bundle %d: private key references were not removed", i)
+		}
+		for _, b := range keyBytes {
+			if b != 0 {
+				t.Fatalf("csr bundle %d: private key bytes were not wiped", i)
+			}
+		}
+		if key, ok := signer.(ed25519.PrivateKey); ok {
+			for _, b := range key {
+				if b != 0 {
+					t.Fatalf("csr bundle %d: Ed25519 private key was not wiped", i)
+				}
+			}
+		}
+	}
+
+	// Zeroizing a nil or empty bundle must be safe
+	var nilBundle *ParsedCertBundle
+	nilBundle.Zeroize()
+	(&ParsedCertBundle{}).Zeroize()
+	(&ParsedCSRBundle{}).Zeroize()
+}
@@ -12,6 +12,10 @@ const (
 	// AuthHeaderName is the name of the header containing the token.
 	AuthHeaderName = "X-Vault-Token"
 
+	// RequestIDHeaderName is the name of the response header containing the
+	// ID Vault assigned to the request.
+	RequestIDHeaderName = "X-Vault-Request-ID"
+
 	// PerformanceReplicationALPN is the negotiated protocol used for
 	// performance replication.
 	PerformanceReplicationALPN = "replication_v1"
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -98,3 +99,170 @@ func DecodeJSONFromReader(r io.Reader, out interface{}) error {
 	// Since 'out' is an interface representing a pointer, pass it to the decoder without an '&'
 	return dec.Decode(out)
 }
+
+// ErrMaxSizeExceeded is returned when decoding JSON input larger than the
+// MaxSize of the DecodeOptions
+var ErrMaxSizeExceeded = errors.New("JSON input exceeds the maximum size")
+
+// ErrMaxDepthExceeded is returned when decoding JSON input nested deeper
+// than the MaxDepth of the DecodeOptions
+var ErrMaxDepthExceeded = errors.New("JSON input exceeds the maximum depth")
+
+// DefaultMaxDepth is the maximum nesting depth of the objects and arrays
+// decoded by DecodeJSONFromReaderWithOptions when no MaxDepth is given
+const DefaultMaxDepth = 1000
+
+// DecodeOptions configures DecodeJSONFromReaderWithOptions
+type DecodeOptions struct {
+	// MaxSize is the maximum number of bytes read from the reader. Zero
+	// means no limit.
+	MaxSize int64
+
+	// MaxDepth is the maximum nesting depth of objects and arrays. Zero
+	// means DefaultMaxDepth.
+	MaxDepth int
+
+	// RejectDuplicateKeys fails the decoding of objects holding the same key
+	// more than once, at any depth, instead of keeping the last value
+	RejectDuplicateKeys bool
+}
+
+// DecodeJSONFromReaderWithOptions decodes the JSON value read from the given
+// io.Reader into the desired object, like DecodeJSONFromReader, integer
+// values being decoded as json.Numbers so that large ones such as TTLs in
+// seconds or serial numbers keep their precision.
+func DecodeJSONFromReaderWithOptions(r io.Reader, out interface{}, opts DecodeOptions) error {
+	if r == nil {
+		return fmt.Errorf("'io.Reader' being decoded is nil")
+	}
+	if out == nil {
+		return fmt.Errorf("output parameter 'out' is nil")
+	}
+
+	if opts.MaxSize > 0 {
+		r = &maxSizeReader{r: r, remaining: opts.MaxSize}
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	// The value is read as a whole first, which validates its syntax without
+	// recursing, and its depth is checked before it is walked or decoded, as
+	// the decoder recurses once per level of nesting.
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return err
+	}
+	if err := checkDepth(raw, maxDepth); err != nil {
+		return err
+	}
+	if opts.RejectDuplicateKeys {
+		if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(raw)), maxDepth); err != nil {
+			return err
+		}
+	}
+	return DecodeJSONFromReader(bytes.NewReader(raw), out)
+}
+
+// checkDepth fails if the objects and arrays of the given valid JSON value
+// are nested deeper than maxDepth
+func checkDepth(raw []byte, maxDepth int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			if depth > maxDepth {
+				return ErrMaxDepthExceeded
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// checkDuplicateKeys consumes the next JSON value of the decoder, failing if
+// any of its objects holds the same key twice, or if its objects and arrays
+// are nested deeper than maxDepth
+func checkDuplicateKeys(dec *json.Decoder, maxDepth int) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	if maxDepth <= 0 {
+		return ErrMaxDepthExceeded
+	}
+
+	switch delim {
+	case '{':
+		keys := make(map[string]struct{})
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := token.(string)
+			if _, ok := keys[key]; ok {
+				return fmt.Errorf("duplicate key %q in JSON object", key)
+			}
+			keys[key] = struct{}{}
+
+			if err := checkDuplicateKeys(dec, maxDepth-1); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for dec.More() {
+			if err := checkDuplicateKeys(dec, maxDepth-1); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// maxSizeReader fails with ErrMaxSizeExceeded once more than the remaining
+// bytes are read
+type maxSizeReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, ErrMaxSizeExceeded
+	}
+
+	// Reading a byte past the limit tells inputs of exactly the maximum
+	// size from larger ones; that byte is not handed to the caller
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n - 1, ErrMaxSizeExceeded
+	}
+	return n, err
+}
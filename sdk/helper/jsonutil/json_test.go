@@ -3,6 +3,7 @@ package jsonutil
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -139,3 +140,71 @@ func TestJSONUtil_DecodeJSONFromReader(t *testing.T) {
 		t.Fatalf("bad: expected:%#v\nactual:%#v", expected, actual)
 	}
 }
+
+func TestJSONUtil_DecodeJSONFromReaderWithOptions(t *testing.T) {
+	input := `{"ttl":9007199254740993,"nested":{"keys":["a","a"]}}`
+
+	var actual map[string]interface{}
+	err := DecodeJSONFromReaderWithOptions(strings.NewReader(input), &actual, DecodeOptions{
+		MaxSize:             int64(len(input)),
+		RejectDuplicateKeys: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Large integers keep their precision
+	if actual["ttl"] != json.Number("9007199254740993") {
+		t.Fatalf("bad ttl: %#v", actual["ttl"])
+	}
+
+	for _, tc := range []struct {
+		input string
+		opts  DecodeOptions
+	}{
+		{input: input, opts: DecodeOptions{MaxSize: int64(len(input)) - 1}},
+		{input: `{"a":1,"b":2,"a":3}`, opts: DecodeOptions{RejectDuplicateKeys: true}},
+		{input: `[{"a":{"b":1,"b":2}}]`, opts: DecodeOptions{RejectDuplicateKeys: true}},
+		{input: `{"a":1,`, opts: DecodeOptions{RejectDuplicateKeys: true}},
+	} {
+		var out map[string]interface{}
+		if err := DecodeJSONFromReaderWithOptions(strings.NewReader(tc.input), &out, tc.opts); err == nil {
+			t.Fatalf("expected error decoding %s with %#v", tc.input, tc.opts)
+		}
+	}
+
+	err = DecodeJSONFromReaderWithOptions(strings.NewReader(input), &actual, DecodeOptions{MaxSize: 10})
+	if err != ErrMaxSizeExceeded {
+		t.Fatalf("expected max size error, got %v", err)
+	}
+
+	// The depth is bounded, whether or not duplicate keys are checked
+	deep := strings.Repeat(`{"a":[`, 3) + "1" + strings.Repeat("]}", 3)
+	for _, reject := range []bool{false, true} {
+		opts := DecodeOptions{MaxDepth: 6, RejectDuplicateKeys: reject}
+		if err := DecodeJSONFromReaderWithOptions(strings.NewReader(deep), &actual, opts); err != nil {
+			t.Fatal(err)
+		}
+		opts.MaxDepth = 5
+		if err := DecodeJSONFromReaderWithOptions(strings.NewReader(deep), &actual, opts); err != ErrMaxDepthExceeded {
+			t.Fatalf("expected max depth error, got %v", err)
+		}
+	}
+	deep = strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1)
+	var deepOut interface{}
+	if err := DecodeJSONFromReaderWithOptions(strings.NewReader(deep), &deepOut, DecodeOptions{}); err != ErrMaxDepthExceeded {
+		t.Fatalf("expected max depth error, got %v", err)
+	}
+	// Brackets in strings don't count
+	if err := DecodeJSONFromReaderWithOptions(strings.NewReader(`{"a":"[[[[\"[["}`), &actual, DecodeOptions{MaxDepth: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Duplicate keys are kept by default, the last value winning
+	actual = nil
+	if err := DecodeJSONFromReaderWithOptions(strings.NewReader(`{"a":1,"a":2}`), &actual, DecodeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if actual["a"] != json.Number("2") {
+		t.Fatalf("bad value: %#v", actual["a"])
+	}
+}
@@ -51,6 +51,13 @@ type PolicyRequest struct {
 
 	// Whether to allow plaintext backup
 	AllowPlaintextBackup bool
+
+	// The name of the managed key holding the private key, if the key isn't
+	// to be generated
+	ManagedKeyName string
+
+	// The managed key named by ManagedKeyName
+	ManagedKey logical.ManagedKey
 }
 
 type LockManager struct {
@@ -334,6 +341,20 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest) (retP *
 			return nil, false, fmt.Errorf("unsupported key type %v", req.KeyType)
 		}
 
+		if req.ManagedKeyName != "" {
+			switch {
+			case req.ManagedKey == nil:
+				cleanup()
+				return nil, false, fmt.Errorf("managed key %q is not available", req.ManagedKeyName)
+			case req.KeyType != KeyType_ECDSA_P256 && req.KeyType != KeyType_RSA2048 && req.KeyType != KeyType_RSA4096:
+				cleanup()
+				return nil, false, fmt.Errorf("managed keys not supported for keys of type %v", req.KeyType)
+			case req.Exportable || req.AllowPlaintextBackup:
+				cleanup()
+				return nil, false, fmt.Errorf("keys backed by a managed key can't be exported or backed up")
+			}
+		}
+
 		p = &Policy{
 			l:                    new(sync.RWMutex),
 			Name:                 req.Name,
@@ -341,6 +362,10 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest) (retP *
 			Derived:              req.Derived,
 			Exportable:           req.Exportable,
 			AllowPlaintextBackup: req.AllowPlaintextBackup,
+			ManagedKeyName:       req.ManagedKeyName,
+		}
+		if req.ManagedKey != nil {
+			p.SetManagedKey(req.ManagedKey)
 		}
 
 		if req.Derived {
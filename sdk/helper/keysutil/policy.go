@@ -168,6 +168,9 @@ type KeyEntry struct {
 
 	RSAKey *rsa.PrivateKey `json:"rsa_key"`
 
+	// The public key of RSA keys whose private key is held by a managed key
+	RSAPublicKey *rsa.PublicKey `json:"rsa_public_key,omitempty"`
+
 	// The public key in an appropriate format for the type of key
 	FormattedPublicKey string `json:"public_key"`
 
@@ -180,6 +183,27 @@ type KeyEntry struct {
 	DeprecatedCreationTime int64 `json:"creation_time"`
 }
 
+// rsaPublicKey returns the public key of an RSA key entry
+func (ke KeyEntry) rsaPublicKey() *rsa.PublicKey {
+	if ke.RSAKey != nil {
+		return &ke.RSAKey.PublicKey
+	}
+	return ke.RSAPublicKey
+}
+
+// matchesPublicKey returns whether the public key is the one of the key entry
+func (ke KeyEntry) matchesPublicKey(pub crypto.PublicKey) bool {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		return ke.EC_X != nil && ke.EC_Y != nil && pub.X.Cmp(ke.EC_X) == 0 && pub.Y.Cmp(ke.EC_Y) == 0
+	case *rsa.PublicKey:
+		rsaPub := ke.rsaPublicKey()
+		return rsaPub != nil && pub.N.Cmp(rsaPub.N) == 0 && pub.E == rsaPub.E
+	default:
+		return false
+	}
+}
+
 // deprecatedKeyEntryMap is used to allow JSON marshal/unmarshal
 type deprecatedKeyEntryMap map[int]KeyEntry
 
@@ -342,6 +366,11 @@ type Policy struct {
 	// The type of key
 	Type KeyType `json:"type"`
 
+	// ManagedKeyName is the name of the managed key holding the private key,
+	// for keys whose private key lives in a KMS or HSM. The key versions only
+	// store the public key then.
+	ManagedKeyName string `json:"managed_key_name,omitempty"`
+
 	// BackupInfo indicates the information about the backup action taken on
 	// this policy
 	BackupInfo *BackupInfo `json:"backup_info"`
@@ -353,6 +382,11 @@ type Policy struct {
 	// AllowPlaintextBackup allows taking backup of the policy in plaintext
 	AllowPlaintextBackup bool `json:"allow_plaintext_backup"`
 
+	// StreamMaxChunkSize is the largest chunk, in bytes, that can be
+	// encrypted or decrypted in chunked mode. Chunked mode is disabled if
+	// this is zero.
+	StreamMaxChunkSize int `json:"stream_max_chunk_size"`
+
 	// VersionTemplate is used to prefix the ciphertext with information about
 	// the key version. It must inclide {{version}} and a delimiter between the
 	// version prefix and the ciphertext.
@@ -365,6 +399,44 @@ type Policy struct {
 	// versionPrefixCache stores caches of version prefix strings and the split
 	// version template.
 	versionPrefixCache sync.Map
+
+	// aeadCache holds a map[int]cipher.AEAD of the ciphers constructed for
+	// non-derived key versions so that the key schedule isn't recomputed on
+	// every request. The map is copied on update, so readers holding the
+	// policy's read lock never contend with each other.
+	aeadCache     atomic.Value
+	aeadCacheLock sync.Mutex
+
+	// managedKey holds a managedKeyHolder with the managed key named by
+	// ManagedKeyName, see SetManagedKey
+	managedKey atomic.Value
+}
+
+// managedKeyHolder wraps the managed key of a policy, so that the
+// atomic.Value always holds the same concrete type
+type managedKeyHolder struct {
+	key logical.ManagedKey
+}
+
+// SetManagedKey sets the managed key named by ManagedKeyName, which signs and
+// decrypts on behalf of the policy. Backends resolve the managed key before
+// each operation needing the private key, as the mounts allowed to use it
+// can change.
+func (p *Policy) SetManagedKey(key logical.ManagedKey) {
+	p.managedKey.Store(managedKeyHolder{key: key})
+}
+
+// getManagedKey returns the managed key of the policy, after checking it
+// still holds the private key of the given version
+func (p *Policy) getManagedKey(ver int) (logical.ManagedKey, error) {
+	holder, _ := p.managedKey.Load().(managedKeyHolder)
+	if holder.key == nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("managed key %q is not available", p.ManagedKeyName)}
+	}
+	if !p.Keys[strconv.Itoa(ver)].matchesPublicKey(holder.key.Public()) {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("managed key %q doesn't hold the private key of version %d anymore", p.ManagedKeyName, ver)}
+	}
+	return holder.key, nil
 }
 
 func (p *Policy) Lock(exclusive bool) {
@@ -519,6 +591,10 @@ func (p *Policy) Persist(ctx context.Context, storage logical.Storage) (retErr e
 		return errors.New("key has been deleted, not persisting")
 	}
 
+	// Any change to the keys goes through here, so drop ciphers built from
+	// the previous state
+	p.resetAEADCache()
+
 	// Other functions will take care of restoring other values; this is just
 	// responsible for archiving and keys since the archive function can modify
 	// keys. At the moment one of the other functions calling persist will also
@@ -826,29 +902,9 @@ func (p *Policy) Encrypt(ver int, context, nonce []byte, value string) (string,
 			}
 		}
 
-		switch p.Type {
-		case KeyType_AES256_GCM96:
-			// Setup the cipher
-			aesCipher, err := aes.NewCipher(encKey)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			// Setup the GCM AEAD
-			gcm, err := cipher.NewGCM(aesCipher)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			aead = gcm
-
-		case KeyType_ChaCha20_Poly1305:
-			cha, err := chacha20poly1305.New(encKey)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			aead = cha
+		aead, err = p.getAEAD(ver, encKey)
+		if err != nil {
+			return "", err
 		}
 
 		if p.ConvergentEncryption {
@@ -886,8 +942,8 @@ func (p *Policy) Encrypt(ver int, context, nonce []byte, value string) (string,
 		}
 
 	case KeyType_RSA2048, KeyType_RSA4096:
-		key := p.Keys[strconv.Itoa(ver)].RSAKey
-		ciphertext, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, plaintext, nil)
+		key := p.Keys[strconv.Itoa(ver)].rsaPublicKey()
+		ciphertext, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, key, plaintext, nil)
 		if err != nil {
 			return "", errutil.InternalError{Err: fmt.Sprintf("failed to RSA encrypt the plaintext: %v", err)}
 		}
@@ -970,29 +1026,9 @@ func (p *Policy) Decrypt(context, nonce []byte, value string) (string, error) {
 			return "", errutil.InternalError{Err: "could not derive enc key, length not correct"}
 		}
 
-		switch p.Type {
-		case KeyType_AES256_GCM96:
-			// Setup the cipher
-			aesCipher, err := aes.NewCipher(encKey)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			// Setup the GCM AEAD
-			gcm, err := cipher.NewGCM(aesCipher)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			aead = gcm
-
-		case KeyType_ChaCha20_Poly1305:
-			cha, err := chacha20poly1305.New(encKey)
-			if err != nil {
-				return "", errutil.InternalError{Err: err.Error()}
-			}
-
-			aead = cha
+		aead, err = p.getAEAD(ver, encKey)
+		if err != nil {
+			return "", err
 		}
 
 		if len(decoded) < aead.NonceSize() {
@@ -1015,8 +1051,17 @@ func (p *Policy) Decrypt(context, nonce []byte, value string) (string, error) {
 		}
 
 	case KeyType_RSA2048, KeyType_RSA4096:
-		key := p.Keys[strconv.Itoa(ver)].RSAKey
-		plain, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, key, decoded, nil)
+		if p.ManagedKeyName != "" {
+			var key logical.ManagedKey
+			key, err = p.getManagedKey(ver)
+			if err != nil {
+				return "", err
+			}
+			plain, err = key.Decrypt(rand.Reader, decoded, &rsa.OAEPOptions{Hash: crypto.SHA256})
+		} else {
+			key := p.Keys[strconv.Itoa(ver)].RSAKey
+			plain, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, key, decoded, nil)
+		}
 		if err != nil {
 			return "", errutil.InternalError{Err: fmt.Sprintf("failed to RSA decrypt the ciphertext: %v", err)}
 		}
@@ -1028,6 +1073,71 @@ func (p *Policy) Decrypt(context, nonce []byte, value string) (string, error) {
 	return base64.StdEncoding.EncodeToString(plain), nil
 }
 
+// getAEAD returns the AEAD for the given key version and encryption key.
+// Ciphers for non-derived keys are cached per version; derived keys differ per
+// context and are constructed each time.
+func (p *Policy) getAEAD(ver int, encKey []byte) (cipher.AEAD, error) {
+	if !p.Derived {
+		if cache, ok := p.aeadCache.Load().(map[int]cipher.AEAD); ok {
+			if aead, ok := cache[ver]; ok {
+				return aead, nil
+			}
+		}
+	}
+
+	var aead cipher.AEAD
+	switch p.Type {
+	case KeyType_AES256_GCM96:
+		// Setup the cipher
+		aesCipher, err := aes.NewCipher(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+		// Setup the GCM AEAD
+		gcm, err := cipher.NewGCM(aesCipher)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+		aead = gcm
+
+	case KeyType_ChaCha20_Poly1305:
+		cha, err := chacha20poly1305.New(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+		aead = cha
+
+	default:
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported key type %v", p.Type)}
+	}
+
+	if !p.Derived {
+		p.aeadCacheLock.Lock()
+		current, _ := p.aeadCache.Load().(map[int]cipher.AEAD)
+		updated := make(map[int]cipher.AEAD, len(current)+1)
+		for k, v := range current {
+			updated[k] = v
+		}
+		updated[ver] = aead
+		p.aeadCache.Store(updated)
+		p.aeadCacheLock.Unlock()
+	}
+
+	return aead, nil
+}
+
+func (p *Policy) resetAEADCache() {
+	p.aeadCacheLock.Lock()
+	defer p.aeadCacheLock.Unlock()
+
+	if cache, _ := p.aeadCache.Load().(map[int]cipher.AEAD); len(cache) > 0 {
+		p.aeadCache.Store(map[int]cipher.AEAD{})
+	}
+}
+
 func (p *Policy) HMACKey(version int) ([]byte, error) {
 	switch {
 	case version < 0:
@@ -1065,19 +1175,43 @@ func (p *Policy) Sign(ver int, context, input []byte, hashAlgorithm HashType, si
 	switch p.Type {
 	case KeyType_ECDSA_P256:
 		curveBits := 256
-		keyParams := p.Keys[strconv.Itoa(ver)]
-		key := &ecdsa.PrivateKey{
-			PublicKey: ecdsa.PublicKey{
-				Curve: elliptic.P256(),
-				X:     keyParams.EC_X,
-				Y:     keyParams.EC_Y,
-			},
-			D: keyParams.EC_D,
-		}
+		var r, s *big.Int
+		if p.ManagedKeyName != "" {
+			key, err := p.getManagedKey(ver)
+			if err != nil {
+				return nil, err
+			}
+			algo, ok := cryptoHashMap[hashAlgorithm]
+			if !ok {
+				return nil, errutil.InternalError{Err: "unsupported hash algorithm"}
+			}
 
-		r, s, err := ecdsa.Sign(rand.Reader, key, input)
-		if err != nil {
-			return nil, err
+			// Managed keys return ASN.1 signatures, which are unmarshaled so
+			// that they can be marshaled as requested
+			der, err := key.Sign(rand.Reader, input, algo)
+			if err != nil {
+				return nil, err
+			}
+			var ecdsaSig ecdsaSignature
+			if _, err := asn1.Unmarshal(der, &ecdsaSig); err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("invalid signature returned by managed key: %v", err)}
+			}
+			r, s = ecdsaSig.R, ecdsaSig.S
+		} else {
+			keyParams := p.Keys[strconv.Itoa(ver)]
+			key := &ecdsa.PrivateKey{
+				PublicKey: ecdsa.PublicKey{
+					Curve: elliptic.P256(),
+					X:     keyParams.EC_X,
+					Y:     keyParams.EC_Y,
+				},
+				D: keyParams.EC_D,
+			}
+
+			r, s, err = ecdsa.Sign(rand.Reader, key, input)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		switch marshaling {
@@ -1160,19 +1294,41 @@ func (p *Policy) Sign(ver int, context, input []byte, hashAlgorithm HashType, si
 			sigAlgorithm = "pss"
 		}
 
-		switch sigAlgorithm {
-		case "pss":
-			sig, err = rsa.SignPSS(rand.Reader, key, algo, input, nil)
+		if p.ManagedKeyName != "" {
+			managedKey, err := p.getManagedKey(ver)
 			if err != nil {
 				return nil, err
 			}
-		case "pkcs1v15":
-			sig, err = rsa.SignPKCS1v15(rand.Reader, key, algo, input)
+
+			var opts crypto.SignerOpts
+			switch sigAlgorithm {
+			case "pss":
+				opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: algo}
+			case "pkcs1v15":
+				opts = algo
+			default:
+				return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported rsa signature algorithm %s", sigAlgorithm)}
+			}
+
+			sig, err = managedKey.Sign(rand.Reader, input, opts)
 			if err != nil {
 				return nil, err
 			}
-		default:
-			return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported rsa signature algorithm %s", sigAlgorithm)}
+		} else {
+			switch sigAlgorithm {
+			case "pss":
+				sig, err = rsa.SignPSS(rand.Reader, key, algo, input, nil)
+				if err != nil {
+					return nil, err
+				}
+			case "pkcs1v15":
+				sig, err = rsa.SignPKCS1v15(rand.Reader, key, algo, input)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported rsa signature algorithm %s", sigAlgorithm)}
+			}
 		}
 
 	default:
@@ -1291,7 +1447,7 @@ func (p *Policy) VerifySignature(context, input []byte, hashAlgorithm HashType,
 		return ed25519.Verify(key.Public().(ed25519.PublicKey), input, sigBytes), nil
 
 	case KeyType_RSA2048, KeyType_RSA4096:
-		key := p.Keys[strconv.Itoa(ver)].RSAKey
+		key := p.Keys[strconv.Itoa(ver)].rsaPublicKey()
 
 		var algo crypto.Hash
 		switch hashAlgorithm {
@@ -1315,9 +1471,9 @@ func (p *Policy) VerifySignature(context, input []byte, hashAlgorithm HashType,
 
 		switch sigAlgorithm {
 		case "pss":
-			err = rsa.VerifyPSS(&key.PublicKey, algo, input, sigBytes, nil)
+			err = rsa.VerifyPSS(key, algo, input, sigBytes, nil)
 		case "pkcs1v15":
-			err = rsa.VerifyPKCS1v15(&key.PublicKey, algo, input, sigBytes)
+			err = rsa.VerifyPKCS1v15(key, algo, input, sigBytes)
 		default:
 			return false, errutil.InternalError{Err: fmt.Sprintf("unsupported rsa signature algorithm %s", sigAlgorithm)}
 		}
@@ -1330,6 +1486,10 @@ func (p *Policy) VerifySignature(context, input []byte, hashAlgorithm HashType,
 }
 
 func (p *Policy) Rotate(ctx context.Context, storage logical.Storage) (retErr error) {
+	if p.ManagedKeyName != "" && p.LatestVersion > 0 {
+		return errutil.UserError{Err: "keys backed by a managed key can't be rotated, as their private key is held by the managed key"}
+	}
+
 	priorLatestVersion := p.LatestVersion
 	priorMinDecryptionVersion := p.MinDecryptionVersion
 	var priorKeys keyEntryMap
@@ -1379,14 +1539,23 @@ func (p *Policy) Rotate(ctx context.Context, storage logical.Storage) (retErr er
 		entry.Key = newKey
 
 	case KeyType_ECDSA_P256:
-		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-		if err != nil {
-			return err
+		var pubKey *ecdsa.PublicKey
+		if p.ManagedKeyName != "" {
+			pubKey, err = p.managedECDSAPublicKey()
+			if err != nil {
+				return err
+			}
+		} else {
+			privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				return err
+			}
+			entry.EC_D = privKey.D
+			pubKey = &privKey.PublicKey
 		}
-		entry.EC_D = privKey.D
-		entry.EC_X = privKey.X
-		entry.EC_Y = privKey.Y
-		derBytes, err := x509.MarshalPKIXPublicKey(privKey.Public())
+		entry.EC_X = pubKey.X
+		entry.EC_Y = pubKey.Y
+		derBytes, err := x509.MarshalPKIXPublicKey(pubKey)
 		if err != nil {
 			return errwrap.Wrapf("error marshaling public key: {{err}}", err)
 		}
@@ -1414,6 +1583,14 @@ func (p *Policy) Rotate(ctx context.Context, storage logical.Storage) (retErr er
 			bitSize = 4096
 		}
 
+		if p.ManagedKeyName != "" {
+			entry.RSAPublicKey, err = p.managedRSAPublicKey(bitSize)
+			if err != nil {
+				return err
+			}
+			break
+		}
+
 		entry.RSAKey, err = rsa.GenerateKey(rand.Reader, bitSize)
 		if err != nil {
 			return err
@@ -1438,6 +1615,34 @@ func (p *Policy) Rotate(ctx context.Context, storage logical.Storage) (retErr er
 	return p.Persist(ctx, storage)
 }
 
+// managedECDSAPublicKey returns the public key of the managed key of an ECDSA
+// policy
+func (p *Policy) managedECDSAPublicKey() (*ecdsa.PublicKey, error) {
+	holder, _ := p.managedKey.Load().(managedKeyHolder)
+	if holder.key == nil {
+		return nil, fmt.Errorf("managed key %q is not available", p.ManagedKeyName)
+	}
+	pubKey, ok := holder.key.Public().(*ecdsa.PublicKey)
+	if !ok || pubKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("managed key %q is not a P-256 ECDSA key", p.ManagedKeyName)
+	}
+	return pubKey, nil
+}
+
+// managedRSAPublicKey returns the public key of the managed key of an RSA
+// policy, which must have the given size
+func (p *Policy) managedRSAPublicKey(bitSize int) (*rsa.PublicKey, error) {
+	holder, _ := p.managedKey.Load().(managedKeyHolder)
+	if holder.key == nil {
+		return nil, fmt.Errorf("managed key %q is not available", p.ManagedKeyName)
+	}
+	pubKey, ok := holder.key.Public().(*rsa.PublicKey)
+	if !ok || pubKey.N.BitLen() != bitSize {
+		return nil, fmt.Errorf("managed key %q is not a %d bit RSA key", p.ManagedKeyName, bitSize)
+	}
+	return pubKey, nil
+}
+
 func (p *Policy) MigrateKeyToKeysMap() {
 	now := time.Now()
 	p.Keys = keyEntryMap{
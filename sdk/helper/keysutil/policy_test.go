@@ -2,6 +2,7 @@ package keysutil
 
 import (
 	"context"
+	"crypto/cipher"
 	"reflect"
 	"strconv"
 	"sync"
@@ -607,3 +608,105 @@ func Test_BadArchive(t *testing.T) {
 		t.Fatalf("unexpected key length %d", len(p.Keys))
 	}
 }
+
+func Test_AEADCache(t *testing.T) {
+	ctx := context.Background()
+	lm := NewLockManager(false)
+	storage := &logical.InmemStorage{}
+
+	p, _, err := lm.GetPolicy(ctx, PolicyRequest{
+		Upsert:  true,
+		Storage: storage,
+		KeyType: KeyType_AES256_GCM96,
+		Name:    "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := p.Encrypt(0, nil, nil, "dGhlIHF1aWNrIGJyb3duIGZveA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, _ := p.aeadCache.Load().(map[int]cipher.AEAD)
+	if _, ok := cache[1]; !ok {
+		t.Fatal("expected the cipher for version 1 to be cached")
+	}
+
+	// Rotating persists the policy, which must drop the cached ciphers
+	if err := p.Rotate(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	cache, _ = p.aeadCache.Load().(map[int]cipher.AEAD)
+	if len(cache) != 0 {
+		t.Fatalf("expected cache to be reset, got %d entries", len(cache))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plaintext, err := p.Decrypt(nil, nil, ciphertext)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if plaintext != "dGhlIHF1aWNrIGJyb3duIGZveA==" {
+				t.Errorf("bad plaintext: %q", plaintext)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Derived keys depend on the context, so their ciphers are never cached
+	derived, _, err := lm.GetPolicy(ctx, PolicyRequest{
+		Upsert:  true,
+		Storage: storage,
+		KeyType: KeyType_AES256_GCM96,
+		Name:    "derived",
+		Derived: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := derived.Encrypt(0, []byte("context"), nil, "dGhlIHF1aWNrIGJyb3duIGZveA=="); err != nil {
+		t.Fatal(err)
+	}
+	cache, _ = derived.aeadCache.Load().(map[int]cipher.AEAD)
+	if len(cache) != 0 {
+		t.Fatal("expected no cached ciphers for a derived key")
+	}
+}
+
+func BenchmarkPolicy_Decrypt(b *testing.B) {
+	ctx := context.Background()
+	lm := NewLockManager(false)
+	storage := &logical.InmemStorage{}
+
+	p, _, err := lm.GetPolicy(ctx, PolicyRequest{
+		Upsert:  true,
+		Storage: storage,
+		KeyType: KeyType_AES256_GCM96,
+		Name:    "test",
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	ciphertext, err := p.Encrypt(0, nil, nil, "dGhlIHF1aWNrIGJyb3duIGZveA==")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Lock(false)
+			_, err := p.Decrypt(nil, nil, ciphertext)
+			p.Unlock()
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package ldaputil
 
 import (
+	"bytes"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"text/template"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/tlsutil"
 
 	"github.com/hashicorp/errwrap"
@@ -83,7 +85,7 @@ Default: cn`,
 
 		"certificate": {
 			Type:        framework.TypeString,
-			Description: "CA certificate to use when verifying LDAP server certificate, must be x509 PEM encoded (optional)",
+			Description: "CA certificates to use when verifying LDAP server certificate, must be x509 PEM encoded or a JSON-encoded certificate bundle, such as the output of the PKI secrets engine (optional)",
 		},
 
 		"discoverdn": {
@@ -182,14 +184,19 @@ func NewConfigEntry(d *framework.FieldData) (*ConfigEntry, error) {
 	}
 	certificate := d.Get("certificate").(string)
 	if certificate != "" {
-		block, _ := pem.Decode([]byte(certificate))
-
-		if block == nil || block.Type != "CERTIFICATE" {
-			return nil, fmt.Errorf("failed to decode PEM block in the certificate")
+		// A JSON-encoded certificate bundle is stored as its CA certificates
+		if strings.HasPrefix(strings.TrimSpace(certificate), "{") {
+			bundle, err := certutil.ParsePKIJSON([]byte(certificate))
+			if err != nil {
+				return nil, errwrap.Wrapf("failed to parse certificate bundle: {{err}}", err)
+			}
+			certificate, err = CACertificatesFromBundle(bundle)
+			if err != nil {
+				return nil, err
+			}
 		}
-		_, err := x509.ParseCertificate(block.Bytes)
-		if err != nil {
-			return nil, errwrap.Wrapf("failed to parse certificate: {{err}}", err)
+		if err := validateCertificates(certificate); err != nil {
+			return nil, err
 		}
 		cfg.Certificate = certificate
 	}
@@ -338,14 +345,54 @@ func (c *ConfigEntry) Validate() error {
 		return errors.New("'tls_max_version' must be greater than or equal to 'tls_min_version'")
 	}
 	if c.Certificate != "" {
-		block, _ := pem.Decode([]byte(c.Certificate))
+		if err := validateCertificates(c.Certificate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CACertificatesFromBundle returns the PEM-encoded CA certificates of a
+// certificate bundle, to trust when verifying the LDAP server certificate.
+// These are the certificate of the bundle, if it's a CA, and its issuing
+// chain; the private key, if any, is ignored.
+func CACertificatesFromBundle(bundle *certutil.ParsedCertBundle) (string, error) {
+	certPath := bundle.CAChain
+	if bundle.Certificate != nil {
+		certPath = bundle.GetCertificatePath()
+	}
+
+	var caCerts []string
+	for _, cert := range certPath {
+		if cert.Certificate == nil || !cert.Certificate.IsCA {
+			continue
+		}
+		caCerts = append(caCerts, strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Bytes,
+		}))))
+	}
+	if len(caCerts) == 0 {
+		return "", errors.New("no CA certificate found in the certificate bundle")
+	}
+	return strings.Join(caCerts, "\n"), nil
+}
+
+// validateCertificates ensures that certificate holds one or more PEM-encoded
+// certificates
+func validateCertificates(certificate string) error {
+	rest := []byte(certificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
 		if block == nil || block.Type != "CERTIFICATE" {
 			return errors.New("failed to decode PEM block in the certificate")
 		}
-		_, err := x509.ParseCertificate(block.Bytes)
-		if err != nil {
-			return fmt.Errorf("failed to parse certificate %s", err.Error())
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return errwrap.Wrapf("failed to parse certificate: {{err}}", err)
+		}
+		if len(bytes.TrimSpace(rest)) == 0 {
+			return nil
 		}
 	}
-	return nil
 }
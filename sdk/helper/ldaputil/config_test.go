@@ -2,7 +2,10 @@ package ldaputil
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
 )
 
 func TestCertificateValidation(t *testing.T) {
@@ -26,6 +29,64 @@ func TestCertificateValidation(t *testing.T) {
 	if err := config.Validate(); err != nil {
 		t.Fatal(err)
 	}
+
+	// several certificates can be given
+	config.Certificate = validCertificate + validCertificate
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.Certificate = validCertificate + "cats"
+	if err := config.Validate(); err == nil {
+		t.Fatal("should err due to bad cert")
+	}
+}
+
+func TestCertificateBundle(t *testing.T) {
+	newConfigEntry := func(certificate string) (*ConfigEntry, error) {
+		return NewConfigEntry(&framework.FieldData{
+			Raw: map[string]interface{}{
+				"certificate": certificate,
+			},
+			Schema: ConfigFields(),
+		})
+	}
+
+	// the CA certificates of a certificate bundle are stored
+	bundle, err := json.Marshal(map[string]interface{}{
+		"certificate": validCertificate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := newConfigEntry(string(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(config.Certificate) != strings.TrimSpace(validCertificate) {
+		t.Fatalf("expected the CA certificate of the bundle but received %s", config.Certificate)
+	}
+	if _, err := getTLSConfig(config, "138.91.247.105"); err != nil {
+		t.Fatal(err)
+	}
+
+	// as well as its CA chain alone
+	bundle, err = json.Marshal(map[string]interface{}{
+		"ca_chain": []string{validCertificate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = newConfigEntry(string(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(config.Certificate) != strings.TrimSpace(validCertificate) {
+		t.Fatalf("expected the CA certificate of the bundle but received %s", config.Certificate)
+	}
+
+	if _, err := newConfigEntry(`{"certificate": "cats"}`); err == nil {
+		t.Fatal("should err due to bad bundle")
+	}
 }
 
 func TestUseTokenGroupsDefault(t *testing.T) {
@@ -0,0 +1,162 @@
+package ldaputil
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap"
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	// DefaultPoolMaxIdle is the number of idle connections kept by default
+	DefaultPoolMaxIdle = 5
+
+	// DefaultPoolIdleTimeout is how long idle connections are kept by default.
+	// It is kept below the idle timeouts of the common LDAP servers, which
+	// drop idle connections after a few minutes.
+	DefaultPoolIdleTimeout = 2 * time.Minute
+)
+
+type idleConnection struct {
+	conn     Connection
+	lastUsed time.Time
+}
+
+// ConnectionPool keeps LDAP connections open between requests to reuse them,
+// saving the connection and TLS handshakes. Idle connections are checked
+// before being reused, and dropped when the connection settings change.
+//
+// A connection must only be returned to the pool while bound as the service
+// account (binddn), never as a user that authenticated with it.
+type ConnectionPool struct {
+	client      *Client
+	maxIdle     int
+	idleTimeout time.Duration
+
+	l sync.Mutex
+
+	// key identifies the connection settings of the idle connections
+	key  string
+	idle []*idleConnection
+}
+
+// NewConnectionPool returns a pool dialing connections with the client and
+// keeping up to maxIdle idle connections for at most idleTimeout.
+func NewConnectionPool(client *Client, maxIdle int, idleTimeout time.Duration) *ConnectionPool {
+	return &ConnectionPool{
+		client:      client,
+		maxIdle:     maxIdle,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Get returns a healthy idle connection matching the connection settings of
+// cfg, or dials a new one.
+func (p *ConnectionPool) Get(cfg *ConfigEntry) (Connection, error) {
+	key := poolKey(cfg)
+	for {
+		conn := p.popIdle(key)
+		if conn == nil {
+			break
+		}
+		if err := checkConnection(conn); err != nil {
+			if p.client.Logger.IsDebug() {
+				p.client.Logger.Debug("dropping unhealthy idle ldap connection", "error", err)
+			}
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+
+	return p.client.DialLDAP(cfg)
+}
+
+// Put returns a connection obtained with the same cfg to the pool, or closes
+// it if the pool is full or the connection settings changed meanwhile.
+func (p *ConnectionPool) Put(cfg *ConfigEntry, conn Connection) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.key != poolKey(cfg) || len(p.idle) >= p.maxIdle {
+		conn.Close()
+		return
+	}
+	p.idle = append(p.idle, &idleConnection{
+		conn:     conn,
+		lastUsed: time.Now(),
+	})
+}
+
+// Close closes the idle connections
+func (p *ConnectionPool) Close() {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	for _, idle := range p.idle {
+		idle.conn.Close()
+	}
+	p.idle = nil
+}
+
+// popIdle removes the most recently used idle connection from the pool and
+// returns it, or nil if there is none. Expired connections, and connections
+// with other settings than key, are closed.
+func (p *ConnectionPool) popIdle(key string) Connection {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.key != key {
+		for _, idle := range p.idle {
+			idle.conn.Close()
+		}
+		p.idle = nil
+		p.key = key
+	}
+
+	for len(p.idle) > 0 {
+		idle := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if time.Since(idle.lastUsed) > p.idleTimeout {
+			idle.conn.Close()
+			continue
+		}
+		return idle.conn
+	}
+	return nil
+}
+
+// poolKey returns the settings of cfg determining how connections are
+// established; connections established with other settings aren't reused
+func poolKey(cfg *ConfigEntry) string {
+	return strings.Join([]string{
+		cfg.Url,
+		cfg.Certificate,
+		strconv.FormatBool(cfg.InsecureTLS),
+		strconv.FormatBool(cfg.StartTLS),
+		cfg.TLSMinVersion,
+		cfg.TLSMaxVersion,
+	}, "\x00")
+}
+
+// checkConnection ensures that an idle connection is still usable, by reading
+// the root DSE
+func checkConnection(conn Connection) error {
+	if c, ok := conn.(interface{ IsClosing() bool }); ok && c.IsClosing() {
+		return errors.New("connection is closed")
+	}
+	_, err := conn.Search(&ldap.SearchRequest{
+		Scope:      ldap.ScopeBaseObject,
+		Filter:     "(objectClass=*)",
+		Attributes: []string{"1.1"},
+		SizeLimit:  1,
+	})
+	if err != nil {
+		return errwrap.Wrapf("failed to read the root DSE: {{err}}", err)
+	}
+	return nil
+}
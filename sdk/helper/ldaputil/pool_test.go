@@ -0,0 +1,113 @@
+package ldaputil
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap"
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+type fakeConnection struct {
+	healthy bool
+	closed  bool
+}
+
+func (c *fakeConnection) Bind(username, password string) error { return nil }
+func (c *fakeConnection) Close()                               { c.closed = true }
+func (c *fakeConnection) Modify(modifyRequest *ldap.ModifyRequest) error {
+	return nil
+}
+func (c *fakeConnection) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if !c.healthy {
+		return nil, errors.New("connection reset by peer")
+	}
+	return &ldap.SearchResult{}, nil
+}
+func (c *fakeConnection) StartTLS(config *tls.Config) error         { return nil }
+func (c *fakeConnection) UnauthenticatedBind(username string) error { return nil }
+
+type fakeLDAP struct {
+	dialed []*fakeConnection
+}
+
+func (l *fakeLDAP) Dial(network, addr string) (Connection, error) {
+	conn := &fakeConnection{healthy: true}
+	l.dialed = append(l.dialed, conn)
+	return conn, nil
+}
+
+func (l *fakeLDAP) DialTLS(network, addr string, config *tls.Config) (Connection, error) {
+	return l.Dial(network, addr)
+}
+
+func TestConnectionPool(t *testing.T) {
+	fake := &fakeLDAP{}
+	pool := NewConnectionPool(&Client{
+		Logger: hclog.NewNullLogger(),
+		LDAP:   fake,
+	}, 1, time.Minute)
+	config := testConfig()
+
+	get := func(config *ConfigEntry) Connection {
+		t.Helper()
+		conn, err := pool.Get(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	// Idle connections are reused
+	conn := get(config)
+	pool.Put(config, conn)
+	if get(config) != conn || len(fake.dialed) != 1 {
+		t.Fatalf("expected the idle connection to be reused, dialed %d", len(fake.dialed))
+	}
+
+	// Connections beyond the idle limit are closed
+	other := get(config)
+	pool.Put(config, conn)
+	pool.Put(config, other)
+	if !other.(*fakeConnection).closed {
+		t.Fatal("expected the connection beyond the idle limit to be closed")
+	}
+
+	// Unhealthy connections are dropped
+	conn.(*fakeConnection).healthy = false
+	if get(config) == conn || !conn.(*fakeConnection).closed {
+		t.Fatal("expected the unhealthy connection to be dropped")
+	}
+
+	// Connections with other settings aren't reused
+	conn = get(config)
+	pool.Put(config, conn)
+	changed := testConfig()
+	changed.StartTLS = true
+	if get(changed) == conn || !conn.(*fakeConnection).closed {
+		t.Fatal("expected the connection with other settings to be dropped")
+	}
+	// nor returned to the pool
+	conn = get(changed)
+	pool.Put(config, conn)
+	if !conn.(*fakeConnection).closed {
+		t.Fatal("expected the connection with other settings to be closed")
+	}
+
+	// Expired connections are dropped
+	pool = NewConnectionPool(pool.client, 1, 0)
+	conn = get(config)
+	pool.Put(config, conn)
+	if get(config) == conn || !conn.(*fakeConnection).closed {
+		t.Fatal("expected the expired connection to be dropped")
+	}
+
+	conn = get(config)
+	pool.Put(config, conn)
+	pool.Close()
+	if !conn.(*fakeConnection).closed {
+		t.Fatal("expected the idle connection to be closed")
+	}
+}
@@ -8,3 +8,44 @@ func Test_CreateLocks(t *testing.T) {
 		t.Fatalf("bad: len(locks): expected:256 actual:%d", len(locks))
 	}
 }
+
+func Test_LocksForKeys(t *testing.T) {
+	locks := CreateLocks()
+
+	if LockForKey(locks, "foo") != LockForKey(locks, "foo") {
+		t.Fatal("expected the same lock for the same key")
+	}
+
+	keys := []string{"foo", "bar", "baz", "foo"}
+	keyLocks := LocksForKeys(locks, keys)
+	if len(keyLocks) == 0 || len(keyLocks) > 3 {
+		t.Fatalf("bad: len(keyLocks): %d", len(keyLocks))
+	}
+
+	// The locks are returned in the order of the lock slice, so that locking
+	// them in turn can't deadlock
+	last := -1
+	for _, l := range keyLocks {
+		var index int
+		for i := range locks {
+			if locks[i] == l {
+				index = i
+			}
+		}
+		if index <= last {
+			t.Fatalf("locks out of order")
+		}
+		last = index
+	}
+	for _, k := range keys {
+		found := false
+		for _, l := range keyLocks {
+			if l == LockForKey(locks, k) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("missing lock for key %q", k)
+		}
+	}
+}
@@ -13,3 +13,20 @@ func Supported() bool {
 func LockMemory() error {
 	return lockMemory()
 }
+
+// LockBytes prevents the memory pages backing b from being swapped to disk
+// and, where the platform supports it, excludes them from core dumps. It is
+// intended for key material when locking all memory isn't possible, and is a
+// no-op on systems that don't support mlock.
+func LockBytes(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return lockBytes(b)
+}
+
+// DisableCoreDumps prevents the process from writing core dumps, which could
+// otherwise contain sensitive memory.
+func DisableCoreDumps() error {
+	return disableCoreDumps()
+}
@@ -0,0 +1,40 @@
+//go:build linux
+// +build linux
+
+package mlock
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func lockBytes(b []byte) error {
+	// madvise requires a page-aligned address, so operate on the whole pages
+	// containing b. The syscalls are made directly to avoid converting the
+	// aligned address back into a pointer.
+	pageSize := uintptr(os.Getpagesize())
+	addr := uintptr(unsafe.Pointer(&b[0]))
+	start := addr &^ (pageSize - 1)
+	length := (addr + uintptr(len(b)) - start + pageSize - 1) &^ (pageSize - 1)
+
+	if _, _, errno := unix.Syscall(unix.SYS_MLOCK, start, length, 0); errno != 0 {
+		return errno
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_MADVISE, start, length, unix.MADV_DONTDUMP); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func disableCoreDumps() error {
+	if err := unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{Cur: 0, Max: 0}); err != nil {
+		return err
+	}
+
+	// Marking the process as not dumpable also prevents other processes
+	// running as the same user from attaching to it or reading its memory
+	// through /proc.
+	return unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+}
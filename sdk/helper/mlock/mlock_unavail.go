@@ -1,3 +1,4 @@
+//go:build android || darwin || nacl || netbsd || plan9 || windows
 // +build android darwin nacl netbsd plan9 windows
 
 package mlock
@@ -11,3 +12,11 @@ func lockMemory() error {
 	// method, but it requires a specific address and offset.
 	return nil
 }
+
+func lockBytes(b []byte) error {
+	return nil
+}
+
+func disableCoreDumps() error {
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build dragonfly || freebsd || openbsd || solaris
+// +build dragonfly freebsd openbsd solaris
+
+package mlock
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func lockBytes(b []byte) error {
+	return unix.Mlock(b)
+}
+
+func disableCoreDumps() error {
+	return unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{Cur: 0, Max: 0})
+}
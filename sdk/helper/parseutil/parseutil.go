@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +15,9 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
+// ParseDurationSecond parses a duration given as a number of seconds, either
+// as a number or a string, or as a string in the Go duration format such as
+// "90s" or "1h30m".
 func ParseDurationSecond(in interface{}) (time.Duration, error) {
 	var dur time.Duration
 	jsonIn, ok := in.(json.Number)
@@ -22,37 +26,40 @@ func ParseDurationSecond(in interface{}) (time.Duration, error) {
 	}
 	switch in.(type) {
 	case string:
-		inp := in.(string)
+		inp := strings.TrimSpace(in.(string))
 		if inp == "" {
 			return time.Duration(0), nil
 		}
+		// Look for a plain second value, otherwise it's a duration string
+		if secs, err := strconv.ParseInt(inp, 10, 64); err == nil {
+			return intSecondsToDuration(secs)
+		}
+		if secs, err := strconv.ParseFloat(inp, 64); err == nil {
+			return floatSecondsToDuration(secs)
+		}
 		var err error
-		// Look for a suffix otherwise its a plain second value
-		if strings.HasSuffix(inp, "s") || strings.HasSuffix(inp, "m") || strings.HasSuffix(inp, "h") || strings.HasSuffix(inp, "ms") {
-			dur, err = time.ParseDuration(inp)
-			if err != nil {
-				return dur, err
-			}
-		} else {
-			// Plain integer
-			secs, err := strconv.ParseInt(inp, 10, 64)
-			if err != nil {
-				return dur, err
-			}
-			dur = time.Duration(secs) * time.Second
+		dur, err = time.ParseDuration(inp)
+		if err != nil {
+			return dur, err
 		}
 	case int:
-		dur = time.Duration(in.(int)) * time.Second
+		return intSecondsToDuration(int64(in.(int)))
 	case int32:
-		dur = time.Duration(in.(int32)) * time.Second
+		return intSecondsToDuration(int64(in.(int32)))
 	case int64:
-		dur = time.Duration(in.(int64)) * time.Second
+		return intSecondsToDuration(in.(int64))
 	case uint:
-		dur = time.Duration(in.(uint)) * time.Second
+		return uintSecondsToDuration(uint64(in.(uint)))
 	case uint32:
-		dur = time.Duration(in.(uint32)) * time.Second
+		return uintSecondsToDuration(uint64(in.(uint32)))
 	case uint64:
-		dur = time.Duration(in.(uint64)) * time.Second
+		return uintSecondsToDuration(in.(uint64))
+	case float32:
+		return floatSecondsToDuration(float64(in.(float32)))
+	case float64:
+		return floatSecondsToDuration(in.(float64))
+	case time.Duration:
+		dur = in.(time.Duration)
 	default:
 		return 0, errors.New("could not parse duration from input")
 	}
@@ -60,6 +67,34 @@ func ParseDurationSecond(in interface{}) (time.Duration, error) {
 	return dur, nil
 }
 
+var errDurationOutOfRange = errors.New("duration is out of range")
+
+func intSecondsToDuration(secs int64) (time.Duration, error) {
+	if secs > math.MaxInt64/int64(time.Second) || secs < math.MinInt64/int64(time.Second) {
+		return 0, errDurationOutOfRange
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+func uintSecondsToDuration(secs uint64) (time.Duration, error) {
+	if secs > math.MaxInt64/uint64(time.Second) {
+		return 0, errDurationOutOfRange
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+func floatSecondsToDuration(secs float64) (time.Duration, error) {
+	if math.IsNaN(secs) || math.IsInf(secs, 0) {
+		return 0, errors.New("duration must be a finite number")
+	}
+	// MaxInt64 rounds up to 2^63 as a float64, which is out of range
+	nanos := secs * float64(time.Second)
+	if nanos >= math.MaxInt64 || nanos < math.MinInt64 {
+		return 0, errDurationOutOfRange
+	}
+	return time.Duration(nanos), nil
+}
+
 func ParseInt(in interface{}) (int64, error) {
 	var ret int64
 	jsonIn, ok := in.(json.Number)
@@ -85,10 +120,16 @@ func ParseInt(in interface{}) (int64, error) {
 	case int64:
 		ret = in.(int64)
 	case uint:
+		if uint64(in.(uint)) > math.MaxInt64 {
+			return 0, errors.New("value is out of range")
+		}
 		ret = int64(in.(uint))
 	case uint32:
 		ret = int64(in.(uint32))
 	case uint64:
+		if in.(uint64) > math.MaxInt64 {
+			return 0, errors.New("value is out of range")
+		}
 		ret = int64(in.(uint64))
 	default:
 		return 0, errors.New("could not parse value from input")
@@ -97,7 +138,30 @@ func ParseInt(in interface{}) (int64, error) {
 	return ret, nil
 }
 
+// ParseBool parses a boolean given as a bool, a number, or a string such as
+// "true", "false", "1" or "0". Strings are case insensitive.
 func ParseBool(in interface{}) (bool, error) {
+	if jsonIn, ok := in.(json.Number); ok {
+		in = jsonIn.String()
+	}
+	if inp, ok := in.(string); ok {
+		inp = strings.ToLower(strings.TrimSpace(inp))
+		if inp == "" {
+			return false, nil
+		}
+		if f, err := strconv.ParseFloat(inp, 64); err == nil {
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return false, fmt.Errorf("could not parse %q as a bool", in)
+			}
+			return f != 0, nil
+		}
+		result, err := strconv.ParseBool(inp)
+		if err != nil {
+			return false, fmt.Errorf("could not parse %q as a bool", in)
+		}
+		return result, nil
+	}
+
 	var result bool
 	if err := mapstructure.WeakDecode(in, &result); err != nil {
 		return false, err
@@ -105,6 +169,84 @@ func ParseBool(in interface{}) (bool, error) {
 	return result, nil
 }
 
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a size in bytes given as a number, or as a string with an
+// optional unit such as "512kb" or "1 GiB". Units are case insensitive;
+// kb, mb, gb and tb are powers of 1000, and kib, mib, gib and tib are powers
+// of 1024.
+func ParseSize(in interface{}) (int64, error) {
+	switch inp := in.(type) {
+	case json.Number:
+		in = inp.String()
+	case float32:
+		in = strconv.FormatFloat(float64(inp), 'f', -1, 32)
+	case float64:
+		in = strconv.FormatFloat(inp, 'f', -1, 64)
+	}
+
+	inp, ok := in.(string)
+	if !ok {
+		size, err := ParseInt(in)
+		if err != nil {
+			return 0, err
+		}
+		if size < 0 {
+			return 0, fmt.Errorf("size must not be negative")
+		}
+		return size, nil
+	}
+
+	inp = strings.ToLower(strings.TrimSpace(inp))
+	if inp == "" {
+		return 0, nil
+	}
+
+	i := strings.IndexFunc(inp, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == 0 {
+		return 0, fmt.Errorf("could not parse size from %q", in)
+	}
+	number, unit := inp, ""
+	if i > 0 {
+		number, unit = inp[:i], strings.TrimSpace(inp[i:])
+	}
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", unit)
+	}
+
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64/multiplier {
+			return 0, fmt.Errorf("size %q is too large", in)
+		}
+		return n * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse size from %q", in)
+	}
+	size := f * float64(multiplier)
+	if math.IsNaN(size) || size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", in)
+	}
+	return int64(size), nil
+}
+
 func ParseCommaStringSlice(in interface{}) ([]string, error) {
 	rawString, ok := in.(string)
 	if ok && rawString == "" {
@@ -2,6 +2,7 @@ package parseutil
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 )
@@ -53,3 +54,105 @@ func Test_ParseBool(t *testing.T) {
 		t.Fatal("wrong output")
 	}
 }
+
+func Test_ParseDurationSecond_Units(t *testing.T) {
+	cases := map[interface{}]time.Duration{
+		"90s":              90 * time.Second,
+		"1h30m":            90 * time.Minute,
+		" 15 ":             15 * time.Second,
+		"1.5":              1500 * time.Millisecond,
+		"250ms":            250 * time.Millisecond,
+		"":                 0,
+		30.0:               30 * time.Second,
+		json.Number("60"):  time.Minute,
+		2 * time.Hour:      2 * time.Hour,
+		int64(7):           7 * time.Second,
+		json.Number("0.5"): 500 * time.Millisecond,
+	}
+	for in, expected := range cases {
+		outp, err := ParseDurationSecond(in)
+		if err != nil {
+			t.Fatalf("%#v: %v", in, err)
+		}
+		if outp != expected {
+			t.Fatalf("%#v: expected %s, got %s", in, expected, outp)
+		}
+	}
+
+	for _, in := range []interface{}{
+		"1 hour", "10x", true,
+		"NaN", "inf", "-Inf", math.NaN(), math.Inf(1),
+		"9223372036854775807", int64(math.MaxInt64), uint64(math.MaxUint64), 1e19, "-1e19",
+	} {
+		if _, err := ParseDurationSecond(in); err == nil {
+			t.Fatalf("%#v: expected error", in)
+		}
+	}
+}
+
+func Test_ParseBool_Inputs(t *testing.T) {
+	cases := map[interface{}]bool{
+		"TRUE":             true,
+		" false ":          false,
+		"1":                true,
+		"0":                false,
+		"":                 false,
+		json.Number("1"):   true,
+		json.Number("0"):   false,
+		json.Number("1.0"): true,
+		0:                  false,
+		1.0:                true,
+		false:              false,
+	}
+	for in, expected := range cases {
+		outp, err := ParseBool(in)
+		if err != nil {
+			t.Fatalf("%#v: %v", in, err)
+		}
+		if outp != expected {
+			t.Fatalf("%#v: expected %t, got %t", in, expected, outp)
+		}
+	}
+
+	for _, in := range []interface{}{"maybe", "NaN", "inf", json.Number("-Inf")} {
+		if _, err := ParseBool(in); err == nil {
+			t.Fatalf("%#v: expected error", in)
+		}
+	}
+
+	if _, err := ParseInt(uint64(math.MaxUint64)); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func Test_ParseSize(t *testing.T) {
+	cases := map[interface{}]int64{
+		"512":               512,
+		"512b":              512,
+		"512kb":             512000,
+		"512KiB":            512 * 1024,
+		"1 GiB":             1 << 30,
+		"1.5mb":             1500000,
+		"2TB":               2000000000000,
+		"":                  0,
+		42:                  42,
+		4096.0:              4096,
+		json.Number("100"):  100,
+		json.Number("10.0"): 10,
+	}
+	for in, expected := range cases {
+		outp, err := ParseSize(in)
+		if err != nil {
+			t.Fatalf("%#v: %v", in, err)
+		}
+		if outp != expected {
+			t.Fatalf("%#v: expected %d, got %d", in, expected, outp)
+		}
+	}
+
+	for _, in := range []interface{}{"-1kb", -1, "kb", "10 parsecs", "1.2.3mb", "10000000tib", math.NaN(), math.Inf(1), true} {
+		if _, err := ParseSize(in); err == nil {
+			t.Fatalf("%#v: expected error", in)
+		}
+	}
+}
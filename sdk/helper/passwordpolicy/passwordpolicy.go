@@ -0,0 +1,260 @@
+// Package passwordpolicy generates random passwords and usernames from
+// declarative policies, so that secrets engines creating credentials in
+// external systems can let operators match the requirements of those systems.
+package passwordpolicy
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/vault/sdk/helper/hclutil"
+)
+
+const (
+	LowercaseCharset    = "abcdefghijklmnopqrstuvwxyz"
+	UppercaseCharset    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	NumericCharset      = "0123456789"
+	AlphaNumericCharset = LowercaseCharset + UppercaseCharset + NumericCharset
+	SymbolCharset       = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// DefaultLength is the length of passwords if the policy doesn't set one
+	DefaultLength = 20
+
+	// MaxLength is the longest password a policy can generate
+	MaxLength = 4096
+)
+
+// DefaultPolicy generates passwords compatible with the ones generated before
+// policies could be configured: alphanumeric, with at least one lowercase
+// letter, one uppercase letter and one digit.
+var DefaultPolicy = &Policy{
+	Length: DefaultLength,
+	Rules: []*CharsetRule{
+		{Charset: LowercaseCharset, MinChars: 1},
+		{Charset: UppercaseCharset, MinChars: 1},
+		{Charset: NumericCharset, MinChars: 1},
+	},
+}
+
+// Policy describes the passwords to generate
+type Policy struct {
+	// Length is the number of characters of the password. Defaults to
+	// DefaultLength.
+	Length int `hcl:"length" json:"length"`
+
+	// Charset is the characters the password is made of. Defaults to the
+	// characters of all the rules, or to alphanumeric characters if there are
+	// no rules.
+	Charset string `hcl:"charset" json:"charset"`
+
+	// Rules are the classes of characters the password must contain
+	Rules []*CharsetRule `hcl:"-" json:"rules"`
+}
+
+// CharsetRule requires passwords to contain a minimum number of characters
+// from a charset
+type CharsetRule struct {
+	Charset  string `hcl:"charset" json:"charset"`
+	MinChars int    `hcl:"min_chars" json:"min_chars"`
+}
+
+// ParsePolicy parses a policy given in HCL or JSON, for instance:
+//
+//	length = 24
+//	rule "charset" {
+//	  charset   = "abcdefghijklmnopqrstuvwxyz"
+//	  min_chars = 2
+//	}
+//	rule "charset" {
+//	  charset   = "0123456789"
+//	  min_chars = 2
+//	}
+//
+// The policy is validated before it is returned.
+func ParsePolicy(raw string) (*Policy, error) {
+	root, err := hcl.Parse(raw)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to parse password policy: {{err}}", err)
+	}
+
+	list, ok := root.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse password policy: does not contain a root object")
+	}
+
+	valid := []string{
+		"length",
+		"charset",
+		"rule",
+	}
+	if err := hclutil.CheckHCLKeys(list, valid); err != nil {
+		return nil, errwrap.Wrapf("failed to parse password policy: {{err}}", err)
+	}
+
+	var p Policy
+	if err := hcl.DecodeObject(&p, list); err != nil {
+		return nil, errwrap.Wrapf("failed to parse password policy: {{err}}", err)
+	}
+
+	for _, item := range list.Filter("rule").Items {
+		if len(item.Keys) != 1 || item.Keys[0].Token.Value().(string) != "charset" {
+			return nil, fmt.Errorf(`failed to parse password policy: rules must be of the form rule "charset" { ... }`)
+		}
+
+		valid := []string{
+			"charset",
+			"min_chars",
+		}
+		if err := hclutil.CheckHCLKeys(item.Val, valid); err != nil {
+			return nil, errwrap.Wrapf("failed to parse password policy rule: {{err}}", err)
+		}
+
+		var rule CharsetRule
+		if err := hcl.DecodeObject(&rule, item.Val); err != nil {
+			return nil, errwrap.Wrapf("failed to parse password policy rule: {{err}}", err)
+		}
+		p.Rules = append(p.Rules, &rule)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Validate checks that passwords can be generated from the policy
+func (p *Policy) Validate() error {
+	length := p.length()
+	if length < 1 || length > MaxLength {
+		return fmt.Errorf("password length must be between 1 and %d", MaxLength)
+	}
+
+	charset := p.charset()
+	var minChars int
+	for _, rule := range p.Rules {
+		if rule.Charset == "" {
+			return fmt.Errorf("password policy rules must have a charset")
+		}
+		if rule.MinChars < 0 {
+			return fmt.Errorf("min_chars of password policy rules must not be negative")
+		}
+		for _, r := range rule.Charset {
+			if !strings.ContainsRune(charset, r) {
+				return fmt.Errorf("character %q of rule charset %q is not in the policy charset", r, rule.Charset)
+			}
+		}
+		minChars += rule.MinChars
+	}
+	if minChars > length {
+		return fmt.Errorf("password policy rules require %d characters, but the length is %d", minChars, length)
+	}
+
+	return nil
+}
+
+// Generate returns a random password satisfying the policy
+func (p *Policy) Generate() (string, error) {
+	return p.generate(rand.Reader)
+}
+
+func (p *Policy) generate(rng io.Reader) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	length := p.length()
+	password := make([]rune, 0, length)
+
+	// Pick the characters the rules require first, then fill up the rest from
+	// the whole charset and shuffle, so that the required characters don't
+	// always come first
+	for _, rule := range p.Rules {
+		chars, err := randomRunes(rng, []rune(rule.Charset), rule.MinChars)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, chars...)
+	}
+	chars, err := randomRunes(rng, []rune(p.charset()), length-len(password))
+	if err != nil {
+		return "", err
+	}
+	password = append(password, chars...)
+
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(rng, i+1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+func (p *Policy) length() int {
+	if p.Length == 0 {
+		return DefaultLength
+	}
+	return p.Length
+}
+
+func (p *Policy) charset() string {
+	if p.Charset != "" {
+		return p.Charset
+	}
+	if len(p.Rules) == 0 {
+		return AlphaNumericCharset
+	}
+
+	var charset strings.Builder
+	for _, rule := range p.Rules {
+		for _, r := range rule.Charset {
+			if !strings.ContainsRune(charset.String(), r) {
+				charset.WriteRune(r)
+			}
+		}
+	}
+	return charset.String()
+}
+
+// RandomString returns a random string of the given length made of the
+// characters of the charset
+func RandomString(charset string, length int) (string, error) {
+	if charset == "" {
+		return "", fmt.Errorf("charset must not be empty")
+	}
+	chars, err := randomRunes(rand.Reader, []rune(charset), length)
+	if err != nil {
+		return "", err
+	}
+	return string(chars), nil
+}
+
+func randomRunes(rng io.Reader, charset []rune, n int) ([]rune, error) {
+	runes := make([]rune, n)
+	for i := range runes {
+		j, err := randomInt(rng, len(charset))
+		if err != nil {
+			return nil, err
+		}
+		runes[i] = charset[j]
+	}
+	return runes, nil
+}
+
+// randomInt returns a uniformly distributed random number in [0, n)
+func randomInt(rng io.Reader, n int) (int, error) {
+	i, err := rand.Int(rng, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
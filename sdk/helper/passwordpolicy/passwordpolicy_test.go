@@ -0,0 +1,131 @@
+package passwordpolicy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	raw := `
+length = 16
+charset = "abcdef0123456789!"
+
+rule "charset" {
+  charset   = "0123456789"
+  min_chars = 4
+}
+
+rule "charset" {
+  charset   = "!"
+  min_chars = 1
+}
+`
+	p, err := ParsePolicy(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Length != 16 || p.Charset != "abcdef0123456789!" || len(p.Rules) != 2 {
+		t.Fatalf("bad: %#v", p)
+	}
+	if p.Rules[0].Charset != NumericCharset || p.Rules[0].MinChars != 4 {
+		t.Fatalf("bad: %#v", p.Rules[0])
+	}
+
+	invalid := map[string]string{
+		"unknown key":           `lenght = 12`,
+		"unknown rule key":      `rule "charset" { chars = "abc" }`,
+		"unknown rule type":     `rule "regex" { charset = "abc" }`,
+		"too long":              `length = 5000`,
+		"negative length":       `length = -1`,
+		"empty rule charset":    `rule "charset" { min_chars = 1 }`,
+		"rule outside charset":  "charset = \"abc\"\nrule \"charset\" { charset = \"0\" }",
+		"rules exceed length":   "length = 2\nrule \"charset\" {\ncharset = \"abc\"\nmin_chars = 3\n}",
+		"negative rule minimum": `rule "charset" { charset = "abc" min_chars = -1 }`,
+	}
+	for name, raw := range invalid {
+		if _, err := ParsePolicy(raw); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPolicy_Generate(t *testing.T) {
+	p := &Policy{
+		Length:  12,
+		Charset: "abc123!",
+		Rules: []*CharsetRule{
+			{Charset: "123", MinChars: 3},
+			{Charset: "!", MinChars: 2},
+		},
+	}
+
+	for i := 0; i < 100; i++ {
+		password, err := p.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != 12 {
+			t.Fatalf("bad length: %q", password)
+		}
+		if strings.Trim(password, "abc123!") != "" {
+			t.Fatalf("character outside the charset: %q", password)
+		}
+		var digits, symbols int
+		for _, r := range password {
+			switch {
+			case strings.ContainsRune("123", r):
+				digits++
+			case r == '!':
+				symbols++
+			}
+		}
+		if digits < 3 || symbols < 2 {
+			t.Fatalf("rules not satisfied: %q", password)
+		}
+	}
+}
+
+func TestPolicy_GenerateDefaults(t *testing.T) {
+	password, err := (&Policy{}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(password) != DefaultLength || strings.Trim(password, AlphaNumericCharset) != "" {
+		t.Fatalf("bad: %q", password)
+	}
+
+	// Without a charset, passwords are made of the characters of the rules
+	p := &Policy{
+		Length: 8,
+		Rules: []*CharsetRule{
+			{Charset: "ab", MinChars: 1},
+			{Charset: "xy", MinChars: 1},
+		},
+	}
+	password, err = p.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Trim(password, "abxy") != "" {
+		t.Fatalf("bad: %q", password)
+	}
+
+	password, err = DefaultPolicy.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.ContainsAny(password, LowercaseCharset) ||
+		!strings.ContainsAny(password, UppercaseCharset) ||
+		!strings.ContainsAny(password, NumericCharset) {
+		t.Fatalf("bad: %q", password)
+	}
+}
+
+func TestPolicy_GenerateRandError(t *testing.T) {
+	// An exhausted source of randomness must fail rather than produce weak
+	// passwords
+	if _, err := DefaultPolicy.generate(bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected error")
+	}
+}
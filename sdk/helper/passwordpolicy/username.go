@@ -0,0 +1,103 @@
+package passwordpolicy
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// UsernameTemplate generates usernames from a text/template, for instance:
+//
+//	v-{{.DisplayName | truncate 8}}-{{.RoleName | truncate 8}}-{{random 20}}-{{unix_time}}
+//
+// Besides the usual template functions, templates can call:
+//
+//	random n          - n random alphanumeric characters
+//	truncate n s      - the first n characters of s
+//	uppercase s       - s in upper case
+//	lowercase s       - s in lower case
+//	replace old new s - s with all occurrences of old replaced by new
+//	unix_time         - the current time in seconds since the epoch
+//
+// The fields available to the template are those of the data given to
+// Generate.
+type UsernameTemplate struct {
+	tmpl *template.Template
+
+	// MaxLength truncates the generated usernames, or zero for no limit
+	MaxLength int
+}
+
+// UsernameData is the data most secrets engines render username templates
+// with
+type UsernameData struct {
+	DisplayName string
+	RoleName    string
+}
+
+// NewUsernameTemplate parses a username template
+func NewUsernameTemplate(raw string) (*UsernameTemplate, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("username template must not be empty")
+	}
+
+	tmpl, err := template.New("username").
+		Option("missingkey=error").
+		Funcs(usernameFuncs).
+		Parse(raw)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to parse username template: {{err}}", err)
+	}
+
+	return &UsernameTemplate{
+		tmpl: tmpl,
+	}, nil
+}
+
+// Generate renders the template with the given data
+func (t *UsernameTemplate) Generate(data interface{}) (string, error) {
+	var username strings.Builder
+	if err := t.tmpl.Execute(&username, data); err != nil {
+		return "", errwrap.Wrapf("failed to generate username: {{err}}", err)
+	}
+
+	result := username.String()
+	if result == "" {
+		return "", fmt.Errorf("username template generated an empty username")
+	}
+
+	if t.MaxLength > 0 {
+		result = truncate(t.MaxLength, result)
+	}
+
+	return result, nil
+}
+
+var usernameFuncs = template.FuncMap{
+	"random": func(n int) (string, error) {
+		if n < 1 {
+			return "", fmt.Errorf("random requires a positive length")
+		}
+		return RandomString(AlphaNumericCharset, n)
+	},
+	"truncate":  truncate,
+	"uppercase": strings.ToUpper,
+	"lowercase": strings.ToLower,
+	"replace": func(old, new, s string) string {
+		return strings.Replace(s, old, new, -1)
+	},
+	"unix_time": func() int64 {
+		return time.Now().Unix()
+	},
+}
+
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,86 @@
+package passwordpolicy
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestUsernameTemplate_Generate(t *testing.T) {
+	data := UsernameData{
+		DisplayName: "token-display-name",
+		RoleName:    "Readonly.Role",
+	}
+
+	cases := map[string]struct {
+		template  string
+		maxLength int
+		expected  string
+	}{
+		"fields": {
+			"{{.DisplayName}}_{{.RoleName}}",
+			0,
+			`^token-display-name_Readonly\.Role$`,
+		},
+		"functions": {
+			`v-{{.DisplayName | truncate 5}}-{{.RoleName | lowercase | replace "." "_"}}-{{random 8}}`,
+			0,
+			`^v-token-readonly_role-[a-zA-Z0-9]{8}$`,
+		},
+		"unix time": {
+			"{{.RoleName | uppercase}}-{{unix_time}}",
+			0,
+			`^READONLY\.ROLE-[0-9]+$`,
+		},
+		"max length": {
+			"{{.DisplayName}}-{{random 20}}",
+			10,
+			`^token-disp$`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := NewUsernameTemplate(tc.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tmpl.MaxLength = tc.maxLength
+
+			username, err := tmpl.Generate(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !regexp.MustCompile(tc.expected).MatchString(username) {
+				t.Fatalf("expected %q to match %q", username, tc.expected)
+			}
+		})
+	}
+}
+
+func TestUsernameTemplate_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"{{.DisplayName",
+		"{{unknown_func}}",
+	}
+	for _, raw := range invalid {
+		if _, err := NewUsernameTemplate(raw); err == nil {
+			t.Fatalf("%q: expected error", raw)
+		}
+	}
+
+	generateErrors := []string{
+		"{{.Unknown}}",
+		"{{random 0}}",
+		"{{if false}}x{{end}}",
+	}
+	for _, raw := range generateErrors {
+		tmpl, err := NewUsernameTemplate(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpl.Generate(UsernameData{}); err == nil {
+			t.Fatalf("%q: expected error", raw)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package pluginutil
 import (
 	"context"
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -18,10 +17,11 @@ import (
 // generateCert is used internally to create certificates for the plugin
 // client and server.
 func generateCert() ([]byte, *ecdsa.PrivateKey, error) {
-	key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
-	if err != nil {
+	var keyBundle certutil.ParsedCSRBundle
+	if err := certutil.GeneratePrivateKey(certutil.ECPrivateKey, 521, &keyBundle); err != nil {
 		return nil, nil, err
 	}
+	key := keyBundle.PrivateKey.(*ecdsa.PrivateKey)
 
 	host, err := uuid.GenerateUUID()
 	if err != nil {
@@ -0,0 +1,59 @@
+package salt
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// LazySalt creates a salt on first use and caches it. Creating the salt
+// requires reading, and possibly writing, the view, which may not be
+// possible yet when the owner of the salt is set up.
+type LazySalt struct {
+	view   logical.Storage
+	config *Config
+
+	lock sync.RWMutex
+	salt *Salt
+}
+
+// NewLazySalt returns a LazySalt creating its salt in the given view with
+// the given configuration
+func NewLazySalt(view logical.Storage, config *Config) *LazySalt {
+	return &LazySalt{
+		view:   view,
+		config: config,
+	}
+}
+
+// Get returns the salt, creating it if necessary
+func (l *LazySalt) Get(ctx context.Context) (*Salt, error) {
+	l.lock.RLock()
+	if l.salt != nil {
+		defer l.lock.RUnlock()
+		return l.salt, nil
+	}
+	l.lock.RUnlock()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.salt != nil {
+		return l.salt, nil
+	}
+
+	salt, err := NewSalt(ctx, l.view, l.config)
+	if err != nil {
+		return nil, err
+	}
+	l.salt = salt
+	return salt, nil
+}
+
+// Reset discards the cached salt, so that the next call to Get loads it from
+// the view again
+func (l *LazySalt) Reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.salt = nil
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -18,6 +19,13 @@ const (
 	// DefaultLocation is the path in the view we store our key salt
 	// if no other path is provided.
 	DefaultLocation = "salt"
+
+	// HMACTypeSuffix is appended to the location of the salt to get the path
+	// where the HMAC type of the salt is stored
+	HMACTypeSuffix = "-hmac-type"
+
+	HMACTypeSHA256 = "hmac-sha256"
+	HMACTypeSHA512 = "hmac-sha512"
 )
 
 // Salt is used to manage a persistent salt key which is used to
@@ -43,7 +51,8 @@ type Config struct {
 	HashFunc HashFunc
 
 	// HMAC allows specification of a hash function to use for
-	// the HMAC helpers
+	// the HMAC helpers. If not provided, it is derived from HMACType,
+	// defaulting to SHA256.
 	HMAC func() hash.Hash
 
 	// String prepended to HMAC strings for identification.
@@ -51,12 +60,29 @@ type Config struct {
 	HMACType string
 }
 
-// NewSalt creates a new salt based on the configuration
+// HMACFunc returns the hash function of a known HMAC type
+func HMACFunc(hmacType string) (func() hash.Hash, error) {
+	switch hmacType {
+	case HMACTypeSHA256:
+		return sha256.New, nil
+	case HMACTypeSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unknown HMAC type %q", hmacType)
+	}
+}
+
+// NewSalt creates a new salt based on the configuration. The salt is stored
+// in the view, along with its HMAC type if it isn't the default, so that a
+// salt keeps producing the same HMACs even if it is later loaded with a
+// different configuration.
 func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt, error) {
-	// Setup the configuration
+	// Setup the configuration, without modifying the caller's copy
 	if config == nil {
 		config = &Config{}
 	}
+	conf := *config
+	config = &conf
 	if config.Location == "" {
 		config.Location = DefaultLocation
 	}
@@ -64,8 +90,17 @@ func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt,
 		config.HashFunc = SHA256Hash
 	}
 	if config.HMAC == nil {
-		config.HMAC = sha256.New
-		config.HMACType = "hmac-sha256"
+		if config.HMACType == "" {
+			config.HMACType = HMACTypeSHA256
+		}
+		hmacFunc, err := HMACFunc(config.HMACType)
+		if err != nil {
+			return nil, err
+		}
+		config.HMAC = hmacFunc
+	}
+	if len(config.HMACType) == 0 {
+		return nil, fmt.Errorf("HMACType must be defined")
 	}
 
 	// Create the salt
@@ -74,13 +109,17 @@ func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt,
 	}
 
 	// Look for the salt
-	var raw *logical.StorageEntry
+	var raw, rawHMACType *logical.StorageEntry
 	var err error
 	if view != nil {
 		raw, err = view.Get(ctx, config.Location)
 		if err != nil {
 			return nil, errwrap.Wrapf("failed to read salt: {{err}}", err)
 		}
+		rawHMACType, err = view.Get(ctx, config.Location+HMACTypeSuffix)
+		if err != nil {
+			return nil, errwrap.Wrapf("failed to read salt HMAC type: {{err}}", err)
+		}
 	}
 
 	// Restore the salt if it exists
@@ -103,12 +142,34 @@ func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt,
 			if err := view.Put(ctx, raw); err != nil {
 				return nil, errwrap.Wrapf("failed to persist salt: {{err}}", err)
 			}
+
+			// Only non-default HMAC types are stored
+			if config.HMACType != HMACTypeSHA256 {
+				if err := view.Put(ctx, &logical.StorageEntry{
+					Key:   config.Location + HMACTypeSuffix,
+					Value: []byte(config.HMACType),
+				}); err != nil {
+					return nil, errwrap.Wrapf("failed to persist salt HMAC type: {{err}}", err)
+				}
+			}
 		}
 	}
 
-	if config.HMAC != nil {
-		if len(config.HMACType) == 0 {
-			return nil, fmt.Errorf("HMACType must be defined")
+	// Use the HMAC type the salt was created with. The configured type only
+	// applies to new salts, and existing salts without a stored type were
+	// created with the default.
+	if !s.generated {
+		hmacType := HMACTypeSHA256
+		if rawHMACType != nil {
+			hmacType = string(rawHMACType.Value)
+		}
+		if hmacType != config.HMACType {
+			hmacFunc, err := HMACFunc(hmacType)
+			if err != nil {
+				return nil, err
+			}
+			config.HMAC = hmacFunc
+			config.HMACType = hmacType
 		}
 	}
 
@@ -176,3 +237,9 @@ func SHA256Hash(inp []byte) []byte {
 	hashed := sha256.Sum256(inp)
 	return hashed[:]
 }
+
+// SHA512Hash returns the SHA512 of the input
+func SHA512Hash(inp []byte) []byte {
+	hashed := sha512.Sum512(inp)
+	return hashed[:]
+}
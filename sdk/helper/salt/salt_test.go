@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
+	"strings"
 	"testing"
 
 	uuid "github.com/hashicorp/go-uuid"
@@ -85,4 +87,105 @@ func TestSaltID(t *testing.T) {
 	if sid1 != sid2 {
 		t.Fatalf("mismatch")
 	}
+
+	sid1 = SaltID(salt, id, SHA512Hash)
+	if len(sid1) != sha512.Size*2 {
+		t.Fatalf("Bad len: %d", len(sid1))
+	}
+}
+
+func TestSalt_HMACType(t *testing.T) {
+	inm := &logical.InmemStorage{}
+	conf := &Config{
+		HMACType: HMACTypeSHA512,
+	}
+
+	salt, err := NewSalt(context.Background(), inm, conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if conf.HMAC != nil || conf.Location != "" {
+		t.Fatalf("config was modified: %#v", conf)
+	}
+
+	hmac := salt.GetIdentifiedHMAC("foo")
+	if !strings.HasPrefix(hmac, HMACTypeSHA512+":") || len(hmac) != len(HMACTypeSHA512)+1+sha512.Size*2 {
+		t.Fatalf("bad: %s", hmac)
+	}
+
+	// Loading the salt with another HMAC type keeps the HMACs consistent
+	salt2, err := NewSalt(context.Background(), inm, &Config{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if salt2.GetIdentifiedHMAC("foo") != hmac {
+		t.Fatalf("mismatch")
+	}
+
+	// Salts with the default HMAC type don't store it, and existing salts
+	// without a stored type keep using it whatever the configured type
+	inm = &logical.InmemStorage{}
+	salt, err = NewSalt(context.Background(), inm, &Config{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := inm.Get(context.Background(), DefaultLocation+HMACTypeSuffix)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("unexpected HMAC type entry")
+	}
+	salt2, err = NewSalt(context.Background(), inm, &Config{HMACType: HMACTypeSHA512})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if salt2.GetIdentifiedHMAC("foo") != salt.GetIdentifiedHMAC("foo") {
+		t.Fatalf("expected the default HMAC type")
+	}
+
+	if _, err := NewSalt(context.Background(), inm, &Config{HMACType: "hmac-md5"}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestLazySalt(t *testing.T) {
+	inm := &logical.InmemStorage{}
+	lazy := NewLazySalt(inm, &Config{})
+
+	keys, err := inm.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("salt created before first use")
+	}
+
+	salt, err := lazy.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	salt2, err := lazy.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if salt != salt2 {
+		t.Fatalf("salt not cached")
+	}
+
+	// After a reset, the salt is loaded from storage again
+	if err := inm.Put(context.Background(), &logical.StorageEntry{
+		Key:   DefaultLocation,
+		Value: []byte("replaced"),
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	lazy.Reset()
+	salt2, err = lazy.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if salt2.salt != "replaced" {
+		t.Fatalf("salt not reloaded")
+	}
 }
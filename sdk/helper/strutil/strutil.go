@@ -32,6 +32,28 @@ func StrListContains(haystack []string, needle string) bool {
 	return false
 }
 
+// StrListContainsCaseInsensitive looks for a string in a list of strings,
+// ignoring case.
+func StrListContainsCaseInsensitive(haystack []string, needle string) bool {
+	for _, item := range haystack {
+		if strings.EqualFold(item, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// StrListContainsAnyGlob checks whether any of the given strings matches any
+// of the given glob patterns.
+func StrListContainsAnyGlob(patterns []string, values []string) bool {
+	for _, value := range values {
+		if StrListContainsGlob(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
+
 // StrListSubset checks if a given list is a subset
 // of another set
 func StrListSubset(super, sub []string) bool {
@@ -239,6 +261,31 @@ func RemoveDuplicates(items []string, lowercase bool) []string {
 	return items
 }
 
+// RemoveDuplicatesStable removes duplicate and empty elements from a slice of
+// strings, preserving the order of the first occurrence of each element. If
+// caseInsensitive is set, elements differing only in case are duplicates,
+// and the first occurrence is kept as is.
+func RemoveDuplicatesStable(items []string, caseInsensitive bool) []string {
+	itemsMap := map[string]bool{}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key := item
+		if caseInsensitive {
+			key = strings.ToLower(item)
+		}
+		if itemsMap[key] {
+			continue
+		}
+		itemsMap[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
 // RemoveEmpty removes empty elements from a slice of
 // strings
 func RemoveEmpty(items []string) []string {
@@ -423,3 +470,28 @@ func Difference(a, b []string, lowercase bool) []string {
 	sort.Strings(items)
 	return items
 }
+
+// Intersection returns the set intersection of the two given slices. The
+// result will not contain duplicated values, and is sorted.
+func Intersection(a, b []string, lowercase bool) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return []string{}
+	}
+
+	a = RemoveDuplicates(a, lowercase)
+	b = RemoveDuplicates(b, lowercase)
+
+	itemsMap := map[string]bool{}
+	for _, aVal := range a {
+		itemsMap[aVal] = true
+	}
+
+	items := []string{}
+	for _, bVal := range b {
+		if itemsMap[bVal] {
+			items = append(items, bVal)
+		}
+	}
+	sort.Strings(items)
+	return items
+}
@@ -532,6 +532,114 @@ func TestDifference(t *testing.T) {
 	}
 }
 
+func TestIntersection(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		SetA           []string
+		SetB           []string
+		Lowercase      bool
+		ExpectedResult []string
+	}{
+		{
+			Name:           "case_sensitive",
+			SetA:           []string{"a", "b", "c", "c"},
+			SetB:           []string{"c", "B", "a"},
+			Lowercase:      false,
+			ExpectedResult: []string{"a", "c"},
+		},
+		{
+			Name:           "case_insensitive",
+			SetA:           []string{"a", "B", "c"},
+			SetB:           []string{"b", "C", "d"},
+			Lowercase:      true,
+			ExpectedResult: []string{"b", "c"},
+		},
+		{
+			Name:           "no_match",
+			SetA:           []string{"a", "b"},
+			SetB:           []string{"c"},
+			Lowercase:      false,
+			ExpectedResult: []string{},
+		},
+		{
+			Name:           "empty_set_b",
+			SetA:           []string{"a", "b"},
+			SetB:           []string{},
+			Lowercase:      false,
+			ExpectedResult: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actualResult := Intersection(tc.SetA, tc.SetB, tc.Lowercase)
+
+			if !reflect.DeepEqual(actualResult, tc.ExpectedResult) {
+				t.Fatalf("expected %v, got %v", tc.ExpectedResult, actualResult)
+			}
+		})
+	}
+}
+
+func TestStrUtil_RemoveDuplicatesStable(t *testing.T) {
+	type tCase struct {
+		input           []string
+		expect          []string
+		caseInsensitive bool
+	}
+
+	tCases := []tCase{
+		tCase{[]string{}, []string{}, false},
+		tCase{[]string{"a", "b", "a", ""}, []string{"a", "b"}, false},
+		tCase{[]string{"c", " b ", "a", "b"}, []string{"c", "b", "a"}, false},
+		tCase{[]string{"B", "a", "b", "A"}, []string{"B", "a", "b", "A"}, false},
+		tCase{[]string{"B", "a", "b", "A"}, []string{"B", "a"}, true},
+	}
+
+	for _, tc := range tCases {
+		actual := RemoveDuplicatesStable(tc.input, tc.caseInsensitive)
+
+		if !reflect.DeepEqual(actual, tc.expect) {
+			t.Fatalf("Bad testcase %#v, expected %v, got %v", tc, tc.expect, actual)
+		}
+	}
+}
+
+func TestStrutil_ListContainsCaseInsensitive(t *testing.T) {
+	haystack := []string{
+		"dev",
+		"Ops",
+	}
+	if !StrListContainsCaseInsensitive(haystack, "DEV") {
+		t.Fatalf("Bad")
+	}
+	if !StrListContainsCaseInsensitive(haystack, "ops") {
+		t.Fatalf("Bad")
+	}
+	if StrListContainsCaseInsensitive(haystack, "prod") {
+		t.Fatalf("Bad")
+	}
+}
+
+func TestStrutil_ListContainsAnyGlob(t *testing.T) {
+	patterns := []string{
+		"*.example.com",
+		"vault",
+	}
+	if !StrListContainsAnyGlob(patterns, []string{"foo", "www.example.com"}) {
+		t.Fatalf("Bad")
+	}
+	if !StrListContainsAnyGlob(patterns, []string{"vault"}) {
+		t.Fatalf("Bad")
+	}
+	if StrListContainsAnyGlob(patterns, []string{"example.com", "vault.io"}) {
+		t.Fatalf("Bad")
+	}
+	if StrListContainsAnyGlob(patterns, nil) {
+		t.Fatalf("Bad")
+	}
+}
+
 func TestStrUtil_EqualStringMaps(t *testing.T) {
 	m1 := map[string]string{
 		"foo": "a",
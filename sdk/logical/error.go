@@ -25,6 +25,10 @@ var (
 	// response from an upstream
 	ErrUpstreamRateLimited = errors.New("upstream rate limited")
 
+	// ErrRateLimited is returned when a request is rejected because its
+	// client is over its request rate budget
+	ErrRateLimited = errors.New("rate limit exceeded")
+
 	// ErrPerfStandbyForward is returned when Vault is in a state such that a
 	// perf standby cannot satisfy a request
 	ErrPerfStandbyPleaseForward = errors.New("please forward to the active node")
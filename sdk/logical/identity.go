@@ -0,0 +1,31 @@
+package logical
+
+// Standard alias metadata keys. Vault sets AliasMetadataKeyMountAccessor on
+// the aliases of every login, overriding any value set by the auth method.
+// Auth methods identifying users in an upstream system by something more
+// stable than the alias name, such as a numeric user ID, set it under
+// AliasMetadataKeyUpstreamID.
+//
+// AliasMetadataKeySourceIP is only part of the alias metadata of audited login
+// responses. It changes from one login to the next, so it is never persisted
+// on identity aliases, and a value set by the auth method is discarded.
+const (
+	AliasMetadataKeySourceIP      = "source_ip"
+	AliasMetadataKeyMountAccessor = "mount_accessor"
+	AliasMetadataKeyUpstreamID    = "upstream_id"
+)
+
+// SetStandardAliasMetadata sets the standard metadata keys of the alias which
+// can be persisted along with it
+func SetStandardAliasMetadata(alias *Alias, req *Request) {
+	if alias == nil || req == nil {
+		return
+	}
+
+	if alias.Metadata == nil {
+		alias.Metadata = make(map[string]string)
+	}
+
+	alias.Metadata[AliasMetadataKeyMountAccessor] = req.MountAccessor
+	delete(alias.Metadata, AliasMetadataKeySourceIP)
+}
@@ -0,0 +1,39 @@
+package logical
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetStandardAliasMetadata(t *testing.T) {
+	alias := &Alias{
+		Name: "foo",
+		Metadata: map[string]string{
+			AliasMetadataKeyUpstreamID: "1234",
+			AliasMetadataKeySourceIP:   "spoofed",
+		},
+	}
+	req := &Request{
+		MountAccessor: "auth_userpass_1234",
+		Connection: &Connection{
+			RemoteAddr: "127.0.0.1",
+		},
+	}
+
+	// The source IP, set by the auth method or not, is never persisted
+	SetStandardAliasMetadata(alias, req)
+	expected := map[string]string{
+		AliasMetadataKeyUpstreamID:    "1234",
+		AliasMetadataKeyMountAccessor: "auth_userpass_1234",
+	}
+	if !reflect.DeepEqual(alias.Metadata, expected) {
+		t.Fatalf("bad: %#v", alias.Metadata)
+	}
+
+	// Aliases without metadata get some
+	alias = &Alias{Name: "foo"}
+	SetStandardAliasMetadata(alias, req)
+	if alias.Metadata[AliasMetadataKeyMountAccessor] != "auth_userpass_1234" {
+		t.Fatalf("bad: %#v", alias.Metadata)
+	}
+}
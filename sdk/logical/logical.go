@@ -123,4 +123,13 @@ type Paths struct {
 	// should be seal wrapped with extra encryption. It is exact matching
 	// unless it ends with '/' in which case it will be treated as a prefix.
 	SealWrapStorage []string
+
+	// WellKnown maps labels under the listener's /.well-known/ prefix, such
+	// as "acme" or "openid-configuration", to the backend path serving them.
+	// Requests for /.well-known/<label>/<rest> are routed to <path>/<rest>
+	// on the mount. This is intended for protocols that mandate fixed URLs.
+	// If several mounts claim a label, the lowest mount path serves it. This
+	// is not yet carried over the plugin protocol, so only builtin backends
+	// can claim labels.
+	WellKnown map[string]string
 }
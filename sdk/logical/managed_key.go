@@ -0,0 +1,30 @@
+package logical
+
+import (
+	"context"
+	"crypto"
+	"io"
+)
+
+// ManagedKey is a private key held outside of Vault, in a KMS or HSM, and
+// configured under sys/managed-keys. The key material never leaves the
+// device: signing and decryption are delegated to it. The digest given to
+// Sign must have been produced with the hash algorithm the device's key is
+// meant to be used with.
+type ManagedKey interface {
+	crypto.Signer
+
+	// Decrypt decrypts the ciphertext with the key, as crypto.Decrypter does.
+	// Keys which can't decrypt return an error.
+	Decrypt(rand io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error)
+}
+
+// ManagedKeySystemView is implemented by the system views of backends able
+// to use managed keys. Backends must check for it, as backends running as
+// external plugins can't use managed keys.
+type ManagedKeySystemView interface {
+	// ManagedKey returns the managed key with the given name. An error is
+	// returned if the key doesn't exist or the mount of the backend isn't
+	// allowed to use it.
+	ManagedKey(ctx context.Context, name string) (ManagedKey, error)
+}
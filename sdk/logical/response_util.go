@@ -110,6 +110,8 @@ func RespondErrorCommon(req *Request, resp *Response, err error) (int, error) {
 			statusCode = http.StatusBadRequest
 		case errwrap.Contains(err, ErrUpstreamRateLimited.Error()):
 			statusCode = http.StatusBadGateway
+		case errwrap.Contains(err, ErrRateLimited.Error()):
+			statusCode = http.StatusTooManyRequests
 		}
 	}
 
@@ -154,9 +156,14 @@ func RespondError(w http.ResponseWriter, status int, err error) {
 	w.WriteHeader(status)
 
 	type ErrorResponse struct {
-		Errors []string `json:"errors"`
+		Errors    []string `json:"errors"`
+		RequestID string   `json:"request_id,omitempty"`
+	}
+	resp := &ErrorResponse{
+		Errors: make([]string, 0, 1),
+		// Set by Vault's HTTP handler when the request is received
+		RequestID: w.Header().Get(consts.RequestIDHeaderName),
 	}
-	resp := &ErrorResponse{Errors: make([]string, 0, 1)}
 	if err != nil {
 		resp.Errors = append(resp.Errors, err.Error())
 	}
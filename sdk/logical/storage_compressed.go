@@ -0,0 +1,98 @@
+package logical
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/compressutil"
+)
+
+// CompressedStorage compresses the values of at least a threshold size on
+// write, and decompresses them transparently on read, to keep large entries
+// under the size limits of storage backends such as Consul.
+//
+// Compressed values are told apart by the canary byte of compressutil, so it
+// must only wrap entries whose uncompressed values never start with one of
+// them, such as JSON or DER encoded values.
+type CompressedStorage struct {
+	storage   Storage
+	config    *compressutil.CompressionConfig
+	threshold int
+}
+
+var _ Storage = (*CompressedStorage)(nil)
+
+// NewCompressedStorage returns a CompressedStorage compressing the values of at
+// least threshold bytes written to the given storage. A nil config defaults to
+// the Gzip format with the best compression, as used for the mount tables.
+func NewCompressedStorage(storage Storage, config *compressutil.CompressionConfig, threshold int) *CompressedStorage {
+	if config == nil {
+		config = &compressutil.CompressionConfig{
+			Type:                 compressutil.CompressionTypeGzip,
+			GzipCompressionLevel: gzip.BestCompression,
+		}
+	}
+	return &CompressedStorage{
+		storage:   storage,
+		config:    config,
+		threshold: threshold,
+	}
+}
+
+// logical.Storage impl.
+func (s *CompressedStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.storage.List(ctx, prefix)
+}
+
+// logical.Storage impl.
+func (s *CompressedStorage) Get(ctx context.Context, key string) (*StorageEntry, error) {
+	entry, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || len(entry.Value) == 0 {
+		return entry, nil
+	}
+
+	decompressed, notCompressed, err := compressutil.Decompress(entry.Value)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to decompress storage entry: {{err}}", err)
+	}
+	if notCompressed {
+		return entry, nil
+	}
+
+	return &StorageEntry{
+		Key:      entry.Key,
+		Value:    decompressed,
+		SealWrap: entry.SealWrap,
+	}, nil
+}
+
+// logical.Storage impl.
+func (s *CompressedStorage) Put(ctx context.Context, entry *StorageEntry) error {
+	if entry == nil {
+		return errors.New("cannot write nil entry")
+	}
+	if len(entry.Value) < s.threshold {
+		return s.storage.Put(ctx, entry)
+	}
+
+	compressed, err := compressutil.Compress(entry.Value, s.config)
+	if err != nil {
+		return errwrap.Wrapf("failed to compress storage entry: {{err}}", err)
+	}
+
+	return s.storage.Put(ctx, &StorageEntry{
+		Key:      entry.Key,
+		Value:    compressed,
+		SealWrap: entry.SealWrap,
+	})
+}
+
+// logical.Storage impl.
+func (s *CompressedStorage) Delete(ctx context.Context, key string) error {
+	return s.storage.Delete(ctx, key)
+}
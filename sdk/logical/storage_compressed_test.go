@@ -0,0 +1,57 @@
+package logical
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/compressutil"
+)
+
+func TestCompressedStorage(t *testing.T) {
+	TestStorage(t, NewCompressedStorage(new(InmemStorage), nil, 0))
+
+	ctx := context.Background()
+	underlying := new(InmemStorage)
+	s := NewCompressedStorage(underlying, &compressutil.CompressionConfig{
+		Type: compressutil.CompressionTypeSnappy,
+	}, 64)
+
+	for key, value := range map[string][]byte{
+		"small": []byte(`{"foo":"bar"}`),
+		"large": bytes.Repeat([]byte(`{"foo":"bar"}`), 100),
+	} {
+		if err := s.Put(ctx, &StorageEntry{Key: key, Value: value}); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := underlying.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compressed := raw.Value[0] == compressutil.CompressionCanarySnappy
+		if compressed != (len(value) >= 64) {
+			t.Fatalf("%s: unexpected compression of %d bytes: %t", key, len(value), compressed)
+		}
+
+		entry, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(entry.Value, value) || entry.Key != key {
+			t.Fatalf("%s: bad entry %#v", key, entry)
+		}
+	}
+
+	// Entries written by the wrapped storage are read as they are
+	if err := underlying.Put(ctx, &StorageEntry{Key: "raw", Value: []byte{0x30, 0x03}}); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := s.Get(ctx, "raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(entry.Value, []byte{0x30, 0x03}) {
+		t.Fatalf("bad value %v", entry.Value)
+	}
+}
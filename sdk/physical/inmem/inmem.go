@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/physical"
 
 	radix "github.com/armon/go-radix"
@@ -29,6 +32,8 @@ var (
 	GetDisabledError    = errors.New("get operations disabled in inmem backend")
 	DeleteDisabledError = errors.New("delete operations disabled in inmem backend")
 	ListDisabledError   = errors.New("list operations disabled in inmem backend")
+	InjectedError       = errors.New("injected error in inmem backend")
+	PartitionedError    = errors.New("inmem backend is partitioned")
 )
 
 // InmemBackend is an in-memory only physical backend. It is useful
@@ -45,6 +50,16 @@ type InmemBackend struct {
 	failList     *uint32
 	logOps       bool
 	maxValueSize int
+
+	// Fault injection, used to test behavior against slow or unreliable
+	// storage
+	latency       time.Duration
+	errorPercent  uint32
+	random        *rand.Rand
+	faultLock     sync.Mutex
+	partitioned   uint32
+	partitionCh   chan struct{}
+	partitionLock sync.Mutex
 }
 
 type TransactionalInmemBackend struct {
@@ -63,7 +78,7 @@ func NewInmem(conf map[string]string, logger log.Logger) (physical.Backend, erro
 		}
 	}
 
-	return &InmemBackend{
+	in := &InmemBackend{
 		root:         radix.New(),
 		permitPool:   physical.NewPermitPool(physical.DefaultParallelOperations),
 		logger:       logger,
@@ -73,7 +88,12 @@ func NewInmem(conf map[string]string, logger log.Logger) (physical.Backend, erro
 		failList:     new(uint32),
 		logOps:       os.Getenv("VAULT_INMEM_LOG_ALL_OPS") != "",
 		maxValueSize: maxValueSize,
-	}, nil
+	}
+	if err := in.setupFaultInjection(conf); err != nil {
+		return nil, err
+	}
+
+	return in, nil
 }
 
 // Basically for now just creates a permit pool of size 1 so only one operation
@@ -89,7 +109,7 @@ func NewTransactionalInmem(conf map[string]string, logger log.Logger) (physical.
 		}
 	}
 
-	return &TransactionalInmemBackend{
+	in := &TransactionalInmemBackend{
 		InmemBackend: InmemBackend{
 			root:         radix.New(),
 			permitPool:   physical.NewPermitPool(1),
@@ -101,11 +121,135 @@ func NewTransactionalInmem(conf map[string]string, logger log.Logger) (physical.
 			logOps:       os.Getenv("VAULT_INMEM_LOG_ALL_OPS") != "",
 			maxValueSize: maxValueSize,
 		},
-	}, nil
+	}
+	if err := in.setupFaultInjection(conf); err != nil {
+		return nil, err
+	}
+
+	return in, nil
+}
+
+// setupFaultInjection configures the artificial latency and error rate of the
+// backend. Setting random_seed makes the injected errors reproducible.
+func (i *InmemBackend) setupFaultInjection(conf map[string]string) error {
+	if seedStr, ok := conf["random_seed"]; ok {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid random_seed: %v", err)
+		}
+		i.random = rand.New(rand.NewSource(seed))
+	}
+
+	if latencyStr, ok := conf["latency"]; ok {
+		latency, err := parseutil.ParseDurationSecond(latencyStr)
+		if err != nil {
+			return fmt.Errorf("invalid latency: %v", err)
+		}
+		i.SetLatency(latency)
+	}
+
+	if percentStr, ok := conf["error_percent"]; ok {
+		percent, err := strconv.Atoi(percentStr)
+		if err != nil {
+			return fmt.Errorf("invalid error_percent: %v", err)
+		}
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("error_percent must be between 0 and 100")
+		}
+		i.SetErrorPercent(percent)
+	}
+
+	return nil
+}
+
+// SetLatency sets the latency added to every operation on the backend.
+func (i *InmemBackend) SetLatency(latency time.Duration) {
+	i.faultLock.Lock()
+	i.latency = latency
+	i.faultLock.Unlock()
+}
+
+// SetErrorPercent sets the percentage of operations on the backend that fail
+// with InjectedError.
+func (i *InmemBackend) SetErrorPercent(percent int) {
+	atomic.StoreUint32(&i.errorPercent, uint32(percent))
+}
+
+// SetPartitioned simulates a network partition between Vault and the backend.
+// While partitioned, all operations fail with PartitionedError and, when used
+// as an HA backend, held locks are lost and cannot be acquired.
+func (i *InmemBackend) SetPartitioned(partitioned bool) {
+	i.partitionLock.Lock()
+	defer i.partitionLock.Unlock()
+
+	if i.partitionCh == nil {
+		i.partitionCh = make(chan struct{})
+	}
+
+	wasPartitioned := atomic.LoadUint32(&i.partitioned) != 0
+	switch {
+	case partitioned && !wasPartitioned:
+		atomic.StoreUint32(&i.partitioned, 1)
+		close(i.partitionCh)
+	case !partitioned && wasPartitioned:
+		atomic.StoreUint32(&i.partitioned, 0)
+		i.partitionCh = make(chan struct{})
+	}
+}
+
+// partitionState returns whether the backend is partitioned, along with a
+// channel that is closed when the backend next becomes partitioned.
+func (i *InmemBackend) partitionState() (bool, <-chan struct{}) {
+	i.partitionLock.Lock()
+	defer i.partitionLock.Unlock()
+
+	if i.partitionCh == nil {
+		i.partitionCh = make(chan struct{})
+	}
+
+	return atomic.LoadUint32(&i.partitioned) != 0, i.partitionCh
+}
+
+// injectFaults applies the configured partition, latency and error rate to an
+// operation on the backend.
+func (i *InmemBackend) injectFaults(ctx context.Context) error {
+	if atomic.LoadUint32(&i.partitioned) != 0 {
+		return PartitionedError
+	}
+
+	i.faultLock.Lock()
+	latency := i.latency
+	i.faultLock.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if percent := atomic.LoadUint32(&i.errorPercent); percent > 0 {
+		i.faultLock.Lock()
+		if i.random == nil {
+			i.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		roll := i.random.Intn(100)
+		i.faultLock.Unlock()
+		if roll < int(percent) {
+			return InjectedError
+		}
+	}
+
+	return nil
 }
 
 // Put is used to insert or update an entry
 func (i *InmemBackend) Put(ctx context.Context, entry *physical.Entry) error {
+	if err := i.injectFaults(ctx); err != nil {
+		return err
+	}
+
 	i.permitPool.Acquire()
 	defer i.permitPool.Release()
 
@@ -147,6 +291,10 @@ func (i *InmemBackend) FailPut(fail bool) {
 
 // Get is used to fetch an entry
 func (i *InmemBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	if err := i.injectFaults(ctx); err != nil {
+		return nil, err
+	}
+
 	i.permitPool.Acquire()
 	defer i.permitPool.Release()
 
@@ -189,6 +337,10 @@ func (i *InmemBackend) FailGet(fail bool) {
 
 // Delete is used to permanently delete an entry
 func (i *InmemBackend) Delete(ctx context.Context, key string) error {
+	if err := i.injectFaults(ctx); err != nil {
+		return err
+	}
+
 	i.permitPool.Acquire()
 	defer i.permitPool.Release()
 
@@ -226,6 +378,10 @@ func (i *InmemBackend) FailDelete(fail bool) {
 // List is used to list all the keys under a given
 // prefix, up to the next prefix.
 func (i *InmemBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := i.injectFaults(ctx); err != nil {
+		return nil, err
+	}
+
 	i.permitPool.Acquire()
 	defer i.permitPool.Release()
 
@@ -280,6 +436,10 @@ func (i *InmemBackend) FailList(fail bool) {
 
 // Implements the transaction interface
 func (t *TransactionalInmemBackend) Transaction(ctx context.Context, txns []*physical.TxnEntry) error {
+	if err := t.injectFaults(ctx); err != nil {
+		return err
+	}
+
 	t.permitPool.Acquire()
 	defer t.permitPool.Release()
 
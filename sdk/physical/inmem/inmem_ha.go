@@ -22,8 +22,8 @@ type TransactionalInmemHABackend struct {
 }
 
 // NewInmemHA constructs a new in-memory HA backend. This is only for testing.
-func NewInmemHA(_ map[string]string, logger log.Logger) (physical.Backend, error) {
-	be, err := NewInmem(nil, logger)
+func NewInmemHA(conf map[string]string, logger log.Logger) (physical.Backend, error) {
+	be, err := NewInmem(conf, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +38,8 @@ func NewInmemHA(_ map[string]string, logger log.Logger) (physical.Backend, error
 	return in, nil
 }
 
-func NewTransactionalInmemHA(_ map[string]string, logger log.Logger) (physical.Backend, error) {
-	transInmem, err := NewTransactionalInmem(nil, logger)
+func NewTransactionalInmemHA(conf map[string]string, logger log.Logger) (physical.Backend, error) {
+	transInmem, err := NewTransactionalInmem(conf, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +58,19 @@ func NewTransactionalInmemHA(_ map[string]string, logger log.Logger) (physical.B
 	return in, nil
 }
 
+// partitionable is implemented by backends that can simulate a partition
+type partitionable interface {
+	partitionState() (bool, <-chan struct{})
+}
+
+// partitionState returns the partition state of the underlying backend
+func (i *InmemHABackend) partitionState() (bool, <-chan struct{}) {
+	if p, ok := i.Backend.(partitionable); ok {
+		return p.partitionState()
+	}
+	return false, nil
+}
+
 // LockWith is used for mutual exclusion based on the given key.
 func (i *InmemHABackend) LockWith(key, value string) (physical.Lock, error) {
 	l := &InmemLock{
@@ -97,6 +110,9 @@ func (i *InmemLock) Lock(stopCh <-chan struct{}) (<-chan struct{}, error) {
 	if i.held {
 		return nil, fmt.Errorf("lock already held")
 	}
+	if partitioned, _ := i.in.partitionState(); partitioned {
+		return nil, PartitionedError
+	}
 
 	// Attempt an async acquisition
 	didLock := make(chan struct{})
@@ -137,6 +153,18 @@ func (i *InmemLock) Lock(stopCh <-chan struct{}) (<-chan struct{}, error) {
 	// Create the leader channel
 	i.held = true
 	i.leaderCh = make(chan struct{})
+
+	// Lose the lock if the backend becomes partitioned while it is held
+	if _, partitionCh := i.in.partitionState(); partitionCh != nil {
+		go func(leaderCh chan struct{}) {
+			select {
+			case <-partitionCh:
+				i.Unlock()
+			case <-leaderCh:
+			}
+		}(i.leaderCh)
+	}
+
 	return i.leaderCh, nil
 }
 
@@ -160,6 +188,10 @@ func (i *InmemLock) Unlock() error {
 }
 
 func (i *InmemLock) Value() (bool, string, error) {
+	if partitioned, _ := i.in.partitionState(); partitioned {
+		return false, "", PartitionedError
+	}
+
 	i.in.l.Lock()
 	val, ok := i.in.locks[i.key]
 	i.in.l.Unlock()
@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"testing"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/logging"
@@ -19,3 +20,55 @@ func TestInmemHA(t *testing.T) {
 	// Use the same inmem backend to acquire the same set of locks
 	physical.ExerciseHABackend(t, inm.(physical.HABackend), inm.(physical.HABackend))
 }
+
+func TestInmemHA_partition(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmemHA(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ha := inm.(*InmemHABackend)
+	backend := ha.Backend.(*InmemBackend)
+
+	lock, err := ha.LockWith("foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaderCh, err := lock.Lock(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Partitioning the backend loses the held lock
+	backend.SetPartitioned(true)
+	select {
+	case <-leaderCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected lock to be lost on partition")
+	}
+	if _, _, err := lock.Value(); err != PartitionedError {
+		t.Fatalf("expected partitioned error, got %v", err)
+	}
+
+	other, err := ha.LockWith("foo", "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Lock(nil); err != PartitionedError {
+		t.Fatalf("expected partitioned error, got %v", err)
+	}
+
+	// Once healed the lock can be acquired again
+	backend.SetPartitioned(false)
+	if _, err := other.Lock(nil); err != nil {
+		t.Fatal(err)
+	}
+	held, val, err := other.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !held || val != "baz" {
+		t.Fatalf("bad: held %t, value %q", held, val)
+	}
+}
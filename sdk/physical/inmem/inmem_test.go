@@ -1,7 +1,10 @@
 package inmem
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/logging"
@@ -18,3 +21,82 @@ func TestInmem(t *testing.T) {
 	physical.ExerciseBackend(t, inm)
 	physical.ExerciseBackend_ListPrefix(t, inm)
 }
+
+func TestInmem_faultInjection(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+	entry := &physical.Entry{Key: "foo", Value: []byte("bar")}
+
+	// The same seed yields the same sequence of injected errors
+	errorSequence := func() []bool {
+		inm, err := NewInmem(map[string]string{
+			"error_percent": "50",
+			"random_seed":   "42",
+		}, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var seq []bool
+		for i := 0; i < 20; i++ {
+			err := inm.Put(ctx, entry)
+			if err != nil && err != InjectedError {
+				t.Fatal(err)
+			}
+			seq = append(seq, err == InjectedError)
+		}
+		return seq
+	}
+	first := errorSequence()
+	if !reflect.DeepEqual(first, errorSequence()) {
+		t.Fatal("expected the same seed to inject the same errors")
+	}
+	var failures int
+	for _, failed := range first {
+		if failed {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Fatalf("expected some but not all operations to fail, got %d of %d", failures, len(first))
+	}
+
+	inm, err := NewInmem(map[string]string{"latency": "50ms"}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := inm.(*InmemBackend)
+
+	start := time.Now()
+	if err := b.Put(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected latency to be added, took %s", elapsed)
+	}
+
+	// Latency respects cancellation
+	cancelledCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Get(cancelledCtx, "foo"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	b.SetLatency(0)
+
+	b.SetPartitioned(true)
+	if _, err := b.Get(ctx, "foo"); err != PartitionedError {
+		t.Fatalf("expected partitioned error, got %v", err)
+	}
+	b.SetPartitioned(false)
+	out, err := b.Get(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out == nil || string(out.Value) != "bar" {
+		t.Fatalf("bad entry after partition healed: %#v", out)
+	}
+
+	if _, err := NewInmem(map[string]string{"error_percent": "101"}, logger); err == nil {
+		t.Fatal("expected error for invalid error_percent")
+	}
+}
@@ -0,0 +1,277 @@
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/hashicorp/vault/sdk/version"
+)
+
+const (
+	// Labels applied to the pod Vault is running in
+	labelVaultVersion = "vault-version"
+	labelActive       = "vault-active"
+	labelSealed       = "vault-sealed"
+	labelPerfStandby  = "vault-perf-standby"
+
+	// retryInterval is how long to wait before retrying a failed label
+	// update
+	retryInterval = 5 * time.Second
+
+	// requestTimeout bounds each call to the Kubernetes API
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	// These are variables so that tests can override them
+	serviceAccountTokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCACertPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	errNotInCluster = errors.New("unable to load in-cluster configuration, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
+)
+
+var _ physical.ServiceDiscovery = (*serviceRegistration)(nil)
+
+// serviceRegistration labels the pod Vault is running in with its current
+// status so that Kubernetes services and monitoring can select on it, e.g. to
+// route traffic only to the active node.
+type serviceRegistration struct {
+	logger    log.Logger
+	client    *client
+	namespace string
+	podName   string
+
+	notifyCh chan struct{}
+
+	l               sync.RWMutex
+	activeFunc      physical.ActiveFunction
+	sealedFunc      physical.SealedFunction
+	perfStandbyFunc physical.PerformanceStandbyFunction
+}
+
+// NewServiceRegistration constructs a Kubernetes service registration. The
+// pod name and namespace are read from the pod_name and namespace parameters,
+// falling back to the VAULT_K8S_POD_NAME and VAULT_K8S_NAMESPACE environment
+// variables and, for the namespace, the service account's namespace. Vault
+// must be running inside the cluster with a service account permitted to
+// patch its own pod.
+func NewServiceRegistration(conf map[string]string, logger log.Logger) (physical.ServiceDiscovery, error) {
+	podName := conf["pod_name"]
+	if podName == "" {
+		podName = os.Getenv("VAULT_K8S_POD_NAME")
+	}
+	if podName == "" {
+		return nil, errors.New("pod_name must be set, either in the configuration or via VAULT_K8S_POD_NAME")
+	}
+
+	namespace := conf["namespace"]
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_K8S_NAMESPACE")
+	}
+	if namespace == "" {
+		ns, err := ioutil.ReadFile(serviceAccountNamespacePath)
+		if err != nil {
+			return nil, errwrap.Wrapf("namespace not configured and could not be read from the service account: {{err}}", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	c, err := newInClusterClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if logger.IsDebug() {
+		logger.Debug("config pod_name set", "pod_name", podName)
+		logger.Debug("config namespace set", "namespace", namespace)
+	}
+
+	return newServiceRegistration(c, namespace, podName, logger), nil
+}
+
+func newServiceRegistration(c *client, namespace, podName string, logger log.Logger) *serviceRegistration {
+	return &serviceRegistration{
+		logger:    logger,
+		client:    c,
+		namespace: namespace,
+		podName:   podName,
+		notifyCh:  make(chan struct{}, 1),
+	}
+}
+
+// RunServiceDiscovery applies the initial set of labels and then starts a
+// goroutine that updates them whenever the core notifies of a state change.
+func (r *serviceRegistration) RunServiceDiscovery(waitGroup *sync.WaitGroup, shutdownCh physical.ShutdownChannel, redirectAddr string, activeFunc physical.ActiveFunction, sealedFunc physical.SealedFunction, perfStandbyFunc physical.PerformanceStandbyFunction) error {
+	r.l.Lock()
+	r.activeFunc = activeFunc
+	r.sealedFunc = sealedFunc
+	r.perfStandbyFunc = perfStandbyFunc
+	r.l.Unlock()
+
+	// Apply the initial labels synchronously so that missing permissions are
+	// reported at startup
+	if err := r.updateLabels(); err != nil {
+		return errwrap.Wrapf("error labeling pod: {{err}}", err)
+	}
+
+	waitGroup.Add(1)
+	go r.run(waitGroup, shutdownCh)
+	return nil
+}
+
+func (r *serviceRegistration) run(waitGroup *sync.WaitGroup, shutdownCh physical.ShutdownChannel) {
+	defer waitGroup.Done()
+
+	var retryCh <-chan time.Time
+	for {
+		select {
+		case <-shutdownCh:
+			r.logger.Debug("shutting down kubernetes service registration")
+			return
+		case <-r.notifyCh:
+		case <-retryCh:
+		}
+
+		retryCh = nil
+		if err := r.updateLabels(); err != nil {
+			r.logger.Warn("error updating pod labels, will retry", "error", err)
+			retryCh = time.After(retryInterval)
+		}
+	}
+}
+
+func (r *serviceRegistration) notify() {
+	select {
+	case r.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *serviceRegistration) NotifyActiveStateChange() error {
+	r.notify()
+	return nil
+}
+
+func (r *serviceRegistration) NotifySealedStateChange() error {
+	r.notify()
+	return nil
+}
+
+func (r *serviceRegistration) NotifyPerformanceStandbyStateChange() error {
+	r.notify()
+	return nil
+}
+
+func (r *serviceRegistration) labels() map[string]string {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	labels := map[string]string{
+		labelVaultVersion: version.GetVersion().VersionNumber(),
+	}
+	if r.activeFunc != nil {
+		labels[labelActive] = strconv.FormatBool(r.activeFunc())
+	}
+	if r.sealedFunc != nil {
+		labels[labelSealed] = strconv.FormatBool(r.sealedFunc())
+	}
+	if r.perfStandbyFunc != nil {
+		labels[labelPerfStandby] = strconv.FormatBool(r.perfStandbyFunc())
+	}
+	return labels
+}
+
+func (r *serviceRegistration) updateLabels() error {
+	return r.client.patchPodLabels(r.namespace, r.podName, r.labels())
+}
+
+// client is a minimal client for the parts of the Kubernetes API used for
+// service registration.
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newInClusterClient() (*client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errNotInCluster
+	}
+
+	token, err := ioutil.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return nil, errwrap.Wrapf("error reading service account token: {{err}}", err)
+	}
+
+	caPEM, err := ioutil.ReadFile(serviceAccountCACertPath)
+	if err != nil {
+		return nil, errwrap.Wrapf("error reading service account CA certificate: {{err}}", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %q", serviceAccountCACertPath)
+	}
+
+	return &client{
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: pool,
+				},
+			},
+		},
+		baseURL: "https://" + net.JoinHostPort(host, port),
+		token:   strings.TrimSpace(string(token)),
+	}, nil
+}
+
+func (c *client) patchPodLabels(namespace, podName string, labels map[string]string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s", c.baseURL, url.PathEscape(namespace), url.PathEscape(podName))
+	req, err := http.NewRequest("PATCH", u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d patching pod %s/%s: %s", resp.StatusCode, namespace, podName, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
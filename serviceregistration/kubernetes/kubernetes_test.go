@@ -0,0 +1,139 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+)
+
+type patchRecorder struct {
+	sync.Mutex
+	paths  []string
+	labels []map[string]string
+	fail   bool
+}
+
+func (p *patchRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.Lock()
+	defer p.Unlock()
+
+	if r.Method != "PATCH" || r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if p.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var body struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	p.paths = append(p.paths, r.URL.Path)
+	p.labels = append(p.labels, body.Metadata.Labels)
+	w.Write([]byte("{}"))
+}
+
+func (p *patchRecorder) last() (string, map[string]string, int) {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.labels) == 0 {
+		return "", nil, 0
+	}
+	return p.paths[len(p.paths)-1], p.labels[len(p.labels)-1], len(p.labels)
+}
+
+func TestServiceRegistration(t *testing.T) {
+	recorder := &patchRecorder{}
+	ts := httptest.NewServer(recorder)
+	defer ts.Close()
+
+	c := &client{
+		httpClient: ts.Client(),
+		baseURL:    ts.URL,
+		token:      "token",
+	}
+	r := newServiceRegistration(c, "vault", "vault-0", logging.NewVaultLogger(log.Trace))
+
+	var l sync.Mutex
+	active, sealed := false, true
+	activeFunc := func() bool { l.Lock(); defer l.Unlock(); return active }
+	sealedFunc := func() bool { l.Lock(); defer l.Unlock(); return sealed }
+	perfStandbyFunc := func() bool { return false }
+
+	shutdownCh := make(chan struct{})
+	var wg sync.WaitGroup
+	if err := r.RunServiceDiscovery(&wg, shutdownCh, "", activeFunc, sealedFunc, perfStandbyFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	path, labels, _ := recorder.last()
+	if path != "/api/v1/namespaces/vault/pods/vault-0" {
+		t.Fatalf("bad path: %q", path)
+	}
+	if labels[labelActive] != "false" || labels[labelSealed] != "true" || labels[labelPerfStandby] != "false" || labels[labelVaultVersion] == "" {
+		t.Fatalf("bad initial labels: %#v", labels)
+	}
+
+	l.Lock()
+	active, sealed = true, false
+	l.Unlock()
+	if err := r.NotifyActiveStateChange(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, labels, _ = recorder.last()
+		if labels[labelActive] == "true" && labels[labelSealed] == "false" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("labels were not updated: %#v", labels)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(shutdownCh)
+	wg.Wait()
+}
+
+func TestServiceRegistration_initialFailure(t *testing.T) {
+	recorder := &patchRecorder{fail: true}
+	ts := httptest.NewServer(recorder)
+	defer ts.Close()
+
+	c := &client{
+		httpClient: ts.Client(),
+		baseURL:    ts.URL,
+		token:      "token",
+	}
+	r := newServiceRegistration(c, "vault", "vault-0", logging.NewVaultLogger(log.Trace))
+
+	f := func() bool { return false }
+	if err := r.RunServiceDiscovery(&sync.WaitGroup{}, make(chan struct{}), "", f, f, f); err == nil {
+		t.Fatal("expected error when the pod cannot be labeled")
+	}
+}
+
+func TestNewServiceRegistration_notInCluster(t *testing.T) {
+	_, err := NewServiceRegistration(map[string]string{
+		"pod_name":  "vault-0",
+		"namespace": "vault",
+	}, logging.NewVaultLogger(log.Trace))
+	if err != errNotInCluster {
+		t.Fatalf("expected not in cluster error, got: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+// Package serviceregistration contains the implementations that advertise a
+// Vault node's status to a service registry independently of the storage
+// backend in use.
+package serviceregistration
+
+import (
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+// Factory is the factory function to create a service registration. The
+// returned value is notified by the core of changes to the node's sealed,
+// active and performance standby status in the same way as an HA backend
+// that implements physical.ServiceDiscovery.
+type Factory func(config map[string]string, logger log.Logger) (physical.ServiceDiscovery, error)
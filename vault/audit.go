@@ -2,7 +2,6 @@ package vault
 
 import (
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,7 +9,6 @@ import (
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/helper/namespace"
-	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -205,7 +203,8 @@ func (c *Core) loadAudits(ctx context.Context) error {
 	defer c.auditLock.Unlock()
 
 	if raw != nil {
-		if err := jsonutil.DecodeJSON(raw.Value, auditTable); err != nil {
+		auditTable, err = c.readMountTable(ctx, raw.Value)
+		if err != nil {
 			c.logger.Error("failed to decode audit table", "error", err)
 			return errLoadAuditFailed
 		}
@@ -219,7 +218,8 @@ func (c *Core) loadAudits(ctx context.Context) error {
 	}
 
 	if rawLocal != nil {
-		if err := jsonutil.DecodeJSON(rawLocal.Value, localAuditTable); err != nil {
+		localAuditTable, err = c.readMountTable(ctx, rawLocal.Value)
+		if err != nil {
 			c.logger.Error("failed to decode local audit table", "error", err)
 			return errLoadAuditFailed
 		}
@@ -305,39 +305,15 @@ func (c *Core) persistAudit(ctx context.Context, table *MountTable, localOnly bo
 	}
 
 	if !localOnly {
-		// Marshal the table
-		compressedBytes, err := jsonutil.EncodeJSONAndCompress(nonLocalAudit, nil)
-		if err != nil {
-			c.logger.Error("failed to encode and/or compress audit table", "error", err)
-			return err
-		}
-
-		// Create an entry
-		entry := &logical.StorageEntry{
-			Key:   coreAuditConfigPath,
-			Value: compressedBytes,
-		}
-
-		// Write to the physical backend
-		if err := c.barrier.Put(ctx, entry); err != nil {
+		// Write the table to the physical backend, sharded if needed
+		if err := c.writeMountTable(ctx, nonLocalAudit, coreAuditConfigPath); err != nil {
 			c.logger.Error("failed to persist audit table", "error", err)
 			return err
 		}
 	}
 
 	// Repeat with local audit
-	compressedBytes, err := jsonutil.EncodeJSONAndCompress(localAudit, nil)
-	if err != nil {
-		c.logger.Error("failed to encode and/or compress local audit table", "error", err)
-		return err
-	}
-
-	entry := &logical.StorageEntry{
-		Key:   coreLocalAuditConfigPath,
-		Value: compressedBytes,
-	}
-
-	if err := c.barrier.Put(ctx, entry); err != nil {
+	if err := c.writeMountTable(ctx, localAudit, coreLocalAuditConfigPath); err != nil {
 		c.logger.Error("failed to persist local audit table", "error", err)
 		return err
 	}
@@ -439,16 +415,28 @@ func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logi
 	if !ok {
 		return nil, fmt.Errorf("unknown backend type: %q", entry.Type)
 	}
+	hmacType := salt.HMACTypeSHA256
+	if conf["hmac_type"] != "" {
+		hmacType = conf["hmac_type"]
+	}
+	hmacFunc, err := salt.HMACFunc(hmacType)
+	if err != nil {
+		return nil, err
+	}
 	saltConfig := &salt.Config{
-		HMAC:     sha256.New,
-		HMACType: "hmac-sha256",
+		HMAC:     hmacFunc,
+		HMACType: hmacType,
 		Location: salt.DefaultLocation,
 	}
 
+	auditLogger := c.baseLogger.Named("audit")
+	c.AddLogger(auditLogger)
+
 	be, err := f(ctx, &audit.BackendConfig{
 		SaltView:   view,
 		SaltConfig: saltConfig,
 		Config:     conf,
+		Logger:     auditLogger.With("path", entry.Path),
 	})
 	if err != nil {
 		return nil, err
@@ -457,9 +445,6 @@ func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logi
 		return nil, fmt.Errorf("nil backend returned from %q factory function", entry.Type)
 	}
 
-	auditLogger := c.baseLogger.Named("audit")
-	c.AddLogger(auditLogger)
-
 	switch entry.Type {
 	case "file":
 		key := "audit_file|" + entry.Path
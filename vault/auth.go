@@ -10,7 +10,6 @@ import (
 	"github.com/hashicorp/vault/builtin/plugin"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/consts"
-	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -509,21 +508,8 @@ func (c *Core) persistAuth(ctx context.Context, table *MountTable, local *bool)
 	}
 
 	writeTable := func(mt *MountTable, path string) error {
-		// Encode the mount table into JSON and compress it (lzw).
-		compressedBytes, err := jsonutil.EncodeJSONAndCompress(mt, nil)
-		if err != nil {
-			c.logger.Error("failed to encode or compress auth mount table", "error", err)
-			return err
-		}
-
-		// Create an entry
-		entry := &logical.StorageEntry{
-			Key:   path,
-			Value: compressedBytes,
-		}
-
-		// Write to the physical backend
-		if err := c.barrier.Put(ctx, entry); err != nil {
+		// Write the auth table to the physical backend, sharded if needed
+		if err := c.writeMountTable(ctx, mt, path); err != nil {
 			c.logger.Error("failed to persist auth mount table", "error", err)
 			return err
 		}
@@ -15,10 +15,12 @@ import (
 
 	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
+	"go.opencensus.io/trace"
 )
 
 const (
@@ -69,6 +71,10 @@ type AESGCMBarrier struct {
 	// future versioning of barrier implementations. It's var instead
 	// of const to allow for testing
 	currentAESGCMVersionByte byte
+
+	// keyLockFunc, if set, is called with key material before a cipher is
+	// constructed from it so that it can be locked into memory
+	keyLockFunc func([]byte)
 }
 
 // NewAESGCMBarrier is used to construct a new barrier that uses
@@ -288,6 +294,7 @@ func (b *AESGCMBarrier) ReloadKeyring(ctx context.Context) error {
 
 	// Setup the keyring and finish
 	b.keyring = keyring
+	b.lockMasterKey()
 	return nil
 }
 
@@ -336,6 +343,7 @@ func (b *AESGCMBarrier) ReloadMasterKey(ctx context.Context) error {
 	// Update the master key
 	oldKeyring := b.keyring
 	b.keyring = b.keyring.SetMasterKey(key.Value)
+	b.lockMasterKey()
 	oldKeyring.Zeroize(false)
 	return nil
 }
@@ -387,6 +395,7 @@ func (b *AESGCMBarrier) Unseal(ctx context.Context, key []byte) error {
 
 		// Setup the keyring and finish
 		b.keyring = keyring
+		b.lockMasterKey()
 		b.sealed = false
 		return nil
 	}
@@ -448,6 +457,7 @@ func (b *AESGCMBarrier) Unseal(ctx context.Context, key []byte) error {
 
 	// Set the vault as unsealed
 	b.keyring = keyring
+	b.lockMasterKey()
 	b.sealed = false
 	return nil
 }
@@ -654,6 +664,7 @@ func (b *AESGCMBarrier) Rekey(ctx context.Context, key []byte) error {
 	// Swap the keyrings
 	oldKeyring := b.keyring
 	b.keyring = newKeyring
+	b.lockMasterKey()
 	oldKeyring.Zeroize(false)
 	return nil
 }
@@ -672,6 +683,7 @@ func (b *AESGCMBarrier) SetMasterKey(key []byte) error {
 	// Swap the keyrings
 	oldKeyring := b.keyring
 	b.keyring = newKeyring
+	b.lockMasterKey()
 	oldKeyring.Zeroize(false)
 	return nil
 }
@@ -693,8 +705,11 @@ func (b *AESGCMBarrier) updateMasterKeyCommon(key []byte) (*Keyring, error) {
 }
 
 // Put is used to insert or update an entry
-func (b *AESGCMBarrier) Put(ctx context.Context, entry *logical.StorageEntry) error {
+func (b *AESGCMBarrier) Put(ctx context.Context, entry *logical.StorageEntry) (retErr error) {
 	defer metrics.MeasureSince([]string{"barrier", "put"}, time.Now())
+	ctx, span := tracing.StartSpan(ctx, "barrier.put", trace.StringAttribute("vault.key", entry.Key))
+	defer func() { tracing.EndSpan(span, retErr) }()
+
 	b.l.RLock()
 	if b.sealed {
 		b.l.RUnlock()
@@ -717,7 +732,7 @@ func (b *AESGCMBarrier) Put(ctx context.Context, entry *logical.StorageEntry) er
 		Value:    value,
 		SealWrap: entry.SealWrap,
 	}
-	return b.backend.Put(ctx, pe)
+	return b.storagePut(ctx, pe)
 }
 
 // Get is used to fetch an entry
@@ -725,8 +740,11 @@ func (b *AESGCMBarrier) Get(ctx context.Context, key string) (*logical.StorageEn
 	return b.lockSwitchedGet(ctx, key, true)
 }
 
-func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, key string, getLock bool) (*logical.StorageEntry, error) {
+func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, key string, getLock bool) (_ *logical.StorageEntry, retErr error) {
 	defer metrics.MeasureSince([]string{"barrier", "get"}, time.Now())
+	ctx, span := tracing.StartSpan(ctx, "barrier.get", trace.StringAttribute("vault.key", key))
+	defer func() { tracing.EndSpan(span, retErr) }()
+
 	if getLock {
 		b.l.RLock()
 	}
@@ -738,7 +756,7 @@ func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, key string, getLock
 	}
 
 	// Read the key from the backend
-	pe, err := b.backend.Get(ctx, key)
+	pe, err := b.storageGet(ctx, key)
 	if err != nil {
 		if getLock {
 			b.l.RUnlock()
@@ -791,8 +809,11 @@ func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, key string, getLock
 }
 
 // Delete is used to permanently delete an entry
-func (b *AESGCMBarrier) Delete(ctx context.Context, key string) error {
+func (b *AESGCMBarrier) Delete(ctx context.Context, key string) (retErr error) {
 	defer metrics.MeasureSince([]string{"barrier", "delete"}, time.Now())
+	ctx, span := tracing.StartSpan(ctx, "barrier.delete", trace.StringAttribute("vault.key", key))
+	defer func() { tracing.EndSpan(span, retErr) }()
+
 	b.l.RLock()
 	sealed := b.sealed
 	b.l.RUnlock()
@@ -800,13 +821,16 @@ func (b *AESGCMBarrier) Delete(ctx context.Context, key string) error {
 		return ErrBarrierSealed
 	}
 
-	return b.backend.Delete(ctx, key)
+	return b.storageDelete(ctx, key)
 }
 
 // List is used ot list all the keys under a given
 // prefix, up to the next prefix.
-func (b *AESGCMBarrier) List(ctx context.Context, prefix string) ([]string, error) {
+func (b *AESGCMBarrier) List(ctx context.Context, prefix string) (_ []string, retErr error) {
 	defer metrics.MeasureSince([]string{"barrier", "list"}, time.Now())
+	ctx, span := tracing.StartSpan(ctx, "barrier.list", trace.StringAttribute("vault.prefix", prefix))
+	defer func() { tracing.EndSpan(span, retErr) }()
+
 	b.l.RLock()
 	sealed := b.sealed
 	b.l.RUnlock()
@@ -814,7 +838,39 @@ func (b *AESGCMBarrier) List(ctx context.Context, prefix string) ([]string, erro
 		return nil, ErrBarrierSealed
 	}
 
-	return b.backend.List(ctx, prefix)
+	return b.storageList(ctx, prefix)
+}
+
+// The storage* functions call the physical backend within a span, so that
+// the time spent in storage can be told apart from the time spent in the
+// barrier
+
+func (b *AESGCMBarrier) storagePut(ctx context.Context, pe *physical.Entry) error {
+	ctx, span := tracing.StartSpan(ctx, "storage.put")
+	err := b.backend.Put(ctx, pe)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (b *AESGCMBarrier) storageGet(ctx context.Context, key string) (*physical.Entry, error) {
+	ctx, span := tracing.StartSpan(ctx, "storage.get")
+	pe, err := b.backend.Get(ctx, key)
+	tracing.EndSpan(span, err)
+	return pe, err
+}
+
+func (b *AESGCMBarrier) storageDelete(ctx context.Context, key string) error {
+	ctx, span := tracing.StartSpan(ctx, "storage.delete")
+	err := b.backend.Delete(ctx, key)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (b *AESGCMBarrier) storageList(ctx context.Context, prefix string) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx, "storage.list")
+	keys, err := b.backend.List(ctx, prefix)
+	tracing.EndSpan(span, err)
+	return keys, err
 }
 
 // aeadForTerm returns the AES-GCM AEAD for the given term
@@ -852,8 +908,19 @@ func (b *AESGCMBarrier) aeadForTerm(term uint32) (cipher.AEAD, error) {
 	return aead, nil
 }
 
+// lockMasterKey locks the keyring's copy of the master key into memory
+func (b *AESGCMBarrier) lockMasterKey() {
+	if b.keyLockFunc != nil && b.keyring != nil && len(b.keyring.MasterKey()) > 0 {
+		b.keyLockFunc(b.keyring.MasterKey())
+	}
+}
+
 // aeadFromKey returns an AES-GCM AEAD using the given key.
 func (b *AESGCMBarrier) aeadFromKey(key []byte) (cipher.AEAD, error) {
+	if b.keyLockFunc != nil {
+		b.keyLockFunc(key)
+	}
+
 	// Create the AES cipher
 	aesCipher, err := aes.NewCipher(key)
 	if err != nil {
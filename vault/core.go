@@ -34,11 +34,13 @@ import (
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/helper/mlock"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/tlsutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/hashicorp/vault/shamir"
 	"github.com/hashicorp/vault/vault/cluster"
+	"github.com/hashicorp/vault/vault/managedkeys"
 	"github.com/hashicorp/vault/vault/seal"
 )
 
@@ -164,6 +166,11 @@ type Core struct {
 	// HABackend may be available depending on the physical backend
 	ha physical.HABackend
 
+	// serviceRegistration is notified of changes to this node's sealed,
+	// active and performance standby status. It is either configured
+	// explicitly or provided by the HA backend.
+	serviceRegistration physical.ServiceDiscovery
+
 	// redirectAddr is the address we advertise as leader if held
 	redirectAddr string
 
@@ -199,6 +206,9 @@ type Core struct {
 	// auditBackends is the mapping of backends to use for this core
 	auditBackends map[string]audit.Factory
 
+	// managedKeyTypes is the mapping of managed key types to use for this core
+	managedKeyTypes map[string]managedkeys.Factory
+
 	// stateLock protects mutable state
 	stateLock sync.RWMutex
 	sealed    *uint32
@@ -357,6 +367,22 @@ type Core struct {
 	// CORS Information
 	corsConfig *CORSConfig
 
+	// featureConfig holds the feature flag overrides of this cluster
+	featureConfig *FeatureConfig
+
+	// rateLimiter applies the per-client rate limit rules
+	rateLimiter *rateLimiter
+
+	// managedKeys caches the managed keys in use
+	managedKeys *managedKeyStore
+
+	// kvWatches holds the watches of kv-v2 secrets
+	kvWatches *kvWatchStore
+
+	// sanitizedConfig holds the sanitized server configuration most recently
+	// loaded at startup or on reload
+	sanitizedConfig atomic.Value
+
 	// The active set of upstream cluster addresses; stored via the Echo
 	// mechanism, loaded by the balancer
 	atomicPrimaryClusterAddrs *atomic.Value
@@ -374,6 +400,11 @@ type Core struct {
 	// rawEnabled indicates whether the Raw endpoint is enabled
 	rawEnabled bool
 
+	// lockKeyMaterialOnly indicates whether the unseal, recovery and master
+	// key material handled by the core is to be locked into memory, as the
+	// rest of the memory isn't
+	lockKeyMaterialOnly bool
+
 	// pluginDirectory is the location vault will look for plugin binaries
 	pluginDirectory string
 
@@ -441,11 +472,17 @@ type CoreConfig struct {
 
 	AuditBackends map[string]audit.Factory `json:"audit_backends" structs:"audit_backends" mapstructure:"audit_backends"`
 
+	ManagedKeyTypes map[string]managedkeys.Factory `json:"managed_key_types" structs:"managed_key_types" mapstructure:"managed_key_types"`
+
 	Physical physical.Backend `json:"physical" structs:"physical" mapstructure:"physical"`
 
 	// May be nil, which disables HA operations
 	HAPhysical physical.HABackend `json:"ha_physical" structs:"ha_physical" mapstructure:"ha_physical"`
 
+	// May be nil, in which case the HA backend is used for service
+	// registration if it supports it
+	ServiceRegistration physical.ServiceDiscovery `json:"service_registration" structs:"service_registration" mapstructure:"service_registration"`
+
 	Seal Seal `json:"seal" structs:"seal" mapstructure:"seal"`
 
 	Logger log.Logger `json:"logger" structs:"logger" mapstructure:"logger"`
@@ -456,6 +493,13 @@ type CoreConfig struct {
 	// Disables mlock syscall
 	DisableMlock bool `json:"disable_mlock" structs:"disable_mlock" mapstructure:"disable_mlock"`
 
+	// Locks only the pages holding barrier key material into memory, for
+	// environments where locking all memory isn't possible
+	LockKeyMaterial bool `json:"lock_key_material" structs:"lock_key_material" mapstructure:"lock_key_material"`
+
+	// Prevents the process from writing core dumps
+	DisableCoreDumps bool `json:"disable_core_dumps" structs:"disable_core_dumps" mapstructure:"disable_core_dumps"`
+
 	// Custom cache size for the LRU cache on the physical backend, or zero for default
 	CacheSize int `json:"cache_size" structs:"cache_size" mapstructure:"cache_size"`
 
@@ -499,6 +543,10 @@ type CoreConfig struct {
 	// Telemetry objects
 	MetricsHelper *metricsutil.MetricsHelper
 
+	// MetricsPathLabeler labels the request path metrics; they are not
+	// emitted without it
+	MetricsPathLabeler *metricsutil.PathLabeler
+
 	CounterSyncInterval time.Duration
 }
 
@@ -508,12 +556,16 @@ func (c *CoreConfig) Clone() *CoreConfig {
 		LogicalBackends:           c.LogicalBackends,
 		CredentialBackends:        c.CredentialBackends,
 		AuditBackends:             c.AuditBackends,
+		ManagedKeyTypes:           c.ManagedKeyTypes,
 		Physical:                  c.Physical,
 		HAPhysical:                c.HAPhysical,
+		ServiceRegistration:       c.ServiceRegistration,
 		Seal:                      c.Seal,
 		Logger:                    c.Logger,
 		DisableCache:              c.DisableCache,
 		DisableMlock:              c.DisableMlock,
+		LockKeyMaterial:           c.LockKeyMaterial,
+		DisableCoreDumps:          c.DisableCoreDumps,
 		CacheSize:                 c.CacheSize,
 		RedirectAddr:              c.RedirectAddr,
 		ClusterAddr:               c.ClusterAddr,
@@ -532,6 +584,7 @@ func (c *CoreConfig) Clone() *CoreConfig {
 		DisablePerformanceStandby: c.DisablePerformanceStandby,
 		DisableIndexing:           c.DisableIndexing,
 		AllLoggers:                c.AllLoggers,
+		MetricsPathLabeler:        c.MetricsPathLabeler,
 		CounterSyncInterval:       c.CounterSyncInterval,
 	}
 }
@@ -620,6 +673,7 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 
 	atomic.StoreUint32(c.sealed, 1)
 	c.allLoggers = append(c.allLoggers, c.logger)
+	c.router.pathLabeler = conf.MetricsPathLabeler
 
 	atomic.StoreUint32(c.replicationState, uint32(consts.ReplicationDRDisabled|consts.ReplicationPerformanceDisabled))
 	c.localClusterCert.Store(([]byte)(nil))
@@ -644,6 +698,16 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		Enabled: new(uint32),
 	}
 
+	c.featureConfig = &FeatureConfig{
+		Overrides: make(map[string]bool),
+	}
+
+	c.rateLimiter = newRateLimiter()
+
+	c.managedKeys = newManagedKeyStore()
+
+	c.kvWatches = newKVWatchStore()
+
 	if c.seal == nil {
 		c.seal = NewDefaultSeal()
 	}
@@ -669,6 +733,12 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		}
 	}
 
+	if conf.DisableCoreDumps {
+		if err := mlock.DisableCoreDumps(); err != nil {
+			return nil, errwrap.Wrapf("failed to disable core dumps: {{err}}", err)
+		}
+	}
+
 	var err error
 
 	if conf.PluginDirectory != "" {
@@ -679,10 +749,15 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	}
 
 	// Construct a new AES-GCM barrier
-	c.barrier, err = NewAESGCMBarrier(c.physical)
+	barrier, err := NewAESGCMBarrier(c.physical)
 	if err != nil {
 		return nil, errwrap.Wrapf("barrier setup failed: {{err}}", err)
 	}
+	if conf.LockKeyMaterial {
+		c.lockKeyMaterialOnly = true
+		barrier.keyLockFunc = c.lockKeyMaterial
+	}
+	c.barrier = barrier
 
 	createSecondaries(c, conf)
 
@@ -690,6 +765,13 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		c.ha = conf.HAPhysical
 	}
 
+	c.serviceRegistration = conf.ServiceRegistration
+	if c.serviceRegistration == nil && c.ha != nil {
+		if sd, ok := c.ha.(physical.ServiceDiscovery); ok {
+			c.serviceRegistration = sd
+		}
+	}
+
 	// We create the funcs here, then populate the given config with it so that
 	// the caller can share state
 	conf.ReloadFuncsLock = &c.reloadFuncsLock
@@ -743,6 +825,12 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	}
 	c.auditBackends = auditBackends
 
+	managedKeyTypes := make(map[string]managedkeys.Factory)
+	for k, f := range conf.ManagedKeyTypes {
+		managedKeyTypes[k] = f
+	}
+	c.managedKeyTypes = managedKeyTypes
+
 	uiStoragePrefix := systemBarrierPrefix + "ui"
 	c.uiConfig = NewUIConfig(conf.EnableUI, physical.NewView(c.physical, uiStoragePrefix), NewBarrierView(c.barrier, uiStoragePrefix))
 
@@ -763,6 +851,41 @@ func (c *Core) CORSConfig() *CORSConfig {
 	return c.corsConfig
 }
 
+// SetSanitizedConfig stores the sanitized server configuration so that it can
+// be returned by the sys/config/state/sanitized endpoint
+func (c *Core) SetSanitizedConfig(conf map[string]interface{}) {
+	c.sanitizedConfig.Store(conf)
+}
+
+// SanitizedConfig returns the sanitized server configuration, or nil if none
+// has been set
+func (c *Core) SanitizedConfig() map[string]interface{} {
+	conf, _ := c.sanitizedConfig.Load().(map[string]interface{})
+	return conf
+}
+
+// lockKeyMaterial locks the memory holding a barrier key. Failures are logged
+// rather than returned since locking is a best-effort protection and the
+// memlock limit may be exhausted.
+func (c *Core) lockKeyMaterial(key []byte) {
+	if err := mlock.LockBytes(key); err != nil {
+		c.logger.Warn("failed to lock key material into memory", "error", err)
+	}
+}
+
+// lockKey locks the memory holding key shares or keys derived from them when
+// only key material is locked into memory
+func (c *Core) lockKey(keys ...[]byte) {
+	if !c.lockKeyMaterialOnly {
+		return
+	}
+	for _, key := range keys {
+		if len(key) > 0 {
+			c.lockKeyMaterial(key)
+		}
+	}
+}
+
 func (c *Core) GetContext() (context.Context, context.CancelFunc) {
 	c.stateLock.RLock()
 	defer c.stateLock.RUnlock()
@@ -878,6 +1001,7 @@ func (c *Core) unsealPart(ctx context.Context, seal Seal, key []byte, useRecover
 	}
 
 	// Store this key
+	c.lockKey(key)
 	c.unlockInfo.Parts = append(c.unlockInfo.Parts, key)
 
 	var config *SealConfig
@@ -922,6 +1046,7 @@ func (c *Core) unsealPart(ctx context.Context, seal Seal, key []byte, useRecover
 			return nil, errwrap.Wrapf("failed to compute master key: {{err}}", err)
 		}
 	}
+	c.lockKey(recoveredKey)
 
 	if seal.RecoveryKeySupported() && (useRecoveryKeys || c.migrationSeal != nil) {
 		// Verify recovery key
@@ -940,6 +1065,7 @@ func (c *Core) unsealPart(ctx context.Context, seal Seal, key []byte, useRecover
 			if err != nil {
 				return nil, errwrap.Wrapf("unable to retrieve stored keys: {{err}}", err)
 			}
+			c.lockKey(masterKeyShares...)
 
 			switch len(masterKeyShares) {
 			case 0:
@@ -951,6 +1077,7 @@ func (c *Core) unsealPart(ctx context.Context, seal Seal, key []byte, useRecover
 				if err != nil {
 					return nil, errwrap.Wrapf("failed to compute master key: {{err}}", err)
 				}
+				c.lockKey(masterKey)
 			}
 		}
 	} else {
@@ -1122,13 +1249,10 @@ func (c *Core) unsealInternal(ctx context.Context, masterKey []byte) (bool, erro
 	// Success!
 	atomic.StoreUint32(c.sealed, 0)
 
-	if c.ha != nil {
-		sd, ok := c.ha.(physical.ServiceDiscovery)
-		if ok {
-			if err := sd.NotifySealedStateChange(); err != nil {
-				if c.logger.IsWarn() {
-					c.logger.Warn("failed to notify unsealed status", "error", err)
-				}
+	if c.serviceRegistration != nil {
+		if err := c.serviceRegistration.NotifySealedStateChange(); err != nil {
+			if c.logger.IsWarn() {
+				c.logger.Warn("failed to notify unsealed status", "error", err)
 			}
 		}
 	}
@@ -1318,6 +1442,15 @@ func (c *Core) UIEnabled() bool {
 	return c.uiConfig.Enabled()
 }
 
+// WellKnownRedirect returns the API path serving the given path under the
+// listener's /.well-known/ prefix, if any mount has claimed it
+func (c *Core) WellKnownRedirect(path string) (string, bool) {
+	if c.Sealed() {
+		return "", false
+	}
+	return c.router.WellKnownRedirect(path)
+}
+
 // UIHeaders returns configured UI headers
 func (c *Core) UIHeaders() (http.Header, error) {
 	return c.uiConfig.Headers(context.Background())
@@ -1356,6 +1489,10 @@ func (c *Core) sealInternalWithOptions(grabStateLock, keepHALock bool) error {
 			}
 		}()
 
+		// Release the watches, which would otherwise wait for the state
+		// lock until they time out
+		c.kvWatches.stop()
+
 		c.stateLock.Lock()
 		close(doneCh)
 		// Stop requests from processing
@@ -1417,13 +1554,10 @@ func (c *Core) sealInternalWithOptions(grabStateLock, keepHALock bool) error {
 		return err
 	}
 
-	if c.ha != nil {
-		sd, ok := c.ha.(physical.ServiceDiscovery)
-		if ok {
-			if err := sd.NotifySealedStateChange(); err != nil {
-				if c.logger.IsWarn() {
-					c.logger.Warn("failed to notify sealed status", "error", err)
-				}
+	if c.serviceRegistration != nil {
+		if err := c.serviceRegistration.NotifySealedStateChange(); err != nil {
+			if c.logger.IsWarn() {
+				c.logger.Warn("failed to notify sealed status", "error", err)
 			}
 		}
 	}
@@ -1475,6 +1609,15 @@ func (s standardUnsealStrategy) unseal(ctx context.Context, logger log.Logger, c
 	if err := c.loadCORSConfig(ctx); err != nil {
 		return err
 	}
+	if err := c.loadFeatureConfig(ctx); err != nil {
+		return err
+	}
+	if err := c.loadRateLimitConfig(ctx); err != nil {
+		return err
+	}
+	if err := c.loadCubbyholeConfig(ctx); err != nil {
+		return err
+	}
 	if err := c.loadCurrentRequestCounters(ctx, time.Now()); err != nil {
 		return err
 	}
@@ -1525,6 +1668,34 @@ func (s standardUnsealStrategy) unseal(ctx context.Context, logger log.Logger, c
 	return nil
 }
 
+// cacheSubsystems are the subsystems whose caches can be flushed at runtime,
+// in the order they are flushed
+var cacheSubsystems = []string{"physical", "policy", "token"}
+
+// flushCaches drops the cached state of the given subsystems of this node, to
+// be read again from storage. The physical cache is flushed first, so that
+// the other subsystems don't reload stale entries from it.
+func (c *Core) flushCaches(ctx context.Context, subsystems []string) {
+	for _, subsystem := range cacheSubsystems {
+		if !strutil.StrListContains(subsystems, subsystem) {
+			continue
+		}
+		switch subsystem {
+		case "physical":
+			c.physicalCache.Purge(ctx)
+		case "policy":
+			if c.policyStore != nil {
+				c.policyStore.purgeCache()
+			}
+		case "token":
+			if c.tokenStore != nil {
+				c.tokenStore.purgeSalts()
+			}
+		}
+	}
+	c.logger.Info("flushed caches", "subsystems", subsystems)
+}
+
 // postUnseal is invoked after the barrier is unsealed, but before
 // allowing any user operations. This allows us to setup any state that
 // requires the Vault to be unsealed such as mount tables, logical backends,
@@ -1625,6 +1796,7 @@ func (c *Core) preSeal() error {
 	if err := enterprisePreSeal(c); err != nil {
 		result = multierror.Append(result, err)
 	}
+	c.closeManagedKeys()
 
 	preSealPhysical(c)
 
@@ -1652,6 +1824,16 @@ func stopReplicationImpl(c *Core) error {
 func (c *Core) emitMetrics(stopCh chan struct{}) {
 	emitTimer := time.Tick(time.Second)
 	writeTimer := time.Tick(c.counters.syncInterval)
+	tokenGaugeTimer := time.Tick(tokenGaugeInterval)
+
+	// Computing the token gauges is cancelled once the metrics are stopped,
+	// rather than holding up the seal
+	ctx, cancel := context.WithCancel(namespace.RootContext(nil))
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
 
 	for {
 		select {
@@ -1662,6 +1844,22 @@ func (c *Core) emitMetrics(stopCh chan struct{}) {
 			}
 			c.metricsMutex.Unlock()
 
+		case <-tokenGaugeTimer:
+			// Only the active node emits the token gauges, as they require
+			// reading all the tokens. The tokens are read without holding
+			// metricsMutex, so that setting up or stopping the expiration
+			// manager doesn't wait on it.
+			c.metricsMutex.Lock()
+			ts := c.tokenStore
+			emit := c.expiration != nil && ts != nil && !c.perfStandby
+			c.metricsMutex.Unlock()
+
+			if emit {
+				if err := ts.emitTokenGauges(ctx); err != nil && ctx.Err() == nil {
+					c.logger.Error("failed to emit token gauges", "error", err)
+				}
+			}
+
 		case <-writeTimer:
 			if c.perfStandby {
 				syncCounter(c)
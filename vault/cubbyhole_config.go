@@ -0,0 +1,115 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// cubbyholeConfigPath is where the cubbyhole config is stored, relative to
+// the core config view
+const cubbyholeConfigPath = "cubbyhole"
+
+// cubbyholeLimitsExemptKey is the context key marking internal writes to a
+// cubbyhole that aren't subject to its limits
+type cubbyholeLimitsExemptKey struct{}
+
+func withoutCubbyholeLimits(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cubbyholeLimitsExemptKey{}, true)
+}
+
+func cubbyholeLimitsExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(cubbyholeLimitsExemptKey{}).(bool)
+	return exempt
+}
+
+// CubbyholeConfig holds the limits applied to the cubbyhole of each token, so
+// that long-lived tokens can't grow it without bounds
+type CubbyholeConfig struct {
+	// MaxTokenBytes is the total size of the values a token can store, or
+	// zero for no limit
+	MaxTokenBytes int64 `json:"max_token_bytes"`
+
+	// MaxTokenEntries is the number of values a token can store, or zero for
+	// no limit
+	MaxTokenEntries int `json:"max_token_entries"`
+
+	// MaxEntryTTL is the longest a value is kept, or zero for no limit.
+	// Values written without a TTL get this TTL.
+	MaxEntryTTL time.Duration `json:"max_entry_ttl"`
+}
+
+func (c CubbyholeConfig) quotaEnabled() bool {
+	return c.MaxTokenBytes > 0 || c.MaxTokenEntries > 0
+}
+
+func (c CubbyholeConfig) validate() error {
+	switch {
+	case c.MaxTokenBytes < 0:
+		return fmt.Errorf("max_token_bytes must not be negative")
+	case c.MaxTokenEntries < 0:
+		return fmt.Errorf("max_token_entries must not be negative")
+	case c.MaxEntryTTL < 0:
+		return fmt.Errorf("max_entry_ttl must not be negative")
+	}
+	return nil
+}
+
+// CubbyholeConfig returns the current cubbyhole config
+func (c *Core) CubbyholeConfig() CubbyholeConfig {
+	if c.cubbyholeBackend == nil {
+		return CubbyholeConfig{}
+	}
+	return c.cubbyholeBackend.getConfig()
+}
+
+// SetCubbyholeConfig persists and applies the cubbyhole config
+func (c *Core) SetCubbyholeConfig(ctx context.Context, config CubbyholeConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	view := c.systemBarrierView.SubView("config/")
+
+	entry, err := logical.StorageEntryJSON(cubbyholeConfigPath, &config)
+	if err != nil {
+		return errwrap.Wrapf("failed to create cubbyhole config entry: {{err}}", err)
+	}
+
+	if err := view.Put(ctx, entry); err != nil {
+		return errwrap.Wrapf("failed to save cubbyhole config: {{err}}", err)
+	}
+
+	if c.cubbyholeBackend != nil {
+		c.cubbyholeBackend.setConfig(config)
+	}
+
+	return nil
+}
+
+// This should only be called with the core state lock held for writing, after
+// the mounts have been set up
+func (c *Core) loadCubbyholeConfig(ctx context.Context) error {
+	view := c.systemBarrierView.SubView("config/")
+
+	out, err := view.Get(ctx, cubbyholeConfigPath)
+	if err != nil {
+		return errwrap.Wrapf("failed to read cubbyhole config: {{err}}", err)
+	}
+
+	var config CubbyholeConfig
+	if out != nil {
+		if err := out.DecodeJSON(&config); err != nil {
+			return err
+		}
+	}
+
+	if c.cubbyholeBackend != nil {
+		c.cubbyholeBackend.setConfig(config)
+	}
+
+	return nil
+}
@@ -253,3 +253,9 @@ func (d dynamicSystemView) PluginEnv(_ context.Context) (*logical.PluginEnvironm
 		VaultVersion: version.GetVersion().Version,
 	}, nil
 }
+
+// ManagedKey returns the managed key with the given name, if the mount is
+// allowed to use it
+func (d dynamicSystemView) ManagedKey(ctx context.Context, name string) (logical.ManagedKey, error) {
+	return d.core.managedKey(ctx, name, d.mountEntry.APIPath())
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -72,6 +73,12 @@ type ExpirationManager struct {
 	pending     map[string]pendingInfo
 	pendingLock sync.RWMutex
 
+	// leaseLocks serialize the updates of a lease, so that its stored entry
+	// and its pending timer are updated in the same order. They are held
+	// while a lease is registered, restored, updated or deleted, but never
+	// across a call into a backend or the token store.
+	leaseLocks []*locksutil.LockEntry
+
 	tidyLock *int32
 
 	restoreMode        *int32
@@ -87,6 +94,10 @@ type ExpirationManager struct {
 
 	logLeaseExpirations bool
 	expireFunc          ExpireLeaseStrategy
+
+	// restoreWorkerCount is the number of leases loaded concurrently while
+	// restoring in the background
+	restoreWorkerCount int
 }
 
 type ExpireLeaseStrategy func(context.Context, *ExpirationManager, *leaseEntry)
@@ -143,6 +154,7 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 		tokenStore: c.tokenStore,
 		logger:     logger,
 		pending:    make(map[string]pendingInfo),
+		leaseLocks: locksutil.CreateLocks(),
 		tidyLock:   new(int32),
 
 		// new instances of the expiration manager will go immediately into
@@ -157,6 +169,7 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 
 		logLeaseExpirations: os.Getenv("VAULT_SKIP_LOGGING_LEASE_EXPIRATIONS") == "",
 		expireFunc:          e,
+		restoreWorkerCount:  restoreWorkerCount(logger),
 	}
 	*exp.restoreMode = 1
 
@@ -168,6 +181,25 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 	return exp
 }
 
+// restoreWorkerCount returns the number of workers used to load leases after
+// unseal, which can be tuned with VAULT_LEASE_RESTORE_WORKER_COUNT to match
+// how much concurrent read load the storage backend tolerates.
+func restoreWorkerCount(logger log.Logger) int {
+	raw := os.Getenv("VAULT_LEASE_RESTORE_WORKER_COUNT")
+	if raw == "" {
+		return consts.ExpirationRestoreWorkerCount
+	}
+
+	count, err := strconv.Atoi(raw)
+	if err != nil || count <= 0 {
+		if logger != nil {
+			logger.Warn("invalid VAULT_LEASE_RESTORE_WORKER_COUNT, using default", "value", raw, "default", consts.ExpirationRestoreWorkerCount)
+		}
+		return consts.ExpirationRestoreWorkerCount
+	}
+	return count
+}
+
 // setupExpiration is invoked after we've loaded the mount table to
 // initialize the expiration manager
 func (c *Core) setupExpiration(e ExpireLeaseStrategy) error {
@@ -388,13 +420,19 @@ func (m *ExpirationManager) Restore(errorFunc func()) (retErr error) {
 		}
 	}()
 
+	// Leases are only loaded here to set up their expiration timers. Until
+	// this finishes, requests touching a lease load it on demand, so unseal
+	// does not wait for the restore.
+	start := time.Now()
+	defer metrics.MeasureSince([]string{"expire", "restore"}, start)
+
 	// Accumulate existing leases
 	m.logger.Debug("collecting leases")
 	existing, leaseCount, err := m.collectLeases()
 	if err != nil {
 		return err
 	}
-	m.logger.Debug("leases collected", "num_existing", leaseCount)
+	m.logger.Info("leases collected, loading in the background", "num_existing", leaseCount, "workers", m.restoreWorkerCount)
 
 	// Make the channels used for the worker pool
 	type lease struct {
@@ -410,8 +448,8 @@ func (m *ExpirationManager) Restore(errorFunc func()) (retErr error) {
 	// Use a wait group
 	wg := &sync.WaitGroup{}
 
-	// Create 64 workers to distribute work to
-	for i := 0; i < consts.ExpirationRestoreWorkerCount; i++ {
+	// Create the workers to distribute work to
+	for i := 0; i < m.restoreWorkerCount; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -505,7 +543,7 @@ func (m *ExpirationManager) Restore(errorFunc func()) (retErr error) {
 	m.restoreLocks = nil
 	m.restoreModeLock.Unlock()
 
-	m.logger.Info("lease restore complete")
+	m.logger.Info("lease restore complete", "num_leases", leaseCount, "duration", time.Since(start))
 	return nil
 }
 
@@ -528,6 +566,10 @@ func (m *ExpirationManager) processRestore(ctx context.Context, leaseID string)
 		return nil
 	}
 
+	lock := locksutil.LockForKey(m.leaseLocks, leaseID)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Load lease and restore expiration timer
 	_, err := m.loadEntryInternal(ctx, leaseID, true, false)
 	if err != nil {
@@ -591,15 +633,8 @@ func (m *ExpirationManager) LazyRevoke(ctx context.Context, leaseID string) erro
 	}
 
 	le.ExpireTime = time.Now()
-	{
-		m.pendingLock.Lock()
-		if err := m.persistEntry(ctx, le); err != nil {
-			m.pendingLock.Unlock()
-			return err
-		}
-
-		m.updatePendingInternal(le, 0)
-		m.pendingLock.Unlock()
+	if err := m.persistAndUpdatePending(ctx, le, 0); err != nil {
+		return err
 	}
 
 	return nil
@@ -634,26 +669,12 @@ func (m *ExpirationManager) revokeCommon(ctx context.Context, leaseID string, fo
 		}
 	}
 
-	// Delete the entry
-	if err := m.deleteEntry(ctx, le); err != nil {
+	// The lease lock isn't held while revoking the entry, as revoking a token
+	// revokes its leases in turn
+	if err := m.deleteLease(ctx, le); err != nil {
 		return err
 	}
 
-	// Delete the secondary index, but only if it's a leased secret (not auth)
-	if le.Secret != nil {
-		if err := m.removeIndexByToken(ctx, le); err != nil {
-			return err
-		}
-	}
-
-	// Clear the expiration handler
-	m.pendingLock.Lock()
-	if pending, ok := m.pending[leaseID]; ok {
-		pending.timer.Stop()
-		delete(m.pending, leaseID)
-	}
-	m.pendingLock.Unlock()
-
 	if m.logger.IsInfo() && !skipToken && m.logLeaseExpirations {
 		m.logger.Info("revoked lease", "lease_id", leaseID)
 	}
@@ -713,15 +734,8 @@ func (m *ExpirationManager) RevokeByToken(ctx context.Context, te *logical.Token
 		if le != nil {
 			le.ExpireTime = time.Now()
 
-			{
-				m.pendingLock.Lock()
-				if err := m.persistEntry(ctx, le); err != nil {
-					m.pendingLock.Unlock()
-					return err
-				}
-
-				m.updatePendingInternal(le, 0)
-				m.pendingLock.Unlock()
+			if err := m.persistAndUpdatePending(ctx, le, 0); err != nil {
+				return err
 			}
 		}
 	}
@@ -896,16 +910,8 @@ func (m *ExpirationManager) Renew(ctx context.Context, leaseID string, increment
 		}
 	}
 
-	{
-		m.pendingLock.Lock()
-		if err := m.persistEntry(ctx, le); err != nil {
-			m.pendingLock.Unlock()
-			return nil, err
-		}
-
-		// Update the expiration time
-		m.updatePendingInternal(le, resp.Secret.LeaseTotal())
-		m.pendingLock.Unlock()
+	if err := m.persistAndUpdatePending(ctx, le, resp.Secret.LeaseTotal()); err != nil {
+		return nil, err
 	}
 
 	// Return the response
@@ -1013,16 +1019,8 @@ func (m *ExpirationManager) RenewToken(ctx context.Context, req *logical.Request
 	le.ExpireTime = resp.Auth.ExpirationTime()
 	le.LastRenewalTime = time.Now()
 
-	{
-		m.pendingLock.Lock()
-		if err := m.persistEntry(ctx, le); err != nil {
-			m.pendingLock.Unlock()
-			return nil, err
-		}
-
-		// Update the expiration time
-		m.updatePendingInternal(le, resp.Auth.LeaseTotal())
-		m.pendingLock.Unlock()
+	if err := m.persistAndUpdatePending(ctx, le, resp.Auth.LeaseTotal()); err != nil {
+		return nil, err
 	}
 
 	retResp.Auth = resp.Auth
@@ -1079,6 +1077,8 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		namespace:       ns,
 	}
 
+	lock := locksutil.LockForKey(m.leaseLocks, le.LeaseID)
+
 	defer func() {
 		// If there is an error we want to rollback as much as possible (note
 		// that errors here are ignored to do as much cleanup as we can). We
@@ -1093,6 +1093,9 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 				retErr = multierror.Append(retErr, errwrap.Wrapf("an additional error was encountered revoking the newly-generated secret: {{err}}", revResp.Error()))
 			}
 
+			lock.Lock()
+			defer lock.Unlock()
+
 			if err := m.deleteEntry(ctx, le); err != nil {
 				retErr = multierror.Append(retErr, errwrap.Wrapf("an additional error was encountered deleting any lease associated with the newly-generated secret: {{err}}", err))
 			}
@@ -1115,6 +1118,10 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	// The lock is released before any rollback, which takes it again
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Encode the entry
 	if err := m.persistEntry(ctx, le); err != nil {
 		return "", err
@@ -1189,14 +1196,10 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 		namespace:   tokenNS,
 	}
 
-	// Encode the entry
-	if err := m.persistEntry(ctx, &le); err != nil {
+	if err := m.persistAndUpdatePending(ctx, &le, auth.LeaseTotal()); err != nil {
 		return err
 	}
 
-	// Setup revocation timer
-	m.updatePending(&le, auth.LeaseTotal())
-
 	return nil
 }
 
@@ -1290,6 +1293,53 @@ func (m *ExpirationManager) leaseTimesForExport(le *leaseEntry) *leaseEntry {
 	return ret
 }
 
+// persistAndUpdatePending persists a lease entry and updates its pending
+// invocation. Updates of the same lease are serialized by its lease lock,
+// rather than by the pending lock, so that the storage write doesn't hold up
+// updates of unrelated leases.
+func (m *ExpirationManager) persistAndUpdatePending(ctx context.Context, le *leaseEntry, leaseTotal time.Duration) error {
+	lock := locksutil.LockForKey(m.leaseLocks, le.LeaseID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := m.persistEntry(ctx, le); err != nil {
+		return err
+	}
+
+	m.updatePending(le, leaseTotal)
+	return nil
+}
+
+// deleteLease deletes a lease entry along with its secondary index and its
+// pending invocation, holding its lease lock
+func (m *ExpirationManager) deleteLease(ctx context.Context, le *leaseEntry) error {
+	lock := locksutil.LockForKey(m.leaseLocks, le.LeaseID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Delete the entry
+	if err := m.deleteEntry(ctx, le); err != nil {
+		return err
+	}
+
+	// Delete the secondary index, but only if it's a leased secret (not auth)
+	if le.Secret != nil {
+		if err := m.removeIndexByToken(ctx, le); err != nil {
+			return err
+		}
+	}
+
+	// Clear the expiration handler
+	m.pendingLock.Lock()
+	if pending, ok := m.pending[le.LeaseID]; ok {
+		pending.timer.Stop()
+		delete(m.pending, le.LeaseID)
+	}
+	m.pendingLock.Unlock()
+
+	return nil
+}
+
 // updatePending is used to update a pending invocation for a lease
 func (m *ExpirationManager) updatePending(le *leaseEntry, leaseTotal time.Duration) {
 	m.pendingLock.Lock()
@@ -1677,7 +1727,11 @@ func (m *ExpirationManager) CreateOrFetchRevocationLeaseByToken(ctx context.Cont
 		}
 
 		// Encode the entry
-		if err := m.persistEntry(ctx, le); err != nil {
+		lock := locksutil.LockForKey(m.leaseLocks, le.LeaseID)
+		lock.Lock()
+		err := m.persistEntry(ctx, le)
+		lock.Unlock()
+		if err != nil {
 			return "", err
 		}
 	}
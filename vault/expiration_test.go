@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"sort"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
@@ -1953,3 +1955,22 @@ func badRenewFactory(ctx context.Context, conf *logical.BackendConfig) (logical.
 
 	return be, nil
 }
+
+func TestExpiration_restoreWorkerCount(t *testing.T) {
+	defer os.Unsetenv("VAULT_LEASE_RESTORE_WORKER_COUNT")
+
+	cases := map[string]int{
+		"":      consts.ExpirationRestoreWorkerCount,
+		"16":    16,
+		"0":     consts.ExpirationRestoreWorkerCount,
+		"-4":    consts.ExpirationRestoreWorkerCount,
+		"lots":  consts.ExpirationRestoreWorkerCount,
+		"  256": consts.ExpirationRestoreWorkerCount,
+	}
+	for raw, expected := range cases {
+		os.Setenv("VAULT_LEASE_RESTORE_WORKER_COUNT", raw)
+		if count := restoreWorkerCount(nil); count != expected {
+			t.Fatalf("%q: expected %d workers, got %d", raw, expected, count)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// FeatureFlag describes an experimental subsystem that can be turned on or
+// off per cluster at runtime, through the sys/features endpoints, rather than
+// at build time.
+type FeatureFlag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+var (
+	featureFlagsLock sync.RWMutex
+	featureFlags     = map[string]*FeatureFlag{}
+)
+
+// RegisterFeatureFlag makes a feature flag known to all cores. It is meant to
+// be called from the init function of the subsystem the flag controls.
+func RegisterFeatureFlag(flag *FeatureFlag) error {
+	if flag == nil || flag.Name == "" {
+		return fmt.Errorf("feature flag must have a name")
+	}
+
+	featureFlagsLock.Lock()
+	defer featureFlagsLock.Unlock()
+
+	if _, ok := featureFlags[flag.Name]; ok {
+		return fmt.Errorf("feature flag %q is already registered", flag.Name)
+	}
+	registered := *flag
+	featureFlags[flag.Name] = &registered
+	return nil
+}
+
+// FeatureFlags returns all registered feature flags, sorted by name
+func FeatureFlags() []*FeatureFlag {
+	featureFlagsLock.RLock()
+	defer featureFlagsLock.RUnlock()
+
+	flags := make([]*FeatureFlag, 0, len(featureFlags))
+	for _, flag := range featureFlags {
+		f := *flag
+		flags = append(flags, &f)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+func lookupFeatureFlag(name string) (*FeatureFlag, bool) {
+	featureFlagsLock.RLock()
+	defer featureFlagsLock.RUnlock()
+
+	flag, ok := featureFlags[name]
+	return flag, ok
+}
+
+// FeatureConfig stores the feature flags that have been explicitly turned on
+// or off for this cluster. Flags without an override use their default.
+type FeatureConfig struct {
+	sync.RWMutex `json:"-"`
+	Overrides    map[string]bool `json:"overrides"`
+}
+
+func (c *Core) saveFeatureConfig(ctx context.Context) error {
+	view := c.systemBarrierView.SubView("config/")
+
+	c.featureConfig.RLock()
+	localConfig := &FeatureConfig{
+		Overrides: make(map[string]bool, len(c.featureConfig.Overrides)),
+	}
+	for name, enabled := range c.featureConfig.Overrides {
+		localConfig.Overrides[name] = enabled
+	}
+	c.featureConfig.RUnlock()
+
+	entry, err := logical.StorageEntryJSON("features", localConfig)
+	if err != nil {
+		return errwrap.Wrapf("failed to create feature config entry: {{err}}", err)
+	}
+
+	if err := view.Put(ctx, entry); err != nil {
+		return errwrap.Wrapf("failed to save feature config: {{err}}", err)
+	}
+
+	return nil
+}
+
+// This should only be called with the core state lock held for writing
+func (c *Core) loadFeatureConfig(ctx context.Context) error {
+	view := c.systemBarrierView.SubView("config/")
+
+	// Load the config in
+	out, err := view.Get(ctx, "features")
+	if err != nil {
+		return errwrap.Wrapf("failed to read feature config: {{err}}", err)
+	}
+
+	newConfig := new(FeatureConfig)
+	if out != nil {
+		if err := out.DecodeJSON(newConfig); err != nil {
+			return err
+		}
+	}
+	if newConfig.Overrides == nil {
+		newConfig.Overrides = make(map[string]bool)
+	}
+
+	// Swap the overrides in place, as the config may be read concurrently
+	c.featureConfig.Lock()
+	c.featureConfig.Overrides = newConfig.Overrides
+	c.featureConfig.Unlock()
+
+	return nil
+}
+
+// FeatureEnabled returns whether the named feature flag is turned on for this
+// cluster. Unknown flags are always off.
+func (c *Core) FeatureEnabled(name string) bool {
+	flag, ok := lookupFeatureFlag(name)
+	if !ok {
+		return false
+	}
+
+	c.featureConfig.RLock()
+	defer c.featureConfig.RUnlock()
+
+	if enabled, ok := c.featureConfig.Overrides[name]; ok {
+		return enabled
+	}
+	return flag.Default
+}
+
+// SetFeatureEnabled turns the named feature flag on or off for this cluster
+func (c *Core) SetFeatureEnabled(ctx context.Context, name string, enabled bool) error {
+	if _, ok := lookupFeatureFlag(name); !ok {
+		return fmt.Errorf("unknown feature %q", name)
+	}
+
+	c.featureConfig.Lock()
+	c.featureConfig.Overrides[name] = enabled
+	c.featureConfig.Unlock()
+
+	return c.saveFeatureConfig(ctx)
+}
+
+// ResetFeature removes any override of the named feature flag, so that its
+// default applies again
+func (c *Core) ResetFeature(ctx context.Context, name string) error {
+	c.featureConfig.Lock()
+	delete(c.featureConfig.Overrides, name)
+	c.featureConfig.Unlock()
+
+	return c.saveFeatureConfig(ctx)
+}
@@ -3,7 +3,9 @@ package vault
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/hashicorp/errwrap"
@@ -23,7 +25,7 @@ var (
 
 	// GenerateDROperationTokenStrategy is the strategy used to generate a
 	// DR operational token
-	GenerateDROperationTokenStrategy GenerateRootStrategy = generateStandardRootToken{}
+	GenerateDROperationTokenStrategy GenerateRootStrategy = generateDROperationToken{}
 )
 
 // GenerateRootStrategy allows us to swap out the strategy we want to use to
@@ -54,6 +56,29 @@ func (g generateStandardRootToken) generate(ctx context.Context, c *Core) (strin
 	return te.ID, cleanupFunc, nil
 }
 
+// generateDROperationToken implements the GenerateRootStrategy and is in
+// charge of creating DR operation tokens. These carry the root policy, but
+// are revoked after being used for a single request.
+type generateDROperationToken struct{}
+
+func (g generateDROperationToken) generate(ctx context.Context, c *Core) (string, func(), error) {
+	te, err := c.tokenStore.drOperationToken(ctx)
+	if err != nil {
+		c.logger.Error("dr operation token generation failed", "error", err)
+		return "", nil, err
+	}
+	if te == nil {
+		c.logger.Error("got nil token entry back from dr operation token generation")
+		return "", nil, fmt.Errorf("got nil token entry back from dr operation token generation")
+	}
+
+	cleanupFunc := func() {
+		c.tokenStore.revokeOrphan(ctx, te.ID)
+	}
+
+	return te.ID, cleanupFunc, nil
+}
+
 // GenerateRootConfig holds the configuration for a root generation
 // command.
 type GenerateRootConfig struct {
@@ -67,10 +92,11 @@ type GenerateRootConfig struct {
 // GenerateRootResult holds the result of a root generation update
 // command
 type GenerateRootResult struct {
-	Progress       int
-	Required       int
-	EncodedToken   string
-	PGPFingerprint string
+	Progress        int
+	Required        int
+	EncodedToken    string
+	PGPFingerprint  string
+	KeyFingerprints []string
 }
 
 // GenerateRootProgress is used to return the root generation progress (num shares)
@@ -90,6 +116,36 @@ func (c *Core) GenerateRootProgress() (int, error) {
 	return len(c.generateRootProgress), nil
 }
 
+// GenerateRootKeyFingerprints returns the fingerprints of the key shares
+// provided so far, in the order they were provided. This lets operators tell
+// who has already taken part without revealing the shares themselves.
+func (c *Core) GenerateRootKeyFingerprints() ([]string, error) {
+	c.stateLock.RLock()
+	defer c.stateLock.RUnlock()
+	if c.Sealed() {
+		return nil, consts.ErrSealed
+	}
+	if c.standby {
+		return nil, consts.ErrStandby
+	}
+
+	c.generateRootLock.Lock()
+	defer c.generateRootLock.Unlock()
+
+	fingerprints := make([]string, 0, len(c.generateRootProgress))
+	for _, key := range c.generateRootProgress {
+		fingerprints = append(fingerprints, KeyShareFingerprint(key))
+	}
+	return fingerprints, nil
+}
+
+// KeyShareFingerprint returns the fingerprint identifying a key share, which
+// is the first 8 bytes of its SHA-256 hash, hex encoded.
+func KeyShareFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
 // GenerateRootConfiguration is used to read the root generation configuration
 // It stubbornly refuses to return the OTP if one is there.
 func (c *Core) GenerateRootConfiguration() (*GenerateRootConfig, error) {
@@ -111,7 +167,6 @@ func (c *Core) GenerateRootConfiguration() (*GenerateRootConfig, error) {
 		conf = new(GenerateRootConfig)
 		*conf = *c.generateRootConfig
 		conf.OTP = ""
-		conf.Strategy = nil
 	}
 	return conf, nil
 }
@@ -248,18 +303,25 @@ func (c *Core) GenerateRootUpdate(ctx context.Context, key []byte, nonce string,
 	}
 
 	// Store this key
+	c.lockKey(key)
 	c.generateRootProgress = append(c.generateRootProgress, key)
 	progress := len(c.generateRootProgress)
 
+	fingerprints := make([]string, 0, progress)
+	for _, existing := range c.generateRootProgress {
+		fingerprints = append(fingerprints, KeyShareFingerprint(existing))
+	}
+
 	// Check if we don't have enough keys to unlock
 	if len(c.generateRootProgress) < config.SecretThreshold {
 		if c.logger.IsDebug() {
 			c.logger.Debug("cannot generate root, not enough keys", "keys", progress, "threshold", config.SecretThreshold)
 		}
 		return &GenerateRootResult{
-			Progress:       progress,
-			Required:       config.SecretThreshold,
-			PGPFingerprint: c.generateRootConfig.PGPFingerprint,
+			Progress:        progress,
+			Required:        config.SecretThreshold,
+			PGPFingerprint:  c.generateRootConfig.PGPFingerprint,
+			KeyFingerprints: fingerprints,
 		}, nil
 	}
 
@@ -275,6 +337,7 @@ func (c *Core) GenerateRootUpdate(ctx context.Context, key []byte, nonce string,
 			return nil, errwrap.Wrapf("failed to compute master key: {{err}}", err)
 		}
 	}
+	c.lockKey(masterKey)
 
 	// Verify the master key
 	if c.seal.RecoveryKeySupported() {
@@ -326,10 +389,11 @@ func (c *Core) GenerateRootUpdate(ctx context.Context, key []byte, nonce string,
 	}
 
 	results := &GenerateRootResult{
-		Progress:       progress,
-		Required:       config.SecretThreshold,
-		EncodedToken:   token,
-		PGPFingerprint: c.generateRootConfig.PGPFingerprint,
+		Progress:        progress,
+		Required:        config.SecretThreshold,
+		EncodedToken:    token,
+		PGPFingerprint:  c.generateRootConfig.PGPFingerprint,
+		KeyFingerprints: fingerprints,
 	}
 
 	switch strategy.(type) {
@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -70,6 +71,40 @@ func (c *Core) PerfStandby() bool {
 	return perfStandby
 }
 
+// HAPeerNode describes a standby node that has recently sent a heartbeat to
+// this node while it was active.
+type HAPeerNode struct {
+	ClusterAddress string
+	LastEcho       time.Time
+}
+
+// HAPeerNodes returns the standby nodes that have sent an echo request to
+// this node within the heartbeat cache window, sorted by cluster address. The
+// result is only meaningful on the active node.
+func (c *Core) HAPeerNodes() []HAPeerNode {
+	items := c.clusterPeerClusterAddrsCache.Items()
+	nodes := make([]HAPeerNode, 0, len(items))
+	for clusterAddr, item := range items {
+		lastEcho, _ := item.Object.(time.Time)
+		nodes = append(nodes, HAPeerNode{
+			ClusterAddress: clusterAddr,
+			LastEcho:       lastEcho,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ClusterAddress < nodes[j].ClusterAddress
+	})
+	return nodes
+}
+
+// ClusterAddr returns the cluster address this node advertises to its peers
+func (c *Core) ClusterAddr() string {
+	c.stateLock.RLock()
+	clusterAddr := c.clusterAddr
+	c.stateLock.RUnlock()
+	return clusterAddr
+}
+
 // Leader is used to get the current active leader
 func (c *Core) Leader() (isLeader bool, leaderAddr, clusterAddr string, err error) {
 	// Check if HA enabled. We don't need the lock for this check as it's set
@@ -554,6 +589,10 @@ func (c *Core) waitForLeadership(newLeaderCh chan func(), manualStepDownCh, stop
 				}
 			}()
 
+			// Release the kv-v2 watches, which would otherwise wait for the
+			// statelock until they time out
+			c.kvWatches.stop()
+
 			// Grab lock if we are not stopped
 			stopped := grabLockOrStop(c.stateLock.Lock, c.stateLock.Unlock, stopCh)
 
@@ -858,9 +897,8 @@ func (c *Core) advertiseLeader(ctx context.Context, uuid string, leaderLostCh <-
 		return err
 	}
 
-	sd, ok := c.ha.(physical.ServiceDiscovery)
-	if ok {
-		if err := sd.NotifyActiveStateChange(); err != nil {
+	if c.serviceRegistration != nil {
+		if err := c.serviceRegistration.NotifyActiveStateChange(); err != nil {
 			if c.logger.IsWarn() {
 				c.logger.Warn("failed to notify active status", "error", err)
 			}
@@ -894,9 +932,8 @@ func (c *Core) clearLeader(uuid string) error {
 	err := c.barrier.Delete(context.Background(), key)
 
 	// Advertise ourselves as a standby
-	sd, ok := c.ha.(physical.ServiceDiscovery)
-	if ok {
-		if err := sd.NotifyActiveStateChange(); err != nil {
+	if c.serviceRegistration != nil {
+		if err := c.serviceRegistration.NotifyActiveStateChange(); err != nil {
 			if c.logger.IsWarn() {
 				c.logger.Warn("failed to notify standby status", "error", err)
 			}
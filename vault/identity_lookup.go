@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
 )
 
 func lookupPaths(i *IdentityStore) []*framework.Path {
@@ -43,6 +44,21 @@ func lookupPaths(i *IdentityStore) []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(lookupHelp["lookup-entity"][0]),
 			HelpDescription: strings.TrimSpace(lookupHelp["lookup-entity"][1]),
 		},
+		{
+			Pattern: "lookup/entity/batch$",
+			Fields: map[string]*framework.FieldSchema{
+				"aliases": {
+					Type:        framework.TypeSlice,
+					Description: "List of the aliases to query the entities of, each with a 'name' and a 'mount_accessor'.",
+				},
+			},
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: i.pathLookupEntityBatchUpdate(),
+			},
+
+			HelpSynopsis:    strings.TrimSpace(lookupHelp["lookup-entity-batch"][0]),
+			HelpDescription: strings.TrimSpace(lookupHelp["lookup-entity-batch"][1]),
+		},
 		{
 			Pattern: "lookup/group$",
 			Fields: map[string]*framework.FieldSchema{
@@ -189,6 +205,77 @@ func (i *IdentityStore) pathLookupEntityUpdate() framework.OperationFunc {
 	}
 }
 
+// pathLookupEntityBatchUpdate queries the entities of the given aliases,
+// reporting for each alias either its entity, if any, or why it could not be
+// queried
+func (i *IdentityStore) pathLookupEntityBatchUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		aliases, errResp := parseAliasBatch(d)
+		if errResp != nil {
+			return errResp, nil
+		}
+
+		results := make([]map[string]interface{}, 0, len(aliases))
+		failed := 0
+		for index, item := range aliases {
+			result := map[string]interface{}{
+				"index": index,
+			}
+			results = append(results, result)
+
+			var entry aliasBatchEntry
+			if err := mapstructure.Decode(item, &entry); err != nil {
+				result["error"] = fmt.Sprintf("invalid alias: %v", err)
+				failed++
+				continue
+			}
+			result["name"] = entry.Name
+			result["mount_accessor"] = entry.MountAccessor
+			if entry.Name == "" || entry.MountAccessor == "" {
+				result["error"] = "both 'name' and 'mount_accessor' needs to be set"
+				failed++
+				continue
+			}
+
+			result["entity"] = nil
+
+			alias, err := i.MemDBAliasByFactors(entry.MountAccessor, entry.Name, false, false)
+			if err != nil {
+				return nil, err
+			}
+			if alias == nil {
+				continue
+			}
+
+			entity, err := i.MemDBEntityByAliasID(alias.ID, false)
+			if err != nil {
+				return nil, err
+			}
+			if entity == nil {
+				continue
+			}
+
+			entityResp, err := i.handleEntityReadCommon(ctx, entity)
+			if err != nil {
+				return nil, err
+			}
+			if entityResp != nil {
+				result["entity"] = entityResp.Data
+			}
+		}
+
+		resp := &logical.Response{
+			Data: map[string]interface{}{
+				"entities": results,
+			},
+		}
+		if failed > 0 {
+			resp.AddWarning(fmt.Sprintf("%d of %d aliases could not be queried", failed, len(aliases)))
+		}
+		return resp, nil
+	}
+}
+
 func (i *IdentityStore) pathLookupGroupUpdate() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 		var group *identity.Group
@@ -313,6 +400,13 @@ var lookupHelp = map[string][2]string{
 		To query the entity by the unique factors that represent an alias; the name and the mount accessor.
 		`,
 	},
+	"lookup-entity-batch": {
+		"Query the entities of aliases in bulk.",
+		`Queries the entities of the given aliases, each represented by its name
+		and mount accessor. The results are returned in the order of the aliases;
+		the entity of each result is null if no entity has the alias.
+		`,
+	},
 	"lookup-group": {
 		"Query groups based on various properties.",
 		`Distinct query parameters to be set:
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hashicorp/vault/helper/namespace"
@@ -173,6 +174,84 @@ func TestIdentityStore_Lookup_Entity(t *testing.T) {
 	}
 }
 
+func TestIdentityStore_Lookup_EntityBatch(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	i, accessor, _ := testIdentityStoreWithGithubAuth(ctx, t)
+
+	resp, err := i.HandleRequest(ctx, &logical.Request{
+		Path:      "entity-alias/import",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"aliases": []interface{}{
+				map[string]interface{}{"name": "alice", "mount_accessor": accessor},
+				map[string]interface{}{"name": "bob", "mount_accessor": accessor},
+			},
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %#v\nresp: %v", err, resp)
+	}
+	imported := resp.Data["aliases"].([]map[string]interface{})
+
+	resp, err = i.HandleRequest(ctx, &logical.Request{
+		Path:      "lookup/entity/batch",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"aliases": []interface{}{
+				map[string]interface{}{"name": "bob", "mount_accessor": accessor},
+				map[string]interface{}{"name": "ALICE", "mount_accessor": accessor},
+				map[string]interface{}{"name": "carol", "mount_accessor": accessor},
+				map[string]interface{}{"name": "alice"},
+			},
+		},
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("bad: err: %#v\nresp: %v", err, resp)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Fatalf("expected a warning for the invalid alias: %#v", resp.Warnings)
+	}
+
+	results := resp.Data["entities"].([]map[string]interface{})
+	if len(results) != 4 {
+		t.Fatalf("bad: results: %#v", results)
+	}
+	for index, expectedID := range []interface{}{imported[1]["canonical_id"], imported[0]["canonical_id"]} {
+		entity := results[index]["entity"].(map[string]interface{})
+		if entity["id"] != expectedID {
+			t.Fatalf("bad entity of result %d: %#v", index, results[index])
+		}
+	}
+	// The results are keyed like those of the import
+	if results[0]["name"] != "bob" || results[0]["mount_accessor"] != accessor {
+		t.Fatalf("bad alias of result 0: %#v", results[0])
+	}
+	if entity, ok := results[2]["entity"]; !ok || entity != nil {
+		t.Fatalf("expected no entity for an unknown alias: %#v", results[2])
+	}
+	if _, ok := results[3]["error"]; !ok {
+		t.Fatalf("expected an error for an alias without mount accessor: %#v", results[3])
+	}
+
+	// Batches larger than the maximum are rejected by both endpoints
+	aliases := make([]interface{}, maxAliasBatchSize+1)
+	for index := range aliases {
+		aliases[index] = map[string]interface{}{"name": fmt.Sprintf("user%d", index), "mount_accessor": accessor}
+	}
+	for _, path := range []string{"lookup/entity/batch", "entity-alias/import"} {
+		resp, err = i.HandleRequest(ctx, &logical.Request{
+			Path:      path,
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"aliases": aliases,
+			},
+		})
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("%s: expected an error for too many aliases: err: %#v\nresp: %v", path, err, resp)
+		}
+	}
+}
+
 func TestIdentityStore_Lookup_Group(t *testing.T) {
 	var err error
 	var resp *logical.Response
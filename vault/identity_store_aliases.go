@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/vault/helper/storagepacker"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
 )
 
 // aliasPaths returns the API endpoints to operate on aliases.
@@ -87,6 +88,22 @@ This field is deprecated, use canonical_id.`,
 			HelpSynopsis:    strings.TrimSpace(aliasHelp["alias-id"][0]),
 			HelpDescription: strings.TrimSpace(aliasHelp["alias-id"][1]),
 		},
+		{
+			Pattern: "entity-alias/import$",
+			Fields: map[string]*framework.FieldSchema{
+				"aliases": {
+					Type: framework.TypeSlice,
+					Description: `List of the aliases to create, each with a 'name', a
+'mount_accessor' and optionally the 'canonical_id' of the entity to tie it to.`,
+				},
+			},
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: i.pathAliasImport(),
+			},
+
+			HelpSynopsis:    strings.TrimSpace(aliasHelp["alias-import"][0]),
+			HelpDescription: strings.TrimSpace(aliasHelp["alias-import"][1]),
+		},
 		{
 			Pattern: "entity-alias/id/?$",
 			Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -102,28 +119,9 @@ This field is deprecated, use canonical_id.`,
 // handleAliasUpdateCommon is used to update an alias
 func (i *IdentityStore) handleAliasUpdateCommon() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-		var err error
-		var alias *identity.Alias
-		var entity *identity.Entity
-		var previousEntity *identity.Entity
-
 		i.lock.Lock()
 		defer i.lock.Unlock()
 
-		// Check for update or create
-		aliasID := d.Get("id").(string)
-		if aliasID != "" {
-			alias, err = i.MemDBAliasByID(aliasID, true, false)
-			if err != nil {
-				return nil, err
-			}
-			if alias == nil {
-				return logical.ErrorResponse("invalid alias id"), nil
-			}
-		} else {
-			alias = &identity.Alias{}
-		}
-
 		// Get entity id
 		canonicalID := d.Get("canonical_id").(string)
 		if canonicalID == "" {
@@ -131,157 +129,276 @@ func (i *IdentityStore) handleAliasUpdateCommon() framework.OperationFunc {
 			canonicalID = d.Get("entity_id").(string)
 		}
 
-		// Get alias name
-		if aliasName := d.Get("name").(string); aliasName == "" {
-			if alias.Name == "" {
-				return logical.ErrorResponse("missing alias name"), nil
-			}
-		} else {
-			alias.Name = aliasName
+		return i.handleAliasUpdate(ctx, d.Get("id").(string), canonicalID, d.Get("name").(string), d.Get("mount_accessor").(string))
+	}
+}
+
+// handleAliasUpdate creates the alias of the given name and mount, or updates
+// the alias of the given ID, tying it to the entity of the given canonical ID,
+// or to a new entity if empty. The caller must hold the identity store lock.
+func (i *IdentityStore) handleAliasUpdate(ctx context.Context, aliasID, canonicalID, aliasName, mountAccessor string) (*logical.Response, error) {
+	var err error
+	var alias *identity.Alias
+	var entity *identity.Entity
+	var previousEntity *identity.Entity
+
+	// Check for update or create
+	if aliasID != "" {
+		alias, err = i.MemDBAliasByID(aliasID, true, false)
+		if err != nil {
+			return nil, err
 		}
+		if alias == nil {
+			return logical.ErrorResponse("invalid alias id"), nil
+		}
+	} else {
+		alias = &identity.Alias{}
+	}
 
-		// Get mount accessor
-		if mountAccessor := d.Get("mount_accessor").(string); mountAccessor == "" {
-			if alias.MountAccessor == "" {
-				return logical.ErrorResponse("missing mount_accessor"), nil
-			}
-		} else {
-			alias.MountAccessor = mountAccessor
+	// Get alias name
+	if aliasName == "" {
+		if alias.Name == "" {
+			return logical.ErrorResponse("missing alias name"), nil
 		}
+	} else {
+		alias.Name = aliasName
+	}
 
-		mountValidationResp := i.core.router.validateMountByAccessor(alias.MountAccessor)
-		if mountValidationResp == nil {
-			return logical.ErrorResponse(fmt.Sprintf("invalid mount accessor %q", alias.MountAccessor)), nil
+	// Get mount accessor
+	if mountAccessor == "" {
+		if alias.MountAccessor == "" {
+			return logical.ErrorResponse("missing mount_accessor"), nil
 		}
-		if mountValidationResp.MountLocal {
-			return logical.ErrorResponse(fmt.Sprintf("mount_accessor %q is of a local mount", alias.MountAccessor)), nil
+	} else {
+		alias.MountAccessor = mountAccessor
+	}
+
+	mountValidationResp := i.core.router.validateMountByAccessor(alias.MountAccessor)
+	if mountValidationResp == nil {
+		return logical.ErrorResponse(fmt.Sprintf("invalid mount accessor %q", alias.MountAccessor)), nil
+	}
+	if mountValidationResp.MountLocal {
+		return logical.ErrorResponse(fmt.Sprintf("mount_accessor %q is of a local mount", alias.MountAccessor)), nil
+	}
+
+	// Verify that the combination of alias name and mount is not
+	// already tied to a different alias
+	aliasByFactors, err := i.MemDBAliasByFactors(mountValidationResp.MountAccessor, alias.Name, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if aliasByFactors != nil {
+		// If it's a create we won't have an alias ID so this will correctly
+		// bail. If it's an update alias will be the same as aliasbyfactors so
+		// we don't need to transfer any info over
+		if aliasByFactors.ID != alias.ID {
+			return logical.ErrorResponse("combination of mount and alias name is already in use"), nil
 		}
 
-		// Verify that the combination of alias name and mount is not
-		// already tied to a different alias
-		aliasByFactors, err := i.MemDBAliasByFactors(mountValidationResp.MountAccessor, alias.Name, false, false)
+		// Fetch the entity to which the alias is tied. We don't need to append
+		// here, so the only further checking is whether the canonical ID is
+		// different
+		entity, err = i.MemDBEntityByAliasID(alias.ID, true)
 		if err != nil {
 			return nil, err
 		}
-		if aliasByFactors != nil {
-			// If it's a create we won't have an alias ID so this will correctly
-			// bail. If it's an update alias will be the same as aliasbyfactors so
-			// we don't need to transfer any info over
-			if aliasByFactors.ID != alias.ID {
-				return logical.ErrorResponse("combination of mount and alias name is already in use"), nil
-			}
-
-			// Fetch the entity to which the alias is tied. We don't need to append
-			// here, so the only further checking is whether the canonical ID is
-			// different
-			entity, err = i.MemDBEntityByAliasID(alias.ID, true)
-			if err != nil {
-				return nil, err
-			}
-			if entity == nil {
-				return nil, fmt.Errorf("existing alias is not associated with an entity")
-			}
-		} else if alias.ID != "" {
-			// This is an update, not a create; if we have an associated entity
-			// already, load it
-			entity, err = i.MemDBEntityByAliasID(alias.ID, true)
-			if err != nil {
-				return nil, err
-			}
+		if entity == nil {
+			return nil, fmt.Errorf("existing alias is not associated with an entity")
+		}
+	} else if alias.ID != "" {
+		// This is an update, not a create; if we have an associated entity
+		// already, load it
+		entity, err = i.MemDBEntityByAliasID(alias.ID, true)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		resp := &logical.Response{}
+	resp := &logical.Response{}
 
-		// If we found an existing alias we won't hit this condition because
-		// canonicalID being empty will result in nil being returned in the block
-		// above, so in this case we know that creating a new entity is the right
-		// thing.
-		if canonicalID == "" {
-			entity = &identity.Entity{
-				Aliases: []*identity.Alias{
-					alias,
-				},
-			}
-		} else {
-			// If we can look up by the given canonical ID, see if this is a
-			// transfer; otherwise if we found no previous entity but we find one
-			// here, use it.
-			canonicalEntity, err := i.MemDBEntityByID(canonicalID, true)
-			if err != nil {
-				return nil, err
-			}
-			if canonicalEntity == nil {
-				return logical.ErrorResponse("invalid canonical ID"), nil
-			}
-			if entity == nil {
-				// If entity is nil, we didn't find a previous alias from factors,
-				// so append to this entity
-				entity = canonicalEntity
-				entity.Aliases = append(entity.Aliases, alias)
-			} else if entity.ID != canonicalEntity.ID {
-				// In this case we found an entity from alias factors or given
-				// alias ID but it's not the same, so it's a migration
-				previousEntity = entity
-				entity = canonicalEntity
-
-				for aliasIndex, item := range previousEntity.Aliases {
-					if item.ID == alias.ID {
-						previousEntity.Aliases = append(previousEntity.Aliases[:aliasIndex], previousEntity.Aliases[aliasIndex+1:]...)
-						break
-					}
+	// If we found an existing alias we won't hit this condition because
+	// canonicalID being empty will result in nil being returned in the block
+	// above, so in this case we know that creating a new entity is the right
+	// thing.
+	if canonicalID == "" {
+		entity = &identity.Entity{
+			Aliases: []*identity.Alias{
+				alias,
+			},
+		}
+	} else {
+		// If we can look up by the given canonical ID, see if this is a
+		// transfer; otherwise if we found no previous entity but we find one
+		// here, use it.
+		canonicalEntity, err := i.MemDBEntityByID(canonicalID, true)
+		if err != nil {
+			return nil, err
+		}
+		if canonicalEntity == nil {
+			return logical.ErrorResponse("invalid canonical ID"), nil
+		}
+		if entity == nil {
+			// If entity is nil, we didn't find a previous alias from factors,
+			// so append to this entity
+			entity = canonicalEntity
+			entity.Aliases = append(entity.Aliases, alias)
+		} else if entity.ID != canonicalEntity.ID {
+			// In this case we found an entity from alias factors or given
+			// alias ID but it's not the same, so it's a migration
+			previousEntity = entity
+			entity = canonicalEntity
+
+			for aliasIndex, item := range previousEntity.Aliases {
+				if item.ID == alias.ID {
+					previousEntity.Aliases = append(previousEntity.Aliases[:aliasIndex], previousEntity.Aliases[aliasIndex+1:]...)
+					break
 				}
-
-				entity.Aliases = append(entity.Aliases, alias)
-				resp.AddWarning(fmt.Sprintf("alias is being transferred from entity %q to %q", previousEntity.ID, entity.ID))
 			}
+
+			entity.Aliases = append(entity.Aliases, alias)
+			resp.AddWarning(fmt.Sprintf("alias is being transferred from entity %q to %q", previousEntity.ID, entity.ID))
 		}
+	}
 
-		// ID creation and other validations; This is more useful for new entities
-		// and may not perform anything for the existing entities. Placing the
-		// check here to make the flow common for both new and existing entities.
-		err = i.sanitizeEntity(ctx, entity)
-		if err != nil {
-			return nil, err
+	// ID creation and other validations; This is more useful for new entities
+	// and may not perform anything for the existing entities. Placing the
+	// check here to make the flow common for both new and existing entities.
+	err = i.sanitizeEntity(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	// Explicitly set to empty as in the past we incorrectly saved it
+	alias.MountPath = ""
+	alias.MountType = ""
+
+	// Set the canonical ID in the alias index. This should be done after
+	// sanitizing entity.
+	alias.CanonicalID = entity.ID
+
+	// ID creation and other validations
+	err = i.sanitizeAlias(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, item := range entity.Aliases {
+		if item.ID == alias.ID {
+			entity.Aliases[index] = alias
 		}
+	}
 
-		// Explicitly set to empty as in the past we incorrectly saved it
-		alias.MountPath = ""
-		alias.MountType = ""
+	// Index entity and its aliases in MemDB and persist entity along with
+	// aliases in storage. If the alias is being transferred over from
+	// one entity to another, previous entity needs to get refreshed in MemDB
+	// and persisted in storage as well.
+	if err := i.upsertEntity(ctx, entity, previousEntity, true); err != nil {
+		return nil, err
+	}
 
-		// Set the canonical ID in the alias index. This should be done after
-		// sanitizing entity.
-		alias.CanonicalID = entity.ID
+	// Return ID of both alias and entity
+	resp.Data = map[string]interface{}{
+		"id":           alias.ID,
+		"canonical_id": entity.ID,
+	}
 
-		// ID creation and other validations
-		err = i.sanitizeAlias(ctx, alias)
-		if err != nil {
-			return nil, err
+	return resp, nil
+}
+
+// maxAliasBatchSize is the maximum number of aliases of the batch import and
+// lookup requests
+const maxAliasBatchSize = 1000
+
+// aliasBatchEntry is an alias of the batch import and lookup requests
+type aliasBatchEntry struct {
+	Name          string `mapstructure:"name"`
+	MountAccessor string `mapstructure:"mount_accessor"`
+	CanonicalID   string `mapstructure:"canonical_id"`
+}
+
+// parseAliasBatch returns the aliases of the 'aliases' field of batch requests
+func parseAliasBatch(d *framework.FieldData) ([]interface{}, *logical.Response) {
+	aliasesRaw, ok := d.GetOk("aliases")
+	if !ok {
+		return nil, logical.ErrorResponse("missing aliases")
+	}
+	aliases := aliasesRaw.([]interface{})
+	if len(aliases) == 0 {
+		return nil, logical.ErrorResponse("missing aliases")
+	}
+	if len(aliases) > maxAliasBatchSize {
+		return nil, logical.ErrorResponse(fmt.Sprintf("too many aliases, at most %d can be given at once", maxAliasBatchSize))
+	}
+	return aliases, nil
+}
+
+// pathAliasImport creates the given aliases one after the other, reporting
+// the result of each so that some failing do not prevent importing the rest
+func (i *IdentityStore) pathAliasImport() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		aliases, errResp := parseAliasBatch(d)
+		if errResp != nil {
+			return errResp, nil
 		}
 
-		for index, item := range entity.Aliases {
-			if item.ID == alias.ID {
-				entity.Aliases[index] = alias
+		results := make([]map[string]interface{}, 0, len(aliases))
+		failed := 0
+		for index, item := range aliases {
+			result := map[string]interface{}{
+				"index": index,
 			}
-		}
+			results = append(results, result)
 
-		// Index entity and its aliases in MemDB and persist entity along with
-		// aliases in storage. If the alias is being transferred over from
-		// one entity to another, previous entity needs to get refreshed in MemDB
-		// and persisted in storage as well.
-		if err := i.upsertEntity(ctx, entity, previousEntity, true); err != nil {
-			return nil, err
-		}
+			var entry aliasBatchEntry
+			if err := mapstructure.Decode(item, &entry); err != nil {
+				result["error"] = fmt.Sprintf("invalid alias: %v", err)
+				failed++
+				continue
+			}
+			result["name"] = entry.Name
+			result["mount_accessor"] = entry.MountAccessor
 
-		// Return ID of both alias and entity
-		resp.Data = map[string]interface{}{
-			"id":           alias.ID,
-			"canonical_id": entity.ID,
+			id, canonicalID, err := i.importAlias(ctx, entry)
+			if err != nil {
+				result["error"] = err.Error()
+				failed++
+				continue
+			}
+			result["id"] = id
+			result["canonical_id"] = canonicalID
 		}
 
+		resp := &logical.Response{
+			Data: map[string]interface{}{
+				"aliases":  results,
+				"imported": len(aliases) - failed,
+				"failed":   failed,
+			},
+		}
+		if failed > 0 {
+			resp.AddWarning(fmt.Sprintf("%d of %d aliases failed to import", failed, len(aliases)))
+		}
 		return resp, nil
 	}
 }
 
+// importAlias creates the given alias and returns its ID and the ID of its
+// entity. The lock is only held for each alias, to not block logins during
+// large imports.
+func (i *IdentityStore) importAlias(ctx context.Context, entry aliasBatchEntry) (string, string, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	resp, err := i.handleAliasUpdate(ctx, "", entry.CanonicalID, entry.Name, entry.MountAccessor)
+	if err != nil {
+		return "", "", err
+	}
+	if resp.IsError() {
+		return "", "", resp.Error()
+	}
+	return resp.Data["id"].(string), resp.Data["canonical_id"].(string), nil
+}
+
 // pathAliasIDRead returns the properties of an alias for a given
 // alias ID
 func (i *IdentityStore) pathAliasIDRead() framework.OperationFunc {
@@ -440,4 +557,11 @@ var aliasHelp = map[string][2]string{
 		"List all the alias IDs.",
 		"",
 	},
+	"alias-import": {
+		"Create aliases in bulk.",
+		`Creates each of the given aliases, e.g. when migrating from another
+identity system, and reports the ID of each created alias along with the ID of
+its entity, or the error that prevented creating it. Aliases are never
+transferred from one entity to another.`,
+	},
 }
@@ -556,3 +556,76 @@ func TestIdentityStore_AliasReadDelete(t *testing.T) {
 		t.Fatalf("bad: alias read response; expected: nil, actual: %#v\n", resp)
 	}
 }
+
+func TestIdentityStore_AliasImport(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, githubAccessor, _ := testIdentityStoreWithGithubAuth(ctx, t)
+
+	resp, err := is.HandleRequest(ctx, &logical.Request{
+		Path:      "entity",
+		Operation: logical.UpdateOperation,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	entityID := resp.Data["id"].(string)
+
+	resp, err = is.HandleRequest(ctx, &logical.Request{
+		Path:      "entity-alias/import",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"aliases": []interface{}{
+				map[string]interface{}{"name": "alice", "mount_accessor": githubAccessor},
+				map[string]interface{}{"name": "bob", "mount_accessor": githubAccessor, "canonical_id": entityID},
+				// Already imported above
+				map[string]interface{}{"name": "alice", "mount_accessor": githubAccessor},
+				map[string]interface{}{"name": "carol", "mount_accessor": "invalid"},
+				map[string]interface{}{"name": "dave", "mount_accessor": githubAccessor, "canonical_id": "invalid"},
+				map[string]interface{}{"mount_accessor": githubAccessor},
+				"eve",
+			},
+		},
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["imported"].(int) != 2 || resp.Data["failed"].(int) != 5 || len(resp.Warnings) != 1 {
+		t.Fatalf("bad: resp:%#v", resp)
+	}
+
+	results := resp.Data["aliases"].([]map[string]interface{})
+	for index, result := range results {
+		if result["index"].(int) != index {
+			t.Fatalf("bad index of result %d: %#v", index, result)
+		}
+		_, failed := result["error"]
+		if failed != (index >= 2) {
+			t.Fatalf("bad result %d: %#v", index, result)
+		}
+	}
+
+	// The imported aliases are tied to a new entity and the given one
+	alias, err := is.MemDBAliasByID(results[0]["id"].(string), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alias == nil || alias.Name != "alice" || alias.CanonicalID != results[0]["canonical_id"] || alias.CanonicalID == entityID {
+		t.Fatalf("bad alias: %#v", alias)
+	}
+	entity, err := is.MemDBEntityByID(entityID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entity.Aliases) != 1 || entity.Aliases[0].ID != results[1]["id"] || results[1]["canonical_id"] != entityID {
+		t.Fatalf("bad entity aliases: %#v", entity.Aliases)
+	}
+
+	resp, err = is.HandleRequest(ctx, &logical.Request{
+		Path:      "entity-alias/import",
+		Operation: logical.UpdateOperation,
+		Data:      map[string]interface{}{},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error without aliases; err:%v resp:%#v", err, resp)
+	}
+}
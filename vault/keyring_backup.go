@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+const (
+	// keyringBackupVersion is the version of the keyring backup format
+	keyringBackupVersion = 1
+)
+
+// keyringBackupPaths are the storage entries making up the barrier keyring
+// and its root of trust. All of them are stored either encrypted or, in the
+// case of the seal configurations, without any secret material, so a backup
+// is only usable together with the unseal keys or the seal it was taken with.
+var keyringBackupPaths = []string{
+	keyringPath,
+	masterKeyPath,
+	barrierSealConfigPath,
+	StoredBarrierKeysPath,
+	recoverySealConfigPlaintextPath,
+	recoveryKeyPath,
+}
+
+// KeyringBackup is a copy of the barrier keyring and the root of trust
+// protecting it, as found in the storage backend.
+type KeyringBackup struct {
+	Version  int               `json:"version"`
+	SealType string            `json:"seal_type"`
+	Term     int               `json:"term"`
+	Entries  map[string][]byte `json:"entries"`
+}
+
+// BackupKeyring returns a backup of the barrier keyring. The backup only
+// covers the encryption keys installed at the time it is taken, so it must be
+// refreshed after every rotation or rekey. It is expected to be called on an
+// unsealed, active node.
+func (c *Core) BackupKeyring(ctx context.Context) (*KeyringBackup, error) {
+	info, err := c.barrier.ActiveKeyInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &KeyringBackup{
+		Version:  keyringBackupVersion,
+		SealType: c.seal.BarrierType(),
+		Term:     info.Term,
+		Entries:  make(map[string][]byte, len(keyringBackupPaths)),
+	}
+	for _, path := range keyringBackupPaths {
+		pe, err := c.physical.Get(ctx, path)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to read %q: {{err}}", path), err)
+		}
+		if pe == nil {
+			continue
+		}
+		backup.Entries[path] = pe.Value
+	}
+
+	if _, ok := backup.Entries[keyringPath]; !ok {
+		return nil, fmt.Errorf("keyring not found in storage")
+	}
+
+	return backup, nil
+}
+
+// RestoreKeyring writes the keyring backup into the storage backend. This is
+// only allowed when Vault is not yet initialized, and leaves it sealed; it
+// must then be unsealed using the keys or the seal the backup was taken with.
+func (c *Core) RestoreKeyring(ctx context.Context, backup *KeyringBackup) error {
+	if backup == nil {
+		return fmt.Errorf("no keyring backup provided")
+	}
+	if backup.Version != keyringBackupVersion {
+		return fmt.Errorf("unsupported keyring backup version %d", backup.Version)
+	}
+	if backup.SealType != c.seal.BarrierType() {
+		return fmt.Errorf("keyring backup seal type of %q does not match expected type of %q", backup.SealType, c.seal.BarrierType())
+	}
+	for _, path := range []string{keyringPath, barrierSealConfigPath} {
+		if len(backup.Entries[path]) == 0 {
+			return fmt.Errorf("keyring backup is missing %q", path)
+		}
+	}
+
+	allowed := make(map[string]bool, len(keyringBackupPaths))
+	for _, path := range keyringBackupPaths {
+		allowed[path] = true
+	}
+	for path := range backup.Entries {
+		if !allowed[path] {
+			return fmt.Errorf("keyring backup contains unexpected entry %q", path)
+		}
+	}
+
+	// Avoid an initialization race
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+
+	init, err := c.Initialized(ctx)
+	if err != nil {
+		c.logger.Error("keyring restore: failed to check initialization", "error", err)
+		return err
+	}
+	if init {
+		c.logger.Error("keyring restore: already initialized")
+		return ErrAlreadyInit
+	}
+
+	// The keyring is written last, as its presence is what marks the barrier
+	// as initialized
+	for i := len(keyringBackupPaths) - 1; i >= 0; i-- {
+		path := keyringBackupPaths[i]
+		value, ok := backup.Entries[path]
+		if !ok {
+			continue
+		}
+		if err := c.physical.Put(ctx, &physical.Entry{
+			Key:   path,
+			Value: value,
+		}); err != nil {
+			c.logger.Error("keyring restore: failed to write entry", "path", path, "error", err)
+			return errwrap.Wrapf(fmt.Sprintf("failed to write %q: {{err}}", path), err)
+		}
+	}
+
+	// Drop any cached seal configuration so it is read from the restored
+	// entries
+	if err := c.seal.SetBarrierConfig(ctx, nil); err != nil {
+		return err
+	}
+
+	c.logger.Info("keyring restored", "term", backup.Term)
+	return nil
+}
@@ -0,0 +1,218 @@
+package vault
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// kvWatchDefaultTimeout is how long a watch waits for a change by default
+	kvWatchDefaultTimeout = 30 * time.Second
+
+	// kvWatchMaxTimeout bounds how long a watch can wait, so that it returns
+	// well before the request is cancelled
+	kvWatchMaxTimeout = 60 * time.Second
+)
+
+// kvWatchPrefixes are the paths of a kv-v2 mount changing a secret, the
+// secret path following the prefix
+var kvWatchPrefixes = []string{"data/", "metadata/", "delete/", "undelete/", "destroy/"}
+
+type kvWatchEntry struct {
+	// changeCh is closed, and replaced, when the secret changes
+	changeCh chan struct{}
+
+	// watchers is the number of watches of the secret, the entry is dropped
+	// when it reaches zero
+	watchers int
+}
+
+// kvWatchStore wakes up the watches of kv-v2 secrets when the secrets change.
+// Secrets are keyed by the UUID of their mount and their path in the mount.
+// Writes are only handled by the active node, so keeping the watches in
+// memory is enough.
+type kvWatchStore struct {
+	sync.Mutex
+
+	entries map[string]*kvWatchEntry
+
+	// stopCh is closed, and replaced, to release all the watches when the
+	// node seals or steps down
+	stopCh chan struct{}
+}
+
+func newKVWatchStore() *kvWatchStore {
+	return &kvWatchStore{
+		entries: make(map[string]*kvWatchEntry),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func kvWatchKey(mountUUID, secretPath string) string {
+	return mountUUID + "/" + secretPath
+}
+
+// watch registers a watch of the secret. The first channel is closed when the
+// secret changes, the second when the watch has to be given up. unwatch must
+// be called once the watch is over.
+func (s *kvWatchStore) watch(key string) (<-chan struct{}, <-chan struct{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &kvWatchEntry{
+			changeCh: make(chan struct{}),
+		}
+		s.entries[key] = entry
+	}
+	entry.watchers++
+
+	return entry.changeCh, s.stopCh
+}
+
+func (s *kvWatchStore) unwatch(key string) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	entry.watchers--
+	if entry.watchers <= 0 {
+		delete(s.entries, key)
+	}
+}
+
+// notify wakes up the watches of the secret
+func (s *kvWatchStore) notify(key string) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	close(entry.changeCh)
+	entry.changeCh = make(chan struct{})
+}
+
+// stop releases all the watches. This is called before grabbing the state
+// lock to seal or step down, so that the watches return at once rather than
+// when they time out.
+func (s *kvWatchStore) stop() {
+	s.Lock()
+	defer s.Unlock()
+
+	close(s.stopCh)
+	s.stopCh = make(chan struct{})
+}
+
+func (s *kvWatchStore) empty() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.entries) == 0
+}
+
+// stateLockHeldKey is the context key marking that the request of the
+// context holds the read lock of the state
+type stateLockHeldKey struct{}
+
+// waitWithoutStateLock calls wait with the state lock released, if the request
+// of the context holds it, so that a request waiting for a change doesn't hold
+// up sealing or stepping down. The lock is taken again before returning, and
+// an error is returned if the node sealed or stepped down meanwhile, or if the
+// context is done. It must be called from the goroutine handling the request.
+func (c *Core) waitWithoutStateLock(ctx context.Context, wait func()) error {
+	if held, _ := ctx.Value(stateLockHeldKey{}).(bool); !held {
+		wait()
+		return ctx.Err()
+	}
+
+	c.stateLock.RUnlock()
+	wait()
+	c.stateLock.RLock()
+
+	switch {
+	case c.Sealed():
+		return consts.ErrSealed
+	case c.standby && !c.perfStandby:
+		return consts.ErrStandby
+	}
+	return ctx.Err()
+}
+
+// kvWatchSecretPath returns the path of the secret changed by a request to a
+// kv-v2 mount, relative to the mount, or false if the request path doesn't
+// change a secret
+func kvWatchSecretPath(relPath string) (string, bool) {
+	for _, prefix := range kvWatchPrefixes {
+		if strings.HasPrefix(relPath, prefix) && len(relPath) > len(prefix) {
+			return strings.TrimPrefix(relPath, prefix), true
+		}
+	}
+	return "", false
+}
+
+// isKVv2Mount returns whether the mount is a versioned key/value store
+func isKVv2Mount(entry *MountEntry) bool {
+	return entry != nil && entry.Type == "kv" && entry.Options["version"] == "2"
+}
+
+// notifyKVWatches wakes up the watches of the kv-v2 secret changed by a
+// successful request, if any
+func (c *Core) notifyKVWatches(ctx context.Context, req *logical.Request) {
+	switch req.Operation {
+	case logical.CreateOperation, logical.UpdateOperation, logical.DeleteOperation:
+	default:
+		return
+	}
+	if c.kvWatches.empty() {
+		return
+	}
+
+	entry := c.router.MatchingMountEntry(ctx, req.Path)
+	if !isKVv2Mount(entry) {
+		return
+	}
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return
+	}
+	relPath := strings.TrimPrefix(ns.Path+req.Path, c.router.MatchingMount(ctx, req.Path))
+	secretPath, ok := kvWatchSecretPath(relPath)
+	if !ok {
+		return
+	}
+
+	c.kvWatches.notify(kvWatchKey(entry.UUID, secretPath))
+}
+
+// kvCurrentVersion returns the current version of a kv-v2 secret, or zero if
+// the secret doesn't exist. mountPath is relative to the namespace of the
+// context.
+func (c *Core) kvCurrentVersion(ctx context.Context, mountPath, secretPath string) (int64, error) {
+	resp, err := c.router.Route(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      mountPath + "metadata/" + secretPath,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if resp == nil || resp.Data == nil {
+		return 0, nil
+	}
+	if resp.IsError() {
+		return 0, resp.Error()
+	}
+	return parseutil.ParseInt(resp.Data["current_version"])
+}
@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+func TestKVWatchStore(t *testing.T) {
+	s := newKVWatchStore()
+
+	changeCh, stopCh := s.watch("foo")
+	otherCh, _ := s.watch("bar")
+
+	s.notify("foo")
+	select {
+	case <-changeCh:
+	default:
+		t.Fatal("expected the watch of foo to be woken up")
+	}
+	select {
+	case <-otherCh:
+		t.Fatal("expected the watch of bar not to be woken up")
+	default:
+	}
+
+	// Watches after a change wait for the next one
+	nextCh, _ := s.watch("foo")
+	select {
+	case <-nextCh:
+		t.Fatal("expected the new watch of foo not to be woken up")
+	default:
+	}
+
+	s.stop()
+	select {
+	case <-stopCh:
+	default:
+		t.Fatal("expected the watches to be released")
+	}
+
+	s.unwatch("foo")
+	s.unwatch("bar")
+	if s.empty() {
+		t.Fatal("expected a watch of foo to remain")
+	}
+	s.unwatch("foo")
+	if !s.empty() {
+		t.Fatal("expected no watch to remain")
+	}
+}
+
+func TestKVWatchSecretPath(t *testing.T) {
+	for relPath, expected := range map[string]string{
+		"data/foo":         "foo",
+		"data/foo/bar":     "foo/bar",
+		"metadata/foo":     "foo",
+		"delete/foo":       "foo",
+		"undelete/foo":     "foo",
+		"destroy/foo":      "foo",
+		"config":           "",
+		"data/":            "",
+		"metadata/foo/bar": "foo/bar",
+	} {
+		secretPath, ok := kvWatchSecretPath(relPath)
+		if ok != (expected != "") || secretPath != expected {
+			t.Fatalf("bad: %q: expected %q, got %q", relPath, expected, secretPath)
+		}
+	}
+}
+
+func TestCore_waitWithoutStateLock(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := context.WithValue(namespace.RootContext(nil), stateLockHeldKey{}, true)
+
+	// The state lock can be grabbed while waiting
+	c.stateLock.RLock()
+	err := c.waitWithoutStateLock(ctx, func() {
+		c.stateLock.Lock()
+		c.stateLock.Unlock()
+	})
+	c.stateLock.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sealing meanwhile fails the request
+	c.stateLock.RLock()
+	err = c.waitWithoutStateLock(ctx, func() {
+		if err := c.Seal(root); err != nil {
+			t.Fatal(err)
+		}
+	})
+	c.stateLock.RUnlock()
+	if err != consts.ErrSealed {
+		t.Fatalf("expected %v, got %v", consts.ErrSealed, err)
+	}
+
+	// Without the state lock held, only the context is checked
+	if err := c.waitWithoutStateLock(namespace.RootContext(nil), func() {}); err != nil {
+		t.Fatal(err)
+	}
+	cancelCtx, cancel := context.WithCancel(namespace.RootContext(nil))
+	cancel()
+	if err := c.waitWithoutStateLock(cancelCtx, func() {}); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -5,16 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
+// cubbyholeExpiringEntryVersion marks stored values that carry an expiration
+// time. Values without an expiration are stored as their plain JSON object,
+// which can never start with this byte.
+const cubbyholeExpiringEntryVersion = 0x01
+
+// cubbyholeExpiringEntry is the stored form of a value written with a TTL
+type cubbyholeExpiringEntry struct {
+	Data       map[string]interface{} `json:"data"`
+	ExpireTime time.Time              `json:"expire_time"`
+}
+
 // CubbyholeBackendFactory constructs a new cubbyhole backend
 func CubbyholeBackendFactory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
-	b := &CubbyholeBackend{}
+	b := &CubbyholeBackend{
+		locks: locksutil.CreateLocks(),
+	}
 	b.Backend = &framework.Backend{
 		Help: strings.TrimSpace(cubbyholeHelp),
 	}
@@ -38,6 +55,26 @@ type CubbyholeBackend struct {
 
 	saltUUID    string
 	storageView logical.Storage
+
+	configLock sync.RWMutex
+	config     CubbyholeConfig
+
+	// locks serialize the writes of each token, so that concurrent writes
+	// can't exceed its quota
+	locks []*locksutil.LockEntry
+}
+
+// setConfig applies the limits of the cubbyhole config
+func (b *CubbyholeBackend) setConfig(config CubbyholeConfig) {
+	b.configLock.Lock()
+	b.config = config
+	b.configLock.Unlock()
+}
+
+func (b *CubbyholeBackend) getConfig() CubbyholeConfig {
+	b.configLock.RLock()
+	defer b.configLock.RUnlock()
+	return b.config
 }
 
 func (b *CubbyholeBackend) paths() []*framework.Path {
@@ -123,9 +160,17 @@ func (b *CubbyholeBackend) handleRead(ctx context.Context, req *logical.Request,
 	}
 
 	// Decode the data
-	var rawData map[string]interface{}
-	if err := jsonutil.DecodeJSON(out.Value, &rawData); err != nil {
-		return nil, errwrap.Wrapf("json decoding failed: {{err}}", err)
+	rawData, expireTime, err := decodeCubbyholeEntry(out.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expired values are removed when they are next accessed
+	if !expireTime.IsZero() && !time.Now().Before(expireTime) {
+		if err := req.Storage.Delete(ctx, out.Key); err != nil {
+			return nil, errwrap.Wrapf("failed to remove expired value: {{err}}", err)
+		}
+		return nil, nil
 	}
 
 	// Generate the response
@@ -147,8 +192,45 @@ func (b *CubbyholeBackend) handleWrite(ctx context.Context, req *logical.Request
 
 	path := data.Get("path").(string)
 
+	config := b.getConfig()
+	if cubbyholeLimitsExempt(ctx) {
+		config = CubbyholeConfig{}
+	}
+
+	var resp *logical.Response
+	var ttl time.Duration
+	if ttlRaw, ok := req.Data["ttl"]; ok {
+		var err error
+		ttl, err = parseutil.ParseDurationSecond(ttlRaw)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("failed to parse ttl: %v", err)), logical.ErrInvalidRequest
+		}
+		if ttl < 0 {
+			return logical.ErrorResponse("ttl must not be negative"), logical.ErrInvalidRequest
+		}
+	}
+	if config.MaxEntryTTL > 0 {
+		if ttl > config.MaxEntryTTL {
+			resp = &logical.Response{}
+			resp.AddWarning(fmt.Sprintf("ttl of %s is greater than the max entry TTL of the mount; capping to %s", ttl, config.MaxEntryTTL))
+		}
+		if ttl == 0 || ttl > config.MaxEntryTTL {
+			ttl = config.MaxEntryTTL
+		}
+	}
+
 	// JSON encode the data
-	buf, err := json.Marshal(req.Data)
+	var buf []byte
+	var err error
+	if ttl > 0 {
+		buf, err = json.Marshal(&cubbyholeExpiringEntry{
+			Data:       req.Data,
+			ExpireTime: time.Now().Add(ttl).UTC(),
+		})
+		buf = append([]byte{cubbyholeExpiringEntryVersion}, buf...)
+	} else {
+		buf, err = json.Marshal(req.Data)
+	}
 	if err != nil {
 		return nil, errwrap.Wrapf("json encoding failed: {{err}}", err)
 	}
@@ -161,11 +243,96 @@ func (b *CubbyholeBackend) handleWrite(ctx context.Context, req *logical.Request
 	if req.WrapInfo != nil && req.WrapInfo.SealWrap {
 		entry.SealWrap = true
 	}
+
+	if config.quotaEnabled() {
+		lock := locksutil.LockForKey(b.locks, req.ClientToken)
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err := b.checkQuota(ctx, req, config, path, int64(len(buf))); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+	}
+
 	if err := req.Storage.Put(ctx, entry); err != nil {
 		return nil, errwrap.Wrapf("failed to write: {{err}}", err)
 	}
 
-	return nil, nil
+	return resp, nil
+}
+
+// checkQuota returns an error if storing a value of the given size at the
+// path would exceed the quota of the token. Expired values found along the
+// way are removed and don't count against the quota. The token's lock must
+// be held.
+func (b *CubbyholeBackend) checkQuota(ctx context.Context, req *logical.Request, config CubbyholeConfig, path string, size int64) error {
+	view := logical.NewStorageView(req.Storage, req.ClientToken+"/")
+	now := time.Now()
+
+	var keys []string
+	if err := logical.ScanView(ctx, view, func(key string) {
+		keys = append(keys, key)
+	}); err != nil {
+		return err
+	}
+
+	var usedBytes int64
+	var usedEntries int
+	for _, key := range keys {
+		// The value being overwritten is replaced by the new one
+		if key == path {
+			continue
+		}
+
+		out, err := view.Get(ctx, key)
+		if err != nil {
+			return errwrap.Wrapf("read failed: {{err}}", err)
+		}
+		if out == nil {
+			continue
+		}
+
+		_, expireTime, err := decodeCubbyholeEntry(out.Value)
+		if err != nil {
+			return err
+		}
+		if !expireTime.IsZero() && !now.Before(expireTime) {
+			if err := view.Delete(ctx, key); err != nil {
+				return errwrap.Wrapf("failed to remove expired value: {{err}}", err)
+			}
+			continue
+		}
+
+		usedBytes += int64(len(out.Value))
+		usedEntries++
+	}
+
+	if config.MaxTokenEntries > 0 && usedEntries+1 > config.MaxTokenEntries {
+		return fmt.Errorf("cubbyhole quota exceeded: the token can store at most %d values", config.MaxTokenEntries)
+	}
+	if config.MaxTokenBytes > 0 && usedBytes+size > config.MaxTokenBytes {
+		return fmt.Errorf("cubbyhole quota exceeded: storing %d bytes would exceed the limit of %d bytes for the token, with %d bytes in use", size, config.MaxTokenBytes, usedBytes)
+	}
+
+	return nil
+}
+
+// decodeCubbyholeEntry decodes a stored value, returning its expiration time
+// if it was written with a TTL
+func decodeCubbyholeEntry(value []byte) (map[string]interface{}, time.Time, error) {
+	if len(value) > 0 && value[0] == cubbyholeExpiringEntryVersion {
+		var entry cubbyholeExpiringEntry
+		if err := jsonutil.DecodeJSON(value[1:], &entry); err != nil {
+			return nil, time.Time{}, errwrap.Wrapf("json decoding failed: {{err}}", err)
+		}
+		return entry.Data, entry.ExpireTime, nil
+	}
+
+	var rawData map[string]interface{}
+	if err := jsonutil.DecodeJSON(value, &rawData); err != nil {
+		return nil, time.Time{}, errwrap.Wrapf("json decoding failed: {{err}}", err)
+	}
+	return rawData, time.Time{}, nil
 }
 
 func (b *CubbyholeBackend) handleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -224,6 +391,9 @@ sharing possible (per-token cubbyholes). This can be useful for implementing
 certain authentication workflows, as well as "scratch" areas for individual
 clients. When the token is revoked, the entire set of stored values for that
 token is also removed.
+
+The size and number of values each token can store, and how long values are
+kept, can be limited with sys/config/cubbyhole.
 `
 
 const cubbyholeHelpSynopsis = `
@@ -237,4 +407,7 @@ encrypting it along the way.
 
 The view into the cubbyhole storage space is different for each token; it is
 a per-token cubbyhole. When the token is revoked all values are removed.
+
+If the written data contains a "ttl" key, the value is removed once the TTL
+has passed, even if the token is still valid.
 `
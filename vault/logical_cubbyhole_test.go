@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -265,3 +266,135 @@ func testCubbyholeBackend() logical.Backend {
 	})
 	return b
 }
+
+func TestCubbyholeBackend_TTL(t *testing.T) {
+	b := testCubbyholeBackend()
+	req := logical.TestRequest(t, logical.UpdateOperation, "foo")
+	req.Data["raw"] = "test"
+	req.Data["ttl"] = "1s"
+	storage := req.Storage
+	clientToken, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ClientToken = clientToken
+
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "foo")
+	req.Storage = storage
+	req.ClientToken = clientToken
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := &logical.Response{
+		Data: map[string]interface{}{
+			"raw": "test",
+			"ttl": "1s",
+		},
+	}
+	if !reflect.DeepEqual(resp, expected) {
+		t.Fatalf("bad response.\n\nexpected: %#v\n\nGot: %#v", expected, resp)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != nil {
+		t.Fatalf("bad: %v", resp)
+	}
+
+	// The expired value is removed
+	keys, err := storage.List(context.Background(), clientToken+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("expected expired value to be removed, got %v", keys)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "foo")
+	req.Storage = storage
+	req.ClientToken = clientToken
+	req.Data["ttl"] = "bogus"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != logical.ErrInvalidRequest || resp == nil || !resp.IsError() {
+		t.Fatalf("expected invalid ttl to be rejected, got err: %v, resp: %#v", err, resp)
+	}
+}
+
+func TestCubbyholeBackend_Quota(t *testing.T) {
+	b := testCubbyholeBackend()
+	b.(*CubbyholeBackend).setConfig(CubbyholeConfig{
+		MaxTokenEntries: 2,
+		MaxTokenBytes:   200,
+		MaxEntryTTL:     time.Hour,
+	})
+	storage := &logical.InmemStorage{}
+	clientToken, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Storage = storage
+		req.ClientToken = clientToken
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	for _, path := range []string{"foo", "bar"} {
+		resp, err := write(path, map[string]interface{}{"raw": "x"})
+		if err != nil || resp != nil {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+	}
+
+	// Only two values are allowed, but overwriting one is fine
+	resp, err := write("baz", map[string]interface{}{"raw": "x"})
+	if err != logical.ErrInvalidRequest || resp == nil || !resp.IsError() {
+		t.Fatalf("expected entry quota to be enforced, got err: %v, resp: %#v", err, resp)
+	}
+	if resp, err := write("foo", map[string]interface{}{"raw": "y"}); err != nil || resp != nil {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	// Values are capped by the max entry TTL, which counts towards the size
+	// of the value
+	resp, err = write("foo", map[string]interface{}{"raw": strings.Repeat("x", 200)})
+	if err != logical.ErrInvalidRequest || resp == nil || !resp.IsError() {
+		t.Fatalf("expected byte quota to be enforced, got err: %v, resp: %#v", err, resp)
+	}
+
+	resp, err = write("foo", map[string]interface{}{"raw": "x", "ttl": "2h"})
+	if err != nil || resp == nil || len(resp.Warnings) != 1 {
+		t.Fatalf("expected ttl to be capped with a warning, got err: %v, resp: %#v", err, resp)
+	}
+	entry, err := storage.Get(context.Background(), clientToken+"/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, expireTime, err := decodeCubbyholeEntry(entry.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(expireTime); remaining <= 0 || remaining > time.Hour {
+		t.Fatalf("bad expiration: %v", expireTime)
+	}
+
+	// Writes of wrapped responses aren't limited
+	req := logical.TestRequest(t, logical.UpdateOperation, "baz")
+	req.Storage = storage
+	req.ClientToken = clientToken
+	req.Data["raw"] = strings.Repeat("x", 200)
+	if resp, err := b.HandleRequest(withoutCubbyholeLimits(context.Background()), req); err != nil || resp != nil {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+}
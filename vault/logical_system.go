@@ -22,6 +22,7 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	memdb "github.com/hashicorp/go-memdb"
 	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/hostutil"
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
@@ -95,15 +96,27 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"replication/dr/reindex",
 				"replication/performance/reindex",
 				"rotate",
+				"keyring/backup",
+				"host-info",
+				"features/*",
 				"config/cors",
+				"config/cubbyhole",
+				"config/state/*",
 				"config/auditing/*",
+				"config/rate-limits",
+				"config/rate-limits/*",
 				"config/ui/headers/*",
+				"managed-keys/*",
 				"plugins/catalog/*",
 				"revoke-prefix/*",
 				"revoke-force/*",
 				"leases/revoke-prefix/*",
 				"leases/revoke-force/*",
 				"leases/lookup/*",
+				"wrapping/tokens*",
+				"wrapping/accessors/*",
+				"internal/cache/flush",
+				"internal/cache/flush/*",
 			},
 
 			Unauthenticated: []string{
@@ -160,6 +173,12 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 	b.Backend.Paths = append(b.Backend.Paths, b.internalPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.remountPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.metricsPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.hostInfoPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.featurePaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.rateLimitPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.managedKeyPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.kvWatchPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.cubbyholeConfigPath())
 
 	if core.rawEnabled {
 		b.Backend.Paths = append(b.Backend.Paths, &framework.Path{
@@ -198,7 +217,15 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 		})
 	}
 
-	b.Backend.Invalidate = sysInvalidate(b)
+	invalidate := sysInvalidate(b)
+	b.Backend.Invalidate = func(ctx context.Context, key string) {
+		if strings.HasPrefix(key, managedKeysPath) {
+			b.Core.invalidateManagedKey(strings.TrimPrefix(key, managedKeysPath))
+		}
+		if invalidate != nil {
+			invalidate(ctx, key)
+		}
+	}
 	return b
 }
 
@@ -236,6 +263,18 @@ func (b *SystemBackend) handleCORSRead(ctx context.Context, req *logical.Request
 	return resp, nil
 }
 
+// handleConfigStateSanitized returns the sanitized server configuration
+func (b *SystemBackend) handleConfigStateSanitized(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	conf := b.Core.SanitizedConfig()
+	if conf == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: conf,
+	}, nil
+}
+
 // handleCORSUpdate sets the list of origins that are allowed to make
 // cross-origin requests and sets the CORS enabled flag to true
 func (b *SystemBackend) handleCORSUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -2291,9 +2330,30 @@ func (b *SystemBackend) handleRawWrite(ctx context.Context, req *logical.Request
 	if err := b.Core.barrier.Put(ctx, entry); err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
 	}
+
+	b.invalidateRawKey(ctx, path)
 	return nil, nil
 }
 
+// invalidateRawKey notifies the backend owning a key written or deleted
+// through sys/raw, so that it drops what it cached for the key. The keys of
+// the system backend itself are left alone, as its route entry is held by the
+// raw request being served, except for the cached managed keys, which are
+// dropped directly.
+func (b *SystemBackend) invalidateRawKey(ctx context.Context, path string) {
+	_, _, prefix, found := b.Core.router.MatchingAPIPrefixByStoragePath(ctx, path)
+	if !found {
+		return
+	}
+	if prefix == systemBarrierPrefix {
+		if strings.HasPrefix(path, systemBarrierPrefix+managedKeysPath) {
+			b.Core.invalidateManagedKey(strings.TrimPrefix(path, systemBarrierPrefix+managedKeysPath))
+		}
+		return
+	}
+	b.Core.router.InvalidateStorageKey(ctx, path)
+}
+
 // handleRawDelete is used to delete directly from the barrier
 func (b *SystemBackend) handleRawDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	path := data.Get("path").(string)
@@ -2309,6 +2369,8 @@ func (b *SystemBackend) handleRawDelete(ctx context.Context, req *logical.Reques
 	if err := b.Core.barrier.Delete(ctx, path); err != nil {
 		return handleErrorNoReadOnlyForward(err)
 	}
+
+	b.invalidateRawKey(ctx, path)
 	return nil, nil
 }
 
@@ -2357,6 +2419,29 @@ func (b *SystemBackend) handleKeyStatus(ctx context.Context, req *logical.Reques
 	return resp, nil
 }
 
+// handleKeyringBackup returns a backup of the barrier keyring and its root
+// of trust, which can be restored into a new storage backend
+func (b *SystemBackend) handleKeyringBackup(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	backup, err := b.Core.BackupKeyring(ctx)
+	if err != nil {
+		b.Backend.Logger().Error("failed to back up keyring", "error", err)
+		return handleError(err)
+	}
+
+	buf, err := json.Marshal(backup)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to encode keyring backup: {{err}}", err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"term":   backup.Term,
+			"backup": base64.StdEncoding.EncodeToString(buf),
+		},
+	}
+	return resp, nil
+}
+
 // handleRotate is used to trigger a key rotation
 func (b *SystemBackend) handleRotate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	repState := b.Core.ReplicationState()
@@ -2598,6 +2683,401 @@ func (b *SystemBackend) handleMetrics(ctx context.Context, req *logical.Request,
 	return b.Core.metricsHelper.ResponseForFormat(format)
 }
 
+// handleFeaturesRead lists the registered feature flags and whether they are
+// turned on for this cluster
+func (b *SystemBackend) handleFeaturesRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	features := make(map[string]interface{})
+	for _, flag := range FeatureFlags() {
+		features[flag.Name] = b.featureResponseData(flag)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"features": features,
+		},
+	}, nil
+}
+
+// handleFeatureRead returns a single feature flag
+func (b *SystemBackend) handleFeatureRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	flag, ok := lookupFeatureFlag(data.Get("name").(string))
+	if !ok {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: b.featureResponseData(flag),
+	}, nil
+}
+
+// handleFeatureUpdate turns a feature flag on or off for this cluster
+func (b *SystemBackend) handleFeatureUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if _, ok := lookupFeatureFlag(name); !ok {
+		return logical.ErrorResponse(fmt.Sprintf("unknown feature %q", name)), logical.ErrInvalidRequest
+	}
+
+	enabledRaw, ok := data.GetOk("enabled")
+	if !ok {
+		return logical.ErrorResponse("missing \"enabled\" value"), logical.ErrInvalidRequest
+	}
+
+	if err := b.Core.SetFeatureEnabled(ctx, name, enabledRaw.(bool)); err != nil {
+		return handleError(err)
+	}
+	b.Backend.Logger().Info("feature flag updated", "name", name, "enabled", enabledRaw.(bool))
+
+	return nil, nil
+}
+
+// handleFeatureDelete resets a feature flag to its default
+func (b *SystemBackend) handleFeatureDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if err := b.Core.ResetFeature(ctx, name); err != nil {
+		return handleError(err)
+	}
+	b.Backend.Logger().Info("feature flag reset", "name", name)
+
+	return nil, nil
+}
+
+// handleRateLimitsRead lists the rate limit rules
+func (b *SystemBackend) handleRateLimitsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	rules := make(map[string]interface{})
+	for name, rule := range b.Core.RateLimitRules() {
+		rules[name] = rateLimitResponseData(rule)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"rules": rules,
+		},
+	}, nil
+}
+
+// handleRateLimitRead returns a single rate limit rule
+func (b *SystemBackend) handleRateLimitRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	rule, ok := b.Core.RateLimitRules()[data.Get("name").(string)]
+	if !ok {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: rateLimitResponseData(rule),
+	}, nil
+}
+
+// handleRateLimitUpdate creates or updates a rate limit rule
+func (b *SystemBackend) handleRateLimitUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	rule, ok := b.Core.RateLimitRules()[name]
+	if !ok {
+		rule = &RateLimitRule{
+			Name: name,
+		}
+	}
+	if pathRaw, ok := data.GetOk("path"); ok {
+		rule.Path = pathRaw.(string)
+	}
+	if rateRaw, ok := data.GetOk("rate"); ok {
+		rate, err := strconv.ParseFloat(rateRaw.(string), 64)
+		if err != nil {
+			return logical.ErrorResponse("rate must be a number of requests per second"), logical.ErrInvalidRequest
+		}
+		rule.Rate = rate
+	}
+	if burstRaw, ok := data.GetOk("burst"); ok {
+		rule.Burst = burstRaw.(int)
+	}
+
+	if err := validateRateLimitRule(rule); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if err := b.Core.SetRateLimitRule(ctx, rule); err != nil {
+		return handleError(err)
+	}
+
+	return nil, nil
+}
+
+// handleRateLimitDelete removes a rate limit rule
+func (b *SystemBackend) handleRateLimitDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := b.Core.DeleteRateLimitRule(ctx, data.Get("name").(string)); err != nil {
+		return handleError(err)
+	}
+
+	return nil, nil
+}
+
+// handleManagedKeysList lists the managed keys
+func (b *SystemBackend) handleManagedKeysList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	keys, err := b.Core.ListManagedKeys(ctx)
+	if err != nil {
+		return handleError(err)
+	}
+
+	return logical.ListResponse(keys), nil
+}
+
+// handleManagedKeyRead returns the config of a managed key
+func (b *SystemBackend) handleManagedKeyRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	entry, err := b.Core.ManagedKeyEntry(ctx, data.Get("name").(string))
+	if err != nil {
+		return handleError(err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	config := make(map[string]interface{}, len(entry.Config))
+	for k, v := range entry.Config {
+		// Secrets such as the PIN of PKCS#11 tokens aren't returned
+		if strutil.StrListContains(managedKeySensitiveConfig, k) {
+			continue
+		}
+		config[k] = v
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":           entry.Name,
+			"type":           entry.Type,
+			"config":         config,
+			"allowed_mounts": entry.AllowedMounts,
+		},
+	}, nil
+}
+
+// handleManagedKeyUpdate creates or updates a managed key config
+func (b *SystemBackend) handleManagedKeyUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	entry, err := b.Core.ManagedKeyEntry(ctx, name)
+	if err != nil {
+		return handleError(err)
+	}
+	if entry == nil {
+		entry = &ManagedKeyEntry{
+			Name: name,
+		}
+	}
+	if typeRaw, ok := data.GetOk("type"); ok {
+		if entry.Type != "" && entry.Type != typeRaw.(string) {
+			return logical.ErrorResponse("the type of a managed key can't be changed"), logical.ErrInvalidRequest
+		}
+		entry.Type = typeRaw.(string)
+	}
+	if configRaw, ok := data.GetOk("config"); ok {
+		entry.Config = configRaw.(map[string]string)
+	}
+	if mountsRaw, ok := data.GetOk("allowed_mounts"); ok {
+		entry.AllowedMounts = mountsRaw.([]string)
+	}
+
+	if err := b.Core.validateManagedKeyEntry(entry); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if err := b.Core.SetManagedKey(ctx, entry); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	return nil, nil
+}
+
+// handleManagedKeyDelete removes a managed key config
+func (b *SystemBackend) handleManagedKeyDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := b.Core.DeleteManagedKey(ctx, data.Get("name").(string)); err != nil {
+		return handleError(err)
+	}
+
+	return nil, nil
+}
+
+// handleKVWatch waits for a kv-v2 secret to change, and returns its current
+// version
+func (b *SystemBackend) handleKVWatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := strings.TrimPrefix(data.Get("path").(string), "/")
+	if path == "" {
+		return logical.ErrorResponse("path is required"), logical.ErrInvalidRequest
+	}
+	version := int64(data.Get("version").(int))
+	if version < 0 {
+		return logical.ErrorResponse("version can't be negative"), logical.ErrInvalidRequest
+	}
+	timeout := time.Duration(data.Get("timeout").(int)) * time.Second
+	if timeout <= 0 {
+		return logical.ErrorResponse("timeout must be positive"), logical.ErrInvalidRequest
+	}
+	if timeout > kvWatchMaxTimeout {
+		timeout = kvWatchMaxTimeout
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry := b.Core.router.MatchingMountEntry(ctx, path)
+	if !isKVv2Mount(entry) {
+		return logical.ErrorResponse(fmt.Sprintf("%q is not in a kv-v2 mount", path)), logical.ErrInvalidRequest
+	}
+	mountPath := strings.TrimPrefix(b.Core.router.MatchingMount(ctx, path), ns.Path)
+	secretPath := strings.TrimPrefix(path, mountPath+"data/")
+	if secretPath == path || secretPath == "" {
+		return logical.ErrorResponse(fmt.Sprintf("%q is not the data path of a secret, such as %q", path, mountPath+"data/foo")), logical.ErrInvalidRequest
+	}
+
+	// The token must be able to read the secret
+	acl, te, entity, _, err := b.Core.fetchACLTokenEntryAndEntity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if entity != nil && entity.Disabled {
+		b.logger.Warn("permission denied as the entity on the token is disabled")
+		return nil, logical.ErrPermissionDenied
+	}
+	if te != nil && te.EntityID != "" && entity == nil {
+		b.logger.Warn("permission denied as the entity on the token is invalid")
+		return nil, logical.ErrPermissionDenied
+	}
+	if !acl.AllowOperation(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      path,
+	}, false).Allowed {
+		return nil, logical.ErrPermissionDenied
+	}
+
+	// Watch before reading the version, so that a change in between isn't
+	// missed
+	key := kvWatchKey(entry.UUID, secretPath)
+	changeCh, stopCh := b.Core.kvWatches.watch(key)
+	defer b.Core.kvWatches.unwatch(key)
+
+	current, err := b.Core.kvCurrentVersion(ctx, mountPath, secretPath)
+	if err != nil {
+		return handleError(err)
+	}
+
+	changed := version != 0 && version != current
+	if !changed {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		err := b.Core.waitWithoutStateLock(ctx, func() {
+			select {
+			case <-changeCh:
+				changed = true
+			case <-timer.C:
+			case <-stopCh:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			current, err = b.Core.kvCurrentVersion(ctx, mountPath, secretPath)
+			if err != nil {
+				return handleError(err)
+			}
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"changed":         changed,
+			"current_version": current,
+		},
+	}, nil
+}
+
+// handleCubbyholeConfigRead returns the cubbyhole limits
+func (b *SystemBackend) handleCubbyholeConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config := b.Core.CubbyholeConfig()
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"max_token_bytes":   config.MaxTokenBytes,
+			"max_token_entries": config.MaxTokenEntries,
+			"max_entry_ttl":     int64(config.MaxEntryTTL.Seconds()),
+		},
+	}, nil
+}
+
+// handleCubbyholeConfigUpdate sets the cubbyhole limits. Limits that aren't
+// given are left unchanged.
+func (b *SystemBackend) handleCubbyholeConfigUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config := b.Core.CubbyholeConfig()
+	if raw, ok := data.GetOk("max_token_bytes"); ok {
+		config.MaxTokenBytes = int64(raw.(int))
+	}
+	if raw, ok := data.GetOk("max_token_entries"); ok {
+		config.MaxTokenEntries = raw.(int)
+	}
+	if raw, ok := data.GetOk("max_entry_ttl"); ok {
+		config.MaxEntryTTL = time.Duration(raw.(int)) * time.Second
+	}
+
+	if err := config.validate(); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if err := b.Core.SetCubbyholeConfig(ctx, config); err != nil {
+		return handleError(err)
+	}
+
+	return nil, nil
+}
+
+// handleCubbyholeConfigDelete removes the cubbyhole limits
+func (b *SystemBackend) handleCubbyholeConfigDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := b.Core.SetCubbyholeConfig(ctx, CubbyholeConfig{}); err != nil {
+		return handleError(err)
+	}
+
+	return nil, nil
+}
+
+func rateLimitResponseData(rule *RateLimitRule) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  rule.Name,
+		"path":  rule.Path,
+		"rate":  rule.Rate,
+		"burst": rule.Burst,
+	}
+}
+
+func (b *SystemBackend) featureResponseData(flag *FeatureFlag) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        flag.Name,
+		"description": flag.Description,
+		"default":     flag.Default,
+		"enabled":     b.Core.FeatureEnabled(flag.Name),
+	}
+}
+
+// handleHostInfo returns information about the host of the active node
+func (b *SystemBackend) handleHostInfo(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	info, err := hostutil.CollectHostInfo("/")
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to collect host information: {{err}}", err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"timestamp": info.Timestamp,
+			"host":      info.Host,
+			"cpu":       info.CPU,
+		},
+	}
+	if info.Memory != nil {
+		resp.Data["memory"] = info.Memory
+	}
+	if len(info.Disk) > 0 {
+		resp.Data["disk"] = info.Disk
+	}
+	return resp, nil
+}
+
 func (b *SystemBackend) handleWrappingLookup(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	// This ordering of lookups has been validated already in the wrapping
 	// validation func, we're just doing this for a safety check
@@ -2738,6 +3218,9 @@ func (b *SystemBackend) handleWrappingRewrap(ctx context.Context, req *logical.R
 	}
 	creationPath := creationPathRaw.(string)
 
+	// Not set for tokens created by previous Vault versions
+	creatorAccessor, _ := cubbyResp.Data["creator_accessor"].(string)
+
 	// Fetch the original response and return it as the data for the new response
 	cubbyReq = &logical.Request{
 		Operation:   logical.ReadOperation,
@@ -2765,10 +3248,11 @@ func (b *SystemBackend) handleWrappingRewrap(ctx context.Context, req *logical.R
 	}
 
 	// Return response in "response"; wrapping code will detect the rewrap and
-	// slot in instead of nesting
+	// slot in instead of nesting. The creator of the original token is kept.
 	return &logical.Response{
 		Data: map[string]interface{}{
-			"response": response,
+			"response":         response,
+			"creator_accessor": creatorAccessor,
 		},
 		WrapInfo: &wrapping.ResponseWrapInfo{
 			TTL:          time.Duration(creationTTL),
@@ -2777,6 +3261,220 @@ func (b *SystemBackend) handleWrappingRewrap(ctx context.Context, req *logical.R
 	}, nil
 }
 
+// handleCacheFlush flushes the caches of all the subsystems, or of the given
+// one, of the node handling the request
+func (b *SystemBackend) handleCacheFlush(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	subsystems := cacheSubsystems
+	if subsystem := data.Get("subsystem").(string); subsystem != "" {
+		if !strutil.StrListContains(cacheSubsystems, subsystem) {
+			return logical.ErrorResponse(fmt.Sprintf("unknown cache subsystem %q; must be one of %s", subsystem, strings.Join(cacheSubsystems, ", "))), logical.ErrInvalidRequest
+		}
+		subsystems = []string{subsystem}
+	}
+
+	b.Core.flushCaches(ctx, subsystems)
+	return nil, nil
+}
+
+// handleWrappingTokensList lists the accessors of the outstanding wrapping
+// tokens of the namespace, along with their wrapping information, optionally
+// filtered by creation path prefix
+func (b *SystemBackend) handleWrappingTokensList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	prefix := data.Get("prefix").(string)
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := b.Core.tokenStore
+	entries, err := ts.accessorView(ns).List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{}
+	keys := []string{}
+	keyInfo := map[string]interface{}{}
+	for _, entry := range entries {
+		aEntry, err := ts.lookupByAccessor(ctx, entry, true, false)
+		if err != nil {
+			resp.AddWarning(fmt.Sprintf("Found an accessor entry that could not be successfully decoded; associated error is %q", err.Error()))
+			continue
+		}
+		if aEntry.TokenID == "" || aEntry.NamespaceID != ns.ID {
+			continue
+		}
+
+		te, err := ts.Lookup(ctx, aEntry.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		if !isWrappingToken(te) {
+			continue
+		}
+
+		info, err := b.wrappingTokenInfo(ctx, te)
+		if err != nil {
+			resp.AddWarning(fmt.Sprintf("Could not read the wrapping information of the token with accessor %q; associated error is %q", aEntry.AccessorID, err.Error()))
+			continue
+		}
+		if info == nil {
+			continue
+		}
+		if creationPath, _ := info["creation_path"].(string); !strings.HasPrefix(creationPath, prefix) {
+			continue
+		}
+
+		keys = append(keys, aEntry.AccessorID)
+		keyInfo[aEntry.AccessorID] = info
+	}
+
+	listResp := logical.ListResponseWithInfo(keys, keyInfo)
+	listResp.Warnings = resp.Warnings
+	return listResp, nil
+}
+
+// handleWrappingAccessorRead returns the wrapping information of an
+// outstanding wrapping token, looked up by accessor
+func (b *SystemBackend) handleWrappingAccessorRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	te, err := b.lookupWrappingTokenByAccessor(ctx, data.Get("accessor").(string))
+	if err != nil {
+		return nil, err
+	}
+	if te == nil {
+		return nil, nil
+	}
+
+	info, err := b.wrappingTokenInfo(ctx, te)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return logical.ErrorResponse("no information found; wrapping token may be from a previous Vault version"), nil
+	}
+
+	return &logical.Response{
+		Data: info,
+	}, nil
+}
+
+// handleWrappingAccessorRevoke revokes an outstanding wrapping token, looked
+// up by accessor, so that the wrapped response can't be unwrapped anymore
+func (b *SystemBackend) handleWrappingAccessorRevoke(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	te, err := b.lookupWrappingTokenByAccessor(ctx, data.Get("accessor").(string))
+	if err != nil {
+		return nil, err
+	}
+	if te == nil {
+		return nil, nil
+	}
+
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, b.Core)
+	if err != nil {
+		return nil, err
+	}
+	if tokenNS == nil {
+		return nil, namespace.ErrNoNamespace
+	}
+
+	revokeCtx := namespace.ContextWithNamespace(b.Core.tokenStore.quitContext, tokenNS)
+	leaseID, err := b.Core.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Core.expiration.Revoke(revokeCtx, leaseID); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// lookupWrappingTokenByAccessor returns the wrapping token with the given
+// accessor, or nil if there is no such outstanding wrapping token
+func (b *SystemBackend) lookupWrappingTokenByAccessor(ctx context.Context, accessor string) (*logical.TokenEntry, error) {
+	if accessor == "" {
+		return nil, &logical.StatusBadRequest{Err: "missing accessor"}
+	}
+
+	aEntry, err := b.Core.tokenStore.lookupByAccessor(ctx, accessor, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if aEntry.TokenID == "" {
+		return nil, nil
+	}
+
+	te, err := b.Core.tokenStore.Lookup(ctx, aEntry.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !isWrappingToken(te) {
+		return nil, nil
+	}
+	return te, nil
+}
+
+// wrappingTokenInfo returns the wrapping information of an outstanding
+// wrapping token, as stored in its cubbyhole when it was created, along with
+// its remaining TTL. It returns nil if there is none, e.g. for tokens created
+// by previous Vault versions.
+func (b *SystemBackend) wrappingTokenInfo(ctx context.Context, te *logical.TokenEntry) (map[string]interface{}, error) {
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, b.Core)
+	if err != nil {
+		return nil, err
+	}
+	if tokenNS == nil {
+		return nil, namespace.ErrNoNamespace
+	}
+
+	cubbyReq := &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "cubbyhole/wrapinfo",
+		ClientToken: te.ID,
+	}
+	cubbyReq.SetTokenEntry(te)
+	cubbyResp, err := b.Core.router.Route(namespace.ContextWithNamespace(ctx, tokenNS), cubbyReq)
+	if err != nil {
+		return nil, errwrap.Wrapf("error looking up wrapping information: {{err}}", err)
+	}
+	if cubbyResp != nil && cubbyResp.IsError() {
+		return nil, cubbyResp.Error()
+	}
+	if cubbyResp == nil || cubbyResp.Data == nil {
+		return nil, nil
+	}
+
+	ttl := time.Until(time.Unix(te.CreationTime, 0).Add(te.TTL))
+	if ttl < 0 {
+		ttl = 0
+	}
+	info := map[string]interface{}{
+		"accessor":         te.Accessor,
+		"creation_path":    cubbyResp.Data["creation_path"],
+		"creation_time":    cubbyResp.Data["creation_time"],
+		"creator_accessor": cubbyResp.Data["creator_accessor"],
+		"ttl":              int64(ttl.Seconds()),
+	}
+	if creationTTLRaw := cubbyResp.Data["creation_ttl"]; creationTTLRaw != nil {
+		creationTTL, err := creationTTLRaw.(json.Number).Int64()
+		if err != nil {
+			return nil, errwrap.Wrapf("error reading creation_ttl value from wrapping information: {{err}}", err)
+		}
+		info["creation_ttl"] = time.Duration(creationTTL).Seconds()
+	}
+	if info["creator_accessor"] == nil {
+		info["creator_accessor"] = ""
+	}
+
+	return info, nil
+}
+
+// isWrappingToken returns whether te is a response-wrapping token
+func isWrappingToken(te *logical.TokenEntry) bool {
+	return te != nil && len(te.Policies) == 1 && te.Policies[0] == responseWrappingPolicyName
+}
+
 func (b *SystemBackend) pathHashWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	inputB64 := d.Get("input").(string)
 	format := d.Get("format").(string)
@@ -2830,6 +3528,10 @@ func (b *SystemBackend) pathHashWrite(ctx context.Context, req *logical.Request,
 	return resp, nil
 }
 
+// maxRandomBytes bounds the size of a sys/tools/random request, so that a
+// single request can't make the server allocate arbitrarily large buffers
+const maxRandomBytes = 128 * 1024
+
 func (b *SystemBackend) pathRandomWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	bytes := 0
 	var err error
@@ -2847,6 +3549,9 @@ func (b *SystemBackend) pathRandomWrite(ctx context.Context, req *logical.Reques
 	if bytes < 1 {
 		return logical.ErrorResponse(`"bytes" cannot be less than 1`), nil
 	}
+	if bytes > maxRandomBytes {
+		return logical.ErrorResponse(fmt.Sprintf(`"bytes" cannot be greater than %d`, maxRandomBytes)), nil
+	}
 
 	switch format {
 	case "hex":
@@ -3384,6 +4089,17 @@ This path responds to the following HTTP methods.
         Clears the CORS configuration and disables acceptance of CORS requests.
 		`,
 	},
+	"config/state": {
+		"Returns the server configuration currently in use.",
+		`
+This path responds to the following HTTP methods.
+
+    GET /sanitized
+        Returns the configuration loaded at startup or on the most recent
+        reload, with storage and seal configuration and telemetry
+        credentials omitted.
+		`,
+	},
 	"config/ui/headers": {
 		"Configures response headers that should be returned from the UI.",
 		`
@@ -3813,6 +4529,17 @@ Enable a new audit backend or disable an existing backend.
 		`,
 	},
 
+	"keyring-backup": {
+		"Exports a backup of the barrier keyring.",
+		`
+		Returns a backup of the barrier keyring and the root of trust protecting
+		it. The backup can be restored into an uninitialized storage backend
+		using the sys/keyring/restore endpoint, after which Vault is unsealed with
+		the same unseal keys or seal. Data written with encryption keys installed
+		after the backup was taken cannot be decrypted using it.
+		`,
+	},
+
 	"rekey_backup": {
 		"Allows fetching or deleting the backup of the rotated unseal keys.",
 		"",
@@ -3867,6 +4594,44 @@ it.`,
 		`Returns the creation TTL and creation time of a response-wrapped token.`,
 	},
 
+	"internal-cache-flush": {
+		"Flushes the internal caches of the node.",
+		`Flushes the internal caches of the node handling the request, so that
+		their content is read again from storage. This allows recovering from
+		suspected cache inconsistencies without restarting the node. Without a
+		subsystem, the caches of all the subsystems are flushed.`,
+	},
+
+	"internal-cache-flush-subsystem": {
+		"The subsystem whose caches to flush: physical, policy or token.",
+		"",
+	},
+
+	"wrapping-tokens": {
+		"Lists the outstanding wrapping tokens.",
+		`Lists the accessors of the outstanding response-wrapping tokens of the
+		namespace along with their creation path, creation time, creation TTL,
+		remaining TTL and the accessor of the token that created them. The tokens
+		may be filtered by creation path prefix, e.g. "sys/wrapping/tokens/pki/".`,
+	},
+
+	"wrapping-tokens-prefix": {
+		"The creation path prefix of the wrapping tokens to list.",
+		"",
+	},
+
+	"wrapping-accessors": {
+		"Looks up or revokes an outstanding wrapping token by its accessor.",
+		`Reading returns the same information as listing the wrapping tokens;
+		deleting revokes the wrapping token, so that the response it wraps can't be
+		unwrapped anymore.`,
+	},
+
+	"wrapping-accessor": {
+		"The accessor of the wrapping token.",
+		"",
+	},
+
 	"rewrap": {
 		"Rotates a response-wrapped token.",
 		`Rotates a response-wrapped token; the output is a new token with the same
@@ -4022,6 +4787,87 @@ This path responds to the following HTTP methods.
 		"Export the metrics aggregated for telemetry purpose.",
 		"",
 	},
+	"features": {
+		"Lists the experimental features of this build.",
+		`
+		Lists the registered feature flags, their defaults and whether they are
+		turned on for this cluster.
+		`,
+	},
+	"feature": {
+		"Turns an experimental feature on or off.",
+		`
+		Reads a feature flag, turns it on or off for this cluster, or resets it
+		to its default. Changes apply at runtime and persist across restarts.
+		`,
+	},
+	"rate-limits": {
+		"Lists the request rate limit rules.",
+		`
+		Lists the rules limiting the request rate of each client. A rule applies
+		to the paths under its path prefix; if several rules match a path, the one
+		with the longest prefix applies.
+		`,
+	},
+	"rate-limit": {
+		"Configures a request rate limit rule.",
+		`
+		Creates, reads, updates or deletes a rule limiting the request rate of
+		each client to the paths under a prefix. Clients are identified by their
+		entity, or by their token accessor if the token has no entity, or by their
+		address for requests without a token. Each client gets its own budget of
+		"rate" requests per second with bursts of up to "burst" requests; requests
+		over budget are rejected with a 429 status code and a Retry-After header.
+		Requests to sys/config/rate-limits are never limited.
+		`,
+	},
+	"managed-keys": {
+		"Lists the managed keys.",
+		`
+		Lists the names of the managed keys: private keys held by a KMS or HSM,
+		which backends reference by name to sign with them.
+		`,
+	},
+	"managed-key": {
+		"Configures a managed key.",
+		`
+		Creates, reads, updates or deletes the configuration of a private key held
+		by a KMS or HSM. The key material never leaves the device; backends such
+		as PKI reference the key by name and Vault asks the device to sign on
+		their behalf. Only the mounts listed in "allowed_mounts" can use the key.
+		The key is looked up when it's configured, so that a configuration not
+		locating a usable key is rejected. Deleting the configuration leaves the
+		key in the device.
+		`,
+	},
+	"kv-watch": {
+		"Waits for a kv-v2 secret to change.",
+		`
+		Waits up to "timeout" for the kv-v2 secret at "path" to be written,
+		deleted, undeleted, destroyed or to have its metadata changed, and returns
+		whether it changed and its current version. If "version" is set and
+		differs from the current version, returns at once, so that clients don't
+		miss changes made between two watches. The token must be able to read the
+		secret.
+		`,
+	},
+	"config/cubbyhole": {
+		"Configures the limits of the per-token cubbyholes.",
+		`
+		Reads, sets or removes the limits applied to the cubbyhole of each token:
+		the total size in bytes and the number of values a token can store, and
+		the longest a value is kept. Values written without a "ttl" get the max
+		entry TTL, and longer TTLs are capped to it. A limit of zero means no
+		limit. The cubbyholes of response wrapping tokens are not limited.
+		`,
+	},
+	"host-info": {
+		"Information about the host of the active node.",
+		`
+		Returns the CPU, memory and disk usage and the uptime of the host the
+		active node is running on.
+		`,
+	},
 	"internal-counters-requests": {
 		"Count of requests seen by this Vault cluster over time.",
 		"Count of requests seen by this Vault cluster over time. Not included in count: health checks, UI asset requests, requests forwarded from another cluster.",
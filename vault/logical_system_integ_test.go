@@ -732,6 +732,12 @@ func TestSystemBackend_InternalUIResultantACL(t *testing.T) {
 					"read",
 				},
 			},
+			"sys/kv-watch": map[string]interface{}{
+				"capabilities": []interface{}{
+					"read",
+					"update",
+				},
+			},
 			"sys/leases/lookup": map[string]interface{}{
 				"capabilities": []interface{}{
 					"update",
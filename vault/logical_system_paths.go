@@ -48,6 +48,20 @@ func (b *SystemBackend) configPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["config/cors"][1]),
 		},
 
+		{
+			Pattern: "config/state/sanitized$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleConfigStateSanitized,
+					Summary:  "Return a sanitized version of the Vault server configuration.",
+				},
+			},
+
+			HelpDescription: strings.TrimSpace(sysHelp["config/state"][0]),
+			HelpSynopsis:    strings.TrimSpace(sysHelp["config/state"][1]),
+		},
+
 		{
 			Pattern: "config/ui/headers/" + framework.GenericNameRegex("header"),
 
@@ -555,6 +569,17 @@ func (b *SystemBackend) sealPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["rotate"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["rotate"][1]),
 		},
+
+		{
+			Pattern: "keyring/backup$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.handleKeyringBackup,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["keyring-backup"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["keyring-backup"][1]),
+		},
 	}
 }
 
@@ -835,6 +860,23 @@ func (b *SystemBackend) internalPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-counters-requests"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["internal-counters-requests"][1]),
 		},
+		{
+			Pattern: "internal/cache/flush" + framework.OptionalParamRegex("subsystem"),
+			Fields: map[string]*framework.FieldSchema{
+				"subsystem": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["internal-cache-flush-subsystem"][0]),
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleCacheFlush,
+					Summary:  "Flush the internal caches of the node.",
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-cache-flush"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["internal-cache-flush"][1]),
+		},
 	}
 }
 
@@ -1130,6 +1172,213 @@ func (b *SystemBackend) metricsPath() *framework.Path {
 
 }
 
+func (b *SystemBackend) featurePaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "features/?$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.handleFeaturesRead,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["features"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["features"][1]),
+		},
+
+		{
+			Pattern: "features/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The name of the feature.",
+				},
+				"enabled": &framework.FieldSchema{
+					Type:        framework.TypeBool,
+					Description: "Whether the feature is turned on for this cluster.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.handleFeatureRead,
+				logical.UpdateOperation: b.handleFeatureUpdate,
+				logical.DeleteOperation: b.handleFeatureDelete,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["feature"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["feature"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) rateLimitPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "config/rate-limits$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.handleRateLimitsRead,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["rate-limits"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["rate-limits"][1]),
+		},
+
+		{
+			Pattern: "config/rate-limits/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The name of the rule.",
+				},
+				"path": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The path prefix the rule applies to, including the namespace path. Defaults to all paths.",
+				},
+				"rate": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The number of requests per second allowed for each client. May be fractional.",
+				},
+				"burst": &framework.FieldSchema{
+					Type:        framework.TypeInt,
+					Description: "The number of requests each client can make at once. Defaults to the rate, rounded up.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.handleRateLimitRead,
+				logical.UpdateOperation: b.handleRateLimitUpdate,
+				logical.DeleteOperation: b.handleRateLimitDelete,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["rate-limit"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["rate-limit"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) managedKeyPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "managed-keys/?$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ListOperation: b.handleManagedKeysList,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["managed-keys"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["managed-keys"][1]),
+		},
+
+		{
+			Pattern: "managed-keys/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The name of the managed key.",
+				},
+				"type": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The type of the KMS or HSM holding the key.",
+				},
+				"config": &framework.FieldSchema{
+					Type:        framework.TypeKVPairs,
+					Description: "The type specific configuration locating the key.",
+				},
+				"allowed_mounts": &framework.FieldSchema{
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The paths of the mounts allowed to use the key, such as \"pki/\".",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.handleManagedKeyRead,
+				logical.UpdateOperation: b.handleManagedKeyUpdate,
+				logical.DeleteOperation: b.handleManagedKeyDelete,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["managed-key"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["managed-key"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) kvWatchPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "kv-watch$",
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "The data path of the kv-v2 secret to watch, such as \"secret/data/foo\".",
+				Query:       true,
+			},
+			"version": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "The version of the secret known to the client. If the current version differs, the watch returns at once. Zero waits for the next change.",
+				Query:       true,
+			},
+			"timeout": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Default:     int(kvWatchDefaultTimeout.Seconds()),
+				Description: "How long to wait for a change, at most 60 seconds.",
+				Query:       true,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.handleKVWatch,
+			logical.UpdateOperation: b.handleKVWatch,
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["kv-watch"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["kv-watch"][1]),
+	}
+}
+
+func (b *SystemBackend) cubbyholeConfigPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "config/cubbyhole$",
+
+		Fields: map[string]*framework.FieldSchema{
+			"max_token_bytes": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "The total size in bytes of the values each token can store. Zero means no limit.",
+			},
+			"max_token_entries": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "The number of values each token can store. Zero means no limit.",
+			},
+			"max_entry_ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "The longest a value is kept. Values written without a TTL get this TTL. Zero means no limit.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.handleCubbyholeConfigRead,
+			logical.UpdateOperation: b.handleCubbyholeConfigUpdate,
+			logical.DeleteOperation: b.handleCubbyholeConfigDelete,
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["config/cubbyhole"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["config/cubbyhole"][1]),
+	}
+}
+
+func (b *SystemBackend) hostInfoPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "host-info/?$",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.handleHostInfo,
+		},
+		HelpSynopsis:    strings.TrimSpace(sysHelp["host-info"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["host-info"][1]),
+	}
+}
+
 func (b *SystemBackend) authPaths() []*framework.Path {
 	return []*framework.Path{
 		{
@@ -1428,6 +1677,52 @@ func (b *SystemBackend) wrappingPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["rewrap"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["rewrap"][1]),
 		},
+
+		{
+			Pattern: "wrapping/tokens/(?P<prefix>.+?)?",
+
+			Fields: map[string]*framework.FieldSchema{
+				"prefix": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["wrapping-tokens-prefix"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleWrappingTokensList,
+					Summary:  "List the outstanding wrapping tokens.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["wrapping-tokens"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["wrapping-tokens"][1]),
+		},
+
+		{
+			Pattern: "wrapping/accessors/(?P<accessor>.+)",
+
+			Fields: map[string]*framework.FieldSchema{
+				"accessor": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["wrapping-accessor"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleWrappingAccessorRead,
+					Summary:  "Look up an outstanding wrapping token by its accessor.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleWrappingAccessorRevoke,
+					Summary:  "Revoke an outstanding wrapping token by its accessor.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["wrapping-accessors"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["wrapping-accessors"][1]),
+		},
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/fatih/structs"
 	"github.com/go-test/deep"
 	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/helper/builtinplugins"
 	"github.com/hashicorp/vault/helper/identity"
@@ -45,15 +47,27 @@ func TestSystemBackend_RootPaths(t *testing.T) {
 		"replication/dr/reindex",
 		"replication/performance/reindex",
 		"rotate",
+		"keyring/backup",
+		"host-info",
+		"features/*",
 		"config/cors",
+		"config/cubbyhole",
+		"config/state/*",
 		"config/auditing/*",
+		"config/rate-limits",
+		"config/rate-limits/*",
 		"config/ui/headers/*",
+		"managed-keys/*",
 		"plugins/catalog/*",
 		"revoke-prefix/*",
 		"revoke-force/*",
 		"leases/revoke-prefix/*",
 		"leases/revoke-force/*",
 		"leases/lookup/*",
+		"wrapping/tokens*",
+		"wrapping/accessors/*",
+		"internal/cache/flush",
+		"internal/cache/flush/*",
 	}
 
 	b := testSystemBackend(t)
@@ -63,6 +77,54 @@ func TestSystemBackend_RootPaths(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_SudoPaths(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+
+	var expected []string
+	for _, path := range b.SpecialPaths().Root {
+		expected = append(expected, "sys/"+path)
+	}
+	for _, path := range c.tokenStore.SpecialPaths().Root {
+		expected = append(expected, "auth/token/"+path)
+	}
+	sort.Strings(expected)
+
+	actual := api.SudoPaths()
+	sort.Strings(actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("api sudo paths do not match the root paths\nexpected:\n%#v\ngot:\n%#v", expected, actual)
+	}
+}
+
+func TestSystemConfigStateSanitized(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+
+	req := logical.TestRequest(t, logical.ReadOperation, "config/state/sanitized")
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != nil {
+		t.Fatalf("expected no response before a config is set, got: %#v", resp)
+	}
+
+	conf := map[string]interface{}{
+		"cluster_name": "testcluster",
+		"storage": map[string]interface{}{
+			"type": "inmem",
+		},
+	}
+	c.SetSanitizedConfig(conf)
+
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp == nil || !reflect.DeepEqual(resp.Data, conf) {
+		t.Fatalf("bad: %#v", resp)
+	}
+}
+
 func TestSystemConfigCORS(t *testing.T) {
 	b := testSystemBackend(t)
 	_, barrier, _ := mockBarrier(t)
@@ -1892,6 +1954,43 @@ func TestSystemBackend_rawReadWrite(t *testing.T) {
 	// simply parse this out directly via GetPolicy, so the test now ends here.
 }
 
+func TestSystemBackend_rawWrite_Invalidate(t *testing.T) {
+	noop := &NoopBackend{}
+	c, b, root := testCoreSystemBackendRaw(t)
+	c.logicalBackends["noop"] = func(context.Context, *logical.BackendConfig) (logical.Backend, error) {
+		return noop, nil
+	}
+
+	// Enable the logical backend
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/mounts/foo")
+	req.Data["type"] = "noop"
+	req.ClientToken = root
+	_, err := c.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	me := c.router.MatchingMountEntry(namespace.RootContext(nil), "foo/")
+	if me == nil {
+		t.Fatalf("missing mount entry")
+	}
+	path := "raw/" + backendBarrierPrefix + me.UUID + "/bar"
+
+	// Write and delete the key of the backend via raw API
+	req = logical.TestRequest(t, logical.UpdateOperation, path)
+	req.Data["value"] = "baz"
+	if _, err := b.HandleRequest(namespace.RootContext(nil), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.DeleteOperation, path)
+	if _, err := b.HandleRequest(namespace.RootContext(nil), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !reflect.DeepEqual(noop.Invalidations, []string{"bar", "bar"}) {
+		t.Fatalf("bad: %v", noop.Invalidations)
+	}
+}
+
 func TestSystemBackend_rawDelete_Protected(t *testing.T) {
 	b := testSystemBackendRaw(t)
 
@@ -2249,6 +2348,9 @@ func TestSystemBackend_ToolsRandom(t *testing.T) {
 	req.Data["format"] = "hex"
 	req.Data["bytes"] = -1
 	doRequest(req, true, "", 0)
+
+	req.Data["bytes"] = maxRandomBytes + 1
+	doRequest(req, true, "", 0)
 }
 
 func TestSystemBackend_InternalUIMounts(t *testing.T) {
@@ -2660,3 +2762,156 @@ func TestSystemBackend_PathWildcardPreflight(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 }
+
+func TestSystemBackend_WrappingTokens(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	rootEntry, err := core.tokenStore.Lookup(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handle := func(req *logical.Request) *logical.Response {
+		t.Helper()
+		req.ClientToken = root
+		resp, err := core.HandleRequest(ctx, req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	// Wrap a response
+	resp := handle(&logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "sys/wrapping/wrap",
+		Data: map[string]interface{}{
+			"foo": "bar",
+		},
+		WrapInfo: &logical.RequestWrapInfo{
+			TTL: 5 * time.Minute,
+		},
+	})
+	if resp == nil || resp.WrapInfo == nil {
+		t.Fatalf("bad: %#v", resp)
+	}
+	wrapInfo := resp.WrapInfo
+
+	// The wrapping token is listed, with its creation path and creator
+	resp = handle(&logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "sys/wrapping/tokens/",
+	})
+	if diff := deep.Equal(resp.Data["keys"], []string{wrapInfo.Accessor}); diff != nil {
+		t.Fatal(diff)
+	}
+	info := resp.Data["key_info"].(map[string]interface{})[wrapInfo.Accessor].(map[string]interface{})
+	if info["creation_path"] != "sys/wrapping/wrap" || info["creator_accessor"] != rootEntry.Accessor || info["creation_ttl"] != float64(300) {
+		t.Fatalf("bad: %#v", info)
+	}
+	if ttl := info["ttl"].(int64); ttl <= 0 || ttl > 300 {
+		t.Fatalf("bad ttl: %d", ttl)
+	}
+
+	// Tokens are filtered by creation path prefix
+	resp = handle(&logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "sys/wrapping/tokens/secret/",
+	})
+	if keys, _ := resp.Data["keys"].([]string); len(keys) != 0 {
+		t.Fatalf("bad: %#v", keys)
+	}
+
+	resp = handle(&logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "sys/wrapping/accessors/" + wrapInfo.Accessor,
+	})
+	if resp == nil || resp.Data["creation_path"] != "sys/wrapping/wrap" {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Other tokens can't be looked up or revoked here
+	resp = handle(&logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "sys/wrapping/accessors/" + rootEntry.Accessor,
+	})
+	if resp != nil {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Once revoked, the response can't be unwrapped anymore
+	handle(&logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "sys/wrapping/accessors/" + wrapInfo.Accessor,
+	})
+	te, err := core.tokenStore.Lookup(ctx, wrapInfo.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if te != nil {
+		t.Fatalf("expected the wrapping token to be revoked, got %#v", te)
+	}
+	resp = handle(&logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "sys/wrapping/tokens/",
+	})
+	if keys, _ := resp.Data["keys"].([]string); len(keys) != 0 {
+		t.Fatalf("bad: %#v", keys)
+	}
+}
+
+func TestSystemBackend_CacheFlush(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "policy/foo")
+	req.Data["policy"] = `path "secret/*" { capabilities = ["read"] }`
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	// Change the policy behind the back of the policy store
+	entry, err := logical.StorageEntryJSON("foo", &PolicyEntry{
+		Version: 2,
+		Raw:     `path "secret/*" { capabilities = ["deny"] }`,
+		Type:    PolicyTypeACL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.policyStore.getACLView(namespace.RootNamespace).Put(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	readPolicy := func() string {
+		t.Helper()
+		p, err := c.policyStore.GetPolicy(ctx, "foo", PolicyTypeACL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.Raw
+	}
+	if raw := readPolicy(); !strings.Contains(raw, "read") {
+		t.Fatalf("expected the cached policy, got %q", raw)
+	}
+
+	// Unknown subsystems are rejected
+	req = logical.TestRequest(t, logical.UpdateOperation, "internal/cache/flush/unknown")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != logical.ErrInvalidRequest || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error, got err: %v, resp: %#v", err, resp)
+	}
+
+	for _, path := range []string{"internal/cache/flush/token", "internal/cache/flush/policy", "internal/cache/flush"} {
+		req = logical.TestRequest(t, logical.UpdateOperation, path)
+		resp, err = b.HandleRequest(ctx, req)
+		if err != nil || resp != nil {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+	}
+	if raw := readPolicy(); !strings.Contains(raw, "deny") {
+		t.Fatalf("expected the stored policy, got %q", raw)
+	}
+}
@@ -0,0 +1,247 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/managedkeys"
+)
+
+// managedKeysPath is where the managed key configs are stored, relative to
+// the system view
+const managedKeysPath = "managed-keys/"
+
+// managedKeySensitiveConfig are the fields of the type specific
+// configurations which are not returned when reading managed keys
+var managedKeySensitiveConfig = []string{"pin"}
+
+// ManagedKeyEntry is the configuration of a managed key: a private key held
+// by a KMS or HSM, which backends reference by name to sign with it
+type ManagedKeyEntry struct {
+	Name string `json:"name"`
+
+	// Type selects the factory creating the key
+	Type string `json:"type"`
+
+	// Config is the type specific configuration locating the key
+	Config map[string]string `json:"config"`
+
+	// AllowedMounts are the API paths of the mounts allowed to use the key,
+	// such as "pki/"
+	AllowedMounts []string `json:"allowed_mounts"`
+}
+
+// managedKeyStore caches the keys created from their configuration, as
+// creating them usually means connecting to the KMS
+type managedKeyStore struct {
+	sync.Mutex
+
+	keys map[string]*cachedManagedKey
+}
+
+// cachedManagedKey is a key along with the config it was created from. The
+// config may be changed in storage by another node, in which case the key
+// is created again.
+type cachedManagedKey struct {
+	managedkeys.Key
+
+	entry *ManagedKeyEntry
+}
+
+func newManagedKeyStore() *managedKeyStore {
+	return &managedKeyStore{
+		keys: make(map[string]*cachedManagedKey),
+	}
+}
+
+// remove drops the cached key with the given name and closes it. The lock
+// must be held.
+func (s *managedKeyStore) remove(c *Core, name string) {
+	key, ok := s.keys[name]
+	if !ok {
+		return
+	}
+	delete(s.keys, name)
+	if err := key.Close(); err != nil {
+		c.logger.Warn("failed to close managed key", "name", name, "error", err)
+	}
+}
+
+// validateManagedKeyEntry checks and normalizes a managed key config
+func (c *Core) validateManagedKeyEntry(entry *ManagedKeyEntry) error {
+	if entry.Type == "" {
+		return fmt.Errorf("type is required")
+	}
+	if _, ok := c.managedKeyTypes[entry.Type]; !ok {
+		return fmt.Errorf("unknown managed key type %q", entry.Type)
+	}
+	if len(entry.AllowedMounts) == 0 {
+		return fmt.Errorf("allowed_mounts is required")
+	}
+
+	mounts := make([]string, 0, len(entry.AllowedMounts))
+	for _, mount := range entry.AllowedMounts {
+		mount = strings.TrimPrefix(strings.TrimSpace(mount), "/")
+		if mount == "" {
+			continue
+		}
+		if !strings.HasSuffix(mount, "/") {
+			mount += "/"
+		}
+		mounts = append(mounts, mount)
+	}
+	entry.AllowedMounts = strutil.RemoveDuplicates(mounts, false)
+	if len(entry.AllowedMounts) == 0 {
+		return fmt.Errorf("allowed_mounts is required")
+	}
+	return nil
+}
+
+// newManagedKey creates the key described by the config
+func (c *Core) newManagedKey(ctx context.Context, entry *ManagedKeyEntry) (managedkeys.Key, error) {
+	factory, ok := c.managedKeyTypes[entry.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown managed key type %q", entry.Type)
+	}
+
+	key, err := factory(ctx, entry.Config, c.baseLogger.Named("managed-key").Named(entry.Name))
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("failed to create managed key %q: {{err}}", entry.Name), err)
+	}
+	return key, nil
+}
+
+// SetManagedKey creates or replaces a managed key config. The key is created
+// from the config first, so that a config locating no usable key is
+// rejected.
+func (c *Core) SetManagedKey(ctx context.Context, entry *ManagedKeyEntry) error {
+	if entry == nil || entry.Name == "" {
+		return fmt.Errorf("managed key must have a name")
+	}
+	if err := c.validateManagedKeyEntry(entry); err != nil {
+		return err
+	}
+
+	key, err := c.newManagedKey(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	c.managedKeys.Lock()
+	defer c.managedKeys.Unlock()
+
+	storageEntry, err := logical.StorageEntryJSON(managedKeysPath+entry.Name, entry)
+	if err != nil {
+		key.Close()
+		return errwrap.Wrapf("failed to create managed key entry: {{err}}", err)
+	}
+	if err := c.systemBarrierView.Put(ctx, storageEntry); err != nil {
+		key.Close()
+		return errwrap.Wrapf("failed to save managed key: {{err}}", err)
+	}
+
+	c.managedKeys.remove(c, entry.Name)
+	c.managedKeys.keys[entry.Name] = &cachedManagedKey{Key: key, entry: entry}
+	return nil
+}
+
+// ManagedKeyEntry returns the config of a managed key, or nil if it doesn't
+// exist
+func (c *Core) ManagedKeyEntry(ctx context.Context, name string) (*ManagedKeyEntry, error) {
+	out, err := c.systemBarrierView.Get(ctx, managedKeysPath+name)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read managed key: {{err}}", err)
+	}
+	if out == nil {
+		return nil, nil
+	}
+
+	entry := new(ManagedKeyEntry)
+	if err := out.DecodeJSON(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ListManagedKeys returns the names of the managed keys
+func (c *Core) ListManagedKeys(ctx context.Context) ([]string, error) {
+	keys, err := c.systemBarrierView.List(ctx, managedKeysPath)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to list managed keys: {{err}}", err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// DeleteManagedKey removes a managed key config. Backends using the key
+// can't sign with it anymore; the key itself is left in the KMS.
+func (c *Core) DeleteManagedKey(ctx context.Context, name string) error {
+	c.managedKeys.Lock()
+	defer c.managedKeys.Unlock()
+
+	if err := c.systemBarrierView.Delete(ctx, managedKeysPath+name); err != nil {
+		return errwrap.Wrapf("failed to delete managed key: {{err}}", err)
+	}
+
+	c.managedKeys.remove(c, name)
+	return nil
+}
+
+// managedKey returns the managed key with the given name, if the mount with
+// the given API path is allowed to use it
+func (c *Core) managedKey(ctx context.Context, name, mountPath string) (logical.ManagedKey, error) {
+	entry, err := c.ManagedKeyEntry(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("managed key %q not found", name)
+	}
+	if !strutil.StrListContains(entry.AllowedMounts, mountPath) {
+		return nil, fmt.Errorf("mount %q is not allowed to use managed key %q", mountPath, name)
+	}
+
+	c.managedKeys.Lock()
+	defer c.managedKeys.Unlock()
+
+	if cached, ok := c.managedKeys.keys[name]; ok {
+		if cached.entry.Type == entry.Type && reflect.DeepEqual(cached.entry.Config, entry.Config) {
+			return cached.Key, nil
+		}
+		c.managedKeys.remove(c, name)
+	}
+
+	key, err := c.newManagedKey(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	c.managedKeys.keys[name] = &cachedManagedKey{Key: key, entry: entry}
+	return key, nil
+}
+
+// invalidateManagedKey drops the cached key with the given name, after its
+// config was changed in storage
+func (c *Core) invalidateManagedKey(name string) {
+	c.managedKeys.Lock()
+	defer c.managedKeys.Unlock()
+
+	c.managedKeys.remove(c, name)
+}
+
+// closeManagedKeys closes the cached managed keys, which are created again
+// when next used
+func (c *Core) closeManagedKeys() {
+	c.managedKeys.Lock()
+	defer c.managedKeys.Unlock()
+
+	for name := range c.managedKeys.keys {
+		c.managedKeys.remove(c, name)
+	}
+}
@@ -0,0 +1,61 @@
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/managedkeys"
+)
+
+func TestCore_ManagedKeyStorageChange(t *testing.T) {
+	c, _, _ := TestCoreUnsealedWithConfig(t, &CoreConfig{
+		ManagedKeyTypes: map[string]managedkeys.Factory{
+			"test": managedkeys.NewTestFactory(),
+		},
+	})
+	ctx := context.Background()
+
+	entry := &ManagedKeyEntry{
+		Name:          "ca",
+		Type:          "test",
+		Config:        map[string]string{"key_id": "first"},
+		AllowedMounts: []string{"pki/"},
+	}
+	if err := c.SetManagedKey(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	first, err := c.managedKey(ctx, "ca", "pki/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The config is changed in storage only, as on another node
+	changed := *entry
+	changed.Config = map[string]string{"key_id": "second"}
+	storageEntry, err := logical.StorageEntryJSON(managedKeysPath+entry.Name, &changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.systemBarrierView.Put(ctx, storageEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := c.managedKey(ctx, "ca", "pki/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equal, err := certutil.ComparePublicKeys(first.Public(), second.Public()); err != nil || equal {
+		t.Fatalf("expected the key of the new config, got the cached one: %v", err)
+	}
+
+	// Invalidating the config drops the cached key
+	c.systemBackend.InvalidateKey(ctx, managedKeysPath+entry.Name)
+	c.managedKeys.Lock()
+	_, ok := c.managedKeys.keys[entry.Name]
+	c.managedKeys.Unlock()
+	if ok {
+		t.Fatal("expected the cached key to be dropped")
+	}
+}
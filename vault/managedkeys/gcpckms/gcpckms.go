@@ -0,0 +1,212 @@
+// Package gcpckms implements managed keys held by Google Cloud KMS
+package gcpckms
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	cloudkms "cloud.google.com/go/kms/apiv1"
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/useragent"
+	"github.com/hashicorp/vault/vault/managedkeys"
+	"google.golang.org/api/option"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+)
+
+// requestTimeout bounds the signing and decryption requests, which are made
+// without a context of their own as crypto.Signer and crypto.Decrypter don't
+// take one
+const requestTimeout = 30 * time.Second
+
+// key is an asymmetric signing or decryption key version of Cloud KMS
+type key struct {
+	name      string
+	algorithm kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+	public    crypto.PublicKey
+	client    *cloudkms.KeyManagementClient
+}
+
+var _ managedkeys.Key = (*key)(nil)
+
+// NewKey returns the Cloud KMS key version described by the configuration,
+// after fetching its public key. The configuration has the "project",
+// "region", "key_ring", "crypto_key" and "crypto_key_version" of the key,
+// and optionally the path to a "credentials" file. Without credentials, the
+// application default credentials are used.
+func NewKey(ctx context.Context, config map[string]string, logger log.Logger) (managedkeys.Key, error) {
+	for _, field := range []string{"project", "region", "key_ring", "crypto_key", "crypto_key_version"} {
+		if config[field] == "" {
+			return nil, fmt.Errorf("%q is required for Cloud KMS managed keys", field)
+		}
+	}
+
+	opts := []option.ClientOption{
+		option.WithUserAgent(useragent.String()),
+	}
+	if config["credentials"] != "" {
+		opts = append(opts, option.WithCredentialsFile(config["credentials"]))
+	}
+	client, err := cloudkms.NewKeyManagementClient(ctx, opts...)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to create KMS client: {{err}}", err)
+	}
+
+	k := &key{
+		name: fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s/cryptoKeyVersions/%s",
+			config["project"], config["region"], config["key_ring"], config["crypto_key"], config["crypto_key_version"]),
+		client: client,
+	}
+
+	resp, err := client.GetPublicKey(ctx, &kmspb.GetPublicKeyRequest{
+		Name: k.name,
+	})
+	if err != nil {
+		client.Close()
+		return nil, errwrap.Wrapf("failed to get the public key - ensure the key version "+
+			"exists and the service account has the roles/cloudkms.publicKeyViewer "+
+			"permission: {{err}}", err)
+	}
+
+	block, _ := pem.Decode([]byte(resp.Pem))
+	if block == nil {
+		client.Close()
+		return nil, errors.New("failed to decode the public key")
+	}
+	k.public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		client.Close()
+		return nil, errwrap.Wrapf("failed to parse the public key: {{err}}", err)
+	}
+	k.algorithm = resp.Algorithm
+
+	if _, err := k.hash(); err != nil && !k.decrypts() {
+		client.Close()
+		return nil, fmt.Errorf("unsupported key algorithm %s, the key must be an asymmetric key", k.algorithm)
+	}
+
+	return k, nil
+}
+
+func (k *key) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs the digest with the key version, which must have been produced
+// with the hash algorithm of the key version
+func (k *key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	defer metrics.MeasureSince([]string{"managed_key", "gcpckms", "sign"}, time.Now())
+
+	hash, err := k.hash()
+	if err != nil {
+		return nil, err
+	}
+	if opts.HashFunc() != hash {
+		return nil, fmt.Errorf("the key can only sign %s digests", hash)
+	}
+
+	_, pss := opts.(*rsa.PSSOptions)
+	if pss != k.pss() {
+		return nil, fmt.Errorf("the key algorithm %s doesn't match the requested padding", k.algorithm)
+	}
+
+	req := &kmspb.AsymmetricSignRequest{
+		Name:   k.name,
+		Digest: &kmspb.Digest{},
+	}
+	switch hash {
+	case crypto.SHA256:
+		req.Digest.Digest = &kmspb.Digest_Sha256{Sha256: digest}
+	case crypto.SHA384:
+		req.Digest.Digest = &kmspb.Digest_Sha384{Sha384: digest}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := k.client.AsymmetricSign(ctx, req)
+	if err != nil {
+		metrics.IncrCounter([]string{"managed_key", "gcpckms", "sign", "error"}, 1)
+		return nil, errwrap.Wrapf("failed to sign with Cloud KMS: {{err}}", err)
+	}
+
+	return resp.Signature, nil
+}
+
+// Decrypt decrypts a ciphertext encrypted with RSA-OAEP, using SHA-256 and no
+// label, with the public key of the key version
+func (k *key) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	defer metrics.MeasureSince([]string{"managed_key", "gcpckms", "decrypt"}, time.Now())
+
+	if !k.decrypts() {
+		return nil, fmt.Errorf("the key algorithm %s can't decrypt", k.algorithm)
+	}
+	oaep, ok := opts.(*rsa.OAEPOptions)
+	if !ok || oaep.Hash != crypto.SHA256 || len(oaep.Label) != 0 {
+		return nil, errors.New("the key can only decrypt RSA-OAEP ciphertexts using SHA-256 and no label")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := k.client.AsymmetricDecrypt(ctx, &kmspb.AsymmetricDecryptRequest{
+		Name:       k.name,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		metrics.IncrCounter([]string{"managed_key", "gcpckms", "decrypt", "error"}, 1)
+		return nil, errwrap.Wrapf("failed to decrypt with Cloud KMS: {{err}}", err)
+	}
+
+	return resp.Plaintext, nil
+}
+
+func (k *key) Close() error {
+	return k.client.Close()
+}
+
+// hash returns the hash algorithm of the digests the key version signs
+func (k *key) hash() (crypto.Hash, error) {
+	switch k.algorithm {
+	case kmspb.CryptoKeyVersion_RSA_SIGN_PSS_2048_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PSS_3072_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PSS_4096_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_2048_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_3072_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_4096_SHA256,
+		kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256:
+		return crypto.SHA256, nil
+	case kmspb.CryptoKeyVersion_EC_SIGN_P384_SHA384:
+		return crypto.SHA384, nil
+	default:
+		return 0, fmt.Errorf("the key algorithm %s can't sign", k.algorithm)
+	}
+}
+
+func (k *key) pss() bool {
+	switch k.algorithm {
+	case kmspb.CryptoKeyVersion_RSA_SIGN_PSS_2048_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PSS_3072_SHA256,
+		kmspb.CryptoKeyVersion_RSA_SIGN_PSS_4096_SHA256:
+		return true
+	}
+	return false
+}
+
+func (k *key) decrypts() bool {
+	switch k.algorithm {
+	case kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_2048_SHA256,
+		kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_3072_SHA256,
+		kmspb.CryptoKeyVersion_RSA_DECRYPT_OAEP_4096_SHA256:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+// Package managedkeys defines the types of managed keys: private keys held
+// by an external KMS or HSM which Vault delegates signing to.
+package managedkeys
+
+import (
+	"context"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Key is a managed key, as returned by the factory of its type
+type Key interface {
+	logical.ManagedKey
+
+	// Close releases the resources held for the key, such as the client of
+	// the KMS. The key isn't used anymore once closed.
+	Close() error
+}
+
+// Factory returns the managed key described by the type specific
+// configuration. It should check that the key exists and can be used, so
+// that configuration errors are reported when the key is configured.
+type Factory func(ctx context.Context, config map[string]string, logger log.Logger) (Key, error)
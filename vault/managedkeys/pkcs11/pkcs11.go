@@ -0,0 +1,324 @@
+//go:build pkcs11
+// +build pkcs11
+
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/vault/managedkeys"
+	p11 "github.com/miekg/pkcs11"
+)
+
+// key is a signing key of a PKCS#11 token
+type key struct {
+	*certutil.PKCS11Signer
+
+	module    *module
+	closeOnce sync.Once
+}
+
+var _ managedkeys.Key = (*key)(nil)
+
+// NewKey returns the private key of a PKCS#11 token described by the
+// configuration, which has the path to the PKCS#11 "library", the "slot" of
+// the token, the user "pin" and the "key_label" of the key.
+func NewKey(_ context.Context, config map[string]string, logger log.Logger) (managedkeys.Key, error) {
+	for _, field := range []string{"library", "slot", "pin", "key_label"} {
+		if config[field] == "" {
+			return nil, fmt.Errorf("%q is required for PKCS#11 managed keys", field)
+		}
+	}
+	slot, err := strconv.ParseUint(config["slot"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PKCS#11 slot %q", config["slot"])
+	}
+
+	m, err := loadModule(config["library"])
+	if err != nil {
+		return nil, err
+	}
+	signer, err := certutil.NewPKCS11Signer(m, certutil.PKCS11Config{
+		Slot:     uint(slot),
+		PIN:      config["pin"],
+		KeyLabel: config["key_label"],
+	})
+	if err != nil {
+		m.release()
+		return nil, err
+	}
+	logger.Debug("opened PKCS#11 session", "library", config["library"], "slot", slot)
+
+	return &key{
+		PKCS11Signer: signer,
+		module:       m,
+	}, nil
+}
+
+// Decrypt is not supported by PKCS#11 managed keys
+func (k *key) Decrypt(_ io.Reader, _ []byte, _ crypto.DecrypterOpts) ([]byte, error) {
+	return nil, errors.New("PKCS#11 managed keys can't decrypt")
+}
+
+// Close closes the session of the key, and the library once none of its
+// keys is used anymore
+func (k *key) Close() error {
+	var err error
+	k.closeOnce.Do(func() {
+		err = k.PKCS11Signer.Close()
+		k.module.release()
+	})
+	return err
+}
+
+var (
+	modulesLock sync.Mutex
+	modules     = make(map[string]*module)
+)
+
+// module is a loaded PKCS#11 library, shared by the keys using it as the
+// library can only be initialized once per process
+type module struct {
+	path string
+	ctx  *p11.Ctx
+	refs int
+}
+
+var _ certutil.PKCS11Module = (*module)(nil)
+
+// loadModule loads and initializes the PKCS#11 library of the given path, or
+// returns the already loaded one. The module must be released once not used
+// anymore.
+func loadModule(path string) (*module, error) {
+	modulesLock.Lock()
+	defer modulesLock.Unlock()
+
+	if m, ok := modules[path]; ok {
+		m.refs++
+		return m, nil
+	}
+
+	ctx := p11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 library %q", path)
+	}
+	if err := ctx.Initialize(); err != nil && !isError(err, p11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, errwrap.Wrapf(fmt.Sprintf("failed to initialize PKCS#11 library %q: {{err}}", path), err)
+	}
+
+	m := &module{
+		path: path,
+		ctx:  ctx,
+		refs: 1,
+	}
+	modules[path] = m
+	return m, nil
+}
+
+// release finalizes and unloads the library once its last key is closed
+func (m *module) release() {
+	modulesLock.Lock()
+	defer modulesLock.Unlock()
+
+	m.refs--
+	if m.refs > 0 {
+		return
+	}
+	delete(modules, m.path)
+	m.ctx.Finalize()
+	m.ctx.Destroy()
+}
+
+// OpenSession opens a session on the token of the given slot and logs the
+// user in
+func (m *module) OpenSession(slot uint, pin string) (certutil.PKCS11Session, error) {
+	handle, err := m.ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, err
+	}
+	// The login state is shared by the sessions of a token
+	if err := m.ctx.Login(handle, p11.CKU_USER, pin); err != nil && !isError(err, p11.CKR_USER_ALREADY_LOGGED_IN) {
+		m.ctx.CloseSession(handle)
+		return nil, errwrap.Wrapf("failed to log in: {{err}}", err)
+	}
+
+	return &session{
+		ctx:    m.ctx,
+		handle: handle,
+	}, nil
+}
+
+// session is a logged in session of a PKCS#11 token
+type session struct {
+	ctx    *p11.Ctx
+	handle p11.SessionHandle
+}
+
+var _ certutil.PKCS11Session = (*session)(nil)
+
+// FindKeyPair returns the private key of the given label, and its public key
+// read from the public key object of the same label or, when the token
+// doesn't hold one, from the certificate of the same label
+func (s *session) FindKeyPair(label string) (certutil.PKCS11Object, crypto.PublicKey, error) {
+	keys, err := s.findObjects(p11.CKO_PRIVATE_KEY, label)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch len(keys) {
+	case 0:
+		return 0, nil, fmt.Errorf("no private key with label %q", label)
+	case 1:
+	default:
+		return 0, nil, fmt.Errorf("%d private keys with label %q", len(keys), label)
+	}
+
+	public, err := s.publicKey(label)
+	if err != nil {
+		return 0, nil, err
+	}
+	return certutil.PKCS11Object(keys[0]), public, nil
+}
+
+func (s *session) publicKey(label string) (crypto.PublicKey, error) {
+	objects, err := s.findObjects(p11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) > 0 {
+		attrs, err := s.ctx.GetAttributeValue(s.handle, objects[0], []*p11.Attribute{
+			p11.NewAttribute(p11.CKA_KEY_TYPE, nil),
+		})
+		if err != nil {
+			return nil, errwrap.Wrapf("failed to read the public key type: {{err}}", err)
+		}
+		keyType, err := attributeUint(attrs[0].Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch keyType {
+		case p11.CKK_RSA:
+			attrs, err := s.ctx.GetAttributeValue(s.handle, objects[0], []*p11.Attribute{
+				p11.NewAttribute(p11.CKA_MODULUS, nil),
+				p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, nil),
+			})
+			if err != nil {
+				return nil, errwrap.Wrapf("failed to read the RSA public key: {{err}}", err)
+			}
+			return parseRSAPublicKey(attrs[0].Value, attrs[1].Value)
+		case p11.CKK_EC:
+			attrs, err := s.ctx.GetAttributeValue(s.handle, objects[0], []*p11.Attribute{
+				p11.NewAttribute(p11.CKA_EC_PARAMS, nil),
+				p11.NewAttribute(p11.CKA_EC_POINT, nil),
+			})
+			if err != nil {
+				return nil, errwrap.Wrapf("failed to read the EC public key: {{err}}", err)
+			}
+			return parseECPublicKey(attrs[0].Value, attrs[1].Value)
+		default:
+			return nil, fmt.Errorf("unsupported PKCS#11 key type %d", keyType)
+		}
+	}
+
+	objects, err = s.findObjects(p11.CKO_CERTIFICATE, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no public key or certificate with label %q", label)
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.handle, objects[0], []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read the certificate: {{err}}", err)
+	}
+	cert, err := x509.ParseCertificate(attrs[0].Value)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to parse the certificate: {{err}}", err)
+	}
+	return cert.PublicKey, nil
+}
+
+// findObjects returns the objects of the given class and label
+func (s *session) findObjects(class uint, label string) ([]p11.ObjectHandle, error) {
+	if err := s.ctx.FindObjectsInit(s.handle, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, class),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	}); err != nil {
+		return nil, errwrap.Wrapf("failed to search the token: {{err}}", err)
+	}
+
+	var objects []p11.ObjectHandle
+	for {
+		found, _, err := s.ctx.FindObjects(s.handle, 16)
+		if err != nil {
+			s.ctx.FindObjectsFinal(s.handle)
+			return nil, errwrap.Wrapf("failed to search the token: {{err}}", err)
+		}
+		if len(found) == 0 {
+			break
+		}
+		objects = append(objects, found...)
+	}
+	if err := s.ctx.FindObjectsFinal(s.handle); err != nil {
+		return nil, errwrap.Wrapf("failed to search the token: {{err}}", err)
+	}
+	return objects, nil
+}
+
+// Sign signs data with the given private key and mechanism
+func (s *session) Sign(key certutil.PKCS11Object, mechanism certutil.PKCS11Mechanism, pss *certutil.PKCS11PSSParams, data []byte) ([]byte, error) {
+	var params interface{}
+	if mechanism == certutil.PKCS11MechanismRSAPKCSPSS {
+		if pss == nil {
+			return nil, errors.New("no PSS parameters given")
+		}
+		hash, ok := pssHashes[pss.Hash]
+		if !ok {
+			return nil, fmt.Errorf("unsupported PSS hash %v", pss.Hash)
+		}
+		params = p11.NewPSSParams(hash.mechanism, hash.mgf, uint(pss.SaltLength))
+	}
+
+	mech := []*p11.Mechanism{p11.NewMechanism(uint(mechanism), params)}
+	if err := s.ctx.SignInit(s.handle, mech, p11.ObjectHandle(key)); err != nil {
+		return nil, err
+	}
+	return s.ctx.Sign(s.handle, data)
+}
+
+// Close logs out and closes the session
+func (s *session) Close() error {
+	s.ctx.Logout(s.handle)
+	return s.ctx.CloseSession(s.handle)
+}
+
+// pssHashes are the CK_RSA_PKCS_PSS_PARAMS hash mechanisms and MGF1
+// functions of the supported hashes
+var pssHashes = map[crypto.Hash]struct {
+	mechanism, mgf uint
+}{
+	crypto.SHA1:   {p11.CKM_SHA_1, p11.CKG_MGF1_SHA1},
+	crypto.SHA224: {p11.CKM_SHA224, p11.CKG_MGF1_SHA224},
+	crypto.SHA256: {p11.CKM_SHA256, p11.CKG_MGF1_SHA256},
+	crypto.SHA384: {p11.CKM_SHA384, p11.CKG_MGF1_SHA384},
+	crypto.SHA512: {p11.CKM_SHA512, p11.CKG_MGF1_SHA512},
+}
+
+// isError returns whether err is the given PKCS#11 error code
+func isError(err error, code uint) bool {
+	p11Err, ok := err.(p11.Error)
+	return ok && uint(p11Err) == code
+}
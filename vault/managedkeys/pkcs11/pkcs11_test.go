@@ -0,0 +1,88 @@
+//go:build pkcs11
+// +build pkcs11
+
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+)
+
+// TestNewKey signs with a key of a PKCS#11 token, e.g. of SoftHSM, set up
+// beforehand and located by the VAULT_PKCS11_LIBRARY, VAULT_PKCS11_SLOT,
+// VAULT_PKCS11_PIN and VAULT_PKCS11_KEY_LABEL environment variables
+func TestNewKey(t *testing.T) {
+	config := map[string]string{
+		"library":   os.Getenv("VAULT_PKCS11_LIBRARY"),
+		"slot":      os.Getenv("VAULT_PKCS11_SLOT"),
+		"pin":       os.Getenv("VAULT_PKCS11_PIN"),
+		"key_label": os.Getenv("VAULT_PKCS11_KEY_LABEL"),
+	}
+	for _, v := range config {
+		if v == "" {
+			t.Skip("VAULT_PKCS11_LIBRARY, VAULT_PKCS11_SLOT, VAULT_PKCS11_PIN and VAULT_PKCS11_KEY_LABEL must be set")
+		}
+	}
+
+	// Two keys share the library
+	key, err := NewKey(context.Background(), config, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	other, err := NewKey(context.Background(), config, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("vault"))
+	opts := []crypto.SignerOpts{crypto.SHA256}
+	if _, ok := key.Public().(*rsa.PublicKey); ok {
+		opts = append(opts, &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+	for _, opt := range opts {
+		sig, err := key.Sign(rand.Reader, digest[:], opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch public := key.Public().(type) {
+		case *rsa.PublicKey:
+			if pss, ok := opt.(*rsa.PSSOptions); ok {
+				err = rsa.VerifyPSS(public, crypto.SHA256, digest[:], sig, pss)
+			} else {
+				err = rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], sig)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		case *ecdsa.PublicKey:
+			var ecSig struct {
+				R, S *big.Int
+			}
+			if _, err := asn1.Unmarshal(sig, &ecSig); err != nil {
+				t.Fatal(err)
+			}
+			if !ecdsa.Verify(public, digest[:], ecSig.R, ecSig.S) {
+				t.Fatal("bad ECDSA signature")
+			}
+		}
+	}
+
+	config["key_label"] += "-missing"
+	if _, err := NewKey(context.Background(), config, log.NewNullLogger()); err == nil {
+		t.Fatal("expected an error with a missing key label")
+	}
+}
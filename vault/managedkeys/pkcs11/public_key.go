@@ -0,0 +1,101 @@
+// Package pkcs11 implements managed keys held by PKCS#11 tokens, e.g. HSMs.
+// The token is accessed through github.com/miekg/pkcs11, which requires cgo,
+// so the managed key type is only built with the pkcs11 build tag.
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"unsafe"
+)
+
+// namedCurves are the curves of the EC keys, by the OID of their
+// CKA_EC_PARAMS
+var namedCurves = []struct {
+	oid   asn1.ObjectIdentifier
+	curve elliptic.Curve
+}{
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 33}, elliptic.P224()},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}, elliptic.P256()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 34}, elliptic.P384()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 35}, elliptic.P521()},
+}
+
+// parseRSAPublicKey returns the RSA public key of the given CKA_MODULUS and
+// CKA_PUBLIC_EXPONENT, which are big-endian integers
+func parseRSAPublicKey(modulus, exponent []byte) (crypto.PublicKey, error) {
+	e := new(big.Int).SetBytes(exponent)
+	if len(modulus) == 0 || !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA public key")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(e.Int64()),
+	}, nil
+}
+
+// parseECPublicKey returns the EC public key of the given CKA_EC_PARAMS, the
+// DER encoded OID of a named curve, and CKA_EC_POINT, the DER encoded octet
+// string of the uncompressed point. The point is also accepted unwrapped, as
+// some tokens return it so.
+func parseECPublicKey(params, point []byte) (crypto.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(params, &oid); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid EC parameters, only named curves are supported")
+	}
+	var curve elliptic.Curve
+	for _, named := range namedCurves {
+		if named.oid.Equal(oid) {
+			curve = named.curve
+		}
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported EC curve %s", oid)
+	}
+
+	// The unwrapped point is told apart by its length, as its 0x04 prefix
+	// is the tag of octet strings as well
+	raw := point
+	if len(point) != 1+2*((curve.Params().BitSize+7)/8) {
+		if rest, err := asn1.Unmarshal(point, &raw); err != nil || len(rest) != 0 {
+			return nil, errors.New("invalid EC point")
+		}
+	}
+	x, y := elliptic.Unmarshal(curve, raw)
+	if x == nil {
+		return nil, errors.New("invalid EC point")
+	}
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+// nativeEndian is the byte order of the CK_ULONG attribute values
+var nativeEndian binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+// attributeUint returns the value of a CK_ULONG attribute
+func attributeUint(value []byte) (uint, error) {
+	switch len(value) {
+	case 4:
+		return uint(nativeEndian.Uint32(value)), nil
+	case 8:
+		return uint(nativeEndian.Uint64(value)), nil
+	}
+	return 0, fmt.Errorf("invalid CK_ULONG attribute length %d", len(value))
+}
@@ -0,0 +1,80 @@
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestParsePublicKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := parseRSAPublicKey(rsaKey.N.Bytes(), big.NewInt(int64(rsaKey.E)).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(public, &rsaKey.PublicKey) {
+		t.Fatal("bad RSA public key")
+	}
+	if _, err := parseRSAPublicKey(rsaKey.N.Bytes(), nil); err == nil {
+		t.Fatal("expected an error parsing an RSA key without exponent")
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 34})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := elliptic.Marshal(ecKey.Curve, ecKey.X, ecKey.Y)
+	point, err := asn1.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both the octet string and the unwrapped point are accepted
+	for _, p := range [][]byte{point, raw} {
+		public, err := parseECPublicKey(params, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(public, &ecKey.PublicKey) {
+			t.Fatal("bad EC public key")
+		}
+	}
+
+	unknownCurve, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, args := range map[string][2][]byte{
+		"unknown curve":     {unknownCurve, point},
+		"invalid params":    {[]byte{0x06}, point},
+		"invalid point":     {params, append(point[:len(point)-1:len(point)-1], 0x00)},
+		"point of P-256":    {params, elliptic.Marshal(elliptic.P256(), elliptic.P256().Params().Gx, elliptic.P256().Params().Gy)},
+		"compressed point":  {params, append([]byte{0x02}, ecKey.X.Bytes()...)},
+		"no point":          {params, nil},
+		"no params":         {nil, point},
+		"trailing params":   {append(params, 0x00), point},
+		"trailing in point": {params, append(point, 0x00)},
+	} {
+		if _, err := parseECPublicKey(args[0], args[1]); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	if v, err := attributeUint(make([]byte, 8)); err != nil || v != 0 {
+		t.Fatalf("bad CK_ULONG value %d: %v", v, err)
+	}
+	if _, err := attributeUint(make([]byte, 2)); err == nil {
+		t.Fatal("expected an error parsing a short CK_ULONG")
+	}
+}
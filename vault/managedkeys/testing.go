@@ -0,0 +1,77 @@
+package managedkeys
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+)
+
+// NewTestFactory returns the factory of a managed key type for tests, whose
+// keys are held in memory. Keys are generated the first time a "key_id" is
+// configured, and the same key is returned for that ID afterwards, like a
+// key held by a KMS. The "key_type" is either "rsa" (the default) or "ec";
+// only RSA keys can decrypt.
+func NewTestFactory() Factory {
+	var l sync.Mutex
+	keys := make(map[string]crypto.Signer)
+
+	return func(_ context.Context, config map[string]string, _ log.Logger) (Key, error) {
+		id := config["key_id"]
+		if id == "" {
+			return nil, fmt.Errorf("key_id is required")
+		}
+
+		l.Lock()
+		defer l.Unlock()
+
+		signer, ok := keys[id]
+		if !ok {
+			var keyBundle certutil.ParsedCSRBundle
+			var err error
+			switch config["key_type"] {
+			case "", "rsa":
+				err = certutil.GeneratePrivateKey(certutil.RSAPrivateKey, 2048, &keyBundle)
+			case "ec":
+				err = certutil.GeneratePrivateKey(certutil.ECPrivateKey, 256, &keyBundle)
+			default:
+				return nil, fmt.Errorf("unsupported key_type %q", config["key_type"])
+			}
+			if err != nil {
+				return nil, err
+			}
+			signer = keyBundle.PrivateKey
+			keys[id] = signer
+		}
+
+		return &testKey{signer: signer}, nil
+	}
+}
+
+type testKey struct {
+	signer crypto.Signer
+}
+
+func (k *testKey) Public() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+func (k *testKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.signer.Sign(rand, digest, opts)
+}
+
+func (k *testKey) Decrypt(rand io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	decrypter, ok := k.signer.(crypto.Decrypter)
+	if !ok {
+		return nil, fmt.Errorf("%T keys can't decrypt", k.signer)
+	}
+	return decrypter.Decrypt(rand, ciphertext, opts)
+}
+
+func (k *testKey) Close() error {
+	return nil
+}
@@ -1,7 +1,9 @@
 package vault
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/errwrap"
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/builtin/plugin"
 	"github.com/hashicorp/vault/helper/namespace"
@@ -17,6 +20,7 @@ import (
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/physical"
 	"github.com/mitchellh/copystructure"
 )
 
@@ -41,8 +45,28 @@ const (
 	// mountTableType is the value we expect to find for the mount table and
 	// corresponding entries
 	mountTableType = "mounts"
+
+	// mountTableShardPrefix is appended to the path of the mount, auth and
+	// audit tables to store their shards
+	mountTableShardPrefix = "/shards/"
+
+	// mountTableShardVersion is the version of the format of sharded tables
+	mountTableShardVersion = 1
+
+	// mountTableShardRetiredKey is written under the prefix of the shards of
+	// a table once it is replaced, with the time it was
+	mountTableShardRetiredKey = "retired"
 )
 
+// mountTableShardSize is the size above which compressed mount, auth and audit
+// tables are split across several storage entries, well under the 512KB
+// value limit of Consul
+var mountTableShardSize = 256 * 1024
+
+// mountTableShardRetention is how long the shards of a replaced table are
+// kept, for the nodes which read that table to be able to read its shards
+var mountTableShardRetention = 5 * time.Minute
+
 // ListingVisibilityType represents the types for listing visibility
 type ListingVisibilityType string
 
@@ -123,6 +147,32 @@ type MountTable struct {
 	Entries []*MountEntry `json:"entries"`
 }
 
+// shardedMountTable is how tables split across shards are stored. Its
+// entries hold the format version and the storage keys of the shards rather
+// than a list, so that older versions of Vault fail to decode sharded tables
+// instead of reading them as empty and storing the default tables over them.
+type shardedMountTable struct {
+	Type    string           `json:"type"`
+	Entries mountTableShards `json:"entries"`
+}
+
+type mountTableShards struct {
+	Version int      `json:"version"`
+	Keys    []string `json:"keys"`
+}
+
+// retiredMountTableShards is stored at mountTableShardRetiredKey
+type retiredMountTableShards struct {
+	Time time.Time `json:"time"`
+}
+
+// storedMountTable decodes the stored tables, whose entries are a list of
+// mount entries, or the mountTableShards of sharded tables
+type storedMountTable struct {
+	Type    string          `json:"type"`
+	Entries json.RawMessage `json:"entries"`
+}
+
 // shallowClone returns a copy of the mount table that
 // keeps the MountEntry locations, so as not to invalidate
 // other locations holding pointers. Care needs to be taken
@@ -323,8 +373,8 @@ func (e *MountEntry) SyncCache() {
 
 func (c *Core) decodeMountTable(ctx context.Context, raw []byte) (*MountTable, error) {
 	// Decode into mount table
-	mountTable := new(MountTable)
-	if err := jsonutil.DecodeJSON(raw, mountTable); err != nil {
+	mountTable, err := c.readMountTable(ctx, raw)
+	if err != nil {
 		return nil, err
 	}
 
@@ -353,6 +403,302 @@ func (c *Core) decodeMountTable(ctx context.Context, raw []byte) (*MountTable, e
 	}, nil
 }
 
+// decodeStoredMountTable decodes the given stored mount, auth or audit table.
+// The entries of sharded tables are not read, the storage keys of their
+// shards are returned instead.
+func decodeStoredMountTable(raw []byte) (*MountTable, []string, error) {
+	stored := new(storedMountTable)
+	if err := jsonutil.DecodeJSON(raw, stored); err != nil {
+		return nil, nil, err
+	}
+
+	mt := &MountTable{
+		Type: stored.Type,
+	}
+	entries := bytes.TrimSpace(stored.Entries)
+	if len(entries) == 0 {
+		return mt, nil, nil
+	}
+	if entries[0] != '{' {
+		if err := jsonutil.DecodeJSONFromReader(bytes.NewReader(entries), &mt.Entries); err != nil {
+			return nil, nil, err
+		}
+		return mt, nil, nil
+	}
+
+	shards := new(mountTableShards)
+	if err := jsonutil.DecodeJSONFromReader(bytes.NewReader(entries), shards); err != nil {
+		return nil, nil, err
+	}
+	if shards.Version != mountTableShardVersion {
+		return nil, nil, fmt.Errorf("unsupported table shard format version %d", shards.Version)
+	}
+	return mt, shards.Keys, nil
+}
+
+// readMountTable decodes the given stored mount, auth or audit table, along
+// with the entries of its shards if it is sharded
+func (c *Core) readMountTable(ctx context.Context, raw []byte) (*MountTable, error) {
+	mt, keys, err := decodeStoredMountTable(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		raw, err := c.barrier.Get(ctx, key)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to read table shard %q: {{err}}", key), err)
+		}
+		if raw == nil {
+			return nil, fmt.Errorf("missing table shard %q", key)
+		}
+
+		shard, _, err := decodeStoredMountTable(raw.Value)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to decode table shard %q: {{err}}", key), err)
+		}
+		mt.Entries = append(mt.Entries, shard.Entries...)
+	}
+	return mt, nil
+}
+
+// writeMountTable encodes, compresses and writes the given mount, auth or
+// audit table to the given path. Tables too large for a single storage entry
+// are split into shards. The shards and the table are written in a single
+// transaction when the storage backend supports it, otherwise the shards are
+// written before the entry at the path which lists them, so that a failed
+// write leaves the previous table in place.
+func (c *Core) writeMountTable(ctx context.Context, mt *MountTable, path string) error {
+	compressedBytes, err := jsonutil.EncodeJSONAndCompress(mt, nil)
+	if err != nil {
+		return errwrap.Wrapf("failed to encode or compress table: {{err}}", err)
+	}
+
+	var entries []*logical.StorageEntry
+	var keys []string
+	if len(compressedBytes) > mountTableShardSize && len(mt.Entries) > 1 {
+		entries, err = c.mountTableShards(mt, path, len(compressedBytes))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+		compressedBytes, err = jsonutil.EncodeJSONAndCompress(&shardedMountTable{
+			Type: mt.Type,
+			Entries: mountTableShards{
+				Version: mountTableShardVersion,
+				Keys:    keys,
+			},
+		}, nil)
+		if err != nil {
+			return errwrap.Wrapf("failed to encode or compress table: {{err}}", err)
+		}
+	}
+	entries = append(entries, &logical.StorageEntry{
+		Key:   path,
+		Value: compressedBytes,
+	})
+
+	// The shards of the tables written before are only deleted once they
+	// have been replaced for mountTableShardRetention, as other nodes may
+	// have read those tables and not their shards yet
+	retired, stale := c.staleMountTableShards(ctx, path, keys)
+	entries = append(entries, retired...)
+
+	if txn, ok := c.physical.(physical.Transactional); ok {
+		err := c.writeMountTableTxn(ctx, txn, entries, stale)
+		if err == nil {
+			return nil
+		}
+		// Backends may limit the size of transactions, e.g. Consul
+		c.logger.Debug("failed to write table in a transaction, writing its entries one by one", "path", path, "error", err)
+	}
+
+	for _, entry := range entries {
+		if err := c.barrier.Put(ctx, entry); err != nil {
+			switch {
+			case entry.Key == path:
+				return err
+			case strings.HasSuffix(entry.Key, "/"+mountTableShardRetiredKey):
+				// The shards are only deleted later on
+				c.logger.Warn("failed to retire table shards", "key", entry.Key, "error", err)
+			default:
+				return errwrap.Wrapf(fmt.Sprintf("failed to write table shard %q: {{err}}", entry.Key), err)
+			}
+		}
+	}
+	// Failures are only logged, as stale shards are never read
+	for _, key := range stale {
+		if err := c.barrier.Delete(ctx, key); err != nil {
+			c.logger.Warn("failed to delete stale table shard", "key", key, "error", err)
+		}
+	}
+	return nil
+}
+
+// writeMountTableTxn writes the given entries, encrypted by the barrier, and
+// deletes the given keys in a single transaction of the physical backend
+func (c *Core) writeMountTableTxn(ctx context.Context, txn physical.Transactional, entries []*logical.StorageEntry, deletes []string) error {
+	txns := make([]*physical.TxnEntry, 0, len(entries)+len(deletes))
+	for _, entry := range entries {
+		value, err := c.barrier.Encrypt(ctx, entry.Key, entry.Value)
+		if err != nil {
+			return err
+		}
+		txns = append(txns, &physical.TxnEntry{
+			Operation: physical.PutOperation,
+			Entry: &physical.Entry{
+				Key:   entry.Key,
+				Value: value,
+			},
+		})
+	}
+	for _, key := range deletes {
+		txns = append(txns, &physical.TxnEntry{
+			Operation: physical.DeleteOperation,
+			Entry: &physical.Entry{
+				Key: key,
+			},
+		})
+	}
+	return txn.Transaction(ctx, txns)
+}
+
+// mountTableShards splits the entries of the given table across as many
+// shards as needed to keep each under mountTableShardSize, given the size of
+// the whole compressed table, and returns the storage entries of the shards
+func (c *Core) mountTableShards(mt *MountTable, path string, size int) ([]*logical.StorageEntry, error) {
+	var values [][]byte
+	for count := size/mountTableShardSize + 1; ; count *= 2 {
+		if count > len(mt.Entries) {
+			count = len(mt.Entries)
+		}
+
+		values = values[:0]
+		fits := true
+		perShard := (len(mt.Entries) + count - 1) / count
+		for i := 0; i < len(mt.Entries); i += perShard {
+			end := i + perShard
+			if end > len(mt.Entries) {
+				end = len(mt.Entries)
+			}
+			value, err := jsonutil.EncodeJSONAndCompress(&MountTable{
+				Type:    mt.Type,
+				Entries: mt.Entries[i:end],
+			}, nil)
+			if err != nil {
+				return nil, errwrap.Wrapf("failed to encode or compress table shard: {{err}}", err)
+			}
+			fits = fits && len(value) <= mountTableShardSize
+			values = append(values, value)
+		}
+
+		// Entries too large on their own are left to the storage backend
+		// to accept or not
+		if fits || count == len(mt.Entries) {
+			break
+		}
+	}
+
+	// Shards of each write go under their own prefix, so that the shards of
+	// the table currently stored are never overwritten
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]*logical.StorageEntry, 0, len(values))
+	for i, value := range values {
+		shards = append(shards, &logical.StorageEntry{
+			Key:   fmt.Sprintf("%s%s%s/%d", path, mountTableShardPrefix, id, i),
+			Value: value,
+		})
+	}
+	return shards, nil
+}
+
+// staleMountTableShards returns the retired markers to write for the shards
+// of the table at the given path which are not the given shards of the table
+// being written, and the keys of the shards retired for longer than
+// mountTableShardRetention, to delete. Failures are only logged, as stale
+// shards are never read.
+func (c *Core) staleMountTableShards(ctx context.Context, path string, shards []string) ([]*logical.StorageEntry, []string) {
+	current := make(map[string]struct{}, len(shards))
+	for _, key := range shards {
+		current[key] = struct{}{}
+	}
+
+	prefix := path + mountTableShardPrefix
+	ids, err := c.barrier.List(ctx, prefix)
+	if err != nil {
+		c.logger.Warn("failed to list table shards", "path", path, "error", err)
+		return nil, nil
+	}
+
+	var retired []*logical.StorageEntry
+	var stale []string
+	for _, id := range ids {
+		keys, err := c.barrier.List(ctx, prefix+id)
+		if err != nil {
+			c.logger.Warn("failed to list table shards", "path", path, "error", err)
+			continue
+		}
+
+		inUse, marked := false, false
+		for _, key := range keys {
+			if _, ok := current[prefix+id+key]; ok {
+				inUse = true
+			}
+			if key == mountTableShardRetiredKey {
+				marked = true
+			}
+		}
+		if inUse || len(keys) == 0 {
+			continue
+		}
+
+		retiredKey := prefix + id + mountTableShardRetiredKey
+		if !marked {
+			value, err := jsonutil.EncodeJSON(&retiredMountTableShards{
+				Time: time.Now(),
+			})
+			if err != nil {
+				c.logger.Warn("failed to encode retired table shards", "key", retiredKey, "error", err)
+				continue
+			}
+			retired = append(retired, &logical.StorageEntry{
+				Key:   retiredKey,
+				Value: value,
+			})
+			continue
+		}
+
+		raw, err := c.barrier.Get(ctx, retiredKey)
+		if err != nil || raw == nil {
+			c.logger.Warn("failed to read retired table shards", "key", retiredKey, "error", err)
+			continue
+		}
+		marker := new(retiredMountTableShards)
+		if err := jsonutil.DecodeJSON(raw.Value, marker); err != nil {
+			c.logger.Warn("failed to decode retired table shards", "key", retiredKey, "error", err)
+			continue
+		}
+		if time.Since(marker.Time) < mountTableShardRetention {
+			continue
+		}
+		// The marker goes last, so that shards left over by a failure are
+		// deleted by the next write
+		for _, key := range keys {
+			if key != mountTableShardRetiredKey {
+				stale = append(stale, prefix+id+key)
+			}
+		}
+		stale = append(stale, retiredKey)
+	}
+	return retired, stale
+}
+
 // Mount is used to mount a new backend to the mount table.
 func (c *Core) mount(ctx context.Context, entry *MountEntry) error {
 	// Ensure we end the path in a slash
@@ -973,21 +1319,8 @@ func (c *Core) persistMounts(ctx context.Context, table *MountTable, local *bool
 	}
 
 	writeTable := func(mt *MountTable, path string) error {
-		// Encode the mount table into JSON and compress it (lzw).
-		compressedBytes, err := jsonutil.EncodeJSONAndCompress(mt, nil)
-		if err != nil {
-			c.logger.Error("failed to encode or compress mount table", "error", err)
-			return err
-		}
-
-		// Create an entry
-		entry := &logical.StorageEntry{
-			Key:   path,
-			Value: compressedBytes,
-		}
-
-		// Write to the physical backend
-		if err := c.barrier.Put(ctx, entry); err != nil {
+		// Write the mount table to the physical backend, sharded if needed
+		if err := c.writeMountTable(ctx, mt, path); err != nil {
 			c.logger.Error("failed to persist mount table", "error", err)
 			return err
 		}
@@ -3,17 +3,22 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-test/deep"
+	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/compressutil"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/hashicorp/vault/sdk/physical/inmem"
 )
 
 func TestMount_ReadOnlyViewDuringMount(t *testing.T) {
@@ -211,6 +216,177 @@ func TestCore_Mount_Local(t *testing.T) {
 	}
 }
 
+// Test that large mount tables are split across shards, which are recombined
+// on load and cleaned up once no longer needed
+func TestCore_MountTable_Sharded(t *testing.T) {
+	oldShardSize, oldRetention := mountTableShardSize, mountTableShardRetention
+	defer func() {
+		mountTableShardSize, mountTableShardRetention = oldShardSize, oldRetention
+	}()
+
+	for name, newPhysical := range map[string]func(map[string]string, log.Logger) (physical.Backend, error){
+		"non-transactional": inmem.NewInmem,
+		"transactional":     inmem.NewTransactionalInmem,
+	} {
+		t.Run(name, func(t *testing.T) {
+			mountTableShardSize, mountTableShardRetention = 1024, oldRetention
+
+			logger := logging.NewVaultLogger(log.Trace)
+			phys, err := newPhysical(nil, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			core, err := NewCore(testCoreConfig(t, phys, logger))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, keys, _ := testCoreUnsealed(t, core)
+
+			for i := 0; i < 20; i++ {
+				me := &MountEntry{
+					Table:       mountTableType,
+					Path:        fmt.Sprintf("kv-%d/", i),
+					Type:        "kv",
+					Description: strings.Repeat(fmt.Sprintf("kv-%d ", i), 20),
+				}
+				if err := c.mount(namespace.RootContext(nil), me); err != nil {
+					t.Fatalf("err: %v", err)
+				}
+			}
+
+			raw, err := c.barrier.Get(context.Background(), coreMountConfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, shards, err := decodeStoredMountTable(raw.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored.Entries) != 0 || len(shards) < 2 {
+				t.Fatalf("expected a sharded mount table, got %d entries and %d shards", len(stored.Entries), len(shards))
+			}
+			for _, key := range shards {
+				shard, err := c.barrier.Get(context.Background(), key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if shard == nil || len(shard.Value) > mountTableShardSize {
+					t.Fatalf("bad shard %q: %#v", key, shard)
+				}
+			}
+
+			// Versions of Vault predating the shards fail to decode the table,
+			// rather than reading it as empty
+			old := &struct {
+				Type    string        `json:"type"`
+				Entries []*MountEntry `json:"entries"`
+			}{}
+			if err := jsonutil.DecodeJSON(raw.Value, old); err == nil {
+				t.Fatal("expected sharded tables to fail decoding as unsharded ones")
+			}
+
+			// Start a second core with same physical
+			conf := &CoreConfig{
+				Physical:     c.physical,
+				DisableMlock: true,
+			}
+			c2, err := NewCore(conf)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			for i, key := range keys {
+				unseal, err := TestCoreUnseal(c2, key)
+				if err != nil {
+					t.Fatalf("err: %v", err)
+				}
+				if i+1 == len(keys) && !unseal {
+					t.Fatalf("should be unsealed")
+				}
+			}
+
+			// Verify matching mount tables
+			if diff := deep.Equal(c.mounts.sortEntriesByPath(), c2.mounts.sortEntriesByPath()); len(diff) > 0 {
+				t.Fatalf("mismatch: %v", diff)
+			}
+
+			// Once the table fits in a single entry again, the shards of the
+			// table it replaces are kept for the nodes still reading them, and
+			// deleted by the writes past the retention period
+			mountTableShardSize = oldShardSize
+			if err := c.unmount(namespace.RootContext(nil), "kv-0/"); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			raw, err = c.barrier.Get(context.Background(), coreMountConfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, newShards, err := decodeStoredMountTable(raw.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var nonLocal int
+			for _, entry := range c.mounts.Entries {
+				if !entry.Local {
+					nonLocal++
+				}
+			}
+			if len(newShards) != 0 || len(stored.Entries) != nonLocal {
+				t.Fatalf("expected an unsharded mount table, got %d entries and %d shards", len(stored.Entries), len(newShards))
+			}
+			for _, key := range shards {
+				if shard, err := c.barrier.Get(context.Background(), key); err != nil || shard == nil {
+					t.Fatalf("expected the shard %q of the previous table to be kept: %v", key, err)
+				}
+			}
+
+			mountTableShardRetention = 0
+			if err := c.unmount(namespace.RootContext(nil), "kv-1/"); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			ids, err := c.barrier.List(context.Background(), coreMountConfigPath+mountTableShardPrefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range ids {
+				shards, err := c.barrier.List(context.Background(), coreMountConfigPath+mountTableShardPrefix+id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(shards) != 0 {
+					t.Fatalf("expected stale shards to be deleted, got %v", shards)
+				}
+			}
+		})
+	}
+}
+
+func TestCore_MountTable_ShardVersion(t *testing.T) {
+	value, err := jsonutil.EncodeJSON(&shardedMountTable{
+		Type: mountTableType,
+		Entries: mountTableShards{
+			Version: mountTableShardVersion + 1,
+			Keys:    []string{coreMountConfigPath + mountTableShardPrefix + "id/0"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := decodeStoredMountTable(value); err == nil || !strings.Contains(err.Error(), "unsupported table shard format version") {
+		t.Fatalf("expected an unsupported version error, got %v", err)
+	}
+
+	// Unsharded tables, with or without entries, are decoded as before
+	for _, value := range []string{`{"type":"mounts","entries":[{"path":"foo/"}]}`, `{"type":"mounts"}`, `{"type":"mounts","entries":null}`} {
+		mt, shards, err := decodeStoredMountTable([]byte(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mt.Type != mountTableType || len(shards) != 0 {
+			t.Fatalf("bad table decoded from %s: %#v", value, mt)
+		}
+	}
+}
+
 func TestCore_Unmount(t *testing.T) {
 	c, keys, _ := TestCoreUnsealed(t)
 	err := c.unmount(namespace.RootContext(nil), "secret")
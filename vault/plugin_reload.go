@@ -190,6 +190,12 @@ func (c *Core) reloadBackendCommon(ctx context.Context, entry *MountEntry, isAut
 		if paths != nil {
 			re.rootPaths.Store(pathsToRadix(paths.Root))
 			re.loginPaths.Store(pathsToRadix(paths.Unauthenticated))
+
+			wellKnown, err := wellKnownPaths(paths.WellKnown)
+			if err != nil {
+				return err
+			}
+			re.wellKnown.Store(wellKnown)
 		}
 	}
 
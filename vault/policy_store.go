@@ -152,6 +152,12 @@ path "sys/tools/random/*" {
     capabilities = ["update"]
 }
 
+# Allow watching kv-v2 secrets; the token must also be able to read the
+# secret
+path "sys/kv-watch" {
+    capabilities = ["read", "update"]
+}
+
 # Allow checking the status of a Control Group request if the user has the
 # accessor
 path "sys/control-group/request" {
@@ -289,6 +295,19 @@ func (c *Core) teardownPolicyStore() error {
 	return nil
 }
 
+// purgeCache drops all the cached policies, to be loaded again from storage
+func (ps *PolicyStore) purgeCache() {
+	ps.modifyLock.Lock()
+	defer ps.modifyLock.Unlock()
+
+	if ps.tokenPoliciesLRU != nil {
+		ps.tokenPoliciesLRU.Purge()
+	}
+	if ps.egpLRU != nil {
+		ps.egpLRU.Purge()
+	}
+}
+
 func (ps *PolicyStore) invalidate(ctx context.Context, name string, policyType PolicyType) {
 	ns, err := namespace.FromContext(ctx)
 	if err != nil {
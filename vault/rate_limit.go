@@ -0,0 +1,320 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimitConfigPath is where the rate limit rules are stored, relative
+	// to the core config view
+	rateLimitConfigPath = "rate-limits"
+
+	// rateLimitSweepInterval is how often idle client buckets are dropped
+	rateLimitSweepInterval = time.Minute
+)
+
+// rateLimitExemptPath is never rate limited, so that rules can't lock
+// operators out of changing them
+const rateLimitExemptPath = "sys/config/rate-limits"
+
+// RateLimitRule limits the request rate of each client to paths under a
+// prefix. Clients are identified by their entity, or by their token accessor
+// if the token has no entity, or by their address for requests without a
+// token. Each client has its own budget, so a single noisy client can't use
+// up the budget of the others.
+type RateLimitRule struct {
+	Name string `json:"name"`
+
+	// Path is the path prefix the rule applies to, including the namespace
+	// path. The rule with the longest matching prefix applies.
+	Path string `json:"path"`
+
+	// Rate is the number of requests per second allowed for each client
+	Rate float64 `json:"rate"`
+
+	// Burst is the number of requests a client can make at once
+	Burst int `json:"burst"`
+}
+
+// RateLimitedError is returned when a request is rejected by a rate limit
+// rule. It carries the time after which a request would be allowed.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return logical.ErrRateLimited.Error()
+}
+
+// RateLimitConfig stores the rate limit rules by name
+type RateLimitConfig struct {
+	Rules map[string]*RateLimitRule `json:"rules"`
+}
+
+type rateLimitBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter applies the rate limit rules to incoming requests
+type rateLimiter struct {
+	sync.Mutex
+
+	// updateLock serializes changes to the rules
+	updateLock sync.Mutex
+
+	rules     map[string]*RateLimitRule
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		rules:   make(map[string]*RateLimitRule),
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+// setRules replaces the rules. Budgets start over, as they may no longer
+// match the rules.
+func (r *rateLimiter) setRules(rules map[string]*RateLimitRule) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.rules = rules
+	r.buckets = make(map[string]*rateLimitBucket)
+}
+
+// copyRules returns a copy of the current rules
+func (r *rateLimiter) copyRules() map[string]*RateLimitRule {
+	r.Lock()
+	defer r.Unlock()
+
+	rules := make(map[string]*RateLimitRule, len(r.rules))
+	for name, rule := range r.rules {
+		ruleCopy := *rule
+		rules[name] = &ruleCopy
+	}
+	return rules
+}
+
+// matchRule returns the rule with the longest prefix matching the path. The
+// lock must be held.
+func (r *rateLimiter) matchRule(path string) *RateLimitRule {
+	var match *RateLimitRule
+	for _, rule := range r.rules {
+		if !strings.HasPrefix(path, rule.Path) {
+			continue
+		}
+		if match == nil || len(rule.Path) > len(match.Path) ||
+			(len(rule.Path) == len(match.Path) && rule.Name < match.Name) {
+			match = rule
+		}
+	}
+	return match
+}
+
+// allow reports whether a request by the client to the path is within its
+// budget, and otherwise how long until it would be
+func (r *rateLimiter) allow(path, client string) (bool, time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(r.rules) == 0 {
+		return true, 0
+	}
+
+	rule := r.matchRule(path)
+	if rule == nil {
+		return true, 0
+	}
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) >= rateLimitSweepInterval {
+		r.sweep(now)
+	}
+
+	key := rule.Name + "\x00" + client
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{
+			limiter: rate.NewLimiter(rate.Limit(rule.Rate), rule.Burst),
+		}
+		r.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops the buckets that have been idle long enough to have refilled,
+// as they are equivalent to new ones. The lock must be held.
+func (r *rateLimiter) sweep(now time.Time) {
+	r.lastSweep = now
+	for key, bucket := range r.buckets {
+		limit := float64(bucket.limiter.Limit())
+		if limit <= 0 {
+			continue
+		}
+		refill := time.Duration(float64(bucket.limiter.Burst()) / limit * float64(time.Second))
+		if now.Sub(bucket.lastSeen) >= refill {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// rateLimitClient returns the key identifying the client making the request
+func rateLimitClient(req *logical.Request, te *logical.TokenEntry) string {
+	switch {
+	case te != nil && te.EntityID != "":
+		return "entity:" + te.EntityID
+	case te != nil && te.Accessor != "":
+		return "accessor:" + te.Accessor
+	case req.Connection != nil && req.Connection.RemoteAddr != "":
+		addr := req.Connection.RemoteAddr
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+		return "addr:" + addr
+	}
+	return ""
+}
+
+// checkRateLimit returns a RateLimitedError if the request is over the
+// budget of its client
+func (c *Core) checkRateLimit(ctx context.Context, req *logical.Request, te *logical.TokenEntry) error {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	path := ns.Path + req.Path
+	if strings.HasPrefix(path, rateLimitExemptPath) {
+		return nil
+	}
+
+	allowed, retryAfter := c.rateLimiter.allow(path, rateLimitClient(req, te))
+	if allowed {
+		return nil
+	}
+	return &RateLimitedError{RetryAfter: retryAfter}
+}
+
+// validateRateLimitRule checks and normalizes a rule
+func validateRateLimitRule(rule *RateLimitRule) error {
+	if rule.Rate <= 0 || math.IsInf(rule.Rate, 0) || math.IsNaN(rule.Rate) {
+		return fmt.Errorf("rate must be a positive number of requests per second")
+	}
+	if rule.Burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	if rule.Burst == 0 {
+		rule.Burst = int(math.Ceil(rule.Rate))
+	}
+	rule.Path = strings.TrimPrefix(rule.Path, "/")
+	return nil
+}
+
+// SetRateLimitRule adds or replaces a rate limit rule
+func (c *Core) SetRateLimitRule(ctx context.Context, rule *RateLimitRule) error {
+	if rule == nil || rule.Name == "" {
+		return fmt.Errorf("rate limit rule must have a name")
+	}
+	if err := validateRateLimitRule(rule); err != nil {
+		return err
+	}
+
+	c.rateLimiter.updateLock.Lock()
+	defer c.rateLimiter.updateLock.Unlock()
+
+	rules := c.rateLimiter.copyRules()
+	rules[rule.Name] = rule
+	if err := c.saveRateLimitConfig(ctx, rules); err != nil {
+		return err
+	}
+
+	c.rateLimiter.setRules(rules)
+	return nil
+}
+
+// DeleteRateLimitRule removes a rate limit rule
+func (c *Core) DeleteRateLimitRule(ctx context.Context, name string) error {
+	c.rateLimiter.updateLock.Lock()
+	defer c.rateLimiter.updateLock.Unlock()
+
+	rules := c.rateLimiter.copyRules()
+	if _, ok := rules[name]; !ok {
+		return nil
+	}
+	delete(rules, name)
+	if err := c.saveRateLimitConfig(ctx, rules); err != nil {
+		return err
+	}
+
+	c.rateLimiter.setRules(rules)
+	return nil
+}
+
+// RateLimitRules returns a copy of the rate limit rules by name
+func (c *Core) RateLimitRules() map[string]*RateLimitRule {
+	return c.rateLimiter.copyRules()
+}
+
+func (c *Core) saveRateLimitConfig(ctx context.Context, rules map[string]*RateLimitRule) error {
+	view := c.systemBarrierView.SubView("config/")
+
+	entry, err := logical.StorageEntryJSON(rateLimitConfigPath, &RateLimitConfig{
+		Rules: rules,
+	})
+	if err != nil {
+		return errwrap.Wrapf("failed to create rate limit config entry: {{err}}", err)
+	}
+
+	if err := view.Put(ctx, entry); err != nil {
+		return errwrap.Wrapf("failed to save rate limit config: {{err}}", err)
+	}
+
+	return nil
+}
+
+// This should only be called with the core state lock held for writing
+func (c *Core) loadRateLimitConfig(ctx context.Context) error {
+	view := c.systemBarrierView.SubView("config/")
+
+	out, err := view.Get(ctx, rateLimitConfigPath)
+	if err != nil {
+		return errwrap.Wrapf("failed to read rate limit config: {{err}}", err)
+	}
+
+	config := new(RateLimitConfig)
+	if out != nil {
+		if err := out.DecodeJSON(config); err != nil {
+			return err
+		}
+	}
+	if config.Rules == nil {
+		config.Rules = make(map[string]*RateLimitRule)
+	}
+
+	c.rateLimiter.setRules(config.Rules)
+	return nil
+}
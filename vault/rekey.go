@@ -358,6 +358,7 @@ func (c *Core) BarrierRekeyUpdate(ctx context.Context, key []byte, nonce string)
 	}
 
 	// Store this key
+	c.lockKey(key)
 	c.barrierRekeyConfig.RekeyProgress = append(c.barrierRekeyConfig.RekeyProgress, key)
 
 	// Check if we don't have enough keys to unlock
@@ -380,6 +381,7 @@ func (c *Core) BarrierRekeyUpdate(ctx context.Context, key []byte, nonce string)
 			return nil, logical.CodedError(http.StatusInternalServerError, errwrap.Wrapf("failed to compute master key: {{err}}", err).Error())
 		}
 	}
+	c.lockKey(recoveredKey)
 
 	if useRecovery {
 		if err := c.seal.VerifyRecoveryKey(ctx, recoveredKey); err != nil {
@@ -588,6 +590,7 @@ func (c *Core) RecoveryRekeyUpdate(ctx context.Context, key []byte, nonce string
 	}
 
 	// Store this key
+	c.lockKey(key)
 	c.recoveryRekeyConfig.RekeyProgress = append(c.recoveryRekeyConfig.RekeyProgress, key)
 
 	// Check if we don't have enough keys to unlock
@@ -610,6 +613,7 @@ func (c *Core) RecoveryRekeyUpdate(ctx context.Context, key []byte, nonce string
 			return nil, logical.CodedError(http.StatusInternalServerError, errwrap.Wrapf("failed to compute recovery key: {{err}}", err).Error())
 		}
 	}
+	c.lockKey(recoveryKey)
 
 	// Verify the recovery key
 	if err := c.seal.VerifyRecoveryKey(ctx, recoveryKey); err != nil {
@@ -784,6 +788,7 @@ func (c *Core) RekeyVerify(ctx context.Context, key []byte, nonce string, recove
 	}
 
 	// Store this key
+	c.lockKey(key)
 	config.VerificationProgress = append(config.VerificationProgress, key)
 
 	// Check if we don't have enough keys to unlock
@@ -821,6 +826,7 @@ func (c *Core) RekeyVerify(ctx context.Context, key []byte, nonce string, recove
 			return nil, logical.CodedError(http.StatusInternalServerError, errwrap.Wrapf("failed to compute key for verification: {{err}}", err).Error())
 		}
 	}
+	c.lockKey(recoveredKey)
 
 	if subtle.ConstantTimeCompare(recoveredKey, config.VerificationKey) != 1 {
 		c.logger.Error("rekey verification failed")
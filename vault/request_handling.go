@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/errutil"
@@ -44,6 +46,10 @@ type HandlerProperties struct {
 	MaxRequestSize        int64
 	MaxRequestDuration    time.Duration
 	DisablePrintableCheck bool
+
+	// CustomResponseHeaders are added to responses, keyed by "default", a
+	// status code such as "404", or a status class such as "4xx"
+	CustomResponseHeaders map[string]http.Header
 }
 
 // fetchEntityAndDerivedPolicies returns the entity object for the given entity
@@ -417,6 +423,10 @@ func (c *Core) switchedLockHandleRequest(httpCtx context.Context, req *logical.R
 		return nil, errwrap.Wrapf("could not parse namespace from http context: {{err}}", err)
 	}
 	ctx = namespace.ContextWithNamespace(ctx, ns)
+	ctx = tracing.CopyContext(ctx, httpCtx)
+	if doLocking {
+		ctx = context.WithValue(ctx, stateLockHeldKey{}, true)
+	}
 
 	resp, err = c.handleCancelableRequest(ctx, ns, req)
 
@@ -455,6 +465,10 @@ func (c *Core) handleCancelableRequest(ctx context.Context, ns *namespace.Namesp
 		resp, auth, err = c.handleRequest(ctx, req)
 	}
 
+	if err == nil && (resp == nil || !resp.IsError()) {
+		c.notifyKVWatches(ctx, req)
+	}
+
 	// Ensure we don't leak internal data
 	if resp != nil {
 		if resp.Secret != nil {
@@ -595,6 +609,13 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 		return nil, nil, ctErr
 	}
 
+	// Reject the request before it uses the token or reaches the backend if
+	// its client is over budget
+	if err := c.checkRateLimit(ctx, req, te); err != nil {
+		retErr = multierror.Append(retErr, err)
+		return nil, nil, retErr
+	}
+
 	// We run this logic first because we want to decrement the use count even
 	// in the case of an error (assuming we can successfully look up; if we
 	// need to forward, we exit before now)
@@ -930,6 +951,11 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 	if ctErr == logical.ErrPerfStandbyPleaseForward {
 		return nil, nil, ctErr
 	}
+
+	if err := c.checkRateLimit(ctx, req, nil); err != nil {
+		retErr = multierror.Append(retErr, err)
+		return nil, nil, retErr
+	}
 	if ctErr != nil {
 		// If it is an internal error we return that, otherwise we
 		// return invalid request so that the status codes can be correct
@@ -1037,6 +1063,10 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 
 		mEntry := c.router.MatchingMountEntry(ctx, req.Path)
 
+		if auth.Alias != nil {
+			logical.SetStandardAliasMetadata(auth.Alias, req)
+		}
+
 		if auth.Alias != nil &&
 			mEntry != nil &&
 			!mEntry.Local &&
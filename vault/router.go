@@ -10,11 +10,15 @@ import (
 
 	metrics "github.com/armon/go-metrics"
 	radix "github.com/armon/go-radix"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/tracing"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"go.opencensus.io/trace"
 )
 
 var (
@@ -34,6 +38,9 @@ type Router struct {
 	// to the backend. This is used to map a key back into the backend that owns it.
 	// For example, logical/uuid1/foobar -> secrets/ (kv backend) + foobar
 	storagePrefix *radix.Tree
+	// pathLabeler labels the path of the request metrics, which are only
+	// emitted when it is enabled
+	pathLabeler *metricsutil.PathLabeler
 }
 
 // NewRouter returns a new router
@@ -56,6 +63,7 @@ type routeEntry struct {
 	storagePrefix string
 	rootPaths     atomic.Value
 	loginPaths    atomic.Value
+	wellKnown     atomic.Value
 	l             sync.RWMutex
 }
 
@@ -130,6 +138,12 @@ func (r *Router) Mount(backend logical.Backend, prefix string, mountEntry *Mount
 	re.rootPaths.Store(pathsToRadix(paths.Root))
 	re.loginPaths.Store(pathsToRadix(paths.Unauthenticated))
 
+	wellKnown, err := wellKnownPaths(paths.WellKnown)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("mount_path: %q, mount_type: %q: {{err}}", mountEntry.Path, mountEntry.Type), err)
+	}
+	re.wellKnown.Store(wellKnown)
+
 	switch {
 	case prefix == "":
 		return fmt.Errorf("missing prefix to be used for router entry; mount_path: %q, mount_type: %q", re.mountEntry.Path, re.mountEntry.Type)
@@ -205,6 +219,54 @@ func (r *Router) Remount(ctx context.Context, src, dst string) error {
 	return nil
 }
 
+// WellKnownRedirect returns the API path serving the given path under the
+// listener's /.well-known/ prefix, if a mount claims its first segment. When
+// more than one mount claims the same label, the one with the lowest mount
+// path, in lexical order, serves it.
+func (r *Router) WellKnownRedirect(path string) (string, bool) {
+	label, rest := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		label, rest = path[:i], path[i+1:]
+	}
+
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	var target string
+	r.root.Walk(func(prefix string, raw interface{}) bool {
+		re := raw.(*routeEntry)
+		if re.tainted {
+			return false
+		}
+		wellKnown, _ := re.wellKnown.Load().(map[string]string)
+		path, ok := wellKnown[label]
+		if !ok {
+			return false
+		}
+
+		target = prefix + strings.Trim(path, "/")
+		if rest != "" {
+			target = strings.TrimSuffix(target, "/") + "/" + rest
+		}
+		return true
+	})
+
+	return target, target != ""
+}
+
+// wellKnownPaths validates the well-known labels claimed by a backend and
+// returns a copy of them
+func wellKnownPaths(paths map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(paths))
+	for label, path := range paths {
+		if label == "" || strings.Contains(label, "/") {
+			return nil, fmt.Errorf("invalid well-known label %q", label)
+		}
+		out[label] = path
+	}
+	return out, nil
+}
+
 // Taint is used to mark a path as tainted. This means only RollbackOperation
 // RevokeOperation requests are allowed to proceed
 func (r *Router) Taint(ctx context.Context, path string) error {
@@ -439,6 +501,36 @@ func (r *Router) MatchingAPIPrefixByStoragePath(ctx context.Context, path string
 	return me.Namespace(), mountPath, prefix, found
 }
 
+// InvalidateStorageKey notifies the backend whose storage holds the given
+// storage path, if any, that the key was modified outside of the backend, e.g.
+// through sys/raw, so that it can drop the state it cached for the key. The
+// key is given to the backend relative to its storage view. It returns whether
+// a backend was notified.
+func (r *Router) InvalidateStorageKey(ctx context.Context, path string) bool {
+	r.l.RLock()
+	prefix, raw, ok := r.storagePrefix.LongestPrefix(path)
+	r.l.RUnlock()
+	if !ok {
+		return false
+	}
+
+	re := raw.(*routeEntry)
+
+	// Grab a read lock on the route entry, as for requests, to not race with
+	// reloads of the backend
+	re.l.RLock()
+	defer re.l.RUnlock()
+
+	// Filtered mounts will have a nil backend
+	if re.backend == nil {
+		return false
+	}
+
+	key := strings.TrimPrefix(path, prefix)
+	re.backend.InvalidateKey(namespace.ContextWithNamespace(ctx, re.mountEntry.Namespace()), key)
+	return true
+}
+
 func (r *Router) matchingMountEntryByPath(ctx context.Context, path string, apiPath bool) (*MountEntry, string, bool) {
 	var raw interface{}
 	var ok bool
@@ -495,8 +587,21 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 	req.Path = adjustedPath
 	defer metrics.MeasureSince([]string{"route", string(req.Operation),
 		strings.Replace(mount, "/", "-", -1)}, time.Now())
+	if r.pathLabeler.Enabled() {
+		defer metrics.MeasureSinceWithLabels([]string{"route", "path"}, time.Now(), []metrics.Label{
+			{Name: "operation", Value: string(req.Operation)},
+			{Name: "mount", Value: mount},
+			{Name: "path", Value: r.pathLabeler.Label(mount, ns.Path+req.Path)},
+		})
+	}
 	re := raw.(*routeEntry)
 
+	ctx, span := tracing.StartSpan(ctx, "router.route",
+		trace.StringAttribute("vault.operation", string(req.Operation)),
+		trace.StringAttribute("vault.mount_point", mount),
+	)
+	defer span.End()
+
 	// Grab a read lock on the route entry, this protects against the backend
 	// being reloaded during a request. The exception is a renew request on the
 	// token store; such a request will have already been routed through the
@@ -662,7 +767,12 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 		ok, exists, err := re.backend.HandleExistenceCheck(ctx, req)
 		return nil, ok, exists, err
 	} else {
-		resp, err := re.backend.HandleRequest(ctx, req)
+		backendCtx, backendSpan := tracing.StartSpan(ctx, "backend.handle_request",
+			trace.StringAttribute("vault.mount_type", re.mountEntry.Type),
+			trace.StringAttribute("vault.mount_point", mount),
+		)
+		resp, err := re.backend.HandleRequest(backendCtx, req)
+		tracing.EndSpan(backendSpan, err)
 		if resp != nil {
 			if len(allowedResponseHeaders) > 0 {
 				resp.Headers = filteredHeaders(resp.Headers, allowedResponseHeaders, nil)
@@ -22,6 +22,7 @@ type NoopBackend struct {
 
 	Root            []string
 	Login           []string
+	WellKnown       map[string]string
 	Paths           []string
 	Requests        []*logical.Request
 	Response        *logical.Response
@@ -64,6 +65,7 @@ func (n *NoopBackend) SpecialPaths() *logical.Paths {
 	return &logical.Paths{
 		Root:            n.Root,
 		Unauthenticated: n.Login,
+		WellKnown:       n.WellKnown,
 	}
 }
 
@@ -336,6 +338,36 @@ func TestRouter_Unmount(t *testing.T) {
 	}
 }
 
+func TestRouter_InvalidateStorageKey(t *testing.T) {
+	r := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &NoopBackend{}
+	err = r.Mount(n, "prod/aws/", &MountEntry{Path: "prod/aws/", UUID: meUUID, Accessor: "awsaccessor", NamespaceID: namespace.RootNamespaceID, namespace: namespace.RootNamespace}, view)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !r.InvalidateStorageKey(namespace.RootContext(nil), "logical/foo/bar") {
+		t.Fatalf("should have invalidated the key")
+	}
+	if !reflect.DeepEqual(n.Invalidations, []string{"foo/bar"}) {
+		t.Fatalf("bad: %v", n.Invalidations)
+	}
+
+	if r.InvalidateStorageKey(namespace.RootContext(nil), "unknown/foo") {
+		t.Fatalf("should not have invalidated the key")
+	}
+	if len(n.Invalidations) != 1 {
+		t.Fatalf("bad: %v", n.Invalidations)
+	}
+}
+
 func TestRouter_Remount(t *testing.T) {
 	r := NewRouter()
 	_, barrier, _ := mockBarrier(t)
@@ -476,6 +508,59 @@ func TestRouter_LoginPath(t *testing.T) {
 	}
 }
 
+func TestRouter_WellKnownRedirect(t *testing.T) {
+	r := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+
+	mount := func(path string, wellKnown map[string]string) {
+		t.Helper()
+		meUUID, err := uuid.GenerateUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := &NoopBackend{WellKnown: wellKnown}
+		view := NewBarrierView(barrier, "logical/"+meUUID+"/")
+		err = r.Mount(n, path, &MountEntry{UUID: meUUID, Accessor: meUUID, NamespaceID: namespace.RootNamespaceID, namespace: namespace.RootNamespace}, view)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	mount("pki-b/", map[string]string{"acme": "acme/"})
+	mount("pki-a/", map[string]string{"acme": "acme/", "est": "est"})
+	mount("auth/oidc/", map[string]string{"openid-configuration": "/.well-known/openid-configuration"})
+
+	tcases := map[string]string{
+		"acme":                 "pki-a/acme",
+		"acme/new-nonce":       "pki-a/acme/new-nonce",
+		"est/simpleenroll":     "pki-a/est/simpleenroll",
+		"openid-configuration": "auth/oidc/.well-known/openid-configuration",
+		"unknown":              "",
+	}
+	for path, expected := range tcases {
+		out, ok := r.WellKnownRedirect(path)
+		if out != expected || ok != (expected != "") {
+			t.Fatalf("bad: path: %s expect: %q got %q", path, expected, out)
+		}
+	}
+
+	// Once unmounted, the label falls back to the next mount claiming it
+	if err := r.Unmount(namespace.RootContext(nil), "pki-a/"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := r.WellKnownRedirect("acme/new-nonce"); out != "pki-b/acme/new-nonce" {
+		t.Fatalf("bad: %q", out)
+	}
+	if _, ok := r.WellKnownRedirect("est"); ok {
+		t.Fatal("expected no redirect for unclaimed label")
+	}
+
+	n := &NoopBackend{WellKnown: map[string]string{"bad/label": "foo"}}
+	err := r.Mount(n, "bad/", &MountEntry{UUID: "bad", Accessor: "bad", NamespaceID: namespace.RootNamespaceID, namespace: namespace.RootNamespace}, NewBarrierView(barrier, "logical/bad/"))
+	if err == nil {
+		t.Fatal("expected error for invalid label")
+	}
+}
+
 func TestRouter_Taint(t *testing.T) {
 	r := NewRouter()
 	_, barrier, _ := mockBarrier(t)
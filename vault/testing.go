@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/tls"
@@ -42,6 +41,7 @@ import (
 	dbMysql "github.com/hashicorp/vault/plugins/database/mysql"
 	dbPostgres "github.com/hashicorp/vault/plugins/database/postgresql"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/helper/salt"
@@ -166,6 +166,7 @@ func TestCoreWithSealAndUI(t testing.T, opts *CoreConfig) *Core {
 	for k, v := range opts.AuditBackends {
 		conf.AuditBackends[k] = v
 	}
+	conf.ManagedKeyTypes = opts.ManagedKeyTypes
 
 	c, err := NewCore(conf)
 	if err != nil {
@@ -1052,10 +1053,11 @@ func NewTestCluster(t testing.T, base *CoreConfig, opts *TestClusterOptions) *Te
 	if opts != nil && opts.CAKey != nil {
 		caKey = opts.CAKey
 	} else {
-		caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-		if err != nil {
+		var keyBundle certutil.ParsedCSRBundle
+		if err := certutil.GeneratePrivateKey(certutil.ECPrivateKey, 256, &keyBundle); err != nil {
 			t.Fatal(err)
 		}
+		caKey = keyBundle.PrivateKey.(*ecdsa.PrivateKey)
 	}
 	testCluster.CAKey = caKey
 	var caBytes []byte
@@ -1117,46 +1119,30 @@ func NewTestCluster(t testing.T, base *CoreConfig, opts *TestClusterOptions) *Te
 	//
 	// Certs generation
 	//
+	caBundle := &certutil.ParsedCertBundle{
+		PrivateKeyType:   certutil.ECPrivateKey,
+		PrivateKeyFormat: certutil.ECBlock,
+		PrivateKeyBytes:  marshaledCAKey,
+		PrivateKey:       caKey,
+		CertificateBytes: caBytes,
+		Certificate:      caCert,
+	}
 	for i := 0; i < numCores; i++ {
-		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-		if err != nil {
-			t.Fatal(err)
-		}
-		certTemplate := &x509.Certificate{
-			Subject: pkix.Name{
-				CommonName: "localhost",
-			},
-			DNSNames:    []string{"localhost"},
-			IPAddresses: certIPs,
-			ExtKeyUsage: []x509.ExtKeyUsage{
-				x509.ExtKeyUsageServerAuth,
-				x509.ExtKeyUsageClientAuth,
-			},
-			KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement,
-			SerialNumber: big.NewInt(mathrand.Int63()),
-			NotBefore:    time.Now().Add(-30 * time.Second),
-			NotAfter:     time.Now().Add(262980 * time.Hour),
-		}
-		certBytes, err := x509.CreateCertificate(rand.Reader, certTemplate, caCert, key.Public(), caKey)
-		if err != nil {
-			t.Fatal(err)
-		}
-		cert, err := x509.ParseCertificate(certBytes)
+		memberBundle, err := certutil.GenerateMemberBundle(caBundle, []string{"localhost"}, certIPs, 262980*time.Hour)
 		if err != nil {
 			t.Fatal(err)
 		}
+		cert := memberBundle.Certificate
+		certBytes := memberBundle.CertificateBytes
+		key := memberBundle.PrivateKey.(*ecdsa.PrivateKey)
 		certPEMBlock := &pem.Block{
 			Type:  "CERTIFICATE",
 			Bytes: certBytes,
 		}
 		certPEM := pem.EncodeToMemory(certPEMBlock)
-		marshaledKey, err := x509.MarshalECPrivateKey(key)
-		if err != nil {
-			t.Fatal(err)
-		}
 		keyPEMBlock := &pem.Block{
 			Type:  "EC PRIVATE KEY",
-			Bytes: marshaledKey,
+			Bytes: memberBundle.PrivateKeyBytes,
 		}
 		keyPEM := pem.EncodeToMemory(keyPEMBlock)
 
@@ -1312,6 +1298,7 @@ func NewTestCluster(t testing.T, base *CoreConfig, opts *TestClusterOptions) *Te
 				coreConfig.AuditBackends[k] = v
 			}
 		}
+		coreConfig.ManagedKeyTypes = base.ManagedKeyTypes
 		if base.Logger != nil {
 			coreConfig.Logger = base.Logger
 		}
@@ -403,6 +403,29 @@ func (ts *TokenStore) paths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(tokenRenewHelp),
 		},
 
+		{
+			Pattern: "renew-accessor",
+
+			Fields: map[string]*framework.FieldSchema{
+				"accessor": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Accessor of the token to renew (request body)",
+				},
+				"increment": &framework.FieldSchema{
+					Type:        framework.TypeDurationSecond,
+					Default:     0,
+					Description: "The desired increment in seconds to the token expiration",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: ts.handleUpdateRenewAccessor,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(tokenRenewAccessorHelp),
+			HelpDescription: strings.TrimSpace(tokenRenewAccessorHelp),
+		},
+
 		{
 			Pattern: "tidy$",
 
@@ -479,6 +502,10 @@ type TokenStore struct {
 
 	tidyLock *uint32
 
+	// gaugedPolicies are the policies live token gauges were last emitted
+	// for, so that gauges of policies without tokens anymore can be reset
+	gaugedPolicies map[string]struct{}
+
 	identityPoliciesDeriverFunc func(string) (*identity.Entity, []string, error)
 
 	quitContext context.Context
@@ -544,12 +571,18 @@ func (ts *TokenStore) Invalidate(ctx context.Context, key string) {
 
 	switch key {
 	case tokenSubPath + salt.DefaultLocation:
-		ts.saltLock.Lock()
-		ts.salts = make(map[string]*salt.Salt)
-		ts.saltLock.Unlock()
+		ts.purgeSalts()
 	}
 }
 
+// purgeSalts drops the cached salts of the namespaces, to be loaded again
+// from storage
+func (ts *TokenStore) purgeSalts() {
+	ts.saltLock.Lock()
+	ts.salts = make(map[string]*salt.Salt)
+	ts.saltLock.Unlock()
+}
+
 func (ts *TokenStore) Salt(ctx context.Context) (*salt.Salt, error) {
 	ns, err := namespace.FromContext(ctx)
 	if err != nil {
@@ -670,6 +703,44 @@ func (ts *TokenStore) rootToken(ctx context.Context) (*logical.TokenEntry, error
 	return te, nil
 }
 
+// drOperationTokenTTL bounds the lifetime of DR operation tokens that are
+// never used
+const drOperationTokenTTL = 30 * time.Minute
+
+// drOperationToken is used to generate a token with the root policy that is
+// revoked after a single use, or once drOperationTokenTTL has passed
+func (ts *TokenStore) drOperationToken(ctx context.Context) (*logical.TokenEntry, error) {
+	ctx = namespace.ContextWithNamespace(ctx, namespace.RootNamespace)
+	te := &logical.TokenEntry{
+		Policies:     []string{"root"},
+		Path:         "auth/token/root",
+		DisplayName:  "dr-operation",
+		CreationTime: time.Now().Unix(),
+		NamespaceID:  namespace.RootNamespaceID,
+		Type:         logical.TokenTypeService,
+		NumUses:      1,
+		TTL:          drOperationTokenTTL,
+	}
+	if err := ts.create(ctx, te); err != nil {
+		return nil, err
+	}
+
+	auth := &logical.Auth{
+		ClientToken: te.ID,
+		Policies:    te.Policies,
+		LeaseOptions: logical.LeaseOptions{
+			TTL:       te.TTL,
+			Renewable: false,
+		},
+	}
+	if err := ts.expiration.RegisterAuth(ctx, te, auth); err != nil {
+		// Revoke since it's not yet being tracked for expiration
+		ts.revokeOrphan(ctx, te.ID)
+		return nil, errwrap.Wrapf("failed to register DR operation token lease: {{err}}", err)
+	}
+	return te, nil
+}
+
 func (ts *TokenStore) tokenStoreAccessorList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	ns, err := namespace.FromContext(ctx)
 	if err != nil {
@@ -826,7 +897,12 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 			return err
 		}
 
-		return ts.storeCommon(ctx, entry, true)
+		if err := ts.storeCommon(ctx, entry, true); err != nil {
+			return err
+		}
+
+		ts.countTokenCreation(ctx, entry)
+		return nil
 
 	case logical.TokenTypeBatch:
 		// Ensure fields we don't support/care about are nilled, proto marshal,
@@ -869,6 +945,7 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 			entry.ID = fmt.Sprintf("%s.%s", entry.ID, tokenNS.ID)
 		}
 
+		ts.countTokenCreation(ctx, entry)
 		return nil
 
 	default:
@@ -2058,6 +2135,56 @@ func (ts *TokenStore) handleUpdateRevokeAccessor(ctx context.Context, req *logic
 	return nil, nil
 }
 
+// handleUpdateRenewAccessor handles the auth/token/renew-accessor path for
+// renewing the token associated with the accessor
+func (ts *TokenStore) handleUpdateRenewAccessor(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	accessor := data.Get("accessor").(string)
+	if accessor == "" {
+		return nil, &logical.StatusBadRequest{Err: "missing accessor"}
+	}
+
+	aEntry, err := ts.lookupByAccessor(ctx, accessor, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the field data required for a renew call
+	d := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"token":     aEntry.TokenID,
+			"increment": data.Get("increment"),
+		},
+		Schema: map[string]*framework.FieldSchema{
+			"token": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Token to renew",
+			},
+			"increment": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Default:     0,
+				Description: "The desired increment in seconds to the token expiration",
+			},
+		},
+	}
+	resp, err := ts.handleRenew(ctx, req, d)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("failed to renew the token")
+	}
+	if resp.IsError() {
+		return resp, nil
+	}
+
+	// Remove the token ID from the response
+	if resp.Auth != nil {
+		resp.Auth.ClientToken = ""
+	}
+
+	return resp, nil
+}
+
 // handleCreate handles the auth/token/create path for creation of new orphan
 // tokens
 func (ts *TokenStore) handleCreateOrphan(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -3179,6 +3306,7 @@ as revocation of tokens. The tokens are renewable if associated with a lease.`
 	tokenRevokeSelfHelp      = `This endpoint will delete the token used to call it and all of its child tokens.`
 	tokenRevokeOrphanHelp    = `This endpoint will delete the token and orphan its child tokens.`
 	tokenRenewHelp           = `This endpoint will renew the given token and prevent expiration.`
+	tokenRenewAccessorHelp   = `This endpoint will renew the token associated with the accessor and prevent expiration.`
 	tokenRenewSelfHelp       = `This endpoint will renew the token used to call it and prevent expiration.`
 	tokenAllowedPoliciesHelp = `If set, tokens can be created with any subset of the policies in this
 list, rather than the normal semantics of tokens being a subset of the
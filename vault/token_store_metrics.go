@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"context"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// tokenGaugeInterval is how often the live token gauges are computed.
+	// Computing them requires reading every token from storage.
+	tokenGaugeInterval = 10 * time.Minute
+)
+
+// tokenTTLBuckets are the upper bounds of the TTL buckets tokens are counted
+// in when created. Tokens with a TTL larger than the last bucket, or without
+// a TTL, are counted in the "+Inf" bucket.
+var tokenTTLBuckets = []struct {
+	ttl   time.Duration
+	label string
+}{
+	{time.Minute, "1m"},
+	{10 * time.Minute, "10m"},
+	{20 * time.Minute, "20m"},
+	{time.Hour, "1h"},
+	{2 * time.Hour, "2h"},
+	{24 * time.Hour, "1d"},
+	{2 * 24 * time.Hour, "2d"},
+	{7 * 24 * time.Hour, "7d"},
+	{30 * 24 * time.Hour, "30d"},
+}
+
+// tokenTTLBucket returns the label of the bucket a token with the given TTL
+// is counted in
+func tokenTTLBucket(ttl time.Duration) string {
+	if ttl <= 0 {
+		return "+Inf"
+	}
+	for _, bucket := range tokenTTLBuckets {
+		if ttl <= bucket.ttl {
+			return bucket.label
+		}
+	}
+	return "+Inf"
+}
+
+// countTokenCreation emits the token creation counter for the entry, by the
+// type of the auth method that created it, the token type and TTL bucket
+func (ts *TokenStore) countTokenCreation(ctx context.Context, entry *logical.TokenEntry) {
+	authMethod := "unknown"
+	if mEntry := ts.core.router.MatchingMountEntry(ctx, entry.Path); mEntry != nil {
+		authMethod = mEntry.Type
+	}
+
+	metrics.IncrCounterWithLabels([]string{"token", "creation"}, 1, []metrics.Label{
+		{Name: "auth_method", Value: authMethod},
+		{Name: "token_type", Value: entry.Type.String()},
+		{Name: "creation_ttl", Value: tokenTTLBucket(entry.TTL)},
+	})
+}
+
+// countLiveTokens returns the number of live service tokens in the namespace
+// and how many of them have each policy. Batch tokens are not stored, so
+// they can't be counted.
+func (ts *TokenStore) countLiveTokens(ctx context.Context, ns *namespace.Namespace) (int, map[string]int, error) {
+	view := ts.idView(ns)
+	saltedIDs, err := view.List(ctx, "")
+	if err != nil {
+		return 0, nil, errwrap.Wrapf("failed to list tokens: {{err}}", err)
+	}
+
+	total := 0
+	byPolicy := make(map[string]int)
+	for _, saltedID := range saltedIDs {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+
+		raw, err := view.Get(ctx, saltedID)
+		if err != nil {
+			return 0, nil, errwrap.Wrapf("failed to read token: {{err}}", err)
+		}
+		if raw == nil {
+			continue
+		}
+
+		entry := new(logical.TokenEntry)
+		if err := jsonutil.DecodeJSON(raw.Value, entry); err != nil {
+			return 0, nil, errwrap.Wrapf("failed to decode token: {{err}}", err)
+		}
+
+		// Skip tokens awaiting revocation
+		if entry.NumUses < 0 {
+			continue
+		}
+
+		total++
+		for _, policy := range entry.Policies {
+			byPolicy[policy]++
+		}
+	}
+
+	return total, byPolicy, nil
+}
+
+// emitTokenGauges emits the number of live service tokens, in total and by
+// policy. It must not be called concurrently.
+func (ts *TokenStore) emitTokenGauges(ctx context.Context) error {
+	total, byPolicy, err := ts.countLiveTokens(ctx, namespace.RootNamespace)
+	if err != nil {
+		return err
+	}
+
+	// Policies without tokens anymore are reset to zero, otherwise some
+	// sinks keep reporting their last value
+	for policy := range ts.gaugedPolicies {
+		if _, ok := byPolicy[policy]; !ok {
+			byPolicy[policy] = 0
+		}
+	}
+
+	gaugedPolicies := make(map[string]struct{}, len(byPolicy))
+	metrics.SetGauge([]string{"token", "count"}, float32(total))
+	for policy, count := range byPolicy {
+		metrics.SetGaugeWithLabels([]string{"token", "count", "by_policy"}, float32(count), []metrics.Label{
+			{Name: "policy", Value: policy},
+		})
+		if count > 0 {
+			gaugedPolicies[policy] = struct{}{}
+		}
+	}
+	ts.gaugedPolicies = gaugedPolicies
+
+	return nil
+}
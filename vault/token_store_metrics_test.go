@@ -0,0 +1,69 @@
+package vault
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/namespace"
+)
+
+func TestTokenStore_TokenTTLBucket(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                   "+Inf",
+		30 * time.Second:    "1m",
+		time.Minute:         "1m",
+		time.Hour:           "1h",
+		90 * time.Minute:    "2h",
+		32 * 24 * time.Hour: "+Inf",
+	}
+	for ttl, expected := range cases {
+		if actual := tokenTTLBucket(ttl); actual != expected {
+			t.Fatalf("%s: expected %q, got %q", ttl, expected, actual)
+		}
+	}
+}
+
+func TestTokenStore_CountLiveTokens(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	testMakeServiceTokenViaCore(t, c, root, "client1", "1h", []string{"foo"})
+	testMakeServiceTokenViaCore(t, c, root, "client2", "1h", []string{"foo", "bar"})
+	testMakeBatchTokenViaCore(t, c, root, "", "1h", []string{"foo"})
+
+	total, byPolicy, err := ts.countLiveTokens(namespace.RootContext(nil), namespace.RootNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The root token and the two service tokens, batch tokens are not stored
+	if total != 3 {
+		t.Fatalf("bad: %d", total)
+	}
+	expected := map[string]int{
+		"root":    1,
+		"default": 2,
+		"foo":     2,
+		"bar":     1,
+	}
+	if !reflect.DeepEqual(byPolicy, expected) {
+		t.Fatalf("bad: %#v", byPolicy)
+	}
+
+	if err := ts.emitTokenGauges(namespace.RootContext(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.gaugedPolicies["bar"]; !ok {
+		t.Fatalf("bad: %#v", ts.gaugedPolicies)
+	}
+
+	// Counting stops once the context is cancelled, e.g. on seal
+	ctx, cancel := context.WithCancel(namespace.RootContext(nil))
+	cancel()
+	if _, _, err := ts.countLiveTokens(ctx, namespace.RootNamespace); !errwrap.Contains(err, context.Canceled.Error()) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+}
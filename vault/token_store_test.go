@@ -2612,6 +2612,63 @@ func TestTokenStore_HandleRequest_Renew(t *testing.T) {
 	}
 }
 
+func TestTokenStore_HandleRequest_RenewAccessor(t *testing.T) {
+	exp := mockExpiration(t)
+	ts := exp.tokenStore
+
+	// Create new token
+	root, err := ts.rootToken(namespace.RootContext(nil))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create a new token
+	auth := &logical.Auth{
+		ClientToken: root.ID,
+		LeaseOptions: logical.LeaseOptions{
+			TTL:       time.Hour,
+			Renewable: true,
+		},
+	}
+	err = exp.RegisterAuth(namespace.RootContext(nil), root, auth)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Get the original expire time to compare
+	originalExpire := auth.ExpirationTime()
+
+	beforeRenew := time.Now()
+	req := logical.TestRequest(t, logical.UpdateOperation, "renew-accessor")
+	req.Data = map[string]interface{}{
+		"accessor":  root.Accessor,
+		"increment": "3600s",
+	}
+	resp, err := ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+
+	// The token ID should not be returned
+	if resp.Auth.ClientToken != "" {
+		t.Fatalf("bad: %#v", resp.Auth)
+	}
+
+	// Get the new expire time
+	newExpire := resp.Auth.ExpirationTime()
+	if newExpire.Before(originalExpire) {
+		t.Fatalf("should expire later: %s %s", newExpire, originalExpire)
+	}
+	if newExpire.Before(beforeRenew.Add(time.Hour)) {
+		t.Fatalf("should have at least an hour: %s %s", newExpire, beforeRenew)
+	}
+
+	req.Data["accessor"] = "invalid"
+	if _, err := ts.HandleRequest(namespace.RootContext(nil), req); err == nil {
+		t.Fatal("expected error renewing an invalid accessor")
+	}
+}
+
 func TestTokenStore_HandleRequest_RenewSelf(t *testing.T) {
 	exp := mockExpiration(t)
 	ts := exp.tokenStore
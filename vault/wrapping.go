@@ -250,7 +250,11 @@ DONELISTHANDLING:
 		}
 	}
 
-	cubbyResp, err := c.router.Route(ctx, cubbyReq)
+	// The wrapping token's cubbyhole only holds the wrapped response, which is
+	// already bounded by the request size limits
+	cubbyCtx := withoutCubbyholeLimits(ctx)
+
+	cubbyResp, err := c.router.Route(cubbyCtx, cubbyReq)
 	if err != nil {
 		// Revoke since it's not yet being tracked for expiration
 		c.tokenStore.revokeOrphan(ctx, te.ID)
@@ -270,13 +274,20 @@ DONELISTHANDLING:
 		"creation_ttl":  resp.WrapInfo.TTL,
 		"creation_time": creationTime,
 	}
-	// Store creation_path if not a rewrap
+	// Store creation_path and the accessor of the creating token, if any, if
+	// not a rewrap
 	if req.Path != "sys/wrapping/rewrap" {
 		cubbyReq.Data["creation_path"] = req.Path
+		if creator := req.TokenEntry(); creator != nil && creator.Accessor != "" {
+			cubbyReq.Data["creator_accessor"] = creator.Accessor
+		}
 	} else {
 		cubbyReq.Data["creation_path"] = resp.WrapInfo.CreationPath
+		if creatorAccessor, ok := resp.Data["creator_accessor"].(string); ok && creatorAccessor != "" {
+			cubbyReq.Data["creator_accessor"] = creatorAccessor
+		}
 	}
-	cubbyResp, err = c.router.Route(ctx, cubbyReq)
+	cubbyResp, err = c.router.Route(cubbyCtx, cubbyReq)
 	if err != nil {
 		// Revoke since it's not yet being tracked for expiration
 		c.tokenStore.revokeOrphan(ctx, te.ID)